@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	encryptionkeytypes "github.com/replicatedhq/kots/pkg/encryptionkey/types"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type rotateEncryptionKeyResponse struct {
+	Success bool                               `json:"success"`
+	Error   string                             `json:"error,omitempty"`
+	Report  *encryptionkeytypes.RotationReport `json:"report,omitempty"`
+}
+
+type encryptionKeyRotationStatusResponse struct {
+	Status         string                             `json:"status"`
+	CurrentMessage string                             `json:"currentMessage"`
+	Report         *encryptionkeytypes.RotationReport `json:"report,omitempty"`
+}
+
+func AdminConsoleRotateEncryptionKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-encryption-key [namespace]",
+		Short: "Rotate the key used to encrypt sensitive data",
+		Long: `Creates a new encryption key for the Admin Console and re-encrypts password config values, registry passwords
+and gitops credentials with it. The previous key is retired once nothing depends on it anymore.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+
+			// use namespace-as-arg if provided, else use namespace from -n/--namespace
+			namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+			if err != nil {
+				return errors.Wrap(err, "failed to get namespace")
+			}
+			if len(args) == 1 {
+				namespace = args[0]
+			} else if len(args) > 1 {
+				fmt.Printf("more than one argument supplied: %+v\n", args)
+				os.Exit(1)
+			}
+
+			if err := validateNamespace(namespace); err != nil {
+				return errors.Wrap(err, "failed to validate namespace")
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			clientset, err := k8sutil.GetClientset()
+			if err != nil {
+				return errors.Wrap(err, "failed to get clientset")
+			}
+
+			getPodName := func() (string, error) {
+				return k8sutil.FindKotsadm(clientset, namespace)
+			}
+
+			localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to start port forwarding")
+			}
+
+			go func() {
+				select {
+				case err := <-errChan:
+					if err != nil {
+						log.Error(err)
+					}
+				case <-stopCh:
+				}
+			}()
+
+			url := fmt.Sprintf("http://localhost:%d/api/v1/encryption-key/rotate", localPort)
+
+			authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+			if err != nil {
+				log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+				if v.GetBool("debug") {
+					return errors.Wrap(err, "failed to get kotsadm auth slug")
+				}
+				os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+			}
+
+			dryRun := v.GetBool("dry-run")
+
+			requestPayload := map[string]interface{}{
+				"dryRun": dryRun,
+			}
+			requestBody, err := json.Marshal(requestPayload)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request json")
+			}
+			newReq, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+			if err != nil {
+				return errors.Wrap(err, "failed to create request")
+			}
+			newReq.Header.Add("Content-Type", "application/json")
+			newReq.Header.Add("Authorization", authSlug)
+
+			resp, err := http.DefaultClient.Do(newReq)
+			if err != nil {
+				return errors.Wrap(err, "failed to rotate encryption key")
+			}
+			defer resp.Body.Close()
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return errors.Wrap(err, "failed to read")
+			}
+
+			response := rotateEncryptionKeyResponse{}
+			if err = json.Unmarshal(b, &response); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal server response: %s", b)
+			}
+
+			if response.Error != "" {
+				return errors.New(response.Error)
+			}
+
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+				return errors.Errorf("unexpected response from server %v: %s", resp.StatusCode, b)
+			}
+
+			if dryRun {
+				printRotationReport(log, response.Report)
+				return nil
+			}
+
+			log.ActionWithSpinner("Rotating encryption key")
+			status, err := waitForEncryptionKeyRotation(url, authSlug)
+			if err != nil {
+				log.FinishSpinnerWithError()
+				return errors.Wrap(err, "failed to wait for encryption key rotation")
+			}
+			if status.Status == "failed" {
+				log.FinishSpinnerWithError()
+				printRotationReport(log, status.Report)
+				return errors.New(status.CurrentMessage)
+			}
+			log.FinishSpinner()
+
+			printRotationReport(log, status.Report)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "report what would be re-encrypted without changing anything")
+
+	return cmd
+}
+
+func waitForEncryptionKeyRotation(url string, authSlug string) (*encryptionKeyRotationStatusResponse, error) {
+	for {
+		time.Sleep(time.Second)
+
+		newReq, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create request")
+		}
+		newReq.Header.Add("Authorization", authSlug)
+
+		resp, err := http.DefaultClient.Do(newReq)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rotation status")
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("unexpected response from server %v: %s", resp.StatusCode, b)
+		}
+
+		status := encryptionKeyRotationStatusResponse{}
+		if err := json.Unmarshal(b, &status); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal server response: %s", b)
+		}
+
+		if status.Status != "running" {
+			return &status, nil
+		}
+	}
+}
+
+func printRotationReport(log *logger.CLILogger, report *encryptionkeytypes.RotationReport) {
+	if report == nil {
+		return
+	}
+
+	verb := "Re-encrypted"
+	if report.DryRun {
+		verb = "Would re-encrypt"
+	}
+
+	log.ActionWithoutSpinner("")
+	for _, appVersion := range report.AppVersions {
+		msg := fmt.Sprintf("%s %d value(s) in %s sequence %d", verb, appVersion.EncryptedValues, appVersion.AppSlug, appVersion.Sequence)
+		if appVersion.LegacyKey {
+			msg += " (removing the legacy key from the installation)"
+		}
+		log.ChildActionWithoutSpinner(msg)
+	}
+	for _, appSlug := range report.RegistryPasswords {
+		log.ChildActionWithoutSpinner("%s the registry password for %s", verb, appSlug)
+	}
+	if report.GitOpsValues > 0 {
+		log.ChildActionWithoutSpinner("%s %d gitops credential(s)", verb, report.GitOpsValues)
+	}
+	for _, errMsg := range report.Errors {
+		log.ChildActionWithoutSpinner("Error: %s", errMsg)
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		log.ActionWithoutSpinner("The previous encryption key was not retired. Resolve the errors and rotate the key again.")
+	case report.DryRun:
+		log.ActionWithoutSpinner("%d encryption key(s) would be retired", report.RetiredKeys)
+	default:
+		log.ActionWithoutSpinner("Encryption key rotated, %d previous key(s) retired", report.RetiredKeys)
+	}
+}
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/handlers"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func AdminConsoleTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage API tokens for automation against the Admin Console API",
+		Long: `API tokens are named, revocable credentials with RBAC roles attached.
+Send them to the Admin Console API in the "Authorization: Bearer <token>" header.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			os.Exit(1)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(AdminConsoleTokensCreateCmd())
+	cmd.AddCommand(AdminConsoleTokensListCmd())
+	cmd.AddCommand(AdminConsoleTokensRevokeCmd())
+
+	return cmd
+}
+
+func AdminConsoleTokensCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "create",
+		Short:         "Create an API token",
+		Long:          "Create an API token with the given roles. The token is only printed once and cannot be retrieved later.",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			output := v.GetString("output")
+			if output != "json" && output != "" {
+				return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+			}
+
+			request := handlers.CreateAPITokenRequest{
+				Name:      v.GetString("name"),
+				Roles:     v.GetStringSlice("role"),
+				ExpiresIn: v.GetString("expires-in"),
+			}
+			if request.Name == "" {
+				return errors.New("--name is required")
+			}
+
+			requestBody, err := json.Marshal(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request json")
+			}
+
+			response := handlers.CreateAPITokenResponse{}
+			err = doAPITokensRequest(cmd, "POST", "/api/v1/api-tokens", bytes.NewBuffer(requestBody), &response)
+			if err != nil {
+				return errors.Wrap(err, "failed to create api token")
+			}
+
+			if output == "json" {
+				str, _ := json.MarshalIndent(response, "", "    ")
+				fmt.Println(string(str))
+				return nil
+			}
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+			log.ActionWithoutSpinner("Created API token %s (%s)", response.APIToken.Name, response.APIToken.ID)
+			log.ActionWithoutSpinner("Store the token now, it cannot be retrieved later:")
+			fmt.Fprintln(cmd.OutOrStdout(), response.Token)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("name", "", "name of the token")
+	cmd.Flags().StringSlice("role", []string{}, "role to grant the token, can be specified multiple times")
+	cmd.Flags().String("expires-in", "", "duration after which the token expires, e.g. 720h (defaults to never)")
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
+
+func AdminConsoleTokensListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List API tokens",
+		Long:          "List API tokens, including the expired and revoked ones",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			output := v.GetString("output")
+			if output != "json" && output != "" {
+				return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+			}
+
+			response := handlers.ListAPITokensResponse{}
+			if err := doAPITokensRequest(cmd, "GET", "/api/v1/api-tokens", nil, &response); err != nil {
+				return errors.Wrap(err, "failed to list api tokens")
+			}
+
+			print.APITokens(response.APITokens, output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
+
+func AdminConsoleTokensRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "revoke [token id]",
+		Short:         "Revoke an API token",
+		Long:          "Revoke an API token. Requests that use the token are rejected from then on.",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		Args:          cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokenID := args[0]
+
+			response := handlers.RevokeAPITokenResponse{}
+			if err := doAPITokensRequest(cmd, "DELETE", fmt.Sprintf("/api/v1/api-tokens/%s", url.PathEscape(tokenID)), nil, &response); err != nil {
+				return errors.Wrap(err, "failed to revoke api token")
+			}
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+			log.ActionWithoutSpinner("Revoked API token %s", tokenID)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// doAPITokensRequest sends a request to the Admin Console in the namespace from -n/--namespace and decodes the json response.
+// Responses are expected to have an "error" field that is set on failure.
+func doAPITokensRequest(cmd *cobra.Command, method string, path string, body io.Reader, response interface{}) error {
+	v := viper.GetViper()
+
+	log := logger.NewCLILogger(cmd.OutOrStdout())
+
+	namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+	if err != nil {
+		return errors.Wrap(err, "failed to get namespace")
+	}
+	if err := validateNamespace(namespace); err != nil {
+		return errors.Wrap(err, "failed to validate namespace")
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	getPodName := func() (string, error) {
+		return k8sutil.FindKotsadm(clientset, namespace)
+	}
+
+	localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+	if err != nil {
+		return errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+	if err != nil {
+		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+		if v.GetBool("debug") {
+			return errors.Wrap(err, "failed to get kotsadm auth slug")
+		}
+		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+	}
+
+	newReq, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d%s", localPort, path), body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	newReq.Header.Add("Content-Type", "application/json")
+	newReq.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read")
+	}
+
+	errResponse := struct {
+		Error string `json:"error"`
+	}{}
+	if err := json.Unmarshal(b, &errResponse); err == nil && errResponse.Error != "" {
+		return errors.New(errResponse.Error)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected response from server %v: %s", resp.StatusCode, b)
+	}
+
+	if err := json.Unmarshal(b, response); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal server response: %s", b)
+	}
+
+	return nil
+}
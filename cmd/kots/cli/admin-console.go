@@ -107,6 +107,8 @@ func AdminConsoleCmd() *cobra.Command {
 	cmd.AddCommand(AdminCopyPublicImagesCmd())
 	cmd.AddCommand(GarbageCollectImagesCmd())
 	cmd.AddCommand(AdminGenerateManifestsCmd())
+	cmd.AddCommand(AdminConsoleRotateEncryptionKeyCmd())
+	cmd.AddCommand(AdminConsoleTokensCmd())
 
 	return cmd
 }
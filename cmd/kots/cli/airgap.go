@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func AirgapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "airgap",
+		Short:         "Work with airgap bundles",
+		Long:          ``,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			os.Exit(1)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(AirgapCreateDeltaCmd())
+	cmd.AddCommand(AirgapVerifyCmd())
+
+	return cmd
+}
+
+func AirgapCreateDeltaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-delta [airgap bundle]",
+		Short: "Create a delta airgap bundle from a full airgap bundle",
+		Long: `Create a delta airgap bundle that only contains the images, manifests and layers that are not in the installed version.
+The installed version is described by its Installation manifest (upstream/userdata/installation.yaml in the app version archive).
+The delta bundle can only be uploaded when that version is deployed.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		Args:          cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			installation, err := kotsutil.LoadInstallationFromPath(v.GetString("installation"))
+			if err != nil {
+				return errors.Wrap(err, "failed to load installation")
+			}
+
+			output := v.GetString("output")
+			if _, err := os.Stat(output); err == nil {
+				return errors.Errorf("output file %s already exists", output)
+			}
+
+			var signingKey []byte
+			if signingKeyFile := v.GetString("signing-key"); signingKeyFile != "" {
+				signingKey, err = ioutil.ReadFile(signingKeyFile)
+				if err != nil {
+					return errors.Wrap(err, "failed to read signing key")
+				}
+			}
+
+			delta, err := airgap.CreateDelta(args[0], installation, signingKey, output)
+			if err != nil {
+				os.Remove(output)
+				return errors.Wrap(err, "failed to create delta airgap bundle")
+			}
+
+			fmt.Printf("Created delta airgap bundle %s for version %s, omitting %d images\n", output, delta.BaseVersionLabel, len(delta.OmittedImages))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("installation", "", "path to the Installation manifest of the installed version")
+	cmd.Flags().StringP("output", "o", "", "path to write the delta airgap bundle to")
+	cmd.Flags().String("signing-key", "", "path to the app private key to sign the checksum manifest of the delta airgap bundle with")
+	cmd.MarkFlagRequired("installation")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func AirgapVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [airgap bundle]",
+		Short: "Verify the integrity of an airgap bundle",
+		Long: `Verify every entry of an airgap bundle, including airgap.yaml, the app release and every image layer, against the checksum manifest in the bundle.
+When a license is provided, the signature of the checksum manifest is verified with the app public key in the license.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		Args:          cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			var license *kotsv1beta1.License
+			if licenseFile := v.GetString("license-file"); licenseFile != "" {
+				l, err := kotsutil.LoadLicenseFromPath(ExpandDir(licenseFile))
+				if err != nil {
+					return errors.Wrap(err, "failed to load license")
+				}
+				license = l
+			}
+
+			manifest, err := integrity.VerifyBundle(args[0], license)
+			if err != nil {
+				if errors.Cause(err) == integrity.ErrManifestMissing {
+					return errors.New("airgap bundle does not have a checksum manifest")
+				}
+				return errors.Wrap(err, "failed to verify airgap bundle")
+			}
+
+			if license == nil {
+				fmt.Printf("Verified %d entries of airgap bundle %s, the checksum manifest signature was not verified because no license was provided\n", len(manifest.Checksums), args[0])
+			} else {
+				fmt.Printf("Verified %d entries and the checksum manifest signature of airgap bundle %s\n", len(manifest.Checksums), args[0])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("license-file", "", "path to the license file to verify the checksum manifest signature with")
+
+	return cmd
+}
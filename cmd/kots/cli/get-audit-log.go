@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/handlers"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func GetAuditLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "audit-log",
+		Short:         "Get the audit log of admin console actions",
+		Long:          "Get the audit log of admin console actions, newest first",
+		SilenceUsage:  false,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: getAuditLogCmd,
+	}
+
+	cmd.Flags().Int("current-page", 0, "offset by page size at which to start retrieving events")
+	cmd.Flags().Int("page-size", 50, "number of events to return (defaults to 50)")
+	cmd.Flags().String("app-slug", "", "only return events for this app")
+	cmd.Flags().String("actor", "", "only return events for this actor")
+	cmd.Flags().String("action", "", "only return events for this action, e.g. app.deploy")
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
+
+func getAuditLogCmd(cmd *cobra.Command, args []string) error {
+	v := viper.GetViper()
+
+	output := v.GetString("output")
+	if output != "json" && output != "" {
+		return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+	}
+
+	log := logger.NewCLILogger(cmd.OutOrStdout())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+	if err != nil {
+		return errors.Wrap(err, "failed to get namespace")
+	}
+
+	getPodName := func() (string, error) {
+		return k8sutil.FindKotsadm(clientset, namespace)
+	}
+
+	localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+		if v.GetBool("debug") {
+			return errors.Wrap(err, "failed to get kotsadm auth slug")
+		}
+		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+	}
+
+	urlVals := url.Values{}
+	urlVals.Set("page", fmt.Sprintf("%d", v.GetInt("current-page")))
+	urlVals.Set("pageSize", fmt.Sprintf("%d", v.GetInt("page-size")))
+	if appSlug := v.GetString("app-slug"); appSlug != "" {
+		urlVals.Set("appSlug", appSlug)
+	}
+	if actor := v.GetString("actor"); actor != "" {
+		urlVals.Set("actor", actor)
+	}
+	if action := v.GetString("action"); action != "" {
+		urlVals.Set("action", action)
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/audit-log?%s", localPort, urlVals.Encode())
+	auditLog, err := getAuditLog(url, authSlug)
+	if err != nil {
+		return errors.Wrap(err, "failed to get audit log")
+	}
+
+	print.AuditEvents(auditLog.Events, output)
+
+	return nil
+}
+
+func getAuditLog(url string, authSlug string) (*handlers.ListAuditEventsResponse, error) {
+	newReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	newReq.Header.Add("Content-Type", "application/json")
+	newReq.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	auditLog := handlers.ListAuditEventsResponse{}
+	if err := json.Unmarshal(b, &auditLog); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal audit log")
+	}
+
+	if resp.StatusCode != 200 {
+		if auditLog.Error != "" {
+			return nil, errors.New(auditLog.Error)
+		}
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return &auditLog, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/handlers"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func GetPreflightsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "preflights [appSlug]",
+		Short:         "Get the preflight run history of an app",
+		Long:          "Get every preflight run of an app, newest first, with what triggered it and the checks that failed or warned",
+		SilenceUsage:  false,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: getPreflightsCmd,
+	}
+
+	cmd.Flags().Int64("sequence", -1, "only return the preflight runs of this sequence")
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
+
+func getPreflightsCmd(cmd *cobra.Command, args []string) error {
+	v := viper.GetViper()
+
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(1)
+	}
+
+	appSlug := args[0]
+
+	output := v.GetString("output")
+	if output != "json" && output != "" {
+		return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+	}
+
+	log := logger.NewCLILogger(cmd.OutOrStdout())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+	if err != nil {
+		return errors.Wrap(err, "failed to get namespace")
+	}
+
+	getPodName := func() (string, error) {
+		return k8sutil.FindKotsadm(clientset, namespace)
+	}
+
+	localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+		if v.GetBool("debug") {
+			return errors.Wrap(err, "failed to get kotsadm auth slug")
+		}
+		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+	}
+
+	urlVals := url.Values{}
+	if sequence := v.GetInt64("sequence"); sequence >= 0 {
+		urlVals.Set("sequence", fmt.Sprintf("%d", sequence))
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/preflight/history?%s", localPort, url.PathEscape(appSlug), urlVals.Encode())
+	history, err := getPreflightResultsHistory(url, authSlug)
+	if err != nil {
+		return errors.Wrap(err, "failed to get preflight history")
+	}
+
+	print.PreflightRuns(history.Runs, output)
+
+	return nil
+}
+
+func getPreflightResultsHistory(url string, authSlug string) (*handlers.GetPreflightResultsHistoryResponse, error) {
+	newReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	newReq.Header.Add("Content-Type", "application/json")
+	newReq.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	history := handlers.GetPreflightResultsHistoryResponse{}
+	if err := json.Unmarshal(b, &history); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal preflight history")
+	}
+
+	if resp.StatusCode != 200 {
+		if history.Error != "" {
+			return nil, errors.New(history.Error)
+		}
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return &history, nil
+}
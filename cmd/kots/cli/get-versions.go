@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/auth"
@@ -20,9 +21,9 @@ import (
 
 func GetVersionsCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "versions [appSlug]",
+		Use:           "versions [appSlug] [--diff fromSequence toSequence]",
 		Short:         "Get App Versions",
-		Long:          "",
+		Long:          "Get the versions of an app, or the diff of the rendered manifests between two versions when --diff is set",
 		SilenceUsage:  false,
 		SilenceErrors: false,
 		PreRun: func(cmd *cobra.Command, args []string) {
@@ -36,6 +37,8 @@ func GetVersionsCmd() *cobra.Command {
 	cmd.Flags().Bool("pin-latest", false, "set to true to always return the latest version at the beginning")
 	cmd.Flags().Bool("pin-latest-deployable", false, "set to true to always return the latest deployable version at the beginning")
 	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+	cmd.Flags().Bool("diff", false, "show the diff of the rendered manifests between two sequences, given as arguments after the app slug")
+	cmd.Flags().Bool("by-object", false, "when used with --diff, show the diff per kubernetes object instead of per file")
 
 	return cmd
 }
@@ -55,6 +58,22 @@ func getVersionsCmd(cmd *cobra.Command, args []string) error {
 		return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
 	}
 
+	var fromSequence, toSequence int64
+	if v.GetBool("diff") {
+		if len(args) != 3 {
+			return errors.New("--diff requires a from and a to sequence, e.g. --diff 3 5")
+		}
+		var err error
+		fromSequence, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse from sequence %q", args[1])
+		}
+		toSequence, err = strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse to sequence %q", args[2])
+		}
+	}
+
 	log := logger.NewCLILogger(cmd.OutOrStdout())
 
 	stopCh := make(chan struct{})
@@ -100,6 +119,17 @@ func getVersionsCmd(cmd *cobra.Command, args []string) error {
 		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
 	}
 
+	if v.GetBool("diff") {
+		url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/diff/%d/%d", localPort, url.PathEscape(appSlug), fromSequence, toSequence)
+		diff, err := getAppVersionDiff(url, authSlug)
+		if err != nil {
+			return errors.Wrap(err, "failed to get app version diff")
+		}
+
+		print.VersionDiff(&diff.VersionDiff, output, v.GetBool("by-object"))
+		return nil
+	}
+
 	urlVals := url.Values{}
 	urlVals.Set("currentPage", fmt.Sprintf("%d", v.GetInt("current-page")))
 	urlVals.Set("pageSize", fmt.Sprintf("%d", v.GetInt("page-size")))
@@ -121,6 +151,7 @@ func getVersionsCmd(cmd *cobra.Command, args []string) error {
 			CreatedOn:    *version.CreatedOn,
 			DeployedAt:   version.DeployedAt,
 			Status:       string(version.Status),
+			StatusInfo:   version.StatusInfo,
 			Source:       version.Source,
 		}
 
@@ -162,3 +193,38 @@ func getAppVersions(url string, authSlug string) (*handlers.GetAppVersionHistory
 
 	return &appVersions, nil
 }
+
+func getAppVersionDiff(url string, authSlug string) (*handlers.GetAppVersionDiffResponse, error) {
+	newReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	newReq.Header.Add("Content-Type", "application/json")
+	newReq.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	if resp.StatusCode != 200 {
+		errResponse := handlers.GetAppVersionDiffErrorResponse{}
+		if err := json.Unmarshal(b, &errResponse); err == nil && errResponse.Error != "" {
+			return nil, errors.New(errResponse.Error)
+		}
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	diff := handlers.GetAppVersionDiffResponse{}
+	if err := json.Unmarshal(b, &diff); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal app version diff")
+	}
+
+	return &diff, nil
+}
@@ -34,6 +34,8 @@ kubectl kots get apps`,
 	cmd.AddCommand(GetVersionsCmd())
 	cmd.AddCommand(GetConfigCmd())
 	cmd.AddCommand(GetRestoresCmd())
+	cmd.AddCommand(GetAuditLogCmd())
+	cmd.AddCommand(GetPreflightsCmd())
 
 	return cmd
 }
@@ -13,6 +13,7 @@ import (
 	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/policy"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/client-go/kubernetes"
@@ -304,6 +305,10 @@ func identityServiceDeploy(ctx context.Context, log *logger.CLILogger, clientset
 		return errors.Wrap(err, "failed to validate identity config")
 	}
 
+	if err := policy.ValidateRoles(identityConfig.Spec); err != nil {
+		return errors.Wrap(err, "failed to validate identity config roles")
+	}
+
 	if err := identity.SetConfig(ctx, namespace, identityConfig); err != nil {
 		return errors.Wrap(err, "failed to set identity config")
 	}
@@ -333,6 +338,10 @@ func identityServiceConfigure(ctx context.Context, log *logger.CLILogger, client
 		return errors.Wrap(err, "failed to validate identity config")
 	}
 
+	if err := policy.ValidateRoles(identityConfig.Spec); err != nil {
+		return errors.Wrap(err, "failed to validate identity config roles")
+	}
+
 	if err := identity.SetConfig(ctx, namespace, identityConfig); err != nil {
 		return errors.Wrap(err, "failed to set identity config")
 	}
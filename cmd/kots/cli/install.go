@@ -21,6 +21,7 @@ import (
 	"github.com/manifoldco/promptui"
 	"github.com/pkg/errors"
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
 	"github.com/replicatedhq/kots/pkg/auth"
 	"github.com/replicatedhq/kots/pkg/automation"
 	dockerregistry "github.com/replicatedhq/kots/pkg/docker/registry"
@@ -260,6 +261,7 @@ func InstallCmd() *cobra.Command {
 				NoProxyEnvValue:        v.GetString("no-proxy"),
 				SkipPreflights:         v.GetBool("skip-preflights"),
 				SkipCompatibilityCheck: v.GetBool("skip-compatibility-check"),
+				SkipIntegrityCheck:     v.GetBool("skip-integrity-check"),
 				AppVersionLabel:        v.GetString("app-version-label"),
 				EnsureRBAC:             v.GetBool("ensure-rbac"),
 				SkipRBACCheck:          v.GetBool("skip-rbac-check"),
@@ -319,6 +321,22 @@ func InstallCmd() *cobra.Command {
 					return errors.New("license is required when airgap bundle is specified")
 				}
 
+				if v.GetBool("skip-integrity-check") {
+					log.ActionWithoutSpinnerWarning("WARNING: skipping the airgap bundle integrity check, the bundle will not be verified and may have been tampered with", nil)
+				} else {
+					log.ActionWithSpinner("Verifying airgap bundle")
+					if _, err := integrity.VerifyBundle(airgapArchive, deployOptions.License); err != nil {
+						if errors.Cause(err) != integrity.ErrManifestMissing || integrity.IsManifestRequired(deployOptions.License) {
+							log.FinishSpinnerWithError()
+							return errors.Wrap(err, "failed to verify airgap bundle")
+						}
+						log.FinishSpinner()
+						log.ActionWithoutSpinnerWarning("WARNING: airgap bundle does not have a checksum manifest, the bundle has not been verified", nil)
+					} else {
+						log.FinishSpinner()
+					}
+				}
+
 				log.ActionWithoutSpinner("Extracting airgap bundle")
 
 				airgapRootDir, err := ioutil.TempDir("", "kotsadm-airgap")
@@ -514,6 +532,7 @@ func InstallCmd() *cobra.Command {
 	cmd.Flags().Bool("skip-registry-check", false, "set to true to skip the connectivity test and validation of the provided registry information")
 	cmd.Flags().Bool("strict-security-context", false, "set to explicitly enable explicit security contexts for all kots pods and containers (may not work for some storage providers)")
 	cmd.Flags().Bool("skip-compatibility-check", false, "set to true to skip compatibility checks between the current kots version and the app")
+	cmd.Flags().Bool("skip-integrity-check", false, "set to true to install an airgap bundle without verifying its checksum manifest and signature. this is insecure and should only be used when the bundle can't be verified")
 	cmd.Flags().String("app-version-label", "", "the application version label to install. if not specified, the latest version will be installed")
 
 	cmd.Flags().String("repo", "", "repo uri to use when installing a helm chart")
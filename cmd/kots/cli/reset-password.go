@@ -45,6 +45,14 @@ func ResetPasswordCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
+			if v.GetBool("unlock") {
+				if err := unlockKotsadmLogin(namespace); err != nil {
+					return errors.Wrap(err, "failed to unlock login")
+				}
+				log.ActionWithoutSpinner("The admin console login has been unlocked for %s", namespace)
+				return nil
+			}
+
 			log.ActionWithoutSpinner("Reset the admin console password for %s", namespace)
 			newPassword, err := util.PromptForNewPassword()
 			if err != nil {
@@ -60,9 +68,23 @@ func ResetPasswordCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("unlock", false, "clear failed login attempts to unlock the admin console without changing the password")
+
 	return cmd
 }
 
+func unlockKotsadmLogin(namespace string) error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to create k8s client")
+	}
+
+	if err := password.UnlockLogin(clientset, namespace); err != nil {
+		return errors.Wrap(err, "failed to clear failed login attempts")
+	}
+	return nil
+}
+
 func setKotsadmPassword(newPassword string, namespace string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
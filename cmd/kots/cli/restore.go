@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/print"
 	"github.com/replicatedhq/kots/pkg/snapshot"
@@ -46,7 +47,19 @@ func RestoreCmd() *cobra.Command {
 				return errors.New("--exclude-admin-console and --exclude-apps cannot be used together")
 			}
 
-			var restoreOutput RestoreOutput
+			filter := snapshottypes.RestoreFilter{
+				IncludedNamespaces: v.GetStringSlice("include-namespaces"),
+				IncludedResources:  v.GetStringSlice("include-resources"),
+				LabelSelector:      v.GetString("selector"),
+				PVCs:               v.GetStringSlice("pvc"),
+			}
+			if !filter.IsEmpty() && (v.GetBool("exclude-admin-console") || v.GetBool("exclude-apps")) {
+				return errors.New("--exclude-admin-console and --exclude-apps cannot be used with --include-namespaces, --include-resources, --selector or --pvc")
+			}
+			if err := snapshot.ValidateRestoreFilter(filter); err != nil {
+				return errors.Wrap(err, "invalid restore filter")
+			}
+
 			options := snapshot.RestoreInstanceBackupOptions{
 				BackupName:          backupName,
 				ExcludeAdminConsole: v.GetBool("exclude-admin-console"),
@@ -54,7 +67,19 @@ func RestoreCmd() *cobra.Command {
 				WaitForApps:         v.GetBool("wait-for-apps"),
 				VeleroNamespace:     v.GetString("velero-namespace"),
 				Silent:              output != "",
+				Filter:              filter,
 			}
+
+			if v.GetBool("preview") {
+				contents, err := snapshot.PreviewInstanceRestore(cmd.Context(), options)
+				if err != nil {
+					return errors.Wrap(err, "failed to preview restore")
+				}
+				print.BackupContents(contents, output)
+				return nil
+			}
+
+			var restoreOutput RestoreOutput
 			err := snapshot.RestoreInstanceBackup(cmd.Context(), options)
 			if err != nil && output == "" {
 				return errors.Wrap(err, "failed to restore instance backup")
@@ -82,6 +107,11 @@ func RestoreCmd() *cobra.Command {
 	cmd.Flags().Bool("exclude-admin-console", false, "exclude restoring the admin console and only restore the application(s)")
 	cmd.Flags().Bool("exclude-apps", false, "exclude restoring the application(s) and only restore the admin console")
 	cmd.Flags().Bool("wait-for-apps", true, "wait for all applications to be restored")
+	cmd.Flags().StringSlice("include-namespaces", []string{}, "only restore resources in these namespaces, the admin console and applications are not replaced")
+	cmd.Flags().StringSlice("include-resources", []string{}, "only restore these resources, e.g. deployments,configmaps")
+	cmd.Flags().StringP("selector", "l", "", "only restore resources matching this label selector")
+	cmd.Flags().StringSlice("pvc", []string{}, "only restore these persistent volume claims, in the form namespace/name. the claims must be deleted first. only claims backed up with volume snapshots can be restored this way, claims in file system backups are restored with their namespace")
+	cmd.Flags().Bool("preview", false, "list the resources in the backup that would be restored, without restoring them")
 	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
 
 	cmd.AddCommand(RestoreListCmd())
@@ -55,6 +55,8 @@ func RootCmd() *cobra.Command {
 	cmd.AddCommand(CompletionCmd())
 	cmd.AddCommand(DockerRegistryCmd())
 	cmd.AddCommand(EnableHACmd())
+	cmd.AddCommand(SupportBundleCmd())
+	cmd.AddCommand(AirgapCmd())
 
 	viper.BindPFlags(cmd.Flags())
 
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func SetAutoRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auto-rollback [appSlug]",
+		Short: "Roll back deploys of an application that do not become healthy",
+		Long: `Roll back deploys of an application that do not become healthy.
+If the application status does not reach the required state within the timeout after a deploy,
+the previously deployed version is redeployed. Rollback must be allowed by the application.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+			appSlug := args[0]
+
+			policy, err := getAutoRollbackPolicyFromFlags(v)
+			if err != nil {
+				return err
+			}
+
+			namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+			if err != nil {
+				return errors.Wrap(err, "failed to get namespace")
+			}
+
+			clientset, err := k8sutil.GetClientset()
+			if err != nil {
+				return errors.Wrap(err, "failed to get clientset")
+			}
+
+			getPodName := func() (string, error) {
+				return k8sutil.WaitForKotsadm(clientset, namespace, time.Second*5)
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to start port forwarding")
+			}
+
+			go func() {
+				select {
+				case err := <-errChan:
+					if err != nil {
+						log.Error(err)
+					}
+				case <-stopCh:
+				}
+			}()
+
+			authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+			if err != nil {
+				return errors.Wrap(err, "failed to get kotsadm auth slug")
+			}
+
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"autoRollbackPolicy": policy,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request json")
+			}
+
+			url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/autorollback", localPort, url.QueryEscape(appSlug))
+			newRequest, err := http.NewRequest("PUT", url, bytes.NewBuffer(requestBody))
+			if err != nil {
+				return errors.Wrap(err, "failed to create http request")
+			}
+			newRequest.Header.Add("Authorization", authSlug)
+			newRequest.Header.Add("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(newRequest)
+			if err != nil {
+				return errors.Wrap(err, "failed to execute http request")
+			}
+			defer resp.Body.Close()
+
+			respBody, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return errors.Wrap(err, "failed to read server response")
+			}
+
+			if resp.StatusCode == http.StatusNotFound {
+				return errors.Errorf("app with slug %s not found", appSlug)
+			}
+			if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+				response := struct {
+					Error string `json:"error"`
+				}{}
+				_ = json.Unmarshal(respBody, &response)
+				return errors.Wrapf(errors.New(response.Error), "unexpected status code %d", resp.StatusCode)
+			}
+
+			if policy == nil {
+				log.ActionWithoutSpinner("Automatic rollback disabled for %s", appSlug)
+			} else {
+				log.ActionWithoutSpinner("Automatic rollback enabled for %s", appSlug)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("timeout", "", "how long the application has to reach the required state after a deploy (e.g. 10m)")
+	cmd.Flags().String("required-state", string(appstatetypes.StateReady), "the state the application must reach. one of: ready, updating, degraded")
+	cmd.Flags().Bool("remove", false, "remove the policy so deploys are never rolled back automatically")
+
+	return cmd
+}
+
+func getAutoRollbackPolicyFromFlags(v *viper.Viper) (*apptypes.AutoRollbackPolicy, error) {
+	if v.GetBool("remove") {
+		if v.GetString("timeout") != "" {
+			return nil, errors.New("--remove cannot be used with --timeout")
+		}
+		return nil, nil
+	}
+
+	policy := &apptypes.AutoRollbackPolicy{
+		Timeout:       v.GetString("timeout"),
+		RequiredState: appstatetypes.State(v.GetString("required-state")),
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid auto rollback policy")
+	}
+
+	return policy, nil
+}
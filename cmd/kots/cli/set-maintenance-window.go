@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func SetMaintenanceWindowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance-window [appSlug]",
+		Short: "Limit automatic deploys of an application to a maintenance window",
+		Long: `Limit automatic deploys of an application to a recurring maintenance window.
+Versions that are found outside of the window are deployed when the next window starts.
+
+A window is either a set of days with a start and end time (--days, --start, --end),
+or a cron expression for the start of the window with a duration (--cron, --duration).`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			if len(args) != 1 {
+				cmd.Help()
+				os.Exit(1)
+			}
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+			appSlug := args[0]
+
+			window, err := getMaintenanceWindowFromFlags(v)
+			if err != nil {
+				return err
+			}
+
+			namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+			if err != nil {
+				return errors.Wrap(err, "failed to get namespace")
+			}
+
+			clientset, err := k8sutil.GetClientset()
+			if err != nil {
+				return errors.Wrap(err, "failed to get clientset")
+			}
+
+			getPodName := func() (string, error) {
+				return k8sutil.WaitForKotsadm(clientset, namespace, time.Second*5)
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to start port forwarding")
+			}
+
+			go func() {
+				select {
+				case err := <-errChan:
+					if err != nil {
+						log.Error(err)
+					}
+				case <-stopCh:
+				}
+			}()
+
+			authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+			if err != nil {
+				return errors.Wrap(err, "failed to get kotsadm auth slug")
+			}
+
+			requestBody, err := json.Marshal(map[string]interface{}{
+				"autoDeployWindow": window,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request json")
+			}
+
+			url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/automaticupdates/window", localPort, url.QueryEscape(appSlug))
+			newRequest, err := http.NewRequest("PUT", url, bytes.NewBuffer(requestBody))
+			if err != nil {
+				return errors.Wrap(err, "failed to create http request")
+			}
+			newRequest.Header.Add("Authorization", authSlug)
+			newRequest.Header.Add("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(newRequest)
+			if err != nil {
+				return errors.Wrap(err, "failed to execute http request")
+			}
+			defer resp.Body.Close()
+
+			respBody, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return errors.Wrap(err, "failed to read server response")
+			}
+
+			if resp.StatusCode == http.StatusNotFound {
+				return errors.Errorf("app with slug %s not found", appSlug)
+			}
+			if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+				response := struct {
+					Error string `json:"error"`
+				}{}
+				_ = json.Unmarshal(respBody, &response)
+				return errors.Wrapf(errors.New(response.Error), "unexpected status code %d", resp.StatusCode)
+			}
+
+			if window == nil {
+				log.ActionWithoutSpinner("Maintenance window removed for %s", appSlug)
+			} else {
+				log.ActionWithoutSpinner("Maintenance window set for %s", appSlug)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("days", nil, "days of the week the window starts on (e.g. sat,sun)")
+	cmd.Flags().String("start", "", "time of day the window starts, in HH:MM format")
+	cmd.Flags().String("end", "", "time of day the window ends, in HH:MM format. windows that end before they start continue into the next day")
+	cmd.Flags().String("cron", "", "cron expression for the start of the window. cannot be used with --days, --start or --end")
+	cmd.Flags().String("duration", "", "duration of the window when --cron is used (e.g. 2h30m)")
+	cmd.Flags().String("timezone", "", "IANA time zone of the window (e.g. America/New_York). defaults to UTC")
+	cmd.Flags().Bool("remove", false, "remove the maintenance window so versions are deployed as soon as they are available")
+
+	return cmd
+}
+
+func getMaintenanceWindowFromFlags(v *viper.Viper) (*apptypes.MaintenanceWindow, error) {
+	window := &apptypes.MaintenanceWindow{
+		Cron:      v.GetString("cron"),
+		Duration:  v.GetString("duration"),
+		Days:      v.GetStringSlice("days"),
+		StartTime: v.GetString("start"),
+		EndTime:   v.GetString("end"),
+		Timezone:  v.GetString("timezone"),
+	}
+
+	if v.GetBool("remove") {
+		if window.Cron != "" || window.Duration != "" || len(window.Days) > 0 || window.StartTime != "" || window.EndTime != "" || window.Timezone != "" {
+			return nil, errors.New("--remove cannot be used with other window flags")
+		}
+		return nil, nil
+	}
+
+	if err := window.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid maintenance window")
+	}
+
+	return window, nil
+}
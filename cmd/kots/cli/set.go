@@ -30,6 +30,8 @@ func SetCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(SetConfigCmd())
+	cmd.AddCommand(SetMaintenanceWindowCmd())
+	cmd.AddCommand(SetAutoRollbackCmd())
 
 	return cmd
 }
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/replicatedhq/kots/pkg/supportbundle"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func SupportBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "support-bundle",
+		Short:         "Work with support bundles",
+		Long:          ``,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			os.Exit(1)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(SupportBundleCompareCmd())
+
+	return cmd
+}
+
+func SupportBundleCompareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare [base bundle archive] [target bundle archive]",
+		Short: "Compare two support bundle archives",
+		Long: `Compare two support bundle archives, e.g. one collected before an upgrade and one collected after it.
+Lists the analyzer outcomes that changed, the pods and events that appeared, the resources whose specs differ,
+and the resource types whose counts changed.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		Args:          cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			output := v.GetString("output")
+			if output != "json" && output != "" {
+				return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+			}
+
+			for _, archivePath := range args {
+				if _, err := os.Stat(archivePath); err != nil {
+					return errors.Wrapf(err, "failed to stat %s", archivePath)
+				}
+			}
+
+			comparison, err := supportbundle.Compare(
+				supportbundle.CompareInput{ID: args[0], ArchivePath: args[0]},
+				supportbundle.CompareInput{ID: args[1], ArchivePath: args[1]},
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to compare support bundles")
+			}
+
+			print.SupportBundleComparison(comparison, output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
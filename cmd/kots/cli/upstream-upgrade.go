@@ -7,9 +7,12 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	dockerregistry "github.com/replicatedhq/kots/pkg/docker/registry"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/kurl"
+	kotslicense "github.com/replicatedhq/kots/pkg/license"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/upload"
 	"github.com/replicatedhq/kots/pkg/upstream"
@@ -80,11 +83,27 @@ func UpstreamUpgradeCmd() *cobra.Command {
 				log.FinishSpinner()
 			}
 
+			// without a license file, the license is fetched from the admin console
+			var license *kotsv1beta1.License
+			if licenseFile := v.GetString("license-file"); licenseFile != "" && v.GetString("airgap-bundle") != "" && !v.GetBool("skip-integrity-check") {
+				l, err := kotsutil.LoadLicenseFromPath(ExpandDir(licenseFile))
+				if err != nil {
+					return errors.Wrap(err, "failed to load license")
+				}
+				l, err = kotslicense.VerifySignature(l)
+				if err != nil {
+					return errors.Wrap(err, "failed to verify license signature")
+				}
+				license = l
+			}
+
 			upgradeOptions := upstream.UpgradeOptions{
 				AirgapBundle:       v.GetString("airgap-bundle"),
 				RegistryConfig:     *registryConfig,
 				IsKurl:             isKurl,
+				License:            license,
 				DisableImagePush:   v.GetBool("disable-image-push"),
+				SkipIntegrityCheck: v.GetBool("skip-integrity-check"),
 				Namespace:          namespace,
 				Debug:              v.GetBool("debug"),
 				Deploy:             v.GetBool("deploy"),
@@ -114,6 +133,9 @@ func UpstreamUpgradeCmd() *cobra.Command {
 			if v.GetBool("skip-compatibility-check") {
 				urlVals.Set("skipCompatibilityCheck", "true")
 			}
+			if v.GetBool("skip-integrity-check") {
+				urlVals.Set("skipIntegrityCheck", "true")
+			}
 			if v.GetBool("is-cli") {
 				urlVals.Set("isCLI", "true")
 			}
@@ -121,6 +143,8 @@ func UpstreamUpgradeCmd() *cobra.Command {
 				urlVals.Set("wait", "true")
 			}
 			upgradeOptions.UpdateCheckEndpoint = fmt.Sprintf("http://localhost:%d/api/v1/app/%s/updatecheck?%s", localPort, url.PathEscape(appSlug), urlVals.Encode())
+			upgradeOptions.LicenseEndpoint = fmt.Sprintf("http://localhost:%d/api/v1/app/%s/license", localPort, url.PathEscape(appSlug))
+			upgradeOptions.VerifyDeltaEndpoint = fmt.Sprintf("http://localhost:%d/api/v1/app/%s/airgap/verify-delta", localPort, url.PathEscape(appSlug))
 
 			go func() {
 				select {
@@ -159,6 +183,8 @@ func UpstreamUpgradeCmd() *cobra.Command {
 
 	cmd.Flags().String("airgap-bundle", "", "path to the application airgap bundle where application images and metadata will be loaded from")
 	cmd.Flags().Bool("disable-image-push", false, "set to true to disable images from being pushed to private registry")
+	cmd.Flags().String("license-file", "", "path to the license file of the app, used to verify the airgap bundle signature before its images are pushed. defaults to the license in the admin console")
+	cmd.Flags().Bool("skip-integrity-check", false, "set to true to upload an airgap bundle without verifying its checksum manifest and signature. this is insecure and should only be used when the bundle can't be verified")
 	cmd.Flags().Bool("skip-registry-check", false, "set to true to skip the connectivity test and validation of the provided registry information")
 	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
 
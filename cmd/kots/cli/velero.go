@@ -25,6 +25,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/snapshot/providers"
 	snapshottypes "github.com/replicatedhq/kots/pkg/snapshot/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -50,6 +51,7 @@ func VeleroCmd() *cobra.Command {
 	cmd.AddCommand(VeleroConfigureHostPathCmd())
 	cmd.AddCommand(VeleroPrintFileSystemInstructionsCmd())
 	cmd.AddCommand(VeleroMigrateMinioFileSystemCmd())
+	cmd.AddCommand(VeleroRemoveSecondaryLocationCmd())
 
 	return cmd
 }
@@ -233,9 +235,11 @@ func VeleroConfigureAmazonS3AccessKeyCmd() *cobra.Command {
 					SecretAccessKey: v.GetString("secret-access-key"),
 					UseInstanceRole: false,
 				},
-				KotsadmNamespace: namespace,
-				RegistryConfig:   &registryConfig,
-				SkipValidation:   v.GetBool("skip-validation"),
+				KotsadmNamespace:  namespace,
+				RegistryConfig:    &registryConfig,
+				SkipValidation:    v.GetBool("skip-validation"),
+				LocationName:      v.GetString("secondary-location"),
+				ReplicationPolicy: snapshottypes.ReplicationPolicy(v.GetString("replication-policy")),
 			}
 			_, err = snapshot.ConfigureStore(cmd.Context(), configureStoreOptions)
 			if err != nil {
@@ -254,6 +258,7 @@ func VeleroConfigureAmazonS3AccessKeyCmd() *cobra.Command {
 	cmd.Flags().String("access-key-id", "", "the aws access key id to use for accessing the bucket (required)")
 	cmd.Flags().String("secret-access-key", "", "the aws secret access key to use for accessing the bucket (required)")
 	cmd.Flags().Bool("skip-validation", false, "skip the validation of the aws s3 endpoint/bucket")
+	secondaryLocationFlags(cmd.Flags())
 
 	cmd.MarkFlagRequired("bucket")
 	cmd.MarkFlagRequired("region")
@@ -417,6 +422,8 @@ func VeleroConfigureOtherS3Cmd() *cobra.Command {
 				KotsadmNamespace:  namespace,
 				RegistryConfig:    registryConfig,
 				SkipValidation:    v.GetBool("skip-validation"),
+				LocationName:      v.GetString("secondary-location"),
+				ReplicationPolicy: snapshottypes.ReplicationPolicy(v.GetString("replication-policy")),
 				ValidateUsingAPod: true,
 				CACertData:        caCertData,
 			}
@@ -439,6 +446,7 @@ func VeleroConfigureOtherS3Cmd() *cobra.Command {
 	cmd.Flags().String("endpoint", "", "the s3 endpoint. (e.g. http://some-other-s3-endpoint, required)")
 	cmd.Flags().String("cacert", "", "file containing a certificate bundle to use when verifying TLS connections to the object store.")
 	cmd.Flags().Bool("skip-validation", false, "skip the validation of the s3 endpoint/bucket")
+	secondaryLocationFlags(cmd.Flags())
 
 	cmd.MarkFlagRequired("bucket")
 	cmd.MarkFlagRequired("region")
@@ -520,9 +528,11 @@ func VeleroConfigureGCPServiceAccount() *cobra.Command {
 				Google: &snapshottypes.StoreGoogle{
 					JSONFile: jsonFile,
 				},
-				KotsadmNamespace: namespace,
-				RegistryConfig:   &registryConfig,
-				SkipValidation:   v.GetBool("skip-validation"),
+				KotsadmNamespace:  namespace,
+				RegistryConfig:    &registryConfig,
+				SkipValidation:    v.GetBool("skip-validation"),
+				LocationName:      v.GetString("secondary-location"),
+				ReplicationPolicy: snapshottypes.ReplicationPolicy(v.GetString("replication-policy")),
 			}
 			_, err = snapshot.ConfigureStore(cmd.Context(), configureStoreOptions)
 			if err != nil {
@@ -539,6 +549,7 @@ func VeleroConfigureGCPServiceAccount() *cobra.Command {
 	cmd.Flags().String("path", "", "path to a subdirectory in the object store bucket")
 	cmd.Flags().String("json-file", "", "path to JSON credntials file for veloro (required)")
 	cmd.Flags().Bool("skip-validation", false, "skip the validation of the bucket")
+	secondaryLocationFlags(cmd.Flags())
 
 	cmd.MarkFlagRequired("bucket")
 	cmd.MarkFlagRequired("json-file")
@@ -685,9 +696,11 @@ func VeleroConfigureAzureServicePrincipleCmd() *cobra.Command {
 					ClientSecret:   v.GetString("client-secret"),
 					CloudName:      v.GetString("cloud-name"),
 				},
-				KotsadmNamespace: namespace,
-				RegistryConfig:   &registryConfig,
-				SkipValidation:   v.GetBool("skip-validation"),
+				KotsadmNamespace:  namespace,
+				RegistryConfig:    &registryConfig,
+				SkipValidation:    v.GetBool("skip-validation"),
+				LocationName:      v.GetString("secondary-location"),
+				ReplicationPolicy: snapshottypes.ReplicationPolicy(v.GetString("replication-policy")),
 			}
 
 			_, err = snapshot.ConfigureStore(cmd.Context(), configureStoreOptions)
@@ -709,6 +722,7 @@ func VeleroConfigureAzureServicePrincipleCmd() *cobra.Command {
 	cmd.Flags().String("path", "", "path to a subdirectory in the blob storage container")
 	cmd.Flags().String("resource-group", "", "the resource group name of the blob storage container (required)")
 	cmd.Flags().Bool("skip-validation", false, "skip the validation of the blob storage container")
+	secondaryLocationFlags(cmd.Flags())
 	cmd.Flags().String("storage-account", "", "the storage account name of the blob storage container (required)")
 	cmd.Flags().String("subscription-id", "", "the subscription id associated with the blob storage container (required)")
 	cmd.Flags().String("tenant-id", "", "the tenant ID associated with the blob storage container (required)")
@@ -1093,6 +1107,44 @@ func promptForFileSystemReset(log *logger.CLILogger, warningMsg string) bool {
 	}
 }
 
+func secondaryLocationFlags(flagset *pflag.FlagSet) {
+	flagset.String("secondary-location", "", "configure a secondary location with this name that backups are replicated to, instead of the primary store")
+	flagset.String("replication-policy", "", "which backups are replicated to the secondary location. Options: all, scheduled, none (defaults to all)")
+}
+
+func VeleroRemoveSecondaryLocationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "remove-secondary-location [name]",
+		Short:         "Remove a secondary location that backups are replicated to",
+		Long:          `Removes the secondary location from velero. Backups that were already replicated to it are not deleted from the bucket.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		Args:          cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+
+			namespace := v.GetString("namespace")
+			if err := validateNamespace(namespace); err != nil {
+				return err
+			}
+
+			if err := snapshot.RemoveSecondaryStore(cmd.Context(), namespace, args[0]); err != nil {
+				return errors.Wrap(err, "failed to remove secondary location")
+			}
+
+			log.Info("\nSecondary location %s removed", args[0])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
 func validateVeleroNamespace(namespace string) error {
 	if namespace == "" {
 		return errors.New("velero-namespace is required")
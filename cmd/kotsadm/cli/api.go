@@ -33,6 +33,8 @@ func APICmd() *cobra.Command {
 			params := apiserver.APIServerParams{
 				Version:                buildversion.Version(),
 				RqliteURI:              os.Getenv("RQLITE_URI"),
+				DatabaseDriver:         os.Getenv("DATABASE_DRIVER"),
+				DatabaseURI:            os.Getenv("DATABASE_URI"),
 				AutocreateClusterToken: os.Getenv("AUTO_CREATE_CLUSTER_TOKEN"),
 			}
 
@@ -147,7 +147,7 @@ func TestMigrateFromPostgresToRqlite(t *testing.T) {
 	// init dbs vars
 	t.Setenv("POSTGRES_URI", pgURI)
 	t.Setenv("POSTGRES_SCHEMA_DIR", POSTGRES_SCHEMA_DIR)
-	persistence.SetDB(&rqliteDB)
+	persistence.SetDB(persistence.NewRqliteDB(&rqliteDB))
 
 	// update postgres schema
 	if err := persistence.UpdateDBSchema("postgres", pgURI, POSTGRES_SCHEMA_DIR); err != nil {
@@ -31,6 +31,23 @@ type AirgapSpec struct {
 	RequiredReleases  []AirgapReleaseMeta `json:"requiredReleases,omitempty"`
 	SavedImages       []string            `json:"savedImages,omitempty"`
 	Format            string              `json:"format,omitempty"`
+	Delta             *AirgapDelta        `json:"delta,omitempty"`
+}
+
+// AirgapDelta describes the installed version a delta bundle was built against.
+// A delta bundle only contains the images, manifests and layers that are not in the base version.
+type AirgapDelta struct {
+	BaseVersionLabel string   `json:"baseVersionLabel,omitempty"`
+	OmittedImages    []string `json:"omittedImages,omitempty"`
+	// SharedLayers are the layers of the images in the delta that were omitted because an omitted image has them
+	SharedLayers []AirgapDeltaLayer `json:"sharedLayers,omitempty"`
+}
+
+// AirgapDeltaLayer is a layer omitted from a delta bundle, it is mounted from the repository of Image when pushing.
+type AirgapDeltaLayer struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType,omitempty"`
+	Image     string `json:"image"`
 }
 
 // AirgapStatus defines airgap release metadata
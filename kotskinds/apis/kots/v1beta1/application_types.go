@@ -58,6 +58,7 @@ type ApplicationSpec struct {
 	KustomizeVersion             string              `json:"kustomizeVersion,omitempty"`
 	AdditionalImages             []string            `json:"additionalImages,omitempty"`
 	AdditionalNamespaces         []string            `json:"additionalNamespaces,omitempty"`
+	BackupHooks                  []BackupHook        `json:"backupHooks,omitempty"`
 	RequireMinimalRBACPrivileges bool                `json:"requireMinimalRBACPrivileges,omitempty"`
 	SupportMinimalRBACPrivileges bool                `json:"supportMinimalRBACPrivileges,omitempty"`
 	ProxyPublicImages            bool                `json:"proxyPublicImages,omitempty"`
@@ -99,6 +100,25 @@ type MetricQuery struct {
 	Legend string `json:"legend,omitempty"`
 }
 
+// BackupHook is a command that's run in the pods matching the selector before (pre) or after (post) the pods
+// are included in a snapshot. KOTS translates backup hooks into Velero backup hooks and checks that matching
+// pods exist before the snapshot starts.
+type BackupHook struct {
+	Name string `json:"name"`
+	// Phase is "pre" or "post", defaults to "pre"
+	Phase string `json:"phase,omitempty"`
+	// Namespace defaults to the namespace the app is deployed to, it must be that namespace or one of the additional namespaces
+	Namespace string            `json:"namespace,omitempty"`
+	Selector  map[string]string `json:"selector"`
+	// Container defaults to the first container in the pod
+	Container string   `json:"container,omitempty"`
+	Command   []string `json:"command"`
+	// Timeout is a duration, e.g. "10m". Defaults to Velero's default of 30s.
+	Timeout string `json:"timeout,omitempty"`
+	// OnError is "fail" or "continue", defaults to "fail"
+	OnError string `json:"onError,omitempty"`
+}
+
 // ApplicationStatus defines the observed state of Application
 type ApplicationStatus struct {
 }
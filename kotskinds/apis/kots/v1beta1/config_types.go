@@ -64,7 +64,17 @@ type ConfigItem struct {
 }
 
 type ConfigItemValidation struct {
-	Regex *RegexValidator `json:"regex,omitempty"`
+	Regex       *RegexValidator       `json:"regex,omitempty"`
+	Int         *IntValidator         `json:"int,omitempty"`
+	Float       *FloatValidator       `json:"float,omitempty"`
+	URL         *URLValidator         `json:"url,omitempty"`
+	Email       *EmailValidator       `json:"email,omitempty"`
+	IP          *IPValidator          `json:"ip,omitempty"`
+	CIDR        *CIDRValidator        `json:"cidr,omitempty"`
+	Hostname    *HostnameValidator    `json:"hostname,omitempty"`
+	Port        *PortValidator        `json:"port,omitempty"`
+	Certificate *CertificateValidator `json:"certificate,omitempty"`
+	Expression  *ExpressionValidator  `json:"expression,omitempty"`
 }
 
 type RegexValidator struct {
@@ -72,6 +82,65 @@ type RegexValidator struct {
 	Pattern string `json:"pattern"`
 }
 
+// IntValidator checks that the value is an integer. Min and Max are inclusive.
+type IntValidator struct {
+	Message string `json:"message,omitempty"`
+	Min     *int64 `json:"min,omitempty"`
+	Max     *int64 `json:"max,omitempty"`
+}
+
+// FloatValidator checks that the value is a number. Min and Max are inclusive and are
+// strings because floats are not supported in CRDs.
+type FloatValidator struct {
+	Message string `json:"message,omitempty"`
+	Min     string `json:"min,omitempty"`
+	Max     string `json:"max,omitempty"`
+}
+
+type URLValidator struct {
+	Message string `json:"message,omitempty"`
+	// Schemes limits the allowed url schemes. Any scheme is allowed when empty.
+	Schemes []string `json:"schemes,omitempty"`
+}
+
+type EmailValidator struct {
+	Message string `json:"message,omitempty"`
+}
+
+type IPValidator struct {
+	Message string `json:"message,omitempty"`
+	// Version is one of "ipv4" or "ipv6". Both are allowed when empty.
+	Version string `json:"version,omitempty"`
+}
+
+type CIDRValidator struct {
+	Message string `json:"message,omitempty"`
+	// Version is one of "ipv4" or "ipv6". Both are allowed when empty.
+	Version string `json:"version,omitempty"`
+}
+
+type HostnameValidator struct {
+	Message string `json:"message,omitempty"`
+}
+
+type PortValidator struct {
+	Message string `json:"message,omitempty"`
+}
+
+// CertificateValidator checks that the value is a PEM encoded certificate. When KeyItem is
+// set, the certificate must also match the PEM encoded private key in that config item.
+type CertificateValidator struct {
+	Message string `json:"message,omitempty"`
+	KeyItem string `json:"keyItem,omitempty"`
+}
+
+// ExpressionValidator is a cross-field rule. Rule is a template expression, usually
+// referencing other config items, that must render to "true" for the value to be valid.
+type ExpressionValidator struct {
+	Message string               `json:"message"`
+	Rule    multitype.QuotedBool `json:"rule"`
+}
+
 type RepeatTemplate struct {
 	APIVersion string `json:"apiVersion"`
 	Kind       string `json:"kind"`
@@ -111,6 +111,7 @@ type LicenseSpec struct {
 	LicenseID                      string                      `json:"licenseID"`
 	LicenseType                    string                      `json:"licenseType,omitempty"`
 	IsAirgapSupported              bool                        `json:"isAirgapSupported,omitempty"`
+	IsAirgapIntegrityRequired      bool                        `json:"isAirgapIntegrityRequired,omitempty"`
 	IsGitOpsSupported              bool                        `json:"isGitOpsSupported,omitempty"`
 	IsIdentityServiceSupported     bool                        `json:"isIdentityServiceSupported,omitempty"`
 	IsGeoaxisSupported             bool                        `json:"isGeoaxisSupported,omitempty"`
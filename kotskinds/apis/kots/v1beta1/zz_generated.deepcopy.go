@@ -53,6 +53,46 @@ func (in *Airgap) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AirgapDelta) DeepCopyInto(out *AirgapDelta) {
+	*out = *in
+	if in.OmittedImages != nil {
+		in, out := &in.OmittedImages, &out.OmittedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedLayers != nil {
+		in, out := &in.SharedLayers, &out.SharedLayers
+		*out = make([]AirgapDeltaLayer, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AirgapDelta.
+func (in *AirgapDelta) DeepCopy() *AirgapDelta {
+	if in == nil {
+		return nil
+	}
+	out := new(AirgapDelta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AirgapDeltaLayer) DeepCopyInto(out *AirgapDeltaLayer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AirgapDeltaLayer.
+func (in *AirgapDeltaLayer) DeepCopy() *AirgapDeltaLayer {
+	if in == nil {
+		return nil
+	}
+	out := new(AirgapDeltaLayer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AirgapList) DeepCopyInto(out *AirgapList) {
 	*out = *in
@@ -119,6 +159,11 @@ func (in *AirgapSpec) DeepCopyInto(out *AirgapSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Delta != nil {
+		in, out := &in.Delta, &out.Delta
+		*out = new(AirgapDelta)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AirgapSpec.
@@ -298,6 +343,13 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.BackupHooks != nil {
+		in, out := &in.BackupHooks, &out.BackupHooks
+		*out = make([]BackupHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ConsoleFeatureFlags != nil {
 		in, out := &in.ConsoleFeatureFlags, &out.ConsoleFeatureFlags
 		*out = make([]string, len(*in))
@@ -330,6 +382,63 @@ func (in *ApplicationStatus) DeepCopy() *ApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHook) DeepCopyInto(out *BackupHook) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHook.
+func (in *BackupHook) DeepCopy() *BackupHook {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CIDRValidator) DeepCopyInto(out *CIDRValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIDRValidator.
+func (in *CIDRValidator) DeepCopy() *CIDRValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(CIDRValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateValidator) DeepCopyInto(out *CertificateValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateValidator.
+func (in *CertificateValidator) DeepCopy() *CertificateValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChartIdentifier) DeepCopyInto(out *ChartIdentifier) {
 	*out = *in
@@ -480,6 +589,56 @@ func (in *ConfigItemValidation) DeepCopyInto(out *ConfigItemValidation) {
 		*out = new(RegexValidator)
 		**out = **in
 	}
+	if in.Int != nil {
+		in, out := &in.Int, &out.Int
+		*out = new(IntValidator)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Float != nil {
+		in, out := &in.Float, &out.Float
+		*out = new(FloatValidator)
+		**out = **in
+	}
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(URLValidator)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailValidator)
+		**out = **in
+	}
+	if in.IP != nil {
+		in, out := &in.IP, &out.IP
+		*out = new(IPValidator)
+		**out = **in
+	}
+	if in.CIDR != nil {
+		in, out := &in.CIDR, &out.CIDR
+		*out = new(CIDRValidator)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(HostnameValidator)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(PortValidator)
+		**out = **in
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(CertificateValidator)
+		**out = **in
+	}
+	if in.Expression != nil {
+		in, out := &in.Expression, &out.Expression
+		*out = new(ExpressionValidator)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigItemValidation.
@@ -735,6 +894,21 @@ func (in *DexConnectorsSource) DeepCopy() *DexConnectorsSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailValidator) DeepCopyInto(out *EmailValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailValidator.
+func (in *EmailValidator) DeepCopy() *EmailValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EntitlementField) DeepCopyInto(out *EntitlementField) {
 	*out = *in
@@ -766,6 +940,36 @@ func (in *EntitlementValue) DeepCopy() *EntitlementValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpressionValidator) DeepCopyInto(out *ExpressionValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpressionValidator.
+func (in *ExpressionValidator) DeepCopy() *ExpressionValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpressionValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FloatValidator) DeepCopyInto(out *FloatValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FloatValidator.
+func (in *FloatValidator) DeepCopy() *FloatValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(FloatValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in GroupValues) DeepCopyInto(out *GroupValues) {
 	{
@@ -908,6 +1112,36 @@ func (in *HelmChartStatus) DeepCopy() *HelmChartStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameValidator) DeepCopyInto(out *HostnameValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameValidator.
+func (in *HostnameValidator) DeepCopy() *HostnameValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPValidator) DeepCopyInto(out *IPValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPValidator.
+func (in *IPValidator) DeepCopy() *IPValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(IPValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Identity) DeepCopyInto(out *Identity) {
 	*out = *in
@@ -1014,6 +1248,46 @@ func (in *IdentityConfigList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentityConfigRole) DeepCopyInto(out *IdentityConfigRole) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]IdentityConfigRolePolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]IdentityConfigRolePolicy, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityConfigRole.
+func (in *IdentityConfigRole) DeepCopy() *IdentityConfigRole {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityConfigRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentityConfigRolePolicy) DeepCopyInto(out *IdentityConfigRolePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityConfigRolePolicy.
+func (in *IdentityConfigRolePolicy) DeepCopy() *IdentityConfigRolePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityConfigRolePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdentityConfigSpec) DeepCopyInto(out *IdentityConfigSpec) {
 	*out = *in
@@ -1024,6 +1298,13 @@ func (in *IdentityConfigSpec) DeepCopyInto(out *IdentityConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]IdentityConfigRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.IngressConfig.DeepCopyInto(&out.IngressConfig)
 	if in.ClientSecret != nil {
 		in, out := &in.ClientSecret, &out.ClientSecret
@@ -1460,6 +1741,31 @@ func (in *InstallationYAMLError) DeepCopy() *InstallationYAMLError {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntValidator) DeepCopyInto(out *IntValidator) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntValidator.
+func (in *IntValidator) DeepCopy() *IntValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(IntValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *License) DeepCopyInto(out *License) {
 	*out = *in
@@ -1768,6 +2074,21 @@ func (in *OptionalValue) DeepCopy() *OptionalValue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortValidator) DeepCopyInto(out *PortValidator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortValidator.
+func (in *PortValidator) DeepCopy() *PortValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(PortValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegexValidator) DeepCopyInto(out *RegexValidator) {
 	*out = *in
@@ -1813,6 +2134,26 @@ func (in *StringValueOrEncrypted) DeepCopy() *StringValueOrEncrypted {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *URLValidator) DeepCopyInto(out *URLValidator) {
+	*out = *in
+	if in.Schemes != nil {
+		in, out := &in.Schemes, &out.Schemes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new URLValidator.
+func (in *URLValidator) DeepCopy() *URLValidator {
+	if in == nil {
+		return nil
+	}
+	out := new(URLValidator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ValuesByGroup) DeepCopyInto(out *ValuesByGroup) {
 	{
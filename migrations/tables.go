@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// Tables holds the schemahero table specs for the kotsadm database.
+//
+//go:embed tables/*.yaml
+var Tables embed.FS
@@ -22,6 +22,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/pull"
 	"github.com/replicatedhq/kots/pkg/registry"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
@@ -46,6 +47,7 @@ type CreateAirgapAppOpts struct {
 	IsAutomated            bool
 	SkipPreflights         bool
 	SkipCompatibilityCheck bool
+	SkipIntegrityCheck     bool
 }
 
 // CreateAppFromAirgap does a lot. Maybe too much. Definitely too much.
@@ -114,6 +116,13 @@ func CreateAppFromAirgap(opts CreateAirgapAppOpts) (finalError error) {
 		archiveDir = dir
 	}
 
+	if airgap, err := kotsutil.FindAirgapMetaInDir(archiveDir); err == nil && airgap.Spec.Delta != nil {
+		return util.ActionableError{
+			NoRetry: true,
+			Message: fmt.Sprintf("This delta airgap bundle cannot be used to install because it was built for updating version %s.", airgap.Spec.Delta.BaseVersionLabel),
+		}
+	}
+
 	// extract the release
 	workspace, err := ioutil.TempDir("", "kots-airgap")
 	if err != nil {
@@ -143,6 +152,14 @@ func CreateAppFromAirgap(opts CreateAirgapAppOpts) (finalError error) {
 	}
 	license := obj.(*kotsv1beta1.License)
 
+	if err := store.GetStore().SetTaskStatus(taskID, "Verifying package...", "running"); err != nil {
+		return errors.Wrap(err, "failed to set task status")
+	}
+
+	if err := verifyIntegrity(archiveDir, airgapBundle, license, opts.SkipIntegrityCheck); err != nil {
+		return errors.Wrap(err, "failed to verify airgap bundle")
+	}
+
 	licenseFile, err := ioutil.TempFile("", "kotsadm")
 	if err != nil {
 		return errors.Wrap(err, "failed to create temp file")
@@ -315,7 +332,7 @@ func CreateAppFromAirgap(opts CreateAirgapAppOpts) (finalError error) {
 	}
 
 	if !opts.SkipPreflights || hasStrictPreflights {
-		if err := preflight.Run(opts.PendingApp.ID, opts.PendingApp.Slug, newSequence, true, tmpRoot); err != nil {
+		if err := preflight.Run(opts.PendingApp.ID, opts.PendingApp.Slug, newSequence, true, preflighttypes.PreflightTriggerInstall, tmpRoot); err != nil {
 			return errors.Wrap(err, "failed to start preflights")
 		}
 	}
@@ -0,0 +1,436 @@
+package airgap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	containersmanifest "github.com/containers/image/v5/manifest"
+	containerstypes "github.com/containers/image/v5/types"
+	"github.com/distribution/distribution/v3/reference"
+	"github.com/mholt/archiver/v3"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	dockertypes "github.com/replicatedhq/kots/pkg/docker/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+// registryImage is the location of an image in the storage of the temp registry the airgap bundle images are served from
+type registryImage struct {
+	repo        string
+	tagOrDigest string
+}
+
+// CreateDelta writes a delta of the given airgap bundle to outputFile.
+// Images that the installed version already has, as recorded in its Installation, are omitted from the delta
+// along with the layers they share with the remaining images of any repository.
+// The shared layers are recorded in the delta so that they can be mounted from the omitted images' repositories when pushing.
+// Image tags are expected to be immutable, an image is omitted if the installed version has the same name and tag.
+// The checksum manifest of the delta is signed with the signing key, which is required if the full bundle has a checksum manifest.
+func CreateDelta(airgapBundle string, installation *kotsv1beta1.Installation, signingKey []byte, outputFile string) (*kotsv1beta1.AirgapDelta, error) {
+	if installation.Spec.VersionLabel == "" {
+		return nil, errors.New("installation does not have a version label")
+	}
+
+	airgap, err := kotsutil.FindAirgapMetaInBundle(airgapBundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find airgap meta")
+	}
+	if airgap.Spec.Delta != nil {
+		return nil, errors.Errorf("airgap bundle is already a delta of version %s", airgap.Spec.Delta.BaseVersionLabel)
+	}
+	if airgap.Spec.Format != dockertypes.FormatDockerRegistry {
+		return nil, errors.Errorf("delta bundles can only be created from bundles in the %q format", dockertypes.FormatDockerRegistry)
+	}
+
+	extractedBundle, err := ioutil.TempDir("", "kots-airgap-delta")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(extractedBundle)
+
+	tarGz := archiver.TarGz{
+		Tar: &archiver.Tar{
+			ImplicitTopLevelFolder: false,
+		},
+	}
+	if err := tarGz.Unarchive(airgapBundle, extractedBundle); err != nil {
+		return nil, errors.Wrap(err, "failed to unarchive airgap bundle")
+	}
+
+	manifestFile := filepath.Join(extractedBundle, integrity.ManifestFileName)
+	if _, err := os.Stat(manifestFile); err == nil {
+		if len(signingKey) == 0 {
+			return nil, errors.New("airgap bundle has a checksum manifest, a signing key is required to sign the checksum manifest of the delta")
+		}
+		if err := os.Remove(manifestFile); err != nil {
+			return nil, errors.Wrap(err, "failed to remove checksum manifest")
+		}
+	}
+
+	delta, err := removeInstalledImages(extractedBundle, airgap.Spec.SavedImages, installation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to remove installed images")
+	}
+
+	airgap.Spec.Delta = delta
+	b, err := kotsutil.EncodeAirgap(*airgap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode airgap meta")
+	}
+	if err := ioutil.WriteFile(filepath.Join(extractedBundle, "airgap.yaml"), b, 0644); err != nil {
+		return nil, errors.Wrap(err, "failed to write airgap meta")
+	}
+
+	if len(signingKey) > 0 {
+		manifest, err := integrity.GenerateManifest(extractedBundle, signingKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate checksum manifest")
+		}
+		if err := integrity.WriteManifest(extractedBundle, manifest); err != nil {
+			return nil, errors.Wrap(err, "failed to write checksum manifest")
+		}
+	}
+
+	paths, err := bundlePaths(extractedBundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bundle contents")
+	}
+	// the archiver requires a tar.gz extension, airgap bundles use .airgap
+	archiveFile := outputFile + ".tar.gz"
+	if err := tarGz.Archive(paths, archiveFile); err != nil {
+		os.Remove(archiveFile)
+		return nil, errors.Wrap(err, "failed to create delta bundle")
+	}
+	if err := os.Rename(archiveFile, outputFile); err != nil {
+		os.Remove(archiveFile)
+		return nil, errors.Wrap(err, "failed to rename delta bundle")
+	}
+
+	return delta, nil
+}
+
+// removeInstalledImages removes the tags and blobs of the images that the installation already has
+// from the registry storage of the extracted airgap bundle
+func removeInstalledImages(airgapRootDir string, savedImages []string, installation *kotsv1beta1.Installation) (*kotsv1beta1.AirgapDelta, error) {
+	storageDir := filepath.Join(airgapRootDir, "images", "docker", "registry", "v2")
+
+	installedImages, err := normalizedInstalledImages(installation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize installed images")
+	}
+
+	delta := &kotsv1beta1.AirgapDelta{
+		BaseVersionLabel: installation.Spec.VersionLabel,
+		OmittedImages:    []string{},
+	}
+
+	keptImages := []string{}
+	omittedImages := []string{}
+	registryImages := map[string]registryImage{}
+	for _, savedImage := range savedImages {
+		normalized, err := normalizeImage(savedImage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to normalize image %s", savedImage)
+		}
+		ri, err := getRegistryImage(savedImage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get registry location of image %s", savedImage)
+		}
+		registryImages[savedImage] = ri
+		if installedImages[normalized] {
+			omittedImages = append(omittedImages, savedImage)
+			delta.OmittedImages = append(delta.OmittedImages, savedImage)
+		} else {
+			keptImages = append(keptImages, savedImage)
+		}
+	}
+
+	// layers of omitted images are already in the destination registry, in the repository of the omitted image.
+	// they are omitted from every remaining image that has them, regardless of its repository, and are mounted when pushing.
+	omittedLayers := map[digest.Digest]string{}
+	for _, savedImage := range omittedImages {
+		_, layers, err := getImageBlobs(storageDir, registryImages[savedImage])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get blobs for image %s", savedImage)
+		}
+		for _, layer := range layers {
+			if _, ok := omittedLayers[layer.Digest]; !ok {
+				omittedLayers[layer.Digest] = savedImage
+			}
+		}
+	}
+
+	keptBlobs := map[digest.Digest]bool{}
+	sharedLayers := map[digest.Digest]bool{}
+	for _, savedImage := range keptImages {
+		manifests, layers, err := getImageBlobs(storageDir, registryImages[savedImage])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get blobs for image %s", savedImage)
+		}
+		for _, manifest := range manifests {
+			keptBlobs[manifest] = true
+		}
+		for _, layer := range layers {
+			omittedImage, ok := omittedLayers[layer.Digest]
+			if !ok {
+				keptBlobs[layer.Digest] = true
+				continue
+			}
+			if sharedLayers[layer.Digest] {
+				continue
+			}
+			sharedLayers[layer.Digest] = true
+			delta.SharedLayers = append(delta.SharedLayers, kotsv1beta1.AirgapDeltaLayer{
+				Digest:    layer.Digest.String(),
+				MediaType: layer.MediaType,
+				Image:     omittedImage,
+			})
+		}
+	}
+
+	for _, savedImage := range omittedImages {
+		ri := registryImages[savedImage]
+		if _, err := digest.Parse(ri.tagOrDigest); err == nil {
+			continue
+		}
+		tagDir := filepath.Join(storageDir, "repositories", ri.repo, "_manifests", "tags", ri.tagOrDigest)
+		if err := os.RemoveAll(tagDir); err != nil {
+			return nil, errors.Wrapf(err, "failed to remove tag %s", ri.tagOrDigest)
+		}
+	}
+
+	if err := removeBlobs(storageDir, keptBlobs); err != nil {
+		return nil, errors.Wrap(err, "failed to remove blobs")
+	}
+
+	return delta, nil
+}
+
+// getImageBlobs returns the manifest and config blobs and the layers of an image in the registry storage
+func getImageBlobs(storageDir string, ri registryImage) ([]digest.Digest, []containerstypes.BlobInfo, error) {
+	manifestDigest, err := digest.Parse(ri.tagOrDigest)
+	if err != nil {
+		link := filepath.Join(storageDir, "repositories", ri.repo, "_manifests", "tags", ri.tagOrDigest, "current", "link")
+		b, err := ioutil.ReadFile(link)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read tag %s", ri.tagOrDigest)
+		}
+		manifestDigest, err = digest.Parse(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse digest of tag %s", ri.tagOrDigest)
+		}
+	}
+
+	return getManifestBlobs(storageDir, manifestDigest)
+}
+
+func getManifestBlobs(storageDir string, manifestDigest digest.Digest) ([]digest.Digest, []containerstypes.BlobInfo, error) {
+	b, err := ioutil.ReadFile(blobDataPath(storageDir, manifestDigest))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read manifest %s", manifestDigest)
+	}
+
+	manifests := []digest.Digest{manifestDigest}
+	layers := []containerstypes.BlobInfo{}
+
+	mimeType := containersmanifest.GuessMIMEType(b)
+	if containersmanifest.MIMETypeIsMultiImage(mimeType) {
+		list, err := containersmanifest.ListFromBlob(b, mimeType)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse manifest list %s", manifestDigest)
+		}
+		for _, instance := range list.Instances() {
+			instanceManifests, instanceLayers, err := getManifestBlobs(storageDir, instance)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to get blobs for %s", instance)
+			}
+			manifests = append(manifests, instanceManifests...)
+			layers = append(layers, instanceLayers...)
+		}
+		return manifests, layers, nil
+	}
+
+	manifest, err := containersmanifest.FromBlob(b, mimeType)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse manifest %s", manifestDigest)
+	}
+	if config := manifest.ConfigInfo(); config.Digest != "" {
+		manifests = append(manifests, config.Digest)
+	}
+	for _, layer := range manifest.LayerInfos() {
+		layers = append(layers, layer.BlobInfo)
+	}
+
+	return manifests, layers, nil
+}
+
+// removeBlobs removes all blobs from the registry storage except for the ones to keep
+func removeBlobs(storageDir string, keep map[digest.Digest]bool) error {
+	blobDirs, err := filepath.Glob(filepath.Join(storageDir, "blobs", "*", "*", "*"))
+	if err != nil {
+		return errors.Wrap(err, "failed to list blobs")
+	}
+
+	for _, blobDir := range blobDirs {
+		hex := filepath.Base(blobDir)
+		algorithm := filepath.Base(filepath.Dir(filepath.Dir(blobDir)))
+		if keep[digest.NewDigestFromEncoded(digest.Algorithm(algorithm), hex)] {
+			continue
+		}
+		if err := os.RemoveAll(blobDir); err != nil {
+			return errors.Wrapf(err, "failed to remove blob %s", hex)
+		}
+	}
+
+	return nil
+}
+
+func blobDataPath(storageDir string, d digest.Digest) string {
+	hex := d.Encoded()
+	return filepath.Join(storageDir, "blobs", d.Algorithm().String(), hex[:2], hex, "data")
+}
+
+// getRegistryImage returns the repository and tag or digest the image is stored under, see TempRegistry.SrcRef
+func getRegistryImage(image string) (registryImage, error) {
+	imageRef, err := reference.ParseDockerRef(image)
+	if err != nil {
+		return registryImage{}, errors.Wrap(err, "failed to parse image")
+	}
+
+	tagOrDigest := "latest"
+	if can, ok := imageRef.(reference.Canonical); ok {
+		tagOrDigest = can.Digest().String()
+	} else if tagged, ok := imageRef.(reference.Tagged); ok {
+		tagOrDigest = tagged.Tag()
+	}
+
+	imageParts := strings.Split(reference.TrimNamed(imageRef).Name(), "/")
+
+	return registryImage{
+		repo:        imageParts[len(imageParts)-1],
+		tagOrDigest: tagOrDigest,
+	}, nil
+}
+
+func normalizeImage(image string) (string, error) {
+	imageRef, err := reference.ParseDockerRef(image)
+	if err != nil {
+		return "", err
+	}
+	return imageRef.String(), nil
+}
+
+func normalizedInstalledImages(installation *kotsv1beta1.Installation) (map[string]bool, error) {
+	installedImages := map[string]bool{}
+	for _, knownImage := range installation.Spec.KnownImages {
+		normalized, err := normalizeImage(knownImage.Image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to normalize image %s", knownImage.Image)
+		}
+		installedImages[normalized] = true
+	}
+	return installedImages, nil
+}
+
+// bundlePaths lists the top level files of the bundle before the directories,
+// since metadata is read from the beginning of the archive until the first directory
+func bundlePaths(airgapRootDir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(airgapRootDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read dir")
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return !entries[i].IsDir() && entries[j].IsDir()
+	})
+
+	paths := []string{}
+	for _, entry := range entries {
+		paths = append(paths, filepath.Join(airgapRootDir, entry.Name()))
+	}
+	return paths, nil
+}
+
+// checkDeltaBase returns an error if the delta bundle was not built against the given installation
+func checkDeltaBase(delta *kotsv1beta1.AirgapDelta, installation *kotsv1beta1.Installation) error {
+	if delta.BaseVersionLabel != installation.Spec.VersionLabel {
+		return errors.Errorf("it was built for version %s, but version %s is deployed", delta.BaseVersionLabel, installation.Spec.VersionLabel)
+	}
+
+	installedImages, err := normalizedInstalledImages(installation)
+	if err != nil {
+		return errors.Wrap(err, "failed to normalize installed images")
+	}
+
+	missingImages := []string{}
+	for _, omittedImage := range delta.OmittedImages {
+		normalized, err := normalizeImage(omittedImage)
+		if err != nil {
+			return errors.Wrapf(err, "failed to normalize image %s", omittedImage)
+		}
+		if !installedImages[normalized] {
+			missingImages = append(missingImages, omittedImage)
+		}
+	}
+	if len(missingImages) > 0 {
+		return errors.Errorf("images %s are not in the deployed version", strings.Join(missingImages, ", "))
+	}
+
+	return nil
+}
+
+// VerifyDeltaBase makes sure the delta bundle applies on top of the currently deployed version,
+// since the images omitted from the delta are expected to already be in the registry
+func VerifyDeltaBase(a *apptypes.App, delta *kotsv1beta1.AirgapDelta) error {
+	downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list downstreams for app")
+	}
+	if len(downstreams) == 0 {
+		return errors.New("no downstreams found for app")
+	}
+
+	currentVersion, err := store.GetStore().GetCurrentDownstreamVersion(a.ID, downstreams[0].ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current downstream version")
+	}
+	if currentVersion == nil {
+		return util.ActionableError{
+			NoRetry: true,
+			Message: fmt.Sprintf("This delta airgap bundle cannot be uploaded because it was built for version %s and no version is deployed.", delta.BaseVersionLabel),
+		}
+	}
+
+	archiveDir, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(archiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, currentVersion.ParentSequence, archiveDir); err != nil {
+		return errors.Wrap(err, "failed to get current app version archive")
+	}
+
+	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archiveDir, "upstream"))
+	if err != nil {
+		return errors.Wrap(err, "failed to load current kotskinds")
+	}
+
+	if err := checkDeltaBase(delta, &kotsKinds.Installation); err != nil {
+		return util.ActionableError{
+			NoRetry: true,
+			Message: fmt.Sprintf("This delta airgap bundle cannot be uploaded because %s.", err.Error()),
+		}
+	}
+
+	return nil
+}
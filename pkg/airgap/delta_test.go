@@ -0,0 +1,228 @@
+package airgap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/opencontainers/go-digest"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkDeltaBase(t *testing.T) {
+	installation := &kotsv1beta1.Installation{
+		Spec: kotsv1beta1.InstallationSpec{
+			VersionLabel: "1.0.0",
+			KnownImages: []kotsv1beta1.InstallationImage{
+				{Image: "registry.replicated.com/app/web:1.0", IsPrivate: true},
+				{Image: "docker.io/library/nginx:1.25", IsPrivate: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		delta   *kotsv1beta1.AirgapDelta
+		wantErr string
+	}{
+		{
+			name: "base matches",
+			delta: &kotsv1beta1.AirgapDelta{
+				BaseVersionLabel: "1.0.0",
+				OmittedImages:    []string{"registry.replicated.com/app/web:1.0", "nginx:1.25"},
+			},
+		},
+		{
+			name: "different version deployed",
+			delta: &kotsv1beta1.AirgapDelta{
+				BaseVersionLabel: "0.9.0",
+				OmittedImages:    []string{"registry.replicated.com/app/web:1.0"},
+			},
+			wantErr: "it was built for version 0.9.0, but version 1.0.0 is deployed",
+		},
+		{
+			name: "omitted image not in deployed version",
+			delta: &kotsv1beta1.AirgapDelta{
+				BaseVersionLabel: "1.0.0",
+				OmittedImages:    []string{"registry.replicated.com/app/web:1.0", "registry.replicated.com/app/worker:1.0"},
+			},
+			wantErr: "images registry.replicated.com/app/worker:1.0 are not in the deployed version",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkDeltaBase(test.delta, installation)
+			if test.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func Test_removeInstalledImages(t *testing.T) {
+	airgapRootDir := t.TempDir()
+	storageDir := filepath.Join(airgapRootDir, "images", "docker", "registry", "v2")
+
+	sharedLayer := writeBlob(t, storageDir, "shared layer")
+	baseLayer := writeBlob(t, storageDir, "base layer shared with another repo")
+	oldLayer := writeBlob(t, storageDir, "old layer")
+	newLayer := writeBlob(t, storageDir, "new layer")
+	workerLayer := writeBlob(t, storageDir, "worker layer")
+
+	oldConfig := writeBlob(t, storageDir, "old config")
+	newConfig := writeBlob(t, storageDir, "new config")
+	workerConfig := writeBlob(t, storageDir, "worker config")
+
+	oldManifest := writeManifest(t, storageDir, "web", "1.0", oldConfig, baseLayer, sharedLayer, oldLayer)
+	newManifest := writeManifest(t, storageDir, "web", "1.1", newConfig, baseLayer, sharedLayer, newLayer)
+	workerManifest := writeManifest(t, storageDir, "worker", "1.0", workerConfig, baseLayer, workerLayer)
+
+	installation := &kotsv1beta1.Installation{
+		Spec: kotsv1beta1.InstallationSpec{
+			VersionLabel: "1.0.0",
+			KnownImages: []kotsv1beta1.InstallationImage{
+				{Image: "registry.replicated.com/app/web:1.0", IsPrivate: true},
+			},
+		},
+	}
+	savedImages := []string{
+		"registry.replicated.com/app/web:1.0",
+		"registry.replicated.com/app/web:1.1",
+		"registry.replicated.com/app/worker:1.0",
+	}
+
+	delta, err := removeInstalledImages(airgapRootDir, savedImages, installation)
+	require.NoError(t, err)
+
+	assert.Equal(t, &kotsv1beta1.AirgapDelta{
+		BaseVersionLabel: "1.0.0",
+		OmittedImages:    []string{"registry.replicated.com/app/web:1.0"},
+		SharedLayers: []kotsv1beta1.AirgapDeltaLayer{
+			{Digest: baseLayer.String(), MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Image: "registry.replicated.com/app/web:1.0"},
+			{Digest: sharedLayer.String(), MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Image: "registry.replicated.com/app/web:1.0"},
+		},
+	}, delta)
+
+	assert.NoDirExists(t, filepath.Join(storageDir, "repositories", "web", "_manifests", "tags", "1.0"))
+	assert.DirExists(t, filepath.Join(storageDir, "repositories", "web", "_manifests", "tags", "1.1"))
+
+	// the base layer is omitted from the worker image too, although the worker image is in another repo
+	for _, removed := range []digest.Digest{oldManifest, oldConfig, oldLayer, sharedLayer, baseLayer} {
+		assert.NoFileExists(t, blobDataPath(storageDir, removed), removed)
+	}
+	for _, kept := range []digest.Digest{newManifest, newConfig, newLayer, workerManifest, workerConfig, workerLayer} {
+		assert.FileExists(t, blobDataPath(storageDir, kept), kept)
+	}
+}
+
+func Test_CreateDelta(t *testing.T) {
+	airgapRootDir := t.TempDir()
+	storageDir := filepath.Join(airgapRootDir, "images", "docker", "registry", "v2")
+
+	oldLayer := writeBlob(t, storageDir, "old layer")
+	newLayer := writeBlob(t, storageDir, "new layer")
+	writeManifest(t, storageDir, "web", "1.0", writeBlob(t, storageDir, "old config"), oldLayer)
+	writeManifest(t, storageDir, "web", "1.1", writeBlob(t, storageDir, "new config"), oldLayer, newLayer)
+
+	airgapYAML := `apiVersion: kots.io/v1beta1
+kind: Airgap
+spec:
+  versionLabel: 1.1.0
+  format: docker
+  savedImages:
+  - registry.replicated.com/app/web:1.0
+  - registry.replicated.com/app/web:1.1
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRootDir, "airgap.yaml"), []byte(airgapYAML), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRootDir, "app.tar.gz"), []byte("app release"), 0644))
+
+	paths, err := bundlePaths(airgapRootDir)
+	require.NoError(t, err)
+	airgapBundle := filepath.Join(t.TempDir(), "app.tar.gz")
+	tarGz := archiver.TarGz{
+		Tar: &archiver.Tar{
+			ImplicitTopLevelFolder: false,
+		},
+	}
+	require.NoError(t, tarGz.Archive(paths, airgapBundle))
+
+	installation := &kotsv1beta1.Installation{
+		Spec: kotsv1beta1.InstallationSpec{
+			VersionLabel: "1.0.0",
+			KnownImages: []kotsv1beta1.InstallationImage{
+				{Image: "registry.replicated.com/app/web:1.0", IsPrivate: true},
+			},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "delta.airgap")
+	delta, err := CreateDelta(airgapBundle, installation, nil, outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry.replicated.com/app/web:1.0"}, delta.OmittedImages)
+
+	airgap, err := kotsutil.FindAirgapMetaInBundle(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, delta, airgap.Spec.Delta)
+	assert.Equal(t, []string{"registry.replicated.com/app/web:1.0", "registry.replicated.com/app/web:1.1"}, airgap.Spec.SavedImages)
+
+	// full bundles with a checksum manifest need a signing key for the delta
+	require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRootDir, integrity.ManifestFileName), []byte("{}"), 0644))
+	paths, err = bundlePaths(airgapRootDir)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(airgapBundle))
+	require.NoError(t, tarGz.Archive(paths, airgapBundle))
+
+	_, err = CreateDelta(airgapBundle, installation, nil, filepath.Join(t.TempDir(), "delta.airgap"))
+	require.Error(t, err)
+}
+
+func Test_bundlePaths(t *testing.T) {
+	airgapRootDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(airgapRootDir, "images"), 0755))
+	for _, name := range []string{"airgap.yaml", "app.tar.gz"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRootDir, name), []byte(name), 0644))
+	}
+
+	paths, err := bundlePaths(airgapRootDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(airgapRootDir, "airgap.yaml"),
+		filepath.Join(airgapRootDir, "app.tar.gz"),
+		filepath.Join(airgapRootDir, "images"),
+	}, paths)
+}
+
+func writeBlob(t *testing.T, storageDir string, content string) digest.Digest {
+	d := digest.FromString(content)
+	dataPath := blobDataPath(storageDir, d)
+	require.NoError(t, os.MkdirAll(filepath.Dir(dataPath), 0755))
+	require.NoError(t, ioutil.WriteFile(dataPath, []byte(content), 0644))
+	return d
+}
+
+func writeManifest(t *testing.T, storageDir string, repo string, tag string, config digest.Digest, layers ...digest.Digest) digest.Digest {
+	layersJSON := ""
+	for i, layer := range layers {
+		if i > 0 {
+			layersJSON += ","
+		}
+		layersJSON += fmt.Sprintf(`{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","size":1,"digest":"%s"}`, layer)
+	}
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"mediaType":"application/vnd.docker.container.image.v1+json","size":1,"digest":"%s"},"layers":[%s]}`, config, layersJSON)
+	d := writeBlob(t, storageDir, manifest)
+
+	linkPath := filepath.Join(storageDir, "repositories", repo, "_manifests", "tags", tag, "current", "link")
+	require.NoError(t, os.MkdirAll(filepath.Dir(linkPath), 0755))
+	require.NoError(t, ioutil.WriteFile(linkPath, []byte(d.String()), 0644))
+
+	return d
+}
@@ -0,0 +1,384 @@
+package integrity
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	kotslicense "github.com/replicatedhq/kots/pkg/license"
+)
+
+// ManifestFileName is the name of the checksum manifest at the root of the airgap bundle.
+// It's written before the images so that it can be read along with the rest of the bundle metadata.
+const ManifestFileName = "checksums.json"
+
+// imagesDir is the dir in the airgap bundle that contains the image layers
+const imagesDir = "images"
+
+var ErrManifestMissing = errors.New("checksum manifest is missing")
+
+// Manifest lists the sha256 checksums of all files in the airgap bundle, including airgap.yaml, the app release and every image layer.
+// The checksums are signed with the app private key (RSA-PSS over a SHA-256 digest) so that they can be verified with the public key in the license.
+type Manifest struct {
+	Checksums map[string]string `json:"checksums"`
+	Signature []byte            `json:"signature,omitempty"`
+}
+
+// IntegrityError names the entry of the airgap bundle that failed verification
+type IntegrityError struct {
+	Entry  string
+	Reason string
+}
+
+func (e IntegrityError) Error() string {
+	return fmt.Sprintf("%s %s", e.Entry, e.Reason)
+}
+
+// GenerateManifest computes the checksums of all files in the extracted airgap bundle.
+// The manifest is signed if a private key is provided.
+func GenerateManifest(airgapRootDir string, privateKeyPEM []byte) (*Manifest, error) {
+	manifest := &Manifest{
+		Checksums: map[string]string{},
+	}
+
+	err := filepath.Walk(airgapRootDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(airgapRootDir, filePath)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		entry := filepath.ToSlash(relPath)
+		if entry == ManifestFileName {
+			return nil
+		}
+
+		checksum, err := fileChecksum(filePath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get checksum of %s", entry)
+		}
+		manifest.Checksums[entry] = checksum
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to walk airgap dir")
+	}
+
+	if len(privateKeyPEM) > 0 {
+		message, err := manifest.message()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get manifest message")
+		}
+		signature, err := sign(message, privateKeyPEM)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sign manifest")
+		}
+		manifest.Signature = signature
+	}
+
+	return manifest, nil
+}
+
+// WriteManifest writes the checksum manifest to the root of the extracted airgap bundle
+func WriteManifest(airgapRootDir string, manifest *Manifest) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal manifest")
+	}
+	if err := ioutil.WriteFile(filepath.Join(airgapRootDir, ManifestFileName), b, 0644); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+	return nil
+}
+
+// VerifyBundle verifies every entry of the airgap bundle against the checksum manifest in it.
+// The manifest signature is verified with the app public key in the license, unless the license is nil.
+func VerifyBundle(airgapBundle string, license *kotsv1beta1.License) (*Manifest, error) {
+	f, err := os.Open(airgapBundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open airgap bundle")
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get new gzip reader")
+	}
+	defer gzipReader.Close()
+
+	var manifest *Manifest
+	checksums := map[string]string{}
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read airgap bundle")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		entry := entryName(header.Name)
+		if entry == ManifestFileName {
+			manifest, err = readManifest(tarReader)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read checksum manifest")
+			}
+			continue
+		}
+
+		h := sha256.New()
+		if _, err := io.Copy(h, tarReader); err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", entry)
+		}
+		checksums[entry] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if manifest == nil {
+		return nil, ErrManifestMissing
+	}
+
+	if err := verifyManifestSignature(manifest, license); err != nil {
+		return nil, err
+	}
+
+	if err := compareChecksums(manifest, checksums, true); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// IsManifestRequired returns true if the license requires airgap bundles to have a checksum manifest.
+// Otherwise, bundles without one are accepted with a warning.
+func IsManifestRequired(license *kotsv1beta1.License) bool {
+	return license != nil && license.Spec.IsAirgapIntegrityRequired
+}
+
+// VerifyDir verifies the files in the airgap dir against the checksum manifest in it.
+// The images are pushed from the CLI host, which verifies them, and are not uploaded with the rest of the bundle,
+// so image entries that aren't in the dir are not reported. Every other entry in the manifest must be there.
+func VerifyDir(airgapRootDir string, license *kotsv1beta1.License) (*Manifest, error) {
+	f, err := os.Open(filepath.Join(airgapRootDir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return nil, ErrManifestMissing
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open checksum manifest")
+	}
+	defer f.Close()
+
+	manifest, err := readManifest(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read checksum manifest")
+	}
+
+	if err := verifyManifestSignature(manifest, license); err != nil {
+		return nil, err
+	}
+
+	checksums := map[string]string{}
+	for entry := range manifest.Checksums {
+		filePath := filepath.Join(airgapRootDir, filepath.FromSlash(entry))
+		if _, err := os.Stat(filePath); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "failed to stat %s", entry)
+			}
+			if strings.HasPrefix(entry, imagesDir+"/") {
+				continue
+			}
+			return nil, IntegrityError{Entry: entry, Reason: "is missing"}
+		}
+		checksum, err := fileChecksum(filePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get checksum of %s", entry)
+		}
+		checksums[entry] = checksum
+	}
+
+	if err := compareChecksums(manifest, checksums, false); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func verifyManifestSignature(manifest *Manifest, license *kotsv1beta1.License) error {
+	if license == nil {
+		return nil
+	}
+	if len(manifest.Signature) == 0 {
+		return IntegrityError{Entry: ManifestFileName, Reason: "is not signed"}
+	}
+
+	publicKey, err := kotslicense.GetAppPublicKey(license)
+	if err != nil {
+		return errors.Wrap(err, "failed to get public key from license")
+	}
+
+	message, err := manifest.message()
+	if err != nil {
+		return errors.Wrap(err, "failed to get manifest message")
+	}
+
+	if err := verify(message, manifest.Signature, publicKey); err != nil {
+		return IntegrityError{Entry: ManifestFileName, Reason: "has an invalid signature"}
+	}
+
+	return nil
+}
+
+// compareChecksums returns an error for the first entry, in sorted order, that doesn't match the manifest.
+// Entries that aren't in the manifest are only reported if the checksums cover the whole bundle.
+func compareChecksums(manifest *Manifest, checksums map[string]string, complete bool) error {
+	entries := []string{}
+	for entry := range manifest.Checksums {
+		entries = append(entries, entry)
+	}
+	for entry := range checksums {
+		if _, ok := manifest.Checksums[entry]; !ok {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Strings(entries)
+
+	for _, entry := range entries {
+		expected, inManifest := manifest.Checksums[entry]
+		actual, inBundle := checksums[entry]
+		switch {
+		case !inManifest:
+			if complete {
+				return IntegrityError{Entry: entry, Reason: "is not in the checksum manifest"}
+			}
+		case !inBundle:
+			if complete {
+				return IntegrityError{Entry: entry, Reason: "is missing"}
+			}
+		case expected != actual:
+			return IntegrityError{Entry: entry, Reason: fmt.Sprintf("is corrupted: expected sha256 %s, got %s", expected, actual)}
+		}
+	}
+
+	return nil
+}
+
+// message is the signed content of the manifest, json marshalling sorts the map keys
+func (m *Manifest) message() ([]byte, error) {
+	return json.Marshal(m.Checksums)
+}
+
+func readManifest(r io.Reader) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to decode manifest")
+	}
+	return manifest, nil
+}
+
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open file")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "failed to read file")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func entryName(name string) string {
+	return path.Clean(strings.TrimPrefix(name, "./"))
+}
+
+// sign signs the sha256 digest of the message with RSA-PSS
+func sign(message []byte, privateKeyPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode private key PEM")
+	}
+
+	var privateKey *rsa.PrivateKey
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		privateKey = key
+	} else {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse private key")
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an RSA key")
+		}
+		privateKey = rsaKey
+	}
+
+	var opts rsa.PSSOptions
+	opts.SaltLength = rsa.PSSSaltLengthAuto
+
+	hashed := sha256.Sum256(message)
+
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, crypto.SHA256, hashed[:], &opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	return signature, nil
+}
+
+// verify is the counterpart of sign
+func verify(message []byte, signature []byte, publicKeyPEM []byte) error {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return errors.New("failed to decode public key PEM")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse public key")
+	}
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("public key is not an RSA key")
+	}
+
+	var opts rsa.PSSOptions
+	opts.SaltLength = rsa.PSSSaltLengthAuto
+
+	hashed := sha256.Sum256(message)
+
+	if err := rsa.VerifyPSS(rsaKey, crypto.SHA256, hashed[:], signature, &opts); err != nil {
+		return errors.Wrap(err, "failed to verify signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package integrity
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver/v3"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	kotslicense "github.com/replicatedhq/kots/pkg/license"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerifyBundle(t *testing.T) {
+	privateKey, license := testKeyAndLicense(t)
+	otherPrivateKey, _ := testKeyAndLicense(t)
+
+	layer := "images/docker/registry/v2/blobs/sha256/ab/abcd/data"
+
+	tests := []struct {
+		name       string
+		signingKey []byte
+		license    *kotsv1beta1.License
+		// modify changes the bundle after the manifest was generated
+		modify  func(t *testing.T, airgapRootDir string)
+		wantErr string
+	}{
+		{
+			name:       "valid bundle",
+			signingKey: privateKey,
+			license:    license,
+		},
+		{
+			name:    "unsigned manifest without license",
+			license: nil,
+		},
+		{
+			name: "corrupted layer",
+			modify: func(t *testing.T, airgapRootDir string) {
+				writeFile(t, airgapRootDir, layer, "corrupted")
+			},
+			signingKey: privateKey,
+			license:    license,
+			wantErr:    layer + " is corrupted: expected sha256 " + sha256Hex("layer") + ", got " + sha256Hex("corrupted"),
+		},
+		{
+			name: "missing app release",
+			modify: func(t *testing.T, airgapRootDir string) {
+				require.NoError(t, os.Remove(filepath.Join(airgapRootDir, "app.tar.gz")))
+			},
+			signingKey: privateKey,
+			license:    license,
+			wantErr:    "app.tar.gz is missing",
+		},
+		{
+			name: "unexpected entry",
+			modify: func(t *testing.T, airgapRootDir string) {
+				writeFile(t, airgapRootDir, "images/extra", "extra")
+			},
+			signingKey: privateKey,
+			license:    license,
+			wantErr:    "images/extra is not in the checksum manifest",
+		},
+		{
+			name:       "signed with another key",
+			signingKey: otherPrivateKey,
+			license:    license,
+			wantErr:    "checksums.json has an invalid signature",
+		},
+		{
+			name:    "unsigned manifest with license",
+			license: license,
+			wantErr: "checksums.json is not signed",
+		},
+		{
+			name: "no manifest",
+			modify: func(t *testing.T, airgapRootDir string) {
+				require.NoError(t, os.Remove(filepath.Join(airgapRootDir, ManifestFileName)))
+			},
+			license: license,
+			wantErr: "checksum manifest is missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			airgapRootDir := t.TempDir()
+			writeFile(t, airgapRootDir, "airgap.yaml", "apiVersion: kots.io/v1beta1\nkind: Airgap\n")
+			writeFile(t, airgapRootDir, "app.tar.gz", "app release")
+			writeFile(t, airgapRootDir, layer, "layer")
+
+			manifest, err := GenerateManifest(airgapRootDir, test.signingKey)
+			require.NoError(t, err)
+			require.NoError(t, WriteManifest(airgapRootDir, manifest))
+
+			if test.modify != nil {
+				test.modify(t, airgapRootDir)
+			}
+
+			airgapBundle := filepath.Join(t.TempDir(), "app.tar.gz")
+			archiveDir(t, airgapRootDir, airgapBundle)
+
+			got, err := VerifyBundle(airgapBundle, test.license)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, got.Checksums, 3)
+		})
+	}
+}
+
+func Test_VerifyDir(t *testing.T) {
+	privateKey, license := testKeyAndLicense(t)
+
+	airgapRootDir := t.TempDir()
+	writeFile(t, airgapRootDir, "airgap.yaml", "apiVersion: kots.io/v1beta1\nkind: Airgap\n")
+	writeFile(t, airgapRootDir, "app.tar.gz", "app release")
+	writeFile(t, airgapRootDir, "images/docker/registry/v2/blobs/sha256/ab/abcd/data", "layer")
+	writeFile(t, airgapRootDir, "support/preflight.yaml", "apiVersion: troubleshoot.sh/v1beta2\nkind: Preflight\n")
+
+	manifest, err := GenerateManifest(airgapRootDir, privateKey)
+	require.NoError(t, err)
+	require.NoError(t, WriteManifest(airgapRootDir, manifest))
+
+	// images were pushed from the cli host and only the metadata was uploaded
+	require.NoError(t, os.RemoveAll(filepath.Join(airgapRootDir, "images")))
+	writeFile(t, airgapRootDir, "images.json", "[]")
+
+	_, err = VerifyDir(airgapRootDir, license)
+	require.NoError(t, err)
+
+	// any entry other than the images is required
+	require.NoError(t, os.Remove(filepath.Join(airgapRootDir, "support", "preflight.yaml")))
+	_, err = VerifyDir(airgapRootDir, license)
+	assert.Equal(t, IntegrityError{Entry: "support/preflight.yaml", Reason: "is missing"}, err)
+	writeFile(t, airgapRootDir, "support/preflight.yaml", "apiVersion: troubleshoot.sh/v1beta2\nkind: Preflight\n")
+
+	writeFile(t, airgapRootDir, "airgap.yaml", "apiVersion: kots.io/v1beta1\nkind: Airgap\nspec: {}\n")
+	_, err = VerifyDir(airgapRootDir, license)
+	require.Error(t, err)
+	assert.Equal(t, "airgap.yaml", err.(IntegrityError).Entry)
+
+	require.NoError(t, os.Remove(filepath.Join(airgapRootDir, "airgap.yaml")))
+	_, err = VerifyDir(airgapRootDir, license)
+	assert.Equal(t, IntegrityError{Entry: "airgap.yaml", Reason: "is missing"}, err)
+}
+
+// testKeyAndLicense returns an app private key and a license with the matching public key
+func testKeyAndLicense(t *testing.T) ([]byte, *kotsv1beta1.License) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+
+	innerSignature, err := json.Marshal(kotslicense.InnerSignature{PublicKey: string(publicKeyPEM)})
+	require.NoError(t, err)
+	outerSignature, err := json.Marshal(kotslicense.OuterSignature{InnerSignature: innerSignature})
+	require.NoError(t, err)
+
+	return privateKeyPEM, &kotsv1beta1.License{
+		Spec: kotsv1beta1.LicenseSpec{
+			Signature: outerSignature,
+		},
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFile(t *testing.T, root string, name string, content string) {
+	filePath := filepath.Join(root, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+}
+
+func archiveDir(t *testing.T, dir string, archivePath string) {
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, entry := range entries {
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	tarGz := archiver.TarGz{
+		Tar: &archiver.Tar{
+			ImplicitTopLevelFolder: false,
+		},
+	}
+	require.NoError(t, tarGz.Archive(paths, archivePath))
+}
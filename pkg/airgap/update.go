@@ -18,6 +18,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/pull"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/store"
@@ -27,7 +28,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/version"
 )
 
-func UpdateAppFromAirgap(a *apptypes.App, airgapBundlePath string, deploy bool, skipPreflights bool, skipCompatibilityCheck bool) (finalError error) {
+func UpdateAppFromAirgap(a *apptypes.App, airgapBundlePath string, deploy bool, skipPreflights bool, skipCompatibilityCheck bool, skipIntegrityCheck bool) (finalError error) {
 	finishedChan := make(chan error)
 	defer close(finishedChan)
 
@@ -46,7 +47,7 @@ func UpdateAppFromAirgap(a *apptypes.App, airgapBundlePath string, deploy bool,
 	}
 	defer os.RemoveAll(airgapRoot)
 
-	err = UpdateAppFromPath(a, airgapRoot, airgapBundlePath, deploy, skipPreflights, skipCompatibilityCheck)
+	err = UpdateAppFromPath(a, airgapRoot, airgapBundlePath, deploy, skipPreflights, skipCompatibilityCheck, skipIntegrityCheck)
 	if err != nil {
 		return errors.Wrap(err, "failed to update app")
 	}
@@ -54,7 +55,7 @@ func UpdateAppFromAirgap(a *apptypes.App, airgapBundlePath string, deploy bool,
 	return nil
 }
 
-func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath string, deploy bool, skipPreflights bool, skipCompatibilityCheck bool) error {
+func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath string, deploy bool, skipPreflights bool, skipCompatibilityCheck bool, skipIntegrityCheck bool) error {
 	if err := store.GetStore().SetTaskStatus("update-download", "Processing package...", "running"); err != nil {
 		return errors.Wrap(err, "failed to set tasks status")
 	}
@@ -69,6 +70,20 @@ func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath stri
 		return errors.Wrap(err, "failed to find airgap meta")
 	}
 
+	// Using license from db instead of upstream bundle because the one in db has not been re-marshalled
+	license, err := kotsutil.LoadLicenseFromBytes([]byte(a.License))
+	if err != nil {
+		return errors.Wrap(err, "failed parse license")
+	}
+
+	if err := store.GetStore().SetTaskStatus("update-download", "Verifying package...", "running"); err != nil {
+		return errors.Wrap(err, "failed to set task status")
+	}
+
+	if err := verifyIntegrity(airgapRoot, airgapBundlePath, license, skipIntegrityCheck); err != nil {
+		return errors.Wrap(err, "failed to verify airgap bundle")
+	}
+
 	missingPrereqs, err := GetMissingRequiredVersions(a, airgap)
 	if err != nil {
 		return errors.Wrapf(err, "failed to check required versions")
@@ -81,6 +96,12 @@ func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath stri
 		}
 	}
 
+	if airgap.Spec.Delta != nil {
+		if err := VerifyDeltaBase(a, airgap.Spec.Delta); err != nil {
+			return errors.Wrap(err, "failed to verify delta base")
+		}
+	}
+
 	archiveDir, baseSequence, err := store.GetStore().GetAppVersionBaseArchive(a.ID, airgap.Spec.VersionLabel)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get base archive dir for version %s", airgap.Spec.VersionLabel)
@@ -123,12 +144,6 @@ func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath stri
 		pipeReader.CloseWithError(scanner.Err())
 	}()
 
-	// Using license from db instead of upstream bundle because the one in db has not been re-marshalled
-	license, err := kotsutil.LoadLicenseFromBytes([]byte(a.License))
-	if err != nil {
-		return errors.Wrap(err, "failed parse license")
-	}
-
 	identityConfigFile := filepath.Join(archiveDir, "upstream", "userdata", "identityconfig.yaml")
 	if _, err := os.Stat(identityConfigFile); os.IsNotExist(err) {
 		file, err := identity.InitAppIdentityConfig(a.Slug)
@@ -198,7 +213,7 @@ func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath stri
 	}
 
 	if !skipPreflights || hasStrictPreflights {
-		if err := preflight.Run(a.ID, a.Slug, newSequence, true, archiveDir); err != nil {
+		if err := preflight.Run(a.ID, a.Slug, newSequence, true, preflighttypes.PreflightTriggerUpdate, archiveDir); err != nil {
 			return errors.Wrap(err, "failed to start preflights")
 		}
 	}
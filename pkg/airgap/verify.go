@@ -0,0 +1,52 @@
+package airgap
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+// verifyIntegrity verifies the airgap bundle against its signed checksum manifest.
+// When there's no bundle, only the files in the airgap dir are verified since the images were pushed from the CLI host.
+// Bundles without a checksum manifest are only rejected if the license requires them to be verified,
+// since bundles built before checksum manifests were introduced don't have one.
+func verifyIntegrity(airgapRoot string, airgapBundlePath string, license *kotsv1beta1.License, skipIntegrityCheck bool) error {
+	if skipIntegrityCheck {
+		logger.Warnf("WARNING: airgap bundle integrity check was skipped, the bundle has not been verified and may have been tampered with")
+		return nil
+	}
+
+	var err error
+	if airgapBundlePath != "" {
+		_, err = integrity.VerifyBundle(airgapBundlePath, license)
+	} else {
+		_, err = integrity.VerifyDir(airgapRoot, license)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if errors.Cause(err) == integrity.ErrManifestMissing {
+		if integrity.IsManifestRequired(license) {
+			return util.ActionableError{
+				NoRetry: true,
+				Message: "Failed to verify airgap bundle integrity: the bundle does not have a checksum manifest, which is required by the license.",
+			}
+		}
+		logger.Warnf("WARNING: airgap bundle does not have a checksum manifest, the bundle has not been verified")
+		return nil
+	}
+
+	if integrityErr, ok := errors.Cause(err).(integrity.IntegrityError); ok {
+		return util.ActionableError{
+			NoRetry: true,
+			Message: fmt.Sprintf("Failed to verify airgap bundle integrity: %s", integrityErr.Error()),
+		}
+	}
+
+	return errors.Wrap(err, "failed to verify airgap bundle integrity")
+}
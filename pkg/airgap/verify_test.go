@@ -0,0 +1,49 @@
+package airgap
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_verifyIntegrity(t *testing.T) {
+	tests := []struct {
+		name               string
+		license            *kotsv1beta1.License
+		skipIntegrityCheck bool
+		wantErr            bool
+	}{
+		{
+			name: "missing checksum manifest is allowed",
+		},
+		{
+			name:    "missing checksum manifest is rejected when the license requires it",
+			license: &kotsv1beta1.License{Spec: kotsv1beta1.LicenseSpec{IsAirgapIntegrityRequired: true}},
+			wantErr: true,
+		},
+		{
+			name:               "integrity check is skipped",
+			skipIntegrityCheck: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			airgapRoot := t.TempDir()
+			require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRoot, "airgap.yaml"), []byte("apiVersion: kots.io/v1beta1\nkind: Airgap\n"), 0644))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(airgapRoot, "app.tar.gz"), []byte("app release"), 0644))
+
+			err := verifyIntegrity(airgapRoot, "", test.license, test.skipIntegrityCheck)
+			if test.wantErr {
+				require.Error(t, err)
+				assert.IsType(t, util.ActionableError{}, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
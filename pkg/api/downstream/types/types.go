@@ -13,12 +13,15 @@ import (
 )
 
 type Downstream struct {
-	ClusterID        string `json:"id"`
-	ClusterSlug      string `json:"slug"`
-	Name             string `json:"name"`
-	CurrentSequence  int64  `json:"currentSequence"`
-	SnapshotSchedule string `json:"snapshotSchedule,omitempty"`
-	SnapshotTTL      string `json:"snapshotTtl,omitempty"`
+	ClusterID               string `json:"id"`
+	ClusterSlug             string `json:"slug"`
+	Name                    string `json:"name"`
+	CurrentSequence         int64  `json:"currentSequence"`
+	SnapshotSchedule        string `json:"snapshotSchedule,omitempty"`
+	SnapshotTTL             string `json:"snapshotTtl,omitempty"`
+	RestoreDrillSchedule    string `json:"restoreDrillSchedule,omitempty"`
+	SnapshotStorageLocation string `json:"snapshotStorageLocation,omitempty"`
+	SnapshotRetention       string `json:"snapshotRetention,omitempty"`
 }
 
 type DownstreamVersion struct {
@@ -29,6 +32,7 @@ type DownstreamVersion struct {
 	ChannelID          string                             `json:"channelId,omitempty"`
 	IsRequired         bool                               `json:"isRequired"`
 	Status             storetypes.DownstreamVersionStatus `json:"status"`
+	StatusInfo         string                             `json:"statusInfo,omitempty"`
 	CreatedOn          *time.Time                         `json:"createdOn,omitempty"`
 	ParentSequence     int64                              `json:"parentSequence"`
 	Sequence           int64                              `json:"sequence"`
@@ -7,6 +7,7 @@ import (
 	versiontypes "github.com/replicatedhq/kots/pkg/api/version/types"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
 )
 
 type ListAppsResponse struct {
@@ -74,18 +75,22 @@ type ResponseDownstream struct {
 }
 
 type ResponseGitOps struct {
-	Enabled     bool   `json:"enabled"`
-	Provider    string `json:"provider"`
-	Uri         string `json:"uri"`
-	Hostname    string `json:"hostname"`
-	HTTPPort    string `json:"httpPort"`
-	SSHPort     string `json:"sshPort"`
-	Path        string `json:"path"`
-	Branch      string `json:"branch"`
-	Format      string `json:"format"`
-	Action      string `json:"action"`
-	DeployKey   string `json:"deployKey"`
-	IsConnected bool   `json:"isConnected"`
+	Enabled          bool   `json:"enabled"`
+	Provider         string `json:"provider"`
+	Uri              string `json:"uri"`
+	Hostname         string `json:"hostname"`
+	HTTPPort         string `json:"httpPort"`
+	SSHPort          string `json:"sshPort"`
+	Path             string `json:"path"`
+	Branch           string `json:"branch"`
+	Format           string `json:"format"`
+	Action           string `json:"action"`
+	Wrapper          string `json:"wrapper"`
+	WrapperNamespace string `json:"wrapperNamespace"`
+	DeployKey        string `json:"deployKey"`
+	IsConnected      bool   `json:"isConnected"`
+	// PullRequest is the most recent pull request opened when the action is pull_request
+	PullRequest *gitopstypes.PullRequest `json:"pullRequest,omitempty"`
 }
 
 type ResponseCluster struct {
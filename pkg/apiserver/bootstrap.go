@@ -7,6 +7,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/encryptionkey"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/util"
@@ -56,6 +58,16 @@ func bootstrapClusterToken(autoCreateClusterToken string) error {
 }
 
 func loadEncryptionKeys() error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	// keys from an encryption key rotation that has not completed
+	if err := encryptionkey.LoadRetiringKeys(clientset, util.PodNamespace); err != nil {
+		return errors.Wrap(err, "failed to load retiring encryption keys")
+	}
+
 	apps, err := store.GetStore().ListInstalledApps()
 	if err != nil {
 		return errors.Wrap(err, "failed to list apps")
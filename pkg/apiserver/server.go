@@ -11,10 +11,13 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/replicatedhq/kots/migrations"
 	"github.com/replicatedhq/kots/pkg/automation"
 	"github.com/replicatedhq/kots/pkg/binaries"
+	"github.com/replicatedhq/kots/pkg/gitops/pullrequests"
 	"github.com/replicatedhq/kots/pkg/handlers"
 	"github.com/replicatedhq/kots/pkg/helm"
+	"github.com/replicatedhq/kots/pkg/identity"
 	identitymigrate "github.com/replicatedhq/kots/pkg/identity/migrate"
 	"github.com/replicatedhq/kots/pkg/informers"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
@@ -23,6 +26,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/policy"
 	"github.com/replicatedhq/kots/pkg/rbac"
+	rbactypes "github.com/replicatedhq/kots/pkg/rbac/types"
 	"github.com/replicatedhq/kots/pkg/reporting"
 	"github.com/replicatedhq/kots/pkg/session"
 	"github.com/replicatedhq/kots/pkg/snapshotscheduler"
@@ -36,6 +40,8 @@ import (
 type APIServerParams struct {
 	Version                string
 	RqliteURI              string
+	DatabaseDriver         string
+	DatabaseURI            string
 	AutocreateClusterToken string
 	SharedPassword         string
 }
@@ -45,7 +51,17 @@ func Start(params *APIServerParams) {
 
 	if !util.IsHelmManaged() {
 		// set some persistence variables
-		persistence.InitDB(params.RqliteURI)
+		databaseURI := params.RqliteURI
+		if params.DatabaseDriver != "" && params.DatabaseDriver != persistence.DriverRqlite {
+			databaseURI = params.DatabaseURI
+
+			// rqlite schemas are applied by the schemahero migrations job, other drivers are migrated here
+			if err := persistence.UpdateDBSchemaFromFS(params.DatabaseDriver, databaseURI, migrations.Tables); err != nil {
+				log.Println("error updating database schema")
+				panic(err)
+			}
+		}
+		persistence.InitDB(params.DatabaseDriver, databaseURI)
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 		if err := store.GetStore().WaitForReady(ctx); err != nil {
@@ -56,7 +72,7 @@ func Start(params *APIServerParams) {
 	}
 
 	// check if we need to migrate from postgres before doing anything else
-	if !util.IsHelmManaged() {
+	if !util.IsHelmManaged() && persistence.Driver() == persistence.DriverRqlite {
 		if err := persistence.MigrateFromPostgresToRqlite(); err != nil {
 			log.Println("error migrating from postgres to rqlite")
 			panic(err)
@@ -138,6 +154,12 @@ func Start(params *APIServerParams) {
 		if err := snapshotscheduler.Start(); err != nil {
 			log.Println("Failed to start snapshot scheduler:", err)
 		}
+		if err := supportbundle.StartScheduler(); err != nil {
+			log.Println("Failed to start support bundle scheduler:", err)
+		}
+		if err := pullrequests.Start(); err != nil {
+			log.Println("Failed to start gitops pull request refresher:", err)
+		}
 	}
 
 	if err := session.StartSessionPurgeCronJob(); err != nil {
@@ -192,6 +214,9 @@ func Start(params *APIServerParams) {
 	**********************************************************************/
 
 	policyMiddleware := policy.NewMiddleware(kotsStore, rbac.DefaultRoles())
+	policyMiddleware.RolesGetter = func(ctx context.Context) ([]rbactypes.Role, error) {
+		return identity.GetRoles(ctx, util.PodNamespace)
+	}
 
 	sessionAuthQuietRouter := r.PathPrefix("").Subrouter()
 	sessionAuthQuietRouter.Use(handlers.RequireValidSessionQuietMiddleware(kotsStore))
@@ -0,0 +1,117 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/apitoken/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	// TokenPrefix identifies api tokens in the authorization header, tokens have the form kat_<id>_<secret>
+	TokenPrefix = "kat_"
+
+	// lastUsedInterval limits how often the last used time of a token is written
+	lastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid api token")
+	ErrExpiredToken = errors.New("api token expired")
+	ErrRevokedToken = errors.New("api token revoked")
+)
+
+// IsAPIToken returns true if the credential looks like an api token rather than a session jwt
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, TokenPrefix)
+}
+
+// Create creates a token with the given roles. The token is only returned here, the store keeps a hash of it.
+// A zero ttl creates a token that does not expire.
+func Create(kotsStore store.Store, name string, roles []string, ttl time.Duration) (string, *types.APIToken, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, errors.Wrap(err, "failed to generate secret")
+	}
+
+	id := ksuid.New().String()
+	tokenString := fmt.Sprintf("%s%s_%s", TokenPrefix, id, hex.EncodeToString(secret))
+
+	token := &types.APIToken{
+		ID:        id,
+		Name:      name,
+		Roles:     roles,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expiresAt := token.CreatedAt.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := kotsStore.CreateAPIToken(token, hashToken(tokenString)); err != nil {
+		return "", nil, errors.Wrap(err, "failed to create api token")
+	}
+
+	return tokenString, token, nil
+}
+
+// Authenticate returns the token if it exists, matches the stored hash, and is neither expired nor revoked
+func Authenticate(kotsStore store.Store, tokenString string) (*types.APIToken, error) {
+	id, err := parseTokenID(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := kotsStore.GetAPIToken(id)
+	if err != nil {
+		if kotsStore.IsNotFound(err) {
+			return nil, ErrInvalidToken
+		}
+		return nil, errors.Wrap(err, "failed to get api token")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(tokenString)), []byte(tokenHash)) != 1 {
+		return nil, ErrInvalidToken
+	}
+	if token.IsRevoked() {
+		return nil, ErrRevokedToken
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, ErrExpiredToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval {
+		if err := kotsStore.UpdateAPITokenLastUsedAt(token.ID, now); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to update last used time of api token %s", token.ID))
+		}
+	}
+
+	return token, nil
+}
+
+func parseTokenID(tokenString string) (string, error) {
+	if !IsAPIToken(tokenString) {
+		return "", ErrInvalidToken
+	}
+	parts := strings.Split(strings.TrimPrefix(tokenString, TokenPrefix), "_")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", ErrInvalidToken
+	}
+	return parts[0], nil
+}
+
+func hashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
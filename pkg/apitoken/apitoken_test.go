@@ -0,0 +1,111 @@
+package apitoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/apitoken/types"
+	mock_store "github.com/replicatedhq/kots/pkg/store/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockStore(ctrl)
+
+	var storedToken *types.APIToken
+	var storedHash string
+	mockStore.EXPECT().CreateAPIToken(gomock.Any(), gomock.Any()).DoAndReturn(func(token *types.APIToken, tokenHash string) error {
+		storedToken = token
+		storedHash = tokenHash
+		return nil
+	})
+
+	tokenString, token, err := Create(mockStore, "ci", []string{"cluster-admin"}, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, IsAPIToken(tokenString))
+	assert.NotContains(t, storedHash, tokenString)
+	require.NotNil(t, token.ExpiresAt)
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	recently := time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name        string
+		tokenString string
+		stored      types.APIToken
+		notFound    bool
+		wantLastUse bool
+		wantErr     error
+	}{
+		{
+			name:        "valid",
+			tokenString: tokenString,
+			stored:      *storedToken,
+			wantLastUse: true,
+		},
+		{
+			name:        "valid recently used",
+			tokenString: tokenString,
+			stored:      types.APIToken{ID: token.ID, ExpiresAt: &future, LastUsedAt: &recently},
+		},
+		{
+			name:        "wrong secret",
+			tokenString: TokenPrefix + token.ID + "_deadbeef",
+			stored:      *storedToken,
+			wantErr:     ErrInvalidToken,
+		},
+		{
+			name:        "revoked",
+			tokenString: tokenString,
+			stored:      types.APIToken{ID: token.ID, RevokedAt: &past},
+			wantErr:     ErrRevokedToken,
+		},
+		{
+			name:        "expired",
+			tokenString: tokenString,
+			stored:      types.APIToken{ID: token.ID, ExpiresAt: &past},
+			wantErr:     ErrExpiredToken,
+		},
+		{
+			name:        "not found",
+			tokenString: tokenString,
+			notFound:    true,
+			wantErr:     ErrInvalidToken,
+		},
+		{
+			name:        "malformed",
+			tokenString: TokenPrefix + "no-secret",
+			wantErr:     ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.notFound {
+				notFoundErr := errors.New("not found")
+				mockStore.EXPECT().GetAPIToken(token.ID).Return(nil, "", notFoundErr)
+				mockStore.EXPECT().IsNotFound(notFoundErr).Return(true)
+			} else if tt.tokenString != TokenPrefix+"no-secret" {
+				stored := tt.stored
+				mockStore.EXPECT().GetAPIToken(token.ID).Return(&stored, storedHash, nil)
+			}
+			if tt.wantLastUse {
+				mockStore.EXPECT().UpdateAPITokenLastUsedAt(token.ID, gomock.Any()).Return(nil)
+			}
+
+			got, err := Authenticate(mockStore, tt.tokenString)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, token.ID, got.ID)
+		})
+	}
+}
@@ -6,14 +6,13 @@ import (
 
 	"github.com/replicatedhq/kots/pkg/app/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 // SetLastUpdateAtTime sets the time that the client last checked for an update to now
 func SetLastUpdateAtTime(appID string, t time.Time) error {
 	db := persistence.MustGetDBSession()
 	query := `update app set last_update_check_at = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{t.Unix(), appID},
 	})
@@ -27,7 +26,7 @@ func SetLastUpdateAtTime(appID string, t time.Time) error {
 func InitiateRestore(snapshotName string, appID string) error {
 	db := persistence.MustGetDBSession()
 	query := `update app set restore_in_progress_name = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{snapshotName, appID},
 	})
@@ -41,7 +40,7 @@ func InitiateRestore(snapshotName string, appID string) error {
 func ResetRestore(appID string) error {
 	db := persistence.MustGetDBSession()
 	query := `update app set restore_in_progress_name = NULL, restore_undeploy_status = '' where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -55,7 +54,7 @@ func ResetRestore(appID string) error {
 func SetRestoreUndeployStatus(appID string, undeployStatus types.UndeployStatus) error {
 	db := persistence.MustGetDBSession()
 	query := `update app set restore_undeploy_status = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{undeployStatus, appID},
 	})
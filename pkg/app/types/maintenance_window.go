@@ -0,0 +1,163 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cron "github.com/robfig/cron/v3"
+)
+
+// MaintenanceWindow limits automatic deploys to a recurring window of time.
+// A window is either a cron expression for the window start with a duration,
+// or a set of weekdays with a start and end time of day.
+type MaintenanceWindow struct {
+	Cron      string   `json:"cron,omitempty"`
+	Duration  string   `json:"duration,omitempty"`
+	Days      []string `json:"days,omitempty"`
+	StartTime string   `json:"startTime,omitempty"`
+	EndTime   string   `json:"endTime,omitempty"`
+	// Timezone is an IANA time zone name. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+var weekdays = map[string]string{
+	"sun": "0", "sunday": "0",
+	"mon": "1", "monday": "1",
+	"tue": "2", "tuesday": "2",
+	"wed": "3", "wednesday": "3",
+	"thu": "4", "thursday": "4",
+	"fri": "5", "friday": "5",
+	"sat": "6", "saturday": "6",
+}
+
+// Validate returns an error if the window cannot be scheduled.
+func (w *MaintenanceWindow) Validate() error {
+	_, _, err := w.schedule()
+	return err
+}
+
+// StartSpec returns a cron spec, including the time zone, that fires at the start of every window.
+func (w *MaintenanceWindow) StartSpec() (string, error) {
+	if w.Cron != "" {
+		if len(w.Days) > 0 || w.StartTime != "" || w.EndTime != "" {
+			return "", errors.New("cron cannot be combined with days, start time or end time")
+		}
+		return fmt.Sprintf("CRON_TZ=%s %s", w.location(), w.Cron), nil
+	}
+
+	if len(w.Days) == 0 {
+		return "", errors.New("either cron or days must be set")
+	}
+
+	days := []string{}
+	for _, day := range w.Days {
+		d, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return "", errors.Errorf("invalid day %q", day)
+		}
+		days = append(days, d)
+	}
+
+	start, err := parseTimeOfDay(w.StartTime)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid start time")
+	}
+
+	return fmt.Sprintf("CRON_TZ=%s %d %d * * %s", w.location(), int(start.Minutes())%60, int(start.Hours()), strings.Join(days, ",")), nil
+}
+
+// IsOpen returns true if t falls within a window.
+func (w *MaintenanceWindow) IsOpen(t time.Time) (bool, error) {
+	schedule, duration, err := w.schedule()
+	if err != nil {
+		return false, err
+	}
+
+	// the most recent window start before t is the first start after t-duration
+	// if that start is not after t, t is inside that window
+	start := schedule.Next(t.Add(-duration))
+	if start.IsZero() {
+		// the schedule never fires
+		return false, nil
+	}
+	return !start.After(t), nil
+}
+
+// NextStart returns the start of the next window after t.
+func (w *MaintenanceWindow) NextStart(t time.Time) (time.Time, error) {
+	schedule, _, err := w.schedule()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(t), nil
+}
+
+func (w *MaintenanceWindow) schedule() (cron.Schedule, time.Duration, error) {
+	if _, err := time.LoadLocation(w.location()); err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid timezone %q", w.Timezone)
+	}
+
+	spec, err := w.StartSpec()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to parse cron spec")
+	}
+
+	duration, err := w.duration()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return schedule, duration, nil
+}
+
+func (w *MaintenanceWindow) duration() (time.Duration, error) {
+	if w.Cron != "" {
+		duration, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid duration")
+		}
+		if duration <= 0 {
+			return 0, errors.New("duration must be greater than zero")
+		}
+		return duration, nil
+	}
+
+	start, err := parseTimeOfDay(w.StartTime)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid start time")
+	}
+	end, err := parseTimeOfDay(w.EndTime)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid end time")
+	}
+
+	// windows that end before they start continue into the next day
+	duration := end - start
+	if duration <= 0 {
+		duration += 24 * time.Hour
+	}
+	return duration, nil
+}
+
+func (w *MaintenanceWindow) location() string {
+	if w.Timezone == "" {
+		return "UTC"
+	}
+	return w.Timezone
+}
+
+// parseTimeOfDay parses a time in HH:MM format and returns it as the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindow_IsOpen(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 2023-07-01 is a saturday
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		now    time.Time
+		want   bool
+	}{
+		{
+			name:   "weekly window, inside",
+			window: MaintenanceWindow{Days: []string{"sat", "sun"}, StartTime: "01:00", EndTime: "05:00"},
+			now:    time.Date(2023, 7, 1, 3, 0, 0, 0, time.UTC),
+			want:   true,
+		},
+		{
+			name:   "weekly window, at start",
+			window: MaintenanceWindow{Days: []string{"Saturday"}, StartTime: "01:00", EndTime: "05:00"},
+			now:    time.Date(2023, 7, 1, 1, 0, 0, 0, time.UTC),
+			want:   true,
+		},
+		{
+			name:   "weekly window, at end",
+			window: MaintenanceWindow{Days: []string{"sat"}, StartTime: "01:00", EndTime: "05:00"},
+			now:    time.Date(2023, 7, 1, 5, 0, 0, 0, time.UTC),
+			want:   false,
+		},
+		{
+			name:   "weekly window, wrong day",
+			window: MaintenanceWindow{Days: []string{"mon"}, StartTime: "01:00", EndTime: "05:00"},
+			now:    time.Date(2023, 7, 1, 3, 0, 0, 0, time.UTC),
+			want:   false,
+		},
+		{
+			name:   "weekly window that crosses midnight",
+			window: MaintenanceWindow{Days: []string{"fri"}, StartTime: "22:00", EndTime: "02:00"},
+			now:    time.Date(2023, 7, 1, 1, 30, 0, 0, time.UTC),
+			want:   true,
+		},
+		{
+			name:   "weekly window in another timezone",
+			window: MaintenanceWindow{Days: []string{"fri"}, StartTime: "22:00", EndTime: "23:00", Timezone: "America/New_York"},
+			now:    time.Date(2023, 7, 1, 2, 30, 0, 0, time.UTC),
+			want:   true,
+		},
+		{
+			name:   "weekly window in another timezone, compared in local time",
+			window: MaintenanceWindow{Days: []string{"fri"}, StartTime: "22:00", EndTime: "23:00", Timezone: "America/New_York"},
+			now:    time.Date(2023, 6, 30, 22, 30, 0, 0, newYork),
+			want:   true,
+		},
+		{
+			name:   "cron window, inside",
+			window: MaintenanceWindow{Cron: "0 2 * * *", Duration: "2h"},
+			now:    time.Date(2023, 7, 1, 3, 59, 0, 0, time.UTC),
+			want:   true,
+		},
+		{
+			name:   "cron window, outside",
+			window: MaintenanceWindow{Cron: "0 2 * * *", Duration: "2h"},
+			now:    time.Date(2023, 7, 1, 4, 1, 0, 0, time.UTC),
+			want:   false,
+		},
+		{
+			name:   "cron window that never fires",
+			window: MaintenanceWindow{Cron: "0 0 30 2 *", Duration: "24h"},
+			now:    time.Date(2023, 7, 1, 4, 1, 0, 0, time.UTC),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.IsOpen(tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMaintenanceWindow_NextStart(t *testing.T) {
+	window := MaintenanceWindow{Days: []string{"mon", "thu"}, StartTime: "01:30", EndTime: "03:00"}
+
+	next, err := window.NextStart(time.Date(2023, 7, 1, 3, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 7, 3, 1, 30, 0, 0, time.UTC), next.UTC())
+}
+
+func TestMaintenanceWindow_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  MaintenanceWindow
+		wantErr bool
+	}{
+		{
+			name:   "weekly window",
+			window: MaintenanceWindow{Days: []string{"sat"}, StartTime: "01:00", EndTime: "05:00", Timezone: "Europe/Berlin"},
+		},
+		{
+			name:   "cron window",
+			window: MaintenanceWindow{Cron: "0 2 * * 1-5", Duration: "90m"},
+		},
+		{
+			name:    "empty",
+			window:  MaintenanceWindow{},
+			wantErr: true,
+		},
+		{
+			name:    "cron without duration",
+			window:  MaintenanceWindow{Cron: "0 2 * * *"},
+			wantErr: true,
+		},
+		{
+			name:    "cron with days",
+			window:  MaintenanceWindow{Cron: "0 2 * * *", Duration: "1h", Days: []string{"sat"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cron",
+			window:  MaintenanceWindow{Cron: "0 2 * *", Duration: "1h"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid day",
+			window:  MaintenanceWindow{Days: []string{"caturday"}, StartTime: "01:00", EndTime: "05:00"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid start time",
+			window:  MaintenanceWindow{Days: []string{"sat"}, StartTime: "1am", EndTime: "05:00"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			window:  MaintenanceWindow{Days: []string{"sat"}, StartTime: "01:00", EndTime: "05:00", Timezone: "Mars/Olympus_Mons"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
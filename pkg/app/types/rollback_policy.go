@@ -0,0 +1,64 @@
+package types
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+)
+
+// AutoRollbackPolicy rolls an app back to the previously deployed version when
+// the status informers do not reach the required state within the timeout after a deploy.
+type AutoRollbackPolicy struct {
+	Timeout string `json:"timeout"`
+	// RequiredState is the minimum app state the deploy must reach. Defaults to ready.
+	RequiredState appstatetypes.State `json:"requiredState,omitempty"`
+}
+
+// DeployHealthMonitor is a deploy whose app status is being watched under the AutoRollbackPolicy of the app.
+// It is persisted so that the deploy is still monitored if kotsadm restarts before the deadline.
+type DeployHealthMonitor struct {
+	Sequence int64     `json:"sequence"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// Validate returns an error if the policy cannot be enforced.
+func (p *AutoRollbackPolicy) Validate() error {
+	if _, err := p.GetTimeout(); err != nil {
+		return err
+	}
+
+	switch p.GetRequiredState() {
+	case appstatetypes.StateReady, appstatetypes.StateUpdating, appstatetypes.StateDegraded:
+		return nil
+	default:
+		return errors.Errorf("invalid required state %q, must be one of: ready, updating, degraded", p.RequiredState)
+	}
+}
+
+func (p *AutoRollbackPolicy) GetTimeout() (time.Duration, error) {
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid timeout")
+	}
+	if timeout <= 0 {
+		return 0, errors.New("timeout must be greater than zero")
+	}
+	return timeout, nil
+}
+
+func (p *AutoRollbackPolicy) GetRequiredState() appstatetypes.State {
+	if p.RequiredState == "" {
+		return appstatetypes.StateReady
+	}
+	return p.RequiredState
+}
+
+// IsSatisfiedBy returns true if an app in the given state meets the required state.
+func (p *AutoRollbackPolicy) IsSatisfiedBy(state appstatetypes.State) bool {
+	if state == "" {
+		return false
+	}
+	required := p.GetRequiredState()
+	return appstatetypes.MinState(state, required) == required
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"testing"
+
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoRollbackPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  AutoRollbackPolicy
+		wantErr bool
+	}{
+		{
+			name:   "timeout with default state",
+			policy: AutoRollbackPolicy{Timeout: "10m"},
+		},
+		{
+			name:   "degraded is allowed",
+			policy: AutoRollbackPolicy{Timeout: "1h", RequiredState: appstatetypes.StateDegraded},
+		},
+		{
+			name:    "missing timeout",
+			policy:  AutoRollbackPolicy{},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			policy:  AutoRollbackPolicy{Timeout: "-5m"},
+			wantErr: true,
+		},
+		{
+			name:    "unavailable is not allowed",
+			policy:  AutoRollbackPolicy{Timeout: "10m", RequiredState: appstatetypes.StateUnavailable},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAutoRollbackPolicy_IsSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		name          string
+		requiredState appstatetypes.State
+		state         appstatetypes.State
+		want          bool
+	}{
+		{
+			name:  "ready by default",
+			state: appstatetypes.StateReady,
+			want:  true,
+		},
+		{
+			name:  "degraded when ready is required",
+			state: appstatetypes.StateDegraded,
+			want:  false,
+		},
+		{
+			name:          "ready when degraded is required",
+			requiredState: appstatetypes.StateDegraded,
+			state:         appstatetypes.StateReady,
+			want:          true,
+		},
+		{
+			name:          "updating when degraded is required",
+			requiredState: appstatetypes.StateDegraded,
+			state:         appstatetypes.StateUpdating,
+			want:          true,
+		},
+		{
+			name:          "unavailable when degraded is required",
+			requiredState: appstatetypes.StateDegraded,
+			state:         appstatetypes.StateUnavailable,
+			want:          false,
+		},
+		{
+			name:  "no status",
+			state: "",
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := AutoRollbackPolicy{Timeout: "10m", RequiredState: tt.requiredState}
+			assert.Equal(t, tt.want, policy.IsSatisfiedBy(tt.state))
+		})
+	}
+}
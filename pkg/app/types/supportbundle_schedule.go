@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	cron "github.com/robfig/cron/v3"
+)
+
+const DefaultSupportBundleRetention = 5
+
+// SupportBundleSchedule collects support bundles for an app on a cron schedule so that
+// intermittent issues are captured while they are happening.
+type SupportBundleSchedule struct {
+	// Schedule is a standard cron expression
+	Schedule string `json:"schedule"`
+	// Retention is the number of scheduled support bundles to keep. Defaults to 5.
+	// Bundles collected on demand are never removed.
+	Retention int `json:"retention,omitempty"`
+	// UploadOnFailure shares a scheduled support bundle with the vendor when its analysis reports an error
+	UploadOnFailure bool `json:"uploadOnFailure,omitempty"`
+}
+
+// Validate returns an error if the schedule cannot be run.
+func (s *SupportBundleSchedule) Validate() error {
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return errors.Wrap(err, "invalid cron schedule")
+	}
+	if s.Retention < 0 {
+		return errors.New("retention must not be negative")
+	}
+	return nil
+}
+
+func (s *SupportBundleSchedule) GetRetention() int {
+	if s.Retention == 0 {
+		return DefaultSupportBundleRetention
+	}
+	return s.Retention
+}
+
+// Next returns the first scheduled time after the given time.
+func (s *SupportBundleSchedule) Next(after time.Time) (time.Time, error) {
+	cronSchedule, err := cron.ParseStandard(s.Schedule)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse cron expression")
+	}
+	return cronSchedule.Next(after), nil
+}
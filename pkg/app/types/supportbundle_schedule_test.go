@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportBundleSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule SupportBundleSchedule
+		wantErr  bool
+	}{
+		{
+			name:     "hourly with default retention",
+			schedule: SupportBundleSchedule{Schedule: "0 * * * *"},
+		},
+		{
+			name:     "descriptor with retention",
+			schedule: SupportBundleSchedule{Schedule: "@daily", Retention: 10, UploadOnFailure: true},
+		},
+		{
+			name:     "missing schedule",
+			schedule: SupportBundleSchedule{},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid schedule",
+			schedule: SupportBundleSchedule{Schedule: "every hour"},
+			wantErr:  true,
+		},
+		{
+			name:     "negative retention",
+			schedule: SupportBundleSchedule{Schedule: "@hourly", Retention: -1},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSupportBundleSchedule_Next(t *testing.T) {
+	schedule := SupportBundleSchedule{Schedule: "30 */6 * * *"}
+
+	next, err := schedule.Next(time.Date(2023, 7, 1, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 7, 1, 12, 30, 0, 0, time.UTC), next)
+
+	assert.Equal(t, DefaultSupportBundleRetention, schedule.GetRetention())
+}
@@ -155,6 +155,48 @@ func GetRenderedApp(versionArchive string, downstreamName, kustomizeBinPath stri
 	return allContent, filesMap, nil
 }
 
+// GetAppSourceFiles returns the kustomize base and overlays for the downstream and the v1beta2 helm charts with their values,
+// keyed by their path relative to the version archive. The v1beta1 charts are not included as they are processed separately.
+func GetAppSourceFiles(versionArchive string, downstreamName string) (map[string][]byte, error) {
+	baseDir := filepath.Join(versionArchive, "base")
+	filter := filterChartsInBasePath(baseDir)
+	if err := cleanBaseApp(baseDir, filter); err != nil {
+		return nil, errors.Wrap(err, "failed to clean base app")
+	}
+
+	sourceDirs := []string{
+		"base",
+		filepath.Join("overlays", "midstream"),
+		filepath.Join("overlays", "downstreams", downstreamName),
+		"helm",
+	}
+
+	files := map[string][]byte{}
+	for _, sourceDir := range sourceDirs {
+		dir := filepath.Join(versionArchive, sourceDir)
+		if _, err := os.Stat(dir); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to stat %s", sourceDir)
+		}
+
+		dirFiles, err := util.GetFilesMap(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get files map for %s", sourceDir)
+		}
+
+		for relPath, content := range dirFiles {
+			if sourceDir != "helm" && strings.Split(relPath, string(os.PathSeparator))[0] == "charts" {
+				continue
+			}
+			files[filepath.Join(sourceDir, relPath)] = content
+		}
+	}
+
+	return files, nil
+}
+
 // cleanBaseApp iterates over the base files and removes any files with nil map entries
 // this does not include helm charts, which are processed separately.
 // an optional filter can be passed to skip files that should not be removed.
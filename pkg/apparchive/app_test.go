@@ -400,3 +400,46 @@ data:
 		})
 	}
 }
+
+func TestGetAppSourceFiles(t *testing.T) {
+	files := map[string]string{
+		"upstream/userdata/config.yaml":                                         "config",
+		"base/kustomization.yaml":                                               "base",
+		"base/deployment.yaml":                                                  "deployment",
+		"base/charts/guestbook/kustomization.yaml":                              "v1beta1 chart",
+		"overlays/midstream/kustomization.yaml":                                 "midstream",
+		"overlays/midstream/secret.yaml":                                        "pull secret",
+		"overlays/midstream/charts/guestbook/kustomization.yaml":                "v1beta1 chart midstream",
+		"overlays/downstreams/this-cluster/kustomization.yaml":                  "this cluster",
+		"overlays/downstreams/this-cluster/charts/guestbook/kustomization.yaml": "v1beta1 chart downstream",
+		"overlays/downstreams/other-cluster/kustomization.yaml":                 "other cluster",
+		"helm/nginx/nginx-1.0.0.tgz":                                            "chart archive",
+		"helm/nginx/values.yaml":                                                "values",
+		"rendered/this-cluster/deployment.yaml":                                 "rendered",
+	}
+
+	want := map[string][]byte{
+		"base/kustomization.yaml":                              []byte("base"),
+		"base/deployment.yaml":                                 []byte("deployment"),
+		"overlays/midstream/kustomization.yaml":                []byte("midstream"),
+		"overlays/midstream/secret.yaml":                       []byte("pull secret"),
+		"overlays/downstreams/this-cluster/kustomization.yaml": []byte("this cluster"),
+		"helm/nginx/nginx-1.0.0.tgz":                           []byte("chart archive"),
+		"helm/nginx/values.yaml":                               []byte("values"),
+	}
+
+	tmpDir := t.TempDir()
+	req := require.New(t)
+
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+		req.NoError(err)
+		err = ioutil.WriteFile(fullPath, []byte(content), 0644)
+		req.NoError(err)
+	}
+
+	got, err := GetAppSourceFiles(tmpDir, "this-cluster")
+	req.NoError(err)
+	req.Equal(want, got)
+}
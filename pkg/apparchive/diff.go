@@ -3,9 +3,15 @@ package apparchive
 import (
 	"bufio"
 	"bytes"
+	"path"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/replicatedhq/kots/pkg/util"
+	yaml "github.com/replicatedhq/yaml/v3"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
@@ -140,3 +146,219 @@ func diffAppFiles(archive map[string][]byte, base map[string][]byte) (*Diff, err
 
 	return &diff, nil
 }
+
+type DiffStatus string
+
+const (
+	DiffStatusAdded    DiffStatus = "added"
+	DiffStatusRemoved  DiffStatus = "removed"
+	DiffStatusModified DiffStatus = "modified"
+)
+
+// VersionDiff is the full diff of the rendered manifests between two app versions
+type VersionDiff struct {
+	Summary Diff         `json:"summary"`
+	Files   []FileDiff   `json:"files"`
+	Objects []ObjectDiff `json:"objects"`
+}
+
+// FileDiff is the unified diff of a single rendered file
+type FileDiff struct {
+	Path         string     `json:"path"`
+	Status       DiffStatus `json:"status"`
+	LinesAdded   int        `json:"linesAdded"`
+	LinesRemoved int        `json:"linesRemoved"`
+	Binary       bool       `json:"binary,omitempty"`
+	Patch        string     `json:"patch,omitempty"`
+}
+
+// ObjectDiff is the unified diff of a single kubernetes object, identified by kind, namespace and name
+type ObjectDiff struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Namespace  string     `json:"namespace,omitempty"`
+	Name       string     `json:"name"`
+	Status     DiffStatus `json:"status"`
+	Patch      string     `json:"patch"`
+}
+
+// GetRenderedAppFiles returns all rendered files for the downstream, including the v1beta1 and v1beta2 charts.
+// Chart files are prefixed with the directory they are rendered to in the archive ("charts" and "helm").
+func GetRenderedAppFiles(versionArchive string, downstreamName string, kustomizeBinPath string) (map[string][]byte, error) {
+	_, appFiles, err := GetRenderedApp(versionArchive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered app")
+	}
+
+	_, v1Beta1ChartFiles, err := GetRenderedV1Beta1ChartsArchive(versionArchive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered v1beta1 charts files")
+	}
+
+	v1Beta2ChartFiles, err := GetRenderedV1Beta2FileMap(versionArchive, downstreamName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered v1beta2 charts files")
+	}
+
+	files := map[string][]byte{}
+	for filename, content := range appFiles {
+		files[filename] = content
+	}
+	for filename, content := range v1Beta1ChartFiles {
+		files[path.Join("charts", filename)] = content
+	}
+	for filename, content := range v1Beta2ChartFiles {
+		files[path.Join("helm", filename)] = content
+	}
+
+	return files, nil
+}
+
+// DiffRenderedAppVersions will generate a per file and per kubernetes object unified diff
+// of the rendered manifests between two different archive dirs
+func DiffRenderedAppVersions(downstreamName string, archive string, diffBasePath string, kustomizeBinPath string) (*VersionDiff, error) {
+	archiveFiles, err := GetRenderedAppFiles(archive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered app files")
+	}
+
+	baseFiles, err := GetRenderedAppFiles(diffBasePath, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base rendered app files")
+	}
+
+	return diffRenderedFiles(archiveFiles, baseFiles)
+}
+
+func diffRenderedFiles(archive map[string][]byte, base map[string][]byte) (*VersionDiff, error) {
+	versionDiff := VersionDiff{
+		Files:   []FileDiff{},
+		Objects: []ObjectDiff{},
+	}
+
+	for _, filename := range sortedKeys(archive, base) {
+		archiveContents, inArchive := archive[filename]
+		baseContents, inBase := base[filename]
+
+		fileDiff := FileDiff{
+			Path:   filename,
+			Status: DiffStatusModified,
+		}
+		if !inBase {
+			fileDiff.Status = DiffStatusAdded
+		} else if !inArchive {
+			fileDiff.Status = DiffStatusRemoved
+		} else if bytes.Equal(archiveContents, baseContents) {
+			continue
+		}
+
+		if !utf8.Valid(archiveContents) || !utf8.Valid(baseContents) {
+			fileDiff.Binary = true
+		} else {
+			linesAdded, linesRemoved, err := diffContent(string(baseContents), string(archiveContents))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to diff base and archive contents %s", filename)
+			}
+			fileDiff.LinesAdded = linesAdded
+			fileDiff.LinesRemoved = linesRemoved
+
+			patch, err := unifiedDiff(filename, string(baseContents), string(archiveContents))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create unified diff for %s", filename)
+			}
+			fileDiff.Patch = patch
+		}
+
+		versionDiff.Files = append(versionDiff.Files, fileDiff)
+		versionDiff.Summary.FilesChanged++
+		versionDiff.Summary.LinesAdded += fileDiff.LinesAdded
+		versionDiff.Summary.LinesRemoved += fileDiff.LinesRemoved
+	}
+
+	archiveObjects, archiveGVKs := objectsFromFiles(archive)
+	baseObjects, baseGVKs := objectsFromFiles(base)
+
+	for _, key := range sortedKeys(archiveObjects, baseObjects) {
+		archiveContents, inArchive := archiveObjects[key]
+		baseContents, inBase := baseObjects[key]
+
+		objectDiff := ObjectDiff{
+			Status: DiffStatusModified,
+		}
+		gvk := archiveGVKs[key]
+		if !inBase {
+			objectDiff.Status = DiffStatusAdded
+		} else if !inArchive {
+			objectDiff.Status = DiffStatusRemoved
+			gvk = baseGVKs[key]
+		} else if bytes.Equal(archiveContents, baseContents) {
+			continue
+		}
+
+		objectDiff.APIVersion = gvk.APIVersion
+		objectDiff.Kind = gvk.Kind
+		objectDiff.Namespace = gvk.Metadata.Namespace
+		objectDiff.Name = gvk.Metadata.Name
+
+		patch, err := unifiedDiff(key, string(baseContents), string(archiveContents))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create unified diff for %s", key)
+		}
+		objectDiff.Patch = patch
+
+		versionDiff.Objects = append(versionDiff.Objects, objectDiff)
+	}
+
+	return &versionDiff, nil
+}
+
+// objectsFromFiles splits all yaml files into kubernetes objects keyed by kind/namespace/name.
+// Documents that are not kubernetes objects are skipped.
+func objectsFromFiles(files map[string][]byte) (map[string][]byte, map[string]util.OverlySimpleGVK) {
+	objects := map[string][]byte{}
+	gvks := map[string]util.OverlySimpleGVK{}
+	for filename, content := range files {
+		if ext := path.Ext(filename); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		for _, doc := range util.ConvertToSingleDocs(content) {
+			gvk := util.OverlySimpleGVK{}
+			if err := yaml.Unmarshal(doc, &gvk); err != nil {
+				continue
+			}
+			if gvk.Kind == "" || gvk.Metadata.Name == "" {
+				continue
+			}
+
+			key := path.Join(gvk.Kind, gvk.Metadata.Namespace, gvk.Metadata.Name)
+			objects[key] = append(bytes.TrimSpace(doc), '\n')
+			gvks[key] = gvk
+		}
+	}
+	return objects, gvks
+}
+
+func unifiedDiff(name string, baseContent string, updatedContent string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(baseContent),
+		B:        difflib.SplitLines(updatedContent),
+		FromFile: path.Join("a", name),
+		ToFile:   path.Join("b", name),
+		Context:  3,
+	})
+}
+
+func sortedKeys(a map[string][]byte, b map[string][]byte) []string {
+	keys := []string{}
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -160,3 +160,79 @@ three lines`),
 		})
 	}
 }
+
+func Test_diffRenderedFiles(t *testing.T) {
+	req := require.New(t)
+
+	base := map[string][]byte{
+		"deployment.yaml": []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+`),
+		"configmap.yaml": []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`),
+	}
+
+	archive := map[string][]byte{
+		"deployment.yaml": []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+`),
+		"secret.yaml": []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+`),
+		"helm/chart-1.0.0.tgz": {0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe},
+	}
+
+	actual, err := diffRenderedFiles(archive, base)
+	req.NoError(err)
+
+	req.Len(actual.Files, 4)
+	assert.Equal(t, FileDiff{Path: "configmap.yaml", Status: DiffStatusRemoved, LinesRemoved: 4, Patch: actual.Files[0].Patch}, actual.Files[0])
+	assert.Equal(t, "deployment.yaml", actual.Files[1].Path)
+	assert.Equal(t, DiffStatusModified, actual.Files[1].Status)
+	assert.Equal(t, 1, actual.Files[1].LinesAdded)
+	assert.Equal(t, 1, actual.Files[1].LinesRemoved)
+	req.Contains(actual.Files[1].Patch, "--- a/deployment.yaml\n+++ b/deployment.yaml\n")
+	req.Contains(actual.Files[1].Patch, "-  replicas: 1\n+  replicas: 2\n")
+	assert.Equal(t, FileDiff{Path: "helm/chart-1.0.0.tgz", Status: DiffStatusAdded, Binary: true}, actual.Files[2])
+	assert.Equal(t, DiffStatusAdded, actual.Files[3].Status)
+	assert.Equal(t, Diff{FilesChanged: 4, LinesAdded: 5, LinesRemoved: 5}, actual.Summary)
+
+	req.Len(actual.Objects, 3)
+	assert.Equal(t, "ConfigMap", actual.Objects[0].Kind)
+	assert.Equal(t, "settings", actual.Objects[0].Name)
+	assert.Equal(t, DiffStatusRemoved, actual.Objects[0].Status)
+	assert.Equal(t, "Deployment", actual.Objects[1].Kind)
+	assert.Equal(t, "default", actual.Objects[1].Namespace)
+	assert.Equal(t, "apps/v1", actual.Objects[1].APIVersion)
+	assert.Equal(t, DiffStatusModified, actual.Objects[1].Status)
+	req.Contains(actual.Objects[1].Patch, "--- a/Deployment/default/web\n+++ b/Deployment/default/web\n")
+	assert.Equal(t, "Secret", actual.Objects[2].Kind)
+	assert.Equal(t, DiffStatusAdded, actual.Objects[2].Status)
+}
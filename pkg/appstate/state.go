@@ -0,0 +1,62 @@
+package appstate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetResourceState returns the current state of the resource the status informer points to.
+// Resources that don't exist are missing. The informer namespace must be set.
+func GetResourceState(ctx context.Context, clientset kubernetes.Interface, informer types.StatusInformer) (types.State, error) {
+	var state types.State
+	var err error
+
+	switch getResourceKindCommonName(informer.Kind) {
+	case DaemonSetResourceKind:
+		r, getErr := clientset.AppsV1().DaemonSets(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculateDaemonSetState(clientset, informer.Namespace, r)
+		}
+	case DeploymentResourceKind:
+		r, getErr := clientset.AppsV1().Deployments(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculateDeploymentState(r)
+		}
+	case IngressResourceKind:
+		r, getErr := clientset.NetworkingV1().Ingresses(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculateIngressState(clientset, r)
+		}
+	case PersistentVolumeClaimResourceKind:
+		r, getErr := clientset.CoreV1().PersistentVolumeClaims(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculatePersistentVolumeClaimState(r)
+		}
+	case ServiceResourceKind:
+		r, getErr := clientset.CoreV1().Services(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculateServiceState(clientset, r)
+		}
+	case StatefulSetResourceKind:
+		r, getErr := clientset.AppsV1().StatefulSets(informer.Namespace).Get(ctx, informer.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			state = CalculateStatefulSetState(clientset, informer.Namespace, r)
+		}
+	default:
+		return "", errors.Errorf("unsupported resource kind %s", informer.Kind)
+	}
+
+	if kuberneteserrors.IsNotFound(err) {
+		return types.StateMissing, nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get %s %s", informer.Kind, informer.Name)
+	}
+
+	return state, nil
+}
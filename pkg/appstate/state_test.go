@@ -0,0 +1,78 @@
+package appstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetResourceState(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		informer types.StatusInformer
+		want     types.State
+		wantErr  bool
+	}{
+		{
+			name:     "ready deployment",
+			informer: types.StatusInformer{Kind: "deploy", Name: "web", Namespace: "app"},
+			want:     types.StateReady,
+		},
+		{
+			name:     "degraded deployment",
+			informer: types.StatusInformer{Kind: "deployment", Name: "worker", Namespace: "app"},
+			want:     types.StateDegraded,
+		},
+		{
+			name:     "bound pvc",
+			informer: types.StatusInformer{Kind: "pvc", Name: "data", Namespace: "app"},
+			want:     types.StateReady,
+		},
+		{
+			name:     "deployment in another namespace",
+			informer: types.StatusInformer{Kind: "deployment", Name: "web", Namespace: "other"},
+			want:     types.StateMissing,
+		},
+		{
+			name:     "unsupported kind",
+			informer: types.StatusInformer{Kind: "configmap", Name: "web", Namespace: "app"},
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GetResourceState(context.Background(), clientset, test.informer)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/session"
+	sessiontypes "github.com/replicatedhq/kots/pkg/session/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
+)
+
+// Actions that are recorded in the audit log
+const (
+	ActionDeployAppVersion    = "app.deploy"
+	ActionUpdateAppConfig     = "app.config.update"
+	ActionUploadLicense       = "license.upload"
+	ActionSyncLicense         = "license.sync"
+	ActionChangeLicense       = "license.change"
+	ActionRestoreApp          = "snapshot.restore.app"
+	ActionRestoreApps         = "snapshot.restore.apps"
+	ActionChangePassword      = "password.change"
+	ActionUpdateRedactor      = "redactor.update"
+	ActionDeleteRedactor      = "redactor.delete"
+	ActionEnableRedactor      = "redactor.enable"
+	ActionRotateEncryptionKey = "encryptionkey.rotate"
+	ActionCreateAPIToken      = "apitoken.create"
+	ActionRevokeAPIToken      = "apitoken.revoke"
+)
+
+// StatusRecorder keeps the status code written by a handler so that the outcome of the request can be recorded
+type StatusRecorder struct {
+	http.ResponseWriter
+	StatusCode int
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{w, http.StatusOK}
+}
+
+func (r *StatusRecorder) WriteHeader(code int) {
+	r.StatusCode = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// RecordRequest records the outcome of a request that was handled with the given status code.
+// Failing to record an event is logged and never fails the request.
+func RecordRequest(kotsStore store.Store, r *http.Request, action string, resource string, statusCode int) {
+	event := EventFromRequest(r, action, resource, statusCode)
+	if err := Record(kotsStore, event); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to record audit event %s", action))
+	}
+}
+
+// Record saves the event and sends it to the configured sinks
+func Record(kotsStore store.Store, event *types.Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	if err := kotsStore.CreateAuditEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create audit event")
+	}
+
+	sinks := getSinks()
+	if len(sinks) > 0 {
+		e := *event
+		go exportEvent(sinks, e)
+	}
+
+	return nil
+}
+
+// EventFromRequest builds the audit event for a request. The target app and sequence are read from the route vars.
+func EventFromRequest(r *http.Request, action string, resource string, statusCode int) *types.Event {
+	actor, actorType := getActor(session.ContextGetSession(r))
+
+	event := &types.Event{
+		Actor:     actor,
+		ActorType: actorType,
+		Action:    action,
+		Resource:  resource,
+		Outcome:   outcomeFromStatus(statusCode),
+	}
+
+	details := []string{}
+	for key, value := range mux.Vars(r) {
+		switch key {
+		case "appSlug":
+			event.AppSlug = value
+		case "sequence":
+			if sequence, err := strconv.ParseInt(value, 10, 64); err == nil {
+				event.Sequence = &sequence
+			}
+		default:
+			details = append(details, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	sort.Strings(details)
+	event.Detail = strings.Join(details, " ")
+
+	return event
+}
+
+func getActor(sess *sessiontypes.Session) (string, string) {
+	if sess == nil {
+		return "", ""
+	}
+
+	if strings.HasPrefix(sess.UserID, usertypes.APITokenUserIDPrefix) {
+		return strings.TrimPrefix(sess.UserID, usertypes.APITokenUserIDPrefix), types.ActorTypeAPIToken
+	}
+
+	switch sess.UserID {
+	case usertypes.CLIUserID:
+		return usertypes.CLIUserID, types.ActorTypeCLI
+	case usertypes.SharedPasswordUserID:
+		return "admin", types.ActorTypePassword
+	case "":
+		// sessions created before the user was stored with the session
+		return sess.ID, types.ActorTypeSession
+	default:
+		return sess.UserID, types.ActorTypeOIDC
+	}
+}
+
+func outcomeFromStatus(statusCode int) string {
+	switch {
+	case statusCode < http.StatusBadRequest:
+		return types.OutcomeSuccess
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return types.OutcomeDenied
+	default:
+		return types.OutcomeFailure
+	}
+}
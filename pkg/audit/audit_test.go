@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/session"
+	sessiontypes "github.com/replicatedhq/kots/pkg/session/types"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventFromRequest(t *testing.T) {
+	sequence := int64(3)
+
+	tests := []struct {
+		name       string
+		session    *sessiontypes.Session
+		vars       map[string]string
+		statusCode int
+		want       *types.Event
+	}{
+		{
+			name:       "cli deploy",
+			session:    &sessiontypes.Session{ID: "kots-cli", UserID: usertypes.CLIUserID},
+			vars:       map[string]string{"appSlug": "my-app", "sequence": "3"},
+			statusCode: http.StatusNoContent,
+			want: &types.Event{
+				Actor:     usertypes.CLIUserID,
+				ActorType: types.ActorTypeCLI,
+				Action:    ActionDeployAppVersion,
+				Resource:  "app.my-app.downstream.",
+				AppSlug:   "my-app",
+				Sequence:  &sequence,
+				Outcome:   types.OutcomeSuccess,
+			},
+		},
+		{
+			name:       "password session denied",
+			session:    &sessiontypes.Session{ID: "abc", UserID: usertypes.SharedPasswordUserID},
+			vars:       map[string]string{"appSlug": "my-app", "sequence": "3"},
+			statusCode: http.StatusForbidden,
+			want: &types.Event{
+				Actor:     "admin",
+				ActorType: types.ActorTypePassword,
+				Action:    ActionDeployAppVersion,
+				Resource:  "app.my-app.downstream.",
+				AppSlug:   "my-app",
+				Sequence:  &sequence,
+				Outcome:   types.OutcomeDenied,
+			},
+		},
+		{
+			name:       "oidc session failed with extra vars",
+			session:    &sessiontypes.Session{ID: "abc", UserID: "user@example.com"},
+			vars:       map[string]string{"appSlug": "my-app", "snapshotName": "backup-1", "sequence": "not-a-number"},
+			statusCode: http.StatusInternalServerError,
+			want: &types.Event{
+				Actor:     "user@example.com",
+				ActorType: types.ActorTypeOIDC,
+				Action:    ActionDeployAppVersion,
+				Resource:  "app.my-app.downstream.",
+				AppSlug:   "my-app",
+				Outcome:   types.OutcomeFailure,
+				Detail:    "snapshotName=backup-1",
+			},
+		},
+		{
+			name:       "session without user",
+			session:    &sessiontypes.Session{ID: "abc"},
+			statusCode: http.StatusOK,
+			want: &types.Event{
+				Actor:     "abc",
+				ActorType: types.ActorTypeSession,
+				Action:    ActionDeployAppVersion,
+				Resource:  "app.my-app.downstream.",
+				Outcome:   types.OutcomeSuccess,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r = session.ContextSetSession(r, tt.session)
+			if tt.vars != nil {
+				r = mux.SetURLVars(r, tt.vars)
+			}
+
+			got := EventFromRequest(r, ActionDeployAppVersion, "app.my-app.downstream.", tt.statusCode)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_webhookSink(t *testing.T) {
+	received := make(chan types.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		event := types.Event{}
+		require.NoError(t, json.Unmarshal(b, &event))
+		received <- event
+	}))
+	defer server.Close()
+
+	event := types.Event{
+		ID:        "1",
+		CreatedAt: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Actor:     "admin",
+		Action:    ActionChangePassword,
+		Outcome:   types.OutcomeSuccess,
+	}
+	require.NoError(t, webhookSink{url: server.URL}.Send(event))
+	assert.Equal(t, event, <-received)
+}
+
+func Test_parseSyslogAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    *syslogSink
+		wantErr bool
+	}{
+		{
+			address: "udp://syslog.example.com:514",
+			want:    &syslogSink{network: "udp", address: "syslog.example.com:514"},
+		},
+		{
+			address: "tcp://10.0.0.1:601",
+			want:    &syslogSink{network: "tcp", address: "10.0.0.1:601"},
+		},
+		{
+			address: "http://syslog.example.com",
+			wantErr: true,
+		},
+		{
+			address: "udp://",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := parseSyslogAddress(tt.address)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+)
+
+const (
+	// WebhookURLEnv is the url that audit events are posted to as json
+	WebhookURLEnv = "AUDIT_LOG_WEBHOOK_URL"
+	// SyslogAddressEnv is the syslog server that audit events are sent to, e.g. udp://syslog.example.com:514 or tcp://syslog.example.com:601
+	SyslogAddressEnv = "AUDIT_LOG_SYSLOG_ADDRESS"
+
+	// facility 13 (log audit), severity 6 (informational)
+	syslogPriority = 13*8 + 6
+)
+
+var sinkClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// Sink receives a copy of every audit event after it has been saved
+type Sink interface {
+	Send(event types.Event) error
+}
+
+type webhookSink struct {
+	url string
+}
+
+func (s webhookSink) Send(event types.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewBuffer(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to post event")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+type syslogSink struct {
+	network string
+	address string
+}
+
+func (s syslogSink) Send(event types.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	conn, err := net.DialTimeout(s.network, s.address, 10*time.Second)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to syslog")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(formatSyslogMessage(event, b))); err != nil {
+		return errors.Wrap(err, "failed to write to syslog")
+	}
+
+	return nil
+}
+
+// formatSyslogMessage formats the event as an RFC 5424 message with the json encoded event as the message body
+func formatSyslogMessage(event types.Event, body []byte) string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return fmt.Sprintf("<%d>1 %s %s kotsadm - %s - %s\n", syslogPriority, event.CreatedAt.UTC().Format(time.RFC3339), hostname, event.Action, body)
+}
+
+func getSinks() []Sink {
+	sinks := []Sink{}
+
+	if webhookURL := os.Getenv(WebhookURLEnv); webhookURL != "" {
+		sinks = append(sinks, webhookSink{url: webhookURL})
+	}
+
+	if syslogAddress := os.Getenv(SyslogAddressEnv); syslogAddress != "" {
+		sink, err := parseSyslogAddress(syslogAddress)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to parse %s", SyslogAddressEnv))
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+
+	return sinks
+}
+
+func parseSyslogAddress(address string) (*syslogSink, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse url")
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, errors.Errorf("unsupported syslog protocol %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("missing syslog host")
+	}
+	return &syslogSink{network: u.Scheme, address: u.Host}, nil
+}
+
+func exportEvent(sinks []Sink, event types.Event) {
+	for _, sink := range sinks {
+		if err := sink.Send(event); err != nil {
+			logger.Error(errors.Wrap(err, "failed to export audit event"))
+		}
+	}
+}
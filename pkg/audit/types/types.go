@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+const (
+	ActorTypeCLI      = "cli"
+	ActorTypePassword = "password"
+	ActorTypeOIDC     = "oidc"
+	ActorTypeSession  = "session"
+	ActorTypeAPIToken = "apitoken"
+
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+type Event struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     string    `json:"actor"`
+	ActorType string    `json:"actorType"`
+	Action    string    `json:"action"`
+	Resource  string    `json:"resource"`
+	AppSlug   string    `json:"appSlug,omitempty"`
+	Sequence  *int64    `json:"sequence,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+type ListOptions struct {
+	// Page is zero based
+	Page     int
+	PageSize int
+	AppSlug  string
+	Actor    string
+	Action   string
+}
+
+type EventList struct {
+	Events     []Event `json:"events"`
+	TotalCount int64   `json:"totalCount"`
+	Page       int     `json:"page"`
+	PageSize   int     `json:"pageSize"`
+}
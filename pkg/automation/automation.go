@@ -302,6 +302,7 @@ func installLicenseSecret(clientset *kubernetes.Clientset, licenseSecret corev1.
 			IsAutomated:            true,
 			SkipPreflights:         instParams.SkipPreflights,
 			SkipCompatibilityCheck: instParams.SkipCompatibilityCheck,
+			SkipIntegrityCheck:     instParams.SkipIntegrityCheck,
 		}
 		err = airgap.CreateAppFromAirgap(createAppOpts)
 		if err != nil {
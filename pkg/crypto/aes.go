@@ -9,6 +9,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +27,10 @@ const keyLength = 24 // 192 bit
 var decryptionCiphers []*aesCipher // used to decrypt data
 var encryptionCipher *aesCipher    // used to encrypt data
 
+// ciphersMu guards decryptionCiphers and encryptionCipher, which can change while data is being encrypted
+// and decrypted when the encryption key is rotated
+var ciphersMu sync.RWMutex
+
 // add cipher from API_ENCRYPTION_KEY environment variable if it is present (and set that key to be used for encryption)
 func init() {
 	decryptionCiphers = []*aesCipher{}
@@ -57,6 +62,9 @@ func InitFromSecret(clientset kubernetes.Interface, namespace string) error {
 		return errors.Wrap(err, "parse kotsadm-encryption secret")
 	}
 
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+
 	addCipher(secCipher)
 	encryptionCipher = secCipher
 
@@ -73,15 +81,19 @@ func InitFromString(data string) error {
 	if err != nil {
 		return err
 	}
+
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+
 	addCipher(newCipher)
 	return nil
 }
 
-// check if a cipher exists in the array, if it does not then add it
+// check if a cipher exists in the array, if it does not then add it. ciphersMu must be locked by the caller.
 func addCipher(aesCipher *aesCipher) {
 	foundMatch := false
 	for _, existingCipher := range decryptionCiphers {
-		if bytes.Equal(existingCipher.key, aesCipher.key) && bytes.Equal(existingCipher.nonce, aesCipher.nonce) {
+		if existingCipher.equals(aesCipher) {
 			foundMatch = true
 		}
 	}
@@ -93,39 +105,98 @@ func addCipher(aesCipher *aesCipher) {
 
 // NewAESCipher creates a new AES cipher to be used for encryption and decryption. If one already exists, it is used instead.
 func NewAESCipher() error {
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+
 	if encryptionCipher != nil && len(decryptionCiphers) >= 1 {
 		return nil
 	}
 
+	newCipher, err := newRandomCipher()
+	if err != nil {
+		return err
+	}
+
+	addCipher(newCipher)
+	encryptionCipher = newCipher
+	return nil
+}
+
+// GenerateKey returns a new random key in the same format as ToString, without registering it.
+func GenerateKey() (string, error) {
+	newCipher, err := newRandomCipher()
+	if err != nil {
+		return "", err
+	}
+	return newCipher.String(), nil
+}
+
+// SetEncryptionKey parses the encryption key from the provided string, adds it to the list of decryptionCiphers and sets this key to be used for encryption.
+// Data encrypted with the previous keys can still be decrypted.
+func SetEncryptionKey(data string) error {
+	newCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return err
+	}
+
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+
+	addCipher(newCipher)
+	encryptionCipher = newCipher
+	return nil
+}
+
+// RemoveDecryptionKey removes the key from the list of decryptionCiphers. The key used for encryption cannot be removed.
+func RemoveDecryptionKey(data string) error {
+	oldCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return err
+	}
+
+	ciphersMu.Lock()
+	defer ciphersMu.Unlock()
+
+	if encryptionCipher != nil && encryptionCipher.equals(oldCipher) {
+		return errors.New("cannot remove the encryption key")
+	}
+
+	remaining := []*aesCipher{}
+	for _, existingCipher := range decryptionCiphers {
+		if !existingCipher.equals(oldCipher) {
+			remaining = append(remaining, existingCipher)
+		}
+	}
+	decryptionCiphers = remaining
+	return nil
+}
+
+func newRandomCipher() (*aesCipher, error) {
 	key := make([]byte, keyLength)
 	if _, err := rand.Read(key); err != nil {
-		return errors.Wrap(err, "failed to read key")
+		return nil, errors.Wrap(err, "failed to read key")
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return errors.Wrap(err, "failed to create new cipher")
+		return nil, errors.Wrap(err, "failed to create new cipher")
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return errors.Wrap(err, "failed to wrap cipher gcm")
+		return nil, errors.Wrap(err, "failed to wrap cipher gcm")
 	}
 
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := rand.Read(nonce); err != nil {
-		return errors.Wrap(err, "failed to read nonce")
+		return nil, errors.Wrap(err, "failed to read nonce")
 	}
 
-	newCipher := &aesCipher{
+	return &aesCipher{
 		key:    key,
 		cipher: gcm,
 		nonce:  nonce,
-	}
-
-	addCipher(newCipher)
-	encryptionCipher = newCipher
-	return nil
+	}, nil
 }
 
 func aesCipherFromString(data string) (newCipher *aesCipher, initErr error) {
@@ -176,10 +247,21 @@ func aesCipherFromString(data string) (newCipher *aesCipher, initErr error) {
 
 // ToString returns a string representation of the global encryption key
 func ToString() string {
+	ciphersMu.RLock()
+	defer ciphersMu.RUnlock()
+
 	if encryptionCipher == nil {
 		return ""
 	}
-	return base64.StdEncoding.EncodeToString(append(encryptionCipher.key, encryptionCipher.nonce...))
+	return encryptionCipher.String()
+}
+
+func (c *aesCipher) String() string {
+	return base64.StdEncoding.EncodeToString(append(append([]byte{}, c.key...), c.nonce...))
+}
+
+func (c *aesCipher) equals(other *aesCipher) bool {
+	return bytes.Equal(c.key, other.key) && bytes.Equal(c.nonce, other.nonce)
 }
 
 func (c *aesCipher) decrypt(in []byte) (result []byte, err error) {
@@ -195,15 +277,26 @@ func (c *aesCipher) decrypt(in []byte) (result []byte, err error) {
 
 // Encrypt encrypts the data with the registered encryption key
 func Encrypt(in []byte) []byte {
-	if encryptionCipher == nil {
+	ciphersMu.RLock()
+	c := encryptionCipher
+	ciphersMu.RUnlock()
+
+	if c == nil {
 		_ = NewAESCipher()
+
+		ciphersMu.RLock()
+		c = encryptionCipher
+		ciphersMu.RUnlock()
 	}
 
-	return encryptionCipher.cipher.Seal(nil, encryptionCipher.nonce, in, nil)
+	return c.cipher.Seal(nil, c.nonce, in, nil)
 }
 
 // Decrypt attempts to decrypt the provided data with all registered keys
 func Decrypt(in []byte) (result []byte, err error) {
+	ciphersMu.RLock()
+	defer ciphersMu.RUnlock()
+
 	if len(decryptionCiphers) == 0 {
 		return nil, NoDecryptionKeysErr{}
 	}
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"encoding/base64"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -160,3 +161,70 @@ func Test_InitFromSecret(t *testing.T) {
 	req.NoError(err)
 	req.Equal(testString, string(decryptedData))
 }
+
+func Test_RotateKey(t *testing.T) {
+	req := require.New(t)
+
+	encryptionCipher = nil
+	decryptionCiphers = nil
+
+	req.NoError(NewAESCipher())
+	oldKey := ToString()
+	oldEncrypted := Encrypt([]byte("encrypted with the old key"))
+
+	newKey, err := GenerateKey()
+	req.NoError(err)
+	req.NotEqual(oldKey, newKey)
+	// generating a key does not change the key used for encryption
+	req.Equal(oldKey, ToString())
+
+	req.NoError(SetEncryptionKey(newKey))
+	req.Equal(newKey, ToString())
+
+	// data encrypted with the old key can still be decrypted until the old key is removed
+	decrypted, err := Decrypt(oldEncrypted)
+	req.NoError(err)
+	req.Equal("encrypted with the old key", string(decrypted))
+
+	newEncrypted := Encrypt([]byte("encrypted with the new key"))
+
+	req.Error(RemoveDecryptionKey(newKey))
+	req.NoError(RemoveDecryptionKey(oldKey))
+
+	_, err = Decrypt(oldEncrypted)
+	req.Error(err)
+	decrypted, err = Decrypt(newEncrypted)
+	req.NoError(err)
+	req.Equal("encrypted with the new key", string(decrypted))
+}
+
+func Test_RotateKeyConcurrently(t *testing.T) {
+	req := require.New(t)
+
+	encryptionCipher = nil
+	decryptionCiphers = nil
+
+	req.NoError(NewAESCipher())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				// the key can be rotated between encrypting and decrypting, but both keys can still decrypt
+				decrypted, err := Decrypt(Encrypt([]byte("test value")))
+				req.NoError(err)
+				req.Equal("test value", string(decrypted))
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		newKey, err := GenerateKey()
+		req.NoError(err)
+		req.NoError(SetEncryptionKey(newKey))
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,174 @@
+package encryptionkey
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"k8s.io/apimachinery/pkg/runtime"
+	serializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+type archiveFile struct {
+	path string
+	obj  runtime.Object
+}
+
+// ReencryptArchive re-encrypts the config values and identity config secrets in an app version archive with the current encryption key,
+// and removes the legacy encryption key from the installation spec. It returns the number of encrypted values in the archive
+// and the legacy key that was removed, if any. Nothing is written for a dry run.
+func ReencryptArchive(archiveDir string, dryRun bool) (int, string, error) {
+	files, err := loadArchiveFiles(archiveDir)
+	if err != nil {
+		return 0, "", errors.Wrap(err, "failed to load archive files")
+	}
+
+	// values in the archive can be encrypted with the legacy key, so it must be loaded before anything is decrypted
+	legacyKey := ""
+	for _, file := range files {
+		if installation, ok := file.obj.(*kotsv1beta1.Installation); ok && installation.Spec.EncryptionKey != "" {
+			legacyKey = installation.Spec.EncryptionKey
+		}
+	}
+	if legacyKey != "" {
+		if err := crypto.InitFromString(legacyKey); err != nil {
+			return 0, "", errors.Wrap(err, "failed to load legacy encryption key")
+		}
+	}
+
+	count := 0
+	for _, file := range files {
+		changed := false
+
+		switch obj := file.obj.(type) {
+		case *kotsv1beta1.Installation:
+			if obj.Spec.EncryptionKey != "" {
+				obj.Spec.EncryptionKey = ""
+				changed = true
+			}
+
+		case *kotsv1beta1.ConfigValues:
+			for name, value := range obj.Spec.Values {
+				// config values don't know the item type, anything that can be decrypted is a password
+				reencrypted, ok := reencryptValue(value.Value)
+				if !ok {
+					continue
+				}
+				value.Value = reencrypted
+				obj.Spec.Values[name] = value
+				count++
+				changed = true
+			}
+
+		case *kotsv1beta1.IdentityConfig:
+			if obj.Spec.ClientSecret != nil && obj.Spec.ClientSecret.ValueEncrypted != "" {
+				reencrypted, ok := reencryptValue(obj.Spec.ClientSecret.ValueEncrypted)
+				if !ok {
+					return 0, "", errors.Errorf("failed to decrypt client secret in %s", file.path)
+				}
+				obj.Spec.ClientSecret.ValueEncrypted = reencrypted
+				count++
+				changed = true
+			}
+			if obj.Spec.DexConnectors.ValueEncrypted != "" {
+				reencrypted, ok := reencryptValue(obj.Spec.DexConnectors.ValueEncrypted)
+				if !ok {
+					return 0, "", errors.Errorf("failed to decrypt dex connectors in %s", file.path)
+				}
+				obj.Spec.DexConnectors.ValueEncrypted = reencrypted
+				count++
+				changed = true
+			}
+		}
+
+		if !changed || dryRun {
+			continue
+		}
+
+		s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+		var b bytes.Buffer
+		if err := s.Encode(file.obj, &b); err != nil {
+			return 0, "", errors.Wrapf(err, "failed to encode %s", file.path)
+		}
+		if err := ioutil.WriteFile(file.path, b.Bytes(), 0644); err != nil {
+			return 0, "", errors.Wrapf(err, "failed to write %s", file.path)
+		}
+	}
+
+	return count, legacyKey, nil
+}
+
+// loadArchiveFiles returns the kots kinds in the archive that can contain encrypted values,
+// both the user data in upstream and the rendered copies in kotsKinds
+func loadArchiveFiles(archiveDir string) ([]archiveFile, error) {
+	files := []archiveFile{}
+
+	dirs := []string{
+		filepath.Join(archiveDir, "upstream", "userdata"),
+		filepath.Join(archiveDir, "kotsKinds"),
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", path)
+			}
+
+			decode := scheme.Codecs.UniversalDeserializer().Decode
+			obj, gvk, err := decode(content, nil, nil)
+			if err != nil {
+				return nil
+			}
+			if gvk.Group != "kots.io" || gvk.Version != "v1beta1" {
+				return nil
+			}
+
+			switch gvk.Kind {
+			case "ConfigValues", "IdentityConfig", "Installation":
+				files = append(files, archiveFile{path: path, obj: obj})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to walk %s", dir)
+		}
+	}
+
+	return files, nil
+}
+
+// reencryptValue decrypts a base64 encoded value and encrypts it again with the current encryption key.
+// It returns false if the value is not encrypted with a known key.
+func reencryptValue(value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", false
+	}
+	decrypted, err := crypto.Decrypt(decoded)
+	if err != nil {
+		return "", false
+	}
+
+	return base64.StdEncoding.EncodeToString(crypto.Encrypt(decrypted)), true
+}
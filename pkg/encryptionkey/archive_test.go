@@ -0,0 +1,159 @@
+package encryptionkey
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/upstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptArchive(t *testing.T) {
+	oldKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	legacyKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	newKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// encrypt with the legacy key and the old key, then forget about the legacy key like a restart would
+	require.NoError(t, crypto.SetEncryptionKey(legacyKey))
+	legacyPassword := base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("legacy-password")))
+	require.NoError(t, crypto.SetEncryptionKey(oldKey))
+	require.NoError(t, crypto.RemoveDecryptionKey(legacyKey))
+	password := base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("password")))
+	clientSecret := kotsv1beta1.NewStringValueOrEncrypted("client-secret")
+
+	configValues := `apiVersion: kots.io/v1beta1
+kind: ConfigValues
+metadata:
+  name: my-app
+spec:
+  values:
+    hostname:
+      value: my-app.example.com
+    password:
+      value: ` + password + `
+    legacy_password:
+      value: ` + legacyPassword + `
+    db_password:
+      value: ref+vault://secret/data/my-app#password
+`
+	installation := `apiVersion: kots.io/v1beta1
+kind: Installation
+metadata:
+  name: my-app
+spec:
+  versionLabel: "1.0.0"
+  encryptionKey: ` + legacyKey + `
+`
+	identityConfig := `apiVersion: kots.io/v1beta1
+kind: IdentityConfig
+metadata:
+  name: identity
+spec:
+  enabled: true
+  clientSecret:
+    valueEncrypted: ` + clientSecret.ValueEncrypted + `
+`
+
+	archiveDir := t.TempDir()
+	archiveFiles := map[string]string{
+		"upstream/userdata/config.yaml":         configValues,
+		"upstream/userdata/installation.yaml":   installation,
+		"upstream/userdata/identityconfig.yaml": identityConfig,
+		"kotsKinds/userdata/config.yaml":        configValues,
+		"kotsKinds/deployment.yaml":             "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: my-app\n",
+	}
+	for filename, content := range archiveFiles {
+		fullPath := filepath.Join(archiveDir, filename)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	// a dry run counts the values without changing the archive
+	count, gotLegacyKey, err := ReencryptArchive(archiveDir, true)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, legacyKey, gotLegacyKey)
+	for filename, content := range archiveFiles {
+		b, err := os.ReadFile(filepath.Join(archiveDir, filename))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b))
+	}
+
+	require.NoError(t, crypto.SetEncryptionKey(newKey))
+
+	count, gotLegacyKey, err = ReencryptArchive(archiveDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, legacyKey, gotLegacyKey)
+
+	// everything must be readable with only the new key
+	require.NoError(t, crypto.RemoveDecryptionKey(oldKey))
+	require.NoError(t, crypto.RemoveDecryptionKey(legacyKey))
+
+	for _, configFile := range []string{"upstream/userdata/config.yaml", "kotsKinds/userdata/config.yaml"} {
+		values, err := kotsutil.LoadConfigValuesFromFile(filepath.Join(archiveDir, configFile))
+		require.NoError(t, err)
+
+		assert.Equal(t, "my-app.example.com", values.Spec.Values["hostname"].Value)
+		assert.Equal(t, "ref+vault://secret/data/my-app#password", values.Spec.Values["db_password"].Value)
+
+		for name, want := range map[string]string{"password": "password", "legacy_password": "legacy-password"} {
+			assert.NotEqual(t, password, values.Spec.Values[name].Value)
+			decoded, err := base64.StdEncoding.DecodeString(values.Spec.Values[name].Value)
+			require.NoError(t, err)
+			decrypted, err := crypto.Decrypt(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(decrypted))
+		}
+	}
+
+	gotInstallation, err := kotsutil.LoadInstallationFromPath(filepath.Join(archiveDir, "upstream", "userdata", "installation.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "", gotInstallation.Spec.EncryptionKey)
+	assert.Equal(t, "1.0.0", gotInstallation.Spec.VersionLabel)
+
+	gotIdentityConfig, err := upstream.LoadIdentityConfig(filepath.Join(archiveDir, "upstream"))
+	require.NoError(t, err)
+	gotClientSecret, err := gotIdentityConfig.Spec.ClientSecret.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, "client-secret", gotClientSecret)
+
+	// the second pass finds nothing left to remove
+	_, gotLegacyKey, err = ReencryptArchive(archiveDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, "", gotLegacyKey)
+}
+
+func Test_parseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: []string{},
+		},
+		{
+			name: "duplicates and blank lines",
+			data: "key-a\n\nkey-b\nkey-a\n",
+			want: []string{"key-a", "key-b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, parseKeys([]byte(test.data)))
+		})
+	}
+}
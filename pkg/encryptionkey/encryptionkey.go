@@ -0,0 +1,353 @@
+package encryptionkey
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/encryptionkey/types"
+	"github.com/replicatedhq/kots/pkg/gitops"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	TaskID = "rotate-encryption-key"
+
+	secretName = "kotsadm-encryption"
+	// encryptionKeyField is the key used to encrypt data, kotsadm reads it from the environment on startup
+	encryptionKeyField = "encryptionKey"
+	// retiringKeysField holds the previous keys, one per line, until nothing is encrypted with them anymore
+	retiringKeysField = "retiringEncryptionKeys"
+)
+
+var (
+	lastReportMtx sync.Mutex
+	lastReport    *types.RotationReport
+)
+
+// LoadRetiringKeys adds the keys that are being retired to the list of decryption keys, so that data that has not been
+// re-encrypted yet can still be read after a restart.
+func LoadRetiringKeys(clientset kubernetes.Interface, namespace string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	for _, key := range parseKeys(secret.Data[retiringKeysField]) {
+		if err := crypto.InitFromString(key); err != nil {
+			return errors.Wrap(err, "failed to load retiring encryption key")
+		}
+	}
+
+	return nil
+}
+
+// StartRotation rotates the encryption key in the background. Progress is reported in the task status and the report
+// can be read with GetLastReport once the task is done.
+func StartRotation(clientset kubernetes.Interface, namespace string) error {
+	status, _, err := store.GetStore().GetTaskStatus(TaskID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get task status")
+	}
+	if status == "running" {
+		return errors.New("encryption key rotation is already running")
+	}
+
+	if err := store.GetStore().SetTaskStatus(TaskID, "Rotating encryption key", "running"); err != nil {
+		return errors.Wrap(err, "failed to set task status")
+	}
+
+	setLastReport(nil)
+
+	go func() {
+		finishedCh := make(chan struct{})
+		defer close(finishedCh)
+		go func() {
+			for {
+				select {
+				case <-time.After(time.Second):
+					if err := store.GetStore().UpdateTaskStatusTimestamp(TaskID); err != nil {
+						logger.Error(err)
+					}
+				case <-finishedCh:
+					return
+				}
+			}
+		}()
+
+		report, err := Rotate(clientset, namespace, false)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to rotate encryption key"))
+			if err := store.GetStore().SetTaskStatus(TaskID, errors.Cause(err).Error(), "failed"); err != nil {
+				logger.Error(errors.Wrap(err, "failed to set rotate encryption key task status as failed"))
+			}
+			return
+		}
+
+		setLastReport(report)
+
+		if len(report.Errors) > 0 {
+			msg := fmt.Sprintf("Failed to re-encrypt %d item(s), the previous key was not retired", len(report.Errors))
+			if err := store.GetStore().SetTaskStatus(TaskID, msg, "failed"); err != nil {
+				logger.Error(errors.Wrap(err, "failed to set rotate encryption key task status as failed"))
+			}
+			return
+		}
+
+		if err := store.GetStore().ClearTaskStatus(TaskID); err != nil {
+			logger.Error(errors.Wrap(err, "failed to clear rotate encryption key task status"))
+		}
+	}()
+
+	return nil
+}
+
+// GetLastReport returns the report of the last rotation that ran to completion since kotsadm started
+func GetLastReport() *types.RotationReport {
+	lastReportMtx.Lock()
+	defer lastReportMtx.Unlock()
+	return lastReport
+}
+
+func setLastReport(report *types.RotationReport) {
+	lastReportMtx.Lock()
+	defer lastReportMtx.Unlock()
+	lastReport = report
+}
+
+// Rotate generates a new encryption key, re-encrypts everything that was encrypted with the previous keys and retires them
+// once nothing depends on them anymore. Items that fail to re-encrypt are listed in the report and keep the previous keys around.
+// A dry run reports what would be re-encrypted without changing anything.
+func Rotate(clientset kubernetes.Interface, namespace string, dryRun bool) (*types.RotationReport, error) {
+	report := &types.RotationReport{
+		DryRun:            dryRun,
+		AppVersions:       []types.AppVersionReport{},
+		RegistryPasswords: []string{},
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	retiringKeys := parseKeys(secret.Data[retiringKeysField])
+	retiringKeys = appendKey(retiringKeys, string(secret.Data[encryptionKeyField]))
+	retiringKeys = appendKey(retiringKeys, crypto.ToString())
+
+	if !dryRun {
+		newKey, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate encryption key")
+		}
+
+		// persist the new key before anything is encrypted with it
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[encryptionKeyField] = []byte(newKey)
+		secret.Data[retiringKeysField] = []byte(strings.Join(retiringKeys, "\n"))
+		if _, err := clientset.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return nil, errors.Wrap(err, "failed to update encryption secret")
+		}
+
+		if err := crypto.SetEncryptionKey(newKey); err != nil {
+			return nil, errors.Wrap(err, "failed to set encryption key")
+		}
+	}
+
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list installed apps")
+	}
+
+	for _, app := range apps {
+		// versions can be created from a version that was not re-encrypted yet while the key is rotated,
+		// so the versions are listed again until no new version is found
+		seen := map[int64]bool{}
+		for {
+			versions, err := store.GetStore().FindDownstreamVersions(app.ID, true)
+			if err != nil {
+				report.Errors = append(report.Errors, errors.Wrapf(err, "failed to find versions for app %s", app.Slug).Error())
+				break
+			}
+
+			found := false
+			for _, version := range versions.AllVersions {
+				if seen[version.ParentSequence] {
+					continue
+				}
+				seen[version.ParentSequence] = true
+				found = true
+
+				versionReport, legacyKey, err := reencryptAppVersion(clientset, namespace, app.ID, app.Slug, version.ParentSequence, dryRun)
+				if err != nil {
+					report.Errors = append(report.Errors, errors.Wrapf(err, "failed to re-encrypt app %s sequence %d", app.Slug, version.ParentSequence).Error())
+					continue
+				}
+				if legacyKey != "" {
+					retiringKeys = appendKey(retiringKeys, legacyKey)
+				}
+				if versionReport.EncryptedValues > 0 || versionReport.LegacyKey {
+					report.AppVersions = append(report.AppVersions, *versionReport)
+				}
+			}
+			if !found {
+				break
+			}
+		}
+
+		registrySettings, err := store.GetStore().GetRegistryDetailsForApp(app.ID)
+		if err != nil {
+			report.Errors = append(report.Errors, errors.Wrapf(err, "failed to get registry settings for app %s", app.Slug).Error())
+			continue
+		}
+		if registrySettings.Password == "" {
+			continue
+		}
+		if !dryRun {
+			err := store.GetStore().UpdateRegistry(app.ID, registrySettings.Hostname, registrySettings.Username, registrySettings.Password, registrySettings.Namespace, registrySettings.IsReadOnly)
+			if err != nil {
+				report.Errors = append(report.Errors, errors.Wrapf(err, "failed to re-encrypt registry password for app %s", app.Slug).Error())
+				continue
+			}
+		}
+		report.RegistryPasswords = append(report.RegistryPasswords, app.Slug)
+	}
+
+	gitOpsValues, err := gitops.ReencryptGitOpsSecret(clientset, dryRun)
+	if err != nil {
+		report.Errors = append(report.Errors, errors.Wrap(err, "failed to re-encrypt gitops secret").Error())
+	}
+	report.GitOpsValues = gitOpsValues
+
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if dryRun {
+		report.RetiredKeys = len(retiringKeys)
+		return report, nil
+	}
+
+	if err := retireKeys(clientset, namespace, retiringKeys); err != nil {
+		return nil, errors.Wrap(err, "failed to retire encryption keys")
+	}
+	report.RetiredKeys = len(retiringKeys)
+
+	return report, nil
+}
+
+func reencryptAppVersion(clientset kubernetes.Interface, namespace string, appID string, appSlug string, sequence int64, dryRun bool) (*types.AppVersionReport, string, error) {
+	// config changes to the version are saved in the same archive, they must not be overwritten by the re-encrypted archive
+	unlock := util.LockAppVersion(appID, sequence)
+	defer unlock()
+
+	archiveDir, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(archiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(appID, sequence, archiveDir); err != nil {
+		return nil, "", errors.Wrap(err, "failed to get app version archive")
+	}
+
+	count, legacyKey, err := ReencryptArchive(archiveDir, dryRun)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to re-encrypt archive")
+	}
+
+	if !dryRun && legacyKey != "" {
+		// the legacy key is only read from the archive on startup, keep it until the rotation completes
+		if err := addRetiringKey(clientset, namespace, legacyKey); err != nil {
+			return nil, "", errors.Wrap(err, "failed to add legacy key to the retiring keys")
+		}
+	}
+
+	if !dryRun && (count > 0 || legacyKey != "") {
+		if err := store.GetStore().UpdateAppVersionArchive(appID, sequence, archiveDir); err != nil {
+			return nil, "", errors.Wrap(err, "failed to update app version archive")
+		}
+	}
+
+	return &types.AppVersionReport{
+		AppSlug:         appSlug,
+		Sequence:        sequence,
+		EncryptedValues: count,
+		LegacyKey:       legacyKey != "",
+	}, legacyKey, nil
+}
+
+func addRetiringKey(clientset kubernetes.Interface, namespace string, key string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	retiringKeys := parseKeys(secret.Data[retiringKeysField])
+	updatedKeys := appendKey(retiringKeys, key)
+	if len(updatedKeys) == len(retiringKeys) {
+		return nil
+	}
+
+	secret.Data[retiringKeysField] = []byte(strings.Join(updatedKeys, "\n"))
+	if _, err := clientset.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update encryption secret")
+	}
+
+	return nil
+}
+
+func retireKeys(clientset kubernetes.Interface, namespace string, keys []string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	delete(secret.Data, retiringKeysField)
+	if _, err := clientset.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update encryption secret")
+	}
+
+	for _, key := range keys {
+		if err := crypto.RemoveDecryptionKey(key); err != nil {
+			return errors.Wrap(err, "failed to remove decryption key")
+		}
+	}
+
+	return nil
+}
+
+func parseKeys(data []byte) []string {
+	keys := []string{}
+	for _, key := range strings.Split(string(data), "\n") {
+		keys = appendKey(keys, key)
+	}
+	return keys
+}
+
+func appendKey(keys []string, key string) []string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return keys
+	}
+	for _, k := range keys {
+		if k == key {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
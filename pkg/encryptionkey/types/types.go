@@ -0,0 +1,18 @@
+package types
+
+type RotationReport struct {
+	DryRun            bool               `json:"dryRun"`
+	AppVersions       []AppVersionReport `json:"appVersions"`
+	RegistryPasswords []string           `json:"registryPasswords"`
+	GitOpsValues      int                `json:"gitOpsValues"`
+	RetiredKeys       int                `json:"retiredKeys"`
+	Errors            []string           `json:"errors,omitempty"`
+}
+
+type AppVersionReport struct {
+	AppSlug         string `json:"appSlug"`
+	Sequence        int64  `json:"sequence"`
+	EncryptedValues int    `json:"encryptedValues"`
+	// LegacyKey is true when the version carries its own encryption key in the installation spec
+	LegacyKey bool `json:"legacyKey"`
+}
@@ -15,13 +15,16 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	go_git_ssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/mikesmitty/edkey"
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kots/pkg/apparchive"
+	kotsv1beta2 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta2"
 	"github.com/replicatedhq/kots/pkg/crypto"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsadm/types"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
@@ -33,19 +36,33 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// ActionCommit pushes a commit for each version to the configured branch
+	ActionCommit = "commit"
+	// ActionPullRequest pushes each version to a new branch and opens a pull request against the configured branch
+	ActionPullRequest = "pull_request"
+)
+
 type GitOpsConfig struct {
-	Provider    string `json:"provider"`
-	RepoURI     string `json:"repoUri"`
-	Hostname    string `json:"hostname"`
-	HTTPPort    string `json:"httpPort"`
-	SSHPort     string `json:"sshPort"`
-	Path        string `json:"path"`
-	Branch      string `json:"branch"`
-	Format      string `json:"format"`
-	Action      string `json:"action"`
-	PublicKey   string `json:"publicKey"`
-	PrivateKey  string `json:"-"`
-	IsConnected bool   `json:"isConnected"`
+	Provider string `json:"provider"`
+	RepoURI  string `json:"repoUri"`
+	Hostname string `json:"hostname"`
+	HTTPPort string `json:"httpPort"`
+	SSHPort  string `json:"sshPort"`
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	Format   string `json:"format"`
+	Action   string `json:"action"`
+	// Wrapper is the kind of gitops controller resource that is committed with the app, if any
+	Wrapper string `json:"wrapper"`
+	// WrapperNamespace is the namespace of the wrapper, defaults to the namespace of the default installation of the gitops controller
+	WrapperNamespace string `json:"wrapperNamespace"`
+	PublicKey        string `json:"publicKey"`
+	PrivateKey       string `json:"-"`
+	Token            string `json:"-"`
+	IsConnected      bool   `json:"isConnected"`
+	// PullRequest is the most recent pull request opened when the action is pull_request
+	PullRequest *gitopstypes.PullRequest `json:"pullRequest,omitempty"`
 }
 
 type GlobalGitOpsConfig struct {
@@ -79,11 +96,34 @@ func (g *GitOpsConfig) CommitURL(hash string) string {
 }
 
 func (g *GitOpsConfig) CloneURL() (string, error) {
+	owner, repo, err := g.ownerAndRepo()
+	if err != nil {
+		return "", err
+	}
+
+	switch g.Provider {
+	case "github":
+		return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo), nil
+	case "gitlab":
+		return fmt.Sprintf("git@gitlab.com:%s/%s.git", owner, repo), nil
+	case "bitbucket":
+		return fmt.Sprintf("git@bitbucket.org:%s/%s.git", owner, repo), nil
+	case "bitbucket_server":
+		return fmt.Sprintf("git@%s:%s/%s/%s.git", g.Hostname, g.SSHPort, owner, repo), nil
+	case "github_enterprise", "gitlab_enterprise", "gitea":
+		return fmt.Sprintf("git@%s:%s/%s.git", g.Hostname, owner, repo), nil
+	}
+
+	return "", errors.Errorf("unsupported provider type: %s", g.Provider)
+}
+
+// ownerAndRepo returns the owner (or project for bitbucket server) and the name of the repo.
+func (g *GitOpsConfig) ownerAndRepo() (string, string, error) {
 	// copied this logic from node js api
 	uriParts := strings.Split(g.RepoURI, "/")
 
 	if len(uriParts) < 5 {
-		return "", errors.Errorf("unexpected url format: %s", g.RepoURI)
+		return "", "", errors.Errorf("unexpected url format: %s", g.RepoURI)
 	}
 
 	owner := uriParts[3]
@@ -91,26 +131,13 @@ func (g *GitOpsConfig) CloneURL() (string, error) {
 
 	if g.Provider == "bitbucket_server" {
 		if len(uriParts) < 7 {
-			return "", errors.Errorf("unexpected bitbucket server url format: %s", g.RepoURI)
+			return "", "", errors.Errorf("unexpected bitbucket server url format: %s", g.RepoURI)
 		}
 		owner = uriParts[4]
 		repo = uriParts[6]
 	}
 
-	switch g.Provider {
-	case "github":
-		return fmt.Sprintf("git@github.com:%s/%s.git", owner, repo), nil
-	case "gitlab":
-		return fmt.Sprintf("git@gitlab.com:%s/%s.git", owner, repo), nil
-	case "bitbucket":
-		return fmt.Sprintf("git@bitbucket.org:%s/%s.git", owner, repo), nil
-	case "bitbucket_server":
-		return fmt.Sprintf("git@%s:%s/%s/%s.git", g.Hostname, g.SSHPort, owner, repo), nil
-	case "github_enterprise", "gitlab_enterprise":
-		return fmt.Sprintf("git@%s:%s/%s.git", g.Hostname, owner, repo), nil
-	}
-
-	return "", errors.Errorf("unsupported provider type: %s", g.Provider)
+	return owner, repo, nil
 }
 
 // GetDownstreamGitOps will return the gitops config for a downstream,
@@ -179,23 +206,44 @@ func GetDownstreamGitOpsConfig(clientset kubernetes.Interface, appID string, clu
 				}
 
 				gitOpsConfig := GitOpsConfig{
-					Provider:   provider,
-					PublicKey:  publicKey,
-					PrivateKey: string(decryptedPrivateKey),
-					RepoURI:    repoURI,
-					Hostname:   hostname,
-					HTTPPort:   httpPort,
-					SSHPort:    sshPort,
-					Branch:     configMapData["branch"],
-					Path:       configMapData["path"],
-					Format:     configMapData["format"],
-					Action:     configMapData["action"],
+					Provider:         provider,
+					PublicKey:        publicKey,
+					PrivateKey:       string(decryptedPrivateKey),
+					RepoURI:          repoURI,
+					Hostname:         hostname,
+					HTTPPort:         httpPort,
+					SSHPort:          sshPort,
+					Branch:           configMapData["branch"],
+					Path:             configMapData["path"],
+					Format:           configMapData["format"],
+					Action:           configMapData["action"],
+					Wrapper:          configMapData["wrapper"],
+					WrapperNamespace: configMapData["wrapperNamespace"],
+				}
+
+				if encodedToken, ok := secret.Data[fmt.Sprintf("provider.%d.token", idx)]; ok {
+					decodedToken, err := base64.StdEncoding.DecodeString(string(encodedToken))
+					if err != nil {
+						return nil, errors.Wrap(err, "failed to decode token")
+					}
+					decryptedToken, err := crypto.Decrypt(decodedToken)
+					if err != nil {
+						return nil, errors.Wrap(err, "failed to decrypt token")
+					}
+					gitOpsConfig.Token = string(decryptedToken)
 				}
 
 				if lastError, ok := configMapData["lastError"]; ok && lastError == "" {
 					gitOpsConfig.IsConnected = true
 				}
 
+				if pullRequest := configMapData["pullRequest"]; pullRequest != "" {
+					gitOpsConfig.PullRequest = &gitopstypes.PullRequest{}
+					if err := json.Unmarshal([]byte(pullRequest), gitOpsConfig.PullRequest); err != nil {
+						return nil, errors.Wrap(err, "failed to unmarshal pull request")
+					}
+				}
+
 				return &gitOpsConfig, nil
 			}
 		}
@@ -315,17 +363,17 @@ func deleteKeysFromGitOpsSecret(clientset kubernetes.Interface, repoURL string)
 	return nil
 }
 
-func UpdateDownstreamGitOps(appID, clusterID, uri, branch, path, format, action string) error {
+func UpdateDownstreamGitOps(appID, clusterID, uri, branch, path, format, action, wrapper, wrapperNamespace string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s client set")
 	}
 
-	err = updateDownstreamGitOps(clientset, appID, clusterID, uri, branch, path, format, action)
+	err = updateDownstreamGitOps(clientset, appID, clusterID, uri, branch, path, format, action, wrapper, wrapperNamespace)
 	return errors.Wrap(err, "failed to update downstream gitops config")
 }
 
-func updateDownstreamGitOps(clientset kubernetes.Interface, appID, clusterID, uri, branch, path, format, action string) error {
+func updateDownstreamGitOps(clientset kubernetes.Interface, appID, clusterID, uri, branch, path, format, action, wrapper, wrapperNamespace string) error {
 	configMap, err := clientset.CoreV1().ConfigMaps(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
 	if err != nil && !kuberneteserrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed to get configmap")
@@ -343,11 +391,13 @@ func updateDownstreamGitOps(clientset kubernetes.Interface, appID, clusterID, ur
 
 	appKey := fmt.Sprintf("%s-%s", appID, clusterID)
 	newAppData := map[string]string{
-		"repoUri": uri,
-		"branch":  branch,
-		"path":    path,
-		"format":  format,
-		"action":  action,
+		"repoUri":          uri,
+		"branch":           branch,
+		"path":             path,
+		"format":           format,
+		"action":           action,
+		"wrapper":          wrapper,
+		"wrapperNamespace": wrapperNamespace,
 	}
 
 	// check if to reset or keep last error
@@ -370,6 +420,10 @@ func updateDownstreamGitOps(clientset kubernetes.Interface, appID, clusterID, ur
 			if ok {
 				newAppData["lastError"] = lastError // keep last error
 			}
+			pullRequest, ok := appDataUnmarshalled["pullRequest"]
+			if ok {
+				newAppData["pullRequest"] = pullRequest // keep the pull request that targets this branch
+			}
 		}
 	}
 
@@ -411,6 +465,36 @@ func SetGitOpsError(appID string, clusterID string, errMsg string) error {
 		return errors.Wrap(err, "failed to get k8s client set")
 	}
 
+	err = setGitOpsAppData(clientset, appID, clusterID, "lastError", errMsg)
+	return errors.Wrap(err, "failed to set gitops error")
+}
+
+// SetGitOpsPullRequest records the most recent pull request opened for a downstream
+func SetGitOpsPullRequest(appID string, clusterID string, pullRequest *gitopstypes.PullRequest) error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client set")
+	}
+
+	err = setGitOpsPullRequest(clientset, appID, clusterID, pullRequest)
+	return errors.Wrap(err, "failed to set gitops pull request")
+}
+
+func setGitOpsPullRequest(clientset kubernetes.Interface, appID string, clusterID string, pullRequest *gitopstypes.PullRequest) error {
+	marshalledPullRequest := ""
+	if pullRequest != nil {
+		b, err := json.Marshal(pullRequest)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal pull request")
+		}
+		marshalledPullRequest = string(b)
+	}
+
+	return setGitOpsAppData(clientset, appID, clusterID, "pullRequest", marshalledPullRequest)
+}
+
+// setGitOpsAppData sets a single key in the gitops config map data of a downstream
+func setGitOpsAppData(clientset kubernetes.Interface, appID string, clusterID string, key string, value string) error {
 	configMap, err := clientset.CoreV1().ConfigMaps(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to get configmap")
@@ -431,7 +515,7 @@ func SetGitOpsError(appID string, clusterID string, errMsg string) error {
 	if err := json.Unmarshal(appDataDecoded, &appDataUnmarshalled); err != nil {
 		return errors.Wrap(err, "failed to unmarshal app data")
 	}
-	appDataUnmarshalled["lastError"] = errMsg
+	appDataUnmarshalled[key] = value
 
 	appDataDecoded, err = json.Marshal(appDataUnmarshalled)
 	if err != nil {
@@ -484,17 +568,19 @@ func TestGitOpsConnection(gitOpsConfig *GitOpsConfig) (string, error) {
 	return ref.Name().Short(), nil
 }
 
-func CreateGitOps(provider string, repoURI string, hostname string, httpPort string, sshPort string) error {
+// CreateGitOps configures a gitops provider. The token is only needed to open pull requests,
+// and an empty token keeps the token that is already configured for the repo, if any.
+func CreateGitOps(provider string, repoURI string, hostname string, httpPort string, sshPort string, token string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s client set")
 	}
 
-	err = createGitOps(clientset, provider, repoURI, hostname, httpPort, sshPort)
+	err = createGitOps(clientset, provider, repoURI, hostname, httpPort, sshPort, token)
 	return errors.Wrap(err, "failed to create gitops")
 }
 
-func createGitOps(clientset kubernetes.Interface, provider string, repoURI string, hostname string, httpPort string, sshPort string) error {
+func createGitOps(clientset kubernetes.Interface, provider string, repoURI string, hostname string, httpPort string, sshPort string, token string) error {
 	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
 	if err != nil && !kuberneteserrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed to get secret")
@@ -586,6 +672,11 @@ func createGitOps(clientset kubernetes.Interface, provider string, repoURI strin
 		secretData[sshPortKey] = []byte(sshPort)
 	}
 
+	if token != "" {
+		encryptedToken := crypto.Encrypt([]byte(token))
+		secretData[fmt.Sprintf("provider.%d.token", repoIdx)] = []byte(base64.StdEncoding.EncodeToString(encryptedToken))
+	}
+
 	if secretExists {
 		secret.Data = secretData
 		_, err = clientset.CoreV1().Secrets(util.PodNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
@@ -629,6 +720,59 @@ func ResetGitOps() error {
 	return nil
 }
 
+// ReencryptGitOpsSecret re-encrypts the provider private keys and tokens in the gitops secret with the current encryption key.
+// It returns the number of values that were (or, for a dry run, would be) re-encrypted.
+func ReencryptGitOpsSecret(clientset kubernetes.Interface, dryRun bool) (int, error) {
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "get kotsadm-gitops secret")
+	}
+
+	count, err := reencryptGitOpsSecretData(secret.Data)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 || dryRun {
+		return count, nil
+	}
+
+	_, err = clientset.CoreV1().Secrets(util.PodNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to update secret")
+	}
+
+	return count, nil
+}
+
+func reencryptGitOpsSecretData(secretData map[string][]byte) (int, error) {
+	count := 0
+	for key, val := range secretData {
+		splitKey := strings.Split(key, ".")
+		if len(splitKey) != 3 {
+			continue
+		}
+		if splitKey[2] != "privateKey" && splitKey[2] != "token" {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(string(val))
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to decode %s", key)
+		}
+		decrypted, err := crypto.Decrypt(decoded)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to decrypt %s", key)
+		}
+
+		secretData[key] = []byte(base64.StdEncoding.EncodeToString(crypto.Encrypt(decrypted)))
+		count++
+	}
+
+	return count, nil
+}
+
 func GetGitOps() (GlobalGitOpsConfig, error) {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
@@ -712,15 +856,30 @@ func getAuth(privateKey string) (transport.AuthMethod, error) {
 	return auth, nil
 }
 
-func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName string, newSequence int, archiveDir string, downstreamName string) (string, error) {
+// CreateGitOpsCommit commits the rendered app to the gitops repo and returns a link to the change.
+// When the action is pull_request, the commit is pushed to a new branch and the link is to the pull request opened for it.
+func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appID string, clusterID string, appSlug string, appName string, newSequence int, archiveDir string, downstreamName string) (string, error) {
 	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archiveDir, "upstream"))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to load kots kinds")
 	}
 
-	out, _, err := apparchive.GetRenderedApp(archiveDir, downstreamName, kotsKinds.GetKustomizeBinaryPath())
+	v1Beta2Charts := []kotsv1beta2.HelmChart{}
+	if kotsKinds.V1Beta2HelmCharts != nil {
+		v1Beta2Charts = kotsKinds.V1Beta2HelmCharts.Items
+	}
+
+	files, err := getAppRepoFiles(gitOpsConfig, appSlug, archiveDir, downstreamName, kotsKinds.GetKustomizeBinaryPath(), v1Beta2Charts)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get rendered app")
+		return "", errors.Wrap(err, "failed to get app files")
+	}
+
+	var pullRequestProvider PullRequestProvider
+	if gitOpsConfig.Action == ActionPullRequest {
+		pullRequestProvider, err = GetPullRequestProvider(gitOpsConfig)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get pull request provider")
+		}
 	}
 
 	// using the deploy key, create the commit in a new branch
@@ -751,40 +910,63 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 		return "", err
 	}
 
-	dirPath := filepath.Join(workDir, gitOpsConfig.Path)
-	_, err = os.Stat(dirPath)
-	if os.IsNotExist(err) {
-		// create subdirectory if not exist
-		err := os.MkdirAll(dirPath, 0755)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to mkdir")
+	pushOptions := &git.PushOptions{
+		RemoteName: cloneOptions.RemoteName,
+		Auth:       auth,
+	}
+
+	pullRequestBranch := ""
+	if pullRequestProvider != nil {
+		// the pull request branch is created from the branch that the pull request will be merged into
+		baseRefName := plumbing.NewRemoteReferenceName(cloneOptions.RemoteName, gitOpsConfig.Branch)
+		if _, err := cloned.Reference(baseRefName, false); err != nil {
+			return "", errors.Wrapf(err, "failed to find branch %s to open pull requests against", gitOpsConfig.Branch)
 		}
-	} // ignore error here and let the stat of the file below handle any errors
 
-	filePath := filepath.Join(dirPath, fmt.Sprintf("%s.yaml", appSlug))
-	_, err = os.Stat(filePath)
-	if err == nil { // if the file has not changed, end now
-		currentRevision, err := ioutil.ReadFile(filePath)
+		pullRequestBranch = fmt.Sprintf("kots/%s-%d", appSlug, newSequence)
+		err := workTree.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(pullRequestBranch),
+			Create: true,
+		})
 		if err != nil {
-			return "", errors.Wrap(err, "failed to read current app yaml")
+			return "", errors.Wrapf(err, "failed to create branch %s", pullRequestBranch)
 		}
-		if string(currentRevision) == string(out) {
-			return "", nil
+
+		// force push so that a version can be pushed again if a previous attempt failed after pushing
+		pushOptions.RefSpecs = []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/heads/%s", pullRequestBranch, pullRequestBranch)),
 		}
-	} else if !os.IsNotExist(err) {
-		return "", errors.Wrap(err, "failed to stat current app yaml")
 	}
 
-	err = ioutil.WriteFile(filePath, out, 0644)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to write updated app yaml")
+	// remove what was committed for the previous version so that files that are no longer part of the app are deleted
+	for _, appPath := range appRepoPaths(gitOpsConfig, appSlug) {
+		if err := os.RemoveAll(filepath.Join(workDir, appPath)); err != nil {
+			return "", errors.Wrapf(err, "failed to remove %s", appPath)
+		}
 	}
 
-	_, err = workTree.Add(strings.TrimPrefix(filepath.Join(gitOpsConfig.Path, fmt.Sprintf("%s.yaml", appSlug)), "/"))
-	if err != nil {
+	for filename, content := range files {
+		filePath := filepath.Join(workDir, filename)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to mkdir")
+		}
+		if err := ioutil.WriteFile(filePath, content, 0644); err != nil {
+			return "", errors.Wrapf(err, "failed to write %s", filename)
+		}
+	}
+
+	if err := workTree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
 		return "", errors.Wrap(err, "failed to add to worktree")
 	}
 
+	status, err := workTree.Status()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get worktree status")
+	}
+	if status.IsClean() { // if the app has not changed, end now
+		return "", nil
+	}
+
 	// commit it
 	updatedHash, err := workTree.Commit(fmt.Sprintf("Updating %s to version %d", appName, newSequence), &git.CommitOptions{
 		Author: &object.Signature{
@@ -797,15 +979,60 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 		return "", errors.Wrap(err, "failed to commit")
 	}
 
-	err = cloned.Push(&git.PushOptions{
-		RemoteName: cloneOptions.RemoteName,
-		Auth:       auth,
-	})
+	err = cloned.Push(pushOptions)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to push")
 	}
 
-	return gitOpsConfig.CommitURL(updatedHash.String()), nil
+	if pullRequestProvider == nil {
+		return gitOpsConfig.CommitURL(updatedHash.String()), nil
+	}
+
+	pullRequest, err := pullRequestProvider.CreatePullRequest(CreatePullRequestOptions{
+		Title: fmt.Sprintf("Update %s to version %d", appName, newSequence),
+		Body:  fmt.Sprintf("This pull request was opened by the KOTS Admin Console to update %s to version %d.", appName, newSequence),
+		Head:  pullRequestBranch,
+		Base:  gitOpsConfig.Branch,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open pull request")
+	}
+	pullRequest.Branch = pullRequestBranch
+	pullRequest.Sequence = int64(newSequence)
+
+	if err := SetGitOpsPullRequest(appID, clusterID, pullRequest); err != nil {
+		return "", errors.Wrap(err, "failed to save pull request")
+	}
+	gitOpsConfig.PullRequest = pullRequest
+
+	return pullRequest.URL, nil
+}
+
+// RefreshPullRequest updates the state of the most recent pull request from the provider, if it is still open.
+func RefreshPullRequest(appID string, clusterID string, gitOpsConfig *GitOpsConfig) error {
+	if gitOpsConfig.PullRequest == nil || gitOpsConfig.PullRequest.State != gitopstypes.PullRequestOpen {
+		return nil
+	}
+
+	provider, err := GetPullRequestProvider(gitOpsConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pull request provider")
+	}
+
+	current, err := provider.GetPullRequest(gitOpsConfig.PullRequest.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pull request")
+	}
+	if current.State == gitOpsConfig.PullRequest.State {
+		return nil
+	}
+
+	gitOpsConfig.PullRequest.State = current.State
+	if err := SetGitOpsPullRequest(appID, clusterID, gitOpsConfig.PullRequest); err != nil {
+		return errors.Wrap(err, "failed to save pull request")
+	}
+
+	return nil
 }
 
 func generatePrivateKey_ed25519() (*KeyPair, error) {
@@ -4,7 +4,10 @@ import (
 	"encoding/base64"
 	"testing"
 
+	"github.com/replicatedhq/kots/pkg/crypto"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,20 +19,22 @@ import (
 
 func Test_createGitOps(t *testing.T) {
 	tests := []struct {
-		name        string
-		appID       string
-		clusterID   string
-		provider    string
-		repoURI     string
-		hostname    string
-		httpPort    string
-		sshPort     string
-		configIndex int64
-		action      string
-		branch      string
-		format      string
-		path        string
-		wantKeyType string
+		name             string
+		appID            string
+		clusterID        string
+		provider         string
+		repoURI          string
+		hostname         string
+		httpPort         string
+		sshPort          string
+		configIndex      int64
+		action           string
+		branch           string
+		format           string
+		wrapper          string
+		wrapperNamespace string
+		path             string
+		wantKeyType      string
 	}{
 		{
 			name:        "gitlab provider",
@@ -60,28 +65,30 @@ func Test_createGitOps(t *testing.T) {
 			wantKeyType: "ssh-ed25519",
 		},
 		{
-			name:        "github enterprise provider",
-			provider:    "github_enterprise",
-			repoURI:     "https://1.2.3.5/test_org/test_repo",
-			hostname:    "1.2.3.5",
-			httpPort:    "",
-			sshPort:     "",
-			configIndex: 1,
-			action:      "commit",
-			branch:      "test2-branch",
-			format:      "single",
-			path:        "/test/path/2",
-			wantKeyType: "ssh-ed25519",
+			name:             "github enterprise provider",
+			provider:         "github_enterprise",
+			repoURI:          "https://1.2.3.5/test_org/test_repo",
+			hostname:         "1.2.3.5",
+			httpPort:         "",
+			sshPort:          "",
+			configIndex:      1,
+			action:           "commit",
+			branch:           "test2-branch",
+			format:           "kustomize",
+			wrapper:          "argocd",
+			wrapperNamespace: "gitops",
+			path:             "/test/path/2",
+			wantKeyType:      "ssh-ed25519",
 		},
 	}
 
 	clientset := fake.NewSimpleClientset()
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := createGitOps(clientset, test.provider, test.repoURI, test.hostname, test.httpPort, test.sshPort)
+			err := createGitOps(clientset, test.provider, test.repoURI, test.hostname, test.httpPort, test.sshPort, "")
 			assert.NoError(t, err)
 
-			err = updateDownstreamGitOps(clientset, test.appID, test.clusterID, test.repoURI, test.branch, test.path, test.format, test.action)
+			err = updateDownstreamGitOps(clientset, test.appID, test.clusterID, test.repoURI, test.branch, test.path, test.format, test.action, test.wrapper, test.wrapperNamespace)
 			assert.NoError(t, err)
 
 			config, err := GetDownstreamGitOpsConfig(clientset, test.appID, test.clusterID)
@@ -95,6 +102,8 @@ func Test_createGitOps(t *testing.T) {
 			assert.Equal(t, test.action, config.Action)
 			assert.Equal(t, test.branch, config.Branch)
 			assert.Equal(t, test.format, config.Format)
+			assert.Equal(t, test.wrapper, config.Wrapper)
+			assert.Equal(t, test.wrapperNamespace, config.WrapperNamespace)
 			assert.Equal(t, test.path, config.Path)
 
 			publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.PublicKey))
@@ -105,6 +114,54 @@ func Test_createGitOps(t *testing.T) {
 	}
 }
 
+func Test_gitOpsPullRequest(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	appID, clusterID := "test-app", "test-cluster"
+	repoURI := "https://github.com/test_org/test_repo"
+
+	err := createGitOps(clientset, "github", repoURI, "", "", "", "test-token")
+	require.NoError(t, err)
+
+	err = updateDownstreamGitOps(clientset, appID, clusterID, repoURI, "main", "/", "single", ActionPullRequest, "", "")
+	require.NoError(t, err)
+
+	pullRequest := &gitopstypes.PullRequest{
+		ID:       12,
+		URL:      "https://github.com/test_org/test_repo/pull/12",
+		Branch:   "kots/my-app-5",
+		State:    gitopstypes.PullRequestOpen,
+		Sequence: 5,
+	}
+	err = setGitOpsPullRequest(clientset, appID, clusterID, pullRequest)
+	require.NoError(t, err)
+
+	config, err := GetDownstreamGitOpsConfig(clientset, appID, clusterID)
+	require.NoError(t, err)
+	assert.Equal(t, "test-token", config.Token)
+	assert.Equal(t, ActionPullRequest, config.Action)
+	assert.Equal(t, pullRequest, config.PullRequest)
+
+	// an empty token keeps the existing token
+	err = createGitOps(clientset, "github", repoURI, "", "", "", "")
+	require.NoError(t, err)
+
+	// the pull request is kept while the branch does not change
+	err = updateDownstreamGitOps(clientset, appID, clusterID, repoURI, "main", "/apps", "single", ActionPullRequest, "", "")
+	require.NoError(t, err)
+
+	config, err = GetDownstreamGitOpsConfig(clientset, appID, clusterID)
+	require.NoError(t, err)
+	assert.Equal(t, "test-token", config.Token)
+	assert.Equal(t, pullRequest, config.PullRequest)
+
+	err = updateDownstreamGitOps(clientset, appID, clusterID, repoURI, "release", "/apps", "single", ActionPullRequest, "", "")
+	require.NoError(t, err)
+
+	config, err = GetDownstreamGitOpsConfig(clientset, appID, clusterID)
+	require.NoError(t, err)
+	assert.Nil(t, config.PullRequest)
+}
+
 func mockGitOpsConfigMapNotFoundClient() kubernetes.Interface {
 	mockClient := fake.Clientset{}
 	mockClient.AddReactor("get", "configmaps", func(action core.Action) (bool, runtime.Object, error) {
@@ -335,3 +392,38 @@ func Test_isGitOpsRepoConfiguredForMultipleApps(t *testing.T) {
 		})
 	}
 }
+
+func Test_reencryptGitOpsSecretData(t *testing.T) {
+	oldKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	newKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	require.NoError(t, crypto.SetEncryptionKey(oldKey))
+	secretData := map[string][]byte{
+		"provider.0.type":       []byte("github"),
+		"provider.0.repoUri":    []byte("https://github.com/org/repo"),
+		"provider.0.publicKey":  []byte("public-key"),
+		"provider.0.privateKey": []byte(base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("private-key")))),
+		"provider.0.token":      []byte(base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("token")))),
+	}
+
+	require.NoError(t, crypto.SetEncryptionKey(newKey))
+	count, err := reencryptGitOpsSecretData(secretData)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.NoError(t, crypto.RemoveDecryptionKey(oldKey))
+
+	for key, want := range map[string]string{"provider.0.privateKey": "private-key", "provider.0.token": "token"} {
+		decoded, err := base64.StdEncoding.DecodeString(string(secretData[key]))
+		require.NoError(t, err)
+		decrypted, err := crypto.Decrypt(decoded)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(decrypted))
+	}
+	assert.Equal(t, "public-key", string(secretData["provider.0.publicKey"]))
+
+	secretData["provider.1.token"] = []byte("not encrypted")
+	_, err = reencryptGitOpsSecretData(secretData)
+	assert.Error(t, err)
+}
@@ -0,0 +1,458 @@
+package gitops
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	kotsv1beta2 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta2"
+	"github.com/replicatedhq/kots/pkg/apparchive"
+	"github.com/replicatedhq/kots/pkg/util"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// FormatSingle commits the rendered manifests of the app as a single file
+	FormatSingle = "single"
+	// FormatKustomize commits the kustomize base and overlays and the unpacked v1beta2 helm charts of the app
+	// so that a gitops controller can render the manifests. The charts are deployed by the wrapper.
+	FormatKustomize = "kustomize"
+
+	// WrapperArgoCD commits an Argo CD Application that points at the committed app
+	WrapperArgoCD = "argocd"
+	// WrapperFlux commits a Flux Kustomization that points at the committed app
+	WrapperFlux = "flux"
+
+	// DefaultArgoCDNamespace is the namespace of the Argo CD wrapper if none is configured
+	DefaultArgoCDNamespace = "argocd"
+	// DefaultFluxNamespace is the namespace of the Flux wrapper if none is configured.
+	// The flux-system GitRepository that flux bootstrap creates is expected in the same namespace.
+	DefaultFluxNamespace = "flux-system"
+)
+
+// ValidateOutput returns an error if the format and wrapper cannot be used together.
+func ValidateOutput(format string, wrapper string, wrapperNamespace string) error {
+	switch format {
+	case FormatSingle, FormatKustomize:
+	default:
+		return errors.Errorf("unsupported gitops format %q", format)
+	}
+
+	switch wrapper {
+	case "", WrapperArgoCD:
+	case WrapperFlux:
+		// flux generates a kustomization for every manifest under the path if there is none,
+		// which would include the wrapper itself and the other apps in the repo
+		if format != FormatKustomize {
+			return errors.Errorf("the %s wrapper requires the %s format", WrapperFlux, FormatKustomize)
+		}
+	default:
+		return errors.Errorf("unsupported gitops wrapper %q", wrapper)
+	}
+
+	if wrapperNamespace != "" {
+		if wrapper == "" {
+			return errors.New("a wrapper namespace requires a wrapper")
+		}
+		if errs := validation.IsDNS1123Label(wrapperNamespace); len(errs) > 0 {
+			return errors.Errorf("invalid wrapper namespace %q: %s", wrapperNamespace, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// getWrapperNamespace returns the namespace of the wrapper, which defaults to the namespace of the default installation
+// of Argo CD or Flux.
+func getWrapperNamespace(gitOpsConfig *GitOpsConfig) string {
+	if gitOpsConfig.WrapperNamespace != "" {
+		return gitOpsConfig.WrapperNamespace
+	}
+	if gitOpsConfig.Wrapper == WrapperFlux {
+		return DefaultFluxNamespace
+	}
+	return DefaultArgoCDNamespace
+}
+
+// appRepoPaths returns all paths in the repo that can be written for the app, relative to the root of the repo.
+// These are removed before each commit so that files from a previous version, format or wrapper do not linger.
+func appRepoPaths(gitOpsConfig *GitOpsConfig, appSlug string) []string {
+	return []string{
+		repoPath(gitOpsConfig.Path, fmt.Sprintf("%s.yaml", appSlug)),
+		repoPath(gitOpsConfig.Path, appSlug),
+		repoPath(gitOpsConfig.Path, fmt.Sprintf("%s-%s.yaml", appSlug, WrapperArgoCD)),
+		repoPath(gitOpsConfig.Path, fmt.Sprintf("%s-%s.yaml", appSlug, WrapperFlux)),
+	}
+}
+
+// getAppRepoFiles returns the files to commit for the app, keyed by their path relative to the root of the repo.
+func getAppRepoFiles(gitOpsConfig *GitOpsConfig, appSlug string, archiveDir string, downstreamName string, kustomizeBinPath string, v1Beta2Charts []kotsv1beta2.HelmChart) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	charts := []committedChart{}
+
+	switch gitOpsConfig.Format {
+	case FormatKustomize:
+		sourceFiles, err := apparchive.GetAppSourceFiles(archiveDir, downstreamName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get app source files")
+		}
+		charts, err = unpackV1Beta2Charts(sourceFiles, v1Beta2Charts)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unpack v1beta2 helm charts")
+		}
+		for filename, content := range sourceFiles {
+			files[repoPath(gitOpsConfig.Path, appSlug, filepath.ToSlash(filename))] = content
+		}
+
+		kustomization, err := appKustomization(downstreamName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get app kustomization")
+		}
+		files[repoPath(gitOpsConfig.Path, appSlug, "kustomization.yaml")] = kustomization
+
+	default:
+		out, _, err := apparchive.GetRenderedApp(archiveDir, downstreamName, kustomizeBinPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rendered app")
+		}
+		files[repoPath(gitOpsConfig.Path, fmt.Sprintf("%s.yaml", appSlug))] = out
+	}
+
+	if gitOpsConfig.Wrapper != "" {
+		wrapper, err := appWrapper(gitOpsConfig, appSlug, charts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s wrapper", gitOpsConfig.Wrapper)
+		}
+		files[repoPath(gitOpsConfig.Path, fmt.Sprintf("%s-%s.yaml", appSlug, gitOpsConfig.Wrapper))] = wrapper
+	}
+
+	return files, nil
+}
+
+// appKustomization returns the kustomization at the root of the committed app, which builds the downstream overlay.
+func appKustomization(downstreamName string) ([]byte, error) {
+	kustomization := struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Kind       string   `yaml:"kind"`
+		Resources  []string `yaml:"resources"`
+	}{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{path.Join("overlays", "downstreams", downstreamName)},
+	}
+
+	b, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal kustomization")
+	}
+	return b, nil
+}
+
+// committedChart is a v1beta2 helm chart committed unpacked with its values, since Argo CD and Flux can only
+// render charts from directories in a git repo. Paths are relative to the directory of the committed app.
+type committedChart struct {
+	ReleaseName string
+	Namespace   string
+	ChartPath   string
+	ValuesPath  string
+}
+
+// unpackV1Beta2Charts replaces the archives of the v1beta2 helm charts in the app source files with the unpacked charts.
+// Source files are keyed by their path relative to the version archive.
+func unpackV1Beta2Charts(sourceFiles map[string][]byte, v1Beta2Charts []kotsv1beta2.HelmChart) ([]committedChart, error) {
+	charts := []committedChart{}
+
+	for _, helmChart := range v1Beta2Charts {
+		chartDir := path.Join("helm", helmChart.GetDirName())
+		archivePath := path.Join(chartDir, fmt.Sprintf("%s-%s.tgz", helmChart.Spec.Chart.Name, helmChart.Spec.Chart.ChartVersion))
+		archive, ok := sourceFiles[filepath.FromSlash(archivePath)]
+		if !ok {
+			// excluded charts are not written to the archive
+			continue
+		}
+
+		chartFiles, err := untarChart(archive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to untar %s", archivePath)
+		}
+
+		chartRoot := ""
+		for filename, content := range chartFiles {
+			sourceFiles[filepath.FromSlash(path.Join(chartDir, filename))] = content
+			if chartRoot == "" {
+				chartRoot = strings.Split(filename, "/")[0]
+			}
+		}
+		delete(sourceFiles, filepath.FromSlash(archivePath))
+
+		namespace := helmChart.GetNamespace()
+		if namespace == "" {
+			namespace = util.AppNamespace()
+		}
+
+		charts = append(charts, committedChart{
+			ReleaseName: helmChart.GetReleaseName(),
+			Namespace:   namespace,
+			ChartPath:   path.Join(chartDir, chartRoot),
+			ValuesPath:  path.Join(chartDir, "values.yaml"),
+		})
+	}
+
+	return charts, nil
+}
+
+// untarChart returns the files in a helm chart archive, keyed by their path in the archive
+func untarChart(archive []byte) (map[string][]byte, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gzip reader")
+	}
+	defer gzipReader.Close()
+
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read tar header")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		filename := path.Clean(header.Name)
+		if path.IsAbs(filename) || filename == ".." || strings.HasPrefix(filename, "../") || !strings.Contains(filename, "/") {
+			return nil, errors.Errorf("invalid chart file %s", header.Name)
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", header.Name)
+		}
+		files[filename] = content
+	}
+
+	return files, nil
+}
+
+type wrapperMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type argoCDApplication struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   wrapperMetadata       `yaml:"metadata"`
+	Spec       argoCDApplicationSpec `yaml:"spec"`
+}
+
+type argoCDApplicationSpec struct {
+	Project     string                       `yaml:"project"`
+	Source      argoCDApplicationSource      `yaml:"source"`
+	Destination argoCDApplicationDestination `yaml:"destination"`
+}
+
+type argoCDApplicationSource struct {
+	RepoURL        string                      `yaml:"repoURL"`
+	TargetRevision string                      `yaml:"targetRevision"`
+	Path           string                      `yaml:"path"`
+	Directory      *argoCDApplicationDirectory `yaml:"directory,omitempty"`
+	Helm           *argoCDApplicationHelm      `yaml:"helm,omitempty"`
+}
+
+type argoCDApplicationHelm struct {
+	ReleaseName string   `yaml:"releaseName"`
+	ValueFiles  []string `yaml:"valueFiles"`
+}
+
+type argoCDApplicationDirectory struct {
+	Include string `yaml:"include"`
+}
+
+type argoCDApplicationDestination struct {
+	Server    string `yaml:"server"`
+	Namespace string `yaml:"namespace"`
+}
+
+type fluxKustomization struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   wrapperMetadata       `yaml:"metadata"`
+	Spec       fluxKustomizationSpec `yaml:"spec"`
+}
+
+type fluxKustomizationSpec struct {
+	Interval  string                  `yaml:"interval"`
+	Path      string                  `yaml:"path"`
+	Prune     bool                    `yaml:"prune"`
+	SourceRef fluxKustomizationSource `yaml:"sourceRef"`
+}
+
+type fluxKustomizationSource struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type fluxHelmRelease struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Metadata   wrapperMetadata     `yaml:"metadata"`
+	Spec       fluxHelmReleaseSpec `yaml:"spec"`
+}
+
+type fluxHelmReleaseSpec struct {
+	Interval        string               `yaml:"interval"`
+	ReleaseName     string               `yaml:"releaseName"`
+	TargetNamespace string               `yaml:"targetNamespace"`
+	Chart           fluxHelmReleaseChart `yaml:"chart"`
+}
+
+type fluxHelmReleaseChart struct {
+	Spec fluxHelmReleaseChartSpec `yaml:"spec"`
+}
+
+type fluxHelmReleaseChartSpec struct {
+	Chart       string                  `yaml:"chart"`
+	SourceRef   fluxKustomizationSource `yaml:"sourceRef"`
+	ValuesFiles []string                `yaml:"valuesFiles"`
+}
+
+// appWrapper returns an Argo CD Application or a Flux Kustomization that deploys the committed app,
+// followed by an Argo CD Application or a Flux HelmRelease for each committed v1beta2 helm chart.
+// The wrappers are created in the configured wrapper namespace, which is where Argo CD or Flux is installed.
+func appWrapper(gitOpsConfig *GitOpsConfig, appSlug string, charts []committedChart) ([]byte, error) {
+	wrappers := []interface{}{}
+	appPath := repoPath(gitOpsConfig.Path, appSlug)
+	wrapperNamespace := getWrapperNamespace(gitOpsConfig)
+
+	switch gitOpsConfig.Wrapper {
+	case WrapperArgoCD:
+		source := argoCDApplicationSource{
+			RepoURL:        gitOpsConfig.RepoURI,
+			TargetRevision: gitOpsConfig.Branch,
+			Path:           path.Join(".", appPath),
+		}
+		if gitOpsConfig.Format != FormatKustomize {
+			// the rendered app is a single file next to the wrapper and the other apps in the repo
+			source.Path = path.Join(".", repoPath(gitOpsConfig.Path))
+			source.Directory = &argoCDApplicationDirectory{
+				Include: fmt.Sprintf("%s.yaml", appSlug),
+			}
+		}
+		wrappers = append(wrappers, argoCDApplicationWrapper(appSlug, wrapperNamespace, source, util.AppNamespace()))
+
+		for _, chart := range charts {
+			chartPath := path.Join(".", appPath, chart.ChartPath)
+			valuesPath := path.Join(".", appPath, chart.ValuesPath)
+			// value files are relative to the path of the chart
+			relValuesPath, err := filepath.Rel(chartPath, valuesPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get values path of chart %s", chart.ReleaseName)
+			}
+			chartSource := argoCDApplicationSource{
+				RepoURL:        gitOpsConfig.RepoURI,
+				TargetRevision: gitOpsConfig.Branch,
+				Path:           chartPath,
+				Helm: &argoCDApplicationHelm{
+					ReleaseName: chart.ReleaseName,
+					ValueFiles:  []string{filepath.ToSlash(relValuesPath)},
+				},
+			}
+			wrappers = append(wrappers, argoCDApplicationWrapper(fmt.Sprintf("%s-%s", appSlug, chart.ReleaseName), wrapperNamespace, chartSource, chart.Namespace))
+		}
+
+	case WrapperFlux:
+		sourceRef := fluxKustomizationSource{
+			Kind:      "GitRepository",
+			Name:      "flux-system",
+			Namespace: wrapperNamespace,
+		}
+
+		wrappers = append(wrappers, fluxKustomization{
+			APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+			Kind:       "Kustomization",
+			Metadata: wrapperMetadata{
+				Name:      appSlug,
+				Namespace: wrapperNamespace,
+			},
+			Spec: fluxKustomizationSpec{
+				Interval:  "10m",
+				Path:      "./" + appPath,
+				Prune:     true,
+				SourceRef: sourceRef,
+			},
+		})
+
+		for _, chart := range charts {
+			wrappers = append(wrappers, fluxHelmRelease{
+				APIVersion: "helm.toolkit.fluxcd.io/v2beta1",
+				Kind:       "HelmRelease",
+				Metadata: wrapperMetadata{
+					Name:      fmt.Sprintf("%s-%s", appSlug, chart.ReleaseName),
+					Namespace: wrapperNamespace,
+				},
+				Spec: fluxHelmReleaseSpec{
+					Interval:        "10m",
+					ReleaseName:     chart.ReleaseName,
+					TargetNamespace: chart.Namespace,
+					Chart: fluxHelmReleaseChart{
+						Spec: fluxHelmReleaseChartSpec{
+							Chart:       "./" + path.Join(appPath, chart.ChartPath),
+							SourceRef:   sourceRef,
+							ValuesFiles: []string{"./" + path.Join(appPath, chart.ValuesPath)},
+						},
+					},
+				},
+			})
+		}
+
+	default:
+		return nil, errors.Errorf("unsupported gitops wrapper %q", gitOpsConfig.Wrapper)
+	}
+
+	docs := [][]byte{}
+	for _, wrapper := range wrappers {
+		b, err := yaml.Marshal(wrapper)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal wrapper")
+		}
+		docs = append(docs, b)
+	}
+	return bytes.Join(docs, []byte("---\n")), nil
+}
+
+func argoCDApplicationWrapper(name string, wrapperNamespace string, source argoCDApplicationSource, namespace string) argoCDApplication {
+	return argoCDApplication{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Application",
+		Metadata: wrapperMetadata{
+			Name:      name,
+			Namespace: wrapperNamespace,
+		},
+		Spec: argoCDApplicationSpec{
+			Project: "default",
+			Source:  source,
+			Destination: argoCDApplicationDestination{
+				Server:    "https://kubernetes.default.svc",
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+// repoPath joins the elements into a path relative to the root of the repo.
+func repoPath(elem ...string) string {
+	return strings.TrimPrefix(path.Join(elem...), "/")
+}
@@ -0,0 +1,458 @@
+package gitops
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kotsv1beta2 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta2"
+	"github.com/replicatedhq/kots/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateOutput(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           string
+		wrapper          string
+		wrapperNamespace string
+		wantErr          bool
+	}{
+		{
+			name:   "single",
+			format: FormatSingle,
+		},
+		{
+			name:   "kustomize",
+			format: FormatKustomize,
+		},
+		{
+			name:    "single with argocd",
+			format:  FormatSingle,
+			wrapper: WrapperArgoCD,
+		},
+		{
+			name:    "kustomize with flux",
+			format:  FormatKustomize,
+			wrapper: WrapperFlux,
+		},
+		{
+			name:             "kustomize with flux in another namespace",
+			format:           FormatKustomize,
+			wrapper:          WrapperFlux,
+			wrapperNamespace: "flux",
+		},
+		{
+			name:             "invalid wrapper namespace",
+			format:           FormatKustomize,
+			wrapper:          WrapperArgoCD,
+			wrapperNamespace: "Argo_CD",
+			wantErr:          true,
+		},
+		{
+			name:             "wrapper namespace without wrapper",
+			format:           FormatKustomize,
+			wrapperNamespace: "argocd",
+			wantErr:          true,
+		},
+		{
+			name:    "single with flux",
+			format:  FormatSingle,
+			wrapper: WrapperFlux,
+			wantErr: true,
+		},
+		{
+			name:    "empty format",
+			wantErr: true,
+		},
+		{
+			name:    "unknown wrapper",
+			format:  FormatKustomize,
+			wrapper: "fleet",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateOutput(test.format, test.wrapper, test.wrapperNamespace)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_getAppRepoFiles(t *testing.T) {
+	util.PodNamespace = "default"
+
+	archiveFiles := map[string]string{
+		"base/kustomization.yaml":                              "base",
+		"overlays/midstream/kustomization.yaml":                "midstream",
+		"overlays/downstreams/this-cluster/kustomization.yaml": "downstream",
+		"helm/web/values.yaml":                                 "values",
+		"helm/web/nginx-1.0.0.tgz": string(chartArchive(t, map[string]string{
+			"nginx/Chart.yaml":                "name: nginx",
+			"nginx/templates/deployment.yaml": "deployment",
+		})),
+		"rendered/this-cluster/deployment.yaml": "rendered",
+	}
+
+	v1Beta2Charts := []kotsv1beta2.HelmChart{
+		{
+			Spec: kotsv1beta2.HelmChartSpec{
+				Chart:       kotsv1beta2.ChartIdentifier{Name: "nginx", ChartVersion: "1.0.0"},
+				ReleaseName: "web",
+				Namespace:   "web-namespace",
+			},
+		},
+		{
+			// excluded charts are not in the archive
+			Spec: kotsv1beta2.HelmChartSpec{
+				Chart: kotsv1beta2.ChartIdentifier{Name: "redis", ChartVersion: "2.0.0"},
+			},
+		},
+	}
+
+	archiveDir := t.TempDir()
+	for filename, content := range archiveFiles {
+		fullPath := filepath.Join(archiveDir, filename)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name         string
+		gitOpsConfig *GitOpsConfig
+		want         map[string]string
+	}{
+		{
+			name: "single",
+			gitOpsConfig: &GitOpsConfig{
+				Path:   "/apps",
+				Format: FormatSingle,
+			},
+			want: map[string]string{
+				"apps/my-app.yaml": "rendered",
+			},
+		},
+		{
+			name: "single with argocd at the root of the repo",
+			gitOpsConfig: &GitOpsConfig{
+				RepoURI: "https://github.com/org/repo",
+				Branch:  "main",
+				Path:    "",
+				Format:  FormatSingle,
+				Wrapper: WrapperArgoCD,
+			},
+			want: map[string]string{
+				"my-app.yaml": "rendered",
+				"my-app-argocd.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://github.com/org/repo
+    targetRevision: main
+    path: .
+    directory:
+      include: my-app.yaml
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: default
+`,
+			},
+		},
+		{
+			name: "single with argocd in another namespace",
+			gitOpsConfig: &GitOpsConfig{
+				RepoURI:          "https://github.com/org/repo",
+				Branch:           "main",
+				Path:             "apps",
+				Format:           FormatSingle,
+				Wrapper:          WrapperArgoCD,
+				WrapperNamespace: "gitops",
+			},
+			want: map[string]string{
+				"apps/my-app.yaml": "rendered",
+				"apps/my-app-argocd.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: gitops
+spec:
+  project: default
+  source:
+    repoURL: https://github.com/org/repo
+    targetRevision: main
+    path: apps
+    directory:
+      include: my-app.yaml
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: default
+`,
+			},
+		},
+		{
+			name: "kustomize with argocd",
+			gitOpsConfig: &GitOpsConfig{
+				RepoURI: "https://github.com/org/repo",
+				Branch:  "main",
+				Path:    "/apps",
+				Format:  FormatKustomize,
+				Wrapper: WrapperArgoCD,
+			},
+			want: map[string]string{
+				"apps/my-app/base/kustomization.yaml":                              "base",
+				"apps/my-app/overlays/midstream/kustomization.yaml":                "midstream",
+				"apps/my-app/overlays/downstreams/this-cluster/kustomization.yaml": "downstream",
+				"apps/my-app/helm/web/values.yaml":                                 "values",
+				"apps/my-app/helm/web/nginx/Chart.yaml":                            "name: nginx",
+				"apps/my-app/helm/web/nginx/templates/deployment.yaml":             "deployment",
+				"apps/my-app/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- overlays/downstreams/this-cluster
+`,
+				"apps/my-app-argocd.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://github.com/org/repo
+    targetRevision: main
+    path: apps/my-app
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: default
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: my-app-web
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://github.com/org/repo
+    targetRevision: main
+    path: apps/my-app/helm/web/nginx
+    helm:
+      releaseName: web
+      valueFiles:
+      - ../values.yaml
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: web-namespace
+`,
+			},
+		},
+		{
+			name: "kustomize with flux",
+			gitOpsConfig: &GitOpsConfig{
+				RepoURI: "https://github.com/org/repo",
+				Branch:  "main",
+				Path:    "apps",
+				Format:  FormatKustomize,
+				Wrapper: WrapperFlux,
+			},
+			want: map[string]string{
+				"apps/my-app/base/kustomization.yaml":                              "base",
+				"apps/my-app/overlays/midstream/kustomization.yaml":                "midstream",
+				"apps/my-app/overlays/downstreams/this-cluster/kustomization.yaml": "downstream",
+				"apps/my-app/helm/web/values.yaml":                                 "values",
+				"apps/my-app/helm/web/nginx/Chart.yaml":                            "name: nginx",
+				"apps/my-app/helm/web/nginx/templates/deployment.yaml":             "deployment",
+				"apps/my-app/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- overlays/downstreams/this-cluster
+`,
+				"apps/my-app-flux.yaml": `apiVersion: kustomize.toolkit.fluxcd.io/v1
+kind: Kustomization
+metadata:
+  name: my-app
+  namespace: flux-system
+spec:
+  interval: 10m
+  path: ./apps/my-app
+  prune: true
+  sourceRef:
+    kind: GitRepository
+    name: flux-system
+    namespace: flux-system
+---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: my-app-web
+  namespace: flux-system
+spec:
+  interval: 10m
+  releaseName: web
+  targetNamespace: web-namespace
+  chart:
+    spec:
+      chart: ./apps/my-app/helm/web/nginx
+      sourceRef:
+        kind: GitRepository
+        name: flux-system
+        namespace: flux-system
+      valuesFiles:
+      - ./apps/my-app/helm/web/values.yaml
+`,
+			},
+		},
+		{
+			name: "kustomize with flux in another namespace",
+			gitOpsConfig: &GitOpsConfig{
+				RepoURI:          "https://github.com/org/repo",
+				Branch:           "main",
+				Path:             "apps",
+				Format:           FormatKustomize,
+				Wrapper:          WrapperFlux,
+				WrapperNamespace: "flux",
+			},
+			want: map[string]string{
+				"apps/my-app/base/kustomization.yaml":                              "base",
+				"apps/my-app/overlays/midstream/kustomization.yaml":                "midstream",
+				"apps/my-app/overlays/downstreams/this-cluster/kustomization.yaml": "downstream",
+				"apps/my-app/helm/web/values.yaml":                                 "values",
+				"apps/my-app/helm/web/nginx/Chart.yaml":                            "name: nginx",
+				"apps/my-app/helm/web/nginx/templates/deployment.yaml":             "deployment",
+				"apps/my-app/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- overlays/downstreams/this-cluster
+`,
+				"apps/my-app-flux.yaml": `apiVersion: kustomize.toolkit.fluxcd.io/v1
+kind: Kustomization
+metadata:
+  name: my-app
+  namespace: flux
+spec:
+  interval: 10m
+  path: ./apps/my-app
+  prune: true
+  sourceRef:
+    kind: GitRepository
+    name: flux-system
+    namespace: flux
+---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: my-app-web
+  namespace: flux
+spec:
+  interval: 10m
+  releaseName: web
+  targetNamespace: web-namespace
+  chart:
+    spec:
+      chart: ./apps/my-app/helm/web/nginx
+      sourceRef:
+        kind: GitRepository
+        name: flux-system
+        namespace: flux
+      valuesFiles:
+      - ./apps/my-app/helm/web/values.yaml
+`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, err := getAppRepoFiles(test.gitOpsConfig, "my-app", archiveDir, "this-cluster", "kustomize", v1Beta2Charts)
+			require.NoError(t, err)
+
+			got := map[string]string{}
+			for filename, content := range files {
+				got[filename] = string(content)
+			}
+			assert.Equal(t, test.want, got)
+
+			// every file that is written must be removed before the next commit
+			for filename := range files {
+				owned := false
+				for _, appPath := range appRepoPaths(test.gitOpsConfig, "my-app") {
+					if filename == appPath || strings.HasPrefix(filename, appPath+"/") {
+						owned = true
+					}
+				}
+				assert.True(t, owned, "%s is not removed before the next commit", filename)
+			}
+		})
+	}
+}
+
+func Test_untarChart(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string][]byte
+		wantErr bool
+	}{
+		{
+			name:  "chart",
+			files: map[string]string{"nginx/Chart.yaml": "name: nginx", "nginx/./values.yaml": "values"},
+			want:  map[string][]byte{"nginx/Chart.yaml": []byte("name: nginx"), "nginx/values.yaml": []byte("values")},
+		},
+		{
+			name:    "file outside of the chart",
+			files:   map[string]string{"nginx/../../kustomization.yaml": "resources: []"},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			files:   map[string]string{"/etc/nginx/Chart.yaml": "name: nginx"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, err := untarChart(chartArchive(t, test.files))
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, files)
+		})
+	}
+}
+
+func chartArchive(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	gzipWriter := gzip.NewWriter(&b)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for filename, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     filename,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return b.Bytes()
+}
@@ -0,0 +1,155 @@
+package gitops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+)
+
+// PullRequestProvider opens and tracks pull requests using the API of a git provider.
+type PullRequestProvider interface {
+	CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error)
+	GetPullRequest(id int64) (*gitopstypes.PullRequest, error)
+}
+
+type CreatePullRequestOptions struct {
+	Title string
+	Body  string
+	// Head is the branch with the changes
+	Head string
+	// Base is the branch the changes are merged into
+	Base string
+}
+
+// GetPullRequestProvider returns a client for the pull request API of the gitops provider.
+func GetPullRequestProvider(g *GitOpsConfig) (PullRequestProvider, error) {
+	if g.Token == "" {
+		return nil, errors.New("an access token is required to open pull requests")
+	}
+
+	apiURL, err := g.apiURL()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get api url")
+	}
+
+	return newPullRequestProvider(g, apiURL)
+}
+
+func newPullRequestProvider(g *GitOpsConfig, apiURL string) (PullRequestProvider, error) {
+	owner, repo, err := g.ownerAndRepo()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get owner and repo")
+	}
+
+	client := &apiClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiURL:     apiURL,
+	}
+
+	switch g.Provider {
+	case "github", "github_enterprise":
+		client.authHeader, client.authValue = "Authorization", fmt.Sprintf("token %s", g.Token)
+		return &gitHubProvider{client: client, owner: owner, repo: repo}, nil
+	case "gitlab", "gitlab_enterprise":
+		client.authHeader, client.authValue = "PRIVATE-TOKEN", g.Token
+		return &gitLabProvider{client: client, owner: owner, repo: repo}, nil
+	case "bitbucket":
+		client.authHeader, client.authValue = "Authorization", fmt.Sprintf("Bearer %s", g.Token)
+		return &bitbucketProvider{client: client, owner: owner, repo: repo}, nil
+	case "bitbucket_server":
+		client.authHeader, client.authValue = "Authorization", fmt.Sprintf("Bearer %s", g.Token)
+		return &bitbucketServerProvider{client: client, project: owner, repo: repo}, nil
+	case "gitea":
+		client.authHeader, client.authValue = "Authorization", fmt.Sprintf("token %s", g.Token)
+		return &giteaProvider{client: client, owner: owner, repo: repo}, nil
+	}
+
+	return nil, errors.Errorf("pull requests are not supported for provider type: %s", g.Provider)
+}
+
+func (g *GitOpsConfig) apiURL() (string, error) {
+	switch g.Provider {
+	case "github":
+		return "https://api.github.com", nil
+	case "gitlab":
+		return "https://gitlab.com/api/v4", nil
+	case "bitbucket":
+		return "https://api.bitbucket.org/2.0", nil
+	case "github_enterprise":
+		return fmt.Sprintf("%s/api/v3", g.httpBaseURL()), nil
+	case "gitlab_enterprise":
+		return fmt.Sprintf("%s/api/v4", g.httpBaseURL()), nil
+	case "bitbucket_server":
+		return fmt.Sprintf("%s/rest/api/1.0", g.httpBaseURL()), nil
+	case "gitea":
+		return fmt.Sprintf("%s/api/v1", g.httpBaseURL()), nil
+	}
+
+	return "", errors.Errorf("unsupported provider type: %s", g.Provider)
+}
+
+func (g *GitOpsConfig) httpBaseURL() string {
+	if g.HTTPPort != "" {
+		return fmt.Sprintf("https://%s:%s", g.Hostname, g.HTTPPort)
+	}
+	return fmt.Sprintf("https://%s", g.Hostname)
+}
+
+type apiClient struct {
+	httpClient *http.Client
+	apiURL     string
+	authHeader string
+	authValue  string
+}
+
+// do sends a request to the provider api and decodes the json response into out.
+func (c *apiClient) do(method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.apiURL+path, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set(c.authHeader, c.authValue)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return errors.Wrap(err, "failed to unmarshal response")
+		}
+	}
+
+	return nil
+}
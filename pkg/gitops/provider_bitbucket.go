@@ -0,0 +1,137 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+)
+
+// bitbucketProvider opens pull requests on bitbucket.org
+type bitbucketProvider struct {
+	client *apiClient
+	owner  string
+	repo   string
+}
+
+type bitbucketBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type bitbucketPullRequest struct {
+	ID     int64           `json:"id"`
+	State  string          `json:"state"`
+	Source bitbucketBranch `json:"source"`
+	Links  struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func (p *bitbucketProvider) CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error) {
+	in := map[string]interface{}{
+		"title":       opts.Title,
+		"description": opts.Body,
+		"source": map[string]interface{}{
+			"branch": map[string]string{"name": opts.Head},
+		},
+		"destination": map[string]interface{}{
+			"branch": map[string]string{"name": opts.Base},
+		},
+	}
+	out := bitbucketPullRequest{}
+	if err := p.client.do("POST", fmt.Sprintf("/repositories/%s/%s/pullrequests", p.owner, p.repo), in, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to create pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *bitbucketProvider) GetPullRequest(id int64) (*gitopstypes.PullRequest, error) {
+	out := bitbucketPullRequest{}
+	if err := p.client.do("GET", fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", p.owner, p.repo, id), nil, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (pr bitbucketPullRequest) toPullRequest() *gitopstypes.PullRequest {
+	return &gitopstypes.PullRequest{
+		ID:     pr.ID,
+		URL:    pr.Links.HTML.Href,
+		Branch: pr.Source.Branch.Name,
+		State:  bitbucketPullRequestState(pr.State),
+	}
+}
+
+// bitbucketServerProvider opens pull requests on bitbucket server and data center
+type bitbucketServerProvider struct {
+	client  *apiClient
+	project string
+	repo    string
+}
+
+type bitbucketServerRef struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId,omitempty"`
+}
+
+type bitbucketServerPullRequest struct {
+	ID      int64              `json:"id"`
+	State   string             `json:"state"`
+	FromRef bitbucketServerRef `json:"fromRef"`
+	Links   struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+func (p *bitbucketServerProvider) CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error) {
+	in := map[string]interface{}{
+		"title":       opts.Title,
+		"description": opts.Body,
+		"fromRef":     bitbucketServerRef{ID: fmt.Sprintf("refs/heads/%s", opts.Head)},
+		"toRef":       bitbucketServerRef{ID: fmt.Sprintf("refs/heads/%s", opts.Base)},
+	}
+	out := bitbucketServerPullRequest{}
+	if err := p.client.do("POST", fmt.Sprintf("/projects/%s/repos/%s/pull-requests", p.project, p.repo), in, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to create pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *bitbucketServerProvider) GetPullRequest(id int64) (*gitopstypes.PullRequest, error) {
+	out := bitbucketServerPullRequest{}
+	if err := p.client.do("GET", fmt.Sprintf("/projects/%s/repos/%s/pull-requests/%d", p.project, p.repo, id), nil, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (pr bitbucketServerPullRequest) toPullRequest() *gitopstypes.PullRequest {
+	url := ""
+	if len(pr.Links.Self) > 0 {
+		url = pr.Links.Self[0].Href
+	}
+	return &gitopstypes.PullRequest{
+		ID:     pr.ID,
+		URL:    url,
+		Branch: pr.FromRef.DisplayID,
+		State:  bitbucketPullRequestState(pr.State),
+	}
+}
+
+// bitbucketPullRequestState maps the pull request states used by bitbucket cloud and server
+func bitbucketPullRequestState(state string) gitopstypes.PullRequestState {
+	switch state {
+	case "MERGED":
+		return gitopstypes.PullRequestMerged
+	case "DECLINED", "SUPERSEDED":
+		return gitopstypes.PullRequestClosed
+	default:
+		return gitopstypes.PullRequestOpen
+	}
+}
@@ -0,0 +1,37 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+)
+
+// giteaProvider opens pull requests on gitea. the gitea api mirrors the github pulls api.
+type giteaProvider struct {
+	client *apiClient
+	owner  string
+	repo   string
+}
+
+func (p *giteaProvider) CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error) {
+	in := map[string]string{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+	}
+	out := gitHubPullRequest{}
+	if err := p.client.do("POST", fmt.Sprintf("/repos/%s/%s/pulls", p.owner, p.repo), in, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to create pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *giteaProvider) GetPullRequest(id int64) (*gitopstypes.PullRequest, error) {
+	out := gitHubPullRequest{}
+	if err := p.client.do("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d", p.owner, p.repo, id), nil, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request")
+	}
+	return out.toPullRequest(), nil
+}
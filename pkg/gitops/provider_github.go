@@ -0,0 +1,62 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+)
+
+// gitHubProvider opens pull requests on github.com and github enterprise
+type gitHubProvider struct {
+	client *apiClient
+	owner  string
+	repo   string
+}
+
+type gitHubPullRequest struct {
+	Number  int64  `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+func (p *gitHubProvider) CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error) {
+	in := map[string]string{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+	}
+	out := gitHubPullRequest{}
+	if err := p.client.do("POST", fmt.Sprintf("/repos/%s/%s/pulls", p.owner, p.repo), in, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to create pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *gitHubProvider) GetPullRequest(id int64) (*gitopstypes.PullRequest, error) {
+	out := gitHubPullRequest{}
+	if err := p.client.do("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d", p.owner, p.repo, id), nil, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (pr gitHubPullRequest) toPullRequest() *gitopstypes.PullRequest {
+	state := gitopstypes.PullRequestOpen
+	if pr.Merged {
+		state = gitopstypes.PullRequestMerged
+	} else if pr.State == "closed" {
+		state = gitopstypes.PullRequestClosed
+	}
+	return &gitopstypes.PullRequest{
+		ID:     pr.Number,
+		URL:    pr.HTMLURL,
+		Branch: pr.Head.Ref,
+		State:  state,
+	}
+}
@@ -0,0 +1,66 @@
+package gitops
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+)
+
+// gitLabProvider opens merge requests on gitlab.com and self-managed gitlab
+type gitLabProvider struct {
+	client *apiClient
+	owner  string
+	repo   string
+}
+
+type gitLabMergeRequest struct {
+	IID          int64  `json:"iid"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"`
+	SourceBranch string `json:"source_branch"`
+}
+
+func (p *gitLabProvider) CreatePullRequest(opts CreatePullRequestOptions) (*gitopstypes.PullRequest, error) {
+	in := map[string]string{
+		"title":         opts.Title,
+		"description":   opts.Body,
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+	}
+	out := gitLabMergeRequest{}
+	if err := p.client.do("POST", fmt.Sprintf("%s/merge_requests", p.projectPath()), in, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to create merge request")
+	}
+	return out.toPullRequest(), nil
+}
+
+func (p *gitLabProvider) GetPullRequest(id int64) (*gitopstypes.PullRequest, error) {
+	out := gitLabMergeRequest{}
+	if err := p.client.do("GET", fmt.Sprintf("%s/merge_requests/%d", p.projectPath(), id), nil, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to get merge request")
+	}
+	return out.toPullRequest(), nil
+}
+
+// projectPath returns the api path of the project, which is identified by its url encoded full path
+func (p *gitLabProvider) projectPath() string {
+	return fmt.Sprintf("/projects/%s", url.QueryEscape(fmt.Sprintf("%s/%s", p.owner, p.repo)))
+}
+
+func (mr gitLabMergeRequest) toPullRequest() *gitopstypes.PullRequest {
+	state := gitopstypes.PullRequestOpen
+	switch mr.State {
+	case "merged":
+		state = gitopstypes.PullRequestMerged
+	case "closed":
+		state = gitopstypes.PullRequestClosed
+	}
+	return &gitopstypes.PullRequest{
+		ID:     mr.IID,
+		URL:    mr.WebURL,
+		Branch: mr.SourceBranch,
+		State:  state,
+	}
+}
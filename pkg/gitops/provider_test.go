@@ -0,0 +1,267 @@
+package gitops
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProviderRoute is a canned response for a request to the fake provider api
+type fakeProviderRoute struct {
+	method   string
+	path     string
+	wantBody map[string]interface{}
+	response string
+}
+
+func newFakeProviderServer(t *testing.T, authHeader string, authValue string, routes []fakeProviderRoute) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, authValue, r.Header.Get(authHeader))
+
+		for _, route := range routes {
+			if r.Method != route.method || r.URL.EscapedPath() != route.path {
+				continue
+			}
+
+			if route.wantBody != nil {
+				b, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				body := map[string]interface{}{}
+				require.NoError(t, json.Unmarshal(b, &body))
+				assert.Equal(t, route.wantBody, body)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(route.response))
+			return
+		}
+
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func Test_pullRequestProviders(t *testing.T) {
+	createOpts := CreatePullRequestOptions{
+		Title: "Update My App to version 5",
+		Body:  "body",
+		Head:  "kots/my-app-5",
+		Base:  "main",
+	}
+
+	tests := []struct {
+		name         string
+		gitOpsConfig GitOpsConfig
+		authHeader   string
+		authValue    string
+		routes       []fakeProviderRoute
+		wantCreated  *gitopstypes.PullRequest
+		wantUpdated  *gitopstypes.PullRequest
+	}{
+		{
+			name:         "github",
+			gitOpsConfig: GitOpsConfig{Provider: "github", RepoURI: "https://github.com/my-org/my-repo", Token: "gh-token"},
+			authHeader:   "Authorization",
+			authValue:    "token gh-token",
+			routes: []fakeProviderRoute{
+				{
+					method:   "POST",
+					path:     "/repos/my-org/my-repo/pulls",
+					wantBody: map[string]interface{}{"title": createOpts.Title, "body": "body", "head": "kots/my-app-5", "base": "main"},
+					response: `{"number": 12, "html_url": "https://github.com/my-org/my-repo/pull/12", "state": "open", "head": {"ref": "kots/my-app-5"}}`,
+				},
+				{
+					method:   "GET",
+					path:     "/repos/my-org/my-repo/pulls/12",
+					response: `{"number": 12, "html_url": "https://github.com/my-org/my-repo/pull/12", "state": "closed", "merged": true, "head": {"ref": "kots/my-app-5"}}`,
+				},
+			},
+			wantCreated: &gitopstypes.PullRequest{ID: 12, URL: "https://github.com/my-org/my-repo/pull/12", Branch: "kots/my-app-5", State: gitopstypes.PullRequestOpen},
+			wantUpdated: &gitopstypes.PullRequest{ID: 12, URL: "https://github.com/my-org/my-repo/pull/12", Branch: "kots/my-app-5", State: gitopstypes.PullRequestMerged},
+		},
+		{
+			name:         "gitlab",
+			gitOpsConfig: GitOpsConfig{Provider: "gitlab_enterprise", RepoURI: "https://gitlab.example.com/my-group/my-repo", Hostname: "gitlab.example.com", Token: "gl-token"},
+			authHeader:   "PRIVATE-TOKEN",
+			authValue:    "gl-token",
+			routes: []fakeProviderRoute{
+				{
+					method:   "POST",
+					path:     "/projects/my-group%2Fmy-repo/merge_requests",
+					wantBody: map[string]interface{}{"title": createOpts.Title, "description": "body", "source_branch": "kots/my-app-5", "target_branch": "main"},
+					response: `{"iid": 3, "web_url": "https://gitlab.example.com/my-group/my-repo/-/merge_requests/3", "state": "opened", "source_branch": "kots/my-app-5"}`,
+				},
+				{
+					method:   "GET",
+					path:     "/projects/my-group%2Fmy-repo/merge_requests/3",
+					response: `{"iid": 3, "web_url": "https://gitlab.example.com/my-group/my-repo/-/merge_requests/3", "state": "closed", "source_branch": "kots/my-app-5"}`,
+				},
+			},
+			wantCreated: &gitopstypes.PullRequest{ID: 3, URL: "https://gitlab.example.com/my-group/my-repo/-/merge_requests/3", Branch: "kots/my-app-5", State: gitopstypes.PullRequestOpen},
+			wantUpdated: &gitopstypes.PullRequest{ID: 3, URL: "https://gitlab.example.com/my-group/my-repo/-/merge_requests/3", Branch: "kots/my-app-5", State: gitopstypes.PullRequestClosed},
+		},
+		{
+			name:         "bitbucket",
+			gitOpsConfig: GitOpsConfig{Provider: "bitbucket", RepoURI: "https://bitbucket.org/my-workspace/my-repo", Token: "bb-token"},
+			authHeader:   "Authorization",
+			authValue:    "Bearer bb-token",
+			routes: []fakeProviderRoute{
+				{
+					method: "POST",
+					path:   "/repositories/my-workspace/my-repo/pullrequests",
+					wantBody: map[string]interface{}{
+						"title":       createOpts.Title,
+						"description": "body",
+						"source":      map[string]interface{}{"branch": map[string]interface{}{"name": "kots/my-app-5"}},
+						"destination": map[string]interface{}{"branch": map[string]interface{}{"name": "main"}},
+					},
+					response: `{"id": 7, "state": "OPEN", "source": {"branch": {"name": "kots/my-app-5"}}, "links": {"html": {"href": "https://bitbucket.org/my-workspace/my-repo/pull-requests/7"}}}`,
+				},
+				{
+					method:   "GET",
+					path:     "/repositories/my-workspace/my-repo/pullrequests/7",
+					response: `{"id": 7, "state": "DECLINED", "source": {"branch": {"name": "kots/my-app-5"}}, "links": {"html": {"href": "https://bitbucket.org/my-workspace/my-repo/pull-requests/7"}}}`,
+				},
+			},
+			wantCreated: &gitopstypes.PullRequest{ID: 7, URL: "https://bitbucket.org/my-workspace/my-repo/pull-requests/7", Branch: "kots/my-app-5", State: gitopstypes.PullRequestOpen},
+			wantUpdated: &gitopstypes.PullRequest{ID: 7, URL: "https://bitbucket.org/my-workspace/my-repo/pull-requests/7", Branch: "kots/my-app-5", State: gitopstypes.PullRequestClosed},
+		},
+		{
+			name:         "bitbucket server",
+			gitOpsConfig: GitOpsConfig{Provider: "bitbucket_server", RepoURI: "https://bitbucket.example.com/projects/PROJ/repos/my-repo", Hostname: "bitbucket.example.com", Token: "bbs-token"},
+			authHeader:   "Authorization",
+			authValue:    "Bearer bbs-token",
+			routes: []fakeProviderRoute{
+				{
+					method: "POST",
+					path:   "/projects/PROJ/repos/my-repo/pull-requests",
+					wantBody: map[string]interface{}{
+						"title":       createOpts.Title,
+						"description": "body",
+						"fromRef":     map[string]interface{}{"id": "refs/heads/kots/my-app-5"},
+						"toRef":       map[string]interface{}{"id": "refs/heads/main"},
+					},
+					response: `{"id": 9, "state": "OPEN", "fromRef": {"id": "refs/heads/kots/my-app-5", "displayId": "kots/my-app-5"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/9"}]}}`,
+				},
+				{
+					method:   "GET",
+					path:     "/projects/PROJ/repos/my-repo/pull-requests/9",
+					response: `{"id": 9, "state": "MERGED", "fromRef": {"id": "refs/heads/kots/my-app-5", "displayId": "kots/my-app-5"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/9"}]}}`,
+				},
+			},
+			wantCreated: &gitopstypes.PullRequest{ID: 9, URL: "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/9", Branch: "kots/my-app-5", State: gitopstypes.PullRequestOpen},
+			wantUpdated: &gitopstypes.PullRequest{ID: 9, URL: "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/9", Branch: "kots/my-app-5", State: gitopstypes.PullRequestMerged},
+		},
+		{
+			name:         "gitea",
+			gitOpsConfig: GitOpsConfig{Provider: "gitea", RepoURI: "https://gitea.example.com/my-org/my-repo", Hostname: "gitea.example.com", Token: "gitea-token"},
+			authHeader:   "Authorization",
+			authValue:    "token gitea-token",
+			routes: []fakeProviderRoute{
+				{
+					method:   "POST",
+					path:     "/repos/my-org/my-repo/pulls",
+					wantBody: map[string]interface{}{"title": createOpts.Title, "body": "body", "head": "kots/my-app-5", "base": "main"},
+					response: `{"number": 4, "html_url": "https://gitea.example.com/my-org/my-repo/pulls/4", "state": "open", "head": {"ref": "kots/my-app-5"}}`,
+				},
+				{
+					method:   "GET",
+					path:     "/repos/my-org/my-repo/pulls/4",
+					response: `{"number": 4, "html_url": "https://gitea.example.com/my-org/my-repo/pulls/4", "state": "closed", "merged": false, "head": {"ref": "kots/my-app-5"}}`,
+				},
+			},
+			wantCreated: &gitopstypes.PullRequest{ID: 4, URL: "https://gitea.example.com/my-org/my-repo/pulls/4", Branch: "kots/my-app-5", State: gitopstypes.PullRequestOpen},
+			wantUpdated: &gitopstypes.PullRequest{ID: 4, URL: "https://gitea.example.com/my-org/my-repo/pulls/4", Branch: "kots/my-app-5", State: gitopstypes.PullRequestClosed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeProviderServer(t, tt.authHeader, tt.authValue, tt.routes)
+			defer server.Close()
+
+			provider, err := newPullRequestProvider(&tt.gitOpsConfig, server.URL)
+			require.NoError(t, err)
+
+			created, err := provider.CreatePullRequest(createOpts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+
+			updated, err := provider.GetPullRequest(created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUpdated, updated)
+		})
+	}
+}
+
+func Test_pullRequestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "A pull request already exists"}`))
+	}))
+	defer server.Close()
+
+	gitOpsConfig := &GitOpsConfig{Provider: "github", RepoURI: "https://github.com/my-org/my-repo", Token: "gh-token"}
+	provider, err := newPullRequestProvider(gitOpsConfig, server.URL)
+	require.NoError(t, err)
+
+	_, err = provider.CreatePullRequest(CreatePullRequestOptions{Title: "title", Head: "head", Base: "main"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "A pull request already exists")
+}
+
+func TestGitOpsConfig_apiURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		gitOpsConfig GitOpsConfig
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "github",
+			gitOpsConfig: GitOpsConfig{Provider: "github"},
+			want:         "https://api.github.com",
+		},
+		{
+			name:         "github enterprise",
+			gitOpsConfig: GitOpsConfig{Provider: "github_enterprise", Hostname: "github.example.com"},
+			want:         "https://github.example.com/api/v3",
+		},
+		{
+			name:         "bitbucket server with http port",
+			gitOpsConfig: GitOpsConfig{Provider: "bitbucket_server", Hostname: "bitbucket.example.com", HTTPPort: "7990"},
+			want:         "https://bitbucket.example.com:7990/rest/api/1.0",
+		},
+		{
+			name:         "gitea",
+			gitOpsConfig: GitOpsConfig{Provider: "gitea", Hostname: "gitea.example.com"},
+			want:         "https://gitea.example.com/api/v1",
+		},
+		{
+			name:         "unknown provider",
+			gitOpsConfig: GitOpsConfig{Provider: "other"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.gitOpsConfig.apiURL()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetPullRequestProvider_requiresToken(t *testing.T) {
+	_, err := GetPullRequestProvider(&GitOpsConfig{Provider: "github", RepoURI: "https://github.com/my-org/my-repo"})
+	assert.Error(t, err)
+}
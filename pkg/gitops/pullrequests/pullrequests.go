@@ -0,0 +1,59 @@
+package pullrequests
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/gitops"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+// refreshInterval is the interval between refreshes of the state of the open gitops pull requests
+const refreshInterval = 60 * time.Second
+
+// Start refreshes the state of the open gitops pull requests in the background, so that the app
+// endpoints can serve the stored state without calling the git provider.
+func Start() error {
+	logger.Debug("starting gitops pull request refresher")
+
+	go func() {
+		for {
+			refreshPullRequests()
+			time.Sleep(refreshInterval)
+		}
+	}()
+
+	return nil
+}
+
+func refreshPullRequests() {
+	appsList, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list installed apps to refresh gitops pull requests"))
+		return
+	}
+
+	for _, a := range appsList {
+		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to list downstreams for app %s", a.ID))
+			continue
+		}
+
+		for _, d := range downstreams {
+			downstreamGitOps, err := gitops.GetDownstreamGitOps(a.ID, d.ClusterID)
+			if err != nil {
+				logger.Error(errors.Wrapf(err, "failed to get downstream gitops for app %s", a.ID))
+				continue
+			}
+			if downstreamGitOps == nil {
+				continue
+			}
+
+			if err := gitops.RefreshPullRequest(a.ID, d.ClusterID, downstreamGitOps); err != nil {
+				logger.Error(errors.Wrapf(err, "failed to refresh gitops pull request for app %s", a.ID))
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package types
+
+type PullRequestState string
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestMerged PullRequestState = "merged"
+	PullRequestClosed PullRequestState = "closed"
+)
+
+// PullRequest is a pull request (or merge request) opened in the gitops repo for an app version.
+type PullRequest struct {
+	ID       int64            `json:"id"`
+	URL      string           `json:"url"`
+	Branch   string           `json:"branch"`
+	State    PullRequestState `json:"state"`
+	Sequence int64            `json:"sequence"`
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	"github.com/replicatedhq/kots/pkg/airgap"
 	"github.com/replicatedhq/kots/pkg/automation"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
@@ -49,6 +50,11 @@ type GetAirgapUploadConfigResponse struct {
 	SimultaneousUploads int `json:"simultaneousUploads"`
 }
 
+type VerifyAirgapDeltaBaseResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
 var uploadedAirgapBundleChunks = map[string]struct{}{}
 var chunkLock sync.Mutex
 var fileLock sync.Mutex
@@ -102,6 +108,45 @@ func (h *Handler) GetAirgapUploadConfig(w http.ResponseWriter, r *http.Request)
 	JSON(w, http.StatusOK, response)
 }
 
+// VerifyAirgapDeltaBase checks that a delta airgap bundle was built against the deployed version,
+// so that the CLI can reject it before pushing any of its images
+func (h *Handler) VerifyAirgapDeltaBase(w http.ResponseWriter, r *http.Request) {
+	response := VerifyAirgapDeltaBaseResponse{
+		Success: false,
+	}
+
+	a, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		response.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	delta := kotsv1beta1.AirgapDelta{}
+	if err := json.NewDecoder(r.Body).Decode(&delta); err != nil {
+		response.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if err := airgap.VerifyDeltaBase(a, &delta); err != nil {
+		if actionableErr, ok := errors.Cause(err).(util.ActionableError); ok {
+			response.Error = actionableErr.Error()
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		response.Error = "failed to verify delta base"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	JSON(w, http.StatusOK, response)
+}
+
 func (h *Handler) CheckAirgapBundleChunk(w http.ResponseWriter, r *http.Request) {
 	resumableIdentifier := r.FormValue("resumableIdentifier")
 	resumableChunkNumber := r.FormValue("resumableChunkNumber")
@@ -328,7 +373,7 @@ func (h *Handler) UpdateAppFromAirgap(w http.ResponseWriter, r *http.Request) {
 	}
 
 	go func() {
-		if err := airgap.UpdateAppFromAirgap(a, airgapBundlePath, false, false, false); err != nil {
+		if err := airgap.UpdateAppFromAirgap(a, airgapBundlePath, false, false, false, false); err != nil {
 			logger.Error(errors.Wrap(err, "failed to update app from airgap bundle"))
 
 			// if NoRetry is set, we stll want to clean up immediately
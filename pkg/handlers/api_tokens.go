@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/apitoken"
+	apitokentypes "github.com/replicatedhq/kots/pkg/apitoken/types"
+	"github.com/replicatedhq/kots/pkg/identity"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/rbac"
+	"github.com/replicatedhq/kots/pkg/session"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+type CreateAPITokenRequest struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	// ExpiresIn is a duration such as "720h", the token does not expire if it is empty
+	ExpiresIn string `json:"expiresIn,omitempty"`
+}
+
+type CreateAPITokenResponse struct {
+	Success  bool                    `json:"success"`
+	Error    string                  `json:"error,omitempty"`
+	Token    string                  `json:"token,omitempty"`
+	APIToken *apitokentypes.APIToken `json:"apiToken,omitempty"`
+}
+
+type ListAPITokensResponse struct {
+	Success   bool                     `json:"success"`
+	Error     string                   `json:"error,omitempty"`
+	APITokens []apitokentypes.APIToken `json:"apiTokens"`
+}
+
+type RevokeAPITokenResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CreateAPIToken creates a named api token with the given roles. The token can only be read in this response.
+func (h *Handler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	response := CreateAPITokenResponse{}
+
+	request := CreateAPITokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error = "failed to decode request"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if request.Name == "" {
+		response.Error = "name is required"
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+	if len(request.Roles) == 0 {
+		response.Error = "at least one role is required"
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	var ttl time.Duration
+	if request.ExpiresIn != "" {
+		d, err := time.ParseDuration(request.ExpiresIn)
+		if err != nil || d <= 0 {
+			response.Error = fmt.Sprintf("invalid expiresIn %q", request.ExpiresIn)
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		ttl = d
+	}
+
+	roles, err := identity.GetRoles(r.Context(), util.PodNamespace)
+	if err != nil {
+		response.Error = "failed to get roles"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	sess := session.ContextGetSession(r)
+	for _, roleID := range request.Roles {
+		found := false
+		for _, role := range roles {
+			if role.ID == roleID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.Error = fmt.Sprintf("unknown role %q", roleID)
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+
+		// a token cannot be used to gain roles that the session creating it does not have
+		if sess != nil && sess.HasRBAC && !canGrantRole(sess.Roles, roleID) {
+			response.Error = fmt.Sprintf("cannot grant role %q", roleID)
+			JSON(w, http.StatusForbidden, response)
+			return
+		}
+	}
+
+	existingTokens, err := store.GetStore().ListAPITokens()
+	if err != nil {
+		response.Error = "failed to list api tokens"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	for _, existingToken := range existingTokens {
+		if existingToken.Name == request.Name && !existingToken.IsRevoked() && !existingToken.IsExpired(time.Now()) {
+			response.Error = fmt.Sprintf("an api token named %q already exists", request.Name)
+			JSON(w, http.StatusConflict, response)
+			return
+		}
+	}
+
+	tokenString, token, err := apitoken.Create(store.GetStore(), request.Name, request.Roles, ttl)
+	if err != nil {
+		response.Error = "failed to create api token"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	response.Token = tokenString
+	response.APIToken = token
+
+	JSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	response := ListAPITokensResponse{}
+
+	tokens, err := store.GetStore().ListAPITokens()
+	if err != nil {
+		response.Error = "failed to list api tokens"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	response.APITokens = tokens
+
+	JSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	response := RevokeAPITokenResponse{}
+
+	tokenID := mux.Vars(r)["tokenId"]
+	if _, _, err := store.GetStore().GetAPIToken(tokenID); err != nil {
+		if store.GetStore().IsNotFound(err) {
+			response.Error = fmt.Sprintf("api token %s not found", tokenID)
+			JSON(w, http.StatusNotFound, response)
+			return
+		}
+		response.Error = "failed to get api token"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	if err := store.GetStore().RevokeAPIToken(tokenID); err != nil {
+		response.Error = "failed to revoke api token"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
+// canGrantRole returns true if a session with the roles can create a token with the role.
+// Cluster admins can grant every role, since they already have every permission.
+func canGrantRole(roles []string, roleID string) bool {
+	for _, r := range roles {
+		if r == roleID || r == rbac.ClusterAdminRoleID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_canGrantRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []string
+		roleID string
+		want   bool
+	}{
+		{
+			name:   "same role",
+			roles:  []string{"support"},
+			roleID: "support",
+			want:   true,
+		},
+		{
+			name:   "cluster admin grants any role",
+			roles:  []string{"cluster-admin"},
+			roleID: "support",
+			want:   true,
+		},
+		{
+			name:   "other role",
+			roles:  []string{"support"},
+			roleID: "cluster-admin",
+			want:   false,
+		},
+		{
+			name:   "no roles",
+			roles:  []string{},
+			roleID: "support",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, canGrantRole(tt.roles, tt.roleID))
+		})
+	}
+}
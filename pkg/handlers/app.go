@@ -16,6 +16,7 @@ import (
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	"github.com/replicatedhq/kots/pkg/gitops"
 	"github.com/replicatedhq/kots/pkg/helm"
+	"github.com/replicatedhq/kots/pkg/identity"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/operator"
@@ -55,10 +56,15 @@ func (h *Handler) GetPendingApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	defaultRoles := rbac.DefaultRoles() // TODO (ethan): this should be set in the handler
+	roles, err := identity.GetRoles(r.Context(), util.PodNamespace)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get roles"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
 	if sess.HasRBAC { // handle pre-rbac sessions
-		allow, err := rbac.CheckAccess(r.Context(), defaultRoles, "read", fmt.Sprintf("app.%s", papp.Slug), sess.Roles)
+		allow, err := rbac.CheckAccess(r.Context(), roles, "read", fmt.Sprintf("app.%s", papp.Slug), sess.Roles)
 		if err != nil {
 			logger.Error(errors.Wrapf(err, "failed to check access for pending app %s", papp.Slug))
 			w.WriteHeader(http.StatusInternalServerError)
@@ -132,11 +138,16 @@ func (h *Handler) ListApps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	defaultRoles := rbac.DefaultRoles() // TODO (ethan): this should be set in the handler
+	roles, err := identity.GetRoles(r.Context(), util.PodNamespace)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get roles"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
 	for _, a := range apps {
 		if sess.HasRBAC { // handle pre-rbac sessions
-			allow, err := rbac.CheckAccess(r.Context(), defaultRoles, "read", fmt.Sprintf("app.%s", a.Slug), sess.Roles)
+			allow, err := rbac.CheckAccess(r.Context(), roles, "read", fmt.Sprintf("app.%s", a.Slug), sess.Roles)
 			if err != nil {
 				logger.Error(errors.Wrapf(err, "failed to check access for app %s", a.Slug))
 				w.WriteHeader(http.StatusInternalServerError)
@@ -293,19 +304,23 @@ func responseAppFromApp(a *apptypes.App) (*types.ResponseApp, error) {
 	}
 	responseGitOps := types.ResponseGitOps{}
 	if downstreamGitOps != nil {
+		// the state of the pull request is refreshed in the background, see pullrequests.Start
 		responseGitOps = types.ResponseGitOps{
-			Enabled:     true,
-			Provider:    downstreamGitOps.Provider,
-			Uri:         downstreamGitOps.RepoURI,
-			Hostname:    downstreamGitOps.Hostname,
-			HTTPPort:    downstreamGitOps.HTTPPort,
-			SSHPort:     downstreamGitOps.SSHPort,
-			Path:        downstreamGitOps.Path,
-			Branch:      downstreamGitOps.Branch,
-			Format:      downstreamGitOps.Format,
-			Action:      downstreamGitOps.Action,
-			DeployKey:   downstreamGitOps.PublicKey,
-			IsConnected: downstreamGitOps.IsConnected,
+			Enabled:          true,
+			Provider:         downstreamGitOps.Provider,
+			Uri:              downstreamGitOps.RepoURI,
+			Hostname:         downstreamGitOps.Hostname,
+			HTTPPort:         downstreamGitOps.HTTPPort,
+			SSHPort:          downstreamGitOps.SSHPort,
+			Path:             downstreamGitOps.Path,
+			Branch:           downstreamGitOps.Branch,
+			Format:           downstreamGitOps.Format,
+			Action:           downstreamGitOps.Action,
+			Wrapper:          downstreamGitOps.Wrapper,
+			WrapperNamespace: downstreamGitOps.WrapperNamespace,
+			DeployKey:        downstreamGitOps.PublicKey,
+			IsConnected:      downstreamGitOps.IsConnected,
+			PullRequest:      downstreamGitOps.PullRequest,
 		}
 	}
 
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+const defaultAuditLogPageSize = 50
+
+type ListAuditEventsResponse struct {
+	audittypes.EventList
+	Error string `json:"error,omitempty"`
+}
+
+// ListAuditEvents returns a page of the audit log, newest first.
+// The page is zero based and the results can be filtered by appSlug, actor and action.
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	response := ListAuditEventsResponse{}
+
+	opts := audittypes.ListOptions{
+		PageSize: defaultAuditLogPageSize,
+		AppSlug:  r.URL.Query().Get("appSlug"),
+		Actor:    r.URL.Query().Get("actor"),
+		Action:   r.URL.Query().Get("action"),
+	}
+
+	if page := r.URL.Query().Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil || p < 0 {
+			response.Error = "invalid page"
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		opts.Page = p
+	}
+
+	if pageSize := r.URL.Query().Get("pageSize"); pageSize != "" {
+		p, err := strconv.Atoi(pageSize)
+		if err != nil || p <= 0 {
+			response.Error = "invalid page size"
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		opts.PageSize = p
+	}
+
+	events, totalCount, err := store.GetStore().ListAuditEvents(opts)
+	if err != nil {
+		response.Error = "failed to list audit events"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Events = events
+	response.TotalCount = totalCount
+	response.Page = opts.Page
+	response.PageSize = opts.PageSize
+
+	JSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+type SetAutoRollbackPolicyRequest struct {
+	// AutoRollbackPolicy is the policy for rolling back unhealthy deploys. A nil policy removes it.
+	AutoRollbackPolicy *apptypes.AutoRollbackPolicy `json:"autoRollbackPolicy"`
+}
+
+type SetAutoRollbackPolicyResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) SetAutoRollbackPolicy(w http.ResponseWriter, r *http.Request) {
+	setAutoRollbackPolicyResponse := &SetAutoRollbackPolicyResponse{}
+
+	if util.IsHelmManaged() {
+		setAutoRollbackPolicyResponse.Error = "auto rollback is not supported for helm managed apps"
+		JSON(w, http.StatusBadRequest, setAutoRollbackPolicyResponse)
+		return
+	}
+
+	setAutoRollbackPolicyRequest := SetAutoRollbackPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&setAutoRollbackPolicyRequest); err != nil {
+		setAutoRollbackPolicyResponse.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, setAutoRollbackPolicyResponse.Error))
+		JSON(w, http.StatusBadRequest, setAutoRollbackPolicyResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		setAutoRollbackPolicyResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, setAutoRollbackPolicyResponse.Error))
+		JSON(w, http.StatusInternalServerError, setAutoRollbackPolicyResponse)
+		return
+	}
+
+	policy := setAutoRollbackPolicyRequest.AutoRollbackPolicy
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			setAutoRollbackPolicyResponse.Error = fmt.Sprintf("invalid auto rollback policy: %v", err)
+			logger.Error(errors.Wrap(err, "invalid auto rollback policy"))
+			JSON(w, http.StatusBadRequest, setAutoRollbackPolicyResponse)
+			return
+		}
+	}
+
+	if err := store.GetStore().SetAutoRollbackPolicy(foundApp.ID, policy); err != nil {
+		setAutoRollbackPolicyResponse.Error = "failed to set auto rollback policy"
+		logger.Error(errors.Wrap(err, setAutoRollbackPolicyResponse.Error))
+		JSON(w, http.StatusInternalServerError, setAutoRollbackPolicyResponse)
+		return
+	}
+
+	JSON(w, http.StatusNoContent, "")
+}
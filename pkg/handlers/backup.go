@@ -166,6 +166,31 @@ func (h *Handler) DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, deleteBackupResponse)
 }
 
+type StartRestoreDrillResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) StartRestoreDrill(w http.ResponseWriter, r *http.Request) {
+	startRestoreDrillResponse := StartRestoreDrillResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	if err := snapshot.StartRestoreDrill(r.Context(), util.PodNamespace, mux.Vars(r)["snapshotName"]); err != nil {
+		logger.Error(errors.Wrap(err, "failed to start restore drill"))
+		startRestoreDrillResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, startRestoreDrillResponse)
+		return
+	}
+
+	startRestoreDrillResponse.Success = true
+
+	JSON(w, http.StatusOK, startRestoreDrillResponse)
+}
+
 type CreateInstanceBackupRequest struct {
 }
 
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const kurlProxyLabelSelector = "app=kurl-proxy-kotsadm"
+
+// getClientIP returns the address of the client that sent the request, failed logins are limited per client address.
+// X-Forwarded-For is only honored for requests from kurl-proxy, and only the address that kurl-proxy appended to it,
+// the rest of the header is set by the client and could be used to get around the limits.
+// An empty string is returned if the client can't be told apart from other clients, e.g. with kubectl port-forward
+// every request comes from the loopback address. Only the global limit applies to those clients.
+func getClientIP(r *http.Request) string {
+	return clientIPFromRequest(r, isKurlProxy)
+}
+
+func clientIPFromRequest(r *http.Request, isTrustedProxy func(ip string) bool) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip.IsLoopback() {
+		return ""
+	}
+	if !isTrustedProxy(ip.String()) {
+		return ip.String()
+	}
+
+	forwardedFor := r.Header.Values("X-Forwarded-For")
+	if len(forwardedFor) == 0 {
+		return ""
+	}
+	hops := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+	forwardedIP := net.ParseIP(strings.TrimSpace(hops[len(hops)-1]))
+	if forwardedIP == nil || forwardedIP.IsLoopback() {
+		return ""
+	}
+	return forwardedIP.String()
+}
+
+// isKurlProxy returns true if the ip belongs to a kurl-proxy pod in the kotsadm namespace
+func isKurlProxy(ip string) bool {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get clientset"))
+		return false
+	}
+
+	pods, err := clientset.CoreV1().Pods(util.PodNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: kurlProxyLabelSelector,
+	})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list kurl-proxy pods"))
+		return false
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == ip {
+			return true
+		}
+		for _, podIP := range pod.Status.PodIPs {
+			if podIP.IP == ip {
+				return true
+			}
+		}
+	}
+
+	return false
+}
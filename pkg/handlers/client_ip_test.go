@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_clientIPFromRequest(t *testing.T) {
+	isTrustedProxy := func(ip string) bool {
+		return ip == "10.32.0.5"
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "192.168.1.10:54321",
+			want:       "192.168.1.10",
+		},
+		{
+			name:         "direct client forwarded header is ignored",
+			remoteAddr:   "192.168.1.10:54321",
+			forwardedFor: []string{"1.2.3.4"},
+			want:         "192.168.1.10",
+		},
+		{
+			name:         "trusted proxy",
+			remoteAddr:   "10.32.0.5:40000",
+			forwardedFor: []string{"192.168.1.10"},
+			want:         "192.168.1.10",
+		},
+		{
+			name:         "trusted proxy with a header set by the client",
+			remoteAddr:   "10.32.0.5:40000",
+			forwardedFor: []string{"1.2.3.4, 192.168.1.10"},
+			want:         "192.168.1.10",
+		},
+		{
+			name:         "trusted proxy with multiple headers",
+			remoteAddr:   "10.32.0.5:40000",
+			forwardedFor: []string{"1.2.3.4", "192.168.1.10"},
+			want:         "192.168.1.10",
+		},
+		{
+			name:       "trusted proxy without a header",
+			remoteAddr: "10.32.0.5:40000",
+			want:       "",
+		},
+		{
+			name:         "trusted proxy with an invalid header",
+			remoteAddr:   "10.32.0.5:40000",
+			forwardedFor: []string{"unknown"},
+			want:         "",
+		},
+		{
+			name:       "port-forward",
+			remoteAddr: "127.0.0.1:40000",
+			want:       "",
+		},
+		{
+			name:         "port-forward ipv6 with a header set by the client",
+			remoteAddr:   "[::1]:40000",
+			forwardedFor: []string{"1.2.3.4"},
+			want:         "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := http.NewRequest("POST", "/api/v1/login", nil)
+			require.NoError(t, err)
+			r.RemoteAddr = test.remoteAddr
+			for _, value := range test.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			assert.Equal(t, test.want, clientIPFromRequest(r, isTrustedProxy))
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/midstream"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/replicatedapp"
@@ -770,6 +771,10 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 		Success: false,
 	}
 
+	// the archive can be re-encrypted while it's being updated when the encryption key is rotated
+	unlock := util.LockAppVersion(updateApp.ID, sequence)
+	defer unlock()
+
 	archiveDir, err := ioutil.TempDir("", "kotsadm")
 	if err != nil {
 		updateAppConfigResponse.Error = "failed to create temp dir"
@@ -798,27 +803,8 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 		return updateAppConfigResponse, nil
 	}
 
-	// we don't merge, this is a wholesale replacement of the config values
-	// so we don't need the complex logic in kots, we can just write
-	if kotsKinds.ConfigValues != nil {
-		values := kotsKinds.ConfigValues.Spec.Values
-		kotsKinds.ConfigValues.Spec.Values = updateAppConfigValues(values, configGroups)
-
-		configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
-		if err != nil {
-			updateAppConfigResponse.Error = "failed to marshal config values spec"
-			return updateAppConfigResponse, err
-		}
-
-		if err := ioutil.WriteFile(filepath.Join(archiveDir, "upstream", "userdata", "config.yaml"), []byte(configValuesSpec), 0644); err != nil {
-			updateAppConfigResponse.Error = "failed to write config.yaml to upstream/userdata"
-			return updateAppConfigResponse, err
-		}
-	}
-
-	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(updateApp.ID)
-	if err != nil {
-		updateAppConfigResponse.Error = "failed to get registry settings"
+	if err := writeConfigValuesToArchive(kotsKinds, configGroups, archiveDir); err != nil {
+		updateAppConfigResponse.Error = "failed to write config values"
 		return updateAppConfigResponse, err
 	}
 
@@ -828,31 +814,12 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 		return updateAppConfigResponse, err
 	}
 
-	latestSequence, err := store.GetStore().GetLatestAppSequence(app.ID, true)
+	registrySettings, err := getVersionRegistrySettings(app.ID, sequence, archiveDir)
 	if err != nil {
-		updateAppConfigResponse.Error = "failed to get latest app sequence"
+		updateAppConfigResponse.Error = "failed to get registry settings"
 		return updateAppConfigResponse, err
 	}
 
-	if latestSequence != sequence {
-		// We are modifying an old version, registry settings may not match what the user has set
-		// for the app.  Midstream in version archive is the only place we can get them from.
-		versionRegistrySettings, err := midstream.LoadPrivateRegistryInfo(archiveDir)
-		if err != nil {
-			updateAppConfigResponse.Error = "failed to get version registry settings"
-			return updateAppConfigResponse, err
-		}
-
-		if versionRegistrySettings == nil {
-			registrySettings = registrytypes.RegistrySettings{}
-		} else {
-			// TODO: missing namespace
-			registrySettings.Hostname = versionRegistrySettings.Hostname
-			registrySettings.Username = versionRegistrySettings.Username
-			registrySettings.Password = versionRegistrySettings.Password
-		}
-	}
-
 	downstreams, err := store.GetStore().ListDownstreamsForApp(updateApp.ID)
 	if err != nil {
 		updateAppConfigResponse.Error = "failed to list downstreams for app"
@@ -915,7 +882,7 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 	}
 
 	if !skipPreflights || hasStrictPreflights {
-		if err := preflight.Run(updateApp.ID, updateApp.Slug, int64(sequence), updateApp.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(updateApp.ID, updateApp.Slug, int64(sequence), updateApp.IsAirgap, preflighttypes.PreflightTriggerConfigChange, archiveDir); err != nil {
 			updateAppConfigResponse.Error = errors.Cause(err).Error()
 			return updateAppConfigResponse, err
 		}
@@ -933,6 +900,62 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 	return updateAppConfigResponse, nil
 }
 
+// writeConfigValuesToArchive replaces the config values in the archive with the values of the config groups.
+// We don't merge, this is a wholesale replacement of the config values
+// so we don't need the complex logic in kots, we can just write.
+func writeConfigValuesToArchive(kotsKinds *kotsutil.KotsKinds, configGroups []kotsv1beta1.ConfigGroup, archiveDir string) error {
+	if kotsKinds.ConfigValues == nil {
+		return nil
+	}
+
+	values := kotsKinds.ConfigValues.Spec.Values
+	kotsKinds.ConfigValues.Spec.Values = updateAppConfigValues(values, configGroups)
+
+	configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config values spec")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(archiveDir, "upstream", "userdata", "config.yaml"), []byte(configValuesSpec), 0644); err != nil {
+		return errors.Wrap(err, "failed to write config.yaml to upstream/userdata")
+	}
+
+	return nil
+}
+
+// getVersionRegistrySettings returns the registry settings to render a version with
+func getVersionRegistrySettings(appID string, sequence int64, archiveDir string) (registrytypes.RegistrySettings, error) {
+	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(appID)
+	if err != nil {
+		return registrytypes.RegistrySettings{}, errors.Wrap(err, "failed to get registry settings")
+	}
+
+	latestSequence, err := store.GetStore().GetLatestAppSequence(appID, true)
+	if err != nil {
+		return registrytypes.RegistrySettings{}, errors.Wrap(err, "failed to get latest app sequence")
+	}
+
+	if latestSequence != sequence {
+		// We are modifying an old version, registry settings may not match what the user has set
+		// for the app.  Midstream in version archive is the only place we can get them from.
+		versionRegistrySettings, err := midstream.LoadPrivateRegistryInfo(archiveDir)
+		if err != nil {
+			return registrytypes.RegistrySettings{}, errors.Wrap(err, "failed to get version registry settings")
+		}
+
+		if versionRegistrySettings == nil {
+			registrySettings = registrytypes.RegistrySettings{}
+		} else {
+			// TODO: missing namespace
+			registrySettings.Hostname = versionRegistrySettings.Hostname
+			registrySettings.Username = versionRegistrySettings.Username
+			registrySettings.Password = versionRegistrySettings.Password
+		}
+	}
+
+	return registrySettings, nil
+}
+
 func getMissingRequiredConfig(configGroups []kotsv1beta1.ConfigGroup) ([]string, []string) {
 	requiredItems := make([]string, 0, 0)
 	requiredItemsTitles := make([]string, 0, 0)
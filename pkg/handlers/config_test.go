@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	"github.com/replicatedhq/kots/kotskinds/multitype"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -82,6 +86,64 @@ func Test_updateAppConfigValues(t *testing.T) {
 	}
 }
 
+func Test_writeConfigValuesToArchive(t *testing.T) {
+	configGroups := []kotsv1beta1.ConfigGroup{
+		{
+			Name: "pod",
+			Items: []kotsv1beta1.ConfigItem{
+				{
+					Name:  "podName",
+					Value: multitype.BoolOrString{Type: 0, StrVal: "real-pod"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		configValues *kotsv1beta1.ConfigValues
+		wantFile     bool
+	}{
+		{
+			name: "config values are replaced",
+			configValues: &kotsv1beta1.ConfigValues{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kots.io/v1beta1", Kind: "ConfigValues"},
+				Spec: kotsv1beta1.ConfigValuesSpec{
+					Values: map[string]kotsv1beta1.ConfigValue{
+						"podName": {Value: "test-pod"},
+					},
+				},
+			},
+			wantFile: true,
+		},
+		{
+			name:         "no config values",
+			configValues: nil,
+			wantFile:     false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			archiveDir := t.TempDir()
+			req.NoError(os.MkdirAll(filepath.Join(archiveDir, "upstream", "userdata"), 0755))
+
+			kotsKinds := &kotsutil.KotsKinds{ConfigValues: test.configValues}
+			req.NoError(writeConfigValuesToArchive(kotsKinds, configGroups, archiveDir))
+
+			b, err := ioutil.ReadFile(filepath.Join(archiveDir, "upstream", "userdata", "config.yaml"))
+			if !test.wantFile {
+				req.True(os.IsNotExist(err))
+				return
+			}
+			req.NoError(err)
+			req.Contains(string(b), "real-pod")
+			req.NotContains(string(b), "test-pod")
+		})
+	}
+}
+
 func Test_mergeConfigValues(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/encryptionkey"
+	encryptionkeytypes "github.com/replicatedhq/kots/pkg/encryptionkey/types"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+type RotateEncryptionKeyRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+type RotateEncryptionKeyResponse struct {
+	Success bool                               `json:"success"`
+	Error   string                             `json:"error,omitempty"`
+	Report  *encryptionkeytypes.RotationReport `json:"report,omitempty"`
+}
+
+type GetEncryptionKeyRotationStatusResponse struct {
+	Status         string                             `json:"status"`
+	CurrentMessage string                             `json:"currentMessage"`
+	Report         *encryptionkeytypes.RotationReport `json:"report,omitempty"`
+}
+
+// RotateEncryptionKey starts rotating the encryption key in the background. A dry run returns the report right away.
+func (h *Handler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	response := RotateEncryptionKeyResponse{}
+
+	request := RotateEncryptionKeyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error = "failed to decode request"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		response.Error = "failed to get k8s clientset"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	if request.DryRun {
+		report, err := encryptionkey.Rotate(clientset, util.PodNamespace, true)
+		if err != nil {
+			response.Error = "failed to report encryption key rotation"
+			logger.Error(errors.Wrap(err, response.Error))
+			JSON(w, http.StatusInternalServerError, response)
+			return
+		}
+		response.Success = true
+		response.Report = report
+		JSON(w, http.StatusOK, response)
+		return
+	}
+
+	if err := encryptionkey.StartRotation(clientset, util.PodNamespace); err != nil {
+		response.Error = errors.Cause(err).Error()
+		logger.Error(errors.Wrap(err, "failed to start encryption key rotation"))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	JSON(w, http.StatusAccepted, response)
+}
+
+func (h *Handler) GetEncryptionKeyRotationStatus(w http.ResponseWriter, r *http.Request) {
+	status, message, err := store.GetStore().GetTaskStatus(encryptionkey.TaskID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get task status"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := GetEncryptionKeyRotationStatusResponse{
+		Status:         status,
+		CurrentMessage: message,
+		Report:         encryptionkey.GetLastReport(),
+	}
+
+	JSON(w, http.StatusOK, response)
+}
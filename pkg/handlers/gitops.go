@@ -24,6 +24,10 @@ type UpdateAppGitOpsInput struct {
 	Path   string `json:"path"`
 	Format string `json:"format"`
 	Action string `json:"action"`
+	// Wrapper is "argocd" or "flux" to commit a resource for the gitops controller that deploys the app
+	Wrapper string `json:"wrapper"`
+	// WrapperNamespace is the namespace of the wrapper, defaults to "argocd" for Argo CD and "flux-system" for Flux
+	WrapperNamespace string `json:"wrapperNamespace"`
 }
 
 type CreateGitOpsRequest struct {
@@ -35,6 +39,8 @@ type CreateGitOpsInput struct {
 	Hostname string `json:"hostname"`
 	HTTPPort string `json:"httpPort"`
 	SSHPort  string `json:"sshPort"`
+	// Token is an api token for the provider, required to open pull requests
+	Token string `json:"token"`
 }
 
 func (h *Handler) UpdateAppGitOps(w http.ResponseWriter, r *http.Request) {
@@ -56,7 +62,13 @@ func (h *Handler) UpdateAppGitOps(w http.ResponseWriter, r *http.Request) {
 	}
 
 	gitOpsInput := updateAppGitOpsRequest.GitOpsInput
-	if err := gitops.UpdateDownstreamGitOps(a.ID, clusterID, gitOpsInput.URI, gitOpsInput.Branch, gitOpsInput.Path, gitOpsInput.Format, gitOpsInput.Action); err != nil {
+	if err := gitops.ValidateOutput(gitOpsInput.Format, gitOpsInput.Wrapper, gitOpsInput.WrapperNamespace); err != nil {
+		logger.Error(err)
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	if err := gitops.UpdateDownstreamGitOps(a.ID, clusterID, gitOpsInput.URI, gitOpsInput.Branch, gitOpsInput.Path, gitOpsInput.Format, gitOpsInput.Action, gitOpsInput.Wrapper, gitOpsInput.WrapperNamespace); err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -146,8 +158,8 @@ func (h *Handler) InitGitOpsConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if downstreamGitOps.Format != "single" {
-		logger.Error(errors.New("unsupported gitops format"))
+	if err := gitops.ValidateOutput(downstreamGitOps.Format, downstreamGitOps.Wrapper, downstreamGitOps.WrapperNamespace); err != nil {
+		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -167,7 +179,7 @@ func (h *Handler) InitGitOpsConnection(w http.ResponseWriter, r *http.Request) {
 	// If a branch is not provided, use the default branch
 	if downstreamGitOps.Branch == "" {
 		err := gitops.UpdateDownstreamGitOps(a.ID, d.ClusterID, downstreamGitOps.RepoURI, defaultBranchName,
-			downstreamGitOps.Path, downstreamGitOps.Format, downstreamGitOps.Action)
+			downstreamGitOps.Path, downstreamGitOps.Format, downstreamGitOps.Action, downstreamGitOps.Wrapper, downstreamGitOps.WrapperNamespace)
 		if err != nil {
 			logger.Infof("Failed to update the gitops configmap with the default branch: %v", err)
 
@@ -232,7 +244,7 @@ func (h *Handler) InitGitOpsConnection(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			_, err = gitops.CreateGitOpsCommit(downstreamGitOps, a.Slug, a.Name, int(appVersions.CurrentVersion.ParentSequence), currentVersionArchive, d.Name)
+			_, err = gitops.CreateGitOpsCommit(downstreamGitOps, a.ID, d.ClusterID, a.Slug, a.Name, int(appVersions.CurrentVersion.ParentSequence), currentVersionArchive, d.Name)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to create gitops commit for current version %d", appVersions.CurrentVersion.ParentSequence)
 				logger.Error(err)
@@ -260,7 +272,7 @@ func (h *Handler) InitGitOpsConnection(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			_, err = gitops.CreateGitOpsCommit(downstreamGitOps, a.Slug, a.Name, int(pendingVersion.ParentSequence), pendingVersionArchive, d.Name)
+			_, err = gitops.CreateGitOpsCommit(downstreamGitOps, a.ID, d.ClusterID, a.Slug, a.Name, int(pendingVersion.ParentSequence), pendingVersionArchive, d.Name)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to create gitops commit for pending version %d", pendingVersion.ParentSequence)
 				logger.Error(err)
@@ -310,7 +322,7 @@ func (h *Handler) CreateGitOps(w http.ResponseWriter, r *http.Request) {
 	}
 
 	gitOpsInput := createGitOpsRequest.GitOpsInput
-	if err := gitops.CreateGitOps(gitOpsInput.Provider, gitOpsInput.URI, gitOpsInput.Hostname, gitOpsInput.HTTPPort, gitOpsInput.SSHPort); err != nil {
+	if err := gitops.CreateGitOps(gitOpsInput.Provider, gitOpsInput.URI, gitOpsInput.Hostname, gitOpsInput.HTTPPort, gitOpsInput.SSHPort, gitOpsInput.Token); err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
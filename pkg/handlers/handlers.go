@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	kotsscheme "github.com/replicatedhq/kots/kotskinds/client/kotsclientset/scheme"
+	"github.com/replicatedhq/kots/pkg/audit"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/policy"
 	"github.com/replicatedhq/kots/pkg/store"
@@ -32,7 +33,7 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 
 	// Installation
 	r.Name("UploadNewLicense").Path("/api/v1/license").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.UploadNewLicense))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppCreate, audit.ActionUploadLicense, handler.UploadNewLicense))
 	r.Name("ExchangePlatformLicense").Path("/api/v1/license/platform").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.ExchangePlatformLicense))
 	r.Name("ResumeInstallOnline").Path("/api/v1/license/resume").Methods("PUT").
@@ -67,10 +68,14 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleWrite, handler.DeleteSupportBundle))
 	r.Name("GetPodDetailsFromSupportBundle").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundle/{bundleId}/pod").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleRead, handler.GetPodDetailsFromSupportBundle))
+	r.Name("SetSupportBundleSchedule").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundle-schedule").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleWrite, handler.SetSupportBundleSchedule))
+	r.Name("CompareSupportBundles").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundles/compare").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleRead, handler.CompareSupportBundles))
 
 	// redactor routes
 	r.Name("UpdateRedact").Path("/api/v1/redact/set").Methods("PUT").
-		HandlerFunc(middleware.EnforceAccess(policy.RedactorWrite, handler.UpdateRedact))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.RedactorWrite, audit.ActionUpdateRedactor, handler.UpdateRedact))
 	r.Name("GetRedact").Path("/api/v1/redact/get").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.RedactorRead, handler.GetRedact))
 	r.Name("ListRedactors").Path("/api/v1/redacts").Methods("GET").
@@ -78,11 +83,11 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("GetRedactMetadataAndYaml").Path("/api/v1/redact/spec/{slug}").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.RedactorRead, handler.GetRedactMetadataAndYaml))
 	r.Name("SetRedactMetadataAndYaml").Path("/api/v1/redact/spec/{slug}").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.RedactorWrite, handler.SetRedactMetadataAndYaml))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.RedactorWrite, audit.ActionUpdateRedactor, handler.SetRedactMetadataAndYaml))
 	r.Name("DeleteRedact").Path("/api/v1/redact/spec/{slug}").Methods("DELETE").
-		HandlerFunc(middleware.EnforceAccess(policy.RedactorWrite, handler.DeleteRedact))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.RedactorWrite, audit.ActionDeleteRedactor, handler.DeleteRedact))
 	r.Name("SetRedactEnabled").Path("/api/v1/redact/enabled/{slug}").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.RedactorWrite, handler.SetRedactEnabled))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.RedactorWrite, audit.ActionEnableRedactor, handler.SetRedactEnabled))
 
 	// Kotsadm Identity Service
 	r.Name("ConfigureIdentityService").Path("/api/v1/identity/config").Methods("POST").
@@ -131,16 +136,22 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.ResetAirgapInstallStatus))
 	r.Name("GetAirgapUploadConfig").Path("/api/v1/app/{appSlug}/airgap/config").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.GetAirgapUploadConfig))
+	r.Name("VerifyAirgapDeltaBase").Path("/api/v1/app/{appSlug}/airgap/verify-delta").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.VerifyAirgapDeltaBase))
 
 	// Implemented handlers
 	r.Name("IgnorePreflightRBACErrors").Path("/api/v1/app/{appSlug}/sequence/{sequence}/preflight/ignore-rbac").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightWrite, handler.IgnorePreflightRBACErrors))
 	r.Name("StartPreflightChecks").Path("/api/v1/app/{appSlug}/sequence/{sequence}/preflight/run").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightWrite, handler.StartPreflightChecks))
+	r.Name("DryRunPreflights").Path("/api/v1/app/{appSlug}/preflight/dry-run").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightWrite, handler.DryRunPreflights))
 	r.Name("GetLatestPreflightResultsForSequenceZero").Path("/api/v1/app/{appSlug}/preflight/result").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightRead, handler.GetLatestPreflightResultsForSequenceZero))
 	r.Name("GetPreflightResult").Path("/api/v1/app/{appSlug}/sequence/{sequence}/preflight/result").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightRead, handler.GetPreflightResult))
+	r.Name("GetPreflightResultsHistory").Path("/api/v1/app/{appSlug}/preflight/history").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamPreflightRead, handler.GetPreflightResultsHistory))
 	r.Name("GetPreflightCommand").Path("/api/v1/app/{appSlug}/sequence/{sequence}/preflightcommand").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRead, handler.GetPreflightCommand)) // this is intentional
 	r.Name("PreflightsReports").Path("/api/v1/app/{appSlug}/preflight/report").Methods("POST").
@@ -155,11 +166,13 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("GetAppVersionDownloadStatus").Path("/api/v1/app/{appSlug}/sequence/{sequence}/task/updatedownload").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRead, handler.GetAppVersionDownloadStatus)) // NOTE: appSlug is unused
 	r.Name("DeployAppVersion").Path("/api/v1/app/{appSlug}/sequence/{sequence}/deploy").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.DeployAppVersion))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppDownstreamWrite, audit.ActionDeployAppVersion, handler.DeployAppVersion))
 	r.Name("RedeployAppVersion").Path("/api/v1/app/{appSlug}/sequence/{sequence}/redeploy").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.RedeployAppVersion))
 	r.Name("GetAppRenderedContents").Path("/api/v1/app/{appSlug}/sequence/{sequence}/renderedcontents").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppRenderedContents))
+	r.Name("GetAppVersionDiff").Path("/api/v1/app/{appSlug}/diff/{fromSequence}/{toSequence}").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppVersionDiff))
 	r.Name("GetAppContents").Path("/api/v1/app/{appSlug}/sequence/{sequence}/contents").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppContents))
 	r.Name("GetAppDashboard").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/dashboard").Methods("GET").
@@ -173,6 +186,18 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.RegistryRead, handler.GetImageRewriteStatus))
 	r.Name("GarbageCollectImages").Path("/api/v1/garbage-collect-images").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.GarbageCollectImages))
+	r.Name("RotateEncryptionKey").Path("/api/v1/encryption-key/rotate").Methods("POST").
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.EncryptionKeyWrite, audit.ActionRotateEncryptionKey, handler.RotateEncryptionKey))
+	r.Name("GetEncryptionKeyRotationStatus").Path("/api/v1/encryption-key/rotate").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.EncryptionKeyRead, handler.GetEncryptionKeyRotationStatus))
+	r.Name("ListAuditEvents").Path("/api/v1/audit-log").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AuditLogRead, handler.ListAuditEvents))
+	r.Name("CreateAPIToken").Path("/api/v1/api-tokens").Methods("POST").
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.APITokenWrite, audit.ActionCreateAPIToken, handler.CreateAPIToken))
+	r.Name("ListAPITokens").Path("/api/v1/api-tokens").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.APITokenRead, handler.ListAPITokens))
+	r.Name("RevokeAPIToken").Path("/api/v1/api-tokens/{tokenId}").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.APITokenWrite, audit.ActionRevokeAPIToken, handler.RevokeAPIToken))
 	r.Name("DockerHubSecretUpdated").Path("/api/v1/docker/secret-updated").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.DockerHubSecretUpdated))
 
@@ -186,7 +211,7 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryWrite, handler.ValidateAppRegistry))
 
 	r.Name("UpdateAppConfig").Path("/api/v1/app/{appSlug}/config").Methods("PUT").
-		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamConfigWrite, handler.UpdateAppConfig))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppDownstreamConfigWrite, audit.ActionUpdateAppConfig, handler.UpdateAppConfig))
 	r.Name("CurrentAppConfig").Path("/api/v1/app/{appSlug}/config/{sequence}").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamConfigRead, handler.CurrentAppConfig))
 	r.Name("LiveAppConfig").Path("/api/v1/app/{appSlug}/liveconfig").Methods("POST").
@@ -197,9 +222,9 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamConfigRead, handler.DownloadFileFromConfig))
 
 	r.Name("SyncLicense").Path("/api/v1/app/{appSlug}/license").Methods("PUT").
-		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseWrite, handler.SyncLicense))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppLicenseWrite, audit.ActionSyncLicense, handler.SyncLicense))
 	r.Name("ChangeLicense").Path("/api/v1/app/{appSlug}/change-license").Methods("PUT").
-		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseWrite, handler.ChangeLicense))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppLicenseWrite, audit.ActionChangeLicense, handler.ChangeLicense))
 	r.Name("GetLicense").Path("/api/v1/app/{appSlug}/license").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseRead, handler.GetLicense))
 
@@ -209,6 +234,10 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetAutomaticUpdatesConfig))
 	r.Name("GetAutomaticUpdatesConfig").Path("/api/v1/app/{appSlug}/automaticupdates").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.GetAutomaticUpdatesConfig))
+	r.Name("SetAutoDeployWindow").Path("/api/v1/app/{appSlug}/automaticupdates/window").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetAutoDeployWindow))
+	r.Name("SetAutoRollbackPolicy").Path("/api/v1/app/{appSlug}/autorollback").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetAutoRollbackPolicy))
 	r.Name("RemoveApp").Path("/api/v1/app/{appSlug}/remove").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppUpdate, handler.RemoveApp))
 
@@ -220,7 +249,7 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("CancelRestore").Path("/api/v1/app/{appSlug}/snapshot/restore").Methods("DELETE").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreWrite, handler.CancelRestore))
 	r.Name("CreateApplicationRestore").Path("/api/v1/app/{appSlug}/snapshot/restore/{snapshotName}").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreWrite, handler.CreateApplicationRestore))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.AppRestoreWrite, audit.ActionRestoreApp, handler.CreateApplicationRestore))
 	r.Name("GetRestoreDetails").Path("/api/v1/app/{appSlug}/snapshot/restore/{restoreName}").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreRead, handler.GetRestoreDetails))
 	r.Name("ListBackups").Path("/api/v1/app/{appSlug}/snapshots").Methods("GET").
@@ -239,10 +268,14 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetInstanceSnapshotConfig))
 	r.Name("SaveInstanceSnapshotConfig").Path("/api/v1/snapshot/config").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.SaveInstanceSnapshotConfig))
+	r.Name("PreviewSnapshotRetention").Path("/api/v1/snapshot/retention/preview").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.PreviewSnapshotRetention))
 	r.Name("GetGlobalSnapshotSettings").Path("/api/v1/snapshots/settings").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetGlobalSnapshotSettings))
 	r.Name("UpdateGlobalSnapshotSettings").Path("/api/v1/snapshots/settings").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.UpdateGlobalSnapshotSettings))
+	r.Name("RemoveSnapshotStorageLocation").Path("/api/v1/snapshots/settings/locations/{locationName}").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.RemoveSnapshotStorageLocation))
 	r.Name("GetFileSystemSnapshotProviderInstructions").Path("/api/v1/snapshots/filesystem/instructions").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetFileSystemSnapshotProviderInstructions))
 	r.Name("GetBackup").Path("/api/v1/snapshot/{snapshotName}").Methods("GET").
@@ -250,9 +283,13 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("DeleteBackup").Path("/api/v1/snapshot/{snapshotName}/delete").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.DeleteBackup))
 	r.Name("RestoreApps").Path("/api/v1/snapshot/{snapshotName}/restore-apps").Methods("POST").
-		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.RestoreApps))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.RestoreWrite, audit.ActionRestoreApps, handler.RestoreApps))
 	r.Name("GetRestoreAppsStatus").Path("/api/v1/snapshot/{snapshotName}/apps-restore-status").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.GetRestoreAppsStatus))
+	r.Name("PreviewRestore").Path("/api/v1/snapshot/{snapshotName}/restore/preview").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.RestoreRead, handler.PreviewRestore))
+	r.Name("StartRestoreDrill").Path("/api/v1/snapshot/{snapshotName}/restore-drill").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.StartRestoreDrill))
 	r.Name("DownloadSnapshotLogs").Path("/api/v1/snapshot/{backup}/logs").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.DownloadSnapshotLogs))
 	r.Name("GetVeleroStatus").Path("/api/v1/velero").Methods("GET").
@@ -295,7 +332,7 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 
 	// Password change
 	r.Name("ChangePassword").Path("/api/v1/password/change").Methods("PUT").
-		HandlerFunc(middleware.EnforceAccess(policy.PasswordChange, handler.ChangePassword))
+		HandlerFunc(middleware.EnforceAccessAndAudit(policy.PasswordChange, audit.ActionChangePassword, handler.ChangePassword))
 
 	// Helm
 	r.Name("IsHelmManaged").Path("/api/v1/is-helm-managed").Methods("GET").
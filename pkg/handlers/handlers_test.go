@@ -31,6 +31,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.UploadNewLicense(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -220,6 +221,28 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"SetSupportBundleSchedule": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.SetSupportBundleSchedule(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"CompareSupportBundles": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.CompareSupportBundles(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 
 	// redactor routes
 	"UpdateRedact": {
@@ -227,6 +250,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.UpdateRedact(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -269,6 +293,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.SetRedactMetadataAndYaml(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -280,6 +305,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.DeleteRedact(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -291,6 +317,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.SetRedactEnabled(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -510,6 +537,17 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"VerifyAirgapDeltaBase": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.VerifyAirgapDeltaBase(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 
 	// Implemented handlers
 	"IgnorePreflightRBACErrors": {
@@ -534,6 +572,18 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"DryRunPreflights": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.DryRunPreflights(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+
 	"GetLatestPreflightResultsForSequenceZero": {
 		{
 			Vars:         map[string]string{"appSlug": "my-app"},
@@ -556,6 +606,17 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"GetPreflightResultsHistory": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.GetPreflightResultsHistory(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"GetPreflightCommand": {
 		{
 			Vars:         map[string]string{"appSlug": "my-app", "sequence": "1"},
@@ -607,6 +668,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.DeployAppVersion(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -656,6 +718,17 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"GetAppVersionDiff": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app", "fromSequence": "1", "toSequence": "2"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.GetAppVersionDiff(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"GetAppContents": {
 		{
 			Vars:         map[string]string{"appSlug": "my-app", "sequence": "1"},
@@ -754,6 +827,75 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"RotateEncryptionKey": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
+				handlerRecorder.RotateEncryptionKey(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"GetEncryptionKeyRotationStatus": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.GetEncryptionKeyRotationStatus(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+
+	// Audit log
+	"ListAuditEvents": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.ListAuditEvents(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+
+	// API tokens
+	"CreateAPIToken": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
+				handlerRecorder.CreateAPIToken(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"ListAPITokens": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.ListAPITokens(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"RevokeAPIToken": {
+		{
+			Vars:         map[string]string{"tokenId": "abc"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
+				handlerRecorder.RevokeAPIToken(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+
 	"DockerHubSecretUpdated": {
 		{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
@@ -782,6 +924,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.UpdateAppConfig(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -837,6 +980,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.SyncLicense(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -848,6 +992,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.ChangeLicense(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -898,6 +1043,28 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"SetAutoDeployWindow": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.SetAutoDeployWindow(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"SetAutoRollbackPolicy": {
+		{
+			Vars:         map[string]string{"appSlug": "my-app"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.SetAutoRollbackPolicy(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"RemoveApp": {
 		{
 			Vars:         map[string]string{"appSlug": "my-app"},
@@ -949,6 +1116,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.CreateApplicationRestore(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -1039,6 +1207,16 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"PreviewSnapshotRetention": {
+		{
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.PreviewSnapshotRetention(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"GetGlobalSnapshotSettings": {
 		{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
@@ -1059,6 +1237,17 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"RemoveSnapshotStorageLocation": {
+		{
+			Vars:         map[string]string{"locationName": "offsite"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.RemoveSnapshotStorageLocation(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"GetFileSystemSnapshotProviderInstructions": {
 		{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
@@ -1097,6 +1286,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.RestoreApps(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
@@ -1113,6 +1303,28 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			ExpectStatus: http.StatusOK,
 		},
 	},
+	"PreviewRestore": {
+		{
+			Vars:         map[string]string{"snapshotName": "snapshot-name"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.PreviewRestore(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
+	"StartRestoreDrill": {
+		{
+			Vars:         map[string]string{"snapshotName": "snapshot-name"},
+			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
+			SessionRoles: []string{rbac.ClusterAdminRoleID},
+			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				handlerRecorder.StartRestoreDrill(gomock.Any(), gomock.Any())
+			},
+			ExpectStatus: http.StatusOK,
+		},
+	},
 	"DownloadSnapshotLogs": {
 		{
 			Vars:         map[string]string{"backup": "backup-name"},
@@ -1303,6 +1515,7 @@ var HandlerPolicyTests = map[string][]HandlerPolicyTest{
 			Roles:        []rbactypes.Role{rbac.ClusterAdminRole},
 			SessionRoles: []string{rbac.ClusterAdminRoleID},
 			Calls: func(storeRecorder *mock_store.MockStoreMockRecorder, handlerRecorder *mock_handlers.MockKOTSHandlerMockRecorder) {
+				storeRecorder.CreateAuditEvent(gomock.Any())
 				handlerRecorder.ChangePassword(gomock.Any(), gomock.Any())
 			},
 			ExpectStatus: http.StatusOK,
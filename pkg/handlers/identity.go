@@ -23,7 +23,9 @@ import (
 	kotsadmidentity "github.com/replicatedhq/kots/pkg/kotsadmidentity"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/policy"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/rbac"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/store"
@@ -151,6 +153,9 @@ func (h *Handler) ConfigureIdentityService(w http.ResponseWriter, r *http.Reques
 		identityConfig.Spec.Groups = request.Groups
 	}
 
+	// custom roles are not edited in the admin console, keep the roles that were defined with the cli
+	identityConfig.Spec.Roles = previousConfig.Spec.Roles
+
 	ingressConfig, err := ingress.GetConfig(r.Context(), namespace)
 	if err != nil {
 		err = errors.Wrap(err, "failed to get ingress config")
@@ -166,6 +171,13 @@ func (h *Handler) ConfigureIdentityService(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := policy.ValidateRoles(identityConfig.Spec); err != nil {
+		err = errors.Wrap(err, "invalid identity config roles")
+		logger.Error(err)
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
 	// TODO: validate dex issuer
 	if err := identity.ValidateConnection(r.Context(), namespace, identityConfig, *ingressConfig); err != nil {
 		if _, ok := errors.Cause(err).(*identity.ErrorConnection); ok {
@@ -471,7 +483,7 @@ func (h *Handler) ConfigureAppIdentityService(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, archiveDir); err != nil {
+	if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, preflighttypes.PreflightTriggerConfigChange, archiveDir); err != nil {
 		err = errors.Wrap(err, "failed to run preflights")
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -603,12 +615,11 @@ func (h *Handler) GetIdentityServiceConfig(w http.ResponseWriter, r *http.Reques
 	}
 
 	roles := []kotsv1beta1.IdentityRole{}
-	defaultRoles := rbac.DefaultRoles()
-	for _, defaultRole := range defaultRoles {
+	for _, r := range rbac.RolesFromIdentityConfig(identityConfig.Spec) {
 		role := kotsv1beta1.IdentityRole{
-			ID:          defaultRole.ID,
-			Name:        defaultRole.Name,
-			Description: defaultRole.Description,
+			ID:          r.ID,
+			Name:        r.Name,
+			Description: r.Description,
 		}
 		roles = append(roles, role)
 	}
@@ -24,6 +24,8 @@ type KOTSHandler interface {
 	ShareSupportBundle(w http.ResponseWriter, r *http.Request)
 	DeleteSupportBundle(w http.ResponseWriter, r *http.Request)
 	GetPodDetailsFromSupportBundle(w http.ResponseWriter, r *http.Request)
+	SetSupportBundleSchedule(w http.ResponseWriter, r *http.Request)
+	CompareSupportBundles(w http.ResponseWriter, r *http.Request)
 
 	// redactor routes
 	UpdateRedact(w http.ResponseWriter, r *http.Request)
@@ -61,12 +63,15 @@ type KOTSHandler interface {
 	GetAirgapInstallStatus(w http.ResponseWriter, r *http.Request)
 	ResetAirgapInstallStatus(w http.ResponseWriter, r *http.Request)
 	GetAirgapUploadConfig(w http.ResponseWriter, r *http.Request)
+	VerifyAirgapDeltaBase(w http.ResponseWriter, r *http.Request)
 
 	// Implemented handlers
 	IgnorePreflightRBACErrors(w http.ResponseWriter, r *http.Request)
 	StartPreflightChecks(w http.ResponseWriter, r *http.Request)
+	DryRunPreflights(w http.ResponseWriter, r *http.Request)
 	GetLatestPreflightResultsForSequenceZero(w http.ResponseWriter, r *http.Request)
 	GetPreflightResult(w http.ResponseWriter, r *http.Request)
+	GetPreflightResultsHistory(w http.ResponseWriter, r *http.Request)
 	GetPreflightCommand(w http.ResponseWriter, r *http.Request) // this is intentionally policy.AppRead
 	PreflightsReports(w http.ResponseWriter, r *http.Request)
 
@@ -77,6 +82,7 @@ type KOTSHandler interface {
 	DeployAppVersion(w http.ResponseWriter, r *http.Request)
 	RedeployAppVersion(w http.ResponseWriter, r *http.Request)
 	GetAppRenderedContents(w http.ResponseWriter, r *http.Request)
+	GetAppVersionDiff(w http.ResponseWriter, r *http.Request)
 	GetAppContents(w http.ResponseWriter, r *http.Request)
 	GetAppDashboard(w http.ResponseWriter, r *http.Request)
 	GetDownstreamOutput(w http.ResponseWriter, r *http.Request)
@@ -89,6 +95,17 @@ type KOTSHandler interface {
 	ValidateAppRegistry(w http.ResponseWriter, r *http.Request)
 	GarbageCollectImages(w http.ResponseWriter, r *http.Request)
 
+	RotateEncryptionKey(w http.ResponseWriter, r *http.Request)
+	GetEncryptionKeyRotationStatus(w http.ResponseWriter, r *http.Request)
+
+	// Audit log
+	ListAuditEvents(w http.ResponseWriter, r *http.Request)
+
+	// API tokens
+	CreateAPIToken(w http.ResponseWriter, r *http.Request)
+	ListAPITokens(w http.ResponseWriter, r *http.Request)
+	RevokeAPIToken(w http.ResponseWriter, r *http.Request)
+
 	UpdateAppConfig(w http.ResponseWriter, r *http.Request)
 	CurrentAppConfig(w http.ResponseWriter, r *http.Request)
 	LiveAppConfig(w http.ResponseWriter, r *http.Request)
@@ -102,6 +119,8 @@ type KOTSHandler interface {
 	AppUpdateCheck(w http.ResponseWriter, r *http.Request)
 	SetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request)
 	GetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request)
+	SetAutoDeployWindow(w http.ResponseWriter, r *http.Request)
+	SetAutoRollbackPolicy(w http.ResponseWriter, r *http.Request)
 	RemoveApp(w http.ResponseWriter, r *http.Request)
 
 	// App snapshot routes
@@ -119,13 +138,17 @@ type KOTSHandler interface {
 	CreateInstanceBackup(w http.ResponseWriter, r *http.Request)
 	GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request)
 	SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request)
+	PreviewSnapshotRetention(w http.ResponseWriter, r *http.Request)
 	GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
 	UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
+	RemoveSnapshotStorageLocation(w http.ResponseWriter, r *http.Request)
 	GetFileSystemSnapshotProviderInstructions(w http.ResponseWriter, r *http.Request)
 	GetBackup(w http.ResponseWriter, r *http.Request)
 	DeleteBackup(w http.ResponseWriter, r *http.Request)
 	RestoreApps(w http.ResponseWriter, r *http.Request)
 	GetRestoreAppsStatus(w http.ResponseWriter, r *http.Request)
+	PreviewRestore(w http.ResponseWriter, r *http.Request)
+	StartRestoreDrill(w http.ResponseWriter, r *http.Request)
 	DownloadSnapshotLogs(w http.ResponseWriter, r *http.Request)
 	GetVeleroStatus(w http.ResponseWriter, r *http.Request)
 
@@ -64,6 +64,8 @@ type GetLicenseResponse struct {
 	Success bool            `json:"success"`
 	Error   string          `json:"error,omitempty"`
 	License LicenseResponse `json:"license"`
+	// LicenseData is the signed license, used by the cli to verify airgap bundles
+	LicenseData string `json:"licenseData,omitempty"`
 }
 
 type EntitlementResponse struct {
@@ -275,8 +277,18 @@ func (h *Handler) GetLicense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+	var b bytes.Buffer
+	if err := s.Encode(license, &b); err != nil {
+		getLicenseResponse.Error = "failed to marshal license"
+		logger.Error(errors.Wrap(err, getLicenseResponse.Error))
+		JSON(w, http.StatusInternalServerError, getLicenseResponse)
+		return
+	}
+
 	getLicenseResponse.Success = true
 	getLicenseResponse.License = *licenseResponse
+	getLicenseResponse.LicenseData = b.String()
 
 	JSON(w, http.StatusOK, getLicenseResponse)
 }
@@ -4,8 +4,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -76,14 +78,17 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	foundUser, err := user.LogIn(loginRequest.Password)
+	foundUser, err := user.LogIn(loginRequest.Password, getClientIP(r))
+	lockedOutErr := &user.LockedOutError{}
 	if err == user.ErrInvalidPassword {
 		loginResponse.Error = "Invalid password. Please try again."
 		JSON(w, http.StatusUnauthorized, loginResponse)
 		return
-	} else if err == user.ErrTooManyAttempts {
-		loginResponse.Error = "Admin Console has been locked.  Please reset password using the \"kubectl kots reset-password\" command."
-		JSON(w, http.StatusUnauthorized, loginResponse)
+	} else if errors.As(err, &lockedOutErr) {
+		remaining := lockoutRemainingSeconds(lockedOutErr.Until)
+		loginResponse.Error = fmt.Sprintf("Too many failed login attempts. Try again in %s, or unlock the Admin Console using the \"kubectl kots reset-password --unlock\" command.", time.Duration(remaining)*time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(remaining, 10))
+		JSON(w, http.StatusTooManyRequests, loginResponse)
 		return
 	} else if err != nil {
 		logger.Error(err)
@@ -410,7 +415,10 @@ func (h *Handler) OIDCLoginCallback(w http.ResponseWriter, r *http.Request) {
 
 type GetLoginInfoResponse struct {
 	Method LoginMethod `json:"method"`
-	Error  string      `json:"error,omitempty"`
+	// LockedUntil is set while shared password logins from the client are locked after too many failed attempts
+	LockedUntil             *time.Time `json:"lockedUntil,omitempty"`
+	LockoutRemainingSeconds int64      `json:"lockoutRemainingSeconds,omitempty"`
+	Error                   string     `json:"error,omitempty"`
 }
 
 func (h *Handler) GetLoginInfo(w http.ResponseWriter, r *http.Request) {
@@ -424,6 +432,15 @@ func (h *Handler) GetLoginInfo(w http.ResponseWriter, r *http.Request) {
 	}
 	if !identityConfig.Spec.Enabled || !identityConfig.Spec.DisablePasswordAuth {
 		getLoginInfoResponse.Method = PasswordAuth
+
+		lockedUntil, err := user.GetLockedUntil(getClientIP(r))
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get login lockout"))
+		} else if lockedUntil != nil {
+			getLoginInfoResponse.LockedUntil = lockedUntil
+			getLoginInfoResponse.LockoutRemainingSeconds = lockoutRemainingSeconds(*lockedUntil)
+		}
+
 		JSON(w, http.StatusOK, getLoginInfoResponse)
 		return
 	}
@@ -432,3 +449,11 @@ func (h *Handler) GetLoginInfo(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, getLoginInfoResponse)
 }
+
+func lockoutRemainingSeconds(lockedUntil time.Time) int64 {
+	remaining := int64(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if remaining < 1 {
+		return 1
+	}
+	return remaining
+}
@@ -154,6 +154,18 @@ func (mr *MockKOTSHandlerMockRecorder) CollectSupportBundle(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectSupportBundle", reflect.TypeOf((*MockKOTSHandler)(nil).CollectSupportBundle), w, r)
 }
 
+// CompareSupportBundles mocks base method.
+func (m *MockKOTSHandler) CompareSupportBundles(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompareSupportBundles", w, r)
+}
+
+// CompareSupportBundles indicates an expected call of CompareSupportBundles.
+func (mr *MockKOTSHandlerMockRecorder) CompareSupportBundles(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareSupportBundles", reflect.TypeOf((*MockKOTSHandler)(nil).CompareSupportBundles), w, r)
+}
+
 // ConfigureAppIdentityService mocks base method.
 func (m *MockKOTSHandler) ConfigureAppIdentityService(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -178,6 +190,18 @@ func (mr *MockKOTSHandlerMockRecorder) ConfigureIdentityService(w, r interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureIdentityService", reflect.TypeOf((*MockKOTSHandler)(nil).ConfigureIdentityService), w, r)
 }
 
+// CreateAPIToken mocks base method.
+func (m *MockKOTSHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateAPIToken", w, r)
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockKOTSHandlerMockRecorder) CreateAPIToken(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockKOTSHandler)(nil).CreateAPIToken), w, r)
+}
+
 // CreateAppFromAirgap mocks base method.
 func (m *MockKOTSHandler) CreateAppFromAirgap(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -394,6 +418,18 @@ func (mr *MockKOTSHandlerMockRecorder) DrainNode(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrainNode", reflect.TypeOf((*MockKOTSHandler)(nil).DrainNode), w, r)
 }
 
+// DryRunPreflights mocks base method.
+func (m *MockKOTSHandler) DryRunPreflights(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DryRunPreflights", w, r)
+}
+
+// DryRunPreflights indicates an expected call of DryRunPreflights.
+func (mr *MockKOTSHandlerMockRecorder) DryRunPreflights(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunPreflights", reflect.TypeOf((*MockKOTSHandler)(nil).DryRunPreflights), w, r)
+}
+
 // ExchangePlatformLicense mocks base method.
 func (m *MockKOTSHandler) ExchangePlatformLicense(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -598,6 +634,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetAppValuesFile(w, r interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppValuesFile", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppValuesFile), w, r)
 }
 
+// GetAppVersionDiff mocks base method.
+func (m *MockKOTSHandler) GetAppVersionDiff(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppVersionDiff", w, r)
+}
+
+// GetAppVersionDiff indicates an expected call of GetAppVersionDiff.
+func (mr *MockKOTSHandlerMockRecorder) GetAppVersionDiff(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersionDiff", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppVersionDiff), w, r)
+}
+
 // GetAppVersionDownloadStatus mocks base method.
 func (m *MockKOTSHandler) GetAppVersionDownloadStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -670,6 +718,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetDownstreamOutput(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstreamOutput", reflect.TypeOf((*MockKOTSHandler)(nil).GetDownstreamOutput), w, r)
 }
 
+// GetEncryptionKeyRotationStatus mocks base method.
+func (m *MockKOTSHandler) GetEncryptionKeyRotationStatus(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetEncryptionKeyRotationStatus", w, r)
+}
+
+// GetEncryptionKeyRotationStatus indicates an expected call of GetEncryptionKeyRotationStatus.
+func (mr *MockKOTSHandlerMockRecorder) GetEncryptionKeyRotationStatus(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEncryptionKeyRotationStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetEncryptionKeyRotationStatus), w, r)
+}
+
 // GetFileSystemSnapshotProviderInstructions mocks base method.
 func (m *MockKOTSHandler) GetFileSystemSnapshotProviderInstructions(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -862,6 +922,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetPreflightResult(w, r interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResult", reflect.TypeOf((*MockKOTSHandler)(nil).GetPreflightResult), w, r)
 }
 
+// GetPreflightResultsHistory mocks base method.
+func (m *MockKOTSHandler) GetPreflightResultsHistory(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetPreflightResultsHistory", w, r)
+}
+
+// GetPreflightResultsHistory indicates an expected call of GetPreflightResultsHistory.
+func (mr *MockKOTSHandlerMockRecorder) GetPreflightResultsHistory(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResultsHistory", reflect.TypeOf((*MockKOTSHandler)(nil).GetPreflightResultsHistory), w, r)
+}
+
 // GetRedact mocks base method.
 func (m *MockKOTSHandler) GetRedact(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1042,6 +1114,18 @@ func (mr *MockKOTSHandlerMockRecorder) IsHelmManaged(w, r interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsHelmManaged", reflect.TypeOf((*MockKOTSHandler)(nil).IsHelmManaged), w, r)
 }
 
+// ListAPITokens mocks base method.
+func (m *MockKOTSHandler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListAPITokens", w, r)
+}
+
+// ListAPITokens indicates an expected call of ListAPITokens.
+func (mr *MockKOTSHandlerMockRecorder) ListAPITokens(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokens", reflect.TypeOf((*MockKOTSHandler)(nil).ListAPITokens), w, r)
+}
+
 // ListApps mocks base method.
 func (m *MockKOTSHandler) ListApps(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1054,6 +1138,18 @@ func (mr *MockKOTSHandlerMockRecorder) ListApps(w, r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApps", reflect.TypeOf((*MockKOTSHandler)(nil).ListApps), w, r)
 }
 
+// ListAuditEvents mocks base method.
+func (m *MockKOTSHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListAuditEvents", w, r)
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockKOTSHandlerMockRecorder) ListAuditEvents(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockKOTSHandler)(nil).ListAuditEvents), w, r)
+}
+
 // ListBackups mocks base method.
 func (m *MockKOTSHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1138,6 +1234,30 @@ func (mr *MockKOTSHandlerMockRecorder) PreflightsReports(w, r interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreflightsReports", reflect.TypeOf((*MockKOTSHandler)(nil).PreflightsReports), w, r)
 }
 
+// PreviewRestore mocks base method.
+func (m *MockKOTSHandler) PreviewRestore(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PreviewRestore", w, r)
+}
+
+// PreviewRestore indicates an expected call of PreviewRestore.
+func (mr *MockKOTSHandlerMockRecorder) PreviewRestore(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewRestore", reflect.TypeOf((*MockKOTSHandler)(nil).PreviewRestore), w, r)
+}
+
+// PreviewSnapshotRetention mocks base method.
+func (m *MockKOTSHandler) PreviewSnapshotRetention(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PreviewSnapshotRetention", w, r)
+}
+
+// PreviewSnapshotRetention indicates an expected call of PreviewSnapshotRetention.
+func (mr *MockKOTSHandlerMockRecorder) PreviewSnapshotRetention(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewSnapshotRetention", reflect.TypeOf((*MockKOTSHandler)(nil).PreviewSnapshotRetention), w, r)
+}
+
 // RedeployAppVersion mocks base method.
 func (m *MockKOTSHandler) RedeployAppVersion(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1162,6 +1282,18 @@ func (mr *MockKOTSHandlerMockRecorder) RemoveApp(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveApp", reflect.TypeOf((*MockKOTSHandler)(nil).RemoveApp), w, r)
 }
 
+// RemoveSnapshotStorageLocation mocks base method.
+func (m *MockKOTSHandler) RemoveSnapshotStorageLocation(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveSnapshotStorageLocation", w, r)
+}
+
+// RemoveSnapshotStorageLocation indicates an expected call of RemoveSnapshotStorageLocation.
+func (mr *MockKOTSHandlerMockRecorder) RemoveSnapshotStorageLocation(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSnapshotStorageLocation", reflect.TypeOf((*MockKOTSHandler)(nil).RemoveSnapshotStorageLocation), w, r)
+}
+
 // ResetAirgapInstallStatus mocks base method.
 func (m *MockKOTSHandler) ResetAirgapInstallStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1210,6 +1342,30 @@ func (mr *MockKOTSHandlerMockRecorder) ResumeInstallOnline(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeInstallOnline", reflect.TypeOf((*MockKOTSHandler)(nil).ResumeInstallOnline), w, r)
 }
 
+// RevokeAPIToken mocks base method.
+func (m *MockKOTSHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeAPIToken", w, r)
+}
+
+// RevokeAPIToken indicates an expected call of RevokeAPIToken.
+func (mr *MockKOTSHandlerMockRecorder) RevokeAPIToken(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIToken", reflect.TypeOf((*MockKOTSHandler)(nil).RevokeAPIToken), w, r)
+}
+
+// RotateEncryptionKey mocks base method.
+func (m *MockKOTSHandler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RotateEncryptionKey", w, r)
+}
+
+// RotateEncryptionKey indicates an expected call of RotateEncryptionKey.
+func (mr *MockKOTSHandlerMockRecorder) RotateEncryptionKey(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateEncryptionKey", reflect.TypeOf((*MockKOTSHandler)(nil).RotateEncryptionKey), w, r)
+}
+
 // SaveInstanceSnapshotConfig mocks base method.
 func (m *MockKOTSHandler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1246,6 +1402,30 @@ func (mr *MockKOTSHandlerMockRecorder) SetAppConfigValues(w, r interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppConfigValues", reflect.TypeOf((*MockKOTSHandler)(nil).SetAppConfigValues), w, r)
 }
 
+// SetAutoDeployWindow mocks base method.
+func (m *MockKOTSHandler) SetAutoDeployWindow(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAutoDeployWindow", w, r)
+}
+
+// SetAutoDeployWindow indicates an expected call of SetAutoDeployWindow.
+func (mr *MockKOTSHandlerMockRecorder) SetAutoDeployWindow(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeployWindow", reflect.TypeOf((*MockKOTSHandler)(nil).SetAutoDeployWindow), w, r)
+}
+
+// SetAutoRollbackPolicy mocks base method.
+func (m *MockKOTSHandler) SetAutoRollbackPolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAutoRollbackPolicy", w, r)
+}
+
+// SetAutoRollbackPolicy indicates an expected call of SetAutoRollbackPolicy.
+func (mr *MockKOTSHandlerMockRecorder) SetAutoRollbackPolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoRollbackPolicy", reflect.TypeOf((*MockKOTSHandler)(nil).SetAutoRollbackPolicy), w, r)
+}
+
 // SetAutomaticUpdatesConfig mocks base method.
 func (m *MockKOTSHandler) SetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1294,6 +1474,18 @@ func (mr *MockKOTSHandlerMockRecorder) SetRedactMetadataAndYaml(w, r interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRedactMetadataAndYaml", reflect.TypeOf((*MockKOTSHandler)(nil).SetRedactMetadataAndYaml), w, r)
 }
 
+// SetSupportBundleSchedule mocks base method.
+func (m *MockKOTSHandler) SetSupportBundleSchedule(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSupportBundleSchedule", w, r)
+}
+
+// SetSupportBundleSchedule indicates an expected call of SetSupportBundleSchedule.
+func (mr *MockKOTSHandlerMockRecorder) SetSupportBundleSchedule(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSupportBundleSchedule", reflect.TypeOf((*MockKOTSHandler)(nil).SetSupportBundleSchedule), w, r)
+}
+
 // ShareSupportBundle mocks base method.
 func (m *MockKOTSHandler) ShareSupportBundle(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1318,6 +1510,18 @@ func (mr *MockKOTSHandlerMockRecorder) StartPreflightChecks(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPreflightChecks", reflect.TypeOf((*MockKOTSHandler)(nil).StartPreflightChecks), w, r)
 }
 
+// StartRestoreDrill mocks base method.
+func (m *MockKOTSHandler) StartRestoreDrill(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartRestoreDrill", w, r)
+}
+
+// StartRestoreDrill indicates an expected call of StartRestoreDrill.
+func (mr *MockKOTSHandlerMockRecorder) StartRestoreDrill(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRestoreDrill", reflect.TypeOf((*MockKOTSHandler)(nil).StartRestoreDrill), w, r)
+}
+
 // SyncLicense mocks base method.
 func (m *MockKOTSHandler) SyncLicense(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1449,3 +1653,15 @@ func (mr *MockKOTSHandlerMockRecorder) ValidateAppRegistry(w, r interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAppRegistry", reflect.TypeOf((*MockKOTSHandler)(nil).ValidateAppRegistry), w, r)
 }
+
+// VerifyAirgapDeltaBase mocks base method.
+func (m *MockKOTSHandler) VerifyAirgapDeltaBase(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyAirgapDeltaBase", w, r)
+}
+
+// VerifyAirgapDeltaBase indicates an expected call of VerifyAirgapDeltaBase.
+func (mr *MockKOTSHandlerMockRecorder) VerifyAirgapDeltaBase(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAirgapDeltaBase", reflect.TypeOf((*MockKOTSHandler)(nil).VerifyAirgapDeltaBase), w, r)
+}
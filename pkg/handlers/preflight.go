@@ -2,21 +2,28 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+	configvalidation "github.com/replicatedhq/kots/pkg/kotsadmconfig/validation"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
 	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/reporting"
 	"github.com/replicatedhq/kots/pkg/store"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+	"github.com/replicatedhq/kots/pkg/util"
 )
 
 type GetPreflightResultResponse struct {
@@ -24,6 +31,27 @@ type GetPreflightResultResponse struct {
 	PreflightResult   preflighttypes.PreflightResult `json:"preflightResult"`
 }
 
+type GetPreflightResultsHistoryResponse struct {
+	Success bool                                 `json:"success"`
+	Error   string                               `json:"error,omitempty"`
+	Runs    []preflighttypes.PreflightRunSummary `json:"runs"`
+}
+
+type DryRunPreflightsRequest struct {
+	Sequence     int64                     `json:"sequence"`
+	ConfigGroups []kotsv1beta1.ConfigGroup `json:"configGroups"`
+}
+
+type DryRunPreflightsResponse struct {
+	Success          bool                                     `json:"success"`
+	Error            string                                   `json:"error,omitempty"`
+	RequiredItems    []string                                 `json:"requiredItems,omitempty"`
+	ValidationErrors []configtypes.ConfigGroupValidationError `json:"validationErrors,omitempty"`
+	PreflightResults *preflighttypes.PreflightResults         `json:"preflightResults,omitempty"`
+	// State is one of pass, warn or fail
+	State string `json:"state,omitempty"`
+}
+
 type GetPreflightCommandRequest struct {
 	Origin string `json:"origin"`
 }
@@ -69,6 +97,45 @@ func (h *Handler) GetPreflightResult(w http.ResponseWriter, r *http.Request) {
 	JSON(w, 200, response)
 }
 
+// GetPreflightResultsHistory returns every preflight run of an app, newest first.
+// The optional sequence query parameter limits the runs to one version.
+func (h *Handler) GetPreflightResultsHistory(w http.ResponseWriter, r *http.Request) {
+	getPreflightResultsHistoryResponse := GetPreflightResultsHistoryResponse{}
+
+	var sequence *int64
+	if sequenceStr := r.URL.Query().Get("sequence"); sequenceStr != "" {
+		s, err := strconv.ParseInt(sequenceStr, 10, 64)
+		if err != nil {
+			getPreflightResultsHistoryResponse.Error = "failed to parse sequence"
+			logger.Error(errors.Wrap(err, getPreflightResultsHistoryResponse.Error))
+			JSON(w, http.StatusBadRequest, getPreflightResultsHistoryResponse)
+			return
+		}
+		sequence = &s
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		getPreflightResultsHistoryResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, getPreflightResultsHistoryResponse.Error))
+		JSON(w, http.StatusInternalServerError, getPreflightResultsHistoryResponse)
+		return
+	}
+
+	runs, err := preflight.GetResultsHistory(foundApp.ID, sequence)
+	if err != nil {
+		getPreflightResultsHistoryResponse.Error = "failed to get preflight results history"
+		logger.Error(errors.Wrap(err, getPreflightResultsHistoryResponse.Error))
+		JSON(w, http.StatusInternalServerError, getPreflightResultsHistoryResponse)
+		return
+	}
+
+	getPreflightResultsHistoryResponse.Success = true
+	getPreflightResultsHistoryResponse.Runs = runs
+
+	JSON(w, http.StatusOK, getPreflightResultsHistoryResponse)
+}
+
 func (h *Handler) GetLatestPreflightResultsForSequenceZero(w http.ResponseWriter, r *http.Request) {
 	appSlug := mux.Vars(r)["appSlug"]
 
@@ -158,7 +225,7 @@ func (h *Handler) IgnorePreflightRBACErrors(w http.ResponseWriter, r *http.Reque
 	removeArchiveDir = false
 	go func() {
 		defer os.RemoveAll(archiveDir)
-		if err := preflight.Run(foundApp.ID, foundApp.Slug, int64(sequence), foundApp.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(foundApp.ID, foundApp.Slug, int64(sequence), foundApp.IsAirgap, preflighttypes.PreflightTriggerRerun, archiveDir); err != nil {
 			logger.Error(errors.Wrap(err, "failed to run preflights"))
 			return
 		}
@@ -225,7 +292,7 @@ func (h *Handler) StartPreflightChecks(w http.ResponseWriter, r *http.Request) {
 	removeArchiveDir = false
 	go func() {
 		defer os.RemoveAll(archiveDir)
-		if err := preflight.Run(foundApp.ID, foundApp.Slug, int64(sequence), foundApp.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(foundApp.ID, foundApp.Slug, int64(sequence), foundApp.IsAirgap, preflighttypes.PreflightTriggerRerun, archiveDir); err != nil {
 			logger.Error(errors.Wrap(err, "failed to run preflights"))
 			return
 		}
@@ -234,6 +301,131 @@ func (h *Handler) StartPreflightChecks(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, struct{}{})
 }
 
+// DryRunPreflights renders a version with config values that have not been saved and runs its preflight checks.
+// No version is created and the results are only returned in the response.
+func (h *Handler) DryRunPreflights(w http.ResponseWriter, r *http.Request) {
+	dryRunPreflightsResponse := DryRunPreflightsResponse{}
+
+	if util.IsHelmManaged() {
+		dryRunPreflightsResponse.Error = "preflight dry runs are not supported for helm managed apps"
+		JSON(w, http.StatusBadRequest, dryRunPreflightsResponse)
+		return
+	}
+
+	dryRunPreflightsRequest := DryRunPreflightsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&dryRunPreflightsRequest); err != nil {
+		dryRunPreflightsResponse.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusBadRequest, dryRunPreflightsResponse)
+		return
+	}
+	sequence := dryRunPreflightsRequest.Sequence
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	validationErrors, err := configvalidation.ValidateConfigSpec(kotsv1beta1.ConfigSpec{Groups: dryRunPreflightsRequest.ConfigGroups})
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to validate config spec"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+	if len(validationErrors) > 0 {
+		dryRunPreflightsResponse.Error = "invalid config values"
+		dryRunPreflightsResponse.ValidationErrors = validationErrors
+		JSON(w, http.StatusBadRequest, dryRunPreflightsResponse)
+		return
+	}
+
+	requiredItems, requiredItemsTitles := getMissingRequiredConfig(dryRunPreflightsRequest.ConfigGroups)
+	if len(requiredItems) > 0 {
+		dryRunPreflightsResponse.RequiredItems = requiredItems
+		dryRunPreflightsResponse.Error = fmt.Sprintf("The following fields are required: %s", strings.Join(requiredItemsTitles, ", "))
+		JSON(w, http.StatusBadRequest, dryRunPreflightsResponse)
+		return
+	}
+
+	archiveDir, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to create temp dir"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+	defer os.RemoveAll(archiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(foundApp.ID, sequence, archiveDir); err != nil {
+		dryRunPreflightsResponse.Error = "failed to get app version archive"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archiveDir, "upstream"))
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to load kots kinds from path"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	if err := writeConfigValuesToArchive(kotsKinds, dryRunPreflightsRequest.ConfigGroups, archiveDir); err != nil {
+		dryRunPreflightsResponse.Error = "failed to write config values"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	registrySettings, err := getVersionRegistrySettings(foundApp.ID, sequence, archiveDir)
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to get registry settings"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	downstreams, err := store.GetStore().ListDownstreamsForApp(foundApp.ID)
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to list downstreams for app"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	if err := render.RenderDir(archiveDir, foundApp, downstreams, registrySettings, sequence); err != nil {
+		cause := errors.Cause(err)
+		if _, ok := cause.(util.ActionableError); ok {
+			dryRunPreflightsResponse.Error = cause.Error()
+			JSON(w, http.StatusBadRequest, dryRunPreflightsResponse)
+			return
+		}
+		dryRunPreflightsResponse.Error = "failed to render archive directory"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	preflightResults, err := preflight.DryRun(foundApp, sequence, archiveDir)
+	if err != nil {
+		dryRunPreflightsResponse.Error = "failed to run preflights"
+		logger.Error(errors.Wrap(err, dryRunPreflightsResponse.Error))
+		JSON(w, http.StatusInternalServerError, dryRunPreflightsResponse)
+		return
+	}
+
+	dryRunPreflightsResponse.Success = true
+	dryRunPreflightsResponse.PreflightResults = preflightResults
+	dryRunPreflightsResponse.State = preflight.GetPreflightState(preflightResults)
+
+	JSON(w, http.StatusOK, dryRunPreflightsResponse)
+}
+
 func (h *Handler) GetPreflightCommand(w http.ResponseWriter, r *http.Request) {
 	appSlug := mux.Vars(r)["appSlug"]
 	sequence, err := strconv.ParseInt(mux.Vars(r)["sequence"], 10, 64)
@@ -323,7 +515,7 @@ func (h *Handler) PostPreflightStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := store.GetStore().SetPreflightResults(foundApp.ID, sequence, b); err != nil {
+	if err := store.GetStore().SetPreflightResults(foundApp.ID, sequence, b, preflighttypes.PreflightTriggerCLI); err != nil {
 		err = errors.Wrap(err, "failed to set preflight results")
 		logger.Error(err)
 		w.WriteHeader(500)
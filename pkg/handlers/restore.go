@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -13,15 +14,22 @@ import (
 	snapshot "github.com/replicatedhq/kots/pkg/kotsadmsnapshot"
 	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	"github.com/replicatedhq/kots/pkg/logger"
+	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/util"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+type CreateApplicationRestoreRequest struct {
+	// Filter restores only part of the snapshot, without undeploying the app first
+	Filter *snapshottypes.RestoreFilter `json:"filter,omitempty"`
+}
+
 type CreateApplicationRestoreResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success     bool   `json:"success"`
+	RestoreName string `json:"restoreName,omitempty"`
+	Error       string `json:"error,omitempty"`
 }
 
 type GetRestoreStatusResponse struct {
@@ -35,6 +43,14 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 		Success: false,
 	}
 
+	// the request body is optional
+	createRestoreRequest := CreateApplicationRestoreRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&createRestoreRequest); err != nil && err != io.EOF {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	appSlug := mux.Vars(r)["appSlug"]
 	snapshotName := mux.Vars(r)["snapshotName"]
 	kotsadmNamespace := util.PodNamespace
@@ -72,6 +88,27 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if createRestoreRequest.Filter != nil && !createRestoreRequest.Filter.IsEmpty() {
+		restore, err := snapshot.CreateSelectiveRestore(r.Context(), kotsadmNamespace, snapshotName, appSlug, *createRestoreRequest.Filter)
+		if err != nil {
+			logger.Error(err)
+			if _, ok := errors.Cause(err).(*kotssnapshot.InvalidRestoreFilterError); ok {
+				createRestoreResponse.Error = errors.Cause(err).Error()
+				JSON(w, http.StatusBadRequest, createRestoreResponse)
+				return
+			}
+			createRestoreResponse.Error = "failed to create selective restore"
+			JSON(w, http.StatusInternalServerError, createRestoreResponse)
+			return
+		}
+
+		createRestoreResponse.Success = true
+		createRestoreResponse.RestoreName = restore.Name
+
+		JSON(w, http.StatusOK, createRestoreResponse)
+		return
+	}
+
 	status, err := store.GetStore().GetDownstreamVersionStatus(kotsApp.ID, sequence)
 	if err != nil {
 		logger.Error(err)
@@ -119,11 +156,14 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 type RestoreAppsRequest struct {
 	RestoreAll bool     `json:"restoreAll"`
 	AppSlugs   []string `json:"appSlugs"`
+	// Filter restores only part of the snapshot for each app, without undeploying the apps first
+	Filter *snapshottypes.RestoreFilter `json:"filter,omitempty"`
 }
 
 type RestoreAppsResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success      bool     `json:"success"`
+	RestoreNames []string `json:"restoreNames,omitempty"`
+	Error        string   `json:"error,omitempty"`
 }
 
 func (h *Handler) RestoreApps(w http.ResponseWriter, r *http.Request) {
@@ -180,6 +220,23 @@ func (h *Handler) RestoreApps(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		if restoreAppsRequest.Filter != nil && !restoreAppsRequest.Filter.IsEmpty() {
+			restore, err := snapshot.CreateSelectiveRestore(r.Context(), kotsadmNamespace, snapshotName, a.Slug, *restoreAppsRequest.Filter)
+			if err != nil {
+				logger.Error(err)
+				if _, ok := errors.Cause(err).(*kotssnapshot.InvalidRestoreFilterError); ok {
+					restoreResponse.Error = errors.Cause(err).Error()
+					JSON(w, http.StatusBadRequest, restoreResponse)
+					return
+				}
+				restoreResponse.Error = fmt.Sprintf("failed to create selective restore for app %s", a.Slug)
+				JSON(w, http.StatusInternalServerError, restoreResponse)
+				return
+			}
+			restoreResponse.RestoreNames = append(restoreResponse.RestoreNames, restore.Name)
+			continue
+		}
+
 		if err := app.ResetRestore(a.ID); err != nil {
 			logger.Error(err)
 			restoreResponse.Error = fmt.Sprintf("failed to reset restore for app %s", a.Slug)
@@ -208,6 +265,42 @@ func (h *Handler) RestoreApps(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, restoreResponse)
 }
 
+type PreviewRestoreResponse struct {
+	Contents *snapshottypes.BackupContents `json:"contents,omitempty"`
+	Error    string                        `json:"error,omitempty"`
+}
+
+// PreviewRestore lists the resources in a snapshot that a restore with the filter in the request body includes
+func (h *Handler) PreviewRestore(w http.ResponseWriter, r *http.Request) {
+	response := PreviewRestoreResponse{}
+
+	filter := snapshottypes.RestoreFilter{}
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	snapshotName := mux.Vars(r)["snapshotName"]
+
+	contents, err := snapshot.PreviewRestore(r.Context(), util.PodNamespace, snapshotName, filter)
+	if err != nil {
+		logger.Error(err)
+		if _, ok := errors.Cause(err).(*kotssnapshot.InvalidRestoreFilterError); ok {
+			response.Error = errors.Cause(err).Error()
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		response.Error = "failed to preview restore"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Contents = contents
+
+	JSON(w, http.StatusOK, response)
+}
+
 type GetRestoreAppsStatusRequest struct {
 	CheckAll bool     `json:"checkAll"`
 	AppSlugs []string `json:"appSlugs"`
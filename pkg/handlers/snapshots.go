@@ -69,6 +69,10 @@ type UpdateGlobalSnapshotSettingsRequest struct {
 	FileSystem *FileSystemOptions             `json:"fileSystem"`
 
 	CACertData []byte `json:"caCertData"`
+
+	// LocationName configures a secondary location that backups are replicated to instead of the primary store
+	LocationName      string                              `json:"locationName,omitempty"`
+	ReplicationPolicy kotssnapshottypes.ReplicationPolicy `json:"replicationPolicy,omitempty"`
 }
 
 type GetFileSystemSnapshotProviderInstructionsResponse struct {
@@ -126,6 +130,13 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	isSecondaryLocation := updateGlobalSnapshotSettingsRequest.LocationName != "" && updateGlobalSnapshotSettingsRequest.LocationName != kotssnapshot.DefaultBackupStorageLocationName
+	if isSecondaryLocation && (updateGlobalSnapshotSettingsRequest.FileSystem != nil || updateGlobalSnapshotSettingsRequest.Internal) {
+		globalSnapshotSettingsResponse.Error = "secondary locations must be object stores"
+		JSON(w, http.StatusBadRequest, globalSnapshotSettingsResponse)
+		return
+	}
+
 	kotsadmNamespace := util.PodNamespace
 
 	isMinioDisabled, err := kotssnapshot.IsFileSystemMinioDisabled(kotsadmNamespace)
@@ -242,6 +253,9 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 		IsMinioDisabled:  globalSnapshotSettingsResponse.IsMinioDisabled,
 
 		CACertData: updateGlobalSnapshotSettingsRequest.CACertData,
+
+		LocationName:      updateGlobalSnapshotSettingsRequest.LocationName,
+		ReplicationPolicy: updateGlobalSnapshotSettingsRequest.ReplicationPolicy,
 	}
 	updatedStore, err := kotssnapshot.ConfigureStore(r.Context(), options)
 	if err != nil {
@@ -369,6 +383,54 @@ func (h *Handler) GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Reque
 	JSON(w, http.StatusOK, globalSnapshotSettingsResponse)
 }
 
+type RemoveSnapshotStorageLocationResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) RemoveSnapshotStorageLocation(w http.ResponseWriter, r *http.Request) {
+	response := RemoveSnapshotStorageLocationResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	locationName := mux.Vars(r)["locationName"]
+
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(err)
+		response.Error = "failed to list clusters"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	for _, c := range clusters {
+		if c.SnapshotStorageLocation == locationName {
+			response.Error = fmt.Sprintf("location %s is used by scheduled snapshots", locationName)
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	if err := kotssnapshot.RemoveSecondaryStore(r.Context(), util.PodNamespace, locationName); err != nil {
+		if _, ok := errors.Cause(err).(*kotssnapshot.InvalidStoreDataError); ok {
+			logger.Error(err)
+			response.Error = err.Error()
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		logger.Error(err)
+		response.Error = "failed to remove storage location"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
 func (h *Handler) GetFileSystemSnapshotProviderInstructions(w http.ResponseWriter, r *http.Request) {
 	response := GetFileSystemSnapshotProviderInstructionsResponse{
 		Success: false,
@@ -661,9 +723,12 @@ func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 }
 
 type InstanceSnapshotConfig struct {
-	AutoEnabled  bool                            `json:"autoEnabled"`
-	AutoSchedule *snapshottypes.SnapshotSchedule `json:"autoSchedule"`
-	TTl          *snapshottypes.SnapshotTTL      `json:"ttl"`
+	AutoEnabled          bool                            `json:"autoEnabled"`
+	AutoSchedule         *snapshottypes.SnapshotSchedule `json:"autoSchedule"`
+	TTl                  *snapshottypes.SnapshotTTL      `json:"ttl"`
+	RestoreDrillSchedule string                          `json:"restoreDrillSchedule,omitempty"`
+	StorageLocation      string                          `json:"storageLocation,omitempty"`
+	Retention            *snapshottypes.RetentionPolicy  `json:"retention,omitempty"`
 }
 
 func (h *Handler) GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request) {
@@ -705,10 +770,20 @@ func (h *Handler) GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Reque
 		snapshotSchedule.Schedule = "0 0 * * MON"
 	}
 
+	retention, err := snapshot.ParseRetentionPolicy(c.SnapshotRetention)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	getInstanceSnapshotConfigResponse := InstanceSnapshotConfig{}
 	getInstanceSnapshotConfigResponse.AutoEnabled = c.SnapshotSchedule != ""
 	getInstanceSnapshotConfigResponse.AutoSchedule = snapshotSchedule
 	getInstanceSnapshotConfigResponse.TTl = ttl
+	getInstanceSnapshotConfigResponse.RestoreDrillSchedule = c.RestoreDrillSchedule
+	getInstanceSnapshotConfigResponse.StorageLocation = c.SnapshotStorageLocation
+	getInstanceSnapshotConfigResponse.Retention = retention
 
 	JSON(w, http.StatusOK, getInstanceSnapshotConfigResponse)
 }
@@ -718,6 +793,14 @@ type SaveInstanceSnapshotConfigRequest struct {
 	InputTimeUnit string `json:"inputTimeUnit"`
 	Schedule      string `json:"schedule"`
 	AutoEnabled   bool   `json:"autoEnabled"`
+	// RestoreDrillSchedule is left unchanged when not set, an empty string disables scheduled restore drills
+	RestoreDrillSchedule *string `json:"restoreDrillSchedule,omitempty"`
+	// StorageLocation is the location scheduled snapshots are taken in. It's left unchanged when not set,
+	// an empty string uses the primary store.
+	StorageLocation *string `json:"storageLocation,omitempty"`
+	// Retention prunes scheduled snapshots by count. It's left unchanged when not set, a policy
+	// that keeps nothing in every tier disables it.
+	Retention *snapshottypes.RetentionPolicy `json:"retention,omitempty"`
 }
 
 type SaveInstanceSnapshotConfigResponse struct {
@@ -775,6 +858,75 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	if requestBody.RestoreDrillSchedule != nil && *requestBody.RestoreDrillSchedule != c.RestoreDrillSchedule {
+		restoreDrillSchedule := *requestBody.RestoreDrillSchedule
+		if restoreDrillSchedule != "" {
+			if _, err := cron.ParseStandard(restoreDrillSchedule); err != nil {
+				logger.Error(err)
+				responseBody.Error = fmt.Sprintf("Invalid restore drill cron schedule expression: %s", restoreDrillSchedule)
+				JSON(w, http.StatusBadRequest, responseBody)
+				return
+			}
+		}
+		if err := saveRestoreDrillSchedule(c.ClusterID, restoreDrillSchedule); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to save restore drill schedule"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.StorageLocation != nil && *requestBody.StorageLocation != c.SnapshotStorageLocation {
+		storageLocation := *requestBody.StorageLocation
+		if storageLocation == kotssnapshot.DefaultBackupStorageLocationName {
+			storageLocation = ""
+		}
+		if storageLocation != "" {
+			exists, err := kotssnapshot.BackupStorageLocationExists(r.Context(), util.PodNamespace, storageLocation)
+			if err != nil {
+				logger.Error(err)
+				responseBody.Error = "Failed to check storage location"
+				JSON(w, http.StatusInternalServerError, responseBody)
+				return
+			}
+			if !exists {
+				responseBody.Error = fmt.Sprintf("Storage location %s not found", storageLocation)
+				JSON(w, http.StatusBadRequest, responseBody)
+				return
+			}
+		}
+		if err := store.GetStore().SetInstanceSnapshotStorageLocation(c.ClusterID, storageLocation); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set instance snapshot storage location"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.Retention != nil {
+		if err := snapshot.ValidateRetentionPolicy(*requestBody.Retention); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid retention policy: %s", err)
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+		retention, err := snapshot.FormatRetentionPolicy(*requestBody.Retention)
+		if err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to format retention policy"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+		if retention != c.SnapshotRetention {
+			if err := store.GetStore().SetInstanceSnapshotRetention(c.ClusterID, retention); err != nil {
+				logger.Error(err)
+				responseBody.Error = "Failed to set instance snapshot retention policy"
+				JSON(w, http.StatusInternalServerError, responseBody)
+				return
+			}
+		}
+	}
+
 	if !requestBody.AutoEnabled {
 		if err := store.GetStore().SetInstanceSnapshotSchedule(c.ClusterID, ""); err != nil {
 			logger.Error(err)
@@ -828,6 +980,52 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 	JSON(w, http.StatusOK, responseBody)
 }
 
+type PreviewSnapshotRetentionResponse struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Keep    []string `json:"keep"`
+	Prune   []string `json:"prune"`
+}
+
+// PreviewSnapshotRetention returns the scheduled instance snapshots that a retention policy would keep and prune,
+// without saving the policy
+func (h *Handler) PreviewSnapshotRetention(w http.ResponseWriter, r *http.Request) {
+	response := PreviewSnapshotRetentionResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	policy := snapshottypes.RetentionPolicy{}
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		logger.Error(err)
+		response.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if err := snapshot.ValidateRetentionPolicy(policy); err != nil {
+		response.Error = fmt.Sprintf("invalid retention policy: %s", err)
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	preview, err := snapshot.PreviewInstanceBackupRetention(r.Context(), util.PodNamespace, policy)
+	if err != nil {
+		logger.Error(err)
+		response.Error = "failed to preview retention policy"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	response.Keep = preview.Keep
+	response.Prune = preview.Prune
+
+	JSON(w, http.StatusOK, response)
+}
+
 func requiresKotsadmVeleroAccess(w http.ResponseWriter, r *http.Request) error {
 	kotsadmNamespace := util.PodNamespace
 	requiresVeleroAccess, err := kotssnapshot.CheckKotsadmVeleroAccess(r.Context(), kotsadmNamespace)
@@ -851,3 +1049,31 @@ func requiresKotsadmVeleroAccess(w http.ResponseWriter, r *http.Request) error {
 	}
 	return nil
 }
+
+func saveRestoreDrillSchedule(clusterID string, schedule string) error {
+	if err := store.GetStore().DeletePendingScheduledRestoreDrills(clusterID); err != nil {
+		return errors.Wrap(err, "failed to delete pending scheduled restore drills")
+	}
+
+	if schedule == "" {
+		if err := store.GetStore().SetInstanceRestoreDrillSchedule(clusterID, ""); err != nil {
+			return errors.Wrap(err, "failed to clear restore drill schedule")
+		}
+		return nil
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse cron expression")
+	}
+	if err := store.GetStore().SetInstanceRestoreDrillSchedule(clusterID, schedule); err != nil {
+		return errors.Wrap(err, "failed to save restore drill schedule")
+	}
+	queued := cronSchedule.Next(time.Now())
+	id := strings.ToLower(rand.String(32))
+	if err := store.GetStore().CreateScheduledRestoreDrill(id, clusterID, queued); err != nil {
+		return errors.Wrap(err, "failed to create first scheduled restore drill")
+	}
+
+	return nil
+}
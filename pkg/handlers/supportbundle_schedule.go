@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+type SetSupportBundleScheduleRequest struct {
+	// SupportBundleSchedule is the schedule for collecting support bundles. A nil schedule removes it.
+	SupportBundleSchedule *apptypes.SupportBundleSchedule `json:"supportBundleSchedule"`
+}
+
+type SetSupportBundleScheduleResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) SetSupportBundleSchedule(w http.ResponseWriter, r *http.Request) {
+	setSupportBundleScheduleResponse := &SetSupportBundleScheduleResponse{}
+
+	if util.IsHelmManaged() {
+		setSupportBundleScheduleResponse.Error = "scheduled support bundles are not supported for helm managed apps"
+		JSON(w, http.StatusBadRequest, setSupportBundleScheduleResponse)
+		return
+	}
+
+	setSupportBundleScheduleRequest := SetSupportBundleScheduleRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&setSupportBundleScheduleRequest); err != nil {
+		setSupportBundleScheduleResponse.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, setSupportBundleScheduleResponse.Error))
+		JSON(w, http.StatusBadRequest, setSupportBundleScheduleResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		setSupportBundleScheduleResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, setSupportBundleScheduleResponse.Error))
+		JSON(w, http.StatusInternalServerError, setSupportBundleScheduleResponse)
+		return
+	}
+
+	schedule := setSupportBundleScheduleRequest.SupportBundleSchedule
+	if schedule != nil {
+		if err := schedule.Validate(); err != nil {
+			setSupportBundleScheduleResponse.Error = fmt.Sprintf("invalid support bundle schedule: %v", err)
+			logger.Error(errors.Wrap(err, "invalid support bundle schedule"))
+			JSON(w, http.StatusBadRequest, setSupportBundleScheduleResponse)
+			return
+		}
+	}
+
+	if err := store.GetStore().SetSupportBundleSchedule(foundApp.ID, schedule); err != nil {
+		setSupportBundleScheduleResponse.Error = "failed to set support bundle schedule"
+		logger.Error(errors.Wrap(err, setSupportBundleScheduleResponse.Error))
+		JSON(w, http.StatusInternalServerError, setSupportBundleScheduleResponse)
+		return
+	}
+
+	JSON(w, http.StatusNoContent, "")
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/helm"
 	"github.com/replicatedhq/kots/pkg/logger"
-	"github.com/replicatedhq/kots/pkg/reporting"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/supportbundle"
 	"github.com/replicatedhq/kots/pkg/supportbundle/types"
@@ -24,20 +23,21 @@ import (
 )
 
 type GetSupportBundleResponse struct {
-	ID         string                       `json:"id"`
-	Slug       string                       `json:"slug"`
-	AppID      string                       `json:"appId"`
-	Name       string                       `json:"name"`
-	Size       float64                      `json:"size"`
-	Status     string                       `json:"status"`
-	TreeIndex  string                       `json:"treeIndex"`
-	CreatedAt  time.Time                    `json:"createdAt"`
-	UploadedAt *time.Time                   `json:"uploadedAt"`
-	UpdatedAt  *time.Time                   `json:"updatedAt"`
-	SharedAt   *time.Time                   `json:"sharedAt"`
-	IsArchived bool                         `json:"isArchived"`
-	Analysis   *types.SupportBundleAnalysis `json:"analysis"`
-	Progress   *types.SupportBundleProgress `json:"progress"`
+	ID          string                       `json:"id"`
+	Slug        string                       `json:"slug"`
+	AppID       string                       `json:"appId"`
+	Name        string                       `json:"name"`
+	Size        float64                      `json:"size"`
+	Status      string                       `json:"status"`
+	TreeIndex   string                       `json:"treeIndex"`
+	CreatedAt   time.Time                    `json:"createdAt"`
+	UploadedAt  *time.Time                   `json:"uploadedAt"`
+	UpdatedAt   *time.Time                   `json:"updatedAt"`
+	SharedAt    *time.Time                   `json:"sharedAt"`
+	IsArchived  bool                         `json:"isArchived"`
+	IsScheduled bool                         `json:"isScheduled"`
+	Analysis    *types.SupportBundleAnalysis `json:"analysis"`
+	Progress    *types.SupportBundleProgress `json:"progress"`
 }
 
 type GetSupportBundleFilesResponse struct {
@@ -51,17 +51,18 @@ type ListSupportBundlesResponse struct {
 	SupportBundles []ResponseSupportBundle `json:"supportBundles"`
 }
 type ResponseSupportBundle struct {
-	ID         string                       `json:"id"`
-	Slug       string                       `json:"slug"`
-	AppID      string                       `json:"appId"`
-	Name       string                       `json:"name"`
-	Size       float64                      `json:"size"`
-	Status     string                       `json:"status"`
-	CreatedAt  time.Time                    `json:"createdAt"`
-	UploadedAt *time.Time                   `json:"uploadedAt"`
-	SharedAt   *time.Time                   `json:"sharedAt"`
-	IsArchived bool                         `json:"isArchived"`
-	Analysis   *types.SupportBundleAnalysis `json:"analysis"`
+	ID          string                       `json:"id"`
+	Slug        string                       `json:"slug"`
+	AppID       string                       `json:"appId"`
+	Name        string                       `json:"name"`
+	Size        float64                      `json:"size"`
+	Status      string                       `json:"status"`
+	CreatedAt   time.Time                    `json:"createdAt"`
+	UploadedAt  *time.Time                   `json:"uploadedAt"`
+	SharedAt    *time.Time                   `json:"sharedAt"`
+	IsArchived  bool                         `json:"isArchived"`
+	IsScheduled bool                         `json:"isScheduled"`
+	Analysis    *types.SupportBundleAnalysis `json:"analysis"`
 }
 
 type GetSupportBundleCommandRequest struct {
@@ -118,20 +119,21 @@ func (h *Handler) GetSupportBundle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	getSupportBundleResponse := GetSupportBundleResponse{
-		ID:         bundle.ID,
-		Slug:       bundle.Slug,
-		AppID:      bundle.AppID,
-		Name:       bundle.Name,
-		Size:       bundle.Size,
-		Status:     string(bundle.Status),
-		TreeIndex:  bundle.TreeIndex,
-		CreatedAt:  bundle.CreatedAt,
-		UpdatedAt:  bundle.UpdatedAt,
-		UploadedAt: bundle.UploadedAt,
-		SharedAt:   bundle.SharedAt,
-		IsArchived: bundle.IsArchived,
-		Analysis:   analysis,
-		Progress:   &bundle.Progress,
+		ID:          bundle.ID,
+		Slug:        bundle.Slug,
+		AppID:       bundle.AppID,
+		Name:        bundle.Name,
+		Size:        bundle.Size,
+		Status:      string(bundle.Status),
+		TreeIndex:   bundle.TreeIndex,
+		CreatedAt:   bundle.CreatedAt,
+		UpdatedAt:   bundle.UpdatedAt,
+		UploadedAt:  bundle.UploadedAt,
+		SharedAt:    bundle.SharedAt,
+		IsArchived:  bundle.IsArchived,
+		IsScheduled: bundle.IsScheduled,
+		Analysis:    analysis,
+		Progress:    &bundle.Progress,
 	}
 
 	JSON(w, http.StatusOK, getSupportBundleResponse)
@@ -197,17 +199,18 @@ func (h *Handler) ListSupportBundles(w http.ResponseWriter, r *http.Request) {
 		}
 
 		responseSupportBundle := ResponseSupportBundle{
-			ID:         bundle.ID,
-			Slug:       bundle.Slug,
-			AppID:      bundle.AppID,
-			Name:       bundle.Name,
-			Size:       bundle.Size,
-			Status:     string(bundle.Status),
-			CreatedAt:  bundle.CreatedAt,
-			UploadedAt: bundle.UploadedAt,
-			IsArchived: bundle.IsArchived,
-			SharedAt:   bundle.SharedAt,
-			Analysis:   analysis,
+			ID:          bundle.ID,
+			Slug:        bundle.Slug,
+			AppID:       bundle.AppID,
+			Name:        bundle.Name,
+			Size:        bundle.Size,
+			Status:      string(bundle.Status),
+			CreatedAt:   bundle.CreatedAt,
+			UploadedAt:  bundle.UploadedAt,
+			IsArchived:  bundle.IsArchived,
+			SharedAt:    bundle.SharedAt,
+			IsScheduled: bundle.IsScheduled,
+			Analysis:    analysis,
 		}
 
 		responseSupportBundles = append(responseSupportBundles, responseSupportBundle)
@@ -353,25 +356,6 @@ func (h *Handler) ShareSupportBundle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if app.IsAirgap {
-		logger.Error(errors.New("Support bundle sharing is not supported for airgapped installations."))
-		JSON(w, http.StatusBadRequest, nil)
-		return
-	}
-
-	license, err := store.GetStore().GetLatestLicenseForApp(app.ID)
-	if err != nil {
-		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-
-	if !license.Spec.IsSupportBundleUploadSupported {
-		logger.Errorf("License does not have support bundle sharing enabled")
-		JSON(w, http.StatusForbidden, nil)
-		return
-	}
-
 	bundle, err := store.GetStore().GetSupportBundle(bundleID)
 	if err != nil {
 		logger.Error(err)
@@ -379,72 +363,21 @@ func (h *Handler) ShareSupportBundle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bundleArchive, err := store.GetStore().GetSupportBundleArchive(bundle.ID)
-	if err != nil {
-		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-	defer os.RemoveAll(bundleArchive)
-
-	f, err := os.Open(bundleArchive)
-	if err != nil {
-		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-	defer f.Close()
-
-	fileStat, err := f.Stat()
-	if err != nil {
-		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-
-	endpoint := fmt.Sprintf("%s/supportbundle/upload/%s", license.Spec.Endpoint, license.Spec.AppSlug)
-
-	req, err := util.NewRequest("POST", endpoint, f)
-	if err != nil {
-		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-
-	reportingInfo := reporting.GetReportingInfo(app.ID)
-	reporting.InjectReportingInfoHeaders(req, reportingInfo)
-
-	req.Header.Set("Content-Type", "application/tar+gzip")
-	req.Header.Set("X-Replicated-SupportBundle-CollectedAt", bundle.CreatedAt.Format(time.RFC3339))
-
-	req.ContentLength = fileStat.Size()
-
-	req.SetBasicAuth(license.Spec.LicenseID, license.Spec.LicenseID)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+	if err := supportbundle.Share(app, bundle); err != nil {
 		logger.Error(err)
-		JSON(w, http.StatusInternalServerError, nil)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			logger.Errorf("Failed to share support bundle: %d: %s", resp.StatusCode, string(body))
-		} else {
-			logger.Errorf("Failed to share support bundle: %d", resp.StatusCode)
+		switch errors.Cause(err) {
+		case supportbundle.ErrShareAirgap:
+			JSON(w, http.StatusBadRequest, nil)
+		case supportbundle.ErrShareNotSupported:
+			JSON(w, http.StatusForbidden, nil)
+		default:
+			shareErr := &supportbundle.ShareError{}
+			if errors.As(err, &shareErr) {
+				JSON(w, http.StatusInternalServerError, shareErr.Body)
+				return
+			}
+			JSON(w, http.StatusInternalServerError, nil)
 		}
-		JSON(w, http.StatusInternalServerError, string(body))
-		return
-	}
-
-	now := time.Now()
-	bundle.SharedAt = &now
-	if err := store.GetStore().UpdateSupportBundle(bundle); err != nil {
-		logger.Error(errors.Wrap(err, "failed to update support bundle"))
-		JSON(w, http.StatusInternalServerError, nil)
 		return
 	}
 
@@ -655,3 +588,94 @@ func (h *Handler) SetSupportBundleRedactions(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusCreated)
 	return
 }
+
+type CompareSupportBundlesResponse struct {
+	Success    bool                           `json:"success"`
+	Error      string                         `json:"error,omitempty"`
+	Comparison *types.SupportBundleComparison `json:"comparison,omitempty"`
+}
+
+// CompareSupportBundles returns what changed between two support bundles of an app, e.g. a bundle collected
+// before an upgrade and one collected after it.
+func (h *Handler) CompareSupportBundles(w http.ResponseWriter, r *http.Request) {
+	compareSupportBundlesResponse := CompareSupportBundlesResponse{}
+
+	baseID := r.URL.Query().Get("base")
+	targetID := r.URL.Query().Get("target")
+	if baseID == "" || targetID == "" {
+		compareSupportBundlesResponse.Error = "base and target support bundle ids are required"
+		JSON(w, http.StatusBadRequest, compareSupportBundlesResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		compareSupportBundlesResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, compareSupportBundlesResponse.Error))
+		JSON(w, http.StatusInternalServerError, compareSupportBundlesResponse)
+		return
+	}
+
+	inputs := []supportbundle.CompareInput{}
+	for _, bundleID := range []string{baseID, targetID} {
+		bundle, err := store.GetStore().GetSupportBundle(bundleID)
+		if err != nil {
+			if store.GetStore().IsNotFound(err) {
+				compareSupportBundlesResponse.Error = fmt.Sprintf("support bundle %s not found", bundleID)
+				JSON(w, http.StatusNotFound, compareSupportBundlesResponse)
+				return
+			}
+			compareSupportBundlesResponse.Error = "failed to get support bundle"
+			logger.Error(errors.Wrap(err, compareSupportBundlesResponse.Error))
+			JSON(w, http.StatusInternalServerError, compareSupportBundlesResponse)
+			return
+		}
+		if bundle.AppID != foundApp.ID {
+			compareSupportBundlesResponse.Error = fmt.Sprintf("support bundle %s not found", bundleID)
+			JSON(w, http.StatusNotFound, compareSupportBundlesResponse)
+			return
+		}
+		if bundle.Status == types.BUNDLE_RUNNING {
+			compareSupportBundlesResponse.Error = fmt.Sprintf("support bundle %s has not finished collecting", bundleID)
+			JSON(w, http.StatusBadRequest, compareSupportBundlesResponse)
+			return
+		}
+
+		bundleArchive, err := store.GetStore().GetSupportBundleArchive(bundle.ID)
+		if err != nil {
+			compareSupportBundlesResponse.Error = "failed to get support bundle archive"
+			logger.Error(errors.Wrap(err, compareSupportBundlesResponse.Error))
+			JSON(w, http.StatusInternalServerError, compareSupportBundlesResponse)
+			return
+		}
+		defer os.RemoveAll(bundleArchive)
+
+		input := supportbundle.CompareInput{
+			ID:          bundle.ID,
+			ArchivePath: bundleArchive,
+		}
+
+		// the stored analysis is used when there is one, otherwise the analysis in the archive is
+		analysis, err := store.GetStore().GetSupportBundleAnalysis(bundle.ID)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get analysis for bundle %s", bundle.ID))
+		} else if analysis != nil {
+			input.Insights = analysis.Insights
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	comparison, err := supportbundle.Compare(inputs[0], inputs[1])
+	if err != nil {
+		compareSupportBundlesResponse.Error = "failed to compare support bundles"
+		logger.Error(errors.Wrap(err, compareSupportBundlesResponse.Error))
+		JSON(w, http.StatusInternalServerError, compareSupportBundlesResponse)
+		return
+	}
+
+	compareSupportBundlesResponse.Success = true
+	compareSupportBundlesResponse.Comparison = comparison
+
+	JSON(w, http.StatusOK, compareSupportBundlesResponse)
+}
@@ -51,6 +51,7 @@ func (h *Handler) AppUpdateCheck(w http.ResponseWriter, r *http.Request) {
 	deployVersionLabel := r.URL.Query().Get("deployVersionLabel")
 	skipPreflights, _ := strconv.ParseBool(r.URL.Query().Get("skipPreflights"))
 	skipCompatibilityCheck, _ := strconv.ParseBool(r.URL.Query().Get("skipCompatibilityCheck"))
+	skipIntegrityCheck, _ := strconv.ParseBool(r.URL.Query().Get("skipIntegrityCheck"))
 	isCLI, _ := strconv.ParseBool(r.URL.Query().Get("isCLI"))
 	wait, _ := strconv.ParseBool(r.URL.Query().Get("wait"))
 
@@ -203,7 +204,7 @@ func (h *Handler) AppUpdateCheck(w http.ResponseWriter, r *http.Request) {
 
 		tasks.StartUpdateTaskMonitor("update-download", finishedChan)
 
-		err = airgap.UpdateAppFromPath(kotsApp, rootDir, "", deploy, skipPreflights, skipCompatibilityCheck)
+		err = airgap.UpdateAppFromPath(kotsApp, rootDir, "", deploy, skipPreflights, skipCompatibilityCheck, skipIntegrityCheck)
 		if err != nil {
 			finishedChan <- err
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -26,9 +27,19 @@ type SetAutomaticUpdatesConfigResponse struct {
 }
 
 type GetAutomaticUpdatesConfigResponse struct {
-	UpdateCheckerSpec string              `json:"updateCheckerSpec"`
-	AutoDeploy        apptypes.AutoDeploy `json:"autoDeploy"`
-	Error             string              `json:"error"`
+	UpdateCheckerSpec string                      `json:"updateCheckerSpec"`
+	AutoDeploy        apptypes.AutoDeploy         `json:"autoDeploy"`
+	AutoDeployWindow  *apptypes.MaintenanceWindow `json:"autoDeployWindow,omitempty"`
+	Error             string                      `json:"error"`
+}
+
+type SetAutoDeployWindowRequest struct {
+	// AutoDeployWindow is the maintenance window for automatic deploys. A nil window removes it.
+	AutoDeployWindow *apptypes.MaintenanceWindow `json:"autoDeployWindow"`
+}
+
+type SetAutoDeployWindowResponse struct {
+	Error string `json:"error"`
 }
 
 func (h *Handler) SetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request) {
@@ -192,7 +203,68 @@ func (h *Handler) GetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Reque
 		}
 		getCheckerSpecResponse.UpdateCheckerSpec = foundApp.UpdateCheckerSpec
 		getCheckerSpecResponse.AutoDeploy = foundApp.AutoDeploy
+		getCheckerSpecResponse.AutoDeployWindow = foundApp.AutoDeployWindow
 	}
 
 	JSON(w, http.StatusOK, getCheckerSpecResponse)
 }
+
+func (h *Handler) SetAutoDeployWindow(w http.ResponseWriter, r *http.Request) {
+	setAutoDeployWindowResponse := &SetAutoDeployWindowResponse{}
+
+	if util.IsHelmManaged() {
+		setAutoDeployWindowResponse.Error = "auto deploy windows are not supported for helm managed apps"
+		JSON(w, http.StatusBadRequest, setAutoDeployWindowResponse)
+		return
+	}
+
+	setAutoDeployWindowRequest := SetAutoDeployWindowRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&setAutoDeployWindowRequest); err != nil {
+		setAutoDeployWindowResponse.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, setAutoDeployWindowResponse.Error))
+		JSON(w, http.StatusBadRequest, setAutoDeployWindowResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		setAutoDeployWindowResponse.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, setAutoDeployWindowResponse.Error))
+		JSON(w, http.StatusInternalServerError, setAutoDeployWindowResponse)
+		return
+	}
+
+	if foundApp.IsAirgap {
+		setAutoDeployWindowResponse.Error = "airgap scheduled update checks are not supported"
+		logger.Error(errors.New(setAutoDeployWindowResponse.Error))
+		JSON(w, http.StatusBadRequest, setAutoDeployWindowResponse)
+		return
+	}
+
+	window := setAutoDeployWindowRequest.AutoDeployWindow
+	if window != nil {
+		if err := window.Validate(); err != nil {
+			setAutoDeployWindowResponse.Error = fmt.Sprintf("invalid auto deploy window: %v", err)
+			logger.Error(errors.Wrap(err, "invalid auto deploy window"))
+			JSON(w, http.StatusBadRequest, setAutoDeployWindowResponse)
+			return
+		}
+	}
+
+	if err := store.GetStore().SetAutoDeployWindow(foundApp.ID, window); err != nil {
+		setAutoDeployWindowResponse.Error = "failed to set auto deploy window"
+		logger.Error(errors.Wrap(err, setAutoDeployWindowResponse.Error))
+		JSON(w, http.StatusInternalServerError, setAutoDeployWindowResponse)
+		return
+	}
+
+	// reconfigure update checker for the app so queued versions are deployed at the window start
+	if err := updatechecker.Configure(foundApp, foundApp.UpdateCheckerSpec); err != nil {
+		setAutoDeployWindowResponse.Error = "failed to reconfigure update checker cron job"
+		logger.Error(errors.Wrap(err, setAutoDeployWindowResponse.Error))
+		JSON(w, http.StatusInternalServerError, setAutoDeployWindowResponse)
+		return
+	}
+
+	JSON(w, http.StatusNoContent, "")
+}
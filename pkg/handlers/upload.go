@@ -15,6 +15,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/store"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
@@ -203,7 +204,7 @@ func (h *Handler) UploadExistingApp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !uploadExistingAppRequest.SkipPreflights || hasStrictPreflights {
-		if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, preflighttypes.PreflightTriggerUpdate, archiveDir); err != nil {
 			uploadResponse.Error = util.StrPointer("failed to get run preflights")
 			logger.Error(errors.Wrap(err, *uploadResponse.Error))
 			JSON(w, http.StatusInternalServerError, uploadResponse)
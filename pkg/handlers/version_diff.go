@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/apparchive"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+type GetAppVersionDiffResponse struct {
+	FromSequence int64 `json:"fromSequence"`
+	ToSequence   int64 `json:"toSequence"`
+	apparchive.VersionDiff
+}
+
+type GetAppVersionDiffErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// GetAppVersionDiff returns the unified diff of the rendered manifests between two app versions,
+// per file and per kubernetes object
+func (h *Handler) GetAppVersionDiff(w http.ResponseWriter, r *http.Request) {
+	appSlug := mux.Vars(r)["appSlug"]
+
+	fromSequence, err := strconv.ParseInt(mux.Vars(r)["fromSequence"], 10, 64)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse from sequence number"))
+		JSON(w, http.StatusBadRequest, GetAppVersionDiffErrorResponse{Error: "invalid from sequence"})
+		return
+	}
+
+	toSequence, err := strconv.ParseInt(mux.Vars(r)["toSequence"], 10, 64)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse to sequence number"))
+		JSON(w, http.StatusBadRequest, GetAppVersionDiffErrorResponse{Error: "invalid to sequence"})
+		return
+	}
+
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, sequence := range []int64{fromSequence, toSequence} {
+		status, err := store.GetStore().GetDownstreamVersionStatus(a.ID, sequence)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get downstream version status for sequence %d", sequence))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if status == storetypes.VersionPendingDownload {
+			errMsg := errors.Errorf("cannot diff version %d because it's %s", sequence, status)
+			logger.Error(errMsg)
+			JSON(w, http.StatusBadRequest, GetAppVersionDiffErrorResponse{Error: errMsg.Error()})
+			return
+		}
+	}
+
+	downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+	if err != nil {
+		logger.Error(errors.Wrapf(err, "failed to list downstreams for app %q", a.Slug))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(downstreams) == 0 {
+		logger.Error(errors.Errorf("no downstreams found for app %q", a.Slug))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	d := downstreams[0]
+
+	fromArchiveDir, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to create from temp dir"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(fromArchiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, fromSequence, fromArchiveDir); err != nil {
+		logger.Error(errors.Wrap(err, "failed to get from app version archive"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	toArchiveDir, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to create to temp dir"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(toArchiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, toSequence, toArchiveDir); err != nil {
+		logger.Error(errors.Wrap(err, "failed to get to app version archive"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	toKotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(toArchiveDir, "upstream"))
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to load kots kinds from path"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	diff, err := apparchive.DiffRenderedAppVersions(d.Name, toArchiveDir, fromArchiveDir, toKotsKinds.GetKustomizeBinaryPath())
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to diff app versions"))
+		JSON(w, http.StatusInternalServerError, GetAppVersionDiffErrorResponse{Error: "failed to diff app versions"})
+		return
+	}
+
+	JSON(w, http.StatusOK, GetAppVersionDiffResponse{
+		FromSequence: fromSequence,
+		ToSequence:   toSequence,
+		VersionDiff:  *diff,
+	})
+}
@@ -120,6 +120,8 @@ func SetConfig(ctx context.Context, namespace string, identityConfig kotsv1beta1
 		return errors.Wrap(err, "failed to ensure config map")
 	}
 
+	ResetRolesCache()
+
 	return nil
 }
 
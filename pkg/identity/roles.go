@@ -0,0 +1,49 @@
+package identity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/rbac"
+	rbactypes "github.com/replicatedhq/kots/pkg/rbac/types"
+)
+
+const rolesCacheTTL = 10 * time.Second
+
+var (
+	rolesCacheMtx       sync.Mutex
+	rolesCache          []rbactypes.Role
+	rolesCacheNamespace string
+	rolesCacheExpiresAt time.Time
+)
+
+// GetRoles returns the default roles and the custom roles defined in the identity config.
+// Roles are checked on every request, so the result is cached for a few seconds.
+func GetRoles(ctx context.Context, namespace string) ([]rbactypes.Role, error) {
+	rolesCacheMtx.Lock()
+	defer rolesCacheMtx.Unlock()
+
+	if rolesCache != nil && rolesCacheNamespace == namespace && time.Now().Before(rolesCacheExpiresAt) {
+		return rolesCache, nil
+	}
+
+	identityConfig, err := GetConfig(ctx, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get identity config")
+	}
+
+	rolesCache = rbac.RolesFromIdentityConfig(identityConfig.Spec)
+	rolesCacheNamespace = namespace
+	rolesCacheExpiresAt = time.Now().Add(rolesCacheTTL)
+
+	return rolesCache, nil
+}
+
+// ResetRolesCache makes the next call to GetRoles read the identity config, e.g. after the config has been updated
+func ResetRolesCache() {
+	rolesCacheMtx.Lock()
+	defer rolesCacheMtx.Unlock()
+	rolesCache = nil
+}
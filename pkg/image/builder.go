@@ -421,6 +421,8 @@ func CopyImage(opts types.CopyImageOptions) error {
 		}
 	}
 
+	destCtx.BlobInfoCacheDir = opts.BlobInfoCacheDir
+
 	username, password := opts.DestAuth.Username, opts.DestAuth.Password
 	registryHost := reference.Domain(opts.DestRef.DockerReference())
 
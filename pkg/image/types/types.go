@@ -23,4 +23,6 @@ type CopyImageOptions struct {
 	SkipSrcTLSVerify  bool
 	SkipDestTLSVerify bool
 	ReportWriter      io.Writer
+	// BlobInfoCacheDir is the directory of the cache of known blob locations used when pushing, the default cache is used if empty
+	BlobInfoCacheDir string
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	kotsscheme "github.com/replicatedhq/kots/kotskinds/client/kotsclientset/scheme"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
 	"github.com/replicatedhq/kots/pkg/docker/registry"
 	registrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	"github.com/replicatedhq/kots/pkg/identity"
@@ -22,6 +23,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsadm/types"
 	"github.com/replicatedhq/kots/pkg/kurl"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/policy"
 	"github.com/replicatedhq/kots/pkg/util"
 	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
@@ -242,6 +244,15 @@ func Deploy(deployOptions types.DeployOptions, log *logger.CLILogger) error {
 		if err := ensureConfigFromFile(deployOptions, clientset, "kotsadm-airgap-images", filepath.Join(airgapPath, "images.json")); err != nil {
 			return errors.Wrap(err, "failed to create config from images.json")
 		}
+		// the checksum manifest lets kotsadm verify the airgap.yaml and app.tar.gz it receives from the cli
+		manifestFile := filepath.Join(airgapPath, integrity.ManifestFileName)
+		if _, err := os.Stat(manifestFile); err == nil {
+			if err := ensureConfigFromFile(deployOptions, clientset, "kotsadm-airgap-checksums", manifestFile); err != nil {
+				return errors.Wrapf(err, "failed to create config from %s", integrity.ManifestFileName)
+			}
+		} else if !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to stat %s", integrity.ManifestFileName)
+		}
 		if err := ensureWaitForAirgapConfig(deployOptions, clientset, "kotsadm-airgap-app"); err != nil {
 			return errors.Wrap(err, "failed to create config from app.tar.gz")
 		}
@@ -483,6 +494,9 @@ func ensureKotsadm(deployOptions types.DeployOptions, clientset *kubernetes.Clie
 		if err := identity.ValidateConfig(context.TODO(), deployOptions.Namespace, identityConfig, ingressConfig); err != nil {
 			return errors.Wrap(err, "failed to validate identity config")
 		}
+		if err := policy.ValidateRoles(identityConfig.Spec); err != nil {
+			return errors.Wrap(err, "failed to validate identity config roles")
+		}
 	}
 
 	// check additional namespaces early in case there are rbac issues we don't
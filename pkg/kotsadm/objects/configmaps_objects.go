@@ -18,6 +18,7 @@ func KotsadmConfigMap(deployOptions types.DeployOptions) *corev1.ConfigMap {
 		"registry-is-read-only":     fmt.Sprintf("%v", deployOptions.DisableImagePush),
 		"minio-enabled-snapshots":   fmt.Sprintf("%v", deployOptions.IncludeMinioSnapshots),
 		"skip-compatibility-check":  fmt.Sprintf("%v", deployOptions.SkipCompatibilityCheck),
+		"skip-integrity-check":      fmt.Sprintf("%v", deployOptions.SkipIntegrityCheck),
 		"ensure-rbac":               fmt.Sprintf("%v", deployOptions.EnsureRBAC),
 		"skip-rbac-check":           fmt.Sprintf("%v", deployOptions.SkipRBACCheck),
 		"use-minimal-rbac":          fmt.Sprintf("%v", deployOptions.UseMinimalRBAC),
@@ -16,12 +16,19 @@ import (
 	"time"
 
 	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	dockerref "github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/pkg/blobinfocache/boltdb"
+	"github.com/containers/image/v5/pkg/compression"
 	"github.com/containers/image/v5/transports/alltransports"
 	containerstypes "github.com/containers/image/v5/types"
 	"github.com/mholt/archiver/v3"
+	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	dockerarchive "github.com/replicatedhq/kots/pkg/docker/archive"
 	dockerregistry "github.com/replicatedhq/kots/pkg/docker/registry"
+	registrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	dockertypes "github.com/replicatedhq/kots/pkg/docker/types"
 	"github.com/replicatedhq/kots/pkg/image"
 	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
@@ -246,7 +253,7 @@ func TagAndPushAppImagesFromPath(airgapRootDir string, options types.PushImagesO
 
 	switch airgap.Spec.Format {
 	case dockertypes.FormatDockerRegistry:
-		return PushAppImagesFromTempRegistry(airgapRootDir, airgap.Spec.SavedImages, options)
+		return pushAirgapImagesFromTempRegistry(airgapRootDir, airgap, options)
 	case dockertypes.FormatDockerArchive, "":
 		return PushAppImagesFromDockerArchivePath(airgapRootDir, options)
 	default:
@@ -276,7 +283,7 @@ func TagAndPushAppImagesFromBundle(airgapBundle string, options types.PushImages
 		if err := tarGz.Unarchive(airgapBundle, extractedBundle); err != nil {
 			return nil, errors.Wrap(err, "falied to unarchive airgap bundle")
 		}
-		return PushAppImagesFromTempRegistry(extractedBundle, airgap.Spec.SavedImages, options)
+		return pushAirgapImagesFromTempRegistry(extractedBundle, airgap, options)
 	case dockertypes.FormatDockerArchive, "":
 		return PushAppImagesFromDockerArchiveBundle(airgapBundle, options)
 	default:
@@ -284,6 +291,55 @@ func TagAndPushAppImagesFromBundle(airgapBundle string, options types.PushImages
 	}
 }
 
+// pushAirgapImagesFromTempRegistry pushes the saved images of the airgap bundle.
+// Images omitted from a delta bundle are not pushed since they are already in the registry from the base version,
+// but they are still returned so that they are rewritten in the app.
+func pushAirgapImagesFromTempRegistry(airgapRootDir string, airgap *kotsv1beta1.Airgap, options types.PushImagesOptions) ([]kustomizetypes.Image, error) {
+	if airgap.Spec.Delta == nil {
+		return PushAppImagesFromTempRegistry(airgapRootDir, airgap.Spec.SavedImages, options)
+	}
+
+	omittedImages := map[string]bool{}
+	for _, omittedImage := range airgap.Spec.Delta.OmittedImages {
+		omittedImages[omittedImage] = true
+	}
+
+	imagesToPush := []string{}
+	for _, savedImage := range airgap.Spec.SavedImages {
+		if !omittedImages[savedImage] {
+			imagesToPush = append(imagesToPush, savedImage)
+		}
+	}
+
+	if len(airgap.Spec.Delta.SharedLayers) > 0 {
+		cacheDir, err := ioutil.TempDir("", "kots-blob-info-cache")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create temp dir")
+		}
+		defer os.RemoveAll(cacheDir)
+
+		if err := recordSharedLayerLocations(cacheDir, options.Registry, airgap.Spec.Delta.SharedLayers); err != nil {
+			return nil, errors.Wrap(err, "failed to record shared layer locations")
+		}
+		options.BlobInfoCacheDir = cacheDir
+	}
+
+	rewrittenImages, err := PushAppImagesFromTempRegistry(airgapRootDir, imagesToPush, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, omittedImage := range airgap.Spec.Delta.OmittedImages {
+		rewrittenImage, err := image.RewriteDockerRegistryImage(options.Registry, omittedImage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to rewrite image %s", omittedImage)
+		}
+		rewrittenImages = append(rewrittenImages, *rewrittenImage)
+	}
+
+	return rewrittenImages, nil
+}
+
 func PushAppImagesFromTempRegistry(airgapRootDir string, imageList []string, options types.PushImagesOptions) ([]kustomizetypes.Image, error) {
 	tempRegistry := &dockerregistry.TempRegistry{}
 	if err := tempRegistry.Start(filepath.Join(airgapRootDir, "images")); err != nil {
@@ -360,6 +416,7 @@ func PushAppImagesFromTempRegistry(airgapRootDir string, imageList []string, opt
 				SkipSrcTLSVerify:  true,
 				SkipDestTLSVerify: true,
 				ReportWriter:      reportWriter,
+				BlobInfoCacheDir:  options.BlobInfoCacheDir,
 			},
 		}
 		if err := pushAppImage(pushAppImageOpts); err != nil {
@@ -971,3 +1028,53 @@ func isAppArchive(rootDir string) bool {
 
 	return false
 }
+
+// blobInfoCacheFilename is the file name of the blob info cache in BlobInfoCacheDir
+const blobInfoCacheFilename = "blob-info-cache-v1.boltdb"
+
+// recordSharedLayerLocations records the repositories of the omitted images that have the layers shared with the pushed images
+// in a blob info cache, so that the layers are mounted from those repositories instead of being pushed
+func recordSharedLayerLocations(cacheDir string, registry registrytypes.RegistryOptions, sharedLayers []kotsv1beta1.AirgapDeltaLayer) error {
+	cache := boltdb.New(filepath.Join(cacheDir, blobInfoCacheFilename))
+	// layers are only mounted from locations with a known compression
+	compressorRecorder, ok := cache.(interface {
+		RecordDigestCompressorName(anyDigest digest.Digest, compressorName string)
+	})
+	if !ok {
+		return errors.New("blob info cache does not record compression")
+	}
+
+	for _, layer := range sharedLayers {
+		layerDigest, err := digest.Parse(layer.Digest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse digest of layer %s", layer.Digest)
+		}
+
+		destImage, err := image.DestImage(registry, layer.Image)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get destination image for %s", layer.Image)
+		}
+		destRef, err := dockerref.ParseNormalizedNamed(destImage)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse dest image %s", destImage)
+		}
+
+		scope := containerstypes.BICTransportScope{Opaque: dockerref.Domain(destRef)}
+		location := containerstypes.BICLocationReference{Opaque: destRef.Name()}
+		cache.RecordKnownLocation(docker.Transport, scope, layerDigest, location)
+		compressorRecorder.RecordDigestCompressorName(layerDigest, layerCompressorName(layer.MediaType))
+	}
+
+	return nil
+}
+
+func layerCompressorName(mediaType string) string {
+	switch {
+	case strings.HasSuffix(mediaType, "zstd"):
+		return compression.Zstd.Name()
+	case strings.HasSuffix(mediaType, ".tar"):
+		return "uncompressed"
+	default:
+		return compression.Gzip.Name()
+	}
+}
@@ -0,0 +1,84 @@
+package kotsadm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/pkg/blobinfocache/boltdb"
+	containerstypes "github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	registrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_recordSharedLayerLocations(t *testing.T) {
+	baseLayer := digest.FromString("base layer")
+	zstdLayer := digest.FromString("zstd layer")
+
+	tests := []struct {
+		name          string
+		registry      registrytypes.RegistryOptions
+		sharedLayers  []kotsv1beta1.AirgapDeltaLayer
+		layer         digest.Digest
+		wantScope     string
+		wantLocations []string
+	}{
+		{
+			name:     "namespaced registry",
+			registry: registrytypes.RegistryOptions{Endpoint: "registry.example.com:5000", Namespace: "app"},
+			sharedLayers: []kotsv1beta1.AirgapDeltaLayer{
+				{Digest: baseLayer.String(), MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Image: "registry.replicated.com/app/web:1.0"},
+			},
+			layer:         baseLayer,
+			wantScope:     "registry.example.com:5000",
+			wantLocations: []string{"registry.example.com:5000/app/web"},
+		},
+		{
+			name:     "registry without namespace",
+			registry: registrytypes.RegistryOptions{Endpoint: "registry.example.com"},
+			sharedLayers: []kotsv1beta1.AirgapDeltaLayer{
+				{Digest: baseLayer.String(), MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Image: "nginx:1.25"},
+				{Digest: zstdLayer.String(), MediaType: "application/vnd.oci.image.layer.v1.tar+zstd", Image: "registry.replicated.com/app/worker:1.0"},
+			},
+			layer:         zstdLayer,
+			wantScope:     "registry.example.com",
+			wantLocations: []string{"registry.example.com/worker"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			err := recordSharedLayerLocations(cacheDir, test.registry, test.sharedLayers)
+			require.NoError(t, err)
+
+			cache := boltdb.New(filepath.Join(cacheDir, blobInfoCacheFilename))
+			candidates := cache.CandidateLocations(docker.Transport, containerstypes.BICTransportScope{Opaque: test.wantScope}, test.layer, false)
+			locations := []string{}
+			for _, candidate := range candidates {
+				locations = append(locations, candidate.Location.Opaque)
+			}
+			assert.Equal(t, test.wantLocations, locations)
+		})
+	}
+}
+
+func Test_layerCompressorName(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      string
+	}{
+		{mediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", want: "gzip"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar+gzip", want: "gzip"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar+zstd", want: "zstd"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar", want: "uncompressed"},
+		{mediaType: "", want: "gzip"},
+	}
+	for _, test := range tests {
+		t.Run(test.mediaType, func(t *testing.T) {
+			assert.Equal(t, test.want, layerCompressorName(test.mediaType))
+		})
+	}
+}
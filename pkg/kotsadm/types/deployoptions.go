@@ -45,6 +45,7 @@ type DeployOptions struct {
 	EnsureKotsadmConfig    bool
 	SkipPreflights         bool
 	SkipCompatibilityCheck bool
+	SkipIntegrityCheck     bool
 	EnsureRBAC             bool
 	SkipRBACCheck          bool
 	UseMinimalRBAC         bool
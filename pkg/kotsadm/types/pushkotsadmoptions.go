@@ -15,6 +15,8 @@ type PushImagesOptions struct {
 	Log            *logger.CLILogger
 	ProgressWriter io.Writer
 	LogForUI       bool
+	// BlobInfoCacheDir is the directory of the cache of known blob locations used when pushing app images
+	BlobInfoCacheDir string
 }
 
 type PushAppImageOptions struct {
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+const (
+	certificateError        = "Value must be a PEM encoded certificate"
+	certificateKeyPairError = "Certificate does not match the private key"
+)
+
+type certificateValidator struct {
+	*kotsv1beta1.CertificateValidator
+	configItems map[string]kotsv1beta1.ConfigItem
+}
+
+func (v *certificateValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	if !isPEMCertificate([]byte(input)) {
+		return newValidationError(v.Message, certificateError), nil
+	}
+
+	if v.KeyItem == "" {
+		return nil, nil
+	}
+
+	keyItem, ok := v.configItems[v.KeyItem]
+	if !ok {
+		return nil, errors.Errorf("key item %s not found", v.KeyItem)
+	}
+
+	key, err := getValidatableItemValue(keyItem.Value, keyItem.Type)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get value of key item %s", v.KeyItem)
+	}
+	if key == "" {
+		// the key item validates its own value when it is required
+		return nil, nil
+	}
+
+	if _, err := tls.X509KeyPair([]byte(input), []byte(key)); err != nil {
+		return newValidationError(v.Message, certificateKeyPairError), nil
+	}
+	return nil, nil
+}
+
+// isPEMCertificate returns true if data contains at least one PEM block and every block is a
+// parsable certificate.
+func isPEMCertificate(data []byte) bool {
+	found := false
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return false
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return false
+		}
+		found = true
+	}
+	return found
+}
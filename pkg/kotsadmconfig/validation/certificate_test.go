@@ -0,0 +1,183 @@
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/kotskinds/multitype"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+func Test_certificateValidator_Validate(t *testing.T) {
+	cert, key := generateTestCertificate(t)
+	_, otherKey := generateTestCertificate(t)
+
+	fileItem := func(name string, value string) kotsv1beta1.ConfigItem {
+		return kotsv1beta1.ConfigItem{
+			Name:  name,
+			Type:  "file",
+			Value: multitype.BoolOrString{Type: multitype.String, StrVal: base64.StdEncoding.EncodeToString([]byte(value))},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		validator   *kotsv1beta1.CertificateValidator
+		configItems map[string]kotsv1beta1.ConfigItem
+		input       string
+		want        *configtypes.ValidationError
+		wantErr     bool
+	}{
+		{
+			name:      "valid certificate",
+			validator: &kotsv1beta1.CertificateValidator{},
+			input:     cert,
+			want:      nil,
+		}, {
+			name:      "not a certificate",
+			validator: &kotsv1beta1.CertificateValidator{},
+			input:     "not a certificate",
+			want:      &configtypes.ValidationError{Message: certificateError},
+		}, {
+			name:      "private key instead of certificate",
+			validator: &kotsv1beta1.CertificateValidator{Message: "must be a certificate"},
+			input:     key,
+			want:      &configtypes.ValidationError{Message: "must be a certificate"},
+		}, {
+			name:        "matching key",
+			validator:   &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"},
+			configItems: map[string]kotsv1beta1.ConfigItem{"tls_key": fileItem("tls_key", key)},
+			input:       cert,
+			want:        nil,
+		}, {
+			name:        "mismatched key",
+			validator:   &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"},
+			configItems: map[string]kotsv1beta1.ConfigItem{"tls_key": fileItem("tls_key", otherKey)},
+			input:       cert,
+			want:        &configtypes.ValidationError{Message: certificateKeyPairError},
+		}, {
+			name:        "key not set",
+			validator:   &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"},
+			configItems: map[string]kotsv1beta1.ConfigItem{"tls_key": {Name: "tls_key", Type: "file"}},
+			input:       cert,
+			want:        nil,
+		}, {
+			name:      "key item does not exist",
+			validator: &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"},
+			input:     cert,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &certificateValidator{
+				CertificateValidator: tt.validator,
+				configItems:          tt.configItems,
+			}
+			got, err := v.Validate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("certificateValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("certificateValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigSpec_certificateKeyPair(t *testing.T) {
+	cert, _ := generateTestCertificate(t)
+	_, otherKey := generateTestCertificate(t)
+
+	configSpec := kotsv1beta1.ConfigSpec{
+		Groups: []kotsv1beta1.ConfigGroup{
+			{
+				Name:  "tls",
+				Title: "TLS",
+				Items: []kotsv1beta1.ConfigItem{
+					{
+						Name:  "tls_cert",
+						Type:  "file",
+						Value: multitype.BoolOrString{Type: multitype.String, StrVal: base64.StdEncoding.EncodeToString([]byte(cert))},
+						Validation: &kotsv1beta1.ConfigItemValidation{
+							Certificate: &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"},
+						},
+					},
+				},
+			},
+			{
+				// the key can be in a different group than the certificate
+				Name:  "advanced",
+				Title: "Advanced",
+				Items: []kotsv1beta1.ConfigItem{
+					{
+						Name:  "tls_key",
+						Type:  "file",
+						Value: multitype.BoolOrString{Type: multitype.String, StrVal: base64.StdEncoding.EncodeToString([]byte(otherKey))},
+					},
+				},
+			},
+		},
+	}
+
+	want := []configtypes.ConfigGroupValidationError{
+		{
+			Name:  "tls",
+			Title: "TLS",
+			ItemErrors: []configtypes.ConfigItemValidationError{
+				{
+					Name:             "tls_cert",
+					Type:             "file",
+					ValidationErrors: []configtypes.ValidationError{{Message: certificateKeyPairError}},
+				},
+			},
+		},
+	}
+
+	got, err := ValidateConfigSpec(configSpec)
+	if err != nil {
+		t.Fatalf("ValidateConfigSpec() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateConfigSpec() = %v, want %v", got, want)
+	}
+}
+
+// generateTestCertificate returns a PEM encoded self-signed certificate and its private key.
+func generateTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kotsadm.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
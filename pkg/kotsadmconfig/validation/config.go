@@ -5,13 +5,22 @@ import (
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	"github.com/replicatedhq/kots/kotskinds/multitype"
 	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+	"github.com/replicatedhq/kots/pkg/secretref"
 	"github.com/replicatedhq/kots/pkg/util"
 )
 
 func ValidateConfigSpec(configSpec kotsv1beta1.ConfigSpec) ([]configtypes.ConfigGroupValidationError, error) {
+	// items are looked up by name for validators that compare against other items
+	configItems := map[string]kotsv1beta1.ConfigItem{}
+	for _, configGroup := range configSpec.Groups {
+		for _, item := range configGroup.Items {
+			configItems[item.Name] = item
+		}
+	}
+
 	var configGroupErrors []configtypes.ConfigGroupValidationError
 	for _, configGroup := range configSpec.Groups {
-		configGroupError, err := validateConfigGroup(configGroup)
+		configGroupError, err := validateConfigGroup(configGroup, configItems)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to validate config group %s", configGroup.Name)
 		}
@@ -22,12 +31,12 @@ func ValidateConfigSpec(configSpec kotsv1beta1.ConfigSpec) ([]configtypes.Config
 	return configGroupErrors, nil
 }
 
-func validateConfigGroup(configGroup kotsv1beta1.ConfigGroup) (*configtypes.ConfigGroupValidationError, error) {
+func validateConfigGroup(configGroup kotsv1beta1.ConfigGroup, configItems map[string]kotsv1beta1.ConfigItem) (*configtypes.ConfigGroupValidationError, error) {
 	if !isValidatableConfigGroup(configGroup) {
 		return nil, nil
 	}
 
-	configItemErrors, err := validateConfigItems(configGroup.Items)
+	configItemErrors, err := validateConfigItems(configGroup.Items, configItems)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to validate config items")
 	}
@@ -42,10 +51,10 @@ func validateConfigGroup(configGroup kotsv1beta1.ConfigGroup) (*configtypes.Conf
 	}, nil
 }
 
-func validateConfigItems(configItems []kotsv1beta1.ConfigItem) ([]configtypes.ConfigItemValidationError, error) {
+func validateConfigItems(items []kotsv1beta1.ConfigItem, configItems map[string]kotsv1beta1.ConfigItem) ([]configtypes.ConfigItemValidationError, error) {
 	var configItemErrors []configtypes.ConfigItemValidationError
-	for _, item := range configItems {
-		configItemErr, err := validateConfigItem(item)
+	for _, item := range items {
+		configItemErr, err := validateConfigItem(item, configItems)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to validate config item %s", item.Name)
 		}
@@ -58,7 +67,7 @@ func validateConfigItems(configItems []kotsv1beta1.ConfigItem) ([]configtypes.Co
 	return configItemErrors, nil
 }
 
-func validateConfigItem(item kotsv1beta1.ConfigItem) (*configtypes.ConfigItemValidationError, error) {
+func validateConfigItem(item kotsv1beta1.ConfigItem, configItems map[string]kotsv1beta1.ConfigItem) (*configtypes.ConfigItemValidationError, error) {
 	if !isValidatableConfigItem(item) {
 		return nil, nil
 	}
@@ -72,7 +81,19 @@ func validateConfigItem(item kotsv1beta1.ConfigItem) (*configtypes.ConfigItemVal
 		return nil, nil
 	}
 
-	validationErrors, err := validate(validatableValue, *item.Validation)
+	// references to external secrets are resolved when rendering, so only the reference itself can be validated
+	if secretref.IsRef(validatableValue) {
+		if _, err := secretref.Parse(validatableValue); err != nil {
+			return &configtypes.ConfigItemValidationError{
+				Name:             item.Name,
+				Type:             item.Type,
+				ValidationErrors: []configtypes.ValidationError{{Message: err.Error()}},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	validationErrors, err := validate(validatableValue, *item.Validation, configItems)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to validate value")
 	}
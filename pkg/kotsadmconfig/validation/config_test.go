@@ -127,6 +127,28 @@ var (
 			},
 		},
 	}
+	secretRefConfigItem = kotsv1beta1.ConfigItem{
+		Name:  "secretRefConfigItem",
+		Type:  "text",
+		Value: multitype.BoolOrString{StrVal: "ref+vault://secret/data/my-app#password"},
+		Validation: &kotsv1beta1.ConfigItemValidation{
+			Regex: &kotsv1beta1.RegexValidator{
+				Pattern: "^[a-z]+$",
+				Message: "must be a valid regex",
+			},
+		},
+	}
+	invalidSecretRefConfigItem = kotsv1beta1.ConfigItem{
+		Name:  "invalidSecretRefConfigItem",
+		Type:  "text",
+		Value: multitype.BoolOrString{StrVal: "ref+vault://secret/data/my-app"},
+		Validation: &kotsv1beta1.ConfigItemValidation{
+			Regex: &kotsv1beta1.RegexValidator{
+				Pattern: "^[a-z]+$",
+				Message: "must be a valid regex",
+			},
+		},
+	}
 	requiredFalseValueEmptyConfigItem = kotsv1beta1.ConfigItem{
 		Name:     "requiredFalseValueEmptyConfigItem",
 		Type:     "text",
@@ -243,6 +265,26 @@ func Test_validateConfigItem(t *testing.T) {
 					},
 				},
 			},
+		}, {
+			name: "secret reference is not validated against the regex",
+			args: args{
+				item: secretRefConfigItem,
+			},
+			want: nil,
+		}, {
+			name: "invalid secret reference",
+			args: args{
+				item: invalidSecretRefConfigItem,
+			},
+			want: &configtypes.ConfigItemValidationError{
+				Name: invalidSecretRefConfigItem.Name,
+				Type: invalidSecretRefConfigItem.Type,
+				ValidationErrors: []configtypes.ValidationError{
+					{
+						Message: `reference "ref+vault://secret/data/my-app" is missing a #<key>`,
+					},
+				},
+			},
 		}, {
 			name: "expect no error when required is false and value is empty",
 			args: args{
@@ -273,7 +315,7 @@ func Test_validateConfigItem(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validateConfigItem(tt.args.item)
+			got, err := validateConfigItem(tt.args.item, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConfigItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -341,7 +383,7 @@ func Test_validateConfigItems(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validateConfigItems(tt.args.configItems)
+			got, err := validateConfigItems(tt.args.configItems, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConfigItems() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -459,7 +501,7 @@ func Test_validateConfigGroup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validateConfigGroup(tt.args.configGroup)
+			got, err := validateConfigGroup(tt.args.configGroup, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateConfigGroup() error = %v, wantErr %v", err, tt.wantErr)
 				return
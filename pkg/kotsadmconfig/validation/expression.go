@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+const (
+	expressionError = "Value does not satisfy the validation rule"
+)
+
+// expressionValidator checks a rule that has already been rendered with the rest of the
+// config, so any references to other config items have been resolved.
+type expressionValidator struct {
+	*kotsv1beta1.ExpressionValidator
+}
+
+func (v *expressionValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	rule := strings.TrimSpace(string(v.Rule))
+	if rule == "" {
+		return nil, errors.New("expression rule is empty")
+	}
+
+	passed, err := strconv.ParseBool(rule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "expression rule %q did not render to a boolean", rule)
+	}
+
+	if !passed {
+		return newValidationError(v.Message, expressionError), nil
+	}
+	return nil, nil
+}
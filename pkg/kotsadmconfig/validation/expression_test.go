@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/kotskinds/multitype"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+func Test_expressionValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.ExpressionValidator
+		want      *configtypes.ValidationError
+		wantErr   bool
+	}{
+		{
+			name:      "rule passed",
+			validator: &kotsv1beta1.ExpressionValidator{Rule: "true"},
+			want:      nil,
+		}, {
+			name:      "rule failed",
+			validator: &kotsv1beta1.ExpressionValidator{Rule: "false", Message: "max must be greater than min"},
+			want:      &configtypes.ValidationError{Message: "max must be greater than min"},
+		}, {
+			name:      "rule failed with no message",
+			validator: &kotsv1beta1.ExpressionValidator{Rule: multitype.QuotedBool(" false\n")},
+			want:      &configtypes.ValidationError{Message: expressionError},
+		}, {
+			name:      "rule not rendered",
+			validator: &kotsv1beta1.ExpressionValidator{Rule: `repl{{ ConfigOptionEquals "a" "b" }}`},
+			wantErr:   true,
+		}, {
+			name:      "empty rule",
+			validator: &kotsv1beta1.ExpressionValidator{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &expressionValidator{
+				ExpressionValidator: tt.validator,
+			}
+			got, err := v.Validate("value")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expressionValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expressionValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
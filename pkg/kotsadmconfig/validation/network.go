@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	urlError      = "Value must be a valid URL"
+	emailError    = "Value must be a valid email address"
+	ipError       = "Value must be a valid IP address"
+	cidrError     = "Value must be a valid CIDR"
+	hostnameError = "Value must be a valid hostname"
+
+	ipVersion4 = "ipv4"
+	ipVersion6 = "ipv6"
+)
+
+type urlValidator struct {
+	*kotsv1beta1.URLValidator
+}
+
+func (v *urlValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return newValidationError(v.Message, urlError), nil
+	}
+
+	if len(v.Schemes) == 0 {
+		return nil, nil
+	}
+	for _, scheme := range v.Schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil, nil
+		}
+	}
+	return newValidationError(v.Message, fmt.Sprintf("URL scheme must be one of: %s", strings.Join(v.Schemes, ", "))), nil
+}
+
+type emailValidator struct {
+	*kotsv1beta1.EmailValidator
+}
+
+func (v *emailValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	address, err := mail.ParseAddress(input)
+	// a display name such as "Name <name@example.com>" is not an email address
+	if err != nil || address.Address != input {
+		return newValidationError(v.Message, emailError), nil
+	}
+	return nil, nil
+}
+
+type ipValidator struct {
+	*kotsv1beta1.IPValidator
+}
+
+func (v *ipValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	ip := net.ParseIP(strings.TrimSpace(input))
+	if ip == nil {
+		return newValidationError(v.Message, ipError), nil
+	}
+
+	matches, err := ipVersionMatches(ip, v.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !matches {
+		return newValidationError(v.Message, fmt.Sprintf("Value must be a valid %s address", v.Version)), nil
+	}
+	return nil, nil
+}
+
+type cidrValidator struct {
+	*kotsv1beta1.CIDRValidator
+}
+
+func (v *cidrValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	ip, _, err := net.ParseCIDR(strings.TrimSpace(input))
+	if err != nil {
+		return newValidationError(v.Message, cidrError), nil
+	}
+
+	matches, err := ipVersionMatches(ip, v.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !matches {
+		return newValidationError(v.Message, fmt.Sprintf("Value must be a valid %s CIDR", v.Version)), nil
+	}
+	return nil, nil
+}
+
+type hostnameValidator struct {
+	*kotsv1beta1.HostnameValidator
+}
+
+func (v *hostnameValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	hostname := strings.ToLower(strings.TrimSpace(input))
+	if errs := k8svalidation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return newValidationError(v.Message, hostnameError), nil
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if len(label) > k8svalidation.DNS1123LabelMaxLength {
+			return newValidationError(v.Message, hostnameError), nil
+		}
+	}
+	return nil, nil
+}
+
+func ipVersionMatches(ip net.IP, version string) (bool, error) {
+	switch version {
+	case "":
+		return true, nil
+	case ipVersion4:
+		return ip.To4() != nil, nil
+	case ipVersion6:
+		return ip.To4() == nil, nil
+	default:
+		return false, errors.Errorf("unknown ip version %q", version)
+	}
+}
@@ -0,0 +1,240 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+func Test_urlValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.URLValidator
+		input     string
+		want      *configtypes.ValidationError
+	}{
+		{
+			name:      "valid url",
+			validator: &kotsv1beta1.URLValidator{},
+			input:     "https://example.com/path",
+			want:      nil,
+		}, {
+			name:      "missing scheme",
+			validator: &kotsv1beta1.URLValidator{},
+			input:     "example.com",
+			want:      &configtypes.ValidationError{Message: urlError},
+		}, {
+			name:      "allowed scheme",
+			validator: &kotsv1beta1.URLValidator{Schemes: []string{"http", "https"}},
+			input:     "HTTPS://example.com",
+			want:      nil,
+		}, {
+			name:      "disallowed scheme",
+			validator: &kotsv1beta1.URLValidator{Schemes: []string{"https"}},
+			input:     "http://example.com",
+			want:      &configtypes.ValidationError{Message: "URL scheme must be one of: https"},
+		}, {
+			name:      "custom message",
+			validator: &kotsv1beta1.URLValidator{Message: "must be a url"},
+			input:     "not a url",
+			want:      &configtypes.ValidationError{Message: "must be a url"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &urlValidator{
+				URLValidator: tt.validator,
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("urlValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("urlValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_emailValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *configtypes.ValidationError
+	}{
+		{
+			name:  "valid email",
+			input: "admin@example.com",
+			want:  nil,
+		}, {
+			name:  "missing domain",
+			input: "admin",
+			want:  &configtypes.ValidationError{Message: emailError},
+		}, {
+			name:  "display name",
+			input: "Admin <admin@example.com>",
+			want:  &configtypes.ValidationError{Message: emailError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &emailValidator{
+				EmailValidator: &kotsv1beta1.EmailValidator{},
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("emailValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("emailValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ipValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.IPValidator
+		input     string
+		want      *configtypes.ValidationError
+		wantErr   bool
+	}{
+		{
+			name:      "valid ipv4",
+			validator: &kotsv1beta1.IPValidator{},
+			input:     "10.0.0.1",
+			want:      nil,
+		}, {
+			name:      "valid ipv6",
+			validator: &kotsv1beta1.IPValidator{},
+			input:     "fd00::1",
+			want:      nil,
+		}, {
+			name:      "invalid ip",
+			validator: &kotsv1beta1.IPValidator{},
+			input:     "10.0.0.256",
+			want:      &configtypes.ValidationError{Message: ipError},
+		}, {
+			name:      "ipv6 when ipv4 is required",
+			validator: &kotsv1beta1.IPValidator{Version: "ipv4"},
+			input:     "fd00::1",
+			want:      &configtypes.ValidationError{Message: "Value must be a valid ipv4 address"},
+		}, {
+			name:      "ipv4 when ipv6 is required",
+			validator: &kotsv1beta1.IPValidator{Version: "ipv6"},
+			input:     "10.0.0.1",
+			want:      &configtypes.ValidationError{Message: "Value must be a valid ipv6 address"},
+		}, {
+			name:      "unknown version",
+			validator: &kotsv1beta1.IPValidator{Version: "ipv5"},
+			input:     "10.0.0.1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &ipValidator{
+				IPValidator: tt.validator,
+			}
+			got, err := v.Validate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ipValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ipValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_cidrValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.CIDRValidator
+		input     string
+		want      *configtypes.ValidationError
+	}{
+		{
+			name:      "valid cidr",
+			validator: &kotsv1beta1.CIDRValidator{},
+			input:     "10.96.0.0/12",
+			want:      nil,
+		}, {
+			name:      "ip without prefix",
+			validator: &kotsv1beta1.CIDRValidator{},
+			input:     "10.96.0.0",
+			want:      &configtypes.ValidationError{Message: cidrError},
+		}, {
+			name:      "ipv6 when ipv4 is required",
+			validator: &kotsv1beta1.CIDRValidator{Version: "ipv4"},
+			input:     "fd00::/8",
+			want:      &configtypes.ValidationError{Message: "Value must be a valid ipv4 CIDR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &cidrValidator{
+				CIDRValidator: tt.validator,
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("cidrValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cidrValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hostnameValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *configtypes.ValidationError
+	}{
+		{
+			name:  "valid hostname",
+			input: "Registry.Example.com",
+			want:  nil,
+		}, {
+			name:  "single label",
+			input: "localhost",
+			want:  nil,
+		}, {
+			name:  "leading hyphen",
+			input: "-example.com",
+			want:  &configtypes.ValidationError{Message: hostnameError},
+		}, {
+			name:  "includes a port",
+			input: "example.com:443",
+			want:  &configtypes.ValidationError{Message: hostnameError},
+		}, {
+			name:  "label too long",
+			input: "a123456789012345678901234567890123456789012345678901234567890123.com",
+			want:  &configtypes.ValidationError{Message: hostnameError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &hostnameValidator{
+				HostnameValidator: &kotsv1beta1.HostnameValidator{},
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("hostnameValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hostnameValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+const (
+	intTypeError   = "Value must be an integer"
+	floatTypeError = "Value must be a number"
+	portError      = "Value must be a port number between 1 and 65535"
+)
+
+type intValidator struct {
+	*kotsv1beta1.IntValidator
+}
+
+func (v *intValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	value, err := strconv.ParseInt(strings.TrimSpace(input), 10, 64)
+	if err != nil {
+		return newValidationError(v.Message, intTypeError), nil
+	}
+
+	if (v.Min != nil && value < *v.Min) || (v.Max != nil && value > *v.Max) {
+		return newValidationError(v.Message, rangeError(formatInt(v.Min), formatInt(v.Max))), nil
+	}
+	return nil, nil
+}
+
+type floatValidator struct {
+	*kotsv1beta1.FloatValidator
+}
+
+func (v *floatValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil {
+		return newValidationError(v.Message, floatTypeError), nil
+	}
+
+	var min, max *float64
+	if v.Min != "" {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v.Min), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse min %q", v.Min)
+		}
+		min = &parsed
+	}
+	if v.Max != "" {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v.Max), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse max %q", v.Max)
+		}
+		max = &parsed
+	}
+
+	if (min != nil && value < *min) || (max != nil && value > *max) {
+		return newValidationError(v.Message, rangeError(v.Min, v.Max)), nil
+	}
+	return nil, nil
+}
+
+type portValidator struct {
+	*kotsv1beta1.PortValidator
+}
+
+func (v *portValidator) Validate(input string) (*configtypes.ValidationError, error) {
+	port, err := strconv.ParseInt(strings.TrimSpace(input), 10, 64)
+	if err != nil || port < 1 || port > 65535 {
+		return newValidationError(v.Message, portError), nil
+	}
+	return nil, nil
+}
+
+func formatInt(i *int64) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.FormatInt(*i, 10)
+}
+
+func rangeError(min string, max string) string {
+	min, max = strings.TrimSpace(min), strings.TrimSpace(max)
+	switch {
+	case min != "" && max != "":
+		return fmt.Sprintf("Value must be between %s and %s", min, max)
+	case min != "":
+		return fmt.Sprintf("Value must be greater than or equal to %s", min)
+	default:
+		return fmt.Sprintf("Value must be less than or equal to %s", max)
+	}
+}
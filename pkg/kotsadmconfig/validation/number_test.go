@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	configtypes "github.com/replicatedhq/kots/pkg/kotsadmconfig/types"
+)
+
+func Test_intValidator_Validate(t *testing.T) {
+	min := int64(1)
+	max := int64(10)
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.IntValidator
+		input     string
+		want      *configtypes.ValidationError
+	}{
+		{
+			name:      "valid integer",
+			validator: &kotsv1beta1.IntValidator{},
+			input:     "-42",
+			want:      nil,
+		}, {
+			name:      "not an integer",
+			validator: &kotsv1beta1.IntValidator{},
+			input:     "4.2",
+			want:      &configtypes.ValidationError{Message: intTypeError},
+		}, {
+			name:      "within range",
+			validator: &kotsv1beta1.IntValidator{Min: &min, Max: &max},
+			input:     " 10 ",
+			want:      nil,
+		}, {
+			name:      "below range",
+			validator: &kotsv1beta1.IntValidator{Min: &min, Max: &max},
+			input:     "0",
+			want:      &configtypes.ValidationError{Message: "Value must be between 1 and 10"},
+		}, {
+			name:      "below min",
+			validator: &kotsv1beta1.IntValidator{Min: &min},
+			input:     "0",
+			want:      &configtypes.ValidationError{Message: "Value must be greater than or equal to 1"},
+		}, {
+			name:      "above max with custom message",
+			validator: &kotsv1beta1.IntValidator{Max: &max, Message: "too many replicas"},
+			input:     "11",
+			want:      &configtypes.ValidationError{Message: "too many replicas"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &intValidator{
+				IntValidator: tt.validator,
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("intValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("intValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_floatValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator *kotsv1beta1.FloatValidator
+		input     string
+		want      *configtypes.ValidationError
+		wantErr   bool
+	}{
+		{
+			name:      "valid float",
+			validator: &kotsv1beta1.FloatValidator{},
+			input:     "0.5",
+			want:      nil,
+		}, {
+			name:      "not a number",
+			validator: &kotsv1beta1.FloatValidator{},
+			input:     "half",
+			want:      &configtypes.ValidationError{Message: floatTypeError},
+		}, {
+			name:      "within range",
+			validator: &kotsv1beta1.FloatValidator{Min: "0", Max: "1"},
+			input:     "0.75",
+			want:      nil,
+		}, {
+			name:      "above max",
+			validator: &kotsv1beta1.FloatValidator{Max: "1"},
+			input:     "1.01",
+			want:      &configtypes.ValidationError{Message: "Value must be less than or equal to 1"},
+		}, {
+			name:      "invalid min",
+			validator: &kotsv1beta1.FloatValidator{Min: "zero"},
+			input:     "1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &floatValidator{
+				FloatValidator: tt.validator,
+			}
+			got, err := v.Validate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("floatValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("floatValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_portValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *configtypes.ValidationError
+	}{
+		{
+			name:  "valid port",
+			input: "8443",
+			want:  nil,
+		}, {
+			name:  "zero",
+			input: "0",
+			want:  &configtypes.ValidationError{Message: portError},
+		}, {
+			name:  "too large",
+			input: "65536",
+			want:  &configtypes.ValidationError{Message: portError},
+		}, {
+			name:  "not a number",
+			input: "http",
+			want:  &configtypes.ValidationError{Message: portError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &portValidator{
+				PortValidator: &kotsv1beta1.PortValidator{},
+			}
+			got, err := v.Validate(tt.input)
+			if err != nil {
+				t.Errorf("portValidator.Validate() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("portValidator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
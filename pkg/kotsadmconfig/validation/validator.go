@@ -52,9 +52,9 @@ func isValidatableConfigItem(item kotsv1beta1.ConfigItem) bool {
 	return true
 }
 
-func validate(value string, itemValidation kotsv1beta1.ConfigItemValidation) ([]configtypes.ValidationError, error) {
+func validate(value string, itemValidation kotsv1beta1.ConfigItemValidation, configItems map[string]kotsv1beta1.ConfigItem) ([]configtypes.ValidationError, error) {
 	var validationErrs []configtypes.ValidationError
-	validators := buildValidators(itemValidation)
+	validators := buildValidators(itemValidation, configItems)
 	for _, v := range validators {
 		validationErr, err := v.Validate(value)
 		if err != nil {
@@ -69,10 +69,51 @@ func validate(value string, itemValidation kotsv1beta1.ConfigItemValidation) ([]
 	return validationErrs, nil
 }
 
-func buildValidators(itemValidator kotsv1beta1.ConfigItemValidation) []validator {
+func buildValidators(itemValidator kotsv1beta1.ConfigItemValidation, configItems map[string]kotsv1beta1.ConfigItem) []validator {
 	var validators []validator
 	if itemValidator.Regex != nil {
 		validators = append(validators, &regexValidator{itemValidator.Regex})
 	}
+	if itemValidator.Int != nil {
+		validators = append(validators, &intValidator{itemValidator.Int})
+	}
+	if itemValidator.Float != nil {
+		validators = append(validators, &floatValidator{itemValidator.Float})
+	}
+	if itemValidator.URL != nil {
+		validators = append(validators, &urlValidator{itemValidator.URL})
+	}
+	if itemValidator.Email != nil {
+		validators = append(validators, &emailValidator{itemValidator.Email})
+	}
+	if itemValidator.IP != nil {
+		validators = append(validators, &ipValidator{itemValidator.IP})
+	}
+	if itemValidator.CIDR != nil {
+		validators = append(validators, &cidrValidator{itemValidator.CIDR})
+	}
+	if itemValidator.Hostname != nil {
+		validators = append(validators, &hostnameValidator{itemValidator.Hostname})
+	}
+	if itemValidator.Port != nil {
+		validators = append(validators, &portValidator{itemValidator.Port})
+	}
+	if itemValidator.Certificate != nil {
+		validators = append(validators, &certificateValidator{itemValidator.Certificate, configItems})
+	}
+	if itemValidator.Expression != nil {
+		validators = append(validators, &expressionValidator{itemValidator.Expression})
+	}
 	return validators
 }
+
+// newValidationError returns a validation error with the validator's message, falling back
+// to defaultMessage when the message is not set.
+func newValidationError(message string, defaultMessage string) *configtypes.ValidationError {
+	if message == "" {
+		message = defaultMessage
+	}
+	return &configtypes.ValidationError{
+		Message: message,
+	}
+}
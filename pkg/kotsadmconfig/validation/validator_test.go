@@ -62,8 +62,9 @@ func Test_isValidatableConfigItem(t *testing.T) {
 
 func Test_validate(t *testing.T) {
 	type args struct {
-		value     string
-		validator kotsv1beta1.ConfigItemValidation
+		value       string
+		validator   kotsv1beta1.ConfigItemValidation
+		configItems map[string]kotsv1beta1.ConfigItem
 	}
 	tests := []struct {
 		name    string
@@ -112,6 +113,43 @@ func Test_validate(t *testing.T) {
 					Message: "must be a valid regex",
 				},
 			},
+		}, {
+			name: "multiple failing validators",
+			args: args{
+				value: "foo",
+				validator: kotsv1beta1.ConfigItemValidation{
+					Regex: &kotsv1beta1.RegexValidator{
+						Pattern: "^[0-9]+$",
+						Message: "must be digits",
+					},
+					Int: &kotsv1beta1.IntValidator{},
+				},
+			},
+			want: []configtypes.ValidationError{
+				{
+					Message: "must be digits",
+				},
+				{
+					Message: intTypeError,
+				},
+			},
+		}, {
+			name: "failing expression rule",
+			args: args{
+				value: "10",
+				validator: kotsv1beta1.ConfigItemValidation{
+					Int: &kotsv1beta1.IntValidator{},
+					Expression: &kotsv1beta1.ExpressionValidator{
+						Rule:    "false",
+						Message: "must be greater than the minimum",
+					},
+				},
+			},
+			want: []configtypes.ValidationError{
+				{
+					Message: "must be greater than the minimum",
+				},
+			},
 		}, {
 			name: "empty item validators",
 			args: args{
@@ -123,7 +161,7 @@ func Test_validate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validate(tt.args.value, tt.args.validator)
+			got, err := validate(tt.args.value, tt.args.validator, tt.args.configItems)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -137,8 +175,15 @@ func Test_validate(t *testing.T) {
 
 func Test_buildValidators(t *testing.T) {
 	regexpValidator := &kotsv1beta1.RegexValidator{Pattern: ".*"}
+	hostnameItemValidator := &kotsv1beta1.HostnameValidator{}
+	portItemValidator := &kotsv1beta1.PortValidator{}
+	certificateItemValidator := &kotsv1beta1.CertificateValidator{KeyItem: "tls_key"}
+	configItems := map[string]kotsv1beta1.ConfigItem{
+		"tls_key": {Name: "tls_key", Type: "file"},
+	}
 	type args struct {
 		itemValidator kotsv1beta1.ConfigItemValidation
+		configItems   map[string]kotsv1beta1.ConfigItem
 	}
 	tests := []struct {
 		name string
@@ -157,11 +202,41 @@ func Test_buildValidators(t *testing.T) {
 					regexpValidator,
 				},
 			},
+		}, {
+			name: "port and hostname",
+			args: args{
+				itemValidator: kotsv1beta1.ConfigItemValidation{
+					Hostname: hostnameItemValidator,
+					Port:     portItemValidator,
+				},
+			},
+			want: []validator{
+				&hostnameValidator{
+					hostnameItemValidator,
+				},
+				&portValidator{
+					portItemValidator,
+				},
+			},
+		}, {
+			name: "certificate",
+			args: args{
+				itemValidator: kotsv1beta1.ConfigItemValidation{
+					Certificate: certificateItemValidator,
+				},
+				configItems: configItems,
+			},
+			want: []validator{
+				&certificateValidator{
+					certificateItemValidator,
+					configItems,
+				},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := buildValidators(tt.args.itemValidator); !reflect.DeepEqual(got, tt.want) {
+			if got := buildValidators(tt.args.itemValidator, tt.args.configItems); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("buildValidators() = %v, want %v", got, tt.want)
 			}
 		})
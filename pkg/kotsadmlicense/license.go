@@ -12,6 +12,7 @@ import (
 	kotslicense "github.com/replicatedhq/kots/pkg/license"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/replicatedapp"
 	"github.com/replicatedhq/kots/pkg/store"
@@ -90,7 +91,7 @@ func Sync(a *apptypes.App, licenseString string, failOnVersionCreate bool) (*kot
 			return nil, false, errors.Wrap(err, "failed to update license")
 		}
 
-		if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, preflighttypes.PreflightTriggerLicenseChange, archiveDir); err != nil {
 			return nil, false, errors.Wrap(err, "failed to run preflights")
 		}
 		synced = true
@@ -196,7 +197,7 @@ func Change(a *apptypes.App, newLicenseString string) (*kotsv1beta1.License, err
 		return nil, errors.Wrap(err, "failed to update license")
 	}
 
-	if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, archiveDir); err != nil {
+	if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, preflighttypes.PreflightTriggerLicenseChange, archiveDir); err != nil {
 		return nil, errors.Wrap(err, "failed to run preflights")
 	}
 
@@ -30,6 +30,7 @@ import (
 	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -165,6 +166,20 @@ func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled b
 		return nil, errors.Wrap(err, "failed to create k8s clientset")
 	}
 
+	resourceHooks, declaredHooks, err := prepareBackupHooks(ctx, clientset, a.Slug, appNamespace, kotsKinds.KotsApplication.Spec.AdditionalNamespaces, kotsKinds.KotsApplication.Spec.BackupHooks)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare backup hooks")
+	}
+	veleroBackup.Spec.Hooks.Resources = append(veleroBackup.Spec.Hooks.Resources, resourceHooks...)
+
+	hooksAnnotations, err := types.BackupHooksAnnotations(declaredHooks)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal backup hooks")
+	}
+	for k, v := range hooksAnnotations {
+		veleroBackup.Annotations[k] = v
+	}
+
 	err = excludeShutdownPodsFromBackup(ctx, clientset, veleroBackup)
 	if err != nil {
 		logger.Error(errors.Wrap(err, "failed to exclude shutdown pods from backup"))
@@ -210,6 +225,7 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 	backupHooks := velerov1.BackupHooks{
 		Resources: []velerov1.BackupResourceHookSpec{},
 	}
+	declaredHooks := []types.DeclaredBackupHook{}
 	// non-supported fields that are intentionally left out cuz they might break full snapshots:
 	// - includedResources
 	// - excludedResources
@@ -308,6 +324,13 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 
 		// backup hooks
 		backupHooks.Resources = append(backupHooks.Resources, veleroBackup.Spec.Hooks.Resources...)
+
+		resourceHooks, appDeclaredHooks, err := prepareBackupHooks(ctx, clientset, a.Slug, appNamespace, kotsKinds.KotsApplication.Spec.AdditionalNamespaces, kotsKinds.KotsApplication.Spec.BackupHooks)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare backup hooks for app %s", a.Slug)
+		}
+		backupHooks.Resources = append(backupHooks.Resources, resourceHooks...)
+		declaredHooks = append(declaredHooks, appDeclaredHooks...)
 	}
 
 	kotsadmVeleroBackendStorageLocation, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
@@ -351,6 +374,14 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 	backupAnnotations["kots.io/kotsadm-deploy-namespace"] = kotsadmNamespace
 	backupAnnotations["kots.io/apps-sequences"] = marshalledAppsSequences
 
+	hooksAnnotations, err := types.BackupHooksAnnotations(declaredHooks)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal backup hooks")
+	}
+	for k, v := range hooksAnnotations {
+		backupAnnotations[k] = v
+	}
+
 	includeClusterResources := true
 	veleroBackup := &velerov1.Backup{
 		ObjectMeta: metav1.ObjectMeta{
@@ -383,6 +414,10 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 		veleroBackup.ObjectMeta.Annotations["kots.io/kurl-registry"] = registryHost
 	}
 
+	if isScheduled && cluster.SnapshotStorageLocation != "" {
+		veleroBackup.Spec.StorageLocation = cluster.SnapshotStorageLocation
+	}
+
 	if cluster.SnapshotTTL != "" {
 		ttlDuration, err := time.ParseDuration(cluster.SnapshotTTL)
 		if err != nil {
@@ -393,6 +428,19 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 		}
 	}
 
+	if isScheduled && cluster.SnapshotRetention != "" {
+		// scheduled backups are pruned by the retention policy, the ttl has to outlive it
+		retention, err := ParseRetentionPolicy(cluster.SnapshotRetention)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse cluster snapshot retention")
+		}
+		if retention != nil && !retention.IsEmpty() {
+			veleroBackup.Spec.TTL = metav1.Duration{
+				Duration: RetentionPolicyTTL(*retention),
+			}
+		}
+	}
+
 	err = excludeShutdownPodsFromBackup(ctx, clientset, veleroBackup)
 	if err != nil {
 		logger.Error(errors.Wrap(err, "failed to exclude shutdown pods from backup"))
@@ -450,7 +498,7 @@ func ListBackupsForApp(ctx context.Context, kotsadmNamespace string, appID strin
 
 		backup := types.Backup{
 			Name:   veleroBackup.Name,
-			Status: string(veleroBackup.Status.Phase),
+			Status: getBackupStatus(&veleroBackup),
 			AppID:  appID,
 		}
 
@@ -579,7 +627,7 @@ func ListInstanceBackups(ctx context.Context, kotsadmNamespace string) ([]*types
 
 		backup := types.Backup{
 			Name:         veleroBackup.Name,
-			Status:       string(veleroBackup.Status.Phase),
+			Status:       getBackupStatus(&veleroBackup),
 			IncludedApps: make([]types.App, 0),
 		}
 
@@ -601,6 +649,10 @@ func ListInstanceBackups(ctx context.Context, kotsadmNamespace string) ([]*types
 			backup.Trigger = trigger
 		}
 
+		backup.RestoreDrill = types.GetRestoreDrill(veleroBackup.Annotations)
+		backup.StorageLocation = veleroBackup.Spec.StorageLocation
+		backup.Replications = types.GetReplications(veleroBackup.Annotations)
+
 		volumeCount, volumeCountOk := veleroBackup.Annotations["kots.io/snapshot-volume-count"]
 		if volumeCountOk {
 			i, err := strconv.Atoi(volumeCount)
@@ -776,6 +828,17 @@ func DeleteBackup(ctx context.Context, kotsadmNamespace string, snapshotName str
 		return errors.Wrap(err, "failed to create clientset")
 	}
 
+	// the copies are deleted first so that a failure can be retried, the backup is gone once velero deletes it
+	veleroBackup, err := veleroClient.Backups(veleroNamespace).Get(ctx, snapshotName, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get backup")
+	}
+	if err == nil {
+		if err := deleteReplicatedBackups(ctx, kotsadmNamespace, snapshotName, types.GetReplications(veleroBackup.Annotations)); err != nil {
+			return errors.Wrap(err, "failed to delete replicated backups")
+		}
+	}
+
 	_, err = veleroClient.DeleteBackupRequests(veleroNamespace).Create(context.TODO(), veleroDeleteBackupRequest, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to create delete backup request")
@@ -846,7 +909,7 @@ func GetBackupDetail(ctx context.Context, kotsadmNamespace string, backupName st
 
 	result := &types.BackupDetail{
 		Name:       backup.Name,
-		Status:     string(backup.Status.Phase),
+		Status:     getBackupStatus(backup),
 		Namespaces: backup.Spec.IncludedNamespaces,
 		Volumes:    listBackupVolumes(backupVolumes.Items),
 	}
@@ -857,17 +920,22 @@ func GetBackupDetail(ctx context.Context, kotsadmNamespace string, backupName st
 	}
 	result.VolumeSizeHuman = units.HumanSize(float64(totalBytesDone)) // TODO: should this be TotalBytes rather than BytesDone?
 
+	var execs []*types.SnapshotHook
 	if backup.Status.Phase == velerov1.BackupPhaseCompleted || backup.Status.Phase == velerov1.BackupPhasePartiallyFailed || backup.Status.Phase == velerov1.BackupPhaseFailed {
-		errs, warnings, execs, err := downloadBackupLogs(ctx, veleroNamespace, backupName)
+		errs, warnings, backupExecs, err := downloadBackupLogs(ctx, veleroNamespace, backupName)
 		result.Errors = errs
 		result.Warnings = warnings
-		result.Hooks = execs
+		result.Hooks = backupExecs
 		if err != nil {
 			// do not fail on error
 			logger.Error(errors.Wrap(err, "failed to download backup logs"))
+		} else {
+			execs = backupExecs
 		}
 	}
 
+	result.HookStatuses = getBackupHookStatuses(backup, backupVolumes.Items, execs)
+
 	return result, nil
 }
 
@@ -0,0 +1,583 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/appstate"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
+	"github.com/replicatedhq/kots/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+)
+
+var (
+	// RestoreDrillTimeout is how long a restore drill waits for the restore to complete and the apps to become ready
+	RestoreDrillTimeout = 30 * time.Minute
+
+	restoreDrillInterval = 5 * time.Second
+
+	// restoreDrillsInProgress are the backups that are drilled by this kotsadm process
+	restoreDrillsInProgress   = map[string]bool{}
+	restoreDrillsInProgressMu sync.Mutex
+)
+
+const (
+	// RestoreDrillLabel marks the scratch namespaces created by restore drills
+	RestoreDrillLabel = "kots.io/restore-drill"
+	// RestoreDrillBackupAnnotation is the name of the backup a restore drill namespace was created for
+	RestoreDrillBackupAnnotation = "kots.io/restore-drill-backup"
+)
+
+// StartRestoreDrill verifies that an instance backup actually restores. The apps in the backup are restored into
+// scratch namespaces, using a velero namespace mapping, and the drill passes once the status informers of the apps
+// are ready there. The drill runs in the background, its result is recorded on the backup and the scratch
+// namespaces are deleted when it finishes. Drills that are interrupted, e.g. by a kotsadm restart, are cleaned up
+// by CleanupStaleRestoreDrills.
+func StartRestoreDrill(ctx context.Context, kotsadmNamespace string, backupName string) error {
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list instance backups")
+	}
+
+	var backup *types.Backup
+	for _, b := range backups {
+		if b.Name == backupName {
+			backup = b
+		}
+		if isRestoreDrillRunning(b.RestoreDrill, time.Now()) {
+			return errors.Errorf("a restore drill is already running for backup %s", b.Name)
+		}
+	}
+	if backup == nil {
+		return errors.Errorf("instance backup %s not found", backupName)
+	}
+	if backup.Status != string(velerov1.BackupPhaseCompleted) {
+		return errors.Errorf("backup %s is %s, only completed backups can be drilled", backupName, backup.Status)
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return errors.New("no backup store location found")
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	// the drill is marked as in progress before it's recorded so that it's never considered stale
+	setRestoreDrillInProgress(backupName, true)
+
+	startedAt := time.Now()
+	drill := types.RestoreDrill{
+		Status:    types.RestoreDrillStatusRunning,
+		StartedAt: &startedAt,
+	}
+	if err := recordRestoreDrill(ctx, veleroClient, veleroNamespace, backupName, drill); err != nil {
+		setRestoreDrillInProgress(backupName, false)
+		return errors.Wrap(err, "failed to record restore drill")
+	}
+
+	go func() {
+		defer setRestoreDrillInProgress(backupName, false)
+
+		drillErr := runRestoreDrill(context.Background(), clientset, veleroClient, veleroNamespace, backupName)
+
+		finishedAt := time.Now()
+		drill.FinishedAt = &finishedAt
+		if drillErr != nil {
+			drill.Status = types.RestoreDrillStatusFailed
+			drill.Message = drillErr.Error()
+			logger.Infof("Restore drill for backup %s failed: %v", backupName, drillErr)
+		} else {
+			drill.Status = types.RestoreDrillStatusPassed
+			logger.Infof("Restore drill for backup %s passed in %s", backupName, drill.Duration())
+		}
+
+		if err := recordRestoreDrill(context.Background(), veleroClient, veleroNamespace, backupName, drill); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to record restore drill for backup %s", backupName))
+		}
+	}()
+
+	return nil
+}
+
+// CleanupStaleRestoreDrills fails the restore drills that are recorded as running but are not running in this
+// kotsadm process, e.g. because kotsadm restarted before the result was recorded, and deletes the scratch namespaces
+// and the restores that were left behind by them.
+func CleanupStaleRestoreDrills(ctx context.Context, kotsadmNamespace string) error {
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		// snapshots are not configured
+		return nil
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list instance backups")
+	}
+
+	return cleanupStaleRestoreDrills(ctx, clientset, veleroClient, veleroNamespace, backups)
+}
+
+func cleanupStaleRestoreDrills(ctx context.Context, clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, backups []*types.Backup) error {
+	for _, backup := range backups {
+		if backup.RestoreDrill == nil || backup.RestoreDrill.Status != types.RestoreDrillStatusRunning {
+			continue
+		}
+		if isRestoreDrillInProgress(backup.Name) {
+			continue
+		}
+
+		drill := *backup.RestoreDrill
+		finishedAt := time.Now()
+		drill.Status = types.RestoreDrillStatusFailed
+		drill.FinishedAt = &finishedAt
+		drill.Message = "restore drill was interrupted before it finished"
+		if err := recordRestoreDrill(ctx, veleroClient, veleroNamespace, backup.Name, drill); err != nil {
+			return errors.Wrapf(err, "failed to record restore drill for backup %s", backup.Name)
+		}
+		logger.Infof("Restore drill for backup %s was interrupted, marked it as failed", backup.Name)
+	}
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", RestoreDrillLabel),
+	})
+	if kuberneteserrors.IsForbidden(err) {
+		// kotsadm has no cluster scoped access, so no drill could have created namespaces
+		namespaces = &corev1.NamespaceList{}
+	} else if err != nil {
+		return errors.Wrap(err, "failed to list restore drill namespaces")
+	}
+	for _, namespace := range namespaces.Items {
+		if namespace.DeletionTimestamp != nil || isRestoreDrillInProgress(namespace.Annotations[RestoreDrillBackupAnnotation]) {
+			continue
+		}
+		err := clientset.CoreV1().Namespaces().Delete(ctx, namespace.Name, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete restore drill namespace %s", namespace.Name)
+		}
+		logger.Infof("Deleted namespace %s of an interrupted restore drill", namespace.Name)
+	}
+
+	restores, err := veleroClient.Restores(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list restores")
+	}
+	for _, restore := range restores.Items {
+		if restore.Annotations[RestoreDrillLabel] != "true" || isRestoreDrillInProgress(restore.Spec.BackupName) {
+			continue
+		}
+		err := veleroClient.Restores(veleroNamespace).Delete(ctx, restore.Name, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete restore drill restore %s", restore.Name)
+		}
+		logger.Infof("Deleted restore %s of an interrupted restore drill", restore.Name)
+	}
+
+	return nil
+}
+
+func setRestoreDrillInProgress(backupName string, inProgress bool) {
+	restoreDrillsInProgressMu.Lock()
+	defer restoreDrillsInProgressMu.Unlock()
+
+	if inProgress {
+		restoreDrillsInProgress[backupName] = true
+	} else {
+		delete(restoreDrillsInProgress, backupName)
+	}
+}
+
+func isRestoreDrillInProgress(backupName string) bool {
+	restoreDrillsInProgressMu.Lock()
+	defer restoreDrillsInProgressMu.Unlock()
+
+	return restoreDrillsInProgress[backupName]
+}
+
+// HasRunningRestoreDrill returns true if any instance backup is being drilled
+func HasRunningRestoreDrill(ctx context.Context, kotsadmNamespace string) (bool, error) {
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list backups")
+	}
+
+	for _, backup := range backups {
+		if isRestoreDrillRunning(backup.RestoreDrill, time.Now()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetLatestCompletedInstanceBackupName returns the name of the most recent completed instance backup,
+// or an empty string if there is none
+func GetLatestCompletedInstanceBackupName(ctx context.Context, kotsadmNamespace string) (string, error) {
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list backups")
+	}
+
+	latest := latestCompletedBackup(backups)
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Name, nil
+}
+
+func runRestoreDrill(ctx context.Context, clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, backupName string) error {
+	ctx, cancel := context.WithTimeout(ctx, RestoreDrillTimeout)
+	defer cancel()
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get backup")
+	}
+
+	drillID := strings.ToLower(rand.String(5))
+
+	namespaceMapping, err := getRestoreDrillNamespaceMapping(backup.Spec.IncludedNamespaces, drillID)
+	if err != nil {
+		return errors.Wrap(err, "failed to map namespaces")
+	}
+
+	appSlugs, err := getBackupAppSlugs(backup)
+	if err != nil {
+		return errors.Wrap(err, "failed to get apps in backup")
+	}
+	if len(appSlugs) == 0 {
+		return errors.New("backup does not include any apps")
+	}
+
+	informers := []appstatetypes.StatusInformer{}
+	for _, appSlug := range appSlugs {
+		a, err := store.GetStore().GetAppFromSlug(appSlug)
+		if err != nil {
+			if store.GetStore().IsNotFound(err) {
+				// app might not exist in current installation
+				continue
+			}
+			return errors.Wrapf(err, "failed to get app %s", appSlug)
+		}
+		appStatus, err := store.GetStore().GetAppStatus(a.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get status of app %s", appSlug)
+		}
+		appInformers, err := getRestoreDrillInformers(appStatus.ResourceStates, namespaceMapping)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get status informers of app %s", appSlug)
+		}
+		informers = append(informers, appInformers...)
+	}
+
+	restore := &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: veleroNamespace,
+			Name:      fmt.Sprintf("%s.drill-%s", backupName, drillID),
+			Annotations: map[string]string{
+				RestoreDrillLabel: "true",
+			},
+		},
+		Spec: velerov1.RestoreSpec{
+			BackupName:       backupName,
+			NamespaceMapping: namespaceMapping,
+			// only restore app-specific objects, and nothing cluster scoped that could affect the running apps
+			LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "kots.io/app-slug",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   appSlugs,
+					},
+				},
+			},
+			RestorePVs:              pointer.Bool(true),
+			IncludeClusterResources: pointer.Bool(false),
+		},
+	}
+
+	defer cleanupRestoreDrill(clientset, veleroClient, veleroNamespace, restore.Name, namespaceMapping)
+
+	// the scratch namespaces are created before the restore so that they're labelled and can be found
+	// and deleted if the drill is interrupted
+	if err := createRestoreDrillNamespaces(ctx, clientset, backupName, namespaceMapping); err != nil {
+		return err
+	}
+
+	if _, err := veleroClient.Restores(veleroNamespace).Create(ctx, restore, metav1.CreateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to create restore")
+	}
+
+	if err := waitForRestoreDrillRestore(ctx, veleroClient, veleroNamespace, restore.Name); err != nil {
+		return err
+	}
+
+	if err := waitForRestoreDrillInformers(ctx, clientset, informers); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func waitForRestoreDrillRestore(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, restoreName string) error {
+	for {
+		restore, err := veleroClient.Restores(veleroNamespace).Get(ctx, restoreName, metav1.GetOptions{})
+		if ctx.Err() != nil {
+			return errors.Errorf("timed out after %s waiting for restore %s to complete", RestoreDrillTimeout, restoreName)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to get restore")
+		}
+
+		switch restore.Status.Phase {
+		case velerov1.RestorePhaseCompleted:
+			return nil
+		case velerov1.RestorePhaseFailed, velerov1.RestorePhaseFailedValidation:
+			return errors.Errorf("restore %s failed: %s", restoreName, restore.Status.FailureReason)
+		case velerov1.RestorePhasePartiallyFailed:
+			return errors.Errorf("restore %s partially failed with %d errors", restoreName, restore.Status.Errors)
+		}
+
+		select {
+		case <-time.After(restoreDrillInterval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func waitForRestoreDrillInformers(ctx context.Context, clientset kubernetes.Interface, informers []appstatetypes.StatusInformer) error {
+	for {
+		notReady := []string{}
+		for _, informer := range informers {
+			state, err := appstate.GetResourceState(ctx, clientset, informer)
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil {
+				return errors.Wrap(err, "failed to get resource state")
+			}
+			if state != appstatetypes.StateReady {
+				notReady = append(notReady, fmt.Sprintf("%s/%s is %s", informer.Kind, informer.Name, state))
+			}
+		}
+		if ctx.Err() != nil {
+			return errors.Errorf("timed out after %s waiting for status informers to be ready: %s", RestoreDrillTimeout, strings.Join(notReady, ", "))
+		}
+		if len(notReady) == 0 {
+			return nil
+		}
+
+		select {
+		case <-time.After(restoreDrillInterval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func createRestoreDrillNamespaces(ctx context.Context, clientset kubernetes.Interface, backupName string, namespaceMapping map[string]string) error {
+	for _, name := range namespaceMapping {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					RestoreDrillLabel: "true",
+				},
+				Annotations: map[string]string{
+					RestoreDrillBackupAnnotation: backupName,
+				},
+			},
+		}
+		if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to create namespace %s", name)
+		}
+	}
+	return nil
+}
+
+// cleanupRestoreDrill deletes the scratch namespaces and the restore. It's best effort, errors are logged.
+// Namespaces that were not created by a restore drill are left in place.
+func cleanupRestoreDrill(clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, restoreName string, namespaceMapping map[string]string) {
+	ctx := context.Background()
+
+	for _, namespace := range namespaceMapping {
+		ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get restore drill namespace %s", namespace))
+			continue
+		}
+		if ns.Labels[RestoreDrillLabel] != "true" {
+			continue
+		}
+		err = clientset.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			logger.Error(errors.Wrapf(err, "failed to delete restore drill namespace %s", namespace))
+		}
+	}
+
+	err := veleroClient.Restores(veleroNamespace).Delete(ctx, restoreName, metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		logger.Error(errors.Wrapf(err, "failed to delete restore drill restore %s", restoreName))
+	}
+}
+
+func recordRestoreDrill(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, backupName string, drill types.RestoreDrill) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": drill.Annotations(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patch")
+	}
+
+	_, err = veleroClient.Backups(veleroNamespace).Patch(ctx, backupName, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to patch backup")
+	}
+
+	return nil
+}
+
+// getRestoreDrillNamespaceMapping maps every namespace in the backup to a scratch namespace for the drill
+func getRestoreDrillNamespaceMapping(namespaces []string, drillID string) (map[string]string, error) {
+	suffix := fmt.Sprintf("-drill-%s", drillID)
+
+	namespaceMapping := map[string]string{}
+	for _, namespace := range namespaces {
+		if namespace == "*" {
+			return nil, errors.New("backups of all namespaces cannot be drilled")
+		}
+		prefix := namespace
+		if len(prefix)+len(suffix) > validation.DNS1123LabelMaxLength {
+			prefix = strings.TrimRight(prefix[:validation.DNS1123LabelMaxLength-len(suffix)], "-")
+		}
+		namespaceMapping[namespace] = prefix + suffix
+	}
+
+	return namespaceMapping, nil
+}
+
+// getRestoreDrillInformers points the status informers of an app at the scratch namespaces
+func getRestoreDrillInformers(resourceStates appstatetypes.ResourceStates, namespaceMapping map[string]string) ([]appstatetypes.StatusInformer, error) {
+	informers := []appstatetypes.StatusInformer{}
+	for _, resourceState := range resourceStates {
+		if resourceState.Kind == "EMPTY" {
+			// app has no status informers
+			continue
+		}
+		namespace, ok := namespaceMapping[resourceState.Namespace]
+		if !ok {
+			return nil, errors.Errorf("namespace %s of %s/%s is not included in the backup", resourceState.Namespace, resourceState.Kind, resourceState.Name)
+		}
+		informers = append(informers, appstatetypes.StatusInformer{
+			Kind:      resourceState.Kind,
+			Name:      resourceState.Name,
+			Namespace: namespace,
+		})
+	}
+	return informers, nil
+}
+
+func getBackupAppSlugs(backup *velerov1.Backup) ([]string, error) {
+	appSlugs := []string{}
+
+	appAnnotationStr := backup.Annotations["kots.io/apps-sequences"]
+	if len(appAnnotationStr) == 0 {
+		return appSlugs, nil
+	}
+
+	var apps map[string]int64
+	if err := json.Unmarshal([]byte(appAnnotationStr), &apps); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal apps sequences")
+	}
+	for slug := range apps {
+		appSlugs = append(appSlugs, slug)
+	}
+	sort.Strings(appSlugs)
+
+	return appSlugs, nil
+}
+
+// isRestoreDrillRunning ignores drills that have been running for much longer than the timeout,
+// e.g. when kotsadm restarted before the result was recorded and CleanupStaleRestoreDrills hasn't run yet
+func isRestoreDrillRunning(drill *types.RestoreDrill, now time.Time) bool {
+	if drill == nil || drill.Status != types.RestoreDrillStatusRunning {
+		return false
+	}
+	if drill.StartedAt == nil {
+		return false
+	}
+	return now.Sub(*drill.StartedAt) < 2*RestoreDrillTimeout
+}
+
+func latestCompletedBackup(backups []*types.Backup) *types.Backup {
+	var latest *types.Backup
+	for _, backup := range backups {
+		if backup.Status != string(velerov1.BackupPhaseCompleted) || backup.StartedAt == nil {
+			continue
+		}
+		if latest == nil || backup.StartedAt.After(*latest.StartedAt) {
+			latest = backup
+		}
+	}
+	return latest
+}
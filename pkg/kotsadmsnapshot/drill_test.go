@@ -0,0 +1,276 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerofake "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_getRestoreDrillNamespaceMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "app and additional namespaces",
+			namespaces: []string{"default", "monitoring"},
+			want: map[string]string{
+				"default":    "default-drill-abcde",
+				"monitoring": "monitoring-drill-abcde",
+			},
+		},
+		{
+			name:       "long namespace is truncated",
+			namespaces: []string{"a-very-long-namespace-name-that-is-close-to-the-max-length-abc"},
+			want: map[string]string{
+				"a-very-long-namespace-name-that-is-close-to-the-max-length-abc": "a-very-long-namespace-name-that-is-close-to-the-max-drill-abcde",
+			},
+		},
+		{
+			name:       "all namespaces",
+			namespaces: []string{"default", "*"},
+			wantErr:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getRestoreDrillNamespaceMapping(test.namespaces, "abcde")
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			for _, namespace := range got {
+				assert.LessOrEqual(t, len(namespace), 63)
+			}
+		})
+	}
+}
+
+func Test_getRestoreDrillInformers(t *testing.T) {
+	namespaceMapping := map[string]string{
+		"default":    "default-drill-abcde",
+		"monitoring": "monitoring-drill-abcde",
+	}
+
+	tests := []struct {
+		name           string
+		resourceStates appstatetypes.ResourceStates
+		want           []appstatetypes.StatusInformer
+		wantErr        string
+	}{
+		{
+			name: "informers are mapped to the scratch namespaces",
+			resourceStates: appstatetypes.ResourceStates{
+				{Kind: "deployment", Name: "web", Namespace: "default", State: appstatetypes.StateReady},
+				{Kind: "statefulset", Name: "prometheus", Namespace: "monitoring", State: appstatetypes.StateDegraded},
+			},
+			want: []appstatetypes.StatusInformer{
+				{Kind: "deployment", Name: "web", Namespace: "default-drill-abcde"},
+				{Kind: "statefulset", Name: "prometheus", Namespace: "monitoring-drill-abcde"},
+			},
+		},
+		{
+			name: "app without status informers",
+			resourceStates: appstatetypes.ResourceStates{
+				{Kind: "EMPTY", Name: "EMPTY", Namespace: "EMPTY", State: appstatetypes.StateReady},
+			},
+			want: []appstatetypes.StatusInformer{},
+		},
+		{
+			name: "namespace not in backup",
+			resourceStates: appstatetypes.ResourceStates{
+				{Kind: "deployment", Name: "web", Namespace: "other", State: appstatetypes.StateReady},
+			},
+			wantErr: "namespace other of deployment/web is not included in the backup",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getRestoreDrillInformers(test.resourceStates, namespaceMapping)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func Test_isRestoreDrillRunning(t *testing.T) {
+	now := time.Now()
+	startedAt := now.Add(-time.Minute)
+	staleStartedAt := now.Add(-3 * RestoreDrillTimeout)
+
+	tests := []struct {
+		name  string
+		drill *types.RestoreDrill
+		want  bool
+	}{
+		{
+			name:  "never drilled",
+			drill: nil,
+			want:  false,
+		},
+		{
+			name:  "running",
+			drill: &types.RestoreDrill{Status: types.RestoreDrillStatusRunning, StartedAt: &startedAt},
+			want:  true,
+		},
+		{
+			name:  "finished",
+			drill: &types.RestoreDrill{Status: types.RestoreDrillStatusPassed, StartedAt: &startedAt, FinishedAt: &now},
+			want:  false,
+		},
+		{
+			name:  "result never recorded",
+			drill: &types.RestoreDrill{Status: types.RestoreDrillStatusRunning, StartedAt: &staleStartedAt},
+			want:  false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, isRestoreDrillRunning(test.drill, now))
+		})
+	}
+}
+
+func Test_latestCompletedBackup(t *testing.T) {
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	third := second.Add(24 * time.Hour)
+
+	backups := []*types.Backup{
+		{Name: "first", Status: "Completed", StartedAt: &first},
+		{Name: "second", Status: "Completed", StartedAt: &second},
+		{Name: "third", Status: "PartiallyFailed", StartedAt: &third},
+		{Name: "new", Status: "New"},
+	}
+
+	assert.Equal(t, "second", latestCompletedBackup(backups).Name)
+	assert.Nil(t, latestCompletedBackup(backups[2:]))
+}
+
+func Test_cleanupRestoreDrill(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default-drill-abcde", Labels: map[string]string{RestoreDrillLabel: "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system-drill-abcde"}},
+	)
+	veleroClientset := velerofake.NewSimpleClientset(
+		&velerov1.Restore{ObjectMeta: metav1.ObjectMeta{Name: "instance-abc.drill-abcde", Namespace: "velero"}},
+	)
+
+	namespaceMapping := map[string]string{
+		"default":     "default-drill-abcde",
+		"monitoring":  "monitoring-drill-abcde",
+		"kube-system": "kube-system-drill-abcde",
+	}
+	cleanupRestoreDrill(clientset, veleroClientset.VeleroV1(), "velero", "instance-abc.drill-abcde", namespaceMapping)
+
+	_, err := clientset.CoreV1().Namespaces().Get(context.Background(), "default", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// not created by the drill
+	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), "kube-system-drill-abcde", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), "default-drill-abcde", metav1.GetOptions{})
+	assert.True(t, kuberneteserrors.IsNotFound(err))
+
+	_, err = veleroClientset.VeleroV1().Restores("velero").Get(context.Background(), "instance-abc.drill-abcde", metav1.GetOptions{})
+	assert.True(t, kuberneteserrors.IsNotFound(err))
+}
+
+func Test_cleanupStaleRestoreDrills(t *testing.T) {
+	startedAt := time.Now().Add(-time.Minute)
+	finishedAt := time.Now()
+
+	drillNamespace := func(name string, backupName string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      map[string]string{RestoreDrillLabel: "true"},
+				Annotations: map[string]string{RestoreDrillBackupAnnotation: backupName},
+			},
+		}
+	}
+	drillRestore := func(name string, backupName string) *velerov1.Restore {
+		return &velerov1.Restore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "velero",
+				Annotations: map[string]string{RestoreDrillLabel: "true"},
+			},
+			Spec: velerov1.RestoreSpec{BackupName: backupName},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		drillNamespace("default-drill-abcde", "instance-interrupted"),
+		drillNamespace("default-drill-fghij", "instance-running"),
+	)
+	veleroClientset := velerofake.NewSimpleClientset(
+		&velerov1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "instance-interrupted", Namespace: "velero"}},
+		&velerov1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "instance-running", Namespace: "velero"}},
+		&velerov1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "instance-passed", Namespace: "velero"}},
+		drillRestore("instance-interrupted.drill-abcde", "instance-interrupted"),
+		drillRestore("instance-running.drill-fghij", "instance-running"),
+		&velerov1.Restore{ObjectMeta: metav1.ObjectMeta{Name: "instance-passed.restore", Namespace: "velero"}, Spec: velerov1.RestoreSpec{BackupName: "instance-passed"}},
+	)
+
+	backups := []*types.Backup{
+		{Name: "instance-interrupted", RestoreDrill: &types.RestoreDrill{Status: types.RestoreDrillStatusRunning, StartedAt: &startedAt}},
+		{Name: "instance-running", RestoreDrill: &types.RestoreDrill{Status: types.RestoreDrillStatusRunning, StartedAt: &startedAt}},
+		{Name: "instance-passed", RestoreDrill: &types.RestoreDrill{Status: types.RestoreDrillStatusPassed, StartedAt: &startedAt, FinishedAt: &finishedAt}},
+	}
+
+	setRestoreDrillInProgress("instance-running", true)
+	defer setRestoreDrillInProgress("instance-running", false)
+
+	err := cleanupStaleRestoreDrills(context.Background(), clientset, veleroClientset.VeleroV1(), "velero", backups)
+	require.NoError(t, err)
+
+	getDrill := func(backupName string) *types.RestoreDrill {
+		backup, err := veleroClientset.VeleroV1().Backups("velero").Get(context.Background(), backupName, metav1.GetOptions{})
+		require.NoError(t, err)
+		return types.GetRestoreDrill(backup.Annotations)
+	}
+
+	interrupted := getDrill("instance-interrupted")
+	require.NotNil(t, interrupted)
+	assert.Equal(t, types.RestoreDrillStatusFailed, interrupted.Status)
+	assert.NotNil(t, interrupted.FinishedAt)
+	assert.Nil(t, getDrill("instance-running"))
+	assert.Nil(t, getDrill("instance-passed"))
+
+	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), "default-drill-abcde", metav1.GetOptions{})
+	assert.True(t, kuberneteserrors.IsNotFound(err))
+	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), "default-drill-fghij", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = clientset.CoreV1().Namespaces().Get(context.Background(), "default", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	_, err = veleroClientset.VeleroV1().Restores("velero").Get(context.Background(), "instance-interrupted.drill-abcde", metav1.GetOptions{})
+	assert.True(t, kuberneteserrors.IsNotFound(err))
+	_, err = veleroClientset.VeleroV1().Restores("velero").Get(context.Background(), "instance-running.drill-fghij", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = veleroClientset.VeleroV1().Restores("velero").Get(context.Background(), "instance-passed.restore", metav1.GetOptions{})
+	require.NoError(t, err)
+}
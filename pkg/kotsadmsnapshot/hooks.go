@@ -0,0 +1,319 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	BackupHookPhasePre  = "pre"
+	BackupHookPhasePost = "post"
+
+	BackupHookOnErrorFail     = "fail"
+	BackupHookOnErrorContinue = "continue"
+)
+
+// prepareBackupHooks translates the backup hooks declared in an Application spec into velero backup hooks.
+// Every hook is checked against the pods that exist before the backup starts. A hook with no running pods to run in,
+// e.g. because its workload is scaled down, is left out of the backup so that it doesn't prevent scheduled backups from
+// being taken. It's reported as skipped if its onError is "continue", otherwise as failed, and the backup is reported
+// as partially failed once velero completes it.
+// Hooks can only run in the namespaces included in the backup, the app namespace and the additional namespaces of the app.
+func prepareBackupHooks(ctx context.Context, clientset kubernetes.Interface, appSlug string, appNamespace string, additionalNamespaces []string, hooks []kotsv1beta1.BackupHook) ([]velerov1.BackupResourceHookSpec, []types.DeclaredBackupHook, error) {
+	resourceHooks := []velerov1.BackupResourceHookSpec{}
+	declaredHooks := []types.DeclaredBackupHook{}
+
+	for _, hook := range hooks {
+		resourceHook, declared, err := translateBackupHook(appSlug, appNamespace, hook)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid backup hook %q", hook.Name)
+		}
+		if !isBackupHookNamespaceIncluded(declared.Namespace, appNamespace, additionalNamespaces) {
+			// velero never runs hooks in namespaces that are not included in the backup
+			return nil, nil, errors.Errorf("invalid backup hook %q: namespace %s is not the app namespace or one of its additional namespaces", hook.Name, declared.Namespace)
+		}
+
+		pods, err := findBackupHookPods(ctx, clientset, declared.Namespace, hook.Selector, hook.Container)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to find pods for backup hook %q", hook.Name)
+		}
+		declared.Pods = pods
+
+		if len(pods) == 0 {
+			validationError := "no running pods match the selector"
+			if hook.Container != "" {
+				validationError = fmt.Sprintf("no running pods with container %s match the selector", hook.Container)
+			}
+			if declared.OnError == BackupHookOnErrorFail {
+				logger.Errorf("Backup hook %q in namespace %s cannot run, the backup will be partially failed: %s", hook.Name, declared.Namespace, validationError)
+			} else {
+				logger.Warnf("Skipping backup hook %q in namespace %s: %s", hook.Name, declared.Namespace, validationError)
+			}
+			declared.ValidationError = validationError
+			declaredHooks = append(declaredHooks, declared)
+			continue
+		}
+
+		resourceHooks = append(resourceHooks, resourceHook)
+		declaredHooks = append(declaredHooks, declared)
+	}
+
+	return resourceHooks, declaredHooks, nil
+}
+
+func translateBackupHook(appSlug string, appNamespace string, hook kotsv1beta1.BackupHook) (velerov1.BackupResourceHookSpec, types.DeclaredBackupHook, error) {
+	if hook.Name == "" {
+		return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.New("name is required")
+	}
+	if len(hook.Command) == 0 {
+		return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.New("command is required")
+	}
+	if len(hook.Selector) == 0 {
+		// an empty selector would run the hook in every pod in the namespace
+		return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.New("selector is required")
+	}
+
+	phase := strings.ToLower(hook.Phase)
+	if phase == "" {
+		phase = BackupHookPhasePre
+	}
+	if phase != BackupHookPhasePre && phase != BackupHookPhasePost {
+		return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.Errorf("invalid phase %q, must be %q or %q", hook.Phase, BackupHookPhasePre, BackupHookPhasePost)
+	}
+
+	onError := strings.ToLower(hook.OnError)
+	if onError == "" {
+		onError = BackupHookOnErrorFail
+	}
+	errorMode := velerov1.HookErrorModeFail
+	switch onError {
+	case BackupHookOnErrorFail:
+	case BackupHookOnErrorContinue:
+		errorMode = velerov1.HookErrorModeContinue
+	default:
+		return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.Errorf("invalid onError %q, must be %q or %q", hook.OnError, BackupHookOnErrorFail, BackupHookOnErrorContinue)
+	}
+
+	timeout := time.Duration(0)
+	if hook.Timeout != "" {
+		d, err := time.ParseDuration(hook.Timeout)
+		if err != nil {
+			return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.Wrapf(err, "failed to parse timeout %q", hook.Timeout)
+		}
+		if d <= 0 {
+			return velerov1.BackupResourceHookSpec{}, types.DeclaredBackupHook{}, errors.Errorf("timeout %q must be positive", hook.Timeout)
+		}
+		timeout = d
+	}
+
+	namespace := hook.Namespace
+	if namespace == "" {
+		namespace = appNamespace
+	}
+
+	name := fmt.Sprintf("%s-%s", appSlug, hook.Name)
+
+	resourceHook := velerov1.BackupResourceHookSpec{
+		Name:               name,
+		IncludedNamespaces: []string{namespace},
+		IncludedResources:  []string{"pods"},
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: hook.Selector,
+		},
+	}
+
+	execHook := velerov1.BackupResourceHook{
+		Exec: &velerov1.ExecHook{
+			Container: hook.Container,
+			Command:   hook.Command,
+			OnError:   errorMode,
+			Timeout:   metav1.Duration{Duration: timeout},
+		},
+	}
+	if phase == BackupHookPhasePre {
+		resourceHook.PreHooks = []velerov1.BackupResourceHook{execHook}
+	} else {
+		resourceHook.PostHooks = []velerov1.BackupResourceHook{execHook}
+	}
+
+	declared := types.DeclaredBackupHook{
+		Name:      name,
+		App:       appSlug,
+		Phase:     phase,
+		Namespace: namespace,
+		OnError:   onError,
+	}
+
+	return resourceHook, declared, nil
+}
+
+func isBackupHookNamespaceIncluded(namespace string, appNamespace string, additionalNamespaces []string) bool {
+	if namespace == appNamespace {
+		return true
+	}
+	for _, additionalNamespace := range additionalNamespaces {
+		if additionalNamespace == namespace || additionalNamespace == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// findBackupHookPods returns the sorted names of the running pods a backup hook will run in
+func findBackupHookPods(ctx context.Context, clientset kubernetes.Interface, namespace string, selector map[string]string, container string) ([]string, error) {
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	pods := []string{}
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if container != "" && !podHasContainer(pod, container) {
+			continue
+		}
+		pods = append(pods, pod.Name)
+	}
+	sort.Strings(pods)
+
+	return pods, nil
+}
+
+func podHasContainer(pod corev1.Pod, container string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return true
+		}
+	}
+	return false
+}
+
+// getBackupHookStatuses returns the status of every backup hook declared for the backup.
+// Execs are the hook executions parsed from the backup logs, nil if the logs are not available.
+// Until then, hooks are reported as executed in a pod once velero has moved past them in that pod:
+// pre hooks run before the pod volume backups are created, post hooks run after they have finished.
+func getBackupHookStatuses(backup *velerov1.Backup, podVolumeBackups []velerov1.PodVolumeBackup, execs []*types.SnapshotHook) []types.BackupHookStatus {
+	statuses := []types.BackupHookStatus{}
+
+	for _, declared := range types.GetDeclaredBackupHooks(backup.Annotations) {
+		status := types.BackupHookStatus{
+			Name:      declared.Name,
+			App:       declared.App,
+			Phase:     declared.Phase,
+			Namespace: declared.Namespace,
+			OnError:   declared.OnError,
+			State:     types.BackupHookStatePending,
+			Pods:      declared.Pods,
+		}
+		if status.Pods == nil {
+			status.Pods = []string{}
+		}
+
+		switch {
+		case declared.ValidationError != "" && declared.OnError == BackupHookOnErrorFail:
+			status.State = types.BackupHookStateFailed
+			status.Message = declared.ValidationError
+
+		case declared.ValidationError != "":
+			status.State = types.BackupHookStateSkipped
+			status.Message = declared.ValidationError
+
+		case execs != nil:
+			setBackupHookStatusFromExecs(&status, execs)
+
+		default:
+			executed := 0
+			for _, pod := range declared.Pods {
+				if isBackupHookExecutedInPod(declared, pod, podVolumeBackups) {
+					executed++
+				}
+			}
+			if executed > 0 {
+				status.State = types.BackupHookStateExecuted
+				status.Message = fmt.Sprintf("executed in %d of %d pods", executed, len(declared.Pods))
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// getBackupStatus returns the status of a backup as reported to the user.
+// A backup that velero completed is partially failed if one of its hooks with onError "fail" couldn't run.
+func getBackupStatus(backup *velerov1.Backup) string {
+	if backup.Status.Phase != velerov1.BackupPhaseCompleted {
+		return string(backup.Status.Phase)
+	}
+	for _, declared := range types.GetDeclaredBackupHooks(backup.Annotations) {
+		if declared.ValidationError != "" && declared.OnError == BackupHookOnErrorFail {
+			return string(velerov1.BackupPhasePartiallyFailed)
+		}
+	}
+	return string(backup.Status.Phase)
+}
+
+func setBackupHookStatusFromExecs(status *types.BackupHookStatus, execs []*types.SnapshotHook) {
+	found := false
+	for _, exec := range execs {
+		if exec.Name != status.Name || exec.Namespace != status.Namespace || exec.Phase != status.Phase {
+			continue
+		}
+		found = true
+		if len(exec.Errors) > 0 {
+			status.State = types.BackupHookStateFailed
+			status.Message = fmt.Sprintf("pod %s: %s", exec.PodName, getSnapshotErrorMessage(exec.Errors[0]))
+			return
+		}
+	}
+
+	if !found {
+		status.State = types.BackupHookStateNotRun
+		return
+	}
+
+	status.State = types.BackupHookStateSucceeded
+}
+
+func getSnapshotErrorMessage(snapshotError types.SnapshotError) string {
+	if snapshotError.Message == "" {
+		return snapshotError.Title
+	}
+	return fmt.Sprintf("%s: %s", snapshotError.Title, snapshotError.Message)
+}
+
+func isBackupHookExecutedInPod(declared types.DeclaredBackupHook, pod string, podVolumeBackups []velerov1.PodVolumeBackup) bool {
+	podVolumeBackupsFound := false
+	for _, podVolumeBackup := range podVolumeBackups {
+		if podVolumeBackup.Spec.Pod.Namespace != declared.Namespace || podVolumeBackup.Spec.Pod.Name != pod {
+			continue
+		}
+		podVolumeBackupsFound = true
+
+		if declared.Phase != BackupHookPhasePost {
+			return true
+		}
+		if podVolumeBackup.Status.Phase != velerov1.PodVolumeBackupPhaseCompleted && podVolumeBackup.Status.Phase != velerov1.PodVolumeBackupPhaseFailed {
+			return false
+		}
+	}
+	return podVolumeBackupsFound
+}
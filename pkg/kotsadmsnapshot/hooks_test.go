@@ -0,0 +1,456 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_translateBackupHook(t *testing.T) {
+	tests := []struct {
+		name             string
+		hook             kotsv1beta1.BackupHook
+		wantResourceHook velerov1.BackupResourceHookSpec
+		wantDeclared     types.DeclaredBackupHook
+		wantErr          bool
+	}{
+		{
+			name: "defaults",
+			hook: kotsv1beta1.BackupHook{
+				Name:     "pg-dump",
+				Selector: map[string]string{"app": "postgres"},
+				Command:  []string{"/bin/sh", "-c", "pg_dump > /backup/dump.sql"},
+			},
+			wantResourceHook: velerov1.BackupResourceHookSpec{
+				Name:               "my-app-pg-dump",
+				IncludedNamespaces: []string{"app-namespace"},
+				IncludedResources:  []string{"pods"},
+				LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"app": "postgres"}},
+				PreHooks: []velerov1.BackupResourceHook{
+					{
+						Exec: &velerov1.ExecHook{
+							Command: []string{"/bin/sh", "-c", "pg_dump > /backup/dump.sql"},
+							OnError: velerov1.HookErrorModeFail,
+						},
+					},
+				},
+			},
+			wantDeclared: types.DeclaredBackupHook{
+				Name:      "my-app-pg-dump",
+				App:       "my-app",
+				Phase:     "pre",
+				Namespace: "app-namespace",
+				OnError:   "fail",
+			},
+		},
+		{
+			name: "post hook with every field set",
+			hook: kotsv1beta1.BackupHook{
+				Name:      "cleanup",
+				Phase:     "Post",
+				Namespace: "db",
+				Selector:  map[string]string{"app": "postgres"},
+				Container: "postgres",
+				Command:   []string{"rm", "/backup/dump.sql"},
+				Timeout:   "5m",
+				OnError:   "Continue",
+			},
+			wantResourceHook: velerov1.BackupResourceHookSpec{
+				Name:               "my-app-cleanup",
+				IncludedNamespaces: []string{"db"},
+				IncludedResources:  []string{"pods"},
+				LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"app": "postgres"}},
+				PostHooks: []velerov1.BackupResourceHook{
+					{
+						Exec: &velerov1.ExecHook{
+							Container: "postgres",
+							Command:   []string{"rm", "/backup/dump.sql"},
+							OnError:   velerov1.HookErrorModeContinue,
+							Timeout:   metav1.Duration{Duration: 5 * time.Minute},
+						},
+					},
+				},
+			},
+			wantDeclared: types.DeclaredBackupHook{
+				Name:      "my-app-cleanup",
+				App:       "my-app",
+				Phase:     "post",
+				Namespace: "db",
+				OnError:   "continue",
+			},
+		},
+		{
+			name:    "missing name",
+			hook:    kotsv1beta1.BackupHook{Selector: map[string]string{"app": "postgres"}, Command: []string{"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing command",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", Selector: map[string]string{"app": "postgres"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing selector",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", Command: []string{"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid phase",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", Phase: "during", Selector: map[string]string{"app": "postgres"}, Command: []string{"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid onError",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", OnError: "ignore", Selector: map[string]string{"app": "postgres"}, Command: []string{"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timeout",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", Timeout: "ten minutes", Selector: map[string]string{"app": "postgres"}, Command: []string{"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			hook:    kotsv1beta1.BackupHook{Name: "hook", Timeout: "-1m", Selector: map[string]string{"app": "postgres"}, Command: []string{"true"}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resourceHook, declared, err := translateBackupHook("my-app", "app-namespace", test.hook)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantResourceHook, resourceHook)
+			assert.Equal(t, test.wantDeclared, declared)
+		})
+	}
+}
+
+func Test_prepareBackupHooks(t *testing.T) {
+	postgresPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "app-namespace",
+				Labels:    map[string]string{"app": "postgres"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "postgres"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	redisPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis-0",
+			Namespace: "cache",
+			Labels:    map[string]string{"app": "redis"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "redis"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+
+	tests := []struct {
+		name              string
+		pods              []*corev1.Pod
+		hooks             []kotsv1beta1.BackupHook
+		wantResourceHooks []string
+		wantDeclared      []types.DeclaredBackupHook
+		wantErr           bool
+	}{
+		{
+			name: "matching running pods",
+			pods: []*corev1.Pod{
+				postgresPod("postgres-1", corev1.PodRunning),
+				postgresPod("postgres-0", corev1.PodRunning),
+				postgresPod("postgres-2", corev1.PodPending),
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "pg-dump", Selector: map[string]string{"app": "postgres"}, Container: "postgres", Command: []string{"pg_dump"}},
+			},
+			wantResourceHooks: []string{"my-app-pg-dump"},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-pg-dump", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "fail", Pods: []string{"postgres-0", "postgres-1"}},
+			},
+		},
+		{
+			name: "no matching pods with onError continue is skipped",
+			pods: []*corev1.Pod{
+				postgresPod("postgres-0", corev1.PodRunning),
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "pg-dump", Selector: map[string]string{"app": "postgres"}, Container: "postgres", Command: []string{"pg_dump"}},
+				{Name: "redis-save", Selector: map[string]string{"app": "redis"}, Command: []string{"redis-cli", "save"}, OnError: "continue"},
+			},
+			wantResourceHooks: []string{"my-app-pg-dump"},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-pg-dump", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "fail", Pods: []string{"postgres-0"}},
+				{Name: "my-app-redis-save", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "continue", Pods: []string{}, ValidationError: "no running pods match the selector"},
+			},
+		},
+		{
+			name: "no matching pods with onError fail is left out",
+			pods: []*corev1.Pod{
+				postgresPod("postgres-0", corev1.PodRunning),
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "redis-save", Selector: map[string]string{"app": "redis"}, Command: []string{"redis-cli", "save"}},
+			},
+			wantResourceHooks: []string{},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-redis-save", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "fail", Pods: []string{}, ValidationError: "no running pods match the selector"},
+			},
+		},
+		{
+			name: "scaled down workload with onError fail is left out",
+			pods: []*corev1.Pod{
+				postgresPod("postgres-0", corev1.PodSucceeded),
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "pg-dump", Selector: map[string]string{"app": "postgres"}, Command: []string{"pg_dump"}},
+			},
+			wantResourceHooks: []string{},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-pg-dump", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "fail", Pods: []string{}, ValidationError: "no running pods match the selector"},
+			},
+		},
+		{
+			name: "missing container with onError fail is left out",
+			pods: []*corev1.Pod{
+				postgresPod("postgres-0", corev1.PodRunning),
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "pg-dump", Selector: map[string]string{"app": "postgres"}, Container: "sidecar", Command: []string{"pg_dump"}},
+			},
+			wantResourceHooks: []string{},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-pg-dump", App: "my-app", Phase: "pre", Namespace: "app-namespace", OnError: "fail", Pods: []string{}, ValidationError: "no running pods with container sidecar match the selector"},
+			},
+		},
+		{
+			name: "additional namespace",
+			pods: []*corev1.Pod{
+				redisPod,
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "redis-save", Namespace: "cache", Selector: map[string]string{"app": "redis"}, Command: []string{"redis-cli", "save"}},
+			},
+			wantResourceHooks: []string{"my-app-redis-save"},
+			wantDeclared: []types.DeclaredBackupHook{
+				{Name: "my-app-redis-save", App: "my-app", Phase: "pre", Namespace: "cache", OnError: "fail", Pods: []string{"redis-0"}},
+			},
+		},
+		{
+			name: "namespace not included in the backup",
+			pods: []*corev1.Pod{
+				redisPod,
+			},
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "redis-save", Namespace: "other", Selector: map[string]string{"app": "redis"}, Command: []string{"redis-cli", "save"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid hook",
+			hooks: []kotsv1beta1.BackupHook{
+				{Name: "pg-dump", Selector: map[string]string{"app": "postgres"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, pod := range test.pods {
+				_, err := clientset.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			resourceHooks, declared, err := prepareBackupHooks(context.Background(), clientset, "my-app", "app-namespace", []string{"cache"}, test.hooks)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			resourceHookNames := []string{}
+			for _, resourceHook := range resourceHooks {
+				resourceHookNames = append(resourceHookNames, resourceHook.Name)
+			}
+			assert.Equal(t, test.wantResourceHooks, resourceHookNames)
+			assert.Equal(t, test.wantDeclared, declared)
+		})
+	}
+}
+
+func Test_getBackupHookStatuses(t *testing.T) {
+	declared := []types.DeclaredBackupHook{
+		{Name: "my-app-pg-dump", App: "my-app", Phase: "pre", Namespace: "app", OnError: "fail", Pods: []string{"postgres-0", "postgres-1"}},
+		{Name: "my-app-cleanup", App: "my-app", Phase: "post", Namespace: "app", OnError: "continue", Pods: []string{"postgres-0"}},
+		{Name: "my-app-redis-save", App: "my-app", Phase: "pre", Namespace: "app", OnError: "continue", ValidationError: "no running pods match the selector"},
+		{Name: "my-app-mysql-dump", App: "my-app", Phase: "pre", Namespace: "app", OnError: "fail", ValidationError: "no running pods match the selector"},
+	}
+	annotations, err := types.BackupHooksAnnotations(declared)
+	require.NoError(t, err)
+
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: annotations,
+		},
+	}
+
+	podVolumeBackup := func(pod string, phase velerov1.PodVolumeBackupPhase) velerov1.PodVolumeBackup {
+		return velerov1.PodVolumeBackup{
+			Spec: velerov1.PodVolumeBackupSpec{
+				Pod: corev1.ObjectReference{Namespace: "app", Name: pod},
+			},
+			Status: velerov1.PodVolumeBackupStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		podVolumeBackups []velerov1.PodVolumeBackup
+		execs            []*types.SnapshotHook
+		wantStates       []types.BackupHookState
+		wantMessages     []string
+	}{
+		{
+			name:         "not started",
+			wantStates:   []types.BackupHookState{types.BackupHookStatePending, types.BackupHookStatePending, types.BackupHookStateSkipped, types.BackupHookStateFailed},
+			wantMessages: []string{"", "", "no running pods match the selector", "no running pods match the selector"},
+		},
+		{
+			name: "pre hook executed in one pod, volumes in progress",
+			podVolumeBackups: []velerov1.PodVolumeBackup{
+				podVolumeBackup("postgres-0", velerov1.PodVolumeBackupPhaseInProgress),
+			},
+			wantStates:   []types.BackupHookState{types.BackupHookStateExecuted, types.BackupHookStatePending, types.BackupHookStateSkipped, types.BackupHookStateFailed},
+			wantMessages: []string{"executed in 1 of 2 pods", "", "no running pods match the selector", "no running pods match the selector"},
+		},
+		{
+			name: "volumes finished",
+			podVolumeBackups: []velerov1.PodVolumeBackup{
+				podVolumeBackup("postgres-0", velerov1.PodVolumeBackupPhaseCompleted),
+				podVolumeBackup("postgres-1", velerov1.PodVolumeBackupPhaseFailed),
+			},
+			wantStates:   []types.BackupHookState{types.BackupHookStateExecuted, types.BackupHookStateExecuted, types.BackupHookStateSkipped, types.BackupHookStateFailed},
+			wantMessages: []string{"executed in 2 of 2 pods", "executed in 1 of 1 pods", "no running pods match the selector", "no running pods match the selector"},
+		},
+		{
+			name: "finished",
+			execs: []*types.SnapshotHook{
+				{Name: "my-app-pg-dump", Namespace: "app", Phase: "pre", PodName: "postgres-0"},
+				{Name: "my-app-pg-dump", Namespace: "app", Phase: "pre", PodName: "postgres-1", Errors: []types.SnapshotError{{Title: "Error executing hook", Message: "command terminated with exit code 1"}}},
+				{Name: "other-hook", Namespace: "app", Phase: "post", PodName: "postgres-0"},
+			},
+			wantStates:   []types.BackupHookState{types.BackupHookStateFailed, types.BackupHookStateNotRun, types.BackupHookStateSkipped, types.BackupHookStateFailed},
+			wantMessages: []string{"pod postgres-1: Error executing hook: command terminated with exit code 1", "", "no running pods match the selector", "no running pods match the selector"},
+		},
+		{
+			name: "finished successfully",
+			execs: []*types.SnapshotHook{
+				{Name: "my-app-pg-dump", Namespace: "app", Phase: "pre", PodName: "postgres-0"},
+				{Name: "my-app-pg-dump", Namespace: "app", Phase: "pre", PodName: "postgres-1"},
+				{Name: "my-app-cleanup", Namespace: "app", Phase: "post", PodName: "postgres-0"},
+			},
+			wantStates:   []types.BackupHookState{types.BackupHookStateSucceeded, types.BackupHookStateSucceeded, types.BackupHookStateSkipped, types.BackupHookStateFailed},
+			wantMessages: []string{"", "", "no running pods match the selector", "no running pods match the selector"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statuses := getBackupHookStatuses(backup, test.podVolumeBackups, test.execs)
+
+			states := []types.BackupHookState{}
+			messages := []string{}
+			for _, status := range statuses {
+				states = append(states, status.State)
+				messages = append(messages, status.Message)
+			}
+			assert.Equal(t, test.wantStates, states)
+			assert.Equal(t, test.wantMessages, messages)
+		})
+	}
+}
+
+func Test_getBackupStatus(t *testing.T) {
+	annotations := func(declared ...types.DeclaredBackupHook) map[string]string {
+		a, err := types.BackupHooksAnnotations(declared)
+		require.NoError(t, err)
+		return a
+	}
+
+	tests := []struct {
+		name   string
+		backup *velerov1.Backup
+		want   string
+	}{
+		{
+			name: "completed without hooks",
+			backup: &velerov1.Backup{
+				Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted},
+			},
+			want: "Completed",
+		},
+		{
+			name: "completed with a skipped continue hook",
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations(types.DeclaredBackupHook{Name: "my-app-redis-save", OnError: "continue", ValidationError: "no running pods match the selector"}),
+				},
+				Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted},
+			},
+			want: "Completed",
+		},
+		{
+			name: "completed with a fail hook that couldn't run",
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations(
+						types.DeclaredBackupHook{Name: "my-app-pg-dump", OnError: "fail", Pods: []string{"postgres-0"}},
+						types.DeclaredBackupHook{Name: "my-app-redis-save", OnError: "fail", ValidationError: "no running pods match the selector"},
+					),
+				},
+				Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted},
+			},
+			want: "PartiallyFailed",
+		},
+		{
+			name: "in progress with a fail hook that couldn't run",
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations(types.DeclaredBackupHook{Name: "my-app-redis-save", OnError: "fail", ValidationError: "no running pods match the selector"}),
+				},
+				Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress},
+			},
+			want: "InProgress",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, getBackupStatus(test.backup))
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
+	snapshottypes "github.com/replicatedhq/kots/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// ReplicationRetryInterval is how long to wait before retrying a failed replication
+	ReplicationRetryInterval = time.Hour
+)
+
+// ReplicateInstanceBackups copies completed instance backups from the location they were taken in
+// to every secondary storage location whose replication policy includes them.
+// The result of each copy is recorded on the backup.
+func ReplicateInstanceBackups(ctx context.Context, kotsadmNamespace string) error {
+	store, err := kotssnapshot.GetGlobalStore(ctx, kotsadmNamespace, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get global store")
+	}
+	if store == nil || len(store.Secondaries) == 0 {
+		return nil
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if bsl == nil {
+		return errors.New("no backup store location found")
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroBackups, err := veleroClient.Backups(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero backups")
+	}
+
+	stores := map[string]*snapshottypes.Store{
+		store.Name: store,
+	}
+	for _, secondary := range store.Secondaries {
+		stores[secondary.Name] = secondary
+	}
+
+	for _, veleroBackup := range veleroBackups.Items {
+		if veleroBackup.Annotations["kots.io/instance"] != "true" {
+			continue
+		}
+		if veleroBackup.Status.Phase != velerov1.BackupPhaseCompleted {
+			continue
+		}
+
+		sourceLocation := veleroBackup.Spec.StorageLocation
+		if sourceLocation == "" {
+			sourceLocation = kotssnapshot.DefaultBackupStorageLocationName
+		}
+		source, ok := stores[sourceLocation]
+		if !ok {
+			continue
+		}
+
+		replications := types.GetReplications(veleroBackup.Annotations)
+		changed := false
+
+		for _, secondary := range store.Secondaries {
+			if secondary.Name == sourceLocation {
+				continue
+			}
+			if !shouldReplicateBackup(secondary.ReplicationPolicy, veleroBackup.Annotations["kots.io/snapshot-trigger"]) {
+				continue
+			}
+			if !needsReplication(findReplication(replications, secondary.Name), time.Now()) {
+				continue
+			}
+
+			replicatedAt := time.Now()
+			replication := types.Replication{
+				Location:     secondary.Name,
+				Phase:        types.ReplicationPhaseCompleted,
+				ReplicatedAt: &replicatedAt,
+			}
+
+			copied, err := kotssnapshot.ReplicateBackup(ctx, source, secondary, veleroBackup.Name)
+			if err != nil {
+				replication.Phase = types.ReplicationPhaseFailed
+				replication.Message = err.Error()
+				logger.Infof("Failed to replicate backup %s to %s: %v", veleroBackup.Name, secondary.Name, err)
+			} else {
+				logger.Infof("Replicated backup %s to %s (%d objects copied)", veleroBackup.Name, secondary.Name, copied)
+			}
+
+			replications = setReplication(replications, replication)
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := recordReplications(ctx, veleroClient, veleroNamespace, veleroBackup.Name, replications); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to record replications for backup %s", veleroBackup.Name))
+		}
+	}
+
+	return nil
+}
+
+// deleteReplicatedBackups deletes the copies of the backup from the secondary storage locations it was replicated to.
+// Velero syncs backups from every location, so a copy that is left behind would bring the backup back.
+func deleteReplicatedBackups(ctx context.Context, kotsadmNamespace string, backupName string, replications []types.Replication) error {
+	if len(replications) == 0 {
+		return nil
+	}
+
+	store, err := kotssnapshot.GetGlobalStore(ctx, kotsadmNamespace, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get global store")
+	}
+	if store == nil {
+		return nil
+	}
+
+	for _, secondary := range getReplicatedStores(replications, store.Secondaries) {
+		deleted, err := kotssnapshot.DeleteReplicatedBackup(ctx, secondary, backupName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete backup %s from %s", backupName, secondary.Name)
+		}
+		logger.Infof("Deleted backup %s from %s (%d objects deleted)", backupName, secondary.Name, deleted)
+	}
+
+	return nil
+}
+
+// getReplicatedStores returns the secondary stores that the backup was replicated to.
+// Failed replications are included since they can leave some of the backup's objects behind.
+func getReplicatedStores(replications []types.Replication, secondaries []*snapshottypes.Store) []*snapshottypes.Store {
+	replicated := []*snapshottypes.Store{}
+	for _, secondary := range secondaries {
+		for _, replication := range replications {
+			if replication.Location == secondary.Name {
+				replicated = append(replicated, secondary)
+				break
+			}
+		}
+	}
+	return replicated
+}
+
+func recordReplications(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, backupName string, replications []types.Replication) error {
+	annotations, err := types.ReplicationAnnotations(replications)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal replications")
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patch")
+	}
+
+	_, err = veleroClient.Backups(veleroNamespace).Patch(ctx, backupName, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to patch backup")
+	}
+
+	return nil
+}
+
+// shouldReplicateBackup returns true if a backup with this trigger is copied to a location with this policy
+func shouldReplicateBackup(policy snapshottypes.ReplicationPolicy, trigger string) bool {
+	switch policy {
+	case snapshottypes.ReplicationPolicyNone:
+		return false
+	case snapshottypes.ReplicationPolicyScheduled:
+		return trigger == "schedule"
+	default:
+		return true
+	}
+}
+
+// needsReplication returns true if the backup has not been copied to the location yet,
+// or if the last attempt failed long enough ago to retry
+func needsReplication(replication *types.Replication, now time.Time) bool {
+	if replication == nil {
+		return true
+	}
+	if replication.Phase == types.ReplicationPhaseCompleted {
+		return false
+	}
+	if replication.ReplicatedAt == nil {
+		return true
+	}
+	return now.Sub(*replication.ReplicatedAt) >= ReplicationRetryInterval
+}
+
+func findReplication(replications []types.Replication, location string) *types.Replication {
+	for i := range replications {
+		if replications[i].Location == location {
+			return &replications[i]
+		}
+	}
+	return nil
+}
+
+func setReplication(replications []types.Replication, replication types.Replication) []types.Replication {
+	for i := range replications {
+		if replications[i].Location == replication.Location {
+			replications[i] = replication
+			return replications
+		}
+	}
+	return append(replications, replication)
+}
@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	snapshottypes "github.com/replicatedhq/kots/pkg/snapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_shouldReplicateBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  snapshottypes.ReplicationPolicy
+		trigger string
+		want    bool
+	}{
+		{name: "all, manual", policy: snapshottypes.ReplicationPolicyAll, trigger: "manual", want: true},
+		{name: "all, schedule", policy: snapshottypes.ReplicationPolicyAll, trigger: "schedule", want: true},
+		{name: "unset defaults to all", policy: "", trigger: "manual", want: true},
+		{name: "scheduled, manual", policy: snapshottypes.ReplicationPolicyScheduled, trigger: "manual", want: false},
+		{name: "scheduled, schedule", policy: snapshottypes.ReplicationPolicyScheduled, trigger: "schedule", want: true},
+		{name: "none, schedule", policy: snapshottypes.ReplicationPolicyNone, trigger: "schedule", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, shouldReplicateBackup(test.policy, test.trigger))
+		})
+	}
+}
+
+func Test_needsReplication(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Minute)
+	old := now.Add(-2 * ReplicationRetryInterval)
+
+	tests := []struct {
+		name        string
+		replication *types.Replication
+		want        bool
+	}{
+		{
+			name: "never replicated",
+			want: true,
+		},
+		{
+			name:        "completed",
+			replication: &types.Replication{Phase: types.ReplicationPhaseCompleted, ReplicatedAt: &old},
+			want:        false,
+		},
+		{
+			name:        "recently failed",
+			replication: &types.Replication{Phase: types.ReplicationPhaseFailed, ReplicatedAt: &recent},
+			want:        false,
+		},
+		{
+			name:        "failed long ago",
+			replication: &types.Replication{Phase: types.ReplicationPhaseFailed, ReplicatedAt: &old},
+			want:        true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, needsReplication(test.replication, now))
+		})
+	}
+}
+
+func Test_replicationAnnotations(t *testing.T) {
+	req := require.New(t)
+
+	replicatedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	replications := []types.Replication{}
+	replications = setReplication(replications, types.Replication{Location: "offsite", Phase: types.ReplicationPhaseFailed, Message: "timeout"})
+	replications = setReplication(replications, types.Replication{Location: "archive", Phase: types.ReplicationPhaseCompleted, ReplicatedAt: &replicatedAt})
+	replications = setReplication(replications, types.Replication{Location: "offsite", Phase: types.ReplicationPhaseCompleted, ReplicatedAt: &replicatedAt})
+	req.Len(replications, 2)
+
+	annotations, err := types.ReplicationAnnotations(replications)
+	req.NoError(err)
+
+	got := types.GetReplications(annotations)
+	req.Len(got, 2)
+	assert.Equal(t, "archive", got[0].Location)
+	assert.Equal(t, "offsite", got[1].Location)
+	assert.Equal(t, types.ReplicationPhaseCompleted, got[1].Phase)
+	assert.Empty(t, got[1].Message)
+	assert.Equal(t, replicatedAt, got[1].ReplicatedAt.UTC())
+
+	assert.Nil(t, findReplication(got, "missing"))
+}
+
+func Test_getReplicatedStores(t *testing.T) {
+	offsite := &snapshottypes.Store{Name: "offsite"}
+	archive := &snapshottypes.Store{Name: "archive"}
+	secondaries := []*snapshottypes.Store{offsite, archive}
+
+	tests := []struct {
+		name         string
+		replications []types.Replication
+		want         []*snapshottypes.Store
+	}{
+		{
+			name: "not replicated",
+			want: []*snapshottypes.Store{},
+		},
+		{
+			name: "replicated and failed",
+			replications: []types.Replication{
+				{Location: "archive", Phase: types.ReplicationPhaseFailed},
+				{Location: "offsite", Phase: types.ReplicationPhaseCompleted},
+			},
+			want: []*snapshottypes.Store{offsite, archive},
+		},
+		{
+			name: "location was removed",
+			replications: []types.Replication{
+				{Location: "old", Phase: types.ReplicationPhaseCompleted},
+				{Location: "offsite", Phase: types.ReplicationPhaseCompleted},
+			},
+			want: []*snapshottypes.Store{offsite},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, getReplicatedStores(test.replications, secondaries))
+		})
+	}
+}
@@ -119,6 +119,114 @@ func CreateApplicationRestore(ctx context.Context, kotsadmNamespace string, snap
 	return nil
 }
 
+// CreateSelectiveRestore restores the parts of a backup that match the filter. Unlike a full restore,
+// the app is not undeployed first, and velero skips resources that still exist in the cluster.
+// If appSlug is set, only the app's resources are restored from an instance backup.
+func CreateSelectiveRestore(ctx context.Context, kotsadmNamespace string, snapshotName string, appSlug string, filter types.RestoreFilter) (*velerov1.Restore, error) {
+	logger.Debug("creating selective restore",
+		zap.String("snapshotName", snapshotName),
+		zap.String("appSlug", appSlug))
+
+	if err := kotssnapshot.ValidateRestoreFilter(filter); err != nil {
+		return nil, err
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return nil, errors.New("no backup store location found")
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, snapshotName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backup")
+	}
+
+	if backup.Status.Phase != velerov1.BackupPhaseCompleted {
+		return nil, &kotssnapshot.InvalidRestoreFilterError{Message: fmt.Sprintf("backup %s is %s, only completed backups can be restored", snapshotName, backup.Status.Phase)}
+	}
+
+	if len(filter.PVCs) > 0 {
+		clientset, err := k8sutil.GetClientset()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create k8s clientset")
+		}
+		resourceList, err := kotssnapshot.GetBackupResourceList(ctx, veleroNamespace, snapshotName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup resource list")
+		}
+		podVolumeBackups, err := kotssnapshot.ListBackupPodVolumeBackups(ctx, veleroClient, backup)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list pod volume backups")
+		}
+		if err := kotssnapshot.CheckPVCsForRestore(ctx, clientset, resourceList, podVolumeBackups, filter.PVCs); err != nil {
+			return nil, errors.Wrap(err, "failed to check persistent volume claims")
+		}
+	}
+
+	restore, err := kotssnapshot.NewSelectiveRestore(backup, kotssnapshot.GetSelectiveRestoreName(snapshotName, appSlug), filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create selective restore")
+	}
+
+	if appSlug != "" && backup.Annotations["kots.io/instance"] == "true" {
+		// only restore app-specific objects
+		labelSelector := metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"kots.io/app-slug": appSlug,
+			},
+		}
+		if restore.Spec.LabelSelector != nil {
+			labelSelector = mergeLabelSelector(labelSelector, *restore.Spec.LabelSelector)
+		}
+		restore.Spec.LabelSelector = &labelSelector
+	}
+
+	restore, err = veleroClient.Restores(veleroNamespace).Create(ctx, restore, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create restore")
+	}
+
+	return restore, nil
+}
+
+// PreviewRestore lists the resources in a backup that a restore with the filter includes.
+// Label selectors are not evaluated, the preview lists every resource of the included kinds and namespaces.
+func PreviewRestore(ctx context.Context, kotsadmNamespace string, snapshotName string, filter types.RestoreFilter) (*types.BackupContents, error) {
+	if err := kotssnapshot.ValidateRestoreFilter(filter); err != nil {
+		return nil, err
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return nil, errors.New("no backup store location found")
+	}
+
+	resourceList, err := kotssnapshot.GetBackupResourceList(ctx, bsl.Namespace, snapshotName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup resource list")
+	}
+
+	return kotssnapshot.FilterBackupContents(resourceList, filter), nil
+}
+
 func DeleteRestore(ctx context.Context, kotsadmNamespace string, snapshotName string) error {
 	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, kotsadmNamespace)
 	if err != nil {
@@ -0,0 +1,201 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+type retentionTier struct {
+	count  int
+	period time.Duration
+	key    func(t time.Time) string
+}
+
+// ParseRetentionPolicy parses a retention policy as saved on the cluster. An empty string means no policy.
+func ParseRetentionPolicy(s string) (*types.RetentionPolicy, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	policy := types.RetentionPolicy{}
+	if err := json.Unmarshal([]byte(s), &policy); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal retention policy")
+	}
+
+	return &policy, nil
+}
+
+// FormatRetentionPolicy returns the retention policy as saved on the cluster. An empty policy is saved as an empty string.
+func FormatRetentionPolicy(policy types.RetentionPolicy) (string, error) {
+	if policy.IsEmpty() {
+		return "", nil
+	}
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal retention policy")
+	}
+
+	return string(b), nil
+}
+
+func ValidateRetentionPolicy(policy types.RetentionPolicy) error {
+	if policy.Hourly < 0 || policy.Daily < 0 || policy.Weekly < 0 || policy.Monthly < 0 {
+		return errors.New("retention counts cannot be negative")
+	}
+	return nil
+}
+
+// RetentionPolicyTTL returns a TTL for scheduled backups that outlives every tier of the policy.
+// Velero's garbage collection is only a backstop, backups are pruned by the snapshot scheduler.
+func RetentionPolicyTTL(policy types.RetentionPolicy) time.Duration {
+	ttl := time.Duration(0)
+	for _, tier := range getRetentionTiers(policy) {
+		if d := time.Duration(tier.count+1) * tier.period; d > ttl {
+			ttl = d
+		}
+	}
+	return ttl
+}
+
+// PreviewInstanceBackupRetention returns the scheduled instance backups that the policy would keep and prune
+func PreviewInstanceBackupRetention(ctx context.Context, kotsadmNamespace string, policy types.RetentionPolicy) (*types.RetentionPreview, error) {
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list instance backups")
+	}
+
+	keep, prune := selectBackupsToPrune(backups, policy, time.Now())
+
+	preview := &types.RetentionPreview{
+		Keep:  []string{},
+		Prune: []string{},
+	}
+	for _, backup := range keep {
+		preview.Keep = append(preview.Keep, backup.Name)
+	}
+	for _, backup := range prune {
+		preview.Prune = append(preview.Prune, backup.Name)
+	}
+
+	return preview, nil
+}
+
+// PruneInstanceBackups deletes the scheduled instance backups that are not kept by the policy,
+// along with their copies in secondary storage locations, and returns the names of the backups that were deleted
+func PruneInstanceBackups(ctx context.Context, kotsadmNamespace string, policy types.RetentionPolicy) ([]string, error) {
+	if policy.IsEmpty() {
+		return nil, nil
+	}
+
+	backups, err := ListInstanceBackups(ctx, kotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list instance backups")
+	}
+
+	_, prune := selectBackupsToPrune(backups, policy, time.Now())
+
+	pruned := []string{}
+	for _, backup := range prune {
+		if err := DeleteBackup(ctx, kotsadmNamespace, backup.Name); err != nil {
+			return pruned, errors.Wrapf(err, "failed to delete backup %s", backup.Name)
+		}
+		pruned = append(pruned, backup.Name)
+	}
+
+	return pruned, nil
+}
+
+// selectBackupsToPrune applies the policy to the completed scheduled backups, newest first.
+// Manual backups, unfinished backups and backups that are being drilled are neither kept nor pruned,
+// they expire according to their TTL.
+func selectBackupsToPrune(backups []*types.Backup, policy types.RetentionPolicy, now time.Time) ([]*types.Backup, []*types.Backup) {
+	candidates := []*types.Backup{}
+	for _, backup := range backups {
+		if backup.Trigger != "schedule" || backup.Status != string(velerov1.BackupPhaseCompleted) {
+			continue
+		}
+		if backup.StartedAt == nil {
+			continue
+		}
+		if isRestoreDrillRunning(backup.RestoreDrill, now) {
+			continue
+		}
+		candidates = append(candidates, backup)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].StartedAt.After(*candidates[j].StartedAt)
+	})
+
+	kept := map[string]bool{}
+	for _, tier := range getRetentionTiers(policy) {
+		periods := map[string]bool{}
+		for _, backup := range candidates {
+			if len(periods) >= tier.count {
+				break
+			}
+			key := tier.key(backup.StartedAt.UTC())
+			if periods[key] {
+				continue
+			}
+			periods[key] = true
+			kept[backup.Name] = true
+		}
+	}
+
+	keep := []*types.Backup{}
+	prune := []*types.Backup{}
+	for _, backup := range candidates {
+		if kept[backup.Name] {
+			keep = append(keep, backup)
+		} else {
+			prune = append(prune, backup)
+		}
+	}
+
+	return keep, prune
+}
+
+func getRetentionTiers(policy types.RetentionPolicy) []retentionTier {
+	tiers := []retentionTier{
+		{
+			count:  policy.Hourly,
+			period: time.Hour,
+			key:    func(t time.Time) string { return t.Format("2006-01-02T15") },
+		},
+		{
+			count:  policy.Daily,
+			period: 24 * time.Hour,
+			key:    func(t time.Time) string { return t.Format("2006-01-02") },
+		},
+		{
+			count:  policy.Weekly,
+			period: 7 * 24 * time.Hour,
+			key: func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-W%02d", year, week)
+			},
+		},
+		{
+			count:  policy.Monthly,
+			period: 31 * 24 * time.Hour,
+			key:    func(t time.Time) string { return t.Format("2006-01") },
+		},
+	}
+
+	enabled := []retentionTier{}
+	for _, tier := range tiers {
+		if tier.count > 0 {
+			enabled = append(enabled, tier)
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,112 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectBackupsToPrune(t *testing.T) {
+	now := time.Date(2023, 3, 15, 12, 30, 0, 0, time.UTC)
+
+	scheduled := func(name string, startedAt time.Time) *types.Backup {
+		return &types.Backup{
+			Name:      name,
+			Status:    "Completed",
+			Trigger:   "schedule",
+			StartedAt: &startedAt,
+		}
+	}
+
+	// one backup every 6 hours for the last 3 days
+	sixHourly := []*types.Backup{}
+	for i := 0; i < 12; i++ {
+		sixHourly = append(sixHourly, scheduled(now.Add(-time.Duration(i)*6*time.Hour).Format("instance-0102-15"), now.Add(-time.Duration(i)*6*time.Hour)))
+	}
+
+	manualStartedAt := now.Add(-48 * time.Hour)
+	failedStartedAt := now.Add(-72 * time.Hour)
+
+	tests := []struct {
+		name      string
+		backups   []*types.Backup
+		policy    types.RetentionPolicy
+		wantKeep  []string
+		wantPrune []string
+	}{
+		{
+			name:      "hourly keeps the newest backups",
+			backups:   sixHourly,
+			policy:    types.RetentionPolicy{Hourly: 2},
+			wantKeep:  []string{"instance-0315-12", "instance-0315-06"},
+			wantPrune: []string{"instance-0315-00", "instance-0314-18", "instance-0314-12", "instance-0314-06", "instance-0314-00", "instance-0313-18", "instance-0313-12", "instance-0313-06", "instance-0313-00", "instance-0312-18"},
+		},
+		{
+			name:      "daily keeps the newest backup of each day",
+			backups:   sixHourly,
+			policy:    types.RetentionPolicy{Daily: 3},
+			wantKeep:  []string{"instance-0315-12", "instance-0314-18", "instance-0313-18"},
+			wantPrune: []string{"instance-0315-06", "instance-0315-00", "instance-0314-12", "instance-0314-06", "instance-0314-00", "instance-0313-12", "instance-0313-06", "instance-0313-00", "instance-0312-18"},
+		},
+		{
+			name:      "tiers are combined",
+			backups:   sixHourly,
+			policy:    types.RetentionPolicy{Hourly: 1, Daily: 2, Weekly: 2},
+			wantKeep:  []string{"instance-0315-12", "instance-0314-18", "instance-0312-18"},
+			wantPrune: []string{"instance-0315-06", "instance-0315-00", "instance-0314-12", "instance-0314-06", "instance-0314-00", "instance-0313-18", "instance-0313-12", "instance-0313-06", "instance-0313-00"},
+		},
+		{
+			name: "manual and failed backups are ignored",
+			backups: []*types.Backup{
+				scheduled("scheduled-1", now),
+				scheduled("scheduled-2", now.Add(-time.Hour)),
+				{Name: "manual", Status: "Completed", Trigger: "manual", StartedAt: &manualStartedAt},
+				{Name: "failed", Status: "Failed", Trigger: "schedule", StartedAt: &failedStartedAt},
+				{Name: "in-progress", Status: "InProgress", Trigger: "schedule"},
+			},
+			policy:    types.RetentionPolicy{Monthly: 1},
+			wantKeep:  []string{"scheduled-1"},
+			wantPrune: []string{"scheduled-2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keep, prune := selectBackupsToPrune(test.backups, test.policy, now)
+			assert.Equal(t, test.wantKeep, backupNames(keep))
+			assert.Equal(t, test.wantPrune, backupNames(prune))
+		})
+	}
+}
+
+func Test_retentionPolicyFormat(t *testing.T) {
+	req := require.New(t)
+
+	s, err := FormatRetentionPolicy(types.RetentionPolicy{})
+	req.NoError(err)
+	assert.Equal(t, "", s)
+
+	policy, err := ParseRetentionPolicy(s)
+	req.NoError(err)
+	assert.Nil(t, policy)
+
+	s, err = FormatRetentionPolicy(types.RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12})
+	req.NoError(err)
+
+	policy, err = ParseRetentionPolicy(s)
+	req.NoError(err)
+	assert.Equal(t, types.RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12}, *policy)
+
+	assert.Equal(t, 13*31*24*time.Hour, RetentionPolicyTTL(*policy))
+	assert.Error(t, ValidateRetentionPolicy(types.RetentionPolicy{Daily: -1}))
+}
+
+func backupNames(backups []*types.Backup) []string {
+	names := []string{}
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/json"
+)
+
+const (
+	BackupHooksAnnotation = "kots.io/backup-hooks"
+)
+
+type BackupHookState string
+
+const (
+	BackupHookStatePending   BackupHookState = "Pending"
+	BackupHookStateExecuted  BackupHookState = "Executed"
+	BackupHookStateSucceeded BackupHookState = "Succeeded"
+	BackupHookStateFailed    BackupHookState = "Failed"
+	BackupHookStateSkipped   BackupHookState = "Skipped"
+	BackupHookStateNotRun    BackupHookState = "NotRun"
+)
+
+// DeclaredBackupHook is a backup hook from an Application spec as it was translated for a backup.
+// The declared hooks of a backup are recorded as a JSON annotation on the Backup CR.
+type DeclaredBackupHook struct {
+	// Name is the name of the velero hook, prefixed with the app slug
+	Name      string   `json:"name"`
+	App       string   `json:"app"`
+	Phase     string   `json:"phase"`
+	Namespace string   `json:"namespace"`
+	OnError   string   `json:"onError"`
+	Pods      []string `json:"pods,omitempty"`
+	// ValidationError is set when the hook was left out of the backup because it could not run
+	ValidationError string `json:"validationError,omitempty"`
+}
+
+// BackupHookStatus is the progress of a declared backup hook.
+// Velero only reports hook results in the backup logs, which are uploaded when the backup finishes,
+// so hooks that have run in a backup that's in progress are Executed until the backup finishes.
+type BackupHookStatus struct {
+	Name      string          `json:"name"`
+	App       string          `json:"app"`
+	Phase     string          `json:"phase"`
+	Namespace string          `json:"namespace"`
+	OnError   string          `json:"onError"`
+	State     BackupHookState `json:"state"`
+	Pods      []string        `json:"pods"`
+	Message   string          `json:"message,omitempty"`
+}
+
+// GetDeclaredBackupHooks returns the declared backup hooks recorded in the backup annotations
+func GetDeclaredBackupHooks(annotations map[string]string) []DeclaredBackupHook {
+	value := annotations[BackupHooksAnnotation]
+	if value == "" {
+		return nil
+	}
+
+	hooks := []DeclaredBackupHook{}
+	if err := json.Unmarshal([]byte(value), &hooks); err != nil {
+		return nil
+	}
+
+	return hooks
+}
+
+// BackupHooksAnnotations returns the backup annotations that record the declared backup hooks
+func BackupHooksAnnotations(hooks []DeclaredBackupHook) (map[string]string, error) {
+	if len(hooks) == 0 {
+		return map[string]string{}, nil
+	}
+
+	b, err := json.Marshal(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		BackupHooksAnnotation: string(b),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	ReplicationStatusAnnotation = "kots.io/replication-status"
+)
+
+type ReplicationPhase string
+
+const (
+	ReplicationPhaseCompleted ReplicationPhase = "Completed"
+	ReplicationPhaseFailed    ReplicationPhase = "Failed"
+)
+
+// Replication is the result of copying a backup to a secondary storage location.
+// The replications of a backup are recorded as a JSON annotation on the Backup CR, keyed by location.
+type Replication struct {
+	Location     string           `json:"location"`
+	Phase        ReplicationPhase `json:"phase"`
+	Message      string           `json:"message,omitempty"`
+	ReplicatedAt *time.Time       `json:"replicatedAt,omitempty"`
+}
+
+// GetReplications returns the replications recorded in the backup annotations, sorted by location
+func GetReplications(annotations map[string]string) []Replication {
+	value := annotations[ReplicationStatusAnnotation]
+	if value == "" {
+		return nil
+	}
+
+	byLocation := map[string]Replication{}
+	if err := json.Unmarshal([]byte(value), &byLocation); err != nil {
+		return nil
+	}
+
+	replications := []Replication{}
+	for location, replication := range byLocation {
+		replication.Location = location
+		replications = append(replications, replication)
+	}
+	sort.Slice(replications, func(i, j int) bool {
+		return replications[i].Location < replications[j].Location
+	})
+
+	return replications
+}
+
+// ReplicationAnnotations returns the backup annotations that record the replications
+func ReplicationAnnotations(replications []Replication) (map[string]string, error) {
+	byLocation := map[string]Replication{}
+	for _, replication := range replications {
+		byLocation[replication.Location] = replication
+	}
+
+	b, err := json.Marshal(byLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		ReplicationStatusAnnotation: string(b),
+	}, nil
+}
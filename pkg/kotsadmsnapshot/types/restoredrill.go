@@ -0,0 +1,75 @@
+package types
+
+import (
+	"time"
+)
+
+const (
+	RestoreDrillStatusAnnotation   = "kots.io/restore-drill-status"
+	RestoreDrillMessageAnnotation  = "kots.io/restore-drill-message"
+	RestoreDrillStartedAnnotation  = "kots.io/restore-drill-started"
+	RestoreDrillFinishedAnnotation = "kots.io/restore-drill-finished"
+)
+
+type RestoreDrillStatus string
+
+const (
+	RestoreDrillStatusRunning RestoreDrillStatus = "Running"
+	RestoreDrillStatusPassed  RestoreDrillStatus = "Passed"
+	RestoreDrillStatusFailed  RestoreDrillStatus = "Failed"
+)
+
+// RestoreDrill is the result of restoring a backup into scratch namespaces to verify that it restores.
+// It's recorded as annotations on the Backup CR.
+type RestoreDrill struct {
+	Status     RestoreDrillStatus `json:"status"`
+	Message    string             `json:"message,omitempty"`
+	StartedAt  *time.Time         `json:"startedAt,omitempty"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"`
+}
+
+// GetRestoreDrill returns the restore drill recorded in the backup annotations, or nil if the backup was never drilled
+func GetRestoreDrill(annotations map[string]string) *RestoreDrill {
+	status := annotations[RestoreDrillStatusAnnotation]
+	if status == "" {
+		return nil
+	}
+
+	drill := &RestoreDrill{
+		Status:  RestoreDrillStatus(status),
+		Message: annotations[RestoreDrillMessageAnnotation],
+	}
+	if t, err := time.Parse(time.RFC3339, annotations[RestoreDrillStartedAnnotation]); err == nil {
+		drill.StartedAt = &t
+	}
+	if t, err := time.Parse(time.RFC3339, annotations[RestoreDrillFinishedAnnotation]); err == nil {
+		drill.FinishedAt = &t
+	}
+
+	return drill
+}
+
+// Annotations returns the backup annotations that record the restore drill
+func (d RestoreDrill) Annotations() map[string]string {
+	annotations := map[string]string{
+		RestoreDrillStatusAnnotation:   string(d.Status),
+		RestoreDrillMessageAnnotation:  d.Message,
+		RestoreDrillStartedAnnotation:  "",
+		RestoreDrillFinishedAnnotation: "",
+	}
+	if d.StartedAt != nil {
+		annotations[RestoreDrillStartedAnnotation] = d.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if d.FinishedAt != nil {
+		annotations[RestoreDrillFinishedAnnotation] = d.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// Duration returns how long the drill took, or zero if it hasn't finished
+func (d RestoreDrill) Duration() time.Duration {
+	if d.StartedAt == nil || d.FinishedAt == nil {
+		return 0
+	}
+	return d.FinishedAt.Sub(*d.StartedAt)
+}
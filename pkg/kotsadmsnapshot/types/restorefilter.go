@@ -0,0 +1,30 @@
+package types
+
+// RestoreFilter restricts a restore to part of a backup. An empty filter restores everything.
+type RestoreFilter struct {
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	// IncludedResources are resource names as accepted by velero, e.g. "deployments" or "deployments.apps"
+	IncludedResources []string `json:"includedResources,omitempty"`
+	// LabelSelector is a label selector query, e.g. "app=postgres,tier!=cache"
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// PVCs are persistent volume claims to restore, in the form "namespace/name"
+	PVCs []string `json:"pvcs,omitempty"`
+}
+
+// IsEmpty returns true if the filter doesn't restrict the restore
+func (f RestoreFilter) IsEmpty() bool {
+	return len(f.IncludedNamespaces) == 0 && len(f.IncludedResources) == 0 && f.LabelSelector == "" && len(f.PVCs) == 0
+}
+
+// BackupContents lists the resources in a backup, grouped by kind
+type BackupContents struct {
+	Namespaces []string              `json:"namespaces"`
+	Resources  []BackupResourceGroup `json:"resources"`
+}
+
+type BackupResourceGroup struct {
+	// Kind is the group, version and kind of the resources, e.g. "apps/v1/Deployment"
+	Kind string `json:"kind"`
+	// Items are "namespace/name" for namespaced resources and "name" for cluster scoped resources
+	Items []string `json:"items"`
+}
@@ -0,0 +1,22 @@
+package types
+
+// RetentionPolicy is a grandfather-father-son retention policy for scheduled instance snapshots.
+// For each tier, the newest backup in each of the last N hours, days, weeks or months that have
+// a backup is kept. Backups that are not kept by any tier are pruned.
+type RetentionPolicy struct {
+	Hourly  int `json:"hourly"`
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+}
+
+// IsEmpty returns true if no tier keeps any backups, which disables the policy
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.Hourly == 0 && p.Daily == 0 && p.Weekly == 0 && p.Monthly == 0
+}
+
+// RetentionPreview lists the scheduled backups a retention policy keeps and the ones it prunes
+type RetentionPreview struct {
+	Keep  []string `json:"keep"`
+	Prune []string `json:"prune"`
+}
@@ -14,31 +14,35 @@ type App struct {
 }
 
 type Backup struct {
-	Name               string     `json:"name"`
-	Status             string     `json:"status"`
-	Trigger            string     `json:"trigger"`
-	AppID              string     `json:"appID"`    // TODO: remove with app backups
-	Sequence           int64      `json:"sequence"` // TODO: remove with app backups
-	StartedAt          *time.Time `json:"startedAt,omitempty"`
-	FinishedAt         *time.Time `json:"finishedAt,omitempty"`
-	ExpiresAt          *time.Time `json:"expiresAt,omitempty"`
-	VolumeCount        int        `json:"volumeCount"`
-	VolumeSuccessCount int        `json:"volumeSuccessCount"`
-	VolumeBytes        int64      `json:"volumeBytes"`
-	VolumeSizeHuman    string     `json:"volumeSizeHuman"`
-	SupportBundleID    string     `json:"supportBundleId,omitempty"`
-	IncludedApps       []App      `json:"includedApps,omitempty"`
+	Name               string        `json:"name"`
+	Status             string        `json:"status"`
+	Trigger            string        `json:"trigger"`
+	AppID              string        `json:"appID"`    // TODO: remove with app backups
+	Sequence           int64         `json:"sequence"` // TODO: remove with app backups
+	StartedAt          *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt         *time.Time    `json:"finishedAt,omitempty"`
+	ExpiresAt          *time.Time    `json:"expiresAt,omitempty"`
+	VolumeCount        int           `json:"volumeCount"`
+	VolumeSuccessCount int           `json:"volumeSuccessCount"`
+	VolumeBytes        int64         `json:"volumeBytes"`
+	VolumeSizeHuman    string        `json:"volumeSizeHuman"`
+	SupportBundleID    string        `json:"supportBundleId,omitempty"`
+	IncludedApps       []App         `json:"includedApps,omitempty"`
+	RestoreDrill       *RestoreDrill `json:"restoreDrill,omitempty"`
+	StorageLocation    string        `json:"storageLocation,omitempty"`
+	Replications       []Replication `json:"replications,omitempty"`
 }
 
 type BackupDetail struct {
-	Name            string           `json:"name"`
-	Status          string           `json:"status"`
-	VolumeSizeHuman string           `json:"volumeSizeHuman"`
-	Namespaces      []string         `json:"namespaces"`
-	Hooks           []*SnapshotHook  `json:"hooks"`
-	Volumes         []SnapshotVolume `json:"volumes"`
-	Errors          []SnapshotError  `json:"errors"`
-	Warnings        []SnapshotError  `json:"warnings"`
+	Name            string             `json:"name"`
+	Status          string             `json:"status"`
+	VolumeSizeHuman string             `json:"volumeSizeHuman"`
+	Namespaces      []string           `json:"namespaces"`
+	Hooks           []*SnapshotHook    `json:"hooks"`
+	HookStatuses    []BackupHookStatus `json:"hookStatuses"`
+	Volumes         []SnapshotVolume   `json:"volumes"`
+	Errors          []SnapshotError    `json:"errors"`
+	Warnings        []SnapshotError    `json:"warnings"`
 }
 
 type RestoreDetail struct {
@@ -132,3 +136,11 @@ type ScheduledInstanceSnapshot struct {
 	// name of Backup CR will be set once scheduled
 	BackupName string `json:"backupName,omitempty"`
 }
+
+type ScheduledRestoreDrill struct {
+	ID                 string    `json:"id"`
+	ClusterID          string    `json:"clusterId"`
+	ScheduledTimestamp time.Time `json:"scheduledTimestamp"`
+	// name of the Backup CR that was drilled will be set once started
+	BackupName string `json:"backupName,omitempty"`
+}
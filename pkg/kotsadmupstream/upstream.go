@@ -14,6 +14,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/pull"
 	"github.com/replicatedhq/kots/pkg/render"
 	"github.com/replicatedhq/kots/pkg/reporting"
@@ -274,7 +275,7 @@ func DownloadUpdate(appID string, update types.Update, skipPreflights bool, skip
 	}
 
 	if !skipPreflights || hasStrictPreflights {
-		if err := preflight.Run(appID, a.Slug, *finalSequence, a.IsAirgap, archiveDir); err != nil {
+		if err := preflight.Run(appID, a.Slug, *finalSequence, a.IsAirgap, preflighttypes.PreflightTriggerUpdate, archiveDir); err != nil {
 			finalError = errors.Wrap(err, "failed to run preflights")
 			return
 		}
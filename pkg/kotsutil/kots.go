@@ -1043,6 +1043,7 @@ type InstallationParams struct {
 	SkipImagePush          bool
 	SkipPreflights         bool
 	SkipCompatibilityCheck bool
+	SkipIntegrityCheck     bool
 	RegistryIsReadOnly     bool
 	EnableImageDeletion    bool
 	EnsureRBAC             bool
@@ -1079,6 +1080,7 @@ func GetInstallationParams(configMapName string) (InstallationParams, error) {
 	autoConfig.SkipImagePush, _ = strconv.ParseBool(kotsadmConfigMap.Data["initial-app-images-pushed"])
 	autoConfig.SkipPreflights, _ = strconv.ParseBool(kotsadmConfigMap.Data["skip-preflights"])
 	autoConfig.SkipCompatibilityCheck, _ = strconv.ParseBool(kotsadmConfigMap.Data["skip-compatibility-check"])
+	autoConfig.SkipIntegrityCheck, _ = strconv.ParseBool(kotsadmConfigMap.Data["skip-integrity-check"])
 	autoConfig.RegistryIsReadOnly, _ = strconv.ParseBool(kotsadmConfigMap.Data["registry-is-read-only"])
 	autoConfig.EnsureRBAC, _ = strconv.ParseBool(kotsadmConfigMap.Data["ensure-rbac"])
 	autoConfig.SkipRBACCheck, _ = strconv.ParseBool(kotsadmConfigMap.Data["skip-rbac-check"])
@@ -1156,6 +1158,13 @@ func EncodeIdentityConfig(spec kotsv1beta1.IdentityConfig) ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+func EncodeAirgap(airgap kotsv1beta1.Airgap) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+	err := s.Encode(&airgap, buf)
+	return buf.Bytes(), err
+}
+
 func IsKotsVersionCompatibleWithApp(kotsApplication kotsv1beta1.Application, isInstall bool) bool {
 	actualSemver, err := semver.ParseTolerant(buildversion.Version())
 	if err != nil {
@@ -34,6 +34,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/render"
 	rendertypes "github.com/replicatedhq/kots/pkg/render/types"
 	"github.com/replicatedhq/kots/pkg/reporting"
+	"github.com/replicatedhq/kots/pkg/secretref"
 	"github.com/replicatedhq/kots/pkg/store"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
 	"github.com/replicatedhq/kots/pkg/supportbundle"
@@ -54,12 +55,16 @@ var (
 )
 
 type Operator struct {
-	client       client.ClientInterface
-	store        store.Store
-	clusterToken string
-	clusterID    string
-	deployMtxs   map[string]*sync.Mutex // key is app id
-	k8sClientset kubernetes.Interface
+	client        client.ClientInterface
+	store         store.Store
+	clusterToken  string
+	clusterID     string
+	deployMtxs    map[string]*sync.Mutex // key is app id
+	deployMtxsMtx sync.Mutex
+	k8sClientset  kubernetes.Interface
+
+	healthMonitors    map[string]context.CancelFunc // key is app id
+	healthMonitorsMtx sync.Mutex
 }
 
 func Init(client client.ClientInterface, store store.Store, clusterToken string, k8sClientset kubernetes.Interface) *Operator {
@@ -69,6 +74,8 @@ func Init(client client.ClientInterface, store store.Store, clusterToken string,
 		clusterToken: clusterToken,
 		deployMtxs:   map[string]*sync.Mutex{},
 		k8sClientset: k8sClientset,
+
+		healthMonitors: map[string]context.CancelFunc{},
 	}
 	return operator
 }
@@ -95,6 +102,7 @@ func (o *Operator) Start() error {
 
 	go o.resumeStatusInformers()
 	go o.resumeDeployments()
+	go o.resumeDeployHealthMonitors()
 	startLoop(o.restoreLoop, 2)
 
 	return nil
@@ -155,12 +163,31 @@ func (o *Operator) resumeDeployment(a *apptypes.App) (bool, error) {
 	return true, nil
 }
 
-func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deployError error) {
+// getDeployMtx returns the mutex that serializes deploys of the app.
+// Deploys can be started concurrently by the api and by the deploy health monitors.
+func (o *Operator) getDeployMtx(appID string) *sync.Mutex {
+	o.deployMtxsMtx.Lock()
+	defer o.deployMtxsMtx.Unlock()
+
 	if _, ok := o.deployMtxs[appID]; !ok {
 		o.deployMtxs[appID] = &sync.Mutex{}
 	}
-	o.deployMtxs[appID].Lock()
-	defer o.deployMtxs[appID].Unlock()
+	return o.deployMtxs[appID]
+}
+
+func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deployError error) {
+	return o.deployApp(appID, sequence, true)
+}
+
+// deployApp deploys the sequence. When monitorHealth is true and the app has an auto rollback policy,
+// the app status is watched after the deploy and the previous version is redeployed if the policy is not met.
+func (o *Operator) deployApp(appID string, sequence int64, monitorHealth bool) (deployed bool, deployError error) {
+	deployMtx := o.getDeployMtx(appID)
+	deployMtx.Lock()
+	defer deployMtx.Unlock()
+
+	// the health of a previous deploy no longer matters once a new one starts
+	o.stopDeployHealthMonitor(appID, nil)
 
 	if err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionDeploying, ""); err != nil {
 		return false, errors.Wrap(err, "failed to update downstream status")
@@ -255,6 +282,14 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		return false, errors.Wrap(err, "failed to get template builder")
 	}
 
+	// secret references are resolved in the temporary copy of the archive only, never in the stored archive
+	secretRefs := builder.SecretRefs()
+	for _, dir := range []string{"base", "overlays", "rendered", "helm"} {
+		if err := secretref.ResolveDir(filepath.Join(deployedVersionArchive, dir), secretRefs); err != nil {
+			return false, errors.Wrap(err, "failed to resolve secret references")
+		}
+	}
+
 	if kotsKinds.V1Beta1HelmCharts != nil {
 		for i, helmChart := range kotsKinds.V1Beta1HelmCharts.Items {
 			renderedNamespace, err := builder.String(helmChart.Spec.Namespace)
@@ -427,6 +462,12 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		return false, errors.Wrap(err, "failed to deploy app")
 	}
 
+	if deployed && monitorHealth && app.AutoRollbackPolicy != nil {
+		if err := o.startDeployHealthMonitor(app.ID, sequence, *app.AutoRollbackPolicy); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to start deploy health monitor for app %s", app.ID))
+		}
+	}
+
 	return deployed, nil
 }
 
@@ -711,11 +752,9 @@ func (o *Operator) checkRestoreComplete(a *apptypes.App, restore *velerov1.Resto
 }
 
 func (o *Operator) UndeployApp(a *apptypes.App, d *downstreamtypes.Downstream, isRestore bool) error {
-	if _, ok := o.deployMtxs[a.ID]; !ok {
-		o.deployMtxs[a.ID] = &sync.Mutex{}
-	}
-	o.deployMtxs[a.ID].Lock()
-	defer o.deployMtxs[a.ID].Unlock()
+	deployMtx := o.getDeployMtx(a.ID)
+	deployMtx.Lock()
+	defer deployMtx.Unlock()
 
 	deployedVersion, err := o.store.GetCurrentDownstreamVersion(a.ID, d.ClusterID)
 	if err != nil {
@@ -269,6 +269,7 @@ spec:
 
 			It("successfully deploys the app and does not return an error ", func() {
 				mockStore.EXPECT().SetDownstreamVersionStatus(appID, sequence, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+				mockStore.EXPECT().SetDeployHealthMonitor(appID, nil).AnyTimes().Return(nil)
 
 				app := &apptypes.App{
 					ID:                    appID,
@@ -348,6 +349,7 @@ spec:
 
 				It("deployed the app and does not error if the errors no longer exist", func() {
 					mockStore.EXPECT().SetDownstreamVersionStatus(appID, sequence, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+					mockStore.EXPECT().SetDeployHealthMonitor(appID, nil).AnyTimes().Return(nil)
 
 					app := &apptypes.App{
 						ID:                    appID,
@@ -566,6 +568,7 @@ spec:
 
 			It("installs the helm chart using the templated namespace and upgrade flags", func() {
 				mockStore.EXPECT().SetDownstreamVersionStatus(appID, sequence, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+				mockStore.EXPECT().SetDeployHealthMonitor(appID, nil).AnyTimes().Return(nil)
 
 				app := &apptypes.App{
 					ID:                    appID,
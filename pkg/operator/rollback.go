@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+var deployHealthCheckInterval = 10 * time.Second
+
+// startDeployHealthMonitor watches the app status after a deploy and rolls back
+// to the previously deployed version if the policy is not satisfied in time.
+// Any monitor that is already running for the app is stopped.
+func (o *Operator) startDeployHealthMonitor(appID string, sequence int64, policy apptypes.AutoRollbackPolicy) error {
+	timeout, err := policy.GetTimeout()
+	if err != nil {
+		return errors.Wrap(err, "failed to get timeout")
+	}
+
+	monitor := apptypes.DeployHealthMonitor{
+		Sequence: sequence,
+		Deadline: time.Now().Add(timeout),
+	}
+	return o.runDeployHealthMonitor(appID, monitor, policy)
+}
+
+// resumeDeployHealthMonitors restarts the monitors that were running when kotsadm stopped
+// so that a restart during the health window does not skip the rollback.
+func (o *Operator) resumeDeployHealthMonitors() {
+	apps, err := o.store.ListAppsForDownstream(o.clusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list installed apps for downstream"))
+		return
+	}
+
+	for _, a := range apps {
+		if a.DeployHealthMonitor == nil {
+			continue
+		}
+
+		if a.AutoRollbackPolicy == nil {
+			// the policy was removed after the deploy
+			if err := o.store.SetDeployHealthMonitor(a.ID, nil); err != nil {
+				logger.Error(errors.Wrapf(err, "failed to clear deploy health monitor for app %s", a.ID))
+			}
+			continue
+		}
+
+		if err := o.runDeployHealthMonitor(a.ID, *a.DeployHealthMonitor, *a.AutoRollbackPolicy); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to resume deploy health monitor for app %s", a.ID))
+		}
+	}
+}
+
+func (o *Operator) runDeployHealthMonitor(appID string, monitor apptypes.DeployHealthMonitor, policy apptypes.AutoRollbackPolicy) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	o.healthMonitorsMtx.Lock()
+	if stop, ok := o.healthMonitors[appID]; ok {
+		stop()
+	}
+	if err := o.store.SetDeployHealthMonitor(appID, &monitor); err != nil {
+		o.healthMonitorsMtx.Unlock()
+		cancel()
+		return errors.Wrap(err, "failed to save deploy health monitor")
+	}
+	o.healthMonitors[appID] = cancel
+	o.healthMonitorsMtx.Unlock()
+
+	go func() {
+		defer o.stopDeployHealthMonitor(appID, ctx)
+
+		if err := o.monitorDeployHealth(ctx, appID, monitor.Sequence, policy, monitor.Deadline); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to monitor health of app %s sequence %d", appID, monitor.Sequence))
+		}
+	}()
+
+	return nil
+}
+
+// stopDeployHealthMonitor stops the monitor for the app and clears its saved state. When ctx is not nil,
+// the monitor is only stopped if it is the one that owns ctx.
+func (o *Operator) stopDeployHealthMonitor(appID string, ctx context.Context) {
+	o.healthMonitorsMtx.Lock()
+	defer o.healthMonitorsMtx.Unlock()
+
+	if stop, ok := o.healthMonitors[appID]; ok {
+		if ctx != nil && ctx.Err() != nil {
+			// a newer monitor replaced this one
+			return
+		}
+		stop()
+		delete(o.healthMonitors, appID)
+	} else if ctx != nil {
+		return
+	}
+
+	if err := o.store.SetDeployHealthMonitor(appID, nil); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to clear deploy health monitor for app %s", appID))
+	}
+}
+
+func (o *Operator) monitorDeployHealth(ctx context.Context, appID string, sequence int64, policy apptypes.AutoRollbackPolicy, deadlineAt time.Time) error {
+	timeout, err := policy.GetTimeout()
+	if err != nil {
+		return errors.Wrap(err, "failed to get timeout")
+	}
+
+	// the deadline may already have passed if the monitor was resumed after a restart
+	deadline := time.NewTimer(time.Until(deadlineAt))
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(deployHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			state, err := o.getStateForSequence(appID, sequence)
+			if err != nil {
+				logger.Error(errors.Wrap(err, "failed to get app state"))
+				continue
+			}
+			if policy.IsSatisfiedBy(state) {
+				return nil
+			}
+
+		case <-deadline.C:
+			state, err := o.getStateForSequence(appID, sequence)
+			if err != nil {
+				return errors.Wrap(err, "failed to get app state")
+			}
+			if policy.IsSatisfiedBy(state) {
+				return nil
+			}
+			if state == "" {
+				state = appstatetypes.StateMissing
+			}
+
+			reason := fmt.Sprintf("App did not reach the %s state within %s of being deployed (last state was %s)", policy.GetRequiredState(), timeout, state)
+			if err := o.rollbackApp(appID, sequence, reason); err != nil {
+				return errors.Wrap(err, "failed to roll back app")
+			}
+			return nil
+		}
+	}
+}
+
+// getStateForSequence returns the app state reported by the status informers for the sequence,
+// or an empty state if the informers have not reported on the sequence yet.
+func (o *Operator) getStateForSequence(appID string, sequence int64) (appstatetypes.State, error) {
+	appStatus, err := o.store.GetAppStatus(appID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get app status")
+	}
+	if appStatus == nil || appStatus.Sequence != sequence {
+		return "", nil
+	}
+	return appStatus.State, nil
+}
+
+// rollbackApp redeploys the previously deployed version in place of sequence
+// and records the reason in the downstream version status of sequence.
+func (o *Operator) rollbackApp(appID string, sequence int64, reason string) error {
+	currentSequence, err := o.store.GetCurrentDownstreamSequence(appID, o.clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current downstream sequence")
+	}
+	if currentSequence != sequence {
+		// another version was deployed in the meantime
+		return nil
+	}
+
+	previousSequence, err := o.store.GetPreviouslyDeployedSequence(appID, o.clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get previously deployed sequence")
+	}
+	if previousSequence == -1 {
+		return o.setRollbackStatus(appID, sequence, fmt.Sprintf("%s. There is no previously deployed version to roll back to.", reason))
+	}
+
+	allowRollback, err := o.store.IsRollbackSupportedForVersion(appID, sequence)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if rollback is supported")
+	}
+	if !allowRollback {
+		return o.setRollbackStatus(appID, sequence, fmt.Sprintf("%s. Rollback is not supported for this version.", reason))
+	}
+
+	logger.Infof("rolling back app %s from sequence %d to sequence %d: %s", appID, sequence, previousSequence, reason)
+
+	if err := o.setRollbackStatus(appID, sequence, fmt.Sprintf("%s. Rolled back to sequence %d.", reason, previousSequence)); err != nil {
+		return err
+	}
+
+	if err := o.store.MarkAsCurrentDownstreamVersion(appID, previousSequence); err != nil {
+		return errors.Wrap(err, "failed to mark as current downstream version")
+	}
+
+	// the previous version is not monitored so that two unhealthy versions do not keep rolling back to each other
+	if _, err := o.deployApp(appID, previousSequence, false); err != nil {
+		return errors.Wrapf(err, "failed to deploy sequence %d", previousSequence)
+	}
+
+	return nil
+}
+
+func (o *Operator) setRollbackStatus(appID string, sequence int64, statusInfo string) error {
+	if err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, statusInfo); err != nil {
+		return errors.Wrap(err, "failed to update downstream status")
+	}
+	return nil
+}
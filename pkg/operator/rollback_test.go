@@ -0,0 +1,177 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	mock_store "github.com/replicatedhq/kots/pkg/store/mock"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_monitorDeployHealth(t *testing.T) {
+	deployHealthCheckInterval = time.Millisecond
+	defer func() {
+		deployHealthCheckInterval = 10 * time.Second
+	}()
+
+	appID := "app-id"
+	sequence := int64(2)
+
+	tests := []struct {
+		name       string
+		policy     apptypes.AutoRollbackPolicy
+		appStatus  *appstatetypes.AppStatus
+		setupStore func(mockStore *mock_store.MockStore)
+	}{
+		{
+			name:      "required state reached",
+			policy:    apptypes.AutoRollbackPolicy{Timeout: "1h"},
+			appStatus: &appstatetypes.AppStatus{AppID: appID, Sequence: sequence, State: appstatetypes.StateReady},
+			setupStore: func(mockStore *mock_store.MockStore) {
+				mockStore.EXPECT().GetCurrentDownstreamSequence(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:      "degraded state is allowed",
+			policy:    apptypes.AutoRollbackPolicy{Timeout: "1h", RequiredState: appstatetypes.StateDegraded},
+			appStatus: &appstatetypes.AppStatus{AppID: appID, Sequence: sequence, State: appstatetypes.StateDegraded},
+			setupStore: func(mockStore *mock_store.MockStore) {
+				mockStore.EXPECT().GetCurrentDownstreamSequence(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:      "newer version deployed before timeout",
+			policy:    apptypes.AutoRollbackPolicy{Timeout: "10ms"},
+			appStatus: &appstatetypes.AppStatus{AppID: appID, Sequence: sequence, State: appstatetypes.StateDegraded},
+			setupStore: func(mockStore *mock_store.MockStore) {
+				mockStore.EXPECT().GetCurrentDownstreamSequence(appID, "").Return(sequence+1, nil)
+				mockStore.EXPECT().SetDownstreamVersionStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:      "no previous version",
+			policy:    apptypes.AutoRollbackPolicy{Timeout: "10ms"},
+			appStatus: &appstatetypes.AppStatus{AppID: appID, Sequence: sequence, State: appstatetypes.StateDegraded},
+			setupStore: func(mockStore *mock_store.MockStore) {
+				mockStore.EXPECT().GetCurrentDownstreamSequence(appID, "").Return(sequence, nil)
+				mockStore.EXPECT().GetPreviouslyDeployedSequence(appID, "").Return(int64(-1), nil)
+				mockStore.EXPECT().SetDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, "App did not reach the ready state within 10ms of being deployed (last state was degraded). There is no previously deployed version to roll back to.")
+			},
+		},
+		{
+			name:      "rollback not supported",
+			policy:    apptypes.AutoRollbackPolicy{Timeout: "10ms"},
+			appStatus: &appstatetypes.AppStatus{AppID: appID, Sequence: sequence - 1, State: appstatetypes.StateReady},
+			setupStore: func(mockStore *mock_store.MockStore) {
+				mockStore.EXPECT().GetCurrentDownstreamSequence(appID, "").Return(sequence, nil)
+				mockStore.EXPECT().GetPreviouslyDeployedSequence(appID, "").Return(sequence-1, nil)
+				mockStore.EXPECT().IsRollbackSupportedForVersion(appID, sequence).Return(false, nil)
+				mockStore.EXPECT().SetDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, "App did not reach the ready state within 10ms of being deployed (last state was missing). Rollback is not supported for this version.")
+				mockStore.EXPECT().MarkAsCurrentDownstreamVersion(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mock_store.NewMockStore(ctrl)
+			mockStore.EXPECT().GetAppStatus(appID).AnyTimes().Return(tt.appStatus, nil)
+			tt.setupStore(mockStore)
+
+			o := &Operator{
+				store:          mockStore,
+				healthMonitors: map[string]context.CancelFunc{},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			timeout, err := tt.policy.GetTimeout()
+			require.NoError(t, err)
+
+			err = o.monitorDeployHealth(ctx, appID, sequence, tt.policy, time.Now().Add(timeout))
+			require.NoError(t, err)
+			assert.NoError(t, ctx.Err(), "monitor did not finish before the test timeout")
+		})
+	}
+}
+
+func Test_resumeDeployHealthMonitors(t *testing.T) {
+	deployHealthCheckInterval = time.Millisecond
+	defer func() {
+		deployHealthCheckInterval = 10 * time.Second
+	}()
+
+	sequence := int64(2)
+	expiredMonitor := &apptypes.DeployHealthMonitor{Sequence: sequence, Deadline: time.Now().Add(-time.Minute)}
+
+	tests := []struct {
+		name       string
+		app        *apptypes.App
+		setupStore func(mockStore *mock_store.MockStore, done chan struct{})
+	}{
+		{
+			name: "no monitor",
+			app:  &apptypes.App{ID: "app-id", AutoRollbackPolicy: &apptypes.AutoRollbackPolicy{Timeout: "10m"}},
+			setupStore: func(mockStore *mock_store.MockStore, done chan struct{}) {
+				mockStore.EXPECT().SetDeployHealthMonitor(gomock.Any(), gomock.Any()).Times(0)
+				close(done)
+			},
+		},
+		{
+			name: "policy removed",
+			app:  &apptypes.App{ID: "app-id", DeployHealthMonitor: expiredMonitor},
+			setupStore: func(mockStore *mock_store.MockStore, done chan struct{}) {
+				mockStore.EXPECT().SetDeployHealthMonitor("app-id", nil).Return(nil)
+				close(done)
+			},
+		},
+		{
+			name: "deadline passed while stopped",
+			app:  &apptypes.App{ID: "app-id", AutoRollbackPolicy: &apptypes.AutoRollbackPolicy{Timeout: "10m"}, DeployHealthMonitor: expiredMonitor},
+			setupStore: func(mockStore *mock_store.MockStore, done chan struct{}) {
+				mockStore.EXPECT().SetDeployHealthMonitor("app-id", expiredMonitor).Return(nil)
+				mockStore.EXPECT().GetAppStatus("app-id").AnyTimes().Return(&appstatetypes.AppStatus{AppID: "app-id", Sequence: sequence, State: appstatetypes.StateUnavailable}, nil)
+				mockStore.EXPECT().GetCurrentDownstreamSequence("app-id", "").Return(sequence, nil)
+				mockStore.EXPECT().GetPreviouslyDeployedSequence("app-id", "").Return(int64(-1), nil)
+				mockStore.EXPECT().SetDownstreamVersionStatus("app-id", sequence, storetypes.VersionFailed, "App did not reach the ready state within 10m0s of being deployed (last state was unavailable). There is no previously deployed version to roll back to.")
+				mockStore.EXPECT().SetDeployHealthMonitor("app-id", nil).DoAndReturn(func(appID string, monitor *apptypes.DeployHealthMonitor) error {
+					close(done)
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			done := make(chan struct{})
+
+			mockStore := mock_store.NewMockStore(ctrl)
+			mockStore.EXPECT().ListAppsForDownstream("").Return([]*apptypes.App{tt.app}, nil)
+			tt.setupStore(mockStore, done)
+
+			o := &Operator{
+				store:          mockStore,
+				healthMonitors: map[string]context.CancelFunc{},
+			}
+			o.resumeDeployHealthMonitors()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("monitor did not finish before the test timeout")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsadm/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/store"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
 	"github.com/replicatedhq/kots/pkg/util"
 	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
@@ -121,6 +122,32 @@ func setSharedPasswordBcrypt(clientset kubernetes.Interface, namespace string, b
 	return nil
 }
 
+// UnlockLogin - will clear the failed login attempts in the kotsadm password secret so that password logins are no longer locked out
+func UnlockLogin(clientset kubernetes.Interface, namespace string) error {
+	passwordLock.Lock()
+	defer passwordLock.Unlock()
+
+	existingPasswordSecret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), util.PasswordSecretName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			// failed logins are not tracked without the secret
+			return nil
+		}
+		return errors.Wrap(err, "failed to lookup secret")
+	}
+
+	delete(existingPasswordSecret.Labels, "numAttempts")
+	delete(existingPasswordSecret.Labels, "lastFailure")
+	delete(existingPasswordSecret.Data, usertypes.FailedLoginsByIPKey)
+
+	_, err = clientset.CoreV1().Secrets(namespace).Update(context.TODO(), existingPasswordSecret, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to update secret")
+	}
+
+	return nil
+}
+
 // deleteAllSessions - delete all sessions in the session secret, log errors if they occur
 func deleteAllSessions(clientset kubernetes.Interface, namespace string) {
 	sessionSecret := &corev1.Secret{
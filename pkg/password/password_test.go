@@ -1,14 +1,18 @@
 package password
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	mock_store "github.com/replicatedhq/kots/pkg/store/mock"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
+	"github.com/replicatedhq/kots/pkg/util"
 	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
@@ -307,3 +311,77 @@ func Test_setSharedPasswordBcrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestUnlockLogin(t *testing.T) {
+	lockedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      util.PasswordSecretName,
+			Namespace: "test",
+			Labels: map[string]string{
+				"numAttempts": "12",
+				"lastFailure": "1690000000",
+				"other":       "label",
+			},
+		},
+		Data: map[string][]byte{
+			"passwordBcrypt":              []byte("hash"),
+			usertypes.FailedLoginsByIPKey: []byte(`{"10.0.0.1":{"count":6}}`),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		clientset kubernetes.Interface
+		wantErr   bool
+	}{
+		{
+			name:      "expect failed logins to be cleared",
+			clientset: fake.NewSimpleClientset(lockedSecret),
+		},
+		{
+			name:      "expect no error when secret not found",
+			clientset: fake.NewSimpleClientset(),
+		},
+		{
+			name:      "expect error when getting k8s secret fails",
+			clientset: newMockClientForExistingSecretGetErr(),
+			wantErr:   true,
+		},
+		{
+			name:      "expect error when existing secret update fails",
+			clientset: newMockClientForExistingSecretUpdateFail(),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UnlockLogin(tt.clientset, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnlockLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			secret, err := tt.clientset.CoreV1().Secrets("test").Get(context.TODO(), util.PasswordSecretName, metav1.GetOptions{})
+			if kuberneteserrors.IsNotFound(err) {
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get secret: %v", err)
+			}
+			if _, ok := secret.Labels["numAttempts"]; ok {
+				t.Errorf("expected numAttempts label to be removed")
+			}
+			if _, ok := secret.Labels["lastFailure"]; ok {
+				t.Errorf("expected lastFailure label to be removed")
+			}
+			if _, ok := secret.Data[usertypes.FailedLoginsByIPKey]; ok {
+				t.Errorf("expected failed logins by ip to be removed")
+			}
+			if secret.Labels["other"] != "label" || string(secret.Data["passwordBcrypt"]) != "hash" {
+				t.Errorf("expected other labels and data to be kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rqlite/gorqlite"
+)
+
+const (
+	DriverRqlite   = "rqlite"
+	DriverSqlite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// DB is the set of operations the kotsadm store performs against its database.
+// Statements are written in the rqlite (sqlite) dialect with "?" placeholders,
+// and each driver is responsible for adapting them to its backend.
+type DB interface {
+	QueryOne(sqlStatement string) (QueryResult, error)
+	QueryOneParameterized(statement ParameterizedStatement) (QueryResult, error)
+	WriteOne(sqlStatement string) (WriteResult, error)
+	WriteOneParameterized(statement ParameterizedStatement) (WriteResult, error)
+	WriteParameterized(statements []ParameterizedStatement) ([]WriteResult, error)
+}
+
+// The statement, write result and nullable scan types are shared with gorqlite so
+// that callers can use the same values regardless of the driver.
+type (
+	ParameterizedStatement = gorqlite.ParameterizedStatement
+	WriteResult            = gorqlite.WriteResult
+	NullString             = gorqlite.NullString
+	NullInt64              = gorqlite.NullInt64
+	NullInt32              = gorqlite.NullInt32
+	NullInt16              = gorqlite.NullInt16
+	NullFloat64            = gorqlite.NullFloat64
+	NullBool               = gorqlite.NullBool
+	NullTime               = gorqlite.NullTime
+)
+
+type resultRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	NumRows() int64
+}
+
+// QueryResult holds the rows returned by a query.
+// Err is populated when the database reported an error for the statement.
+type QueryResult struct {
+	Err  error
+	rows resultRows
+}
+
+// Next advances to the next row, returning false when there are no more rows.
+func (qr *QueryResult) Next() bool {
+	if qr.rows == nil {
+		return false
+	}
+	return qr.rows.Next()
+}
+
+// Scan copies the columns of the current row into dest.
+func (qr *QueryResult) Scan(dest ...interface{}) error {
+	if qr.rows == nil {
+		return errors.New("no rows to scan")
+	}
+	return qr.rows.Scan(dest...)
+}
+
+// NumRows returns the number of rows returned by the query.
+func (qr *QueryResult) NumRows() int64 {
+	if qr.rows == nil {
+		return 0
+	}
+	return qr.rows.NumRows()
+}
+
+// Open connects to the database at uri using the named driver.
+func Open(driver string, uri string) (DB, error) {
+	switch driver {
+	case DriverRqlite, "":
+		return openRqlite(uri)
+	case DriverSqlite, DriverPostgres:
+		return openSQL(driver, uri)
+	}
+	return nil, errors.Errorf("unsupported database driver %q", driver)
+}
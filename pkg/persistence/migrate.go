@@ -205,7 +205,7 @@ func MigrateFromPostgresToRqlite() error {
 	return nil
 }
 
-func isAlreadyMigrated(rqliteDB *gorqlite.Connection) (bool, error) {
+func isAlreadyMigrated(rqliteDB DB) (bool, error) {
 	rows, err := rqliteDB.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     `SELECT value FROM kotsadm_params WHERE key = ?`,
 		Arguments: []interface{}{RQLITE_MIGRATION_SUCCESS_KEY},
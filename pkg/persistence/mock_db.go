@@ -3,8 +3,6 @@
 
 package persistence
 
-import "github.com/rqlite/gorqlite"
-
-func MustGetDBSession() *gorqlite.Connection {
+func MustGetDBSession() DB {
 	return db
 }
@@ -1,17 +1,30 @@
 package persistence
 
-import "github.com/rqlite/gorqlite"
-
 var (
-	db  *gorqlite.Connection
-	uri string
+	db     DB
+	driver string
+	uri    string
 )
 
-func SetDB(database *gorqlite.Connection) {
+func SetDB(database DB) {
 	db = database
 }
 
-func InitDB(databaseUri string) {
+// InitDB records the driver and uri that MustGetDBSession connects to.
+// An empty driver defaults to rqlite.
+func InitDB(databaseDriver string, databaseUri string) {
+	if databaseDriver == "" {
+		databaseDriver = DriverRqlite
+	}
+	driver = databaseDriver
 	uri = databaseUri
 	MustGetDBSession()
 }
+
+// Driver returns the name of the database driver in use.
+func Driver() string {
+	if driver == "" {
+		return DriverRqlite
+	}
+	return driver
+}
@@ -1,23 +1,44 @@
-//go:build !testing
-
 package persistence
 
 import (
-	"fmt"
-
 	"github.com/rqlite/gorqlite"
 )
 
-func MustGetDBSession() *gorqlite.Connection {
-	if db != nil {
-		return db
-	}
-	newDB, err := gorqlite.Open(uri)
+type rqliteDB struct {
+	conn *gorqlite.Connection
+}
+
+// NewRqliteDB returns a DB backed by an existing rqlite connection.
+func NewRqliteDB(conn *gorqlite.Connection) DB {
+	return &rqliteDB{conn: conn}
+}
+
+func openRqlite(uri string) (DB, error) {
+	conn, err := gorqlite.Open(uri)
 	if err != nil {
-		fmt.Printf("error connecting to rqlite: %v\n", err)
-		panic(err)
+		return nil, err
 	}
+	return NewRqliteDB(&conn), nil
+}
+
+func (r *rqliteDB) QueryOne(sqlStatement string) (QueryResult, error) {
+	qr, err := r.conn.QueryOne(sqlStatement)
+	return QueryResult{Err: qr.Err, rows: &qr}, err
+}
+
+func (r *rqliteDB) QueryOneParameterized(statement ParameterizedStatement) (QueryResult, error) {
+	qr, err := r.conn.QueryOneParameterized(statement)
+	return QueryResult{Err: qr.Err, rows: &qr}, err
+}
+
+func (r *rqliteDB) WriteOne(sqlStatement string) (WriteResult, error) {
+	return r.conn.WriteOne(sqlStatement)
+}
+
+func (r *rqliteDB) WriteOneParameterized(statement ParameterizedStatement) (WriteResult, error) {
+	return r.conn.WriteOneParameterized(statement)
+}
 
-	db = &newDB
-	return db
+func (r *rqliteDB) WriteParameterized(statements []ParameterizedStatement) ([]WriteResult, error) {
+	return r.conn.WriteParameterized(statements)
 }
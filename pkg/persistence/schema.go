@@ -1,27 +1,34 @@
 package persistence
 
 import (
+	"encoding/json"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+	schemasv1alpha4 "github.com/schemahero/schemahero/pkg/apis/schemas/v1alpha4"
 	schemaherodb "github.com/schemahero/schemahero/pkg/database"
+	"gopkg.in/yaml.v2"
 )
 
 func UpdateDBSchema(driver string, uri string, schemaDir string) error {
-	statements := []string{}
+	return UpdateDBSchemaFromFS(driver, uri, os.DirFS(schemaDir))
+}
 
-	schemaheroDB := schemaherodb.Database{
-		Driver: driver,
-		URI:    uri,
-	}
+// UpdateDBSchemaFromFS applies every table spec found in schemaFS to the database.
+// Tables that only declare an rqlite schema are translated to the sqlite or postgres
+// equivalent, so the specs in migrations/tables can be used with every driver.
+func UpdateDBSchemaFromFS(driver string, uri string, schemaFS fs.FS) error {
+	tables := []schemasv1alpha4.TableSpec{}
 
-	err := filepath.Walk(schemaDir, func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(schemaFS, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if info.IsDir() {
+		if d.IsDir() {
 			return nil
 		}
 
@@ -29,11 +36,20 @@ func UpdateDBSchema(driver string, uri string, schemaDir string) error {
 			return nil
 		}
 
-		stmnts, err := schemaheroDB.PlanSyncFromFile(path, "table")
+		content, err := fs.ReadFile(schemaFS, path)
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+
+		table := schemasv1alpha4.Table{}
+		if err := yaml.Unmarshal(content, &table); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", path)
 		}
-		statements = append(statements, stmnts...)
+
+		if err := translateTableSchema(driver, table.Spec.Schema); err != nil {
+			return errors.Wrapf(err, "failed to translate %s", path)
+		}
+		tables = append(tables, table.Spec)
 
 		return nil
 	})
@@ -41,9 +57,88 @@ func UpdateDBSchema(driver string, uri string, schemaDir string) error {
 		return errors.Wrap(err, "failed to walk")
 	}
 
+	if driver == DriverSqlite {
+		return updateSqliteSchema(uri, tables)
+	}
+
+	schemaheroDB := schemaherodb.Database{
+		Driver: driver,
+		URI:    uri,
+	}
+
+	statements := []string{}
+	for i := range tables {
+		stmnts, err := schemaheroDB.PlanSyncTableSpec(&tables[i])
+		if err != nil {
+			return err
+		}
+		statements = append(statements, stmnts...)
+	}
+
 	if err := schemaheroDB.ApplySync(statements); err != nil {
 		return errors.Wrap(err, "failed to apply sync")
 	}
 
 	return nil
 }
+
+// translateTableSchema fills in the schema for driver from the rqlite schema when the
+// spec does not declare one for that driver.
+func translateTableSchema(driver string, schema *schemasv1alpha4.TableSchema) error {
+	if schema == nil || schema.RQLite == nil {
+		return nil
+	}
+
+	switch driver {
+	case DriverSqlite:
+		if schema.SQLite != nil {
+			return nil
+		}
+		sqliteSchema := &schemasv1alpha4.SqliteTableSchema{}
+		if err := convertSchema(schema.RQLite, sqliteSchema); err != nil {
+			return err
+		}
+		// tables are not strict so that they accept the same values as the rqlite tables they are translated from
+		sqliteSchema.Strict = false
+		schema.SQLite = sqliteSchema
+
+	case DriverPostgres:
+		if schema.Postgres != nil {
+			return nil
+		}
+		postgresSchema := &schemasv1alpha4.PostgresqlTableSchema{}
+		if err := convertSchema(schema.RQLite, postgresSchema); err != nil {
+			return err
+		}
+		for _, column := range postgresSchema.Columns {
+			column.Type = postgresColumnType(column.Type)
+		}
+		schema.Postgres = postgresSchema
+	}
+
+	return nil
+}
+
+// convertSchema copies a schema between drivers that share the same structure.
+func convertSchema(from interface{}, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal schema")
+	}
+	if err := json.Unmarshal(b, to); err != nil {
+		return errors.Wrap(err, "failed to unmarshal schema")
+	}
+	return nil
+}
+
+func postgresColumnType(sqliteType string) string {
+	switch strings.ToLower(sqliteType) {
+	case "integer":
+		return "bigint"
+	case "real":
+		return "double precision"
+	case "blob":
+		return "bytea"
+	}
+	return sqliteType
+}
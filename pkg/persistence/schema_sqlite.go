@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	schemasv1alpha4 "github.com/schemahero/schemahero/pkg/apis/schemas/v1alpha4"
+	schemaherosqlite "github.com/schemahero/schemahero/pkg/database/sqlite"
+	"github.com/schemahero/schemahero/pkg/database/types"
+)
+
+// updateSqliteSchema applies the table specs to a sqlite database. Schemahero connects to sqlite with a driver
+// that requires cgo, so the statements are planned and executed here with the pure go driver instead.
+// Only tables, columns and indexes are added or removed, changes to existing columns are not supported.
+func updateSqliteSchema(uri string, tables []schemasv1alpha4.TableSpec) error {
+	conn, err := sql.Open(DriverSqlite, uri)
+	if err != nil {
+		return errors.Wrap(err, "failed to open sqlite database")
+	}
+	defer conn.Close()
+
+	statements := []string{}
+	for _, table := range tables {
+		stmnts, err := planSqliteTable(conn, table)
+		if err != nil {
+			return errors.Wrapf(err, "failed to plan table %s", table.Name)
+		}
+		statements = append(statements, stmnts...)
+	}
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(statement); err != nil {
+			return errors.Wrapf(err, "failed to execute %q", statement)
+		}
+	}
+
+	return nil
+}
+
+func planSqliteTable(conn *sql.DB, table schemasv1alpha4.TableSpec) ([]string, error) {
+	if table.Schema == nil || table.Schema.SQLite == nil {
+		return []string{}, nil
+	}
+	schema := table.Schema.SQLite
+
+	tableExists := 0
+	row := conn.QueryRow("select count(1) from sqlite_master where type = 'table' and name = ?", table.Name)
+	if err := row.Scan(&tableExists); err != nil {
+		return nil, errors.Wrap(err, "failed to check if table exists")
+	}
+
+	if schema.IsDeleted {
+		if tableExists == 0 {
+			return []string{}, nil
+		}
+		return []string{fmt.Sprintf(`drop table "%s"`, table.Name)}, nil
+	}
+
+	seedDataStatements := []string{}
+	if table.SeedData != nil {
+		stmnts, err := schemaherosqlite.SeedDataStatements(table.Name, table.SeedData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create seed data statements")
+		}
+		seedDataStatements = stmnts
+	}
+
+	if tableExists == 0 {
+		statements, err := schemaherosqlite.CreateTableStatements(table.Name, schema)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create table statements")
+		}
+		return append(statements, seedDataStatements...), nil
+	}
+
+	existingColumns, err := listSqliteValues(conn, "select name from pragma_table_info(?)", table.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list columns")
+	}
+	existingIndexes, err := listSqliteValues(conn, "select name from sqlite_master where type = 'index' and tbl_name = ? and sql is not null", table.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list indexes")
+	}
+
+	statements := []string{}
+	desiredColumns := map[string]bool{}
+	for _, column := range schema.Columns {
+		desiredColumns[column.Name] = true
+		if existingColumns[column.Name] {
+			continue
+		}
+		statement, err := schemaherosqlite.InsertColumnStatement(table.Name, column)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create add column statement for %s", column.Name)
+		}
+		statements = append(statements, statement)
+	}
+
+	desiredIndexes := map[string]bool{}
+	for _, index := range schema.Indexes {
+		name := index.Name
+		if name == "" {
+			name = types.GenerateSqliteIndexName(table.Name, index)
+		}
+		desiredIndexes[name] = true
+		if !existingIndexes[name] {
+			statements = append(statements, schemaherosqlite.AddIndexStatement(table.Name, index))
+		}
+	}
+	// indexes are dropped before their columns
+	for name := range existingIndexes {
+		if !desiredIndexes[name] {
+			statements = append(statements, fmt.Sprintf("drop index if exists %s", name))
+		}
+	}
+
+	for name := range existingColumns {
+		if !desiredColumns[name] {
+			statements = append(statements, fmt.Sprintf(`alter table "%s" drop column "%s"`, table.Name, name))
+		}
+	}
+
+	return append(statements, seedDataStatements...), nil
+}
+
+func listSqliteValues(conn *sql.DB, query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	values := map[string]bool{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		values[value] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate")
+	}
+
+	return values, nil
+}
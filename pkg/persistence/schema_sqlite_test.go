@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateSqliteSchema(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "kotsadm.db")
+
+	v1 := fstest.MapFS{
+		"app.yaml": &fstest.MapFile{Data: []byte(`apiVersion: schemas.schemahero.io/v1alpha4
+kind: Table
+metadata:
+  name: app
+spec:
+  name: app
+  schema:
+    rqlite:
+      primaryKey:
+      - id
+      columns:
+      - name: id
+        type: text
+      - name: slug
+        type: text
+      - name: legacy
+        type: text
+`)},
+	}
+	v2 := fstest.MapFS{
+		"app.yaml": &fstest.MapFile{Data: []byte(`apiVersion: schemas.schemahero.io/v1alpha4
+kind: Table
+metadata:
+  name: app
+spec:
+  name: app
+  schema:
+    rqlite:
+      primaryKey:
+      - id
+      columns:
+      - name: id
+        type: text
+      - name: slug
+        type: text
+      - name: created_at
+        type: integer
+      indexes:
+      - columns:
+        - slug
+        name: app_slug_idx
+        isUnique: true
+`)},
+	}
+
+	require.NoError(t, UpdateDBSchemaFromFS(DriverSqlite, dsn, v1))
+	// applying the same schema again is a no-op
+	require.NoError(t, UpdateDBSchemaFromFS(DriverSqlite, dsn, v1))
+	require.NoError(t, UpdateDBSchemaFromFS(DriverSqlite, dsn, v2))
+
+	db, err := Open(DriverSqlite, dsn)
+	require.NoError(t, err)
+
+	_, err = db.WriteOneParameterized(ParameterizedStatement{
+		Query:     `insert into app (id, slug, created_at) values (?, ?, ?)`,
+		Arguments: []interface{}{"1", "my-app", 1},
+	})
+	require.NoError(t, err)
+
+	_, err = db.WriteOneParameterized(ParameterizedStatement{
+		Query:     `insert into app (id, slug, created_at) values (?, ?, ?)`,
+		Arguments: []interface{}{"2", "my-app", 2},
+	})
+	assert.Error(t, err, "slug index should be unique")
+
+	_, err = db.QueryOne(`select legacy from app`)
+	assert.Error(t, err, "legacy column should be dropped")
+}
@@ -0,0 +1,21 @@
+//go:build !testing
+
+package persistence
+
+import (
+	"fmt"
+)
+
+func MustGetDBSession() DB {
+	if db != nil {
+		return db
+	}
+	newDB, err := Open(Driver(), uri)
+	if err != nil {
+		fmt.Printf("error connecting to %s: %v\n", Driver(), err)
+		panic(err)
+	}
+
+	db = newDB
+	return db
+}
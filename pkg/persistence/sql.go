@@ -0,0 +1,466 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+// sqlDB implements DB on top of database/sql for the sqlite and postgres drivers.
+type sqlDB struct {
+	driver string
+	db     *sql.DB
+}
+
+func openSQL(driver string, uri string) (DB, error) {
+	conn, err := sql.Open(driver, uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s database", driver)
+	}
+	if driver == DriverSqlite {
+		// sqlite only supports a single writer
+		conn.SetMaxOpenConns(1)
+	}
+
+	return NewSQLDB(driver, conn), nil
+}
+
+// NewSQLDB returns a DB backed by an existing database/sql connection.
+func NewSQLDB(driver string, conn *sql.DB) DB {
+	return &sqlDB{driver: driver, db: conn}
+}
+
+func (s *sqlDB) QueryOne(sqlStatement string) (QueryResult, error) {
+	return s.QueryOneParameterized(ParameterizedStatement{Query: sqlStatement})
+}
+
+func (s *sqlDB) QueryOneParameterized(statement ParameterizedStatement) (QueryResult, error) {
+	query, args := s.prepare(statement)
+
+	rows, err := s.db.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return QueryResult{Err: err}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return QueryResult{Err: err}, err
+	}
+
+	result := &sqlRows{columns: columns, rowNumber: -1}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return QueryResult{Err: err}, err
+		}
+		for i := range values {
+			values[i] = normalizeValue(values[i])
+		}
+		result.values = append(result.values, values)
+	}
+	if err := rows.Err(); err != nil {
+		return QueryResult{Err: err}, err
+	}
+
+	return QueryResult{rows: result}, nil
+}
+
+func (s *sqlDB) WriteOne(sqlStatement string) (WriteResult, error) {
+	return s.WriteOneParameterized(ParameterizedStatement{Query: sqlStatement})
+}
+
+func (s *sqlDB) WriteOneParameterized(statement ParameterizedStatement) (WriteResult, error) {
+	wrs, err := s.WriteParameterized([]ParameterizedStatement{statement})
+	if len(wrs) == 0 {
+		return WriteResult{Err: err}, err
+	}
+	return wrs[0], err
+}
+
+// WriteParameterized executes all statements in a single transaction.
+func (s *sqlDB) WriteParameterized(statements []ParameterizedStatement) ([]WriteResult, error) {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	results := []WriteResult{}
+	for _, statement := range statements {
+		query, args := s.prepare(statement)
+
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			results = append(results, WriteResult{Err: err})
+			return results, errors.Wrap(err, "failed to execute statement")
+		}
+
+		wr := WriteResult{}
+		wr.RowsAffected, _ = res.RowsAffected()
+		if s.driver != DriverPostgres {
+			wr.LastInsertID, _ = res.LastInsertId()
+		}
+		results = append(results, wr)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return results, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return results, nil
+}
+
+func (s *sqlDB) prepare(statement ParameterizedStatement) (string, []interface{}) {
+	args := make([]interface{}, 0, len(statement.Arguments))
+	for _, arg := range statement.Arguments {
+		args = append(args, normalizeArgument(arg))
+	}
+
+	if s.driver == DriverPostgres {
+		return toPostgresDialect(statement.Query), args
+	}
+	return statement.Query, args
+}
+
+// normalizeArgument converts arguments the same way the rqlite json api would store them.
+func normalizeArgument(arg interface{}) interface{} {
+	switch a := arg.(type) {
+	case bool:
+		if a {
+			return int64(1)
+		}
+		return int64(0)
+	case time.Time:
+		return a.Format(time.RFC3339Nano)
+	case *time.Time:
+		if a == nil {
+			return nil
+		}
+		return a.Format(time.RFC3339Nano)
+	}
+	return arg
+}
+
+// normalizeValue converts a column value to one of the types returned by rqlite:
+// string, int64, float64 or nil.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+// toPostgresDialect rewrites a sqlite statement for postgres. Placeholders are
+// numbered, ifnull becomes coalesce, and the scalar form of max becomes greatest.
+func toPostgresDialect(query string) string {
+	var b strings.Builder
+	n := 0
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inQuote = !inQuote
+			b.WriteByte(c)
+		case c == '?' && !inQuote:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+		case !inQuote && hasFunctionAt(query, i, "ifnull"):
+			b.WriteString("coalesce")
+			i += len("ifnull") - 1
+		case !inQuote && hasFunctionAt(query, i, "max") && isScalarCall(query, i+len("max")):
+			b.WriteString("greatest")
+			i += len("max") - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// hasFunctionAt reports whether a call to the function name starts at index i.
+func hasFunctionAt(query string, i int, name string) bool {
+	if i > 0 && isIdentChar(query[i-1]) {
+		return false
+	}
+	if i+len(name) > len(query) || !strings.EqualFold(query[i:i+len(name)], name) {
+		return false
+	}
+	rest := strings.TrimLeft(query[i+len(name):], " ")
+	return strings.HasPrefix(rest, "(")
+}
+
+// isScalarCall reports whether the parenthesized argument list starting after index i
+// has more than one top-level argument.
+func isScalarCall(query string, i int) bool {
+	depth := 0
+	for ; i < len(query); i++ {
+		switch query[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return false
+			}
+		case ',':
+			if depth == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// sqlRows holds a fully read result set, mirroring how rqlite returns query results.
+type sqlRows struct {
+	columns   []string
+	values    [][]interface{}
+	rowNumber int64
+}
+
+func (r *sqlRows) Next() bool {
+	if r.rowNumber >= int64(len(r.values)-1) {
+		return false
+	}
+	r.rowNumber++
+	return true
+}
+
+func (r *sqlRows) NumRows() int64 {
+	return int64(len(r.values))
+}
+
+func (r *sqlRows) Scan(dest ...interface{}) error {
+	if r.rowNumber == -1 {
+		return errors.New("you need to Next() before you Scan()")
+	}
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("expected %d columns but got %d vars", len(r.columns), len(dest))
+	}
+
+	row := r.values[r.rowNumber]
+	for n, d := range dest {
+		if err := scanValue(d, row[n]); err != nil {
+			return errors.Wrapf(err, "failed to scan column %s", r.columns[n])
+		}
+	}
+
+	return nil
+}
+
+func scanValue(dest interface{}, src interface{}) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = src
+	case *string:
+		if src == nil {
+			return nil
+		}
+		s, ok := src.(string)
+		if !ok {
+			return fmt.Errorf("invalid string type:%T val:%v", src, src)
+		}
+		*d = s
+	case *int:
+		if src == nil {
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = int(i)
+	case *int64:
+		if src == nil {
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = i
+	case *float64:
+		if src == nil {
+			return nil
+		}
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		*d = f
+	case *bool:
+		if src == nil {
+			return nil
+		}
+		b, err := toBool(src)
+		if err != nil {
+			return err
+		}
+		*d = b
+	case *time.Time:
+		if src == nil {
+			return nil
+		}
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		*d = t
+	case *NullString:
+		if src == nil {
+			*d = NullString{}
+			return nil
+		}
+		s, ok := src.(string)
+		if !ok {
+			return fmt.Errorf("invalid string type:%T val:%v", src, src)
+		}
+		*d = NullString{Valid: true, String: s}
+	case *NullInt64:
+		if src == nil {
+			*d = NullInt64{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = NullInt64{Valid: true, Int64: i}
+	case *NullInt32:
+		if src == nil {
+			*d = NullInt32{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = NullInt32{Valid: true, Int32: int32(i)}
+	case *NullInt16:
+		if src == nil {
+			*d = NullInt16{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = NullInt16{Valid: true, Int16: int16(i)}
+	case *NullFloat64:
+		if src == nil {
+			*d = NullFloat64{}
+			return nil
+		}
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		*d = NullFloat64{Valid: true, Float64: f}
+	case *NullBool:
+		if src == nil {
+			*d = NullBool{}
+			return nil
+		}
+		b, err := toBool(src)
+		if err != nil {
+			return err
+		}
+		*d = NullBool{Valid: true, Bool: b}
+	case *NullTime:
+		if src == nil {
+			*d = NullTime{}
+			return nil
+		}
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		*d = NullTime{Valid: true, Time: t}
+	default:
+		return fmt.Errorf("unknown destination type %T", dest)
+	}
+
+	return nil
+}
+
+func toInt64(src interface{}) (int64, error) {
+	switch s := src.(type) {
+	case int64:
+		return s, nil
+	case float64:
+		return int64(s), nil
+	case string:
+		return strconv.ParseInt(s, 10, 64)
+	}
+	return 0, fmt.Errorf("invalid int type:%T val:%v", src, src)
+}
+
+func toFloat64(src interface{}) (float64, error) {
+	switch s := src.(type) {
+	case int64:
+		return float64(s), nil
+	case float64:
+		return s, nil
+	case string:
+		return strconv.ParseFloat(s, 64)
+	}
+	return 0, fmt.Errorf("invalid float type:%T val:%v", src, src)
+}
+
+func toBool(src interface{}) (bool, error) {
+	switch s := src.(type) {
+	case int64:
+		return strconv.ParseBool(strconv.FormatInt(s, 10))
+	case float64:
+		return strconv.ParseBool(strconv.FormatFloat(s, 'g', -1, 64))
+	case string:
+		return strconv.ParseBool(s)
+	}
+	return false, fmt.Errorf("invalid bool type:%T val:%v", src, src)
+}
+
+func toTime(src interface{}) (time.Time, error) {
+	switch s := src.(type) {
+	case string:
+		if t, err := time.Parse("2006-01-02 15:04:05", s); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, s)
+	case float64:
+		return time.Unix(int64(s), 0), nil
+	case int64:
+		return time.Unix(s, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time type:%T val:%v", src, src)
+}
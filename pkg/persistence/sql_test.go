@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"testing"
+)
+
+func Test_toPostgresDialect(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "placeholders",
+			query: `select id from app where slug = ? and install_state = ?`,
+			want:  `select id from app where slug = $1 and install_state = $2`,
+		},
+		{
+			name:  "quoted question marks are left alone",
+			query: `select id from app where name = '?' and slug = ?`,
+			want:  `select id from app where name = '?' and slug = $1`,
+		},
+		{
+			name:  "scalar max and ifnull",
+			query: `update app set current_sequence = ifnull(max(current_sequence, ?), 0), name = ? where id = ?`,
+			want:  `update app set current_sequence = coalesce(greatest(current_sequence, $1), 0), name = $2 where id = $3`,
+		},
+		{
+			name:  "aggregate max is unchanged",
+			query: `select max(sequence) from app_version where app_id = ?`,
+			want:  `select max(sequence) from app_version where app_id = $1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toPostgresDialect(tt.query); got != tt.want {
+				t.Errorf("toPostgresDialect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package policy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/audit"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/rbac"
 	rbactypes "github.com/replicatedhq/kots/pkg/rbac/types"
@@ -31,6 +33,8 @@ func (e RBACError) Abort(w http.ResponseWriter) error {
 type Middleware struct {
 	KOTSStore store.Store
 	Roles     []rbactypes.Role
+	// RolesGetter returns the roles to enforce for a request, Roles are used if it is not set
+	RolesGetter func(ctx context.Context) ([]rbactypes.Role, error)
 }
 
 func NewMiddleware(kotsStore store.Store, roles []rbactypes.Role) *Middleware {
@@ -59,7 +63,14 @@ func (m *Middleware) EnforceAccess(p *Policy, handler http.HandlerFunc) http.Han
 
 			rbacErr := NewRBACError(resource)
 
-			allow, err := rbac.CheckAccess(r.Context(), m.Roles, action, resource, sess.Roles)
+			roles, err := m.getRoles(r.Context())
+			if err != nil {
+				logger.Error(errors.Wrap(err, "failed to get roles"))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			allow, err := rbac.CheckAccess(r.Context(), roles, action, resource, sess.Roles)
 			if err != nil {
 				logger.Error(errors.Wrapf(err, "failed to check access to resource %q", resource))
 				w.WriteHeader(http.StatusInternalServerError)
@@ -75,6 +86,28 @@ func (m *Middleware) EnforceAccess(p *Policy, handler http.HandlerFunc) http.Han
 	}
 }
 
+func (m *Middleware) getRoles(ctx context.Context) ([]rbactypes.Role, error) {
+	if m.RolesGetter == nil {
+		return m.Roles, nil
+	}
+	return m.RolesGetter(ctx)
+}
+
+// EnforceAccessAndAudit enforces access like EnforceAccess and records the request and its outcome in the audit log
+func (m *Middleware) EnforceAccessAndAudit(p *Policy, action string, handler http.HandlerFunc) http.HandlerFunc {
+	enforceAccess := m.EnforceAccess(p, handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := audit.NewStatusRecorder(w)
+		enforceAccess(recorder, r)
+
+		_, resource, err := p.execute(r, m.KOTSStore)
+		if err != nil {
+			resource = p.resource
+		}
+		audit.RecordRequest(m.KOTSStore, r, action, resource, recorder.StatusCode)
+	}
+}
+
 // TODO: move everything below here to a shared package
 
 type ErrorResponse struct {
@@ -63,6 +63,13 @@ var (
 	PasswordChange = Must(NewPolicy(ActionWrite, "passwordupdate."))
 )
 
+// Encryption key
+
+var (
+	EncryptionKeyRead  = Must(NewPolicy(ActionRead, "encryptionkey."))
+	EncryptionKeyWrite = Must(NewPolicy(ActionWrite, "encryptionkey."))
+)
+
 // Kotsadm Identity Service
 
 var (
@@ -153,6 +160,19 @@ var (
 	AppDownstreamConfigWrite = Must(NewPolicy(ActionWrite, "app.{{.appSlug}}.downstream.config."))
 )
 
+// API tokens
+
+var (
+	APITokenRead  = Must(NewPolicy(ActionRead, "apitoken."))
+	APITokenWrite = Must(NewPolicy(ActionWrite, "apitoken."))
+)
+
+// Audit log
+
+var (
+	AuditLogRead = Must(NewPolicy(ActionRead, "auditlog."))
+)
+
 // Helm
 
 var (
@@ -3,12 +3,18 @@ package policy
 import (
 	"bytes"
 	"net/http"
+	"sort"
 	"text/template"
 
 	"github.com/gorilla/mux"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/rbac"
 	"github.com/replicatedhq/kots/pkg/store"
 )
 
+// resources are the resource templates of all policies, custom roles are validated against them
+var resources = []string{}
+
 type VarsGetter func(kotsStore store.Store, vars map[string]string) (map[string]string, error)
 
 type Policy struct {
@@ -20,6 +26,9 @@ type Policy struct {
 
 func NewPolicy(action, resource string, fns ...VarsGetter) (policy *Policy, err error) {
 	policy = &Policy{action: action, resource: resource, varsGetterFns: fns}
+	if resource != "" {
+		resources = append(resources, resource)
+	}
 	policy.resourceTemplate, err = template.New(resource).Option("missingkey=error").Parse(resource)
 	return
 }
@@ -31,6 +40,25 @@ func Must(p *Policy, err error) *Policy {
 	return p
 }
 
+// Resources returns the resource templates of all policies, e.g. "app.{{.appSlug}}.downstream."
+func Resources() []string {
+	unique := map[string]bool{}
+	result := []string{}
+	for _, resource := range resources {
+		if !unique[resource] {
+			unique[resource] = true
+			result = append(result, resource)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ValidateRoles validates the custom roles in the identity config against the policy resources
+func ValidateRoles(spec kotsv1beta1.IdentityConfigSpec) error {
+	return rbac.ValidateRoles(spec, Resources())
+}
+
 func (p *Policy) execute(r *http.Request, kotsStore store.Store) (action, resource string, err error) {
 	vars := mux.Vars(r)
 	for _, fn := range p.varsGetterFns {
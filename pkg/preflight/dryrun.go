@@ -0,0 +1,74 @@
+package preflight
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	troubleshootanalyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
+	"go.uber.org/zap"
+)
+
+// DryRun runs the preflight checks of a rendered archive that is not stored as an app version, e.g. one rendered
+// with config values that have not been saved yet. The results are returned and nothing is written to the store.
+// Errors while running the checks are returned in the results, the same way they are for a stored version.
+func DryRun(app *apptypes.App, sequence int64, archiveDir string) (*types.PreflightResults, error) {
+	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archiveDir, "upstream"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load rendered kots kinds")
+	}
+
+	renderedSpec, err := renderSpec(app, sequence, "", true, kotsKinds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render preflight spec")
+	}
+
+	preflightSpec, err := kotsutil.LoadPreflightFromContents(renderedSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load rendered preflight")
+	}
+
+	numAnalyzers := 0
+	for _, analyzer := range preflightSpec.Spec.Analyzers {
+		if !troubleshootanalyze.GetExcludeFlag(analyzer).BoolOrDefaultFalse() {
+			numAnalyzers += 1
+		}
+	}
+	if numAnalyzers == 0 {
+		return &types.PreflightResults{}, nil
+	}
+
+	ignoreRBAC, err := store.GetStore().GetIgnoreRBACErrors(app.ID, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ignore rbac flag")
+	}
+
+	logger.Debug("executing dry run preflight checks",
+		zap.String("appID", app.ID),
+		zap.Int64("sequence", sequence))
+
+	progressChan := make(chan interface{}, 0) // the collectors block until progress is read
+	defer close(progressChan)
+	go func() {
+		for msg := range progressChan {
+			if err, ok := msg.(error); ok {
+				logger.Errorf("error while running dry run preflights: %v", err)
+			}
+		}
+	}()
+
+	results, err := collectAndAnalyze(preflightSpec, ignoreRBAC, progressChan)
+	if err != nil {
+		return &types.PreflightResults{
+			Errors: []*types.PreflightError{
+				{Error: err.Error()},
+			},
+		}, nil
+	}
+
+	return results, nil
+}
@@ -17,7 +17,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func setPreflightResult(appID string, sequence int64, preflightResults *types.PreflightResults, preflightRunError error) error {
+func setPreflightResult(appID string, sequence int64, trigger types.PreflightTrigger, preflightResults *types.PreflightResults, preflightRunError error) error {
 	if preflightRunError != nil {
 		if preflightResults.Errors == nil {
 			preflightResults.Errors = []*types.PreflightError{}
@@ -33,7 +33,7 @@ func setPreflightResult(appID string, sequence int64, preflightResults *types.Pr
 		return errors.Wrap(err, "failed to marshal preflight results")
 	}
 
-	if err := store.GetStore().SetPreflightResults(appID, sequence, b); err != nil {
+	if err := store.GetStore().SetPreflightResults(appID, sequence, b, trigger); err != nil {
 		return errors.Wrap(err, "failed to set preflight results")
 	}
 
@@ -42,7 +42,7 @@ func setPreflightResult(appID string, sequence int64, preflightResults *types.Pr
 
 // execute will execute the preflights using spec in preflightSpec.
 // This spec should be rendered, no template functions remaining
-func execute(appID string, sequence int64, preflightSpec *troubleshootv1beta2.Preflight, ignorePermissionErrors bool) (*types.PreflightResults, error) {
+func execute(appID string, sequence int64, trigger types.PreflightTrigger, preflightSpec *troubleshootv1beta2.Preflight, ignorePermissionErrors bool) (*types.PreflightResults, error) {
 	logger.Debug("executing preflight checks",
 		zap.String("appID", appID),
 		zap.Int64("sequence", sequence))
@@ -97,16 +97,30 @@ func execute(appID string, sequence int64, preflightSpec *troubleshootv1beta2.Pr
 		defer completeMx.Unlock()
 
 		isComplete = true
-		if err := setPreflightResult(appID, sequence, uploadPreflightResults, preflightRunError); err != nil {
+		if err := setPreflightResult(appID, sequence, trigger, uploadPreflightResults, preflightRunError); err != nil {
 			logger.Error(errors.Wrap(err, "failed to set preflight results"))
 			return
 		}
 	}()
 
-	restConfig, err := k8sutil.GetClusterConfig()
+	results, err := collectAndAnalyze(preflightSpec, ignorePermissionErrors, progressChan)
 	if err != nil {
 		preflightRunError = err
-		return nil, errors.Wrap(err, "failed to get cluster config")
+		return nil, errors.Wrap(err, "failed to run preflight checks")
+	}
+	uploadPreflightResults = results
+
+	return uploadPreflightResults, nil
+}
+
+// collectAndAnalyze runs the collectors and analyzers of a rendered preflight spec. Collection progress is sent on progressChan.
+// RBAC errors are returned in the results rather than as an error, unless ignorePermissionErrors is set.
+func collectAndAnalyze(preflightSpec *troubleshootv1beta2.Preflight, ignorePermissionErrors bool, progressChan chan interface{}) (*types.PreflightResults, error) {
+	uploadPreflightResults := &types.PreflightResults{}
+
+	restConfig, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	collectOpts := troubleshootpreflight.CollectOpts{
@@ -119,14 +133,12 @@ func execute(appID string, sequence int64, preflightSpec *troubleshootv1beta2.Pr
 	logger.Debug("preflight collect phase")
 	collectResults, err := troubleshootpreflight.Collect(collectOpts, preflightSpec)
 	if err != nil && !isPermissionsError(err) {
-		preflightRunError = err
-		return nil, errors.Wrap(err, "failed to collect")
+		return nil, err
 	}
 
 	clusterCollectResult, ok := collectResults.(troubleshootpreflight.ClusterCollectResult)
 	if !ok {
-		preflightRunError = errors.Errorf("unexpected result type: %T", collectResults)
-		return nil, preflightRunError
+		return nil, errors.Errorf("unexpected result type: %T", collectResults)
 	}
 
 	if isPermissionsError(err) {
@@ -0,0 +1,62 @@
+package preflight
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+// GetResultsHistory returns the preflight runs of an app with the outcome of their checks, newest first.
+// Runs of all versions are returned if sequence is nil.
+func GetResultsHistory(appID string, sequence *int64) ([]types.PreflightRunSummary, error) {
+	runs, err := store.GetStore().GetPreflightResultsHistory(appID, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get preflight results history")
+	}
+
+	summaries := []types.PreflightRunSummary{}
+	for _, run := range runs {
+		summaries = append(summaries, SummarizeRun(run))
+	}
+
+	return summaries, nil
+}
+
+// SummarizeRun lists the failing and warning checks of a preflight run so that runs can be compared
+func SummarizeRun(run types.PreflightRun) types.PreflightRunSummary {
+	summary := types.PreflightRunSummary{
+		PreflightRun: run,
+		Failing:      []string{},
+		Warning:      []string{},
+		Errors:       []string{},
+	}
+
+	preflightResults := types.PreflightResults{}
+	if err := json.Unmarshal([]byte(run.Result), &preflightResults); err != nil {
+		summary.State = "fail"
+		summary.Errors = append(summary.Errors, "failed to parse preflight results")
+		return summary
+	}
+
+	summary.State = GetPreflightState(&preflightResults)
+	for _, result := range preflightResults.Results {
+		if result == nil {
+			continue
+		}
+		if result.IsFail {
+			summary.Failing = append(summary.Failing, result.Title)
+		} else if result.IsWarn {
+			summary.Warning = append(summary.Warning, result.Title)
+		}
+	}
+	for _, preflightError := range preflightResults.Errors {
+		if preflightError == nil {
+			continue
+		}
+		summary.Errors = append(summary.Errors, preflightError.Error)
+	}
+
+	return summary
+}
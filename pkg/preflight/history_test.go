@@ -0,0 +1,65 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      string
+		wantState   string
+		wantFailing []string
+		wantWarning []string
+		wantErrors  []string
+	}{
+		{
+			name:        "pass",
+			result:      `{"results":[{"isPass":true,"title":"Kubernetes version"}]}`,
+			wantState:   "pass",
+			wantFailing: []string{},
+			wantWarning: []string{},
+			wantErrors:  []string{},
+		},
+		{
+			name:        "fail and warn",
+			result:      `{"results":[{"isFail":true,"title":"Memory"},{"isWarn":true,"title":"CPU"},{"isPass":true,"title":"Kubernetes version"}]}`,
+			wantState:   "fail",
+			wantFailing: []string{"Memory"},
+			wantWarning: []string{"CPU"},
+			wantErrors:  []string{},
+		},
+		{
+			name:        "run error",
+			result:      `{"errors":[{"error":"failed to collect","isRbac":false}]}`,
+			wantState:   "fail",
+			wantFailing: []string{},
+			wantWarning: []string{},
+			wantErrors:  []string{"failed to collect"},
+		},
+		{
+			name:        "unparseable",
+			result:      `not json`,
+			wantState:   "fail",
+			wantFailing: []string{},
+			wantWarning: []string{},
+			wantErrors:  []string{"failed to parse preflight results"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := types.PreflightRun{ID: "run", Trigger: types.PreflightTriggerRerun, Result: tt.result}
+
+			got := SummarizeRun(run)
+
+			assert.Equal(t, run, got.PreflightRun)
+			assert.Equal(t, tt.wantState, got.State)
+			assert.Equal(t, tt.wantFailing, got.Failing)
+			assert.Equal(t, tt.wantWarning, got.Warning)
+			assert.Equal(t, tt.wantErrors, got.Errors)
+		})
+	}
+}
@@ -41,7 +41,8 @@ const (
 	SpecDataKey = "preflight-spec"
 )
 
-func Run(appID string, appSlug string, sequence int64, isAirgap bool, archiveDir string) error {
+// Run runs the preflight checks of an app version in the background. The trigger is recorded in the preflight history.
+func Run(appID string, appSlug string, sequence int64, isAirgap bool, trigger types.PreflightTrigger, archiveDir string) error {
 	renderedKotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archiveDir, "upstream"))
 	if err != nil {
 		return errors.Wrap(err, "failed to load rendered kots kinds")
@@ -108,7 +109,7 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, archiveDir
 		var preflightErr error
 		defer func() {
 			if preflightErr != nil {
-				err := setPreflightResult(appID, sequence, &types.PreflightResults{}, preflightErr)
+				err := setPreflightResult(appID, sequence, trigger, &types.PreflightResults{}, preflightErr)
 				if err != nil {
 					logger.Error(errors.Wrap(err, "failed to set preflight results"))
 					return
@@ -138,7 +139,7 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, archiveDir
 
 		go func() {
 			logger.Debug("preflight checks beginning")
-			uploadPreflightResults, err := execute(appID, sequence, preflight, ignoreRBAC)
+			uploadPreflightResults, err := execute(appID, sequence, trigger, preflight, ignoreRBAC)
 			if err != nil {
 				logger.Error(errors.Wrap(err, "failed to run preflight checks"))
 				return
@@ -263,51 +264,9 @@ func GetPreflightCommand(appSlug string) []string {
 }
 
 func CreateRenderedSpec(app *apptypes.App, sequence int64, origin string, inCluster bool, kotsKinds *kotsutil.KotsKinds) error {
-	builtPreflight := kotsKinds.Preflight.DeepCopy()
-	if builtPreflight == nil {
-		builtPreflight = &troubleshootv1beta2.Preflight{
-			TypeMeta: v1.TypeMeta{
-				Kind:       "Preflight",
-				APIVersion: "troubleshoot.sh/v1beta2",
-			},
-			ObjectMeta: v1.ObjectMeta{
-				Name: "default-preflight",
-			},
-		}
-	}
-
-	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(app.ID)
+	renderedSpec, err := renderSpec(app, sequence, origin, inCluster, kotsKinds)
 	if err != nil {
-		return errors.Wrap(err, "failed to get registry settings for app")
-	}
-
-	injectDefaultPreflights(builtPreflight, kotsKinds, registrySettings)
-
-	collectors, err := registry.UpdateCollectorSpecsWithRegistryData(builtPreflight.Spec.Collectors, registrySettings, kotsKinds.Installation, kotsKinds.License, &kotsKinds.KotsApplication)
-	if err != nil {
-		return errors.Wrap(err, "failed to rewrite images in preflight")
-	}
-	builtPreflight.Spec.Collectors = collectors
-
-	baseURL := os.Getenv("API_ADVERTISE_ENDPOINT")
-	if inCluster {
-		baseURL = os.Getenv("API_ENDPOINT")
-	} else if origin != "" {
-		baseURL = origin
-	}
-	builtPreflight.Spec.UploadResultsTo = fmt.Sprintf("%s/api/v1/preflight/app/%s/sequence/%d", baseURL, app.Slug, sequence)
-
-	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
-	var b bytes.Buffer
-	if err := s.Encode(builtPreflight, &b); err != nil {
-		return errors.Wrap(err, "failed to encode preflight")
-	}
-
-	templatedSpec := b.Bytes()
-
-	renderedSpec, err := helper.RenderAppFile(app, &sequence, templatedSpec, kotsKinds, util.PodNamespace)
-	if err != nil {
-		return errors.Wrap(err, "failed render preflight spec")
+		return errors.Wrap(err, "failed to render preflight spec")
 	}
 
 	clientset, err := k8sutil.GetClientset()
@@ -358,6 +317,59 @@ func CreateRenderedSpec(app *apptypes.App, sequence int64, origin string, inClus
 	return nil
 }
 
+// renderSpec renders the preflight spec of an app version, with the default preflights and registry rewrites applied.
+// Results are uploaded to the admin console at the origin, or at the in cluster endpoint if inCluster is set.
+func renderSpec(app *apptypes.App, sequence int64, origin string, inCluster bool, kotsKinds *kotsutil.KotsKinds) ([]byte, error) {
+	builtPreflight := kotsKinds.Preflight.DeepCopy()
+	if builtPreflight == nil {
+		builtPreflight = &troubleshootv1beta2.Preflight{
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Preflight",
+				APIVersion: "troubleshoot.sh/v1beta2",
+			},
+			ObjectMeta: v1.ObjectMeta{
+				Name: "default-preflight",
+			},
+		}
+	}
+
+	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(app.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get registry settings for app")
+	}
+
+	injectDefaultPreflights(builtPreflight, kotsKinds, registrySettings)
+
+	collectors, err := registry.UpdateCollectorSpecsWithRegistryData(builtPreflight.Spec.Collectors, registrySettings, kotsKinds.Installation, kotsKinds.License, &kotsKinds.KotsApplication)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to rewrite images in preflight")
+	}
+	builtPreflight.Spec.Collectors = collectors
+
+	baseURL := os.Getenv("API_ADVERTISE_ENDPOINT")
+	if inCluster {
+		baseURL = os.Getenv("API_ENDPOINT")
+	} else if origin != "" {
+		baseURL = origin
+	}
+	builtPreflight.Spec.UploadResultsTo = fmt.Sprintf("%s/api/v1/preflight/app/%s/sequence/%d", baseURL, app.Slug, sequence)
+
+	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+	var b bytes.Buffer
+	if err := s.Encode(builtPreflight, &b); err != nil {
+		return nil, errors.Wrap(err, "failed to encode preflight")
+	}
+
+	templatedSpec := b.Bytes()
+
+	renderedSpec, err := helper.RenderAppFile(app, &sequence, templatedSpec, kotsKinds, util.PodNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed render preflight spec")
+	}
+
+	return renderedSpec, nil
+}
+
 func injectDefaultPreflights(preflight *troubleshootv1beta2.Preflight, kotskinds *kotsutil.KotsKinds, registrySettings registrytypes.RegistrySettings) {
 	if registrySettings.IsValid() && registrySettings.IsReadOnly {
 		// Get images from Installation.KnownImages, see UpdateCollectorSpecsWithRegistryData
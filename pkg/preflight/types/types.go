@@ -24,3 +24,37 @@ type PreflightResults struct {
 	Results []*preflight.UploadPreflightResult `json:"results,omitempty"`
 	Errors  []*PreflightError                  `json:"errors,omitempty"`
 }
+
+// PreflightTrigger is what caused preflight checks to run
+type PreflightTrigger string
+
+const (
+	PreflightTriggerInstall        PreflightTrigger = "install"
+	PreflightTriggerUpdate         PreflightTrigger = "update"
+	PreflightTriggerConfigChange   PreflightTrigger = "config-change"
+	PreflightTriggerLicenseChange  PreflightTrigger = "license-change"
+	PreflightTriggerRegistryChange PreflightTrigger = "registry-change"
+	PreflightTriggerRerun          PreflightTrigger = "rerun"
+	PreflightTriggerCLI            PreflightTrigger = "cli"
+)
+
+// PreflightRun is one run of the preflight checks for an app version.
+// Every run is kept, unlike the results of the version which are replaced on rerun.
+type PreflightRun struct {
+	ID        string           `json:"id"`
+	AppID     string           `json:"appId"`
+	Sequence  int64            `json:"sequence"`
+	Trigger   PreflightTrigger `json:"trigger"`
+	Result    string           `json:"result"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// PreflightRunSummary is a preflight run with the outcome of its checks
+type PreflightRunSummary struct {
+	PreflightRun
+	// State is one of pass, warn or fail
+	State   string   `json:"state"`
+	Failing []string `json:"failing"`
+	Warning []string `json:"warning"`
+	Errors  []string `json:"errors"`
+}
@@ -0,0 +1,51 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	apitokentypes "github.com/replicatedhq/kots/pkg/apitoken/types"
+)
+
+func APITokens(tokens []apitokentypes.APIToken, format string) {
+	switch format {
+	case "json":
+		printAPITokensJSON(tokens)
+	default:
+		printAPITokensTable(tokens)
+	}
+}
+
+func printAPITokensJSON(tokens []apitokentypes.APIToken) {
+	str, _ := json.MarshalIndent(tokens, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printAPITokensTable(tokens []apitokentypes.APIToken) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	now := time.Now()
+
+	fmtColumns := "%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "ID", "NAME", "ROLES", "STATUS", "CREATED", "EXPIRES", "LAST USED")
+	for _, t := range tokens {
+		status := "active"
+		if t.IsRevoked() {
+			status = "revoked"
+		} else if t.IsExpired(now) {
+			status = "expired"
+		}
+
+		fmt.Fprintf(w, fmtColumns, t.ID, t.Name, strings.Join(t.Roles, ","), status, formatTime(&t.CreatedAt), formatTime(t.ExpiresAt), formatTime(t.LastUsedAt))
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
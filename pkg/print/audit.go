@@ -0,0 +1,38 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+)
+
+func AuditEvents(events []audittypes.Event, format string) {
+	switch format {
+	case "json":
+		printAuditEventsJSON(events)
+	default:
+		printAuditEventsTable(events)
+	}
+}
+
+func printAuditEventsJSON(events []audittypes.Event) {
+	str, _ := json.MarshalIndent(events, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printAuditEventsTable(events []audittypes.Event) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	fmtColumns := "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "TIME", "ACTOR", "ACTOR TYPE", "ACTION", "RESOURCE", "APP", "SEQUENCE", "OUTCOME")
+	for _, e := range events {
+		sequence := ""
+		if e.Sequence != nil {
+			sequence = fmt.Sprintf("%d", *e.Sequence)
+		}
+		fmt.Fprintf(w, fmtColumns, e.CreatedAt.Local().Format(time.RFC3339), e.Actor, e.ActorType, e.Action, e.Resource, e.AppSlug, sequence, e.Outcome)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 )
 
@@ -26,8 +27,8 @@ func printBackupsTable(backups []velerov1.Backup) {
 	w := NewTabWriter()
 	defer w.Flush()
 
-	fmtColumns := "%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
-	fmt.Fprintf(w, fmtColumns, "NAME", "STATUS", "ERRORS", "WARNINGS", "STARTED", "COMPLETED", "EXPIRES")
+	fmtColumns := "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "NAME", "STATUS", "ERRORS", "WARNINGS", "STARTED", "COMPLETED", "EXPIRES", "RESTORE DRILL")
 	for _, b := range backups {
 		expiresAt := ""
 		if b.Status.Expiration != nil {
@@ -50,6 +51,14 @@ func printBackupsTable(backups []velerov1.Backup) {
 			phase = "New"
 		}
 
-		fmt.Fprintf(w, fmtColumns, b.ObjectMeta.Name, phase, fmt.Sprintf("%d", b.Status.Errors), fmt.Sprintf("%d", b.Status.Warnings), startedAt, completedAt, expiresAt)
+		restoreDrill := ""
+		if drill := snapshottypes.GetRestoreDrill(b.Annotations); drill != nil {
+			restoreDrill = string(drill.Status)
+			if drill.Status != snapshottypes.RestoreDrillStatusRunning {
+				restoreDrill = fmt.Sprintf("%s (%s)", drill.Status, drill.Duration().Round(time.Second))
+			}
+		}
+
+		fmt.Fprintf(w, fmtColumns, b.ObjectMeta.Name, phase, fmt.Sprintf("%d", b.Status.Errors), fmt.Sprintf("%d", b.Status.Warnings), startedAt, completedAt, expiresAt, restoreDrill)
 	}
 }
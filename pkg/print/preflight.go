@@ -0,0 +1,34 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+)
+
+func PreflightRuns(runs []preflighttypes.PreflightRunSummary, format string) {
+	switch format {
+	case "json":
+		printPreflightRunsJSON(runs)
+	default:
+		printPreflightRunsTable(runs)
+	}
+}
+
+func printPreflightRunsJSON(runs []preflighttypes.PreflightRunSummary) {
+	str, _ := json.MarshalIndent(runs, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printPreflightRunsTable(runs []preflighttypes.PreflightRunSummary) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	fmtColumns := "%s\t%v\t%s\t%s\t%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "CREATED", "SEQUENCE", "TRIGGER", "STATE", "FAILING", "WARNING")
+	for _, run := range runs {
+		fmt.Fprintf(w, fmtColumns, formatTime(&run.CreatedAt), run.Sequence, run.Trigger, run.State, strings.Join(run.Failing, ","), strings.Join(run.Warning, ","))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 )
 
@@ -47,3 +48,30 @@ func printRestoresTable(restores []velerov1.Restore) {
 		fmt.Fprintf(w, fmtColumns, r.ObjectMeta.Name, r.Spec.BackupName, phase, startedAt, completedAt, fmt.Sprintf("%d", r.Status.Errors), fmt.Sprintf("%d", r.Status.Warnings))
 	}
 }
+
+func BackupContents(contents *snapshottypes.BackupContents, format string) {
+	switch format {
+	case "json":
+		printBackupContentsJSON(contents)
+	default:
+		printBackupContentsTable(contents)
+	}
+}
+
+func printBackupContentsJSON(contents *snapshottypes.BackupContents) {
+	str, _ := json.MarshalIndent(contents, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printBackupContentsTable(contents *snapshottypes.BackupContents) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	fmtColumns := "%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "KIND", "NAME")
+	for _, group := range contents.Resources {
+		for _, item := range group.Items {
+			fmt.Fprintf(w, fmtColumns, group.Kind, item)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+
+	supportbundletypes "github.com/replicatedhq/kots/pkg/supportbundle/types"
+)
+
+func SupportBundleComparison(comparison *supportbundletypes.SupportBundleComparison, format string) {
+	switch format {
+	case "json":
+		printSupportBundleComparisonJSON(comparison)
+	default:
+		printSupportBundleComparisonTable(comparison)
+	}
+}
+
+func printSupportBundleComparisonJSON(comparison *supportbundletypes.SupportBundleComparison) {
+	str, _ := json.MarshalIndent(comparison, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printSupportBundleComparisonTable(comparison *supportbundletypes.SupportBundleComparison) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	if len(comparison.AnalyzerChanges) == 0 && len(comparison.PodChanges) == 0 && len(comparison.NewEvents) == 0 &&
+		len(comparison.ResourceChanges) == 0 && len(comparison.ClusterResourceDeltas) == 0 {
+		fmt.Fprintln(w, "No differences found")
+		return
+	}
+
+	if len(comparison.AnalyzerChanges) > 0 {
+		fmtColumns := "%s\t%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, fmtColumns, "ANALYZER", "CHANGE", "BASE", "TARGET", "MESSAGE")
+		for _, c := range comparison.AnalyzerChanges {
+			fmt.Fprintf(w, fmtColumns, c.Key, c.Change, c.BaseSeverity, c.TargetSeverity, c.Primary)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(comparison.PodChanges) > 0 {
+		fmtColumns := "%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, fmtColumns, "POD", "NAMESPACE", "CHANGE", "DETAIL")
+		for _, c := range comparison.PodChanges {
+			fmt.Fprintf(w, fmtColumns, c.Name, c.Namespace, c.Change, c.Detail)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(comparison.NewEvents) > 0 {
+		fmtColumns := "%s\t%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, fmtColumns, "NEW EVENT", "NAMESPACE", "TYPE", "REASON", "OBJECT")
+		for _, e := range comparison.NewEvents {
+			fmt.Fprintf(w, fmtColumns, e.Name, e.Namespace, e.Type, e.Reason, e.InvolvedObject)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(comparison.ResourceChanges) > 0 {
+		fmtColumns := "%s\t%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, fmtColumns, "RESOURCE", "NAME", "NAMESPACE", "CHANGE", "DETAIL")
+		for _, c := range comparison.ResourceChanges {
+			fmt.Fprintf(w, fmtColumns, c.Resource, c.Name, c.Namespace, c.Change, c.Detail)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(comparison.ClusterResourceDeltas) > 0 {
+		fmtColumns := "%s\t%d\t%d\n"
+		fmt.Fprintf(w, "%s\t%s\t%s\n", "RESOURCE TYPE", "BASE COUNT", "TARGET COUNT")
+		for _, d := range comparison.ClusterResourceDeltas {
+			fmt.Fprintf(w, fmtColumns, d.Resource, d.BaseCount, d.TargetCount)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package print
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/replicatedhq/kots/pkg/apparchive"
 )
 
 type AppVersionResponse struct {
@@ -11,6 +14,7 @@ type AppVersionResponse struct {
 	Sequence     int64      `json:"sequence"`
 	CreatedOn    time.Time  `json:"createdOn"`
 	Status       string     `json:"status"`
+	StatusInfo   string     `json:"statusInfo,omitempty"`
 	DeployedAt   *time.Time `json:"deployedAt"`
 	Source       string     `json:"source"`
 }
@@ -33,9 +37,44 @@ func printVersionsTable(versions []AppVersionResponse) {
 	w := NewTabWriter()
 	defer w.Flush()
 
-	fmtColumns := "%s\t%v\t%s\t%s\n"
-	fmt.Fprintf(w, fmtColumns, "VERSION", "SEQUENCE", "STATUS", "SOURCE")
+	fmtColumns := "%s\t%v\t%s\t%s\t%s\n"
+	fmt.Fprintf(w, fmtColumns, "VERSION", "SEQUENCE", "STATUS", "SOURCE", "STATUS INFO")
 	for _, version := range versions {
-		fmt.Fprintf(w, fmtColumns, version.VersionLabel, version.Sequence, version.Status, version.Source)
+		// status info can be a multi-line deploy error, only the first line fits in the table
+		statusInfo, _, _ := strings.Cut(version.StatusInfo, "\n")
+		fmt.Fprintf(w, fmtColumns, version.VersionLabel, version.Sequence, version.Status, version.Source, statusInfo)
+	}
+}
+
+func VersionDiff(diff *apparchive.VersionDiff, format string, byObject bool) {
+	switch format {
+	case "json":
+		printVersionDiffJSON(diff)
+	default:
+		printVersionDiffPatch(diff, byObject)
+	}
+}
+
+func printVersionDiffJSON(diff *apparchive.VersionDiff) {
+	str, _ := json.MarshalIndent(diff, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printVersionDiffPatch(diff *apparchive.VersionDiff, byObject bool) {
+	if byObject {
+		for _, object := range diff.Objects {
+			fmt.Print(object.Patch)
+		}
+		return
+	}
+
+	for _, file := range diff.Files {
+		if file.Binary {
+			fmt.Printf("Binary files a/%s and b/%s differ\n", file.Path, file.Path)
+			continue
+		}
+		fmt.Print(file.Patch)
 	}
+
+	fmt.Printf("\n%d files changed, %d insertions(+), %d deletions(-)\n", diff.Summary.FilesChanged, diff.Summary.LinesAdded, diff.Summary.LinesRemoved)
 }
@@ -1,6 +1,7 @@
 package rbac
 
 import (
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 	"github.com/replicatedhq/kots/pkg/rbac/types"
 )
 
@@ -51,3 +52,25 @@ func DefaultRoles() []types.Role {
 		SupportRole,
 	}
 }
+
+// RolesFromIdentityConfig returns the default roles followed by the custom roles defined in the identity config
+func RolesFromIdentityConfig(spec kotsv1beta1.IdentityConfigSpec) []types.Role {
+	roles := DefaultRoles()
+	for _, r := range spec.Roles {
+		role := types.Role{
+			ID:          r.ID,
+			Name:        r.Name,
+			Description: r.Description,
+			Allow:       []types.Policy{},
+			Deny:        []types.Policy{},
+		}
+		for _, p := range r.Allow {
+			role.Allow = append(role.Allow, types.Policy{Action: p.Action, Resource: p.Resource})
+		}
+		for _, p := range r.Deny {
+			role.Deny = append(role.Deny, types.Policy{Action: p.Action, Resource: p.Resource})
+		}
+		roles = append(roles, role)
+	}
+	return roles
+}
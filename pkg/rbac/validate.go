@@ -0,0 +1,101 @@
+package rbac
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+)
+
+var (
+	validActions       = []string{"read", "write", "*", "**"}
+	templateVarsRegexp = regexp.MustCompile(`{{[^}]*}}`)
+)
+
+// ValidateRoles validates the custom roles defined in the identity config and the role ids that groups map to.
+// Every resource pattern must match at least one of the known resources, which are policy resource templates such as "app.{{.appSlug}}.downstream.".
+func ValidateRoles(spec kotsv1beta1.IdentityConfigSpec, knownResources []string) error {
+	roleIDs := map[string]bool{}
+	for _, role := range DefaultRoles() {
+		roleIDs[role.ID] = true
+	}
+
+	for _, role := range spec.Roles {
+		if role.ID == "" {
+			return errors.New("role id is required")
+		}
+		if roleIDs[role.ID] {
+			return errors.Errorf("role %q is already defined", role.ID)
+		}
+		roleIDs[role.ID] = true
+
+		if len(role.Allow) == 0 {
+			return errors.Errorf("role %q must allow at least one action", role.ID)
+		}
+		for _, p := range append(role.Allow, role.Deny...) {
+			if err := validateRolePolicy(p, knownResources); err != nil {
+				return errors.Wrapf(err, "invalid policy in role %q", role.ID)
+			}
+		}
+	}
+
+	for _, group := range spec.Groups {
+		for _, roleID := range group.RoleIDs {
+			if !roleIDs[roleID] {
+				return errors.Errorf("group %q refers to unknown role %q", group.ID, roleID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateRolePolicy(p kotsv1beta1.IdentityConfigRolePolicy, knownResources []string) error {
+	validAction := false
+	for _, action := range validActions {
+		if p.Action == action {
+			validAction = true
+			break
+		}
+	}
+	if !validAction {
+		return errors.Errorf("action %q is not one of %s", p.Action, strings.Join(validActions, ", "))
+	}
+
+	if p.Resource == "" {
+		return errors.New("resource is required")
+	}
+	pattern, err := glob.Compile(p.Resource, '.')
+	if err != nil {
+		return errors.Wrapf(err, "failed to compile resource %q", p.Resource)
+	}
+	for _, resource := range knownResources {
+		if resourceTemplateMatches(pattern, p.Resource, resource) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("resource %q does not match any resource", p.Resource)
+}
+
+// resourceTemplateMatches returns true if the pattern matches the resource template for some value of its template variables.
+// Template variables are full segments, so it is enough to try the literal segments of the pattern and a placeholder.
+func resourceTemplateMatches(pattern glob.Glob, patternStr string, resourceTemplate string) bool {
+	if !templateVarsRegexp.MatchString(resourceTemplate) {
+		return pattern.Match(resourceTemplate)
+	}
+
+	candidates := append(strings.Split(patternStr, "."), "x")
+	for _, candidate := range candidates {
+		if candidate == "" || strings.ContainsAny(candidate, "*?[]{}!") {
+			continue
+		}
+		if pattern.Match(templateVarsRegexp.ReplaceAllString(resourceTemplate, candidate)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testResources = []string{
+	"app.",
+	"app.{{.appSlug}}",
+	"app.{{.appSlug}}.downstream.",
+	"app.{{.appSlug}}.downstream.config.",
+	"app.{{.appSlug}}.downstream.filetree.",
+	"redactor.",
+}
+
+var (
+	deployerRole = kotsv1beta1.IdentityConfigRole{
+		ID:   "deployer",
+		Name: "Deployer",
+		Allow: []kotsv1beta1.IdentityConfigRolePolicy{
+			{Action: "read", Resource: "**"},
+			{Action: "write", Resource: "app.*.downstream."},
+		},
+		Deny: []kotsv1beta1.IdentityConfigRolePolicy{
+			{Action: "write", Resource: "app.*.downstream.config."},
+		},
+	}
+	auditorRole = kotsv1beta1.IdentityConfigRole{
+		ID:   "auditor",
+		Name: "Auditor",
+		Allow: []kotsv1beta1.IdentityConfigRolePolicy{
+			{Action: "read", Resource: "**"},
+		},
+		Deny: []kotsv1beta1.IdentityConfigRolePolicy{
+			{Action: "**", Resource: "app.*.downstream.filetree."},
+		},
+	}
+)
+
+func TestValidateRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    kotsv1beta1.IdentityConfigSpec
+		wantErr string
+	}{
+		{
+			name: "custom roles",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{deployerRole, auditorRole},
+				Groups: []kotsv1beta1.IdentityConfigGroup{
+					{ID: "deployers", RoleIDs: []string{"deployer"}},
+					{ID: "admins", RoleIDs: []string{ClusterAdminRoleID}},
+				},
+			},
+		},
+		{
+			name: "resource for a specific app",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{
+					{ID: "my-app", Allow: []kotsv1beta1.IdentityConfigRolePolicy{{Action: "**", Resource: "app.my-app.**"}}},
+				},
+			},
+		},
+		{
+			name: "duplicate of a default role",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{
+					{ID: ClusterAdminRoleID, Allow: []kotsv1beta1.IdentityConfigRolePolicy{{Action: "read", Resource: "**"}}},
+				},
+			},
+			wantErr: `role "cluster-admin" is already defined`,
+		},
+		{
+			name: "missing id",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{
+					{Allow: []kotsv1beta1.IdentityConfigRolePolicy{{Action: "read", Resource: "**"}}},
+				},
+			},
+			wantErr: "role id is required",
+		},
+		{
+			name: "no allow policies",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{{ID: "empty"}},
+			},
+			wantErr: `role "empty" must allow at least one action`,
+		},
+		{
+			name: "invalid action",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{
+					{ID: "deleter", Allow: []kotsv1beta1.IdentityConfigRolePolicy{{Action: "delete", Resource: "**"}}},
+				},
+			},
+			wantErr: `invalid policy in role "deleter": action "delete" is not one of read, write, *, **`,
+		},
+		{
+			name: "unknown resource",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Roles: []kotsv1beta1.IdentityConfigRole{
+					{ID: "typo", Allow: []kotsv1beta1.IdentityConfigRolePolicy{{Action: "read", Resource: "app.*.downstrem."}}},
+				},
+			},
+			wantErr: `invalid policy in role "typo": resource "app.*.downstrem." does not match any resource`,
+		},
+		{
+			name: "group with unknown role",
+			spec: kotsv1beta1.IdentityConfigSpec{
+				Groups: []kotsv1beta1.IdentityConfigGroup{
+					{ID: "deployers", RoleIDs: []string{"deployer"}},
+				},
+			},
+			wantErr: `group "deployers" refers to unknown role "deployer"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRoles(tt.spec, testResources)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCustomRolesCheckAccess(t *testing.T) {
+	roles := RolesFromIdentityConfig(kotsv1beta1.IdentityConfigSpec{
+		Roles: []kotsv1beta1.IdentityConfigRole{deployerRole, auditorRole},
+	})
+	require.Len(t, roles, len(DefaultRoles())+2)
+
+	tests := []struct {
+		name         string
+		action       string
+		resource     string
+		sessionRoles []string
+		want         bool
+	}{
+		{
+			name:         "deployer can deploy",
+			action:       "write",
+			resource:     "app.my-app.downstream.",
+			sessionRoles: []string{"deployer"},
+			want:         true,
+		},
+		{
+			name:         "deployer cannot edit config",
+			action:       "write",
+			resource:     "app.my-app.downstream.config.",
+			sessionRoles: []string{"deployer"},
+			want:         false,
+		},
+		{
+			name:         "deployer can read config",
+			action:       "read",
+			resource:     "app.my-app.downstream.config.",
+			sessionRoles: []string{"deployer"},
+			want:         true,
+		},
+		{
+			name:         "auditor can read",
+			action:       "read",
+			resource:     "app.my-app.downstream.",
+			sessionRoles: []string{"auditor"},
+			want:         true,
+		},
+		{
+			name:         "auditor cannot read the file tree",
+			action:       "read",
+			resource:     "app.my-app.downstream.filetree.",
+			sessionRoles: []string{"auditor"},
+			want:         false,
+		},
+		{
+			name:         "auditor cannot write",
+			action:       "write",
+			resource:     "redactor.",
+			sessionRoles: []string{"auditor"},
+			want:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckAccess(context.Background(), roles, tt.action, tt.resource, tt.sessionRoles)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package secretref
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+type externalSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   externalSecretMeta `yaml:"metadata"`
+	Spec       externalSecretSpec `yaml:"spec"`
+}
+
+type externalSecretMeta struct {
+	Name string `yaml:"name"`
+}
+
+type externalSecretSpec struct {
+	RefreshInterval string                 `yaml:"refreshInterval"`
+	SecretStoreRef  externalSecretStoreRef `yaml:"secretStoreRef"`
+	Target          externalSecretTarget   `yaml:"target"`
+	Data            []externalSecretData   `yaml:"data"`
+}
+
+type externalSecretStoreRef struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+type externalSecretTarget struct {
+	Name           string `yaml:"name"`
+	CreationPolicy string `yaml:"creationPolicy"`
+}
+
+type externalSecretData struct {
+	SecretKey string                  `yaml:"secretKey"`
+	RemoteRef externalSecretRemoteRef `yaml:"remoteRef"`
+}
+
+type externalSecretRemoteRef struct {
+	Key      string `yaml:"key"`
+	Property string `yaml:"property"`
+}
+
+// ExternalSecret returns an External Secrets Operator ExternalSecret that creates the secret
+// with the referenced property stored under secretKey.
+func ExternalSecret(ref Ref, secretName string, secretKey string) ([]byte, error) {
+	if ref.Provider != ProviderExternalSecret {
+		return nil, errors.Errorf("cannot create an external secret for a %s reference", ref.Provider)
+	}
+
+	parts := strings.SplitN(ref.Path, "/", 3)
+	if len(parts) != 3 {
+		return nil, errors.Errorf("unexpected external secret reference path %q", ref.Path)
+	}
+
+	es := externalSecret{
+		APIVersion: "external-secrets.io/v1beta1",
+		Kind:       "ExternalSecret",
+		Metadata: externalSecretMeta{
+			Name: secretName,
+		},
+		Spec: externalSecretSpec{
+			RefreshInterval: "1h",
+			SecretStoreRef: externalSecretStoreRef{
+				Kind: parts[0],
+				Name: parts[1],
+			},
+			Target: externalSecretTarget{
+				Name:           secretName,
+				CreationPolicy: "Owner",
+			},
+			Data: []externalSecretData{
+				{
+					SecretKey: secretKey,
+					RemoteRef: externalSecretRemoteRef{
+						Key:      parts[2],
+						Property: ref.Key,
+					},
+				},
+			},
+		},
+	}
+
+	b, err := yaml.Marshal(es)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal external secret")
+	}
+	return b, nil
+}
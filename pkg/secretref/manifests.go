@@ -0,0 +1,223 @@
+package secretref
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ResolveDir resolves the references in every yaml file in the directory, rewriting the files in place.
+// It is meant to be run on a temporary copy of an app version archive right before it is deployed,
+// so that resolved values are never written back to the archive. A directory that does not exist is ignored.
+// Only the given references, which are the values of the config items, are resolved.
+func ResolveDir(dir string, refs []string) error {
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to stat %s", dir)
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		if !bytes.Contains(content, []byte(refPrefix)) {
+			return nil
+		}
+
+		resolved, err := ResolveManifests(content, refs)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve references in %s", path)
+		}
+
+		if err := os.WriteFile(path, resolved, info.Mode()); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to walk %s", dir)
+	}
+
+	return nil
+}
+
+// ResolveManifests replaces every string value in the yaml documents that is one of the references with the value of the referenced secret.
+// Other values are left as is, even if they look like references, so that literal values with the same prefix can still be deployed.
+func ResolveManifests(content []byte, refs []string) ([]byte, error) {
+	if len(refs) == 0 {
+		return content, nil
+	}
+
+	isRef := map[string]bool{}
+	for _, ref := range refs {
+		isRef[ref] = true
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := yaml.Node{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to decode yaml")
+		}
+
+		if err := resolveNode(&doc, isRef); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.Encode(&doc); err != nil {
+			return nil, errors.Wrap(err, "failed to encode yaml")
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close yaml encoder")
+	}
+
+	return out.Bytes(), nil
+}
+
+func resolveNode(node *yaml.Node, isRef map[string]bool) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" || !isRef[node.Value] {
+			return nil
+		}
+		ref, err := Parse(node.Value)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse reference")
+		}
+		value, err := Resolve(*ref)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve %s", ref.String())
+		}
+		node.Value = value
+		node.Style = 0
+
+	case yaml.MappingNode:
+		// only values are resolved, never keys
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := resolveNode(node.Content[i], isRef); err != nil {
+				return err
+			}
+		}
+
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := resolveNode(child, isRef); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckUsage returns an error if one of the references is embedded in or encoded into a value of the rendered content
+// instead of being used as a whole string value, since only whole values are resolved when the version is deployed.
+func CheckUsage(content []byte, refs []string) error {
+	used := []string{}
+	for _, ref := range refs {
+		if bytes.Contains(content, []byte(ref)) {
+			used = append(used, ref)
+		}
+	}
+	if len(used) == 0 {
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	docs := []*yaml.Node{}
+	for {
+		doc := yaml.Node{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			// not valid yaml yet (e.g. a helm chart template), the references must be the last value on their lines
+			return checkLines(content, used)
+		}
+		docs = append(docs, &doc)
+	}
+
+	for _, doc := range docs {
+		if err := checkNode(doc, used); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkNode(node *yaml.Node, refs []string) error {
+	if node.Kind == yaml.ScalarNode {
+		return checkValue(node.Value, refs, func(ref string) bool {
+			return node.Value == ref
+		})
+	}
+
+	for _, child := range node.Content {
+		if err := checkNode(child, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLines(content []byte, refs []string) error {
+	for _, line := range strings.Split(string(content), "\n") {
+		err := checkValue(line, refs, func(ref string) bool {
+			idx := strings.Index(line, ref)
+			before := strings.TrimRight(line[:idx], `"'`)
+			after := strings.TrimSpace(strings.TrimLeft(line[idx+len(ref):], `"'`))
+			return after == "" && (before == "" || strings.HasSuffix(before, " ") || strings.HasSuffix(before, "\t"))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValue returns an error if the value contains one of the references but none of them is used as the whole value.
+func checkValue(value string, refs []string, isWholeValue func(ref string) bool) error {
+	contained := ""
+	for _, ref := range refs {
+		if !strings.Contains(value, ref) {
+			continue
+		}
+		if isWholeValue(ref) {
+			return nil
+		}
+		contained = ref
+	}
+	if contained == "" {
+		return nil
+	}
+
+	return errors.Errorf("secret reference %s must be used as a whole value, it cannot be embedded in or encoded into another value", contained)
+}
@@ -0,0 +1,120 @@
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/util"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var getClientset = func() (kubernetes.Interface, error) {
+	return k8sutil.GetClientset()
+}
+
+type vaultSecretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// resolveVault reads the key from a KV secret. The path is the api path of the secret,
+// which includes "data" after the mount for v2 engines (e.g. secret/data/my-app).
+func resolveVault(ref Ref) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(addr, "/"), strings.TrimPrefix(ref.Path, "/")), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read secret from vault")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code from vault: %d", resp.StatusCode)
+	}
+
+	secret := vaultSecretResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", errors.Wrap(err, "failed to decode vault response")
+	}
+
+	data := secret.Data
+	// kv v2 engines nest the secret data and return its metadata next to it
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %s not found in vault secret %s", ref.Key, ref.Path)
+	}
+
+	return fmt.Sprintf("%v", value), nil
+}
+
+// resolveKubernetesSecret reads the key from a Secret using the kotsadm service account.
+// Only Secrets in the kotsadm namespace or the app namespace can be referenced so that
+// config editors cannot read Secrets from elsewhere in the cluster.
+func resolveKubernetesSecret(ref Ref) (string, error) {
+	namespace, name, _ := strings.Cut(ref.Path, "/")
+	if namespace != util.PodNamespace && namespace != util.AppNamespace() {
+		return "", errors.Errorf("secret %s is in namespace %s, only secrets in the kotsadm or app namespace can be referenced", name, namespace)
+	}
+
+	clientset, err := getClientset()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %s in namespace %s", name, namespace)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %s not found in secret %s in namespace %s", ref.Key, name, namespace)
+	}
+
+	return string(value), nil
+}
+
+// resolveFile reads the key from a yaml (or json) file of key/value pairs.
+func resolveFile(ref Ref) (string, error) {
+	content, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read secrets file")
+	}
+
+	values := map[string]string{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal secrets file")
+	}
+
+	value, ok := values[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %s not found in %s", ref.Key, ref.Path)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,119 @@
+// Package secretref resolves config values that reference secrets stored outside of the app version archive.
+//
+// A reference is a config value of the form ref+<provider>://<path>#<key>:
+//
+//	ref+vault://secret/data/my-app#password              a key in a Vault KV (v1 or v2) secret, read from VAULT_ADDR with VAULT_TOKEN
+//	ref+k8s://my-namespace/my-secret#password            a key in a Kubernetes Secret in the kotsadm or app namespace
+//	ref+externalsecret://ClusterSecretStore/vault/my-app#password
+//	                                                     a property of a remote key in an External Secrets Operator store,
+//	                                                     resolved in the cluster by the ExternalSecret that kots emits for it
+//	ref+file:///etc/my-app/secrets.yaml#password         a key in a local yaml file, to be used as a stand-in during development,
+//	                                                     only accepted when KOTSADM_ENV is set to "dev"
+//
+// References are kept as is in the rendered manifests, so they are never written to the app version archive.
+// Vault, Kubernetes Secret and file references are resolved by kotsadm when the version is deployed, which means they
+// must be used as whole string values (e.g. with ConfigOptionSecret). Rendering fails when a reference is encoded or
+// embedded in another value, since the manifests would otherwise be deployed with the reference instead of the secret.
+package secretref
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ProviderVault          = "vault"
+	ProviderKubernetes     = "k8s"
+	ProviderExternalSecret = "externalsecret"
+	ProviderFile           = "file"
+
+	refPrefix = "ref+"
+)
+
+type Ref struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s%s://%s#%s", refPrefix, r.Provider, r.Path, r.Key)
+}
+
+// IsRef returns true if the value looks like a reference, it may still fail to parse.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, refPrefix)
+}
+
+// Parse parses a reference. It returns nil if the value is not a reference.
+func Parse(value string) (*Ref, error) {
+	if !IsRef(value) {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, refPrefix), "://", 2)
+	if len(parts) != 2 {
+		return nil, errors.Errorf("reference %q must be in the form ref+<provider>://<path>#<key>", value)
+	}
+	provider := parts[0]
+
+	idx := strings.LastIndex(parts[1], "#")
+	if idx == -1 {
+		return nil, errors.Errorf("reference %q is missing a #<key>", value)
+	}
+	ref := &Ref{
+		Provider: provider,
+		Path:     parts[1][:idx],
+		Key:      parts[1][idx+1:],
+	}
+	if ref.Path == "" || ref.Key == "" {
+		return nil, errors.Errorf("reference %q must have a path and a key", value)
+	}
+
+	switch provider {
+	case ProviderVault:
+	case ProviderKubernetes:
+		if len(strings.Split(ref.Path, "/")) != 2 {
+			return nil, errors.Errorf("kubernetes secret reference %q must be in the form ref+k8s://<namespace>/<name>#<key>", value)
+		}
+	case ProviderExternalSecret:
+		if len(strings.SplitN(ref.Path, "/", 3)) != 3 {
+			return nil, errors.Errorf("external secret reference %q must be in the form ref+externalsecret://<store kind>/<store name>/<remote key>#<property>", value)
+		}
+	case ProviderFile:
+		if !isDevEnv() {
+			return nil, errors.Errorf("file reference %q is only supported in development environments", value)
+		}
+	default:
+		return nil, errors.Errorf("unsupported secret provider %q", provider)
+	}
+
+	return ref, nil
+}
+
+// Resolve returns the value of the referenced secret.
+// References to External Secrets Operator stores cannot be resolved by kots, an ExternalSecret must be emitted for them instead.
+func Resolve(ref Ref) (string, error) {
+	switch ref.Provider {
+	case ProviderVault:
+		return resolveVault(ref)
+	case ProviderKubernetes:
+		return resolveKubernetesSecret(ref)
+	case ProviderFile:
+		if !isDevEnv() {
+			return "", errors.New("file references are only supported in development environments")
+		}
+		return resolveFile(ref)
+	case ProviderExternalSecret:
+		return "", errors.New("external secret references are resolved by the external secrets operator")
+	}
+
+	return "", errors.Errorf("unsupported secret provider %q", ref.Provider)
+}
+
+func isDevEnv() bool {
+	return os.Getenv("KOTSADM_ENV") == "dev"
+}
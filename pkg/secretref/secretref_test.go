@@ -0,0 +1,356 @@
+package secretref
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *Ref
+		wantErr bool
+	}{
+		{
+			name:  "plain value",
+			value: "my-password",
+			want:  nil,
+		},
+		{
+			name:  "vault",
+			value: "ref+vault://secret/data/my-app#password",
+			want:  &Ref{Provider: ProviderVault, Path: "secret/data/my-app", Key: "password"},
+		},
+		{
+			name:  "kubernetes secret",
+			value: "ref+k8s://other-namespace/db#password",
+			want:  &Ref{Provider: ProviderKubernetes, Path: "other-namespace/db", Key: "password"},
+		},
+		{
+			name:  "external secret",
+			value: "ref+externalsecret://ClusterSecretStore/vault/my-app/db#password",
+			want:  &Ref{Provider: ProviderExternalSecret, Path: "ClusterSecretStore/vault/my-app/db", Key: "password"},
+		},
+		{
+			name:    "missing key",
+			value:   "ref+vault://secret/data/my-app",
+			wantErr: true,
+		},
+		{
+			name:    "kubernetes secret without namespace",
+			value:   "ref+k8s://db#password",
+			wantErr: true,
+		},
+		{
+			name:    "external secret without remote key",
+			value:   "ref+externalsecret://SecretStore/vault#password",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported provider",
+			value:   "ref+awssm://my-secret#password",
+			wantErr: true,
+		},
+		{
+			name:    "file outside of a dev environment",
+			value:   "ref+file:///etc/my-app/secrets.yaml#password",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.value)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			if got != nil {
+				assert.Equal(t, test.value, got.String())
+			}
+		})
+	}
+}
+
+func TestResolveVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/my-app":
+			w.Write([]byte(`{"data":{"data":{"password":"kv2-password"},"metadata":{"version":1}}}`))
+		case "/v1/kv/my-app":
+			w.Write([]byte(`{"data":{"password":"kv1-password"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	got, err := Resolve(Ref{Provider: ProviderVault, Path: "secret/data/my-app", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "kv2-password", got)
+
+	got, err = Resolve(Ref{Provider: ProviderVault, Path: "kv/my-app", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "kv1-password", got)
+
+	_, err = Resolve(Ref{Provider: ProviderVault, Path: "kv/my-app", Key: "username"})
+	assert.Error(t, err)
+
+	_, err = Resolve(Ref{Provider: ProviderVault, Path: "kv/missing", Key: "password"})
+	assert.Error(t, err)
+}
+
+func TestResolveKubernetesSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "app-namespace",
+		},
+		Data: map[string][]byte{
+			"password": []byte("k8s-password"),
+		},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "other-namespace",
+		},
+		Data: map[string][]byte{
+			"password": []byte("other-password"),
+		},
+	})
+
+	prev := getClientset
+	getClientset = func() (kubernetes.Interface, error) { return clientset, nil }
+	defer func() { getClientset = prev }()
+
+	prevNamespace := util.PodNamespace
+	util.PodNamespace = "app-namespace"
+	defer func() { util.PodNamespace = prevNamespace }()
+
+	got, err := Resolve(Ref{Provider: ProviderKubernetes, Path: "app-namespace/db", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "k8s-password", got)
+
+	_, err = Resolve(Ref{Provider: ProviderKubernetes, Path: "app-namespace/missing", Key: "password"})
+	assert.Error(t, err)
+
+	_, err = Resolve(Ref{Provider: ProviderKubernetes, Path: "other-namespace/db", Key: "password"})
+	assert.Error(t, err)
+}
+
+func TestResolveFile(t *testing.T) {
+	secretsFile := filepath.Join(t.TempDir(), "secrets.yaml")
+	require.NoError(t, os.WriteFile(secretsFile, []byte("password: file-password\n"), 0600))
+
+	ref := Ref{Provider: ProviderFile, Path: secretsFile, Key: "password"}
+
+	_, err := Resolve(ref)
+	assert.Error(t, err)
+
+	t.Setenv("KOTSADM_ENV", "dev")
+
+	parsed, err := Parse(ref.String())
+	require.NoError(t, err)
+	assert.Equal(t, &ref, parsed)
+
+	got, err := Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "file-password", got)
+
+	_, err = Resolve(Ref{Provider: ProviderFile, Path: secretsFile, Key: "username"})
+	assert.Error(t, err)
+}
+
+func TestExternalSecret(t *testing.T) {
+	ref := Ref{Provider: ProviderExternalSecret, Path: "ClusterSecretStore/vault/my-app/db", Key: "password"}
+
+	_, err := Resolve(ref)
+	assert.Error(t, err)
+
+	got, err := ExternalSecret(ref, "db-credentials", "db_password")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: db-credentials
+spec:
+  refreshInterval: 1h
+  secretStoreRef:
+    kind: ClusterSecretStore
+    name: vault
+  target:
+    name: db-credentials
+    creationPolicy: Owner
+  data:
+  - secretKey: db_password
+    remoteRef:
+      key: my-app/db
+      property: password
+`, string(got))
+
+	_, err = ExternalSecret(Ref{Provider: ProviderVault, Path: "secret/data/my-app", Key: "password"}, "db-credentials", "db_password")
+	assert.Error(t, err)
+}
+
+func TestResolveManifests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"password":"12345"}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	refs := []string{"ref+vault://kv/my-app#password", "ref+vault://kv/my-app#username"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "secret string data",
+			content: `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  password: ref+vault://kv/my-app#password
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  # not a reference
+  url: https://example.com/ref+vault://kv/my-app#password
+`,
+			want: `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  password: "12345"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  # not a reference
+  url: https://example.com/ref+vault://kv/my-app#password
+`,
+		},
+		{
+			name: "helm values",
+			content: `postgres:
+  passwords:
+  - ref+vault://kv/my-app#password
+`,
+			want: `postgres:
+  passwords:
+    - "12345"
+`,
+		},
+		{
+			name: "literal value that is not a config item reference",
+			content: `data:
+  password: ref+vault://kv/my-app#password
+  literal: ref+vault://kv/other-app#password
+`,
+			want: `data:
+  password: "12345"
+  literal: ref+vault://kv/other-app#password
+`,
+		},
+		{
+			name: "unresolvable reference",
+			content: `stringData:
+  password: ref+vault://kv/my-app#username
+`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ResolveManifests([]byte(test.content), refs)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+		})
+	}
+}
+
+func TestCheckUsage(t *testing.T) {
+	refs := []string{"ref+vault://secret/data/my-app", "ref+vault://secret/data/my-app#password"}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "no references",
+			content: "password: my-password\n",
+		},
+		{
+			name:    "whole values",
+			content: "password: ref+vault://secret/data/my-app#password\n---\nkey: \"ref+vault://secret/data/my-app\"\n",
+		},
+		{
+			name:    "embedded in a value",
+			content: "url: postgres://admin:ref+vault://secret/data/my-app#password@db\n",
+			wantErr: true,
+		},
+		{
+			name:    "embedded in a block scalar",
+			content: "data:\n  config.yaml: |\n    password: ref+vault://secret/data/my-app#password\n",
+			wantErr: true,
+		},
+		{
+			name:    "whole value in a template",
+			content: "url: {{ .Values.url }}\npassword: \"ref+vault://secret/data/my-app#password\"\n",
+		},
+		{
+			name:    "embedded in a template",
+			content: "url: {{ .Values.url }}\npassword: ref+vault://secret/data/my-app#password-{{ .Values.suffix }}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckUsage([]byte(test.content), refs)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
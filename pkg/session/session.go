@@ -12,10 +12,12 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/pkg/errors"
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/apitoken"
 	"github.com/replicatedhq/kots/pkg/identity"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/session/types"
 	"github.com/replicatedhq/kots/pkg/store"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
 	"github.com/replicatedhq/kots/pkg/util"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -60,6 +62,7 @@ func Parse(kotsStore store.Store, signedToken string) (*types.Session, error) {
 
 		s := types.Session{
 			ID:        "kots-cli",
+			UserID:    usertypes.CLIUserID,
 			IssuedAt:  time.Now(),
 			ExpiresAt: time.Now().Add(time.Minute),
 			// TODO: super user permissions
@@ -70,6 +73,25 @@ func Parse(kotsStore store.Store, signedToken string) (*types.Session, error) {
 		return &s, nil
 	}
 
+	if apitoken.IsAPIToken(tokenParts[1]) {
+		// api tokens carry their own roles and are checked on every request, the session only lives for the request
+		token, err := apitoken.Authenticate(kotsStore, tokenParts[1])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to authenticate api token")
+		}
+
+		s := types.Session{
+			ID:        fmt.Sprintf("apitoken-%s", token.ID),
+			UserID:    usertypes.APITokenUserIDPrefix + token.Name,
+			IssuedAt:  time.Now(),
+			ExpiresAt: time.Now().Add(time.Minute),
+			Roles:     token.Roles,
+			HasRBAC:   true,
+		}
+
+		return &s, nil
+	}
+
 	token, err := jwt.Parse(tokenParts[1], func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
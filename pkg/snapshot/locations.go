@@ -0,0 +1,350 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	SecondaryLocationLabel           = "kots.io/secondary-location"
+	ReplicationPolicyAnnotation      = "kots.io/replication-policy"
+	CloudCredentialsSecretKey        = "cloud"
+	secondaryCredentialsSecretPrefix = "kotsadm-bsl-"
+)
+
+// configureSecondaryStore creates or updates a non-default backup storage location that completed backups
+// are replicated to. Secondary locations keep their credentials in their own secret, referenced from the
+// location, so they do not interfere with the primary store's cloud-credentials secret.
+func configureSecondaryStore(ctx context.Context, options ConfigureStoreOptions) (*types.Store, error) {
+	if errs := validation.IsDNS1123Subdomain(options.LocationName); len(errs) > 0 {
+		return nil, &InvalidStoreDataError{Message: fmt.Sprintf("invalid location name: %s", strings.Join(errs, ", "))}
+	}
+	if options.Internal || options.FileSystem != nil {
+		return nil, &InvalidStoreDataError{Message: "secondary locations must be object stores"}
+	}
+	if (options.AWS != nil && options.AWS.UseInstanceRole) || (options.Google != nil && options.Google.UseInstanceRole) {
+		return nil, &InvalidStoreDataError{Message: "instance roles are not supported for secondary locations"}
+	}
+	if err := validateReplicationPolicy(options.ReplicationPolicy); err != nil {
+		return nil, &InvalidStoreDataError{Message: err.Error()}
+	}
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	primaryBSL, err := FindBackupStoreLocation(ctx, options.KotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if primaryBSL == nil {
+		return nil, &InvalidStoreDataError{Message: "a primary store must be configured before adding secondary locations"}
+	}
+
+	existingBSL, err := veleroClient.BackupStorageLocations(primaryBSL.Namespace).Get(ctx, options.LocationName, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "failed to get backup storage location")
+	}
+
+	var existingStore *types.Store
+	if err == nil {
+		if !IsSecondaryBackupStorageLocation(existingBSL) {
+			return nil, &InvalidStoreDataError{Message: fmt.Sprintf("location %s is not a secondary location", options.LocationName)}
+		}
+		if existingBSL.Spec.ObjectStorage != nil {
+			existingStore, err = getStoreFromBackupStorageLocation(ctx, clientset, existingBSL)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get existing secondary store")
+			}
+		}
+	} else {
+		existingBSL = nil
+	}
+
+	newStore, _, err := buildNewStore(ctx, clientset, existingStore, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update existing store")
+	}
+	if !isObjectStore(newStore) {
+		return nil, &InvalidStoreDataError{Message: "secondary locations must be object stores"}
+	}
+
+	if !options.SkipValidation {
+		validateStoreOptions := ValidateStoreOptions{
+			KotsadmNamespace:  options.KotsadmNamespace,
+			RegistryConfig:    options.RegistryConfig,
+			ValidateUsingAPod: options.ValidateUsingAPod,
+			CACertData:        options.CACertData,
+		}
+		if err := validateStore(ctx, newStore, validateStoreOptions); err != nil {
+			return nil, &InvalidStoreDataError{Message: errors.Cause(err).Error()}
+		}
+	}
+
+	bsl, credentials, err := buildSecondaryBackupStorageLocation(existingBSL, newStore, options.LocationName, primaryBSL.Namespace, options.ReplicationPolicy)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build secondary backup storage location")
+	}
+
+	if err := ensureCredentialsSecret(ctx, clientset, bsl.Namespace, bsl.Spec.Credential.Name, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to ensure secondary credentials secret")
+	}
+
+	if _, err := upsertBackupStorageLocation(ctx, bsl); err != nil {
+		return nil, errors.Wrap(err, "failed to upsert secondary backup storage location")
+	}
+
+	updatedStore, err := GetGlobalStore(ctx, options.KotsadmNamespace, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get updated store")
+	}
+	if updatedStore == nil {
+		return nil, errors.New("store not found")
+	}
+
+	if err := Redact(updatedStore); err != nil {
+		return nil, errors.Wrap(err, "failed to redact")
+	}
+
+	return updatedStore, nil
+}
+
+// buildSecondaryBackupStorageLocation returns the backup storage location for a secondary store along with
+// the contents of the credentials secret it references
+func buildSecondaryBackupStorageLocation(existing *velerov1.BackupStorageLocation, store *types.Store, name string, veleroNamespace string, policy types.ReplicationPolicy) (*velerov1.BackupStorageLocation, []byte, error) {
+	bsl := existing
+	if bsl == nil {
+		bsl = &velerov1.BackupStorageLocation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: veleroNamespace,
+			},
+		}
+	}
+
+	if bsl.Labels == nil {
+		bsl.Labels = map[string]string{}
+	}
+	bsl.Labels[SecondaryLocationLabel] = "true"
+
+	if bsl.Annotations == nil {
+		bsl.Annotations = map[string]string{}
+	}
+	if policy != "" {
+		bsl.Annotations[ReplicationPolicyAnnotation] = string(policy)
+	} else if bsl.Annotations[ReplicationPolicyAnnotation] == "" {
+		bsl.Annotations[ReplicationPolicyAnnotation] = string(types.ReplicationPolicyAll)
+	}
+
+	config, credentials, err := getObjectStoreConfig(store)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get object store config")
+	}
+	if credentials == nil {
+		return nil, nil, errors.New("secondary locations require credentials")
+	}
+
+	bsl.Spec.Default = false
+	bsl.Spec.Provider = store.Provider
+	bsl.Spec.Config = config
+	bsl.Spec.ObjectStorage = &velerov1.ObjectStorageLocation{
+		Bucket: store.Bucket,
+		Prefix: store.Path,
+		CACert: store.CACertData,
+	}
+	bsl.Spec.Credential = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{
+			Name: secondaryCredentialsSecretName(name),
+		},
+		Key: CloudCredentialsSecretKey,
+	}
+
+	return bsl, credentials, nil
+}
+
+// RemoveSecondaryStore deletes a secondary backup storage location and its credentials. Backups already
+// replicated to the location are left in the bucket.
+func RemoveSecondaryStore(ctx context.Context, kotsadmNamespace string, name string) error {
+	if name == DefaultBackupStorageLocationName {
+		return &InvalidStoreDataError{Message: "the primary store cannot be removed"}
+	}
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace(ctx, clientset, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return errors.New("velero is not installed")
+	}
+
+	bsl, err := veleroClient.BackupStorageLocations(veleroNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return &InvalidStoreDataError{Message: fmt.Sprintf("location %s not found", name)}
+		}
+		return errors.Wrap(err, "failed to get backup storage location")
+	}
+	if !IsSecondaryBackupStorageLocation(bsl) {
+		return &InvalidStoreDataError{Message: fmt.Sprintf("location %s is not a secondary location", name)}
+	}
+
+	err = veleroClient.BackupStorageLocations(veleroNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete backup storage location")
+	}
+
+	if bsl.Spec.Credential != nil {
+		err := clientset.CoreV1().Secrets(veleroNamespace).Delete(ctx, bsl.Spec.Credential.Name, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to delete credentials secret")
+		}
+	}
+
+	return nil
+}
+
+// ListSecondaryBackupStorageLocations returns the backup storage locations that were configured as secondaries
+func ListSecondaryBackupStorageLocations(ctx context.Context, veleroNamespace string) ([]velerov1.BackupStorageLocation, error) {
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	return listSecondaryBackupStorageLocations(ctx, veleroClient, veleroNamespace)
+}
+
+func listSecondaryBackupStorageLocations(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string) ([]velerov1.BackupStorageLocation, error) {
+	bsls, err := veleroClient.BackupStorageLocations(veleroNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", SecondaryLocationLabel),
+	})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to list backupstoragelocations")
+	}
+
+	return bsls.Items, nil
+}
+
+// BackupStorageLocationExists returns true if the primary store or a secondary store with this name is configured
+func BackupStorageLocationExists(ctx context.Context, kotsadmNamespace string, name string) (bool, error) {
+	bsl, err := FindBackupStoreLocation(ctx, kotsadmNamespace)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if bsl == nil {
+		return false, nil
+	}
+	if name == bsl.Name {
+		return true, nil
+	}
+
+	secondaries, err := ListSecondaryBackupStorageLocations(ctx, bsl.Namespace)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list secondary backup storage locations")
+	}
+	for _, secondary := range secondaries {
+		if secondary.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func IsSecondaryBackupStorageLocation(bsl *velerov1.BackupStorageLocation) bool {
+	return bsl != nil && bsl.Labels[SecondaryLocationLabel] == "true"
+}
+
+func GetReplicationPolicy(bsl *velerov1.BackupStorageLocation) types.ReplicationPolicy {
+	if policy := types.ReplicationPolicy(bsl.Annotations[ReplicationPolicyAnnotation]); policy != "" {
+		return policy
+	}
+	return types.ReplicationPolicyAll
+}
+
+func validateReplicationPolicy(policy types.ReplicationPolicy) error {
+	switch policy {
+	case "", types.ReplicationPolicyAll, types.ReplicationPolicyScheduled, types.ReplicationPolicyNone:
+		return nil
+	}
+	return errors.Errorf("invalid replication policy %q, must be one of %q, %q or %q", policy, types.ReplicationPolicyAll, types.ReplicationPolicyScheduled, types.ReplicationPolicyNone)
+}
+
+func secondaryCredentialsSecretName(locationName string) string {
+	return secondaryCredentialsSecretPrefix + locationName
+}
+
+// getCredentialsSecretReference returns the secret and key holding the credentials for a backup storage location.
+// Locations without an explicit credential use velero's cloud-credentials secret.
+func getCredentialsSecretReference(bsl *velerov1.BackupStorageLocation) (string, string) {
+	if bsl.Spec.Credential != nil && bsl.Spec.Credential.Name != "" {
+		key := bsl.Spec.Credential.Key
+		if key == "" {
+			key = CloudCredentialsSecretKey
+		}
+		return bsl.Spec.Credential.Name, key
+	}
+	return CloudCredentialsSecretName, CloudCredentialsSecretKey
+}
+
+func getStoreStatus(bsl *velerov1.BackupStorageLocation) *types.StoreStatus {
+	if bsl.Status.Phase == "" && bsl.Status.LastValidationTime == nil {
+		return nil
+	}
+
+	status := &types.StoreStatus{
+		Phase:   string(bsl.Status.Phase),
+		Message: bsl.Status.Message,
+	}
+	if bsl.Status.LastValidationTime != nil {
+		t := bsl.Status.LastValidationTime.Time
+		status.LastValidationTime = &t
+	}
+
+	return status
+}
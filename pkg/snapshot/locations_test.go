@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/snapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerofake "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_buildSecondaryBackupStorageLocation(t *testing.T) {
+	otherStore := &types.Store{
+		Provider: "aws",
+		Bucket:   "offsite",
+		Path:     "kots",
+		Other: &types.StoreOther{
+			Region:          "us-east-1",
+			Endpoint:        "https://s3.example.com",
+			AccessKeyID:     "access-key",
+			SecretAccessKey: "secret-key",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		existing   *velerov1.BackupStorageLocation
+		store      *types.Store
+		policy     types.ReplicationPolicy
+		wantPolicy string
+		wantErr    bool
+	}{
+		{
+			name:       "new location defaults to replicating all backups",
+			store:      otherStore,
+			wantPolicy: "all",
+		},
+		{
+			name:       "new location with policy",
+			store:      otherStore,
+			policy:     types.ReplicationPolicyScheduled,
+			wantPolicy: "scheduled",
+		},
+		{
+			name: "existing location keeps its policy",
+			existing: &velerov1.BackupStorageLocation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "offsite",
+					Namespace:   "velero",
+					Annotations: map[string]string{ReplicationPolicyAnnotation: "none"},
+				},
+			},
+			store:      otherStore,
+			wantPolicy: "none",
+		},
+		{
+			name: "google instance role is not supported",
+			store: &types.Store{
+				Provider: "gcp",
+				Bucket:   "offsite",
+				Google: &types.StoreGoogle{
+					UseInstanceRole: true,
+					ServiceAccount:  "sa",
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			bsl, credentials, err := buildSecondaryBackupStorageLocation(test.existing, test.store, "offsite", "velero", test.policy)
+			if test.wantErr {
+				req.Error(err)
+				return
+			}
+			req.NoError(err)
+
+			assert.Equal(t, "offsite", bsl.Name)
+			assert.Equal(t, "velero", bsl.Namespace)
+			assert.False(t, bsl.Spec.Default)
+			assert.True(t, IsSecondaryBackupStorageLocation(bsl))
+			assert.Equal(t, test.wantPolicy, bsl.Annotations[ReplicationPolicyAnnotation])
+			assert.Equal(t, "offsite", bsl.Spec.ObjectStorage.Bucket)
+			assert.Equal(t, "kots", bsl.Spec.ObjectStorage.Prefix)
+			assert.Equal(t, "https://s3.example.com", bsl.Spec.Config["s3Url"])
+			assert.Equal(t, "kotsadm-bsl-offsite", bsl.Spec.Credential.Name)
+			assert.Equal(t, "cloud", bsl.Spec.Credential.Key)
+			assert.Contains(t, string(credentials), "aws_access_key_id     = access-key")
+		})
+	}
+}
+
+func Test_getCredentialsSecretReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		bsl      *velerov1.BackupStorageLocation
+		wantName string
+		wantKey  string
+	}{
+		{
+			name:     "default location uses cloud-credentials",
+			bsl:      &velerov1.BackupStorageLocation{},
+			wantName: "cloud-credentials",
+			wantKey:  "cloud",
+		},
+		{
+			name: "location with credential",
+			bsl: &velerov1.BackupStorageLocation{
+				Spec: velerov1.BackupStorageLocationSpec{
+					Credential: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "kotsadm-bsl-offsite"},
+						Key:                  "creds",
+					},
+				},
+			},
+			wantName: "kotsadm-bsl-offsite",
+			wantKey:  "creds",
+		},
+		{
+			name: "location with credential and no key",
+			bsl: &velerov1.BackupStorageLocation{
+				Spec: velerov1.BackupStorageLocationSpec{
+					Credential: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "kotsadm-bsl-offsite"},
+					},
+				},
+			},
+			wantName: "kotsadm-bsl-offsite",
+			wantKey:  "cloud",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, key := getCredentialsSecretReference(test.bsl)
+			assert.Equal(t, test.wantName, name)
+			assert.Equal(t, test.wantKey, key)
+		})
+	}
+}
+
+func Test_listSecondaryBackupStorageLocations(t *testing.T) {
+	req := require.New(t)
+
+	veleroClient := velerofake.NewSimpleClientset(
+		&velerov1.BackupStorageLocation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "velero"},
+		},
+		&velerov1.BackupStorageLocation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "offsite",
+				Namespace: "velero",
+				Labels:    map[string]string{SecondaryLocationLabel: "true"},
+			},
+		},
+	).VeleroV1()
+
+	bsls, err := listSecondaryBackupStorageLocations(context.Background(), veleroClient, "velero")
+	req.NoError(err)
+	req.Len(bsls, 1)
+	assert.Equal(t, "offsite", bsls[0].Name)
+}
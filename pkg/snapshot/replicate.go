@@ -0,0 +1,265 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/snapshot/types"
+)
+
+// ReplicateBackup copies a velero backup, along with the file system backup repositories it references,
+// from one store to another. Objects that already exist in the destination with the same size are skipped,
+// so replicating several backups to the same store only uploads the data that changed.
+// Returns the number of objects that were copied.
+func ReplicateBackup(ctx context.Context, source *types.Store, destination *types.Store, backupName string) (int, error) {
+	sourceSession, err := newS3Session(source)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create source session")
+	}
+	destinationSession, err := newS3Session(destination)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create destination session")
+	}
+
+	sourceClient := s3.New(sourceSession)
+	destinationClient := s3.New(destinationSession)
+	uploader := s3manager.NewUploader(destinationSession)
+
+	copied := 0
+	for _, prefix := range getReplicationPrefixes(backupName) {
+		sourceObjects, err := listObjects(ctx, sourceClient, source.Bucket, objectKey(source.Path, prefix))
+		if err != nil {
+			return copied, errors.Wrapf(err, "failed to list source objects in %s", prefix)
+		}
+		if len(sourceObjects) == 0 {
+			continue
+		}
+
+		destinationObjects, err := listObjects(ctx, destinationClient, destination.Bucket, objectKey(destination.Path, prefix))
+		if err != nil {
+			return copied, errors.Wrapf(err, "failed to list destination objects in %s", prefix)
+		}
+
+		for _, key := range getObjectsToCopy(sourceObjects, destinationObjects) {
+			output, err := sourceClient.GetObjectWithContext(ctx, &s3.GetObjectInput{
+				Bucket: aws.String(source.Bucket),
+				Key:    aws.String(objectKey(source.Path, prefix+key)),
+			})
+			if err != nil {
+				return copied, errors.Wrapf(err, "failed to get object %s", prefix+key)
+			}
+
+			_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+				Bucket: aws.String(destination.Bucket),
+				Key:    aws.String(objectKey(destination.Path, prefix+key)),
+				Body:   output.Body,
+			})
+			output.Body.Close()
+			if err != nil {
+				return copied, errors.Wrapf(err, "failed to upload object %s", prefix+key)
+			}
+
+			copied++
+		}
+	}
+
+	return copied, nil
+}
+
+// DeleteReplicatedBackup deletes a backup that was replicated to a store.
+// The restic and kopia repositories are shared with the other backups in the store and are left in place,
+// so the file system snapshots of the backup are not forgotten from them. Velero only maintains the repositories
+// in the location the backups are taken in, and replication never deletes objects, so the repositories in a
+// secondary store keep the data of deleted backups until they are removed from the store.
+// Returns the number of objects that were deleted.
+func DeleteReplicatedBackup(ctx context.Context, store *types.Store, backupName string) (int, error) {
+	storeSession, err := newS3Session(store)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create session")
+	}
+
+	client := s3.New(storeSession)
+
+	prefix := objectKey(store.Path, getReplicatedBackupPrefix(backupName))
+	objects, err := listObjects(ctx, client, store.Bucket, prefix)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list objects")
+	}
+
+	keys := []string{}
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	deleted := 0
+	for _, batch := range batchKeys(keys, maxDeleteObjects) {
+		identifiers := []*s3.ObjectIdentifier{}
+		for _, key := range batch {
+			identifiers = append(identifiers, &s3.ObjectIdentifier{Key: aws.String(prefix + key)})
+		}
+
+		output, err := client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(store.Bucket),
+			Delete: &s3.Delete{
+				Objects: identifiers,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return deleted, errors.Wrap(err, "failed to delete objects")
+		}
+		if len(output.Errors) > 0 {
+			return deleted, errors.Errorf("failed to delete object %s: %s", aws.StringValue(output.Errors[0].Key), aws.StringValue(output.Errors[0].Message))
+		}
+
+		deleted += len(batch)
+	}
+
+	return deleted, nil
+}
+
+// maxDeleteObjects is the maximum number of keys in a single s3 delete objects request
+const maxDeleteObjects = 1000
+
+func batchKeys(keys []string, size int) [][]string {
+	batches := [][]string{}
+	for len(keys) > size {
+		batches = append(batches, keys[:size])
+		keys = keys[size:]
+	}
+	if len(keys) > 0 {
+		batches = append(batches, keys)
+	}
+	return batches
+}
+
+func getReplicatedBackupPrefix(backupName string) string {
+	return "backups/" + backupName + "/"
+}
+
+// getReplicationPrefixes returns the object prefixes, relative to the store path, that make up a backup.
+// The restic and kopia repositories are shared by all backups and are copied incrementally.
+func getReplicationPrefixes(backupName string) []string {
+	return []string{
+		getReplicatedBackupPrefix(backupName),
+		"restic/",
+		"kopia/",
+	}
+}
+
+// getObjectsToCopy returns the sorted keys of source objects that are missing from the destination or differ in size
+func getObjectsToCopy(sourceObjects map[string]int64, destinationObjects map[string]int64) []string {
+	keys := []string{}
+	for key, size := range sourceObjects {
+		if destinationSize, ok := destinationObjects[key]; ok && destinationSize == size {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func objectKey(storePath string, key string) string {
+	storePath = strings.Trim(storePath, "/")
+	if storePath == "" {
+		return key
+	}
+	return storePath + "/" + key
+}
+
+// listObjects returns the size of every object under the prefix, keyed by the path relative to the prefix
+func listObjects(ctx context.Context, client *s3.S3, bucket string, prefix string) (map[string]int64, error) {
+	objects := map[string]int64{}
+	err := client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects[strings.TrimPrefix(aws.StringValue(object.Key), prefix)] = aws.Int64Value(object.Size)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func newS3Session(store *types.Store) (*session.Session, error) {
+	s3Config := &aws.Config{}
+
+	switch {
+	case store.AWS != nil:
+		s3Config.Region = aws.String(store.AWS.Region)
+		if store.AWS.UseInstanceRole {
+			ec2Session, err := session.NewSession()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to create AWS ec2 session")
+			}
+			s3Config.Credentials = credentials.NewChainCredentials([]credentials.Provider{
+				&ec2rolecreds.EC2RoleProvider{
+					Client:       ec2metadata.New(ec2Session),
+					ExpiryWindow: 5 * time.Minute,
+				},
+			})
+		} else {
+			s3Config.Credentials = credentials.NewStaticCredentials(store.AWS.AccessKeyID, store.AWS.SecretAccessKey, "")
+		}
+
+	case store.Other != nil:
+		s3Config.Region = aws.String(store.Other.Region)
+		s3Config.Endpoint = aws.String(store.Other.Endpoint)
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+		s3Config.Credentials = credentials.NewStaticCredentials(store.Other.AccessKeyID, store.Other.SecretAccessKey, "")
+
+	case store.Internal != nil && store.Provider == "aws":
+		s3Config.Region = aws.String(store.Internal.Region)
+		s3Config.Endpoint = aws.String(store.Internal.Endpoint)
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+		s3Config.Credentials = credentials.NewStaticCredentials(store.Internal.AccessKeyID, store.Internal.SecretAccessKey, "")
+
+	case store.FileSystem != nil && store.Provider == FileSystemMinioProvider:
+		s3Config.Region = aws.String(store.FileSystem.Region)
+		s3Config.Endpoint = aws.String(store.FileSystem.Endpoint)
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+		s3Config.Credentials = credentials.NewStaticCredentials(store.FileSystem.AccessKeyID, store.FileSystem.SecretAccessKey, "")
+
+	default:
+		return nil, errors.Errorf("replication is not supported for provider %s", store.Provider)
+	}
+
+	if len(store.CACertData) > 0 {
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM(store.CACertData) {
+			return nil, errors.New("failed to parse ca cert")
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+		s3Config.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	newSession, err := session.NewSession(s3Config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 session")
+	}
+
+	return newSession, nil
+}
@@ -0,0 +1,90 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getObjectsToCopy(t *testing.T) {
+	tests := []struct {
+		name               string
+		sourceObjects      map[string]int64
+		destinationObjects map[string]int64
+		want               []string
+	}{
+		{
+			name:               "empty destination",
+			sourceObjects:      map[string]int64{"b": 2, "a": 1},
+			destinationObjects: map[string]int64{},
+			want:               []string{"a", "b"},
+		},
+		{
+			name:               "skips objects with the same size",
+			sourceObjects:      map[string]int64{"a": 1, "b": 2, "c": 3},
+			destinationObjects: map[string]int64{"a": 1, "b": 5},
+			want:               []string{"b", "c"},
+		},
+		{
+			name:               "everything replicated",
+			sourceObjects:      map[string]int64{"a": 1},
+			destinationObjects: map[string]int64{"a": 1, "z": 9},
+			want:               []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, getObjectsToCopy(test.sourceObjects, test.destinationObjects))
+		})
+	}
+}
+
+func Test_objectKey(t *testing.T) {
+	tests := []struct {
+		storePath string
+		key       string
+		want      string
+	}{
+		{storePath: "", key: "backups/a/", want: "backups/a/"},
+		{storePath: "velero", key: "backups/a/", want: "velero/backups/a/"},
+		{storePath: "/velero/", key: "restic/", want: "velero/restic/"},
+	}
+	for _, test := range tests {
+		t.Run(test.storePath+test.key, func(t *testing.T) {
+			assert.Equal(t, test.want, objectKey(test.storePath, test.key))
+		})
+	}
+}
+
+func Test_batchKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		size int
+		want [][]string
+	}{
+		{
+			name: "no keys",
+			keys: []string{},
+			size: 2,
+			want: [][]string{},
+		},
+		{
+			name: "single batch",
+			keys: []string{"a", "b"},
+			size: 2,
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "partial last batch",
+			keys: []string{"a", "b", "c", "d", "e"},
+			size: 2,
+			want: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, batchKeys(test.keys, test.size))
+		})
+	}
+}
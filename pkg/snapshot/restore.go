@@ -21,6 +21,7 @@ import (
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 )
 
@@ -31,6 +32,8 @@ type RestoreInstanceBackupOptions struct {
 	WaitForApps         bool
 	VeleroNamespace     string
 	Silent              bool
+	// Filter restores only part of the backup, without replacing the admin console or the applications
+	Filter snapshottypes.RestoreFilter
 }
 
 type ListInstanceRestoresOptions struct {
@@ -101,6 +104,10 @@ func RestoreInstanceBackup(ctx context.Context, options RestoreInstanceBackupOpt
 		log.Silence()
 	}
 
+	if !options.Filter.IsEmpty() {
+		return restoreInstanceBackupSelection(ctx, clientset, veleroClient, backup, options.Filter, log)
+	}
+
 	if !options.ExcludeAdminConsole {
 		log.ActionWithSpinner("Deleting Admin Console")
 
@@ -251,6 +258,86 @@ func RestoreInstanceBackup(ctx context.Context, options RestoreInstanceBackupOpt
 	return nil
 }
 
+func restoreInstanceBackupSelection(ctx context.Context, clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, backup *velerov1.Backup, filter snapshottypes.RestoreFilter, log *logger.CLILogger) error {
+	log.ActionWithSpinner("Restoring selected resources")
+
+	if len(filter.PVCs) > 0 {
+		resourceList, err := GetBackupResourceList(ctx, backup.Namespace, backup.Name)
+		if err != nil {
+			log.FinishSpinnerWithError()
+			return errors.Wrap(err, "failed to get backup resource list")
+		}
+		podVolumeBackups, err := ListBackupPodVolumeBackups(ctx, veleroClient, backup)
+		if err != nil {
+			log.FinishSpinnerWithError()
+			return errors.Wrap(err, "failed to list pod volume backups")
+		}
+		if err := CheckPVCsForRestore(ctx, clientset, resourceList, podVolumeBackups, filter.PVCs); err != nil {
+			log.FinishSpinnerWithError()
+			return errors.Wrap(err, "failed to check persistent volume claims")
+		}
+	}
+
+	restore, err := NewSelectiveRestore(backup, GetSelectiveRestoreName(backup.Name, ""), filter)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to create selective restore")
+	}
+
+	restore, err = veleroClient.Restores(backup.Namespace).Create(ctx, restore, metav1.CreateOptions{})
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to create restore")
+	}
+
+	restore, err = waitForVeleroRestoreCompleted(ctx, backup.Namespace, restore.ObjectMeta.Name)
+	if err != nil {
+		if restore != nil {
+			errMsg := fmt.Sprintf("Restore %s failed with %d errors and %d warnings.", restore.ObjectMeta.Name, restore.Status.Errors, restore.Status.Warnings)
+			log.FinishSpinnerWithError()
+			log.ActionWithoutSpinner(errMsg)
+			return errors.Wrap(err, errMsg)
+		}
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to wait for velero restore completed")
+	}
+
+	log.FinishSpinner()
+	log.ActionWithoutSpinner(fmt.Sprintf("Selected resources restored successfully by restore %s.", restore.ObjectMeta.Name))
+
+	return nil
+}
+
+// PreviewInstanceRestore lists the resources in the instance backup that a restore with the options' filter includes
+func PreviewInstanceRestore(ctx context.Context, options RestoreInstanceBackupOptions) (*snapshottypes.BackupContents, error) {
+	if err := ValidateRestoreFilter(options.Filter); err != nil {
+		return nil, err
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	veleroNamespace := options.VeleroNamespace
+	if veleroNamespace == "" {
+		veleroNamespace, err = DetectVeleroNamespace(ctx, clientset, "")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to detect velero namespace")
+		}
+		if veleroNamespace == "" {
+			return nil, errors.New("velero not found")
+		}
+	}
+
+	resourceList, err := GetBackupResourceList(ctx, veleroNamespace, options.BackupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup resource list")
+	}
+
+	return FilterBackupContents(resourceList, options.Filter), nil
+}
+
 func ListInstanceRestores(ctx context.Context, options ListInstanceRestoresOptions) ([]velerov1.Restore, error) {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
@@ -0,0 +1,428 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"github.com/vmware-tanzu/velero/pkg/cmd/util/downloadrequest"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+	kbclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	RestoreFilterAnnotation = "kots.io/restore-filter"
+
+	pvcResourceKind = "v1/PersistentVolumeClaim"
+
+	// podVolumeBackupPVCNameAnnotation is set by velero on the pod volume backups of volumes that mount a persistent volume claim
+	podVolumeBackupPVCNameAnnotation = "velero.io/pvc-name"
+)
+
+var (
+	// pvcRestoreResources are restored for a persistent volume claim. Pods are not restored since velero
+	// can't filter them by the claims they mount, so only volume snapshots are restored. Claims that were
+	// backed up with a file system backup of their pod (the default) are rejected by CheckPVCsForRestore.
+	pvcRestoreResources = []string{"persistentvolumeclaims", "persistentvolumes"}
+)
+
+type InvalidRestoreFilterError struct {
+	Message string
+}
+
+func (e *InvalidRestoreFilterError) Error() string {
+	return e.Message
+}
+
+// ValidateRestoreFilter checks the syntax of a restore filter
+func ValidateRestoreFilter(filter snapshottypes.RestoreFilter) error {
+	for _, namespace := range filter.IncludedNamespaces {
+		if namespace == "" {
+			return &InvalidRestoreFilterError{Message: "included namespaces cannot be empty"}
+		}
+	}
+
+	for _, resource := range filter.IncludedResources {
+		if resource == "" {
+			return &InvalidRestoreFilterError{Message: "included resources cannot be empty"}
+		}
+	}
+
+	if filter.LabelSelector != "" {
+		if _, err := parseLabelSelector(filter.LabelSelector); err != nil {
+			return &InvalidRestoreFilterError{Message: fmt.Sprintf("invalid label selector %q: %v", filter.LabelSelector, err)}
+		}
+	}
+
+	if len(filter.PVCs) > 0 {
+		// velero can't filter a restore by name, so persistent volume claims are restored on their own
+		if len(filter.IncludedNamespaces) > 0 || len(filter.IncludedResources) > 0 || filter.LabelSelector != "" {
+			return &InvalidRestoreFilterError{Message: "persistent volume claims cannot be combined with other restore filters"}
+		}
+		for _, pvc := range filter.PVCs {
+			if _, _, err := splitPVCName(pvc); err != nil {
+				return &InvalidRestoreFilterError{Message: err.Error()}
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewSelectiveRestore returns a velero restore of the parts of the backup that match the filter
+func NewSelectiveRestore(backup *velerov1.Backup, restoreName string, filter snapshottypes.RestoreFilter) (*velerov1.Restore, error) {
+	if err := ValidateRestoreFilter(filter); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal restore filter")
+	}
+
+	restore := &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: backup.Namespace,
+			Name:      restoreName,
+			Annotations: map[string]string{
+				RestoreFilterAnnotation: string(b),
+			},
+		},
+		Spec: velerov1.RestoreSpec{
+			BackupName: backup.Name,
+			RestorePVs: pointer.Bool(true),
+		},
+	}
+
+	if backup.Annotations["kots.io/instance"] == "true" {
+		restore.Annotations["kots.io/instance"] = "true"
+		restore.Annotations["kots.io/kotsadm-deploy-namespace"] = backup.Annotations["kots.io/kotsadm-deploy-namespace"]
+	}
+
+	if len(filter.PVCs) > 0 {
+		restore.Spec.IncludedNamespaces = getPVCNamespaces(filter.PVCs)
+		restore.Spec.IncludedResources = append([]string{}, pvcRestoreResources...)
+		return restore, nil
+	}
+
+	restore.Spec.IncludedNamespaces = filter.IncludedNamespaces
+	restore.Spec.IncludedResources = filter.IncludedResources
+	if filter.LabelSelector != "" {
+		labelSelector, err := parseLabelSelector(filter.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse label selector")
+		}
+		restore.Spec.LabelSelector = labelSelector
+	}
+	if len(filter.IncludedNamespaces) == 0 {
+		// when namespaces are filtered, velero only restores the cluster scoped resources that belong to the restored resources
+		restore.Spec.IncludeClusterResources = pointer.Bool(true)
+	}
+
+	return restore, nil
+}
+
+// GetSelectiveRestoreName returns a unique name for a selective restore from the backup
+func GetSelectiveRestoreName(backupName string, appSlug string) string {
+	suffix := fmt.Sprintf("selective-%s", time.Now().UTC().Format("20060102150405"))
+	if appSlug == "" {
+		return fmt.Sprintf("%s.%s", backupName, suffix)
+	}
+	return fmt.Sprintf("%s.%s.%s", backupName, appSlug, suffix)
+}
+
+// CheckPVCsForRestore makes sure that restoring the persistent volume claims restores only those, with their data.
+// Velero skips resources that exist in the cluster, so the persistent volume claims must have been deleted,
+// and every other persistent volume claim from the backup in the same namespaces must still exist.
+// The data of claims in the pod volume backups can only be restored with their pods, so those claims are rejected.
+func CheckPVCsForRestore(ctx context.Context, clientset kubernetes.Interface, resourceList map[string][]string, podVolumeBackups []velerov1.PodVolumeBackup, pvcs []string) error {
+	backupPVCs := resourceList[pvcResourceKind]
+	namespaces := getPVCNamespaces(pvcs)
+
+	for _, pvc := range pvcs {
+		if !contains(backupPVCs, pvc) {
+			return &InvalidRestoreFilterError{Message: fmt.Sprintf("persistent volume claim %s is not in the backup", pvc)}
+		}
+
+		if pod := getPVCBackupPod(podVolumeBackups, pvc); pod != "" {
+			return &InvalidRestoreFilterError{Message: fmt.Sprintf("persistent volume claim %s was backed up with the file system of pod %s and can only be restored with the pod, restore its namespace instead", pvc, pod)}
+		}
+
+		exists, err := pvcExists(ctx, clientset, pvc)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check persistent volume claim %s", pvc)
+		}
+		if exists {
+			return &InvalidRestoreFilterError{Message: fmt.Sprintf("persistent volume claim %s exists, delete it before restoring it", pvc)}
+		}
+	}
+
+	for _, backupPVC := range backupPVCs {
+		if contains(pvcs, backupPVC) {
+			continue
+		}
+		namespace, _, err := splitPVCName(backupPVC)
+		if err != nil || !contains(namespaces, namespace) {
+			continue
+		}
+
+		exists, err := pvcExists(ctx, clientset, backupPVC)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check persistent volume claim %s", backupPVC)
+		}
+		if !exists {
+			return &InvalidRestoreFilterError{Message: fmt.Sprintf("persistent volume claim %s is also missing and would be restored, include it in the restore", backupPVC)}
+		}
+	}
+
+	return nil
+}
+
+// ListBackupPodVolumeBackups returns the file system backups of the pod volumes in the backup
+func ListBackupPodVolumeBackups(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, backup *velerov1.Backup) ([]velerov1.PodVolumeBackup, error) {
+	podVolumeBackups, err := veleroClient.PodVolumeBackups(backup.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", velerov1.BackupNameLabel, velerolabel.GetValidName(backup.Name)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pod volume backups")
+	}
+	return podVolumeBackups.Items, nil
+}
+
+// GetBackupResourceList returns the resources velero backed up, keyed by group, version and kind
+func GetBackupResourceList(ctx context.Context, veleroNamespace string, backupName string) (map[string][]string, error) {
+	clientConfig, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	scheme := runtime.NewScheme()
+	velerov1.AddToScheme(scheme)
+	kbClient, err := kbclient.New(clientConfig, kbclient.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kubebuilder client")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := downloadrequest.Stream(ctx, kbClient, veleroNamespace, backupName, velerov1.DownloadTargetKindBackupResourceList, buf, time.Minute, true, ""); err != nil {
+		return nil, errors.Wrap(err, "failed to download backup resource list")
+	}
+
+	resourceList := map[string][]string{}
+	if err := json.NewDecoder(buf).Decode(&resourceList); err != nil {
+		return nil, errors.Wrap(err, "failed to decode backup resource list")
+	}
+
+	return resourceList, nil
+}
+
+// FilterBackupContents returns the resources in the backup resource list that match the filter.
+// Label selectors can't be evaluated against the resource list and are ignored.
+func FilterBackupContents(resourceList map[string][]string, filter snapshottypes.RestoreFilter) *snapshottypes.BackupContents {
+	contents := &snapshottypes.BackupContents{
+		Namespaces: []string{},
+		Resources:  []snapshottypes.BackupResourceGroup{},
+	}
+
+	kinds := []string{}
+	for kind := range resourceList {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	namespaces := map[string]bool{}
+	for _, kind := range kinds {
+		if len(filter.PVCs) > 0 && kind != pvcResourceKind {
+			continue
+		}
+		if len(filter.IncludedResources) > 0 && !matchesAnyResource(kind, filter.IncludedResources) {
+			continue
+		}
+
+		items := []string{}
+		for _, item := range resourceList[kind] {
+			namespace := ""
+			if parts := strings.SplitN(item, "/", 2); len(parts) == 2 {
+				namespace = parts[0]
+			}
+			if len(filter.IncludedNamespaces) > 0 && namespace != "" && !contains(filter.IncludedNamespaces, namespace) {
+				continue
+			}
+			if len(filter.PVCs) > 0 && !contains(filter.PVCs, item) {
+				continue
+			}
+			if namespace != "" {
+				namespaces[namespace] = true
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		sort.Strings(items)
+		contents.Resources = append(contents.Resources, snapshottypes.BackupResourceGroup{
+			Kind:  kind,
+			Items: items,
+		})
+	}
+
+	for namespace := range namespaces {
+		contents.Namespaces = append(contents.Namespaces, namespace)
+	}
+	sort.Strings(contents.Namespaces)
+
+	return contents
+}
+
+// matchesAnyResource returns true if a resource list kind, e.g. "apps/v1/Deployment", matches any of the resources.
+// Resources are matched the way velero accepts them, by plural or singular name with an optional group, e.g. "deployments.apps".
+func matchesAnyResource(kind string, resources []string) bool {
+	i := strings.LastIndex(kind, "/")
+	if i == -1 {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(kind[:i])
+	if err != nil {
+		return false
+	}
+	plural, singular := meta.UnsafeGuessKindToResource(gv.WithKind(kind[i+1:]))
+
+	for _, resource := range resources {
+		if resource == "*" {
+			return true
+		}
+		name, group := strings.ToLower(resource), ""
+		if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+			name, group = parts[0], parts[1]
+		}
+		if group != "" && group != gv.Group {
+			continue
+		}
+		if name == plural.Resource || name == singular.Resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseLabelSelector converts a label selector query, e.g. "app=postgres,tier!=cache", to a label selector
+func parseLabelSelector(selector string) (*metav1.LabelSelector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	requirements, _ := parsed.Requirements()
+
+	labelSelector := &metav1.LabelSelector{}
+	for _, requirement := range requirements {
+		values := requirement.Values().List()
+
+		var operator metav1.LabelSelectorOperator
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			if labelSelector.MatchLabels == nil {
+				labelSelector.MatchLabels = map[string]string{}
+			}
+			labelSelector.MatchLabels[requirement.Key()] = values[0]
+			continue
+		case selection.NotEquals, selection.NotIn:
+			operator = metav1.LabelSelectorOpNotIn
+		case selection.In:
+			operator = metav1.LabelSelectorOpIn
+		case selection.Exists:
+			operator = metav1.LabelSelectorOpExists
+		case selection.DoesNotExist:
+			operator = metav1.LabelSelectorOpDoesNotExist
+		default:
+			return nil, errors.Errorf("operator %q is not supported", requirement.Operator())
+		}
+
+		labelSelector.MatchExpressions = append(labelSelector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      requirement.Key(),
+			Operator: operator,
+			Values:   values,
+		})
+	}
+
+	return labelSelector, nil
+}
+
+func splitPVCName(pvc string) (string, string, error) {
+	parts := strings.Split(pvc, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid persistent volume claim %q, must be namespace/name", pvc)
+	}
+	return parts[0], parts[1], nil
+}
+
+func getPVCNamespaces(pvcs []string) []string {
+	namespaces := []string{}
+	for _, pvc := range pvcs {
+		namespace, _, err := splitPVCName(pvc)
+		if err != nil || contains(namespaces, namespace) {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+// getPVCBackupPod returns the pod, in the form namespace/name, whose pod volume backup has the data of the persistent volume claim
+func getPVCBackupPod(podVolumeBackups []velerov1.PodVolumeBackup, pvc string) string {
+	namespace, name, err := splitPVCName(pvc)
+	if err != nil {
+		return ""
+	}
+	for _, podVolumeBackup := range podVolumeBackups {
+		if podVolumeBackup.Spec.Pod.Namespace == namespace && podVolumeBackup.Annotations[podVolumeBackupPVCNameAnnotation] == name {
+			return fmt.Sprintf("%s/%s", podVolumeBackup.Spec.Pod.Namespace, podVolumeBackup.Spec.Pod.Name)
+		}
+	}
+	return ""
+}
+
+func pvcExists(ctx context.Context, clientset kubernetes.Interface, pvc string) (bool, error) {
+	namespace, name, err := splitPVCName(pvc)
+	if err != nil {
+		return false, err
+	}
+	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get persistent volume claim")
+	}
+	return true, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+var testBackupResourceList = map[string][]string{
+	"apps/v1/Deployment":                       {"app/api", "app/web", "kotsadm/kotsadm"},
+	"apps/v1/StatefulSet":                      {"app/postgres"},
+	"v1/ConfigMap":                             {"app/config", "kotsadm/kotsadm-confg"},
+	"v1/Namespace":                             {"app", "kotsadm"},
+	"v1/PersistentVolume":                      {"pvc-1234", "pvc-5678"},
+	"v1/PersistentVolumeClaim":                 {"app/data-postgres-0", "app/uploads", "kotsadm/kotsadm-rqlite"},
+	"rbac.authorization.k8s.io/v1/ClusterRole": {"kotsadm-role"},
+}
+
+func TestValidateRestoreFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  snapshottypes.RestoreFilter
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			filter: snapshottypes.RestoreFilter{},
+		},
+		{
+			name: "namespaces, resources and selector",
+			filter: snapshottypes.RestoreFilter{
+				IncludedNamespaces: []string{"app"},
+				IncludedResources:  []string{"deployments", "configmaps"},
+				LabelSelector:      "app=postgres,tier!=cache",
+			},
+		},
+		{
+			name:   "pvcs",
+			filter: snapshottypes.RestoreFilter{PVCs: []string{"app/uploads", "app/data-postgres-0"}},
+		},
+		{
+			name:    "empty namespace",
+			filter:  snapshottypes.RestoreFilter{IncludedNamespaces: []string{""}},
+			wantErr: true,
+		},
+		{
+			name:    "empty resource",
+			filter:  snapshottypes.RestoreFilter{IncludedResources: []string{""}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid selector",
+			filter:  snapshottypes.RestoreFilter{LabelSelector: "app in (postgres"},
+			wantErr: true,
+		},
+		{
+			name:    "pvc without namespace",
+			filter:  snapshottypes.RestoreFilter{PVCs: []string{"uploads"}},
+			wantErr: true,
+		},
+		{
+			name:    "pvcs combined with namespaces",
+			filter:  snapshottypes.RestoreFilter{IncludedNamespaces: []string{"app"}, PVCs: []string{"app/uploads"}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateRestoreFilter(test.filter)
+			if test.wantErr {
+				require.Error(t, err)
+				assert.IsType(t, &InvalidRestoreFilterError{}, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewSelectiveRestore(t *testing.T) {
+	instanceBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "instance-abcd",
+			Namespace: "velero",
+			Annotations: map[string]string{
+				"kots.io/instance":                 "true",
+				"kots.io/kotsadm-deploy-namespace": "kotsadm",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		backup   *velerov1.Backup
+		filter   snapshottypes.RestoreFilter
+		wantSpec velerov1.RestoreSpec
+	}{
+		{
+			name:   "namespaces and selector",
+			backup: instanceBackup,
+			filter: snapshottypes.RestoreFilter{
+				IncludedNamespaces: []string{"app"},
+				LabelSelector:      "app=postgres,tier!=cache,backup",
+			},
+			wantSpec: velerov1.RestoreSpec{
+				BackupName:         "instance-abcd",
+				RestorePVs:         pointer.Bool(true),
+				IncludedNamespaces: []string{"app"},
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "postgres"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "backup", Operator: metav1.LabelSelectorOpExists, Values: []string{}},
+						{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"cache"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "resources",
+			backup: instanceBackup,
+			filter: snapshottypes.RestoreFilter{
+				IncludedResources: []string{"configmaps"},
+			},
+			wantSpec: velerov1.RestoreSpec{
+				BackupName:              "instance-abcd",
+				RestorePVs:              pointer.Bool(true),
+				IncludedResources:       []string{"configmaps"},
+				IncludeClusterResources: pointer.Bool(true),
+			},
+		},
+		{
+			name:   "pvcs",
+			backup: instanceBackup,
+			filter: snapshottypes.RestoreFilter{
+				PVCs: []string{"app/uploads", "db/data", "app/data-postgres-0"},
+			},
+			wantSpec: velerov1.RestoreSpec{
+				BackupName:         "instance-abcd",
+				RestorePVs:         pointer.Bool(true),
+				IncludedNamespaces: []string{"app", "db"},
+				IncludedResources:  []string{"persistentvolumeclaims", "persistentvolumes"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			restore, err := NewSelectiveRestore(test.backup, "instance-abcd.selective", test.filter)
+			require.NoError(t, err)
+
+			assert.Equal(t, "instance-abcd.selective", restore.Name)
+			assert.Equal(t, "velero", restore.Namespace)
+			assert.Equal(t, "true", restore.Annotations["kots.io/instance"])
+			assert.Equal(t, "kotsadm", restore.Annotations["kots.io/kotsadm-deploy-namespace"])
+			assert.NotEmpty(t, restore.Annotations[RestoreFilterAnnotation])
+			assert.Equal(t, test.wantSpec, restore.Spec)
+		})
+	}
+}
+
+func TestFilterBackupContents(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter snapshottypes.RestoreFilter
+		want   *snapshottypes.BackupContents
+	}{
+		{
+			name:   "namespace",
+			filter: snapshottypes.RestoreFilter{IncludedNamespaces: []string{"app"}},
+			want: &snapshottypes.BackupContents{
+				Namespaces: []string{"app"},
+				Resources: []snapshottypes.BackupResourceGroup{
+					{Kind: "apps/v1/Deployment", Items: []string{"app/api", "app/web"}},
+					{Kind: "apps/v1/StatefulSet", Items: []string{"app/postgres"}},
+					{Kind: "rbac.authorization.k8s.io/v1/ClusterRole", Items: []string{"kotsadm-role"}},
+					{Kind: "v1/ConfigMap", Items: []string{"app/config"}},
+					{Kind: "v1/Namespace", Items: []string{"app", "kotsadm"}},
+					{Kind: "v1/PersistentVolume", Items: []string{"pvc-1234", "pvc-5678"}},
+					{Kind: "v1/PersistentVolumeClaim", Items: []string{"app/data-postgres-0", "app/uploads"}},
+				},
+			},
+		},
+		{
+			name:   "resources",
+			filter: snapshottypes.RestoreFilter{IncludedResources: []string{"deployments.apps", "ConfigMap"}},
+			want: &snapshottypes.BackupContents{
+				Namespaces: []string{"app", "kotsadm"},
+				Resources: []snapshottypes.BackupResourceGroup{
+					{Kind: "apps/v1/Deployment", Items: []string{"app/api", "app/web", "kotsadm/kotsadm"}},
+					{Kind: "v1/ConfigMap", Items: []string{"app/config", "kotsadm/kotsadm-confg"}},
+				},
+			},
+		},
+		{
+			name:   "namespace and resources",
+			filter: snapshottypes.RestoreFilter{IncludedNamespaces: []string{"kotsadm"}, IncludedResources: []string{"deployment", "clusterroles"}},
+			want: &snapshottypes.BackupContents{
+				Namespaces: []string{"kotsadm"},
+				Resources: []snapshottypes.BackupResourceGroup{
+					{Kind: "apps/v1/Deployment", Items: []string{"kotsadm/kotsadm"}},
+					{Kind: "rbac.authorization.k8s.io/v1/ClusterRole", Items: []string{"kotsadm-role"}},
+				},
+			},
+		},
+		{
+			name:   "pvcs",
+			filter: snapshottypes.RestoreFilter{PVCs: []string{"app/uploads"}},
+			want: &snapshottypes.BackupContents{
+				Namespaces: []string{"app"},
+				Resources: []snapshottypes.BackupResourceGroup{
+					{Kind: "v1/PersistentVolumeClaim", Items: []string{"app/uploads"}},
+				},
+			},
+		},
+		{
+			name:   "nothing matches",
+			filter: snapshottypes.RestoreFilter{IncludedResources: []string{"secrets"}},
+			want: &snapshottypes.BackupContents{
+				Namespaces: []string{},
+				Resources:  []snapshottypes.BackupResourceGroup{},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, FilterBackupContents(testBackupResourceList, test.filter))
+		})
+	}
+}
+
+func TestCheckPVCsForRestore(t *testing.T) {
+	pvc := func(namespace string, name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+		}
+	}
+
+	podVolumeBackup := func(namespace string, pod string, pvc string) velerov1.PodVolumeBackup {
+		return velerov1.PodVolumeBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "velero",
+				Name:        "instance-abcd-" + pod,
+				Annotations: map[string]string{"velero.io/pvc-name": pvc},
+			},
+			Spec: velerov1.PodVolumeBackupSpec{
+				Pod: corev1.ObjectReference{Namespace: namespace, Name: pod},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		existing         []*corev1.PersistentVolumeClaim
+		podVolumeBackups []velerov1.PodVolumeBackup
+		pvcs             []string
+		wantErr          string
+	}{
+		{
+			name:             "deleted pvc",
+			existing:         []*corev1.PersistentVolumeClaim{pvc("app", "data-postgres-0")},
+			podVolumeBackups: []velerov1.PodVolumeBackup{podVolumeBackup("kotsadm", "kotsadm-rqlite-0", "uploads")},
+			pvcs:             []string{"app/uploads"},
+		},
+		{
+			name:             "pvc in a pod volume backup",
+			existing:         []*corev1.PersistentVolumeClaim{pvc("app", "data-postgres-0")},
+			podVolumeBackups: []velerov1.PodVolumeBackup{podVolumeBackup("app", "web-5f7d8", "uploads")},
+			pvcs:             []string{"app/uploads"},
+			wantErr:          "persistent volume claim app/uploads was backed up with the file system of pod app/web-5f7d8 and can only be restored with the pod, restore its namespace instead",
+		},
+		{
+			name: "every deleted pvc in the namespace",
+			pvcs: []string{"app/uploads", "app/data-postgres-0"},
+		},
+		{
+			name:     "pvc not in backup",
+			existing: []*corev1.PersistentVolumeClaim{pvc("app", "data-postgres-0")},
+			pvcs:     []string{"app/cache"},
+			wantErr:  "persistent volume claim app/cache is not in the backup",
+		},
+		{
+			name:     "pvc still exists",
+			existing: []*corev1.PersistentVolumeClaim{pvc("app", "data-postgres-0"), pvc("app", "uploads")},
+			pvcs:     []string{"app/uploads"},
+			wantErr:  "persistent volume claim app/uploads exists, delete it before restoring it",
+		},
+		{
+			name:    "another pvc in the namespace is missing",
+			pvcs:    []string{"app/uploads"},
+			wantErr: "persistent volume claim app/data-postgres-0 is also missing and would be restored, include it in the restore",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, existing := range test.existing {
+				_, err := clientset.CoreV1().PersistentVolumeClaims(existing.Namespace).Create(context.Background(), existing, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			err := CheckPVCsForRestore(context.Background(), clientset, testBackupResourceList, test.podVolumeBackups, test.pvcs)
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				assert.IsType(t, &InvalidRestoreFilterError{}, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -78,6 +78,10 @@ type ConfigureStoreOptions struct {
 	ValidateUsingAPod bool
 	SkipValidation    bool
 	IsMinioDisabled   bool
+
+	// LocationName configures the secondary store with this name instead of the primary store
+	LocationName      string
+	ReplicationPolicy types.ReplicationPolicy
 }
 
 type ValidateStoreOptions struct {
@@ -97,6 +101,10 @@ func (e *InvalidStoreDataError) Error() string {
 }
 
 func ConfigureStore(ctx context.Context, options ConfigureStoreOptions) (*types.Store, error) {
+	if options.LocationName != "" && options.LocationName != DefaultBackupStorageLocationName {
+		return configureSecondaryStore(ctx, options)
+	}
+
 	existingStore, err := GetGlobalStore(ctx, options.KotsadmNamespace, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get store")
@@ -461,38 +469,20 @@ func upsertGlobalStore(ctx context.Context, store *types.Store, kotsadmNamespace
 	bsl.Spec.ObjectStorage.Prefix = store.Path
 	bsl.Spec.ObjectStorage.CACert = store.CACertData
 
-	if store.AWS != nil {
-		resolver := endpoints.DefaultResolver()
-		resolvedEndpoint, err := resolver.EndpointFor("s3", store.AWS.Region)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to resolve endpoint")
-		}
-		bsl.Spec.Config = map[string]string{
-			"region": store.AWS.Region,
-			"s3Url":  resolvedEndpoint.URL,
-		}
-
-		awsCredentials, err := BuildAWSCredentials(store.AWS.AccessKeyID, store.AWS.SecretAccessKey)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to format aws credentials")
-		}
-
-		if err := ensureCloudCredentialsSecret(ctx, clientset, bsl.Namespace, awsCredentials); err != nil {
-			return nil, errors.Wrap(err, "failed to ensure cloud credentials secret")
-		}
-	} else if store.Other != nil {
-		bsl.Spec.Config = map[string]string{
-			"region":           store.Other.Region,
-			"s3Url":            store.Other.Endpoint,
-			"s3ForcePathStyle": "true",
-		}
-
-		otherCredentials, err := BuildAWSCredentials(store.Other.AccessKeyID, store.Other.SecretAccessKey)
+	if isObjectStore(store) {
+		config, credentials, err := getObjectStoreConfig(store)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to format other credentials")
+			return nil, errors.Wrap(err, "failed to get object store config")
 		}
+		bsl.Spec.Config = config
 
-		if err := ensureCloudCredentialsSecret(ctx, clientset, bsl.Namespace, otherCredentials); err != nil {
+		if credentials == nil {
+			// instance role, delete the secret
+			err := clientset.CoreV1().Secrets(bsl.Namespace).Delete(ctx, CloudCredentialsSecretName, metav1.DeleteOptions{})
+			if err != nil && !kuberneteserrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "failed to delete cloud credentials secret")
+			}
+		} else if err := ensureCloudCredentialsSecret(ctx, clientset, bsl.Namespace, credentials); err != nil {
 			return nil, errors.Wrap(err, "failed to ensure cloud credentials secret")
 		}
 	} else if store.Internal != nil {
@@ -534,31 +524,79 @@ func upsertGlobalStore(ctx context.Context, store *types.Store, kotsadmNamespace
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to update file system store for lvp")
 		}
-	} else if store.Google != nil {
+	} else {
+		return nil, errors.Wrap(err, "malformed input - could not determine provider")
+	}
+
+	updated, err := upsertBackupStorageLocation(ctx, bsl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to upsert backup storage location")
+	}
+
+	return updated, nil
+}
+
+func isObjectStore(store *types.Store) bool {
+	return store.AWS != nil || store.Other != nil || store.Google != nil || store.Azure != nil
+}
+
+// getObjectStoreConfig returns the backup storage location config and the contents of the velero credentials file
+// for cloud object stores. The credentials are nil when an instance role is used.
+func getObjectStoreConfig(store *types.Store) (map[string]string, []byte, error) {
+	if store.AWS != nil {
+		resolver := endpoints.DefaultResolver()
+		resolvedEndpoint, err := resolver.EndpointFor("s3", store.AWS.Region)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to resolve endpoint")
+		}
+		config := map[string]string{
+			"region": store.AWS.Region,
+			"s3Url":  resolvedEndpoint.URL,
+		}
+
+		// empty credentials are written for instance roles
+		awsCredentials, err := BuildAWSCredentials(store.AWS.AccessKeyID, store.AWS.SecretAccessKey)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to format aws credentials")
+		}
+
+		return config, awsCredentials, nil
+	}
+
+	if store.Other != nil {
+		config := map[string]string{
+			"region":           store.Other.Region,
+			"s3Url":            store.Other.Endpoint,
+			"s3ForcePathStyle": "true",
+		}
+
+		otherCredentials, err := BuildAWSCredentials(store.Other.AccessKeyID, store.Other.SecretAccessKey)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to format other credentials")
+		}
+
+		return config, otherCredentials, nil
+	}
+
+	if store.Google != nil {
 		if store.Google.UseInstanceRole {
-			bsl.Spec.Config = map[string]string{
+			config := map[string]string{
 				"serviceAccount": store.Google.ServiceAccount,
 			}
-
-			// delete the secret
-			err := clientset.CoreV1().Secrets(bsl.Namespace).Delete(ctx, CloudCredentialsSecretName, metav1.DeleteOptions{})
-			if err != nil && !kuberneteserrors.IsNotFound(err) {
-				return nil, errors.Wrap(err, "failed to delete google creds secret")
-			}
-		} else {
-			if err := ensureCloudCredentialsSecret(ctx, clientset, bsl.Namespace, []byte(store.Google.JSONFile)); err != nil {
-				return nil, errors.Wrap(err, "failed to ensure cloud credentials secret")
-			}
+			return config, nil, nil
 		}
-	} else if store.Azure != nil {
+		return map[string]string{}, []byte(store.Google.JSONFile), nil
+	}
+
+	if store.Azure != nil {
 		// https://github.com/vmware-tanzu/velero-plugin-for-microsoft-azure/blob/main/backupstoragelocation.md
-		bsl.Spec.Config = map[string]string{
+		config := map[string]string{
 			"resourceGroup":  store.Azure.ResourceGroup,
 			"storageAccount": store.Azure.StorageAccount,
 			"subscriptionId": store.Azure.SubscriptionID,
 		}
 
-		config := providers.Azure{
+		azureConfig := providers.Azure{
 			SubscriptionID: store.Azure.SubscriptionID,
 			TenantID:       store.Azure.TenantID,
 			ClientID:       store.Azure.ClientID,
@@ -566,23 +604,19 @@ func upsertGlobalStore(ctx context.Context, store *types.Store, kotsadmNamespace
 			ResourceGroup:  store.Azure.ResourceGroup,
 			CloudName:      store.Azure.CloudName,
 		}
-		if err := ensureCloudCredentialsSecret(ctx, clientset, bsl.Namespace, providers.RenderAzureConfig(config)); err != nil {
-			return nil, errors.Wrap(err, "failed to ensure cloud credentials secret")
-		}
-	} else {
-		return nil, errors.Wrap(err, "malformed input - could not determine provider")
-	}
 
-	updated, err := upsertBackupStorageLocation(ctx, bsl)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to upsert backup storage location")
+		return config, providers.RenderAzureConfig(azureConfig), nil
 	}
 
-	return updated, nil
+	return nil, nil, errors.New("not an object store")
 }
 
 func ensureCloudCredentialsSecret(ctx context.Context, clientset kubernetes.Interface, veleroNamespace string, creds []byte) error {
-	credsSecret, err := clientset.CoreV1().Secrets(veleroNamespace).Get(ctx, CloudCredentialsSecretName, metav1.GetOptions{})
+	return ensureCredentialsSecret(ctx, clientset, veleroNamespace, CloudCredentialsSecretName, creds)
+}
+
+func ensureCredentialsSecret(ctx context.Context, clientset kubernetes.Interface, veleroNamespace string, secretName string, creds []byte) error {
+	credsSecret, err := clientset.CoreV1().Secrets(veleroNamespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil && !kuberneteserrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed to read secret")
 	}
@@ -594,7 +628,7 @@ func ensureCloudCredentialsSecret(ctx context.Context, clientset kubernetes.Inte
 				Kind:       "Secret",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      CloudCredentialsSecretName,
+				Name:      secretName,
 				Namespace: veleroNamespace,
 			},
 			Data: map[string][]byte{
@@ -886,13 +920,44 @@ func GetGlobalStore(ctx context.Context, kotsadmNamespace string, bsl *velerov1.
 		return nil, nil
 	}
 
+	store, err := getStoreFromBackupStorageLocation(ctx, clientset, bsl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get store from backup storage location")
+	}
+
+	secondaryBSLs, err := ListSecondaryBackupStorageLocations(ctx, bsl.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list secondary backup storage locations")
+	}
+
+	for _, secondaryBSL := range secondaryBSLs {
+		if secondaryBSL.Spec.ObjectStorage == nil {
+			continue
+		}
+		secondary, err := getStoreFromBackupStorageLocation(ctx, clientset, &secondaryBSL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get secondary store %s", secondaryBSL.Name)
+		}
+		secondary.ReplicationPolicy = GetReplicationPolicy(&secondaryBSL)
+		store.Secondaries = append(store.Secondaries, secondary)
+	}
+
+	return store, nil
+}
+
+// getStoreFromBackupStorageLocation maps a single backup storage location and its credentials to a store
+func getStoreFromBackupStorageLocation(ctx context.Context, clientset kubernetes.Interface, bsl *velerov1.BackupStorageLocation) (*types.Store, error) {
 	store := types.Store{
+		Name:       bsl.Name,
 		Provider:   bsl.Spec.Provider,
 		Bucket:     bsl.Spec.ObjectStorage.Bucket,
 		Path:       bsl.Spec.ObjectStorage.Prefix,
 		CACertData: bsl.Spec.ObjectStorage.CACert,
+		Status:     getStoreStatus(bsl),
 	}
 
+	credentialsSecretName, credentialsKey := getCredentialsSecretReference(bsl)
+
 	switch store.Provider {
 	case "aws":
 		err := mapAWSBackupStorageLocationToStore(bsl, &store)
@@ -900,13 +965,13 @@ func GetGlobalStore(ctx context.Context, kotsadmNamespace string, bsl *velerov1.
 			return nil, errors.Wrap(err, "failed to map aws backup storage location to store")
 		}
 
-		awsSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, "cloud-credentials", metav1.GetOptions{})
+		awsSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, credentialsSecretName, metav1.GetOptions{})
 		if err != nil && !kuberneteserrors.IsNotFound(err) {
 			return nil, errors.Wrap(err, "failed to read aws secret")
 		}
 
 		if err == nil {
-			awsCfg, err := ini.Load(awsSecret.Data["cloud"])
+			awsCfg, err := ini.Load(awsSecret.Data[credentialsKey])
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to load aws credentials")
 			}
@@ -942,13 +1007,13 @@ func GetGlobalStore(ctx context.Context, kotsadmNamespace string, bsl *velerov1.
 		}
 
 		// get the secret
-		azureSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, "cloud-credentials", metav1.GetOptions{})
+		azureSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, credentialsSecretName, metav1.GetOptions{})
 		if err != nil && !kuberneteserrors.IsNotFound(err) {
 			return nil, errors.Wrap(err, "failed to read azure secret")
 		}
 
 		if err == nil {
-			azureConfig := providers.ParseAzureConfig(azureSecret.Data["cloud"])
+			azureConfig := providers.ParseAzureConfig(azureSecret.Data[credentialsKey])
 			store.Azure.TenantID = azureConfig.TenantID
 			store.Azure.ClientID = azureConfig.ClientID
 			store.Azure.ClientSecret = azureConfig.ClientSecret
@@ -960,14 +1025,14 @@ func GetGlobalStore(ctx context.Context, kotsadmNamespace string, bsl *velerov1.
 		}
 
 	case "gcp":
-		currentSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, "cloud-credentials", metav1.GetOptions{})
+		currentSecret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, credentialsSecretName, metav1.GetOptions{})
 		if err != nil && !kuberneteserrors.IsNotFound(err) {
 			return nil, errors.Wrap(err, "failed to read google secret")
 		}
 
 		jsonFile := ""
 		if err == nil {
-			currentJSONFile, ok := currentSecret.Data["cloud"]
+			currentJSONFile, ok := currentSecret.Data[credentialsKey]
 			if ok {
 				jsonFile = string(currentJSONFile)
 			}
@@ -1609,6 +1674,12 @@ func Redact(store *types.Store) error {
 		}
 	}
 
+	for _, secondary := range store.Secondaries {
+		if err := Redact(secondary); err != nil {
+			return errors.Wrapf(err, "failed to redact secondary store %s", secondary.Name)
+		}
+	}
+
 	return nil
 }
 
@@ -65,8 +65,32 @@ type Store struct {
 	Other      *StoreOther      `json:"other,omitempty"`
 	Internal   *StoreInternal   `json:"internal,omitempty"`
 	FileSystem *StoreFileSystem `json:"fileSystem,omitempty"`
+
+	// Name is the name of the velero backup storage location
+	Name   string       `json:"name,omitempty"`
+	Status *StoreStatus `json:"status,omitempty"`
+	// ReplicationPolicy is only set for secondary stores
+	ReplicationPolicy ReplicationPolicy `json:"replicationPolicy,omitempty"`
+	// Secondaries are only set for the primary store
+	Secondaries []*Store `json:"secondaries,omitempty"`
+}
+
+// StoreStatus is the validation status of the backup storage location as reported by velero
+type StoreStatus struct {
+	Phase              string     `json:"phase"`
+	Message            string     `json:"message,omitempty"`
+	LastValidationTime *time.Time `json:"lastValidationTime,omitempty"`
 }
 
+// ReplicationPolicy determines which completed backups are copied to a secondary store
+type ReplicationPolicy string
+
+const (
+	ReplicationPolicyAll       ReplicationPolicy = "all"
+	ReplicationPolicyScheduled ReplicationPolicy = "scheduled"
+	ReplicationPolicyNone      ReplicationPolicy = "none"
+)
+
 type FileSystemConfig struct {
 	NFS      *NFSConfig `json:"nfs,omitempty"`
 	HostPath *string    `json:"hostPath,omitempty"`
@@ -23,6 +23,9 @@ func Start() error {
 
 	startLoop(appScheduleLoop, 60)
 	startLoop(instanceScheduleLoop, 60)
+	startLoop(restoreDrillScheduleLoop, 60)
+	startLoop(replicationLoop, 60)
+	startLoop(retentionLoop, 60)
 
 	return nil
 }
@@ -67,6 +70,64 @@ func instanceScheduleLoop() {
 	}
 }
 
+func restoreDrillScheduleLoop() {
+	if err := snapshot.CleanupStaleRestoreDrills(context.Background(), util.PodNamespace); err != nil {
+		logger.Error(errors.Wrap(err, "failed to clean up stale restore drills"))
+	}
+
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters for scheduled restore drills"))
+		return
+	}
+
+	for _, c := range clusters {
+		if err := handleClusterRestoreDrill(c); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to handle scheduled restore drills for cluster %s", c.ClusterID))
+		}
+	}
+}
+
+func replicationLoop() {
+	if err := snapshot.ReplicateInstanceBackups(context.Background(), util.PodNamespace); err != nil {
+		logger.Error(errors.Wrap(err, "failed to replicate instance backups to secondary locations"))
+	}
+}
+
+func retentionLoop() {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters for snapshot retention"))
+		return
+	}
+
+	for _, c := range clusters {
+		if err := handleClusterRetention(c); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to prune scheduled snapshots for cluster %s", c.ClusterID))
+		}
+	}
+}
+
+func handleClusterRetention(c *downstreamtypes.Downstream) error {
+	retention, err := snapshot.ParseRetentionPolicy(c.SnapshotRetention)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse retention policy")
+	}
+	if retention == nil || retention.IsEmpty() {
+		return nil
+	}
+
+	pruned, err := snapshot.PruneInstanceBackups(context.Background(), util.PodNamespace, *retention)
+	for _, backupName := range pruned {
+		logger.Infof("Pruned scheduled snapshot %s by retention policy", backupName)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to prune instance backups")
+	}
+
+	return nil
+}
+
 /* App Level Scheduled Snapshots */
 func handleApp(a *apptypes.App) error {
 	if a.SnapshotSchedule == "" {
@@ -233,6 +294,89 @@ func handleCluster(c *downstreamtypes.Downstream) error {
 	return nil
 }
 
+func handleClusterRestoreDrill(c *downstreamtypes.Downstream) error {
+	if c.RestoreDrillSchedule == "" {
+		return nil
+	}
+
+	/*
+	* This queue works the same as the scheduled instance snapshots queue above, using the scheduled_restore_drills
+	* table. When a drill is due, the latest completed instance backup is drilled, unless another drill is running.
+	 */
+
+	pending, err := store.GetStore().ListPendingScheduledRestoreDrills(c.ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pending scheduled restore drills")
+	}
+
+	if len(pending) == 0 {
+		logger.Infof("No pending restore drills scheduled for cluster %s with schedule %s. Queueing one.", c.ClusterID, c.RestoreDrillSchedule)
+		queued, err := nextScheduledRestoreDrill(c.ClusterID, c.RestoreDrillSchedule)
+		if err != nil {
+			return errors.Wrap(err, "failed to get next schedule")
+		}
+		if err := store.GetStore().CreateScheduledRestoreDrill(queued.ID, queued.ClusterID, queued.ScheduledTimestamp); err != nil {
+			return errors.Wrap(err, "failed to create scheduled restore drill")
+		}
+		return nil
+	}
+
+	next := pending[0]
+	if next.ScheduledTimestamp.After(time.Now()) {
+		logger.Debugf("Not yet time to run restore drill for cluster %s", c.ClusterID)
+		return nil
+	}
+
+	hasRunning, err := snapshot.HasRunningRestoreDrill(context.Background(), util.PodNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if cluster has running restore drills")
+	}
+	if hasRunning {
+		logger.Infof("Postponing scheduled restore drill for cluster %s because one is running", c.ClusterID)
+		return nil
+	}
+
+	backupName, err := snapshot.GetLatestCompletedInstanceBackupName(context.Background(), util.PodNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest completed instance backup")
+	}
+
+	if backupName == "" {
+		logger.Infof("Skipping scheduled restore drill %s because there are no completed instance backups", next.ID)
+		if err := store.GetStore().DeletePendingScheduledRestoreDrills(c.ClusterID); err != nil {
+			return errors.Wrap(err, "failed to delete pending scheduled restore drills")
+		}
+	} else {
+		if err := snapshot.StartRestoreDrill(context.Background(), util.PodNamespace, backupName); err != nil {
+			return errors.Wrap(err, "failed to start restore drill")
+		}
+
+		if err := store.GetStore().UpdateScheduledRestoreDrill(next.ID, backupName); err != nil {
+			return errors.Wrap(err, "failed to update scheduled restore drill")
+		}
+		logger.Infof("Started restore drill of backup %s from scheduled restore drill %s", backupName, next.ID)
+
+		if len(pending) > 1 {
+			err := store.GetStore().DeletePendingScheduledRestoreDrills(c.ClusterID)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete pending scheduled restore drills")
+			}
+		}
+	}
+
+	queued, err := nextScheduledRestoreDrill(c.ClusterID, c.RestoreDrillSchedule)
+	if err != nil {
+		return errors.Wrap(err, "failed to get next schedule")
+	}
+
+	if err := store.GetStore().CreateScheduledRestoreDrill(queued.ID, queued.ClusterID, queued.ScheduledTimestamp); err != nil {
+		return errors.Wrap(err, "failed to create scheduled restore drill")
+	}
+	logger.Infof("Scheduled next restore drill %s", queued.ID)
+
+	return nil
+}
+
 func nextScheduledApplicationSnapshot(appID string, cronExpression string) (*snapshottypes.ScheduledSnapshot, error) {
 	cronSchedule, err := cron.ParseStandard(cronExpression)
 	if err != nil {
@@ -262,3 +406,18 @@ func nextScheduledInstanceSnapshot(clusterID string, cronExpression string) (*sn
 
 	return scheduledSnapshot, nil
 }
+
+func nextScheduledRestoreDrill(clusterID string, cronExpression string) (*snapshottypes.ScheduledRestoreDrill, error) {
+	cronSchedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cron expression")
+	}
+
+	scheduledDrill := &snapshottypes.ScheduledRestoreDrill{
+		ClusterID:          clusterID,
+		ID:                 strings.ToLower(rand.String(32)),
+		ScheduledTimestamp: cronSchedule.Next(time.Now()),
+	}
+
+	return scheduledDrill, nil
+}
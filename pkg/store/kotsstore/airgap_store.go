@@ -7,7 +7,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/airgap/types"
 	airgaptypes "github.com/replicatedhq/kots/pkg/airgap/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetPendingAirgapUploadApp() (*airgaptypes.PendingApp, error) {
@@ -28,7 +27,7 @@ func (s *KOTSStore) GetPendingAirgapUploadApp() (*airgaptypes.PendingApp, error)
 	}
 
 	query = `select id, slug, name, license from app where id = ?`
-	rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err = db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id},
 	})
@@ -50,7 +49,7 @@ func (s *KOTSStore) GetPendingAirgapUploadApp() (*airgaptypes.PendingApp, error)
 func (s *KOTSStore) GetAirgapInstallStatus(appID string) (*airgaptypes.InstallStatus, error) {
 	db := persistence.MustGetDBSession()
 	query := `SELECT slug, install_state FROM app WHERE id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -66,7 +65,7 @@ func (s *KOTSStore) GetAirgapInstallStatus(appID string) (*airgaptypes.InstallSt
 	}
 
 	var slug string
-	var installState gorqlite.NullString
+	var installState persistence.NullString
 	if err := rows.Scan(&slug, &installState); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
@@ -88,7 +87,7 @@ func (s *KOTSStore) ResetAirgapInstallInProgress(appID string) error {
 	db := persistence.MustGetDBSession()
 
 	query := `update app set install_state = 'airgap_upload_in_progress' where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -103,7 +102,7 @@ func (s *KOTSStore) SetAppIsAirgap(appID string, isAirgap bool) error {
 	db := persistence.MustGetDBSession()
 
 	query := `update app set is_airgap = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{isAirgap, appID},
 	})
@@ -0,0 +1,146 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	apitokentypes "github.com/replicatedhq/kots/pkg/apitoken/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+)
+
+func (s *KOTSStore) CreateAPIToken(token *apitokentypes.APIToken, tokenHash string) error {
+	db := persistence.MustGetDBSession()
+
+	roles, err := json.Marshal(token.Roles)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal roles")
+	}
+
+	var expiresAt interface{}
+	if token.ExpiresAt != nil {
+		expiresAt = token.ExpiresAt.UnixMilli()
+	}
+
+	query := `insert into api_token (id, name, token_hash, roles, created_at, expires_at) values (?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{token.ID, token.Name, tokenHash, string(roles), token.CreatedAt.UnixMilli(), expiresAt},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// GetAPIToken returns the token with the given id and the hash of its secret
+func (s *KOTSStore) GetAPIToken(id string) (*apitokentypes.APIToken, string, error) {
+	db := persistence.MustGetDBSession()
+
+	query := `select id, name, token_hash, roles, created_at, expires_at, last_used_at, revoked_at from api_token where id = ?`
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{id},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, "", ErrNotFound
+	}
+
+	token, tokenHash, err := apiTokenFromRow(&rows)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to read api token")
+	}
+
+	return token, tokenHash, nil
+}
+
+// ListAPITokens returns all tokens, including the expired and revoked ones, newest first
+func (s *KOTSStore) ListAPITokens() ([]apitokentypes.APIToken, error) {
+	db := persistence.MustGetDBSession()
+
+	query := `select id, name, token_hash, roles, created_at, expires_at, last_used_at, revoked_at from api_token order by created_at desc`
+	rows, err := db.QueryOne(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	tokens := []apitokentypes.APIToken{}
+	for rows.Next() {
+		token, _, err := apiTokenFromRow(&rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read api token")
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, nil
+}
+
+func (s *KOTSStore) RevokeAPIToken(id string) error {
+	db := persistence.MustGetDBSession()
+
+	query := `update api_token set revoked_at = ? where id = ? and revoked_at is null`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{time.Now().UnixMilli(), id},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) UpdateAPITokenLastUsedAt(id string, lastUsedAt time.Time) error {
+	db := persistence.MustGetDBSession()
+
+	query := `update api_token set last_used_at = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{lastUsedAt.UnixMilli(), id},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func apiTokenFromRow(rows *persistence.QueryResult) (*apitokentypes.APIToken, string, error) {
+	token := apitokentypes.APIToken{}
+
+	var tokenHash string
+	var createdAt int64
+	var roles persistence.NullString
+	var expiresAt, lastUsedAt, revokedAt persistence.NullInt64
+	if err := rows.Scan(&token.ID, &token.Name, &tokenHash, &roles, &createdAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, "", errors.Wrap(err, "failed to scan")
+	}
+
+	token.CreatedAt = time.UnixMilli(createdAt).UTC()
+	token.ExpiresAt = timeFromNullMillis(expiresAt)
+	token.LastUsedAt = timeFromNullMillis(lastUsedAt)
+	token.RevokedAt = timeFromNullMillis(revokedAt)
+
+	token.Roles = []string{}
+	if roles.Valid && roles.String != "" {
+		if err := json.Unmarshal([]byte(roles.String), &token.Roles); err != nil {
+			return nil, "", errors.Wrap(err, "failed to unmarshal roles")
+		}
+	}
+
+	return &token, tokenHash, nil
+}
+
+func timeFromNullMillis(v persistence.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.UnixMilli(v.Int64).UTC()
+	return &t
+}
@@ -1,6 +1,7 @@
 package kotsstore
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -14,7 +15,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	troubleshootanalyze "github.com/replicatedhq/troubleshoot/pkg/analyze"
-	"github.com/rqlite/gorqlite"
 	"github.com/segmentio/ksuid"
 	"go.uber.org/zap"
 )
@@ -28,7 +28,7 @@ func (s *KOTSStore) AddAppToAllDownstreams(appID string) error {
 	}
 	for _, cluster := range clusters {
 		query := `insert into app_downstream (app_id, cluster_id, downstream_name) values (?, ?, ?) ON CONFLICT DO NOTHING`
-		wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{appID, cluster.ClusterID, cluster.Name},
 		})
@@ -44,7 +44,7 @@ func (s *KOTSStore) SetAppInstallState(appID string, state string) error {
 	db := persistence.MustGetDBSession()
 
 	query := `update app set install_state = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{state, appID},
 	})
@@ -125,7 +125,7 @@ func (s *KOTSStore) ListInstalledAppSlugs() ([]string, error) {
 func (s *KOTSStore) GetAppIDFromSlug(slug string) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select id from app where slug = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{slug},
 	})
@@ -146,8 +146,8 @@ func (s *KOTSStore) GetAppIDFromSlug(slug string) (string, error) {
 
 func (s *KOTSStore) GetApp(id string) (*apptypes.App, error) {
 	db := persistence.MustGetDBSession()
-	query := `select id, name, license, upstream_uri, icon_uri, created_at, updated_at, slug, current_sequence, last_update_check_at, last_license_sync, is_airgap, snapshot_ttl_new, snapshot_schedule, restore_in_progress_name, restore_undeploy_status, update_checker_spec, semver_auto_deploy, install_state, channel_changed from app where id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	query := `select id, name, license, upstream_uri, icon_uri, created_at, updated_at, slug, current_sequence, last_update_check_at, last_license_sync, is_airgap, snapshot_ttl_new, snapshot_schedule, restore_in_progress_name, restore_undeploy_status, update_checker_spec, semver_auto_deploy, auto_deploy_window, auto_rollback_policy, deploy_health_monitor, support_bundle_schedule, install_state, channel_changed from app where id = ?`
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id},
 	})
@@ -160,21 +160,25 @@ func (s *KOTSStore) GetApp(id string) (*apptypes.App, error) {
 
 	app := apptypes.App{}
 
-	var licenseStr gorqlite.NullString
-	var upstreamURI gorqlite.NullString
-	var iconURI gorqlite.NullString
-	var updatedAt gorqlite.NullTime
-	var currentSequence gorqlite.NullInt64
-	var lastUpdateCheckAt gorqlite.NullTime
-	var lastLicenseSync gorqlite.NullTime
-	var snapshotTTLNew gorqlite.NullString
-	var snapshotSchedule gorqlite.NullString
-	var restoreInProgressName gorqlite.NullString
-	var restoreUndeployStatus gorqlite.NullString
-	var updateCheckerSpec gorqlite.NullString
-	var autoDeploy gorqlite.NullString
-
-	if err := rows.Scan(&app.ID, &app.Name, &licenseStr, &upstreamURI, &iconURI, &app.CreatedAt, &updatedAt, &app.Slug, &currentSequence, &lastUpdateCheckAt, &lastLicenseSync, &app.IsAirgap, &snapshotTTLNew, &snapshotSchedule, &restoreInProgressName, &restoreUndeployStatus, &updateCheckerSpec, &autoDeploy, &app.InstallState, &app.ChannelChanged); err != nil {
+	var licenseStr persistence.NullString
+	var upstreamURI persistence.NullString
+	var iconURI persistence.NullString
+	var updatedAt persistence.NullTime
+	var currentSequence persistence.NullInt64
+	var lastUpdateCheckAt persistence.NullTime
+	var lastLicenseSync persistence.NullTime
+	var snapshotTTLNew persistence.NullString
+	var snapshotSchedule persistence.NullString
+	var restoreInProgressName persistence.NullString
+	var restoreUndeployStatus persistence.NullString
+	var updateCheckerSpec persistence.NullString
+	var autoDeploy persistence.NullString
+	var autoDeployWindow persistence.NullString
+	var autoRollbackPolicy persistence.NullString
+	var deployHealthMonitor persistence.NullString
+	var supportBundleSchedule persistence.NullString
+
+	if err := rows.Scan(&app.ID, &app.Name, &licenseStr, &upstreamURI, &iconURI, &app.CreatedAt, &updatedAt, &app.Slug, &currentSequence, &lastUpdateCheckAt, &lastLicenseSync, &app.IsAirgap, &snapshotTTLNew, &snapshotSchedule, &restoreInProgressName, &restoreUndeployStatus, &updateCheckerSpec, &autoDeploy, &autoDeployWindow, &autoRollbackPolicy, &deployHealthMonitor, &supportBundleSchedule, &app.InstallState, &app.ChannelChanged); err != nil {
 		return nil, errors.Wrap(err, "failed to scan app")
 	}
 
@@ -188,6 +192,38 @@ func (s *KOTSStore) GetApp(id string) (*apptypes.App, error) {
 	app.UpdateCheckerSpec = updateCheckerSpec.String
 	app.AutoDeploy = apptypes.AutoDeploy(autoDeploy.String)
 
+	if autoDeployWindow.String != "" {
+		window := apptypes.MaintenanceWindow{}
+		if err := json.Unmarshal([]byte(autoDeployWindow.String), &window); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal auto deploy window")
+		}
+		app.AutoDeployWindow = &window
+	}
+
+	if autoRollbackPolicy.String != "" {
+		policy := apptypes.AutoRollbackPolicy{}
+		if err := json.Unmarshal([]byte(autoRollbackPolicy.String), &policy); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal auto rollback policy")
+		}
+		app.AutoRollbackPolicy = &policy
+	}
+
+	if deployHealthMonitor.String != "" {
+		monitor := apptypes.DeployHealthMonitor{}
+		if err := json.Unmarshal([]byte(deployHealthMonitor.String), &monitor); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal deploy health monitor")
+		}
+		app.DeployHealthMonitor = &monitor
+	}
+
+	if supportBundleSchedule.String != "" {
+		schedule := apptypes.SupportBundleSchedule{}
+		if err := json.Unmarshal([]byte(supportBundleSchedule.String), &schedule); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal support bundle schedule")
+		}
+		app.SupportBundleSchedule = &schedule
+	}
+
 	if lastLicenseSync.Valid {
 		app.LastLicenseSync = lastLicenseSync.Time.Format(time.RFC3339)
 	}
@@ -213,7 +249,7 @@ func (s *KOTSStore) GetApp(id string) (*apptypes.App, error) {
 		}
 
 		query = `select preflight_spec, config_spec from app_version where app_id = ? AND sequence = ?`
-		rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{id, latestSequence},
 		})
@@ -224,8 +260,8 @@ func (s *KOTSStore) GetApp(id string) (*apptypes.App, error) {
 			return nil, ErrNotFound
 		}
 
-		var preflightSpec gorqlite.NullString
-		var configSpec gorqlite.NullString
+		var preflightSpec persistence.NullString
+		var configSpec persistence.NullString
 
 		if err := rows.Scan(&preflightSpec, &configSpec); err != nil {
 			return nil, errors.Wrap(err, "failed to scan app_version")
@@ -297,7 +333,7 @@ func (s *KOTSStore) CreateApp(name string, upstreamURI string, licenseData strin
 		}
 
 		query := `select count(1) as count from app where slug = ?`
-		rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{slugProposal},
 		})
@@ -338,7 +374,7 @@ func (s *KOTSStore) CreateApp(name string, upstreamURI string, licenseData strin
 	id := ksuid.New().String()
 
 	query := `insert into app (id, name, icon_uri, created_at, slug, upstream_uri, license, is_all_users, install_state, registry_is_readonly) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id, name, "", time.Now().Unix(), slugProposal, upstreamURI, licenseData, true, installState, registryIsReadOnly},
 	})
@@ -352,7 +388,7 @@ func (s *KOTSStore) CreateApp(name string, upstreamURI string, licenseData strin
 func (s *KOTSStore) ListDownstreamsForApp(appID string) ([]downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
 	query := `select c.id from app_downstream d inner join cluster c on d.cluster_id = c.id where app_id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -381,7 +417,7 @@ func (s *KOTSStore) ListDownstreamsForApp(appID string) ([]downstreamtypes.Downs
 func (s *KOTSStore) ListAppsForDownstream(clusterID string) ([]*apptypes.App, error) {
 	db := persistence.MustGetDBSession()
 	query := `select ad.app_id from app_downstream ad inner join app a on ad.app_id = a.id where ad.cluster_id = ? and a.install_state = 'installed'`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{clusterID},
 	})
@@ -408,7 +444,7 @@ func (s *KOTSStore) ListAppsForDownstream(clusterID string) ([]*apptypes.App, er
 func (s *KOTSStore) GetDownstream(clusterID string) (*downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
 	query := `select c.id, c.slug, d.downstream_name, d.current_sequence from app_downstream d inner join cluster c on d.cluster_id = c.id where c.id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{clusterID},
 	})
@@ -422,7 +458,7 @@ func (s *KOTSStore) GetDownstream(clusterID string) (*downstreamtypes.Downstream
 	downstream := downstreamtypes.Downstream{
 		CurrentSequence: -1,
 	}
-	var sequence gorqlite.NullInt64
+	var sequence persistence.NullInt64
 	if err := rows.Scan(&downstream.ClusterID, &downstream.ClusterSlug, &downstream.Name, &sequence); err != nil {
 		return nil, errors.Wrap(err, "failed to scan downstream")
 	}
@@ -458,7 +494,7 @@ func (s *KOTSStore) SetUpdateCheckerSpec(appID string, updateCheckerSpec string)
 
 	db := persistence.MustGetDBSession()
 	query := `update app set update_checker_spec = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{updateCheckerSpec, appID},
 	})
@@ -475,7 +511,7 @@ func (s *KOTSStore) SetAutoDeploy(appID string, autoDeploy apptypes.AutoDeploy)
 
 	db := persistence.MustGetDBSession()
 	query := `update app set semver_auto_deploy = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{autoDeploy, appID},
 	})
@@ -486,13 +522,121 @@ func (s *KOTSStore) SetAutoDeploy(appID string, autoDeploy apptypes.AutoDeploy)
 	return nil
 }
 
+func (s *KOTSStore) SetAutoDeployWindow(appID string, window *apptypes.MaintenanceWindow) error {
+	logger.Debug("setting auto deploy window",
+		zap.String("appID", appID))
+
+	// an empty value means there is no window and versions are deployed as soon as they are available
+	marshalledWindow := ""
+	if window != nil {
+		b, err := json.Marshal(window)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal auto deploy window")
+		}
+		marshalledWindow = string(b)
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `update app set auto_deploy_window = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{marshalledWindow, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) SetAutoRollbackPolicy(appID string, policy *apptypes.AutoRollbackPolicy) error {
+	logger.Debug("setting auto rollback policy",
+		zap.String("appID", appID))
+
+	// an empty value means deploys are never rolled back automatically
+	marshalledPolicy := ""
+	if policy != nil {
+		b, err := json.Marshal(policy)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal auto rollback policy")
+		}
+		marshalledPolicy = string(b)
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `update app set auto_rollback_policy = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{marshalledPolicy, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) SetSupportBundleSchedule(appID string, schedule *apptypes.SupportBundleSchedule) error {
+	logger.Debug("setting support bundle schedule",
+		zap.String("appID", appID))
+
+	// an empty value means support bundles are only collected on demand
+	marshalledSchedule := ""
+	if schedule != nil {
+		b, err := json.Marshal(schedule)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal support bundle schedule")
+		}
+		marshalledSchedule = string(b)
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `update app set support_bundle_schedule = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{marshalledSchedule, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) SetDeployHealthMonitor(appID string, monitor *apptypes.DeployHealthMonitor) error {
+	logger.Debug("setting deploy health monitor",
+		zap.String("appID", appID))
+
+	// an empty value means no deploy is being monitored
+	marshalledMonitor := ""
+	if monitor != nil {
+		b, err := json.Marshal(monitor)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal deploy health monitor")
+		}
+		marshalledMonitor = string(b)
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `update app set deploy_health_monitor = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{marshalledMonitor, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
 func (s *KOTSStore) SetSnapshotTTL(appID string, snapshotTTL string) error {
 	logger.Debug("Setting snapshot TTL",
 		zap.String("appID", appID))
 
 	db := persistence.MustGetDBSession()
 	query := `update app set snapshot_ttl_new = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{snapshotTTL, appID},
 	})
@@ -509,7 +653,7 @@ func (s *KOTSStore) SetSnapshotSchedule(appID string, snapshotSchedule string) e
 
 	db := persistence.MustGetDBSession()
 	query := `update app set snapshot_schedule = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{snapshotSchedule, appID},
 	})
@@ -525,46 +669,51 @@ func (s *KOTSStore) RemoveApp(appID string) error {
 		zap.String("appID", appID))
 
 	db := persistence.MustGetDBSession()
-	statements := []gorqlite.ParameterizedStatement{}
+	statements := []persistence.ParameterizedStatement{}
 
 	// TODO: api_task_status needs app ID
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app_status where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app_downstream_output where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app_downstream_version where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app_downstream where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app_version where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from user_app where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from pending_supportbundle where app_id = ?",
 		Arguments: []interface{}{appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
+		Query:     "delete from preflight_result_history where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "delete from app where id = ?",
 		Arguments: []interface{}{appID},
 	})
@@ -584,7 +733,7 @@ func (s *KOTSStore) SetAppChannelChanged(appID string, channelChanged bool) erro
 	db := persistence.MustGetDBSession()
 
 	query := `update app set channel_changed = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{channelChanged, appID},
 	})
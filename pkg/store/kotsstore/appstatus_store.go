@@ -8,13 +8,12 @@ import (
 	"github.com/pkg/errors"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetAppStatus(appID string) (*appstatetypes.AppStatus, error) {
 	db := persistence.MustGetDBSession()
 	query := `select resource_states, updated_at, sequence from app_status where app_id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -32,9 +31,9 @@ func (s *KOTSStore) GetAppStatus(appID string) (*appstatetypes.AppStatus, error)
 		}, nil
 	}
 
-	var updatedAt gorqlite.NullTime
-	var resourceStatesStr gorqlite.NullString
-	var sequence gorqlite.NullInt64
+	var updatedAt persistence.NullTime
+	var resourceStatesStr persistence.NullString
+	var sequence persistence.NullInt64
 
 	if err := rows.Scan(&resourceStatesStr, &updatedAt, &sequence); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
@@ -76,7 +75,7 @@ func (s *KOTSStore) SetAppStatus(appID string, resourceStates appstatetypes.Reso
 	  resource_states = EXCLUDED.resource_states,
 	  updated_at = EXCLUDED.updated_at,
 	  sequence = EXCLUDED.sequence`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, string(marshalledResourceStates), updatedAt.Unix(), sequence},
 	})
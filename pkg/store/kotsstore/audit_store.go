@@ -0,0 +1,134 @@
+package kotsstore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/segmentio/ksuid"
+)
+
+func (s *KOTSStore) CreateAuditEvent(event *audittypes.Event) error {
+	db := persistence.MustGetDBSession()
+
+	if event.ID == "" {
+		event.ID = ksuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	var sequence interface{}
+	if event.Sequence != nil {
+		sequence = *event.Sequence
+	}
+
+	query := `insert into audit_log (id, created_at, actor, actor_type, action, resource, app_slug, sequence, outcome, detail) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query: query,
+		Arguments: []interface{}{
+			event.ID,
+			event.CreatedAt.UnixMilli(),
+			event.Actor,
+			event.ActorType,
+			event.Action,
+			event.Resource,
+			event.AppSlug,
+			sequence,
+			event.Outcome,
+			event.Detail,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// ListAuditEvents returns a page of audit events, newest first, and the total number of events that match the filters
+func (s *KOTSStore) ListAuditEvents(opts audittypes.ListOptions) ([]audittypes.Event, int64, error) {
+	db := persistence.MustGetDBSession()
+
+	conditions := []string{}
+	args := []interface{}{}
+	if opts.AppSlug != "" {
+		conditions = append(conditions, "app_slug = ?")
+		args = append(args, opts.AppSlug)
+	}
+	if opts.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, opts.Actor)
+	}
+	if opts.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, opts.Action)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " where " + strings.Join(conditions, " and ")
+	}
+
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
+		Query:     `select count(1) from audit_log` + where,
+		Arguments: args,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	var totalCount int64
+	if rows.Next() {
+		if err := rows.Scan(&totalCount); err != nil {
+			return nil, 0, errors.Wrap(err, "failed to scan count")
+		}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	page := opts.Page
+	if page < 0 {
+		page = 0
+	}
+
+	query := `select id, created_at, actor, actor_type, action, resource, app_slug, sequence, outcome, detail from audit_log` + where + ` order by created_at desc, id desc limit ? offset ?`
+	rows, err = db.QueryOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: append(args, pageSize, page*pageSize),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	events := []audittypes.Event{}
+	for rows.Next() {
+		var createdAt int64
+		var actor, actorType, resource, appSlug, outcome, detail persistence.NullString
+		var sequence persistence.NullInt64
+
+		event := audittypes.Event{}
+		if err := rows.Scan(&event.ID, &createdAt, &actor, &actorType, &event.Action, &resource, &appSlug, &sequence, &outcome, &detail); err != nil {
+			return nil, 0, errors.Wrap(err, "failed to scan audit event")
+		}
+
+		event.CreatedAt = time.UnixMilli(createdAt).UTC()
+		event.Actor = actor.String
+		event.ActorType = actorType.String
+		event.Resource = resource.String
+		event.AppSlug = appSlug.String
+		event.Outcome = outcome.String
+		event.Detail = detail.String
+		if sequence.Valid {
+			event.Sequence = &sequence.Int64
+		}
+
+		events = append(events, event)
+	}
+
+	return events, totalCount, nil
+}
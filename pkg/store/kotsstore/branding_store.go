@@ -7,7 +7,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 	"github.com/segmentio/ksuid"
 )
 
@@ -25,7 +24,7 @@ func (s *KOTSStore) GetInitialBranding() ([]byte, error) {
 		return nil, nil
 	}
 
-	var encodedBrandingArchive gorqlite.NullString
+	var encodedBrandingArchive persistence.NullString
 	if err := rows.Scan(&encodedBrandingArchive); err != nil {
 		return nil, errors.Wrap(err, "failed to scan branding")
 	}
@@ -50,7 +49,7 @@ func (s *KOTSStore) CreateInitialBranding(brandingArchive []byte) (string, error
 	encodedBrandingArchive := base64.StdEncoding.EncodeToString(brandingArchive)
 
 	query := `INSERT INTO initial_branding (id, contents, created_at) VALUES (?, ?, ?)`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id, encodedBrandingArchive, time.Now().Unix()},
 	})
@@ -90,7 +89,7 @@ LIMIT 1`
 		return s.GetInitialBranding()
 	}
 
-	var encodedBrandingArchive gorqlite.NullString
+	var encodedBrandingArchive persistence.NullString
 	if err := rows.Scan(&encodedBrandingArchive); err != nil {
 		return nil, errors.Wrap(err, "failed to scan latest deployed branding")
 	}
@@ -128,7 +127,7 @@ WHERE
 ORDER BY
 	adv.applied_at DESC
 LIMIT 1`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -141,7 +140,7 @@ LIMIT 1`
 		return s.GetInitialBranding()
 	}
 
-	var encodedBrandingArchive gorqlite.NullString
+	var encodedBrandingArchive persistence.NullString
 	if err := rows.Scan(&encodedBrandingArchive); err != nil {
 		return nil, errors.Wrapf(err, "failed to scan latest deployed branding for app %s", appID)
 	}
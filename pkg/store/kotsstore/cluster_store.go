@@ -10,14 +10,13 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/rand"
-	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 )
 
 func (s *KOTSStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
 
-	query := `select id, slug, title, snapshot_schedule, snapshot_ttl from cluster` // TODO the current sequence
+	query := `select id, slug, title, snapshot_schedule, snapshot_ttl, restore_drill_schedule, snapshot_storage_location, snapshot_retention from cluster` // TODO the current sequence
 	rows, err := db.QueryOne(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
@@ -27,15 +26,21 @@ func (s *KOTSStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 	for rows.Next() {
 		cluster := downstreamtypes.Downstream{}
 
-		var snapshotSchedule gorqlite.NullString
-		var snapshotTTL gorqlite.NullString
+		var snapshotSchedule persistence.NullString
+		var snapshotTTL persistence.NullString
+		var restoreDrillSchedule persistence.NullString
+		var snapshotStorageLocation persistence.NullString
+		var snapshotRetention persistence.NullString
 
-		if err := rows.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL); err != nil {
+		if err := rows.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL, &restoreDrillSchedule, &snapshotStorageLocation, &snapshotRetention); err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
 		}
 
 		cluster.SnapshotSchedule = snapshotSchedule.String
 		cluster.SnapshotTTL = snapshotTTL.String
+		cluster.RestoreDrillSchedule = restoreDrillSchedule.String
+		cluster.SnapshotStorageLocation = snapshotStorageLocation.String
+		cluster.SnapshotRetention = snapshotRetention.String
 
 		clusters = append(clusters, &cluster)
 	}
@@ -46,7 +51,7 @@ func (s *KOTSStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 func (s *KOTSStore) GetClusterIDFromSlug(slug string) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select id from cluster where slug = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{slug},
 	})
@@ -69,7 +74,7 @@ func (s *KOTSStore) GetClusterIDFromSlug(slug string) (string, error) {
 func (s *KOTSStore) GetClusterIDFromDeployToken(deployToken string) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select id from cluster where token = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{deployToken},
 	})
@@ -103,7 +108,7 @@ func (s *KOTSStore) CreateNewCluster(userID string, isAllUsers bool, title strin
 		}
 
 		query := `select count(1) as count from cluster where slug = ?`
-		rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{slugProposal},
 		})
@@ -130,14 +135,14 @@ func (s *KOTSStore) CreateNewCluster(userID string, isAllUsers bool, title strin
 		token = rand.StringWithCharset(32, rand.LOWER_CASE)
 	}
 
-	statements := []gorqlite.ParameterizedStatement{}
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements := []persistence.ParameterizedStatement{}
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     `insert into cluster (id, title, slug, created_at, cluster_type, is_all_users, token) values (?, ?, ?, ?, ?, ?, ?)`,
 		Arguments: []interface{}{clusterID, title, clusterSlug, time.Now().Unix(), "ship", isAllUsers, token},
 	})
 
 	if userID != "" {
-		statements = append(statements, gorqlite.ParameterizedStatement{
+		statements = append(statements, persistence.ParameterizedStatement{
 			Query:     `insert into user_cluster (user_id, cluster_id) values (?, ?)`,
 			Arguments: []interface{}{userID, clusterID},
 		})
@@ -160,7 +165,7 @@ func (s *KOTSStore) SetInstanceSnapshotTTL(clusterID string, snapshotTTL string)
 
 	db := persistence.MustGetDBSession()
 	query := `update cluster set snapshot_ttl = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{snapshotTTL, clusterID},
 	})
@@ -177,7 +182,7 @@ func (s *KOTSStore) SetInstanceSnapshotSchedule(clusterID string, snapshotSchedu
 
 	db := persistence.MustGetDBSession()
 	query := `update cluster set snapshot_schedule = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{snapshotSchedule, clusterID},
 	})
@@ -187,3 +192,54 @@ func (s *KOTSStore) SetInstanceSnapshotSchedule(clusterID string, snapshotSchedu
 
 	return nil
 }
+
+func (s *KOTSStore) SetInstanceRestoreDrillSchedule(clusterID string, restoreDrillSchedule string) error {
+	logger.Debug("Setting instance restore drill schedule",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `update cluster set restore_drill_schedule = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{restoreDrillSchedule, clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) SetInstanceSnapshotStorageLocation(clusterID string, storageLocation string) error {
+	logger.Debug("Setting instance snapshot storage location",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `update cluster set snapshot_storage_location = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{storageLocation, clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) SetInstanceSnapshotRetention(clusterID string, retention string) error {
+	logger.Debug("Setting instance snapshot retention",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `update cluster set snapshot_retention = ? where id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{retention, clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
@@ -15,13 +15,12 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/store/types"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetCurrentDownstreamSequence(appID string, clusterID string) (int64, error) {
 	db := persistence.MustGetDBSession()
 	query := `select current_sequence from app_downstream where app_id = ? and cluster_id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID},
 	})
@@ -32,7 +31,7 @@ func (s *KOTSStore) GetCurrentDownstreamSequence(appID string, clusterID string)
 		return -1, ErrNotFound
 	}
 
-	var currentSequence gorqlite.NullInt64
+	var currentSequence persistence.NullInt64
 	if err := rows.Scan(&currentSequence); err != nil {
 		return -1, errors.Wrap(err, "failed to scan")
 	}
@@ -55,7 +54,7 @@ func (s *KOTSStore) GetCurrentParentSequence(appID string, clusterID string) (in
 
 	db := persistence.MustGetDBSession()
 	query := `select parent_sequence from app_downstream_version where app_id = ? and cluster_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, currentSequence},
 	})
@@ -66,7 +65,7 @@ func (s *KOTSStore) GetCurrentParentSequence(appID string, clusterID string) (in
 		return -1, ErrNotFound
 	}
 
-	var parentSequence gorqlite.NullInt64
+	var parentSequence persistence.NullInt64
 	if err := rows.Scan(&parentSequence); err != nil {
 		return -1, errors.Wrap(err, "failed to scan")
 	}
@@ -81,7 +80,7 @@ func (s *KOTSStore) GetCurrentParentSequence(appID string, clusterID string) (in
 func (s *KOTSStore) GetParentSequenceForSequence(appID string, clusterID string, sequence int64) (int64, error) {
 	db := persistence.MustGetDBSession()
 	query := `select parent_sequence from app_downstream_version where app_id = ? and cluster_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence},
 	})
@@ -92,7 +91,7 @@ func (s *KOTSStore) GetParentSequenceForSequence(appID string, clusterID string,
 		return -1, ErrNotFound
 	}
 
-	var parentSequence gorqlite.NullInt64
+	var parentSequence persistence.NullInt64
 	if err := rows.Scan(&parentSequence); err != nil {
 		return -1, errors.Wrap(err, "failed to scan")
 	}
@@ -107,7 +106,7 @@ func (s *KOTSStore) GetParentSequenceForSequence(appID string, clusterID string,
 func (s *KOTSStore) GetPreviouslyDeployedSequence(appID string, clusterID string) (int64, error) {
 	db := persistence.MustGetDBSession()
 	query := `select sequence from app_downstream_version where app_id = ? and cluster_id = ? and applied_at is not null order by applied_at desc limit 2`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID},
 	})
@@ -131,14 +130,14 @@ func (s *KOTSStore) GetPreviouslyDeployedSequence(appID string, clusterID string
 
 func (s *KOTSStore) MarkAsCurrentDownstreamVersion(appID string, sequence int64) error {
 	db := persistence.MustGetDBSession()
-	statements := []gorqlite.ParameterizedStatement{}
+	statements := []persistence.ParameterizedStatement{}
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     `update app_downstream set current_sequence = ? where app_id = ?`,
 		Arguments: []interface{}{sequence, appID},
 	})
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     `update app_downstream_version set applied_at = ? where sequence = ? and app_id = ?`,
 		Arguments: []interface{}{time.Now().Unix(), sequence, appID},
 	})
@@ -158,7 +157,7 @@ func (s *KOTSStore) MarkAsCurrentDownstreamVersion(appID string, sequence int64)
 func (s *KOTSStore) SetDownstreamVersionStatus(appID string, sequence int64, status types.DownstreamVersionStatus, statusInfo string) error {
 	db := persistence.MustGetDBSession()
 	query := `update app_downstream_version set status = ?, status_info = ? where app_id = ? and sequence = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{status, statusInfo, appID, sequence},
 	})
@@ -173,7 +172,7 @@ func (s *KOTSStore) SetDownstreamVersionStatus(appID string, sequence int64, sta
 func (s *KOTSStore) GetDownstreamVersionStatus(appID string, sequence int64) (types.DownstreamVersionStatus, error) {
 	db := persistence.MustGetDBSession()
 	query := `select status from app_downstream_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -184,7 +183,7 @@ func (s *KOTSStore) GetDownstreamVersionStatus(appID string, sequence int64) (ty
 		return "", nil
 	}
 
-	var status gorqlite.NullString
+	var status persistence.NullString
 	if err := rows.Scan(&status); err != nil {
 		return "", errors.Wrap(err, "failed to get downstream version")
 	}
@@ -196,7 +195,7 @@ func (s *KOTSStore) GetDownstreamVersionStatus(appID string, sequence int64) (ty
 func (s *KOTSStore) GetDownstreamVersionSource(appID string, sequence int64) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select source from app_downstream_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -207,7 +206,7 @@ func (s *KOTSStore) GetDownstreamVersionSource(appID string, sequence int64) (st
 		return "", nil
 	}
 
-	var source gorqlite.NullString
+	var source persistence.NullString
 	if err := rows.Scan(&source); err != nil {
 		return "", errors.Wrap(err, "failed to get downstream version")
 	}
@@ -218,7 +217,7 @@ func (s *KOTSStore) GetDownstreamVersionSource(appID string, sequence int64) (st
 func (s *KOTSStore) GetIgnoreRBACErrors(appID string, sequence int64) (bool, error) {
 	db := persistence.MustGetDBSession()
 	query := `SELECT preflight_ignore_permissions FROM app_downstream_version WHERE app_id = ? and sequence = ? LIMIT 1`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -229,7 +228,7 @@ func (s *KOTSStore) GetIgnoreRBACErrors(appID string, sequence int64) (bool, err
 		return false, ErrNotFound
 	}
 
-	var shouldIgnore gorqlite.NullBool
+	var shouldIgnore persistence.NullBool
 	if err := rows.Scan(&shouldIgnore); err != nil {
 		return false, errors.Wrap(err, "failed to select downstream")
 	}
@@ -254,6 +253,7 @@ func (s *KOTSStore) GetCurrentDownstreamVersion(appID string, clusterID string)
 	query := `SELECT
 	adv.created_at,
 	adv.status,
+	adv.status_info,
 	adv.sequence,
 	adv.parent_sequence,
 	adv.applied_at,
@@ -281,7 +281,7 @@ func (s *KOTSStore) GetCurrentDownstreamVersion(appID string, clusterID string)
 	 adv.app_id = ? AND
 	 adv.cluster_id = ? AND
 	 adv.sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, currentSequence},
 	})
@@ -319,7 +319,7 @@ func (s *KOTSStore) GetStatusForVersion(appID string, clusterID string, sequence
 		adv.app_id = ado.app_id AND adv.cluster_id = ado.cluster_id AND adv.sequence = ado.downstream_sequence
  WHERE
  	adv.app_id = ? AND adv.cluster_id = ? AND adv.sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence},
 	})
@@ -330,8 +330,8 @@ func (s *KOTSStore) GetStatusForVersion(appID string, clusterID string, sequence
 		return "", ErrNotFound
 	}
 
-	var status gorqlite.NullString
-	var hasError gorqlite.NullBool
+	var status persistence.NullString
+	var hasError persistence.NullBool
 	if err := rows.Scan(&status, &hasError); err != nil {
 		return "", errors.Wrap(err, "failed to scan")
 	}
@@ -349,6 +349,7 @@ func (s *KOTSStore) GetDownstreamVersions(appID string, clusterID string, downlo
 	query := `SELECT
 	adv.created_at,
 	adv.status,
+	adv.status_info,
 	adv.sequence,
 	adv.parent_sequence,
 	adv.applied_at,
@@ -382,7 +383,7 @@ func (s *KOTSStore) GetDownstreamVersions(appID string, clusterID string, downlo
 
 	query += ` ORDER BY adv.sequence DESC`
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID},
 	})
@@ -555,7 +556,7 @@ func (s *KOTSStore) AddDownstreamVersionsDetails(appID string, clusterID string,
  ORDER BY
  	 adv.sequence DESC`, strings.Join(sequencesToQuery, ","))
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID},
 	})
@@ -565,14 +566,14 @@ func (s *KOTSStore) AddDownstreamVersionsDetails(appID string, clusterID string,
 
 	for rows.Next() {
 		var sequence int64
-		var diffSummary gorqlite.NullString
-		var diffSummaryError gorqlite.NullString
-		var preflightResult gorqlite.NullString
-		var preflightResultCreatedAt gorqlite.NullTime
-		var kotsInstallationSpecStr gorqlite.NullString
-		var kotsAppSpecStr gorqlite.NullString
-		var preflightSpecStr gorqlite.NullString
-		var configSpecStr gorqlite.NullString
+		var diffSummary persistence.NullString
+		var diffSummaryError persistence.NullString
+		var preflightResult persistence.NullString
+		var preflightResultCreatedAt persistence.NullTime
+		var kotsInstallationSpecStr persistence.NullString
+		var kotsAppSpecStr persistence.NullString
+		var preflightSpecStr persistence.NullString
+		var configSpecStr persistence.NullString
 
 		if err := rows.Scan(
 			&sequence,
@@ -752,26 +753,28 @@ func (s *KOTSStore) getLatestDeployableDownstreamVersion(appID string, clusterID
 	return
 }
 
-func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryResult) (*downstreamtypes.DownstreamVersion, error) {
+func (s *KOTSStore) downstreamVersionFromRow(appID string, row persistence.QueryResult) (*downstreamtypes.DownstreamVersion, error) {
 	v := &downstreamtypes.DownstreamVersion{}
 
-	var createdOn gorqlite.NullTime
-	var versionLabel gorqlite.NullString
-	var channelID gorqlite.NullString
-	var updateCursor gorqlite.NullString
-	var status gorqlite.NullString
-	var parentSequence gorqlite.NullInt64
-	var deployedAt gorqlite.NullTime
-	var source gorqlite.NullString
-	var preflightSkipped gorqlite.NullBool
-	var commitURL gorqlite.NullString
-	var gitDeployable gorqlite.NullBool
-	var hasError gorqlite.NullBool
-	var upstreamReleasedAt gorqlite.NullTime
+	var createdOn persistence.NullTime
+	var versionLabel persistence.NullString
+	var channelID persistence.NullString
+	var updateCursor persistence.NullString
+	var status persistence.NullString
+	var statusInfo persistence.NullString
+	var parentSequence persistence.NullInt64
+	var deployedAt persistence.NullTime
+	var source persistence.NullString
+	var preflightSkipped persistence.NullBool
+	var commitURL persistence.NullString
+	var gitDeployable persistence.NullBool
+	var hasError persistence.NullBool
+	var upstreamReleasedAt persistence.NullTime
 
 	if err := row.Scan(
 		&createdOn,
 		&status,
+		&statusInfo,
 		&v.Sequence,
 		&parentSequence,
 		&deployedAt,
@@ -807,6 +810,7 @@ func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryRes
 	v.ChannelID = channelID.String
 
 	v.Status = types.DownstreamVersionStatus(status.String)
+	v.StatusInfo = statusInfo.String
 	v.ParentSequence = parentSequence.Int64
 
 	if deployedAt.Valid {
@@ -1005,7 +1009,7 @@ ALL_VERSIONS_LOOP:
 func getReleaseNotes(appID string, parentSequence int64) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `SELECT release_notes FROM app_version WHERE app_id = ? AND sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, parentSequence},
 	})
@@ -1016,7 +1020,7 @@ func getReleaseNotes(appID string, parentSequence int64) (string, error) {
 		return "", nil
 	}
 
-	var releaseNotes gorqlite.NullString
+	var releaseNotes persistence.NullString
 	if err := rows.Scan(&releaseNotes); err != nil {
 		return "", errors.Wrap(err, "failed to scan")
 	}
@@ -1058,7 +1062,7 @@ WHERE
 	adv.cluster_id = ? AND
 	adv.sequence = ?`
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence},
 	})
@@ -1069,14 +1073,14 @@ WHERE
 		return &downstreamtypes.DownstreamOutput{}, nil
 	}
 
-	var status gorqlite.NullString
-	var statusInfo gorqlite.NullString
-	var dryrunStdout gorqlite.NullString
-	var dryrunStderr gorqlite.NullString
-	var applyStdout gorqlite.NullString
-	var applyStderr gorqlite.NullString
-	var helmStdout gorqlite.NullString
-	var helmStderr gorqlite.NullString
+	var status persistence.NullString
+	var statusInfo persistence.NullString
+	var dryrunStdout persistence.NullString
+	var dryrunStderr persistence.NullString
+	var applyStdout persistence.NullString
+	var applyStderr persistence.NullString
+	var helmStdout persistence.NullString
+	var helmStderr persistence.NullString
 
 	if err := rows.Scan(&status, &statusInfo, &dryrunStdout, &dryrunStderr, &applyStdout, &applyStderr, &helmStdout, &helmStderr); err != nil {
 		return nil, errors.Wrap(err, "failed to select downstream")
@@ -1143,7 +1147,7 @@ func (s *KOTSStore) IsDownstreamDeploySuccessful(appID string, clusterID string,
 	FROM app_downstream_output
 	WHERE app_id = ? AND cluster_id = ? AND downstream_sequence = ?`
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence},
 	})
@@ -1170,7 +1174,7 @@ func (s *KOTSStore) UpdateDownstreamDeployStatus(appID string, clusterID string,
 	dryrun_stdout = EXCLUDED.dryrun_stdout, dryrun_stderr = EXCLUDED.dryrun_stderr, apply_stdout = EXCLUDED.apply_stdout, apply_stderr = EXCLUDED.apply_stderr,
 	helm_stdout = EXCLUDED.helm_stdout, helm_stderr = EXCLUDED.helm_stderr`
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence, isError, output.DryrunStdout, output.DryrunStderr, output.ApplyStdout, output.ApplyStderr, output.HelmStdout, output.HelmStderr},
 	})
@@ -1186,7 +1190,7 @@ func (s *KOTSStore) DeleteDownstreamDeployStatus(appID string, clusterID string,
 
 	query := `delete from app_downstream_output where app_id = ? and cluster_id = ? and downstream_sequence = ?`
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, clusterID, sequence},
 	})
@@ -5,13 +5,12 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetEmbeddedClusterAuthToken() (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select value from kotsadm_params where key = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"embedded.cluster.auth.token"},
 	})
@@ -34,7 +33,7 @@ func (s *KOTSStore) SetEmbeddedClusterAuthToken(token string) error {
 	db := persistence.MustGetDBSession()
 
 	query := `delete from kotsadm_params where key = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"embedded.cluster.auth.token"},
 	})
@@ -43,7 +42,7 @@ func (s *KOTSStore) SetEmbeddedClusterAuthToken(token string) error {
 	}
 
 	query = `insert into kotsadm_params (key, value) values (?, ?)`
-	wr, err = db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err = db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"embedded.cluster.auth.token", token},
 	})
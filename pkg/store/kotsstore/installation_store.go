@@ -6,7 +6,6 @@ import (
 	"github.com/pkg/errors"
 	installationtypes "github.com/replicatedhq/kots/pkg/online/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetPendingInstallationStatus() (*installationtypes.InstallStatus, error) {
@@ -24,7 +23,7 @@ func (s *KOTSStore) GetPendingInstallationStatus() (*installationtypes.InstallSt
 		}, nil
 	}
 
-	var installState gorqlite.NullString
+	var installState persistence.NullString
 	if err := rows.Scan(&installState); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
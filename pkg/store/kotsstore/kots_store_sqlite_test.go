@@ -0,0 +1,489 @@
+package kotsstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	apitokentypes "github.com/replicatedhq/kots/pkg/apitoken/types"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchemaDir = "../../../migrations/tables"
+
+// newSqliteTestStore returns a store backed by an in-process sqlite database with the
+// kotsadm schema applied.
+func newSqliteTestStore(t *testing.T) *KOTSStore {
+	dsn := filepath.Join(t.TempDir(), "kotsadm.db")
+
+	err := persistence.UpdateDBSchema(persistence.DriverSqlite, dsn, testSchemaDir)
+	require.NoError(t, err)
+
+	db, err := persistence.Open(persistence.DriverSqlite, dsn)
+	require.NoError(t, err)
+
+	persistence.SetDB(db)
+	t.Cleanup(func() {
+		persistence.SetDB(nil)
+	})
+
+	return &KOTSStore{}
+}
+
+func TestSqliteAppStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("My App", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", app.Slug)
+	assert.Equal(t, "online_upload_pending", app.InstallState)
+	assert.Equal(t, int64(-1), app.CurrentSequence)
+	assert.Equal(t, "720h", app.SnapshotTTL)
+
+	second, err := s.CreateApp("My App", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, "My App-1", second.Slug)
+
+	id, err := s.GetAppIDFromSlug("my-app")
+	require.NoError(t, err)
+	assert.Equal(t, app.ID, id)
+
+	_, err = s.GetAppIDFromSlug("missing")
+	assert.True(t, s.IsNotFound(err))
+
+	require.NoError(t, s.SetAppInstallState(app.ID, "installed"))
+	require.NoError(t, s.SetSnapshotSchedule(app.ID, "0 * * * *"))
+	require.NoError(t, s.SetAppChannelChanged(app.ID, true))
+
+	installed, err := s.ListInstalledApps()
+	require.NoError(t, err)
+	require.Len(t, installed, 1)
+	assert.Equal(t, app.ID, installed[0].ID)
+	assert.Equal(t, "0 * * * *", installed[0].SnapshotSchedule)
+	assert.True(t, installed[0].ChannelChanged)
+
+	failed, err := s.ListFailedApps()
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, second.ID, failed[0].ID)
+
+	slugs, err := s.ListInstalledAppSlugs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-app"}, slugs)
+}
+
+func TestSqliteAutoDeployWindow(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, app.AutoDeployWindow)
+
+	window := &apptypes.MaintenanceWindow{
+		Days:      []string{"sat", "sun"},
+		StartTime: "01:00",
+		EndTime:   "05:00",
+		Timezone:  "America/New_York",
+	}
+	require.NoError(t, s.SetAutoDeployWindow(app.ID, window))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, window, app.AutoDeployWindow)
+
+	require.NoError(t, s.SetAutoDeployWindow(app.ID, nil))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Nil(t, app.AutoDeployWindow)
+}
+
+func TestSqliteAutoRollbackPolicy(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, app.AutoRollbackPolicy)
+
+	policy := &apptypes.AutoRollbackPolicy{
+		Timeout:       "15m",
+		RequiredState: appstatetypes.StateDegraded,
+	}
+	require.NoError(t, s.SetAutoRollbackPolicy(app.ID, policy))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, policy, app.AutoRollbackPolicy)
+
+	require.NoError(t, s.SetAutoRollbackPolicy(app.ID, nil))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Nil(t, app.AutoRollbackPolicy)
+}
+
+func TestSqliteDeployHealthMonitor(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, app.DeployHealthMonitor)
+
+	monitor := &apptypes.DeployHealthMonitor{
+		Sequence: 2,
+		Deadline: time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC),
+	}
+	require.NoError(t, s.SetDeployHealthMonitor(app.ID, monitor))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, monitor, app.DeployHealthMonitor)
+
+	require.NoError(t, s.SetDeployHealthMonitor(app.ID, nil))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Nil(t, app.DeployHealthMonitor)
+}
+
+func TestSqliteClusterStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	clusterID, err := s.CreateNewCluster("", true, "This Cluster", "token")
+	require.NoError(t, err)
+
+	clusters, err := s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, clusterID, clusters[0].ClusterID)
+	assert.Equal(t, "this-cluster", clusters[0].ClusterSlug)
+
+	id, err := s.GetClusterIDFromSlug("this-cluster")
+	require.NoError(t, err)
+	assert.Equal(t, clusterID, id)
+
+	id, err = s.GetClusterIDFromDeployToken("token")
+	require.NoError(t, err)
+	assert.Equal(t, clusterID, id)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	require.NoError(t, s.AddAppToAllDownstreams(app.ID))
+	// adding the app a second time must be a no-op
+	require.NoError(t, s.AddAppToAllDownstreams(app.ID))
+
+	downstreams, err := s.ListDownstreamsForApp(app.ID)
+	require.NoError(t, err)
+	require.Len(t, downstreams, 1)
+	assert.Equal(t, clusterID, downstreams[0].ClusterID)
+	assert.Equal(t, int64(-1), downstreams[0].CurrentSequence)
+}
+
+func TestSqliteKotsadmParamsStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	address, err := s.GetPrometheusAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "", address)
+
+	require.NoError(t, s.SetPrometheusAddress("http://prometheus:9090"))
+	require.NoError(t, s.SetPrometheusAddress("http://prometheus:9091"))
+
+	address, err = s.GetPrometheusAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "http://prometheus:9091", address)
+
+	generated, err := s.IsKotsadmIDGenerated()
+	require.NoError(t, err)
+	assert.False(t, generated)
+
+	require.NoError(t, s.SetIsKotsadmIDGenerated())
+
+	generated, err = s.IsKotsadmIDGenerated()
+	require.NoError(t, err)
+	assert.True(t, generated)
+}
+
+func TestSqliteAppStatusStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	appStatus, err := s.GetAppStatus("app-id")
+	require.NoError(t, err)
+	assert.Equal(t, appstatetypes.StateMissing, appStatus.State)
+
+	updatedAt := time.Unix(1690000000, 0)
+	resourceStates := appstatetypes.ResourceStates{
+		{Kind: "Deployment", Name: "web", Namespace: "default", State: appstatetypes.StateReady},
+	}
+	require.NoError(t, s.SetAppStatus("app-id", resourceStates, updatedAt, 3))
+
+	appStatus, err = s.GetAppStatus("app-id")
+	require.NoError(t, err)
+	assert.Equal(t, appstatetypes.StateReady, appStatus.State)
+	assert.Equal(t, int64(3), appStatus.Sequence)
+	assert.True(t, updatedAt.Equal(appStatus.UpdatedAt))
+	assert.Equal(t, resourceStates, appStatus.ResourceStates)
+}
+
+func TestSqliteScheduledSnapshotStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	require.NoError(t, s.CreateScheduledSnapshot("snapshot-1", "app-id", time.Unix(1690000000, 0)))
+	require.NoError(t, s.CreateScheduledSnapshot("snapshot-2", "app-id", time.Unix(1690003600, 0)))
+
+	pending, err := s.ListPendingScheduledSnapshots("app-id")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	require.NoError(t, s.UpdateScheduledSnapshot("snapshot-1", "backup-1"))
+
+	pending, err = s.ListPendingScheduledSnapshots("app-id")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "snapshot-2", pending[0].ID)
+
+	require.NoError(t, s.DeletePendingScheduledSnapshots("app-id"))
+
+	pending, err = s.ListPendingScheduledSnapshots("app-id")
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+}
+
+func TestSqliteScheduledRestoreDrillStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	clusterID, err := s.CreateNewCluster("", true, "This Cluster", "token")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetInstanceRestoreDrillSchedule(clusterID, "0 2 * * SUN"))
+
+	clusters, err := s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "0 2 * * SUN", clusters[0].RestoreDrillSchedule)
+
+	require.NoError(t, s.CreateScheduledRestoreDrill("drill-1", clusterID, time.Unix(1690000000, 0)))
+	require.NoError(t, s.CreateScheduledRestoreDrill("drill-2", clusterID, time.Unix(1690003600, 0)))
+
+	pending, err := s.ListPendingScheduledRestoreDrills(clusterID)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	require.NoError(t, s.UpdateScheduledRestoreDrill("drill-1", "instance-abc"))
+
+	pending, err = s.ListPendingScheduledRestoreDrills(clusterID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "drill-2", pending[0].ID)
+
+	require.NoError(t, s.DeletePendingScheduledRestoreDrills(clusterID))
+
+	pending, err = s.ListPendingScheduledRestoreDrills(clusterID)
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+}
+
+func TestSqliteSnapshotStorageLocation(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	clusterID, err := s.CreateNewCluster("", true, "This Cluster", "token")
+	require.NoError(t, err)
+
+	clusters, err := s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "", clusters[0].SnapshotStorageLocation)
+
+	require.NoError(t, s.SetInstanceSnapshotStorageLocation(clusterID, "offsite"))
+
+	clusters, err = s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "offsite", clusters[0].SnapshotStorageLocation)
+}
+
+func TestSqliteSnapshotRetention(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	clusterID, err := s.CreateNewCluster("", true, "This Cluster", "token")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetInstanceSnapshotRetention(clusterID, `{"hourly":0,"daily":7,"weekly":4,"monthly":12}`))
+
+	clusters, err := s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, `{"hourly":0,"daily":7,"weekly":4,"monthly":12}`, clusters[0].SnapshotRetention)
+
+	require.NoError(t, s.SetInstanceSnapshotRetention(clusterID, ""))
+
+	clusters, err = s.ListClusters()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "", clusters[0].SnapshotRetention)
+}
+
+func TestSqliteAuditStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	sequence := int64(2)
+	for i := 0; i < 5; i++ {
+		event := &audittypes.Event{
+			CreatedAt: time.UnixMilli(1690000000000 + int64(i)*1000).UTC(),
+			Actor:     "admin",
+			ActorType: audittypes.ActorTypePassword,
+			Action:    "app.deploy",
+			Resource:  "app.my-app.downstream.",
+			AppSlug:   "my-app",
+			Sequence:  &sequence,
+			Outcome:   audittypes.OutcomeSuccess,
+		}
+		require.NoError(t, s.CreateAuditEvent(event))
+		assert.NotEmpty(t, event.ID)
+	}
+	require.NoError(t, s.CreateAuditEvent(&audittypes.Event{
+		CreatedAt: time.UnixMilli(1690000010000).UTC(),
+		Actor:     "user@example.com",
+		ActorType: audittypes.ActorTypeOIDC,
+		Action:    "password.change",
+		Resource:  "password.",
+		Outcome:   audittypes.OutcomeDenied,
+	}))
+
+	events, totalCount, err := s.ListAuditEvents(audittypes.ListOptions{PageSize: 4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), totalCount)
+	require.Len(t, events, 4)
+	assert.Equal(t, "password.change", events[0].Action)
+	assert.Nil(t, events[0].Sequence)
+	assert.Equal(t, time.UnixMilli(1690000010000).UTC(), events[0].CreatedAt)
+	assert.Equal(t, "my-app", events[1].AppSlug)
+	assert.Equal(t, &sequence, events[1].Sequence)
+
+	events, totalCount, err = s.ListAuditEvents(audittypes.ListOptions{Page: 1, PageSize: 4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), totalCount)
+	assert.Len(t, events, 2)
+
+	events, totalCount, err = s.ListAuditEvents(audittypes.ListOptions{Actor: "user@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), totalCount)
+	require.Len(t, events, 1)
+	assert.Equal(t, audittypes.OutcomeDenied, events[0].Outcome)
+
+	events, totalCount, err = s.ListAuditEvents(audittypes.ListOptions{AppSlug: "my-app", Action: "app.deploy"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), totalCount)
+	assert.Len(t, events, 5)
+}
+
+func TestSqliteAPITokenStore(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	expiresAt := time.UnixMilli(1690000100000).UTC()
+	require.NoError(t, s.CreateAPIToken(&apitokentypes.APIToken{
+		ID:        "token-1",
+		Name:      "ci",
+		Roles:     []string{"cluster-admin"},
+		CreatedAt: time.UnixMilli(1690000000000).UTC(),
+		ExpiresAt: &expiresAt,
+	}, "hash-1"))
+	require.NoError(t, s.CreateAPIToken(&apitokentypes.APIToken{
+		ID:        "token-2",
+		Name:      "gitops",
+		Roles:     []string{"read-only", "support"},
+		CreatedAt: time.UnixMilli(1690000001000).UTC(),
+	}, "hash-2"))
+
+	token, tokenHash, err := s.GetAPIToken("token-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash-1", tokenHash)
+	assert.Equal(t, "ci", token.Name)
+	assert.Equal(t, []string{"cluster-admin"}, token.Roles)
+	assert.Equal(t, &expiresAt, token.ExpiresAt)
+	assert.Nil(t, token.LastUsedAt)
+	assert.Nil(t, token.RevokedAt)
+
+	_, _, err = s.GetAPIToken("missing")
+	assert.True(t, s.IsNotFound(err))
+
+	lastUsedAt := time.UnixMilli(1690000050000).UTC()
+	require.NoError(t, s.UpdateAPITokenLastUsedAt("token-2", lastUsedAt))
+	require.NoError(t, s.RevokeAPIToken("token-1"))
+
+	tokens, err := s.ListAPITokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "token-2", tokens[0].ID)
+	assert.Equal(t, &lastUsedAt, tokens[0].LastUsedAt)
+	assert.Nil(t, tokens[0].ExpiresAt)
+	assert.True(t, tokens[1].IsRevoked())
+}
+
+func TestSqliteSupportBundleSchedule(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, app.SupportBundleSchedule)
+
+	schedule := &apptypes.SupportBundleSchedule{
+		Schedule:        "@hourly",
+		Retention:       3,
+		UploadOnFailure: true,
+	}
+	require.NoError(t, s.SetSupportBundleSchedule(app.ID, schedule))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Equal(t, schedule, app.SupportBundleSchedule)
+
+	require.NoError(t, s.SetSupportBundleSchedule(app.ID, nil))
+
+	app, err = s.GetApp(app.ID)
+	require.NoError(t, err)
+	assert.Nil(t, app.SupportBundleSchedule)
+}
+
+func TestSqlitePreflightResultsHistory(t *testing.T) {
+	s := newSqliteTestStore(t)
+
+	app, err := s.CreateApp("my-app", "replicated://my-app", "license", false, false, false)
+	require.NoError(t, err)
+
+	runs, err := s.GetPreflightResultsHistory(app.ID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	// runs are usually written in the same millisecond here
+	require.NoError(t, s.SetPreflightResults(app.ID, 0, []byte(`{"results":[{"isFail":true}]}`), preflighttypes.PreflightTriggerInstall))
+	require.NoError(t, s.SetPreflightResults(app.ID, 0, []byte(`{"results":[{"isPass":true}]}`), preflighttypes.PreflightTriggerRerun))
+	require.NoError(t, s.SetPreflightResults(app.ID, 1, []byte(`{"results":[]}`), preflighttypes.PreflightTriggerConfigChange))
+
+	runs, err = s.GetPreflightResultsHistory(app.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, preflighttypes.PreflightTriggerConfigChange, runs[0].Trigger)
+	assert.Equal(t, int64(1), runs[0].Sequence)
+
+	sequence := int64(0)
+	runs, err = s.GetPreflightResultsHistory(app.ID, &sequence)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, preflighttypes.PreflightTriggerRerun, runs[0].Trigger)
+	assert.Equal(t, `{"results":[{"isPass":true}]}`, runs[0].Result)
+	assert.Equal(t, preflighttypes.PreflightTriggerInstall, runs[1].Trigger)
+	assert.Equal(t, app.ID, runs[1].AppID)
+
+	require.NoError(t, s.ResetPreflightResults(app.ID, 0))
+
+	runs, err = s.GetPreflightResultsHistory(app.ID, &sequence)
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+}
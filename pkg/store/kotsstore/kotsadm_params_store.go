@@ -5,7 +5,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 // IsKotsadmIDGenerated retrieves the id of kotsadm if the pod is already
@@ -33,7 +32,7 @@ func (s *KOTSStore) SetIsKotsadmIDGenerated() error {
 	db := persistence.MustGetDBSession()
 
 	query := `insert into kotsadm_params (key, value) values (?, ?) on conflict (key) do update set value = EXCLUDED.value`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"IS_KOTSADM_ID_GENERATED", true},
 	})
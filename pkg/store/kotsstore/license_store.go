@@ -14,14 +14,13 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	rendertypes "github.com/replicatedhq/kots/pkg/render/types"
-	"github.com/rqlite/gorqlite"
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
 )
 
 func (s *KOTSStore) GetLatestLicenseForApp(appID string) (*kotsv1beta1.License, error) {
 	db := persistence.MustGetDBSession()
 	query := `select license from app where id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -32,7 +31,7 @@ func (s *KOTSStore) GetLatestLicenseForApp(appID string) (*kotsv1beta1.License,
 		return nil, ErrNotFound
 	}
 
-	var licenseStr gorqlite.NullString
+	var licenseStr persistence.NullString
 	if err := rows.Scan(&licenseStr); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
@@ -49,7 +48,7 @@ func (s *KOTSStore) GetLatestLicenseForApp(appID string) (*kotsv1beta1.License,
 func (s *KOTSStore) GetLicenseForAppVersion(appID string, sequence int64) (*kotsv1beta1.License, error) {
 	db := persistence.MustGetDBSession()
 	query := `select kots_license from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -60,7 +59,7 @@ func (s *KOTSStore) GetLicenseForAppVersion(appID string, sequence int64) (*kots
 		return nil, ErrNotFound
 	}
 
-	var licenseStr gorqlite.NullString
+	var licenseStr persistence.NullString
 	if err := rows.Scan(&licenseStr); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
@@ -86,7 +85,7 @@ func (s *KOTSStore) GetAllAppLicenses() ([]*kotsv1beta1.License, error) {
 		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
 	}
 
-	var licenseStr gorqlite.NullString
+	var licenseStr persistence.NullString
 	licenses := []*kotsv1beta1.License{}
 	for rows.Next() {
 		if err := rows.Scan(&licenseStr); err != nil {
@@ -109,7 +108,7 @@ func (s *KOTSStore) GetAllAppLicenses() ([]*kotsv1beta1.License, error) {
 func (s *KOTSStore) UpdateAppLicense(appID string, baseSequence int64, archiveDir string, newLicense *kotsv1beta1.License, originalLicenseData string, channelChanged bool, failOnVersionCreate bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) (int64, error) {
 	db := persistence.MustGetDBSession()
 
-	statements := []gorqlite.ParameterizedStatement{}
+	statements := []persistence.ParameterizedStatement{}
 
 	ser := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
 	var b bytes.Buffer
@@ -122,7 +121,7 @@ func (s *KOTSStore) UpdateAppLicense(appID string, baseSequence int64, archiveDi
 	}
 
 	//  app has the original license data received from the server
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     `update app set license = ?, last_license_sync = ?, channel_changed = ? where id = ?`,
 		Arguments: []interface{}{originalLicenseData, time.Now().Unix(), channelChanged, appID},
 	})
@@ -153,7 +152,7 @@ func (s *KOTSStore) UpdateAppLicense(appID string, baseSequence int64, archiveDi
 func (s *KOTSStore) UpdateAppLicenseSyncNow(appID string) error {
 	db := persistence.MustGetDBSession()
 	query := `update app set last_license_sync = ? where id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{time.Now().Unix(), appID},
 	})
@@ -164,7 +163,7 @@ func (s *KOTSStore) UpdateAppLicenseSyncNow(appID string) error {
 	return nil
 }
 
-func (s *KOTSStore) createNewVersionForLicenseChangeStatements(appID string, baseSequence int64, archiveDir string, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]gorqlite.ParameterizedStatement, int64, error) {
+func (s *KOTSStore) createNewVersionForLicenseChangeStatements(appID string, baseSequence int64, archiveDir string, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]persistence.ParameterizedStatement, int64, error) {
 	registrySettings, err := s.GetRegistryDetailsForApp(appID)
 	if err != nil {
 		return nil, int64(0), errors.Wrap(err, "failed to get registry settings for app")
@@ -10,7 +10,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) RunMigrations() {
@@ -108,7 +107,7 @@ func (s *KOTSStore) migrateKotsAppSpec() error {
 			}
 
 			query := `update app_version set kots_app_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
@@ -180,7 +179,7 @@ func (s *KOTSStore) migrateKotsInstallationSpec() error {
 			}
 
 			query := `update app_version set kots_installation_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
@@ -252,7 +251,7 @@ func (s *KOTSStore) migrateSupportBundleSpec() error {
 			}
 
 			query := `update app_version set supportbundle_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
@@ -324,7 +323,7 @@ func (s *KOTSStore) migratePreflightSpec() error {
 			}
 
 			query := `update app_version set preflight_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
@@ -396,7 +395,7 @@ func (s *KOTSStore) migrateAnalyzerSpec() error {
 			}
 
 			query := `update app_version set analyzer_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
@@ -468,7 +467,7 @@ func (s *KOTSStore) migrateAppSpec() error {
 			}
 
 			query := `update app_version set app_spec = ? where app_id = ? and sequence = ?`
-			wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 				Query:     query,
 				Arguments: []interface{}{spec, version.appID, version.sequence},
 			})
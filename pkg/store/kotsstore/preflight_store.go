@@ -10,14 +10,14 @@ import (
 	"github.com/replicatedhq/kots/pkg/persistence"
 	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	troubleshootpreflight "github.com/replicatedhq/troubleshoot/pkg/preflight"
-	"github.com/rqlite/gorqlite"
+	"github.com/segmentio/ksuid"
 )
 
 func (s *KOTSStore) SetPreflightProgress(appID string, sequence int64, progress string) error {
 	db := persistence.MustGetDBSession()
 	query := `update app_downstream_version set preflight_progress = ? where app_id = ? and parent_sequence = ?`
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{progress, appID, sequence},
 	})
@@ -35,7 +35,7 @@ func (s *KOTSStore) GetPreflightProgress(appID string, sequence int64) (string,
 	FROM app_downstream_version
 	WHERE app_id = ? AND sequence = ?`
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -46,7 +46,7 @@ func (s *KOTSStore) GetPreflightProgress(appID string, sequence int64) (string,
 		return "", ErrNotFound
 	}
 
-	var progress gorqlite.NullString
+	var progress persistence.NullString
 	if err := rows.Scan(&progress); err != nil {
 		return "", errors.Wrap(err, "failed to scan")
 	}
@@ -54,22 +54,74 @@ func (s *KOTSStore) GetPreflightProgress(appID string, sequence int64) (string,
 	return progress.String, nil
 }
 
-func (s *KOTSStore) SetPreflightResults(appID string, sequence int64, results []byte) error {
+func (s *KOTSStore) SetPreflightResults(appID string, sequence int64, results []byte, trigger preflighttypes.PreflightTrigger) error {
 	db := persistence.MustGetDBSession()
-	query := `update app_downstream_version set preflight_result = ?, preflight_result_created_at = ?,
+	now := time.Now()
+
+	statements := []persistence.ParameterizedStatement{
+		{
+			Query: `update app_downstream_version set preflight_result = ?, preflight_result_created_at = ?,
 status = (case when status = 'deployed' then 'deployed' when status = 'deploying' then 'deploying' else 'pending' end),
 preflight_progress = NULL, preflight_skipped = false
-where app_id = ? and parent_sequence = ?`
+where app_id = ? and parent_sequence = ?`,
+			Arguments: []interface{}{string(results), now.Unix(), appID, sequence},
+		},
+		{
+			Query: `insert into preflight_result_history (id, app_id, sequence, run_trigger, result, created_at, run_sequence)
+values (?, ?, ?, ?, ?, ?, (select coalesce(max(run_sequence), 0) + 1 from preflight_result_history where app_id = ?))`,
+			Arguments: []interface{}{ksuid.New().String(), appID, sequence, string(trigger), string(results), now.UnixMilli(), appID},
+		},
+	}
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+	}
+
+	return nil
+}
+
+// GetPreflightResultsHistory returns every preflight run of an app, newest first.
+// Runs of all versions are returned if sequence is nil.
+func (s *KOTSStore) GetPreflightResultsHistory(appID string, sequence *int64) ([]preflighttypes.PreflightRun, error) {
+	db := persistence.MustGetDBSession()
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	query := `select id, app_id, sequence, run_trigger, result, created_at from preflight_result_history where app_id = ?`
+	args := []interface{}{appID}
+	if sequence != nil {
+		query += ` and sequence = ?`
+		args = append(args, *sequence)
+	}
+	// run_sequence counts the runs of the app, it orders runs that were written in the same millisecond
+	query += ` order by created_at desc, run_sequence desc`
+
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
-		Arguments: []interface{}{string(results), time.Now().Unix(), appID, sequence},
+		Arguments: args,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
 	}
 
-	return nil
+	runs := []preflighttypes.PreflightRun{}
+	for rows.Next() {
+		var trigger persistence.NullString
+		var createdAt int64
+
+		run := preflighttypes.PreflightRun{}
+		if err := rows.Scan(&run.ID, &run.AppID, &run.Sequence, &trigger, &run.Result, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "failed to scan preflight run")
+		}
+		run.Trigger = preflighttypes.PreflightTrigger(trigger.String)
+		run.CreatedAt = time.UnixMilli(createdAt).UTC()
+
+		runs = append(runs, run)
+	}
+
+	return runs, nil
 }
 
 func (s *KOTSStore) GetPreflightResults(appID string, sequence int64) (*preflighttypes.PreflightResult, error) {
@@ -90,7 +142,7 @@ func (s *KOTSStore) GetPreflightResults(appID string, sequence int64) (*prefligh
 		app_downstream_version.app_id = ? AND
 		app_downstream_version.sequence = ?`
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -109,10 +161,11 @@ func (s *KOTSStore) GetPreflightResults(appID string, sequence int64) (*prefligh
 	return r, nil
 }
 
+// ResetPreflightResults clears the results of a version before preflights are rerun. The history of runs is kept.
 func (s *KOTSStore) ResetPreflightResults(appID string, sequence int64) error {
 	db := persistence.MustGetDBSession()
 	query := `update app_downstream_version set preflight_result=null, preflight_result_created_at=null, preflight_skipped=false where app_id = ? and parent_sequence = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -128,7 +181,7 @@ func (s *KOTSStore) SetIgnorePreflightPermissionErrors(appID string, sequence in
 	SET status = 'pending_preflight', preflight_ignore_permissions = true, preflight_result = null, preflight_skipped = false
 	WHERE app_id = ? AND sequence = ?`
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -139,12 +192,12 @@ func (s *KOTSStore) SetIgnorePreflightPermissionErrors(appID string, sequence in
 	return nil
 }
 
-func (s *KOTSStore) preflightResultFromRow(row gorqlite.QueryResult) (*preflighttypes.PreflightResult, error) {
+func (s *KOTSStore) preflightResultFromRow(row persistence.QueryResult) (*preflighttypes.PreflightResult, error) {
 	r := &preflighttypes.PreflightResult{}
 
-	var preflightResult gorqlite.NullString
-	var preflightResultCreatedAt gorqlite.NullTime
-	var preflightSpec gorqlite.NullString
+	var preflightResult persistence.NullString
+	var preflightResultCreatedAt persistence.NullTime
+	var preflightSpec persistence.NullString
 
 	if err := row.Scan(
 		&preflightResult,
@@ -171,7 +224,7 @@ func (s *KOTSStore) preflightResultFromRow(row gorqlite.QueryResult) (*preflight
 	return r, nil
 }
 
-func (s *KOTSStore) hasFailingStrictPreflights(preflightSpecStr gorqlite.NullString, preflightResultStr gorqlite.NullString) (bool, error) {
+func (s *KOTSStore) hasFailingStrictPreflights(preflightSpecStr persistence.NullString, preflightResultStr persistence.NullString) (bool, error) {
 	hasFailingStrictPreflights, err := s.hasStrictPreflights(preflightSpecStr)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to check for strict preflight")
@@ -187,7 +240,7 @@ func (s *KOTSStore) hasFailingStrictPreflights(preflightSpecStr gorqlite.NullStr
 	return hasFailingStrictPreflights, nil
 }
 
-func (s *KOTSStore) hasStrictPreflights(preflightSpecStr gorqlite.NullString) (bool, error) {
+func (s *KOTSStore) hasStrictPreflights(preflightSpecStr persistence.NullString) (bool, error) {
 	hasStrictPreflights := false
 	if preflightSpecStr.Valid && preflightSpecStr.String != "" {
 		preflight, err := kotsutil.LoadPreflightFromContents([]byte(preflightSpecStr.String))
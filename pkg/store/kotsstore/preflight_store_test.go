@@ -1,13 +1,20 @@
 package kotsstore
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"io"
 	"testing"
 
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/persistence"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	"github.com/replicatedhq/troubleshoot/pkg/multitype"
 	troubleshootpreflight "github.com/replicatedhq/troubleshoot/pkg/preflight"
-	"github.com/rqlite/gorqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -67,12 +74,12 @@ var (
 	}
 )
 
-func toSqlString(t *testing.T, val interface{}) gorqlite.NullString {
+func toSqlString(t *testing.T, val interface{}) persistence.NullString {
 	b, err := json.Marshal(val)
 	if err != nil {
 		t.Fatalf("hasFailingStrictPreflights() json Unmarshall error = %v", err)
 	}
-	return gorqlite.NullString{
+	return persistence.NullString{
 		String: string(b),
 		Valid:  true,
 	}
@@ -81,8 +88,8 @@ func toSqlString(t *testing.T, val interface{}) gorqlite.NullString {
 func Test_hasFailingStrictPreflights(t *testing.T) {
 	tests := []struct {
 		name               string
-		preflightSpecStr   gorqlite.NullString
-		preflightResultStr gorqlite.NullString
+		preflightSpecStr   persistence.NullString
+		preflightResultStr persistence.NullString
 		want               bool
 		wantErr            bool
 	}{
@@ -112,26 +119,26 @@ func Test_hasFailingStrictPreflights(t *testing.T) {
 			wantErr:            false,
 		}, {
 			name:               "expect false, error when preflightSpec has a parse error",
-			preflightSpecStr:   gorqlite.NullString{Valid: true, String: "invalid"},
+			preflightSpecStr:   persistence.NullString{Valid: true, String: "invalid"},
 			preflightResultStr: toSqlString(t, strictFailFalsePreflightResultSpec),
 			want:               false,
 			wantErr:            true,
 		}, {
 			name:               "expect false, error when preflightResultSpec has a parse error",
 			preflightSpecStr:   toSqlString(t, strictTruePreflightSpec),
-			preflightResultStr: gorqlite.NullString{Valid: true, String: "invalid"},
+			preflightResultStr: persistence.NullString{Valid: true, String: "invalid"},
 			want:               false,
 			wantErr:            true,
 		}, {
 			name:               "expect false, error when preflightSpec with strict:true analyzer and preflightResultSpec has a empty string",
 			preflightSpecStr:   toSqlString(t, strictTruePreflightSpec),
-			preflightResultStr: gorqlite.NullString{Valid: true, String: ""},
+			preflightResultStr: persistence.NullString{Valid: true, String: ""},
 			want:               true,
 			wantErr:            false,
 		}, {
 			name:               "expect false, error when preflightSpec with strict:false analyzer and preflightResultSpec has a empty string",
 			preflightSpecStr:   toSqlString(t, strictFalsePreflightSpec),
-			preflightResultStr: gorqlite.NullString{Valid: true, String: ""},
+			preflightResultStr: persistence.NullString{Valid: true, String: ""},
 			want:               false,
 			wantErr:            false,
 		},
@@ -150,3 +157,91 @@ func Test_hasFailingStrictPreflights(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPreflightResultsHistoryPostgres(t *testing.T) {
+	queries := &recordingDriver{}
+	conn := sql.OpenDB(queries)
+	defer conn.Close()
+
+	persistence.SetDB(persistence.NewSQLDB(persistence.DriverPostgres, conn))
+	defer persistence.SetDB(nil)
+
+	s := &KOTSStore{}
+
+	sequence := int64(1)
+	runs, err := s.GetPreflightResultsHistory("app-id", &sequence)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	require.Len(t, queries.queries, 1)
+	assert.Equal(t, `select id, app_id, sequence, run_trigger, result, created_at from preflight_result_history where app_id = $1 and sequence = $2 order by created_at desc, run_sequence desc`, queries.queries[0])
+}
+
+// recordingDriver is a database/sql connector that records the queries it receives and returns no rows
+type recordingDriver struct {
+	queries []string
+}
+
+func (d *recordingDriver) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) Driver() driver.Driver {
+	return d
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("writes are not supported")
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.queries = append(s.conn.driver.queries, s.query)
+	return &emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (r *emptyRows) Columns() []string {
+	return []string{"id", "app_id", "sequence", "run_trigger", "result", "created_at"}
+}
+
+func (r *emptyRows) Close() error {
+	return nil
+}
+
+func (r *emptyRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
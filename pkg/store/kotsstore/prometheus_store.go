@@ -5,13 +5,12 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) GetPrometheusAddress() (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select value from kotsadm_params where key = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"PROMETHEUS_ADDRESS"},
 	})
@@ -34,7 +33,7 @@ func (s *KOTSStore) SetPrometheusAddress(address string) error {
 	db := persistence.MustGetDBSession()
 	query := `insert into kotsadm_params (key, value) values (?, ?) on conflict (key) do update set value = EXCLUDED.value`
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"PROMETHEUS_ADDRESS", address},
 	})
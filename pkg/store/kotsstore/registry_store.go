@@ -9,14 +9,13 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
-	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 )
 
 func (s *KOTSStore) GetRegistryDetailsForApp(appID string) (registrytypes.RegistrySettings, error) {
 	db := persistence.MustGetDBSession()
 	query := `select registry_hostname, registry_username, registry_password_enc, namespace, registry_is_readonly from app where id = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -27,11 +26,11 @@ func (s *KOTSStore) GetRegistryDetailsForApp(appID string) (registrytypes.Regist
 		return registrytypes.RegistrySettings{}, ErrNotFound
 	}
 
-	var registryHostname gorqlite.NullString
-	var registryUsername gorqlite.NullString
-	var registryPasswordEnc gorqlite.NullString
-	var registryNamespace gorqlite.NullString
-	var isReadOnly gorqlite.NullBool
+	var registryHostname persistence.NullString
+	var registryUsername persistence.NullString
+	var registryPasswordEnc persistence.NullString
+	var registryNamespace persistence.NullString
+	var isReadOnly persistence.NullBool
 
 	if err := rows.Scan(&registryHostname, &registryUsername, &registryPasswordEnc, &registryNamespace, &isReadOnly); err != nil {
 		return registrytypes.RegistrySettings{}, errors.Wrap(err, "failed to scan registry")
@@ -72,7 +71,7 @@ func (s *KOTSStore) UpdateRegistry(appID string, hostname string, username strin
 	if password == registrytypes.PasswordMask {
 		// password unchanged - don't update it
 		query := `update app set registry_hostname = ?, registry_username = ?, namespace = ?, registry_is_readonly = ? where id = ?`
-		wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{hostname, username, namespace, isReadOnly, appID},
 		})
@@ -83,7 +82,7 @@ func (s *KOTSStore) UpdateRegistry(appID string, hostname string, username strin
 		passwordEnc := base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte(password)))
 
 		query := `update app set registry_hostname = ?, registry_username = ?, registry_password_enc = ?, namespace = ?, registry_is_readonly = ? where id = ?`
-		wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{hostname, username, passwordEnc, namespace, isReadOnly, appID},
 		})
@@ -98,7 +97,7 @@ func (s *KOTSStore) UpdateRegistry(appID string, hostname string, username strin
 func (s *KOTSStore) GetAppIDsFromRegistry(hostname string) ([]string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select id from app where registry_hostname = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{hostname},
 	})
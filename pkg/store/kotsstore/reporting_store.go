@@ -9,7 +9,6 @@ import (
 	reportingtypes "github.com/replicatedhq/kots/pkg/api/reporting/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 func (s *KOTSStore) SavePreflightReport(licenseID string, preflightStatus *reportingtypes.PreflightStatus) error {
@@ -43,7 +42,7 @@ func (s *KOTSStore) SavePreflightReport(licenseID string, preflightStatus *repor
 		app_status = EXCLUDED.app_status,
 		kots_version = EXCLUDED.kots_version`
 
-	statement := gorqlite.ParameterizedStatement{
+	statement := persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			createdAt.UnixMilli(),
@@ -138,7 +137,7 @@ func (s *KOTSStore) SaveReportingInfo(licenseID string, reportingInfo *reporting
 		repl_helm_installs = EXCLUDED.repl_helm_installs,
 		native_helm_installs = EXCLUDED.native_helm_installs`
 
-	statement := gorqlite.ParameterizedStatement{
+	statement := persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			createdAt.UnixMilli(),
@@ -187,7 +186,7 @@ func (s *KOTSStore) removeOldReportingData(reportingTable string) error {
 	db := persistence.MustGetDBSession()
 
 	query := fmt.Sprintf(`select count(1) from %s`, reportingTable)
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query: query,
 	})
 	if err != nil {
@@ -210,7 +209,7 @@ func (s *KOTSStore) removeOldReportingData(reportingTable string) error {
 	}
 
 	query = fmt.Sprintf(`select created_at from %s order by created_at desc limit ?, 1`, reportingTable)
-	rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err = db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			reportingMaxRows,
@@ -232,7 +231,7 @@ func (s *KOTSStore) removeOldReportingData(reportingTable string) error {
 	oldestCreatedAt := time.UnixMilli(timeMs)
 
 	query = fmt.Sprintf(`delete from %s where created_at <= ?`, reportingTable)
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			oldestCreatedAt.UnixMilli(),
@@ -15,7 +15,6 @@ import (
 	sessiontypes "github.com/replicatedhq/kots/pkg/session/types"
 	usertypes "github.com/replicatedhq/kots/pkg/user/types"
 	"github.com/replicatedhq/kots/pkg/util"
-	"github.com/rqlite/gorqlite"
 	"github.com/segmentio/ksuid"
 	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
@@ -54,7 +53,7 @@ func (s *KOTSStore) migrateSessionsFromRqlite() error {
 	for rows.Next() {
 		session := sessiontypes.Session{}
 
-		var issuedAt gorqlite.NullTime
+		var issuedAt persistence.NullTime
 		var expiresAt time.Time
 		var metadataStr string
 		if err := rows.Scan(&session.ID, &metadataStr, &issuedAt, &expiresAt); err != nil {
@@ -125,6 +124,7 @@ func (s *KOTSStore) CreateSession(forUser *usertypes.User, issuedAt time.Time, e
 
 	session := sessiontypes.Session{
 		ID:        id,
+		UserID:    forUser.ID,
 		IssuedAt:  issuedAt,
 		ExpiresAt: expiresAt,
 		Roles:     roles,
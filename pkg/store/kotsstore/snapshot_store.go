@@ -8,7 +8,6 @@ import (
 	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +17,7 @@ func (s *KOTSStore) ListPendingScheduledSnapshots(appID string) ([]snapshottypes
 
 	db := persistence.MustGetDBSession()
 	query := `SELECT id, app_id, scheduled_timestamp FROM scheduled_snapshots WHERE app_id = ? AND backup_name IS NULL;`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -44,7 +43,7 @@ func (s *KOTSStore) UpdateScheduledSnapshot(snapshotID string, backupName string
 
 	db := persistence.MustGetDBSession()
 	query := `UPDATE scheduled_snapshots SET backup_name = ? WHERE id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{backupName, snapshotID},
 	})
@@ -60,7 +59,7 @@ func (s *KOTSStore) DeletePendingScheduledSnapshots(appID string) error {
 
 	db := persistence.MustGetDBSession()
 	query := `DELETE FROM scheduled_snapshots WHERE app_id = ? AND backup_name IS NULL`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
 	})
@@ -87,7 +86,7 @@ func (s *KOTSStore) CreateScheduledSnapshot(id string, appID string, timestamp t
 			?
 		)
 	`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id, appID, timestamp.Unix()},
 	})
@@ -104,7 +103,7 @@ func (s *KOTSStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]s
 
 	db := persistence.MustGetDBSession()
 	query := `SELECT id, cluster_id, scheduled_timestamp FROM scheduled_instance_snapshots WHERE cluster_id = ? AND backup_name IS NULL;`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{clusterID},
 	})
@@ -130,7 +129,7 @@ func (s *KOTSStore) UpdateScheduledInstanceSnapshot(snapshotID string, backupNam
 
 	db := persistence.MustGetDBSession()
 	query := `UPDATE scheduled_instance_snapshots SET backup_name = ? WHERE id = ?`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{backupName, snapshotID},
 	})
@@ -146,7 +145,7 @@ func (s *KOTSStore) DeletePendingScheduledInstanceSnapshots(clusterID string) er
 
 	db := persistence.MustGetDBSession()
 	query := `DELETE FROM scheduled_instance_snapshots WHERE cluster_id = ? AND backup_name IS NULL`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{clusterID},
 	})
@@ -173,7 +172,93 @@ func (s *KOTSStore) CreateScheduledInstanceSnapshot(id string, clusterID string,
 			?
 		)
 	`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{id, clusterID, timestamp.Unix()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) ListPendingScheduledRestoreDrills(clusterID string) ([]snapshottypes.ScheduledRestoreDrill, error) {
+	logger.Debug("Listing pending scheduled restore drills",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `SELECT id, cluster_id, scheduled_timestamp FROM scheduled_restore_drills WHERE cluster_id = ? AND backup_name IS NULL;`
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{clusterID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	scheduledDrills := []snapshottypes.ScheduledRestoreDrill{}
+	for rows.Next() {
+		s := snapshottypes.ScheduledRestoreDrill{}
+		if err := rows.Scan(&s.ID, &s.ClusterID, &s.ScheduledTimestamp); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		scheduledDrills = append(scheduledDrills, s)
+	}
+
+	return scheduledDrills, nil
+}
+
+func (s *KOTSStore) UpdateScheduledRestoreDrill(drillID string, backupName string) error {
+	logger.Debug("Updating scheduled restore drill",
+		zap.String("ID", drillID))
+
+	db := persistence.MustGetDBSession()
+	query := `UPDATE scheduled_restore_drills SET backup_name = ? WHERE id = ?`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{backupName, drillID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+	return nil
+}
+
+func (s *KOTSStore) DeletePendingScheduledRestoreDrills(clusterID string) error {
+	logger.Debug("Deleting pending scheduled restore drills",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `DELETE FROM scheduled_restore_drills WHERE cluster_id = ? AND backup_name IS NULL`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) CreateScheduledRestoreDrill(id string, clusterID string, timestamp time.Time) error {
+	logger.Debug("Creating scheduled restore drill",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+	query := `
+		INSERT INTO scheduled_restore_drills (
+			id,
+			cluster_id,
+			scheduled_timestamp
+		) VALUES (
+			?,
+			?,
+			?
+		)
+	`
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{id, clusterID, timestamp.Unix()},
 	})
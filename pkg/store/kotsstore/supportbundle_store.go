@@ -23,7 +23,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/supportbundle/types"
 	"github.com/replicatedhq/kots/pkg/util"
 	troubleshootredact "github.com/replicatedhq/troubleshoot/pkg/redact"
-	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
@@ -108,12 +107,12 @@ func (s *KOTSStore) migrateSupportBundlesFromRqlite() error {
 
 	supportBundles := []types.SupportBundle{}
 	for rows.Next() {
-		var name gorqlite.NullString
-		var size gorqlite.NullFloat64
-		var treeIndex gorqlite.NullString
-		var uploadedAt gorqlite.NullTime
-		var sharedAt gorqlite.NullTime
-		var isArchived gorqlite.NullBool
+		var name persistence.NullString
+		var size persistence.NullFloat64
+		var treeIndex persistence.NullString
+		var uploadedAt persistence.NullTime
+		var sharedAt persistence.NullTime
+		var isArchived persistence.NullBool
 
 		s := types.SupportBundle{}
 		if err := rows.Scan(&s.ID, &s.AppID, &name, &size, &s.Status, &treeIndex, &s.CreatedAt, &uploadedAt, &sharedAt, &isArchived); err != nil {
@@ -142,7 +141,7 @@ func (s *KOTSStore) migrateSupportBundlesFromRqlite() error {
 
 		// NOTE we are dropping ID, error and max_severity from the data because it's not used and has unknown validity
 		query = `SELECT insights, created_at FROM supportbundle_analysis where supportbundle_id = ?`
-		rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{supportBundle.ID},
 		})
@@ -153,7 +152,7 @@ func (s *KOTSStore) migrateSupportBundlesFromRqlite() error {
 			return ErrNotFound
 		}
 
-		var insightsStr gorqlite.NullString
+		var insightsStr persistence.NullString
 		a := &types.SupportBundleAnalysis{}
 		hasAnalysis := true
 		if err := rows.Scan(&insightsStr, &a.CreatedAt); err != nil {
@@ -179,7 +178,7 @@ func (s *KOTSStore) migrateSupportBundlesFromRqlite() error {
 		}
 
 		query = `select redact_report from supportbundle where id = ?`
-		rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		rows, err = db.QueryOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{supportBundle.ID},
 		})
@@ -190,7 +189,7 @@ func (s *KOTSStore) migrateSupportBundlesFromRqlite() error {
 			return ErrNotFound
 		}
 
-		var redactString gorqlite.NullString
+		var redactString persistence.NullString
 		if err := rows.Scan(&redactString); err != nil {
 			return errors.Wrap(err, "failed to scan")
 		}
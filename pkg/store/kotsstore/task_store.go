@@ -8,7 +8,6 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/persistence"
-	"github.com/rqlite/gorqlite"
 )
 
 const (
@@ -48,8 +47,8 @@ func (s *KOTSStore) migrateTasksFromRqlite() error {
 
 	for rows.Next() {
 		var id string
-		var status gorqlite.NullString
-		var message gorqlite.NullString
+		var status persistence.NullString
+		var message persistence.NullString
 
 		ts := TaskStatus{}
 		if err := rows.Scan(&id, &ts.UpdatedAt, &message, &status); err != nil {
@@ -2,16 +2,19 @@ package kotsstore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/persistence"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
 	"github.com/replicatedhq/kots/pkg/util"
-	"github.com/rqlite/gorqlite"
+	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -21,6 +24,11 @@ var (
 	passwordSecretName = "kotsadm-password"
 )
 
+const (
+	failedLoginsByIPRetention = 24 * time.Hour
+	maxFailedLoginsByIP       = 1000
+)
+
 // GetSharedPasswordBcrypt will return the hash of the current password
 // that can be used to validate an auth request. This is in the store pkg,
 // but the data may be in the cluster or the database, depending on the
@@ -38,15 +46,6 @@ func (s *KOTSStore) GetSharedPasswordBcrypt() ([]byte, error) {
 		// so instead we fallback to the environment variable
 		shaBytes = []byte(os.Getenv("SHARED_PASSWORD_BCRYPT"))
 	} else {
-		if passwordSecret.Labels == nil {
-			passwordSecret.Labels = map[string]string{}
-		}
-
-		numAttempts, _ := strconv.Atoi(passwordSecret.Labels["numAttempts"])
-		if numAttempts > 10 {
-			return nil, ErrTooManyAttempts
-		}
-
 		shaBytes = passwordSecret.Data["passwordBcrypt"]
 	}
 
@@ -60,7 +59,7 @@ func (s *KOTSStore) getSharedPaswordBcryptFromDatabase() ([]byte, error) {
 
 	// check for too many attempts / locked out
 	query := `select value from kotsadm_params where key = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"failed.login.count"},
 	})
@@ -85,7 +84,7 @@ func (s *KOTSStore) getSharedPaswordBcryptFromDatabase() ([]byte, error) {
 	}
 
 	// get the pasword now
-	rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err = db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"password.bcrypt"},
 	})
@@ -105,7 +104,25 @@ func (s *KOTSStore) getSharedPaswordBcryptFromDatabase() ([]byte, error) {
 	return hash, nil
 }
 
-func (s *KOTSStore) FlagInvalidPassword() error {
+// GetLoginAttempts returns the failed logins recorded on the password secret
+func (s *KOTSStore) GetLoginAttempts() (*usertypes.LoginAttempts, error) {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), passwordSecretName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return &usertypes.LoginAttempts{ByIP: map[string]usertypes.FailedLogins{}}, nil
+		}
+		return nil, errors.Wrap(err, "failed to get password secret")
+	}
+
+	return loginAttemptsFromSecret(secret)
+}
+
+func (s *KOTSStore) FlagInvalidPassword(clientIP string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s clientset")
@@ -120,13 +137,24 @@ func (s *KOTSStore) FlagInvalidPassword() error {
 			return errors.Wrap(err, "failed to get password secret")
 		}
 
-		if secret.Labels == nil {
-			secret.Labels = map[string]string{}
+		attempts, err := loginAttemptsFromSecret(secret)
+		if err != nil {
+			return errors.Wrap(err, "failed to get login attempts")
+		}
+
+		now := time.Now()
+		attempts.Global.Count++
+		attempts.Global.LastFailure = now
+		if clientIP != "" {
+			ipAttempts := attempts.ByIP[clientIP]
+			ipAttempts.Count++
+			ipAttempts.LastFailure = now
+			attempts.ByIP[clientIP] = ipAttempts
 		}
 
-		secret.Labels["lastFailure"] = fmt.Sprintf("%d", time.Now().Unix())
-		numAttempts, _ := strconv.Atoi(secret.Labels["numAttempts"])
-		secret.Labels["numAttempts"] = strconv.Itoa(numAttempts + 1)
+		if err := setLoginAttemptsOnSecret(secret, attempts); err != nil {
+			return errors.Wrap(err, "failed to set login attempts")
+		}
 
 		if _, err := clientset.CoreV1().Secrets(util.PodNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
 			if kuberneteserrors.IsConflict(err) {
@@ -146,7 +174,7 @@ func (s *KOTSStore) flagInvalidPasswordInDatabase() error {
 	db := persistence.MustGetDBSession()
 
 	query := `select value from kotsadm_params where key = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{"failed.login.count"},
 	})
@@ -156,7 +184,7 @@ func (s *KOTSStore) flagInvalidPasswordInDatabase() error {
 
 	if !rows.Next() {
 		query = `insert into kotsadm_params (key, value) values (?, ?)`
-		wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 			Query:     query,
 			Arguments: []interface{}{"failed.login.count", "1"},
 		})
@@ -177,7 +205,7 @@ func (s *KOTSStore) flagInvalidPasswordInDatabase() error {
 	}
 	i++
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     `update kotsadm_params set value = ? where key = ?`,
 		Arguments: []interface{}{strconv.Itoa(i), "failed.login.count"},
 	})
@@ -189,7 +217,9 @@ func (s *KOTSStore) flagInvalidPasswordInDatabase() error {
 
 }
 
-func (s *KOTSStore) FlagSuccessfulLogin() error {
+// FlagSuccessfulLogin resets the global failed login count and the count for the client ip.
+// Failures from other ips are kept so that a successful login does not unlock them.
+func (s *KOTSStore) FlagSuccessfulLogin(clientIP string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s clientset")
@@ -204,11 +234,18 @@ func (s *KOTSStore) FlagSuccessfulLogin() error {
 			return errors.Wrap(err, "failed to get password secret")
 		}
 
-		if secret.Labels == nil {
-			secret.Labels = map[string]string{}
+		attempts, err := loginAttemptsFromSecret(secret)
+		if err != nil {
+			return errors.Wrap(err, "failed to get login attempts")
+		}
+
+		attempts.Global = usertypes.FailedLogins{}
+		delete(attempts.ByIP, clientIP)
+
+		if err := setLoginAttemptsOnSecret(secret, attempts); err != nil {
+			return errors.Wrap(err, "failed to set login attempts")
 		}
 
-		secret.Labels["numAttempts"] = "0"
 		if _, err := clientset.CoreV1().Secrets(util.PodNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
 			if kuberneteserrors.IsConflict(err) {
 				if i > 2 {
@@ -223,10 +260,87 @@ func (s *KOTSStore) FlagSuccessfulLogin() error {
 	}
 }
 
+// loginAttemptsFromSecret reads the global failed logins from the secret labels, where older versions kept them,
+// and the failed logins per client ip from the secret data
+func loginAttemptsFromSecret(secret *corev1.Secret) (*usertypes.LoginAttempts, error) {
+	attempts := &usertypes.LoginAttempts{
+		ByIP: map[string]usertypes.FailedLogins{},
+	}
+
+	attempts.Global.Count, _ = strconv.Atoi(secret.Labels["numAttempts"])
+	if lastFailure, err := strconv.ParseInt(secret.Labels["lastFailure"], 10, 64); err == nil {
+		attempts.Global.LastFailure = time.Unix(lastFailure, 0)
+	}
+
+	if data, ok := secret.Data[usertypes.FailedLoginsByIPKey]; ok && len(data) > 0 {
+		if err := json.Unmarshal(data, &attempts.ByIP); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal failed logins by ip")
+		}
+	}
+
+	return attempts, nil
+}
+
+func setLoginAttemptsOnSecret(secret *corev1.Secret, attempts *usertypes.LoginAttempts) error {
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	secret.Labels["numAttempts"] = strconv.Itoa(attempts.Global.Count)
+	if attempts.Global.LastFailure.IsZero() {
+		delete(secret.Labels, "lastFailure")
+	} else {
+		secret.Labels["lastFailure"] = fmt.Sprintf("%d", attempts.Global.LastFailure.Unix())
+	}
+
+	byIP := pruneFailedLoginsByIP(attempts.ByIP, time.Now())
+	if len(byIP) == 0 {
+		delete(secret.Data, usertypes.FailedLoginsByIPKey)
+		return nil
+	}
+
+	data, err := json.Marshal(byIP)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal failed logins by ip")
+	}
+	secret.Data[usertypes.FailedLoginsByIPKey] = data
+
+	return nil
+}
+
+// pruneFailedLoginsByIP drops stale entries and keeps the most recent ones so that the secret stays small
+func pruneFailedLoginsByIP(byIP map[string]usertypes.FailedLogins, now time.Time) map[string]usertypes.FailedLogins {
+	pruned := map[string]usertypes.FailedLogins{}
+	ips := []string{}
+	for ip, failedLogins := range byIP {
+		if now.Sub(failedLogins.LastFailure) > failedLoginsByIPRetention {
+			continue
+		}
+		pruned[ip] = failedLogins
+		ips = append(ips, ip)
+	}
+
+	if len(ips) <= maxFailedLoginsByIP {
+		return pruned
+	}
+
+	sort.Slice(ips, func(i, j int) bool {
+		return pruned[ips[i]].LastFailure.After(pruned[ips[j]].LastFailure)
+	})
+	for _, ip := range ips[maxFailedLoginsByIP:] {
+		delete(pruned, ip)
+	}
+
+	return pruned
+}
+
 func (s *KOTSStore) flagSuccessfulLoginInDatabase() error {
 	db := persistence.MustGetDBSession()
 
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     `delete from kotsadm_params where key = ?`,
 		Arguments: []interface{}{"failed.login.count"},
 	})
@@ -234,7 +348,7 @@ func (s *KOTSStore) flagSuccessfulLoginInDatabase() error {
 		return fmt.Errorf("failed to delete failed login count: %v: %v", err, wr.Err)
 	}
 
-	wr, err = db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err = db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     `insert into kotsadm_params (key, value) values (?, ?)`,
 		Arguments: []interface{}{"failed.login.count", "0"},
 	})
@@ -33,7 +33,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/util"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	troubleshootpreflight "github.com/replicatedhq/troubleshoot/pkg/preflight"
-	"github.com/rqlite/gorqlite"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -44,7 +43,7 @@ import (
 func (s *KOTSStore) IsRollbackSupportedForVersion(appID string, sequence int64) (bool, error) {
 	db := persistence.MustGetDBSession()
 	query := `select kots_app_spec from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -55,7 +54,7 @@ func (s *KOTSStore) IsRollbackSupportedForVersion(appID string, sequence int64)
 		return false, nil
 	}
 
-	var kotsAppSpecStr gorqlite.NullString
+	var kotsAppSpecStr persistence.NullString
 	if err := rows.Scan(&kotsAppSpecStr); err != nil {
 		return false, errors.Wrap(err, "failed to scan")
 	}
@@ -75,7 +74,7 @@ func (s *KOTSStore) IsRollbackSupportedForVersion(appID string, sequence int64)
 func (s *KOTSStore) IsIdentityServiceSupportedForVersion(appID string, sequence int64) (bool, error) {
 	db := persistence.MustGetDBSession()
 	query := `select identity_spec from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -86,7 +85,7 @@ func (s *KOTSStore) IsIdentityServiceSupportedForVersion(appID string, sequence
 		return false, nil
 	}
 
-	var identitySpecStr gorqlite.NullString
+	var identitySpecStr persistence.NullString
 	if err := rows.Scan(&identitySpecStr); err != nil {
 		return false, errors.Wrap(err, "failed to scan")
 	}
@@ -97,7 +96,7 @@ func (s *KOTSStore) IsIdentityServiceSupportedForVersion(appID string, sequence
 func (s *KOTSStore) IsSnapshotsSupportedForVersion(a *apptypes.App, sequence int64, renderer rendertypes.Renderer) (bool, error) {
 	db := persistence.MustGetDBSession()
 	query := `select backup_spec from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{a.ID, sequence},
 	})
@@ -108,7 +107,7 @@ func (s *KOTSStore) IsSnapshotsSupportedForVersion(a *apptypes.App, sequence int
 		return false, nil
 	}
 
-	var backupSpecStr gorqlite.NullString
+	var backupSpecStr persistence.NullString
 	if err := rows.Scan(&backupSpecStr); err != nil {
 		return false, errors.Wrap(err, "failed to scan")
 	}
@@ -171,7 +170,7 @@ func (s *KOTSStore) IsSnapshotsSupportedForVersion(a *apptypes.App, sequence int
 func (s *KOTSStore) GetTargetKotsVersionForVersion(appID string, sequence int64) (string, error) {
 	db := persistence.MustGetDBSession()
 	query := `select kots_app_spec from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -182,7 +181,7 @@ func (s *KOTSStore) GetTargetKotsVersionForVersion(appID string, sequence int64)
 		return "", nil
 	}
 
-	var kotsAppSpecStr gorqlite.NullString
+	var kotsAppSpecStr persistence.NullString
 	if err := rows.Scan(&kotsAppSpecStr); err != nil {
 		return "", errors.Wrap(err, "failed to scan")
 	}
@@ -368,7 +367,7 @@ func (s *KOTSStore) GetAppVersionBaseArchive(appID string, versionLabel string)
 
 func (s *KOTSStore) CreatePendingDownloadAppVersion(appID string, update upstreamtypes.Update, kotsApplication *kotsv1beta1.Application, license *kotsv1beta1.License) (int64, error) {
 	db := persistence.MustGetDBSession()
-	statements := []gorqlite.ParameterizedStatement{}
+	statements := []persistence.ParameterizedStatement{}
 
 	a, err := s.GetApp(appID)
 	if err != nil {
@@ -481,7 +480,7 @@ func (s *KOTSStore) CreateAppVersion(appID string, baseSequence *int64, filesInD
 	return newSequence, nil
 }
 
-func (s *KOTSStore) createAppVersionStatements(appID string, baseSequence *int64, filesInDir string, source string, skipPreflights bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]gorqlite.ParameterizedStatement, int64, error) {
+func (s *KOTSStore) createAppVersionStatements(appID string, baseSequence *int64, filesInDir string, source string, skipPreflights bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]persistence.ParameterizedStatement, int64, error) {
 	newSequence, err := s.GetNextAppSequence(appID)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "failed to get next sequence number")
@@ -495,8 +494,8 @@ func (s *KOTSStore) createAppVersionStatements(appID string, baseSequence *int64
 	return appVersionStatements, newSequence, nil
 }
 
-func (s *KOTSStore) upsertAppVersionStatements(appID string, sequence int64, baseSequence *int64, filesInDir string, source string, skipPreflights bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]gorqlite.ParameterizedStatement, error) {
-	statements := []gorqlite.ParameterizedStatement{}
+func (s *KOTSStore) upsertAppVersionStatements(appID string, sequence int64, baseSequence *int64, filesInDir string, source string, skipPreflights bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) ([]persistence.ParameterizedStatement, error) {
+	statements := []persistence.ParameterizedStatement{}
 
 	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(filesInDir, "upstream"))
 	if err != nil {
@@ -634,7 +633,7 @@ func (s *KOTSStore) upsertAppVersionStatements(appID string, sequence int64, bas
 	return statements, nil
 }
 
-func (s *KOTSStore) createAppVersionRecordStatements(appID string, appName string, appIcon string, kotsKinds *kotsutil.KotsKinds, brandingArchive []byte) ([]gorqlite.ParameterizedStatement, int64, error) {
+func (s *KOTSStore) createAppVersionRecordStatements(appID string, appName string, appIcon string, kotsKinds *kotsutil.KotsKinds, brandingArchive []byte) ([]persistence.ParameterizedStatement, int64, error) {
 	newSequence, err := s.GetNextAppSequence(appID)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "failed to get next sequence number")
@@ -648,8 +647,8 @@ func (s *KOTSStore) createAppVersionRecordStatements(appID string, appName strin
 	return appVersionRecordStatements, newSequence, nil
 }
 
-func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int64, appName string, appIcon string, kotsKinds *kotsutil.KotsKinds, brandingArchive []byte) ([]gorqlite.ParameterizedStatement, error) {
-	statements := []gorqlite.ParameterizedStatement{}
+func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int64, appName string, appIcon string, kotsKinds *kotsutil.KotsKinds, brandingArchive []byte) ([]persistence.ParameterizedStatement, error) {
+	statements := []persistence.ParameterizedStatement{}
 
 	// we marshal these here because it's a decision of the store to cache them in the app version table
 	// not all stores will do this
@@ -733,7 +732,7 @@ func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int6
 		identity_spec = EXCLUDED.identity_spec,
 		branding_archive = EXCLUDED.branding_archive`
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			appID,
@@ -763,7 +762,7 @@ func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int6
 	})
 
 	// an old version could be downloaded at a later point, pick higher sequence
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query:     "update app set current_sequence = ifnull(max(current_sequence, ?), 0), name = ?, icon_uri = ? where id = ?",
 		Arguments: []interface{}{sequence, appName, appIcon, appID},
 	})
@@ -771,8 +770,8 @@ func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int6
 	return statements, nil
 }
 
-func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID string, sequence int64, versionLabel string, status types.DownstreamVersionStatus, source string, diffSummary string, diffSummaryError string, commitURL string, gitDeployable bool, preflightsSkipped bool) ([]gorqlite.ParameterizedStatement, error) {
-	statements := []gorqlite.ParameterizedStatement{}
+func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID string, sequence int64, versionLabel string, status types.DownstreamVersionStatus, source string, diffSummary string, diffSummaryError string, commitURL string, gitDeployable bool, preflightsSkipped bool) ([]persistence.ParameterizedStatement, error) {
+	statements := []persistence.ParameterizedStatement{}
 
 	query := `insert into app_downstream_version (app_id, cluster_id, sequence, parent_sequence, created_at, version_label, status, source, diff_summary, diff_summary_error, git_commit_url, git_deployable, preflight_skipped)
 		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
@@ -787,7 +786,7 @@ func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID
 		git_deployable = EXCLUDED.git_deployable,
 		preflight_skipped= EXCLUDED.preflight_skipped`
 
-	statements = append(statements, gorqlite.ParameterizedStatement{
+	statements = append(statements, persistence.ParameterizedStatement{
 		Query: query,
 		Arguments: []interface{}{
 			appID,
@@ -812,7 +811,7 @@ func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID
 func (s *KOTSStore) GetAppVersion(appID string, sequence int64) (*versiontypes.AppVersion, error) {
 	db := persistence.MustGetDBSession()
 	query := `select app_id, sequence, update_cursor, channel_id, version_label, created_at, status, applied_at, kots_installation_spec, kots_app_spec, kots_license from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -916,7 +915,7 @@ func (s *KOTSStore) UpdateNextAppVersionDiffSummary(appID string, baseSequence i
 	}
 
 	db := persistence.MustGetDBSession()
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     `update app_downstream_version set diff_summary = ?, diff_summary_error = ? where app_id = ? AND sequence = ?`,
 		Arguments: []interface{}{diffSummary, diffSummaryError, appID, nextSequence},
 	})
@@ -935,7 +934,7 @@ func (s *KOTSStore) UpdateAppVersionInstallationSpec(appID string, sequence int6
 	}
 
 	db := persistence.MustGetDBSession()
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
 		Query:     `UPDATE app_version SET kots_installation_spec = ? WHERE app_id = ? AND sequence = ?`,
 		Arguments: []interface{}{b.String(), appID, sequence},
 	})
@@ -945,10 +944,41 @@ func (s *KOTSStore) UpdateAppVersionInstallationSpec(appID string, sequence int6
 	return nil
 }
 
+// UpdateAppVersionArchive replaces the archive of an existing version and refreshes the columns cached from it that can hold encrypted data
+func (s *KOTSStore) UpdateAppVersionArchive(appID string, sequence int64, archivePath string) error {
+	kotsKinds, err := kotsutil.LoadKotsKindsFromPath(filepath.Join(archivePath, "upstream"))
+	if err != nil {
+		return errors.Wrap(err, "failed to read kots kinds")
+	}
+
+	kotsInstallationSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "Installation")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal kots installation spec")
+	}
+	configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal configvalues spec")
+	}
+
+	if err := s.CreateAppVersionArchive(appID, sequence, archivePath); err != nil {
+		return errors.Wrap(err, "failed to create app version archive")
+	}
+
+	db := persistence.MustGetDBSession()
+	wr, err := db.WriteOneParameterized(persistence.ParameterizedStatement{
+		Query:     `UPDATE app_version SET encryption_key = ?, kots_installation_spec = ?, config_values = ? WHERE app_id = ? AND sequence = ?`,
+		Arguments: []interface{}{kotsKinds.Installation.Spec.EncryptionKey, kotsInstallationSpec, configValuesSpec, appID, sequence},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+	return nil
+}
+
 func (s *KOTSStore) GetNextAppSequence(appID string) (int64, error) {
 	db := persistence.MustGetDBSession()
 
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     `select max(sequence) from app_version where app_id = ?`,
 		Arguments: []interface{}{appID},
 	})
@@ -959,7 +989,7 @@ func (s *KOTSStore) GetNextAppSequence(appID string) (int64, error) {
 		return 0, ErrNotFound
 	}
 
-	var maxSequence gorqlite.NullInt64
+	var maxSequence persistence.NullInt64
 	if err := rows.Scan(&maxSequence); err != nil {
 		return 0, errors.Wrap(err, "failed to find current max sequence in row")
 	}
@@ -977,7 +1007,7 @@ func (s *KOTSStore) GetCurrentUpdateCursor(appID string, channelID string) (stri
 	query := `SELECT update_cursor FROM app_version WHERE app_id = ? AND channel_id = ? AND sequence IN (
 		SELECT MAX(sequence) FROM app_version WHERE app_id = ? AND channel_id = ?
 	) ORDER BY sequence DESC LIMIT 1`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, channelID, appID, channelID},
 	})
@@ -988,7 +1018,7 @@ func (s *KOTSStore) GetCurrentUpdateCursor(appID string, channelID string) (stri
 		return "", nil
 	}
 
-	var updateCursor gorqlite.NullString
+	var updateCursor persistence.NullString
 	if err := rows.Scan(&updateCursor); err != nil {
 		return "", errors.Wrap(err, "failed to scan")
 	}
@@ -997,10 +1027,10 @@ func (s *KOTSStore) GetCurrentUpdateCursor(appID string, channelID string) (stri
 }
 
 func (s *KOTSStore) HasStrictPreflights(appID string, sequence int64) (bool, error) {
-	var preflightSpecStr gorqlite.NullString
+	var preflightSpecStr persistence.NullString
 	db := persistence.MustGetDBSession()
 	query := `SELECT preflight_spec FROM app_version WHERE app_id = ? AND sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -1074,18 +1104,18 @@ func (s *KOTSStore) renderApplicationSpec(appID string, appSlug string, sequence
 	return nil, nil
 }
 
-func (s *KOTSStore) appVersionFromRow(row gorqlite.QueryResult) (*versiontypes.AppVersion, error) {
+func (s *KOTSStore) appVersionFromRow(row persistence.QueryResult) (*versiontypes.AppVersion, error) {
 	v := &versiontypes.AppVersion{}
 
-	var status gorqlite.NullString
-	var deployedAt gorqlite.NullTime
-	var createdAt gorqlite.NullTime
-	var installationSpec gorqlite.NullString
-	var kotsAppSpec gorqlite.NullString
-	var licenseSpec gorqlite.NullString
-	var updateCursor gorqlite.NullString
-	var channelID gorqlite.NullString
-	var versionLabel gorqlite.NullString
+	var status persistence.NullString
+	var deployedAt persistence.NullTime
+	var createdAt persistence.NullTime
+	var installationSpec persistence.NullString
+	var kotsAppSpec persistence.NullString
+	var licenseSpec persistence.NullString
+	var updateCursor persistence.NullString
+	var channelID persistence.NullString
+	var versionLabel persistence.NullString
 
 	if err := row.Scan(&v.AppID, &v.Sequence, &updateCursor, &channelID, &versionLabel, &createdAt, &status, &createdAt, &installationSpec, &kotsAppSpec, &licenseSpec); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
@@ -15,19 +15,21 @@ import (
 	types0 "github.com/replicatedhq/kots/pkg/api/downstream/types"
 	types1 "github.com/replicatedhq/kots/pkg/api/reporting/types"
 	types2 "github.com/replicatedhq/kots/pkg/api/version/types"
-	types3 "github.com/replicatedhq/kots/pkg/app/types"
-	types4 "github.com/replicatedhq/kots/pkg/appstate/types"
-	types5 "github.com/replicatedhq/kots/pkg/gitops/types"
-	types6 "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
-	types7 "github.com/replicatedhq/kots/pkg/online/types"
-	types8 "github.com/replicatedhq/kots/pkg/preflight/types"
-	types9 "github.com/replicatedhq/kots/pkg/registry/types"
-	types10 "github.com/replicatedhq/kots/pkg/render/types"
-	types11 "github.com/replicatedhq/kots/pkg/session/types"
-	types12 "github.com/replicatedhq/kots/pkg/store/types"
-	types13 "github.com/replicatedhq/kots/pkg/supportbundle/types"
-	types14 "github.com/replicatedhq/kots/pkg/upstream/types"
-	types15 "github.com/replicatedhq/kots/pkg/user/types"
+	types3 "github.com/replicatedhq/kots/pkg/apitoken/types"
+	types4 "github.com/replicatedhq/kots/pkg/app/types"
+	types5 "github.com/replicatedhq/kots/pkg/appstate/types"
+	types6 "github.com/replicatedhq/kots/pkg/audit/types"
+	types7 "github.com/replicatedhq/kots/pkg/gitops/types"
+	types8 "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	types9 "github.com/replicatedhq/kots/pkg/online/types"
+	types10 "github.com/replicatedhq/kots/pkg/preflight/types"
+	types11 "github.com/replicatedhq/kots/pkg/registry/types"
+	types12 "github.com/replicatedhq/kots/pkg/render/types"
+	types13 "github.com/replicatedhq/kots/pkg/session/types"
+	types14 "github.com/replicatedhq/kots/pkg/store/types"
+	types15 "github.com/replicatedhq/kots/pkg/supportbundle/types"
+	types16 "github.com/replicatedhq/kots/pkg/upstream/types"
+	types17 "github.com/replicatedhq/kots/pkg/user/types"
 	redact "github.com/replicatedhq/troubleshoot/pkg/redact"
 )
 
@@ -110,11 +112,25 @@ func (mr *MockStoreMockRecorder) ClearTaskStatus(taskID interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearTaskStatus", reflect.TypeOf((*MockStore)(nil).ClearTaskStatus), taskID)
 }
 
+// CreateAPIToken mocks base method.
+func (m *MockStore) CreateAPIToken(token *types3.APIToken, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", token, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockStoreMockRecorder) CreateAPIToken(token, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockStore)(nil).CreateAPIToken), token, tokenHash)
+}
+
 // CreateApp mocks base method.
-func (m *MockStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush, registryIsReadOnly bool) (*types3.App, error) {
+func (m *MockStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush, registryIsReadOnly bool) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateApp", name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush, registryIsReadOnly)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -126,7 +142,7 @@ func (mr *MockStoreMockRecorder) CreateApp(name, upstreamURI, licenseData, isAir
 }
 
 // CreateAppVersion mocks base method.
-func (m *MockStore) CreateAppVersion(appID string, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) (int64, error) {
+func (m *MockStore) CreateAppVersion(appID string, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateAppVersion", appID, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -154,8 +170,22 @@ func (mr *MockStoreMockRecorder) CreateAppVersionArchive(appID, sequence, archiv
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppVersionArchive", reflect.TypeOf((*MockStore)(nil).CreateAppVersionArchive), appID, sequence, archivePath)
 }
 
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(event *types6.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), event)
+}
+
 // CreateInProgressSupportBundle mocks base method.
-func (m *MockStore) CreateInProgressSupportBundle(supportBundle *types13.SupportBundle) error {
+func (m *MockStore) CreateInProgressSupportBundle(supportBundle *types15.SupportBundle) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateInProgressSupportBundle", supportBundle)
 	ret0, _ := ret[0].(error)
@@ -199,7 +229,7 @@ func (mr *MockStoreMockRecorder) CreateNewCluster(userID, isAllUsers, title, tok
 }
 
 // CreatePendingDownloadAppVersion mocks base method.
-func (m *MockStore) CreatePendingDownloadAppVersion(appID string, update types14.Update, kotsApplication *v1beta1.Application, license *v1beta1.License) (int64, error) {
+func (m *MockStore) CreatePendingDownloadAppVersion(appID string, update types16.Update, kotsApplication *v1beta1.Application, license *v1beta1.License) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePendingDownloadAppVersion", appID, update, kotsApplication, license)
 	ret0, _ := ret[0].(int64)
@@ -227,6 +257,20 @@ func (mr *MockStoreMockRecorder) CreateScheduledInstanceSnapshot(snapshotID, clu
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledInstanceSnapshot", reflect.TypeOf((*MockStore)(nil).CreateScheduledInstanceSnapshot), snapshotID, clusterID, timestamp)
 }
 
+// CreateScheduledRestoreDrill mocks base method.
+func (m *MockStore) CreateScheduledRestoreDrill(drillID, clusterID string, timestamp time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledRestoreDrill", drillID, clusterID, timestamp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateScheduledRestoreDrill indicates an expected call of CreateScheduledRestoreDrill.
+func (mr *MockStoreMockRecorder) CreateScheduledRestoreDrill(drillID, clusterID, timestamp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledRestoreDrill", reflect.TypeOf((*MockStore)(nil).CreateScheduledRestoreDrill), drillID, clusterID, timestamp)
+}
+
 // CreateScheduledSnapshot mocks base method.
 func (m *MockStore) CreateScheduledSnapshot(snapshotID, appID string, timestamp time.Time) error {
 	m.ctrl.T.Helper()
@@ -242,10 +286,10 @@ func (mr *MockStoreMockRecorder) CreateScheduledSnapshot(snapshotID, appID, time
 }
 
 // CreateSession mocks base method.
-func (m *MockStore) CreateSession(user *types15.User, issuedAt, expiresAt time.Time, roles []string) (*types11.Session, error) {
+func (m *MockStore) CreateSession(user *types17.User, issuedAt, expiresAt time.Time, roles []string) (*types13.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", user, issuedAt, expiresAt, roles)
-	ret0, _ := ret[0].(*types11.Session)
+	ret0, _ := ret[0].(*types13.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -257,10 +301,10 @@ func (mr *MockStoreMockRecorder) CreateSession(user, issuedAt, expiresAt, roles
 }
 
 // CreateSupportBundle mocks base method.
-func (m *MockStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types13.SupportBundle, error) {
+func (m *MockStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSupportBundle", bundleID, appID, archivePath, marshalledTree)
-	ret0, _ := ret[0].(*types13.SupportBundle)
+	ret0, _ := ret[0].(*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -313,6 +357,20 @@ func (mr *MockStoreMockRecorder) DeletePendingScheduledInstanceSnapshots(cluster
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledInstanceSnapshots", reflect.TypeOf((*MockStore)(nil).DeletePendingScheduledInstanceSnapshots), clusterID)
 }
 
+// DeletePendingScheduledRestoreDrills mocks base method.
+func (m *MockStore) DeletePendingScheduledRestoreDrills(clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingScheduledRestoreDrills", clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingScheduledRestoreDrills indicates an expected call of DeletePendingScheduledRestoreDrills.
+func (mr *MockStoreMockRecorder) DeletePendingScheduledRestoreDrills(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledRestoreDrills", reflect.TypeOf((*MockStore)(nil).DeletePendingScheduledRestoreDrills), clusterID)
+}
+
 // DeletePendingScheduledSnapshots mocks base method.
 func (m *MockStore) DeletePendingScheduledSnapshots(appID string) error {
 	m.ctrl.T.Helper()
@@ -371,31 +429,47 @@ func (mr *MockStoreMockRecorder) FindDownstreamVersions(appID, downloadedOnly in
 }
 
 // FlagInvalidPassword mocks base method.
-func (m *MockStore) FlagInvalidPassword() error {
+func (m *MockStore) FlagInvalidPassword(clientIP string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FlagInvalidPassword")
+	ret := m.ctrl.Call(m, "FlagInvalidPassword", clientIP)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FlagInvalidPassword indicates an expected call of FlagInvalidPassword.
-func (mr *MockStoreMockRecorder) FlagInvalidPassword() *gomock.Call {
+func (mr *MockStoreMockRecorder) FlagInvalidPassword(clientIP interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagInvalidPassword", reflect.TypeOf((*MockStore)(nil).FlagInvalidPassword))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagInvalidPassword", reflect.TypeOf((*MockStore)(nil).FlagInvalidPassword), clientIP)
 }
 
 // FlagSuccessfulLogin mocks base method.
-func (m *MockStore) FlagSuccessfulLogin() error {
+func (m *MockStore) FlagSuccessfulLogin(clientIP string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FlagSuccessfulLogin")
+	ret := m.ctrl.Call(m, "FlagSuccessfulLogin", clientIP)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FlagSuccessfulLogin indicates an expected call of FlagSuccessfulLogin.
-func (mr *MockStoreMockRecorder) FlagSuccessfulLogin() *gomock.Call {
+func (mr *MockStoreMockRecorder) FlagSuccessfulLogin(clientIP interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSuccessfulLogin", reflect.TypeOf((*MockStore)(nil).FlagSuccessfulLogin))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSuccessfulLogin", reflect.TypeOf((*MockStore)(nil).FlagSuccessfulLogin), clientIP)
+}
+
+// GetAPIToken mocks base method.
+func (m *MockStore) GetAPIToken(id string) (*types3.APIToken, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", id)
+	ret0, _ := ret[0].(*types3.APIToken)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockStoreMockRecorder) GetAPIToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockStore)(nil).GetAPIToken), id)
 }
 
 // GetAirgapInstallStatus mocks base method.
@@ -429,10 +503,10 @@ func (mr *MockStoreMockRecorder) GetAllAppLicenses() *gomock.Call {
 }
 
 // GetApp mocks base method.
-func (m *MockStore) GetApp(appID string) (*types3.App, error) {
+func (m *MockStore) GetApp(appID string) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetApp", appID)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -444,10 +518,10 @@ func (mr *MockStoreMockRecorder) GetApp(appID interface{}) *gomock.Call {
 }
 
 // GetAppFromSlug mocks base method.
-func (m *MockStore) GetAppFromSlug(slug string) (*types3.App, error) {
+func (m *MockStore) GetAppFromSlug(slug string) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppFromSlug", slug)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -489,10 +563,10 @@ func (mr *MockStoreMockRecorder) GetAppIDsFromRegistry(hostname interface{}) *go
 }
 
 // GetAppStatus mocks base method.
-func (m *MockStore) GetAppStatus(appID string) (*types4.AppStatus, error) {
+func (m *MockStore) GetAppStatus(appID string) (*types5.AppStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppStatus", appID)
-	ret0, _ := ret[0].(*types4.AppStatus)
+	ret0, _ := ret[0].(*types5.AppStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -714,10 +788,10 @@ func (mr *MockStoreMockRecorder) GetDownstreamVersionSource(appID, sequence inte
 }
 
 // GetDownstreamVersionStatus mocks base method.
-func (m *MockStore) GetDownstreamVersionStatus(appID string, sequence int64) (types12.DownstreamVersionStatus, error) {
+func (m *MockStore) GetDownstreamVersionStatus(appID string, sequence int64) (types14.DownstreamVersionStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetDownstreamVersionStatus", appID, sequence)
-	ret0, _ := ret[0].(types12.DownstreamVersionStatus)
+	ret0, _ := ret[0].(types14.DownstreamVersionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -880,6 +954,21 @@ func (mr *MockStoreMockRecorder) GetLicenseForAppVersion(appID, sequence interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLicenseForAppVersion", reflect.TypeOf((*MockStore)(nil).GetLicenseForAppVersion), appID, sequence)
 }
 
+// GetLoginAttempts mocks base method.
+func (m *MockStore) GetLoginAttempts() (*types17.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts")
+	ret0, _ := ret[0].(*types17.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockStoreMockRecorder) GetLoginAttempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockStore)(nil).GetLoginAttempts))
+}
+
 // GetNextAppSequence mocks base method.
 func (m *MockStore) GetNextAppSequence(appID string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -941,10 +1030,10 @@ func (mr *MockStoreMockRecorder) GetPendingAirgapUploadApp() *gomock.Call {
 }
 
 // GetPendingInstallationStatus mocks base method.
-func (m *MockStore) GetPendingInstallationStatus() (*types7.InstallStatus, error) {
+func (m *MockStore) GetPendingInstallationStatus() (*types9.InstallStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPendingInstallationStatus")
-	ret0, _ := ret[0].(*types7.InstallStatus)
+	ret0, _ := ret[0].(*types9.InstallStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -971,10 +1060,10 @@ func (mr *MockStoreMockRecorder) GetPreflightProgress(appID, sequence interface{
 }
 
 // GetPreflightResults mocks base method.
-func (m *MockStore) GetPreflightResults(appID string, sequence int64) (*types8.PreflightResult, error) {
+func (m *MockStore) GetPreflightResults(appID string, sequence int64) (*types10.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPreflightResults", appID, sequence)
-	ret0, _ := ret[0].(*types8.PreflightResult)
+	ret0, _ := ret[0].(*types10.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -985,6 +1074,21 @@ func (mr *MockStoreMockRecorder) GetPreflightResults(appID, sequence interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResults", reflect.TypeOf((*MockStore)(nil).GetPreflightResults), appID, sequence)
 }
 
+// GetPreflightResultsHistory mocks base method.
+func (m *MockStore) GetPreflightResultsHistory(appID string, sequence *int64) ([]types10.PreflightRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreflightResultsHistory", appID, sequence)
+	ret0, _ := ret[0].([]types10.PreflightRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreflightResultsHistory indicates an expected call of GetPreflightResultsHistory.
+func (mr *MockStoreMockRecorder) GetPreflightResultsHistory(appID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResultsHistory", reflect.TypeOf((*MockStore)(nil).GetPreflightResultsHistory), appID, sequence)
+}
+
 // GetPreviouslyDeployedSequence mocks base method.
 func (m *MockStore) GetPreviouslyDeployedSequence(appID, clusterID string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -1031,10 +1135,10 @@ func (mr *MockStoreMockRecorder) GetRedactions(bundleID interface{}) *gomock.Cal
 }
 
 // GetRegistryDetailsForApp mocks base method.
-func (m *MockStore) GetRegistryDetailsForApp(appID string) (types9.RegistrySettings, error) {
+func (m *MockStore) GetRegistryDetailsForApp(appID string) (types11.RegistrySettings, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRegistryDetailsForApp", appID)
-	ret0, _ := ret[0].(types9.RegistrySettings)
+	ret0, _ := ret[0].(types11.RegistrySettings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1046,10 +1150,10 @@ func (mr *MockStoreMockRecorder) GetRegistryDetailsForApp(appID interface{}) *go
 }
 
 // GetSession mocks base method.
-func (m *MockStore) GetSession(sessionID string) (*types11.Session, error) {
+func (m *MockStore) GetSession(sessionID string) (*types13.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", sessionID)
-	ret0, _ := ret[0].(*types11.Session)
+	ret0, _ := ret[0].(*types13.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1076,10 +1180,10 @@ func (mr *MockStoreMockRecorder) GetSharedPasswordBcrypt() *gomock.Call {
 }
 
 // GetStatusForVersion mocks base method.
-func (m *MockStore) GetStatusForVersion(appID, clusterID string, sequence int64) (types12.DownstreamVersionStatus, error) {
+func (m *MockStore) GetStatusForVersion(appID, clusterID string, sequence int64) (types14.DownstreamVersionStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetStatusForVersion", appID, clusterID, sequence)
-	ret0, _ := ret[0].(types12.DownstreamVersionStatus)
+	ret0, _ := ret[0].(types14.DownstreamVersionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1091,10 +1195,10 @@ func (mr *MockStoreMockRecorder) GetStatusForVersion(appID, clusterID, sequence
 }
 
 // GetSupportBundle mocks base method.
-func (m *MockStore) GetSupportBundle(bundleID string) (*types13.SupportBundle, error) {
+func (m *MockStore) GetSupportBundle(bundleID string) (*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundle", bundleID)
-	ret0, _ := ret[0].(*types13.SupportBundle)
+	ret0, _ := ret[0].(*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1106,10 +1210,10 @@ func (mr *MockStoreMockRecorder) GetSupportBundle(bundleID interface{}) *gomock.
 }
 
 // GetSupportBundleAnalysis mocks base method.
-func (m *MockStore) GetSupportBundleAnalysis(bundleID string) (*types13.SupportBundleAnalysis, error) {
+func (m *MockStore) GetSupportBundleAnalysis(bundleID string) (*types15.SupportBundleAnalysis, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleAnalysis", bundleID)
-	ret0, _ := ret[0].(*types13.SupportBundleAnalysis)
+	ret0, _ := ret[0].(*types15.SupportBundleAnalysis)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1301,7 +1405,7 @@ func (mr *MockStoreMockRecorder) IsRollbackSupportedForVersion(appID, sequence i
 }
 
 // IsSnapshotsSupportedForVersion mocks base method.
-func (m *MockStore) IsSnapshotsSupportedForVersion(a *types3.App, sequence int64, renderer types10.Renderer) (bool, error) {
+func (m *MockStore) IsSnapshotsSupportedForVersion(a *types4.App, sequence int64, renderer types12.Renderer) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsSnapshotsSupportedForVersion", a, sequence, renderer)
 	ret0, _ := ret[0].(bool)
@@ -1315,11 +1419,26 @@ func (mr *MockStoreMockRecorder) IsSnapshotsSupportedForVersion(a, sequence, ren
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSnapshotsSupportedForVersion", reflect.TypeOf((*MockStore)(nil).IsSnapshotsSupportedForVersion), a, sequence, renderer)
 }
 
+// ListAPITokens mocks base method.
+func (m *MockStore) ListAPITokens() ([]types3.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPITokens")
+	ret0, _ := ret[0].([]types3.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPITokens indicates an expected call of ListAPITokens.
+func (mr *MockStoreMockRecorder) ListAPITokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokens", reflect.TypeOf((*MockStore)(nil).ListAPITokens))
+}
+
 // ListAppsForDownstream mocks base method.
-func (m *MockStore) ListAppsForDownstream(clusterID string) ([]*types3.App, error) {
+func (m *MockStore) ListAppsForDownstream(clusterID string) ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListAppsForDownstream", clusterID)
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1330,6 +1449,22 @@ func (mr *MockStoreMockRecorder) ListAppsForDownstream(clusterID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsForDownstream", reflect.TypeOf((*MockStore)(nil).ListAppsForDownstream), clusterID)
 }
 
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(opts types6.ListOptions) ([]types6.Event, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", opts)
+	ret0, _ := ret[0].([]types6.Event)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), opts)
+}
+
 // ListClusters mocks base method.
 func (m *MockStore) ListClusters() ([]*types0.Downstream, error) {
 	m.ctrl.T.Helper()
@@ -1361,10 +1496,10 @@ func (mr *MockStoreMockRecorder) ListDownstreamsForApp(appID interface{}) *gomoc
 }
 
 // ListFailedApps mocks base method.
-func (m *MockStore) ListFailedApps() ([]*types3.App, error) {
+func (m *MockStore) ListFailedApps() ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListFailedApps")
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1391,10 +1526,10 @@ func (mr *MockStoreMockRecorder) ListInstalledAppSlugs() *gomock.Call {
 }
 
 // ListInstalledApps mocks base method.
-func (m *MockStore) ListInstalledApps() ([]*types3.App, error) {
+func (m *MockStore) ListInstalledApps() ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListInstalledApps")
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1406,10 +1541,10 @@ func (mr *MockStoreMockRecorder) ListInstalledApps() *gomock.Call {
 }
 
 // ListPendingScheduledInstanceSnapshots mocks base method.
-func (m *MockStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types6.ScheduledInstanceSnapshot, error) {
+func (m *MockStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types8.ScheduledInstanceSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledInstanceSnapshots", clusterID)
-	ret0, _ := ret[0].([]types6.ScheduledInstanceSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledInstanceSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1420,11 +1555,26 @@ func (mr *MockStoreMockRecorder) ListPendingScheduledInstanceSnapshots(clusterID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledInstanceSnapshots", reflect.TypeOf((*MockStore)(nil).ListPendingScheduledInstanceSnapshots), clusterID)
 }
 
+// ListPendingScheduledRestoreDrills mocks base method.
+func (m *MockStore) ListPendingScheduledRestoreDrills(clusterID string) ([]types8.ScheduledRestoreDrill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingScheduledRestoreDrills", clusterID)
+	ret0, _ := ret[0].([]types8.ScheduledRestoreDrill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingScheduledRestoreDrills indicates an expected call of ListPendingScheduledRestoreDrills.
+func (mr *MockStoreMockRecorder) ListPendingScheduledRestoreDrills(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledRestoreDrills", reflect.TypeOf((*MockStore)(nil).ListPendingScheduledRestoreDrills), clusterID)
+}
+
 // ListPendingScheduledSnapshots mocks base method.
-func (m *MockStore) ListPendingScheduledSnapshots(appID string) ([]types6.ScheduledSnapshot, error) {
+func (m *MockStore) ListPendingScheduledSnapshots(appID string) ([]types8.ScheduledSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledSnapshots", appID)
-	ret0, _ := ret[0].([]types6.ScheduledSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1436,10 +1586,10 @@ func (mr *MockStoreMockRecorder) ListPendingScheduledSnapshots(appID interface{}
 }
 
 // ListSupportBundles mocks base method.
-func (m *MockStore) ListSupportBundles(appID string) ([]*types13.SupportBundle, error) {
+func (m *MockStore) ListSupportBundles(appID string) ([]*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSupportBundles", appID)
-	ret0, _ := ret[0].([]*types13.SupportBundle)
+	ret0, _ := ret[0].([]*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1506,6 +1656,20 @@ func (mr *MockStoreMockRecorder) ResetPreflightResults(appID, sequence interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPreflightResults", reflect.TypeOf((*MockStore)(nil).ResetPreflightResults), appID, sequence)
 }
 
+// RevokeAPIToken mocks base method.
+func (m *MockStore) RevokeAPIToken(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIToken", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIToken indicates an expected call of RevokeAPIToken.
+func (mr *MockStoreMockRecorder) RevokeAPIToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIToken", reflect.TypeOf((*MockStore)(nil).RevokeAPIToken), id)
+}
+
 // RunMigrations mocks base method.
 func (m *MockStore) RunMigrations() {
 	m.ctrl.T.Helper()
@@ -1589,7 +1753,7 @@ func (mr *MockStoreMockRecorder) SetAppIsAirgap(appID, isAirgap interface{}) *go
 }
 
 // SetAppStatus mocks base method.
-func (m *MockStore) SetAppStatus(appID string, resourceStates types4.ResourceStates, updatedAt time.Time, sequence int64) error {
+func (m *MockStore) SetAppStatus(appID string, resourceStates types5.ResourceStates, updatedAt time.Time, sequence int64) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetAppStatus", appID, resourceStates, updatedAt, sequence)
 	ret0, _ := ret[0].(error)
@@ -1603,7 +1767,7 @@ func (mr *MockStoreMockRecorder) SetAppStatus(appID, resourceStates, updatedAt,
 }
 
 // SetAutoDeploy mocks base method.
-func (m *MockStore) SetAutoDeploy(appID string, autoDeploy types3.AutoDeploy) error {
+func (m *MockStore) SetAutoDeploy(appID string, autoDeploy types4.AutoDeploy) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetAutoDeploy", appID, autoDeploy)
 	ret0, _ := ret[0].(error)
@@ -1616,8 +1780,50 @@ func (mr *MockStoreMockRecorder) SetAutoDeploy(appID, autoDeploy interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeploy", reflect.TypeOf((*MockStore)(nil).SetAutoDeploy), appID, autoDeploy)
 }
 
+// SetAutoDeployWindow mocks base method.
+func (m *MockStore) SetAutoDeployWindow(appID string, window *types4.MaintenanceWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAutoDeployWindow", appID, window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAutoDeployWindow indicates an expected call of SetAutoDeployWindow.
+func (mr *MockStoreMockRecorder) SetAutoDeployWindow(appID, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeployWindow", reflect.TypeOf((*MockStore)(nil).SetAutoDeployWindow), appID, window)
+}
+
+// SetAutoRollbackPolicy mocks base method.
+func (m *MockStore) SetAutoRollbackPolicy(appID string, policy *types4.AutoRollbackPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAutoRollbackPolicy", appID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAutoRollbackPolicy indicates an expected call of SetAutoRollbackPolicy.
+func (mr *MockStoreMockRecorder) SetAutoRollbackPolicy(appID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoRollbackPolicy", reflect.TypeOf((*MockStore)(nil).SetAutoRollbackPolicy), appID, policy)
+}
+
+// SetDeployHealthMonitor mocks base method.
+func (m *MockStore) SetDeployHealthMonitor(appID string, monitor *types4.DeployHealthMonitor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeployHealthMonitor", appID, monitor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeployHealthMonitor indicates an expected call of SetDeployHealthMonitor.
+func (mr *MockStoreMockRecorder) SetDeployHealthMonitor(appID, monitor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeployHealthMonitor", reflect.TypeOf((*MockStore)(nil).SetDeployHealthMonitor), appID, monitor)
+}
+
 // SetDownstreamVersionStatus mocks base method.
-func (m *MockStore) SetDownstreamVersionStatus(appID string, sequence int64, status types12.DownstreamVersionStatus, statusInfo string) error {
+func (m *MockStore) SetDownstreamVersionStatus(appID string, sequence int64, status types14.DownstreamVersionStatus, statusInfo string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetDownstreamVersionStatus", appID, sequence, status, statusInfo)
 	ret0, _ := ret[0].(error)
@@ -1658,6 +1864,34 @@ func (mr *MockStoreMockRecorder) SetIgnorePreflightPermissionErrors(appID, seque
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIgnorePreflightPermissionErrors", reflect.TypeOf((*MockStore)(nil).SetIgnorePreflightPermissionErrors), appID, sequence)
 }
 
+// SetInstanceRestoreDrillSchedule mocks base method.
+func (m *MockStore) SetInstanceRestoreDrillSchedule(clusterID, restoreDrillSchedule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceRestoreDrillSchedule", clusterID, restoreDrillSchedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceRestoreDrillSchedule indicates an expected call of SetInstanceRestoreDrillSchedule.
+func (mr *MockStoreMockRecorder) SetInstanceRestoreDrillSchedule(clusterID, restoreDrillSchedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceRestoreDrillSchedule", reflect.TypeOf((*MockStore)(nil).SetInstanceRestoreDrillSchedule), clusterID, restoreDrillSchedule)
+}
+
+// SetInstanceSnapshotRetention mocks base method.
+func (m *MockStore) SetInstanceSnapshotRetention(clusterID, retention string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotRetention", clusterID, retention)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotRetention indicates an expected call of SetInstanceSnapshotRetention.
+func (mr *MockStoreMockRecorder) SetInstanceSnapshotRetention(clusterID, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotRetention", reflect.TypeOf((*MockStore)(nil).SetInstanceSnapshotRetention), clusterID, retention)
+}
+
 // SetInstanceSnapshotSchedule mocks base method.
 func (m *MockStore) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule string) error {
 	m.ctrl.T.Helper()
@@ -1672,6 +1906,20 @@ func (mr *MockStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, snapshot
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
 }
 
+// SetInstanceSnapshotStorageLocation mocks base method.
+func (m *MockStore) SetInstanceSnapshotStorageLocation(clusterID, storageLocation string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotStorageLocation", clusterID, storageLocation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotStorageLocation indicates an expected call of SetInstanceSnapshotStorageLocation.
+func (mr *MockStoreMockRecorder) SetInstanceSnapshotStorageLocation(clusterID, storageLocation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotStorageLocation", reflect.TypeOf((*MockStore)(nil).SetInstanceSnapshotStorageLocation), clusterID, storageLocation)
+}
+
 // SetInstanceSnapshotTTL mocks base method.
 func (m *MockStore) SetInstanceSnapshotTTL(clusterID, snapshotTTL string) error {
 	m.ctrl.T.Helper()
@@ -1715,17 +1963,17 @@ func (mr *MockStoreMockRecorder) SetPreflightProgress(appID, sequence, progress
 }
 
 // SetPreflightResults mocks base method.
-func (m *MockStore) SetPreflightResults(appID string, sequence int64, results []byte) error {
+func (m *MockStore) SetPreflightResults(appID string, sequence int64, results []byte, trigger types10.PreflightTrigger) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPreflightResults", appID, sequence, results)
+	ret := m.ctrl.Call(m, "SetPreflightResults", appID, sequence, results, trigger)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetPreflightResults indicates an expected call of SetPreflightResults.
-func (mr *MockStoreMockRecorder) SetPreflightResults(appID, sequence, results interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) SetPreflightResults(appID, sequence, results, trigger interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreflightResults", reflect.TypeOf((*MockStore)(nil).SetPreflightResults), appID, sequence, results)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreflightResults", reflect.TypeOf((*MockStore)(nil).SetPreflightResults), appID, sequence, results, trigger)
 }
 
 // SetPrometheusAddress mocks base method.
@@ -1798,6 +2046,20 @@ func (mr *MockStoreMockRecorder) SetSupportBundleAnalysis(bundleID, insights int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSupportBundleAnalysis", reflect.TypeOf((*MockStore)(nil).SetSupportBundleAnalysis), bundleID, insights)
 }
 
+// SetSupportBundleSchedule mocks base method.
+func (m *MockStore) SetSupportBundleSchedule(appID string, schedule *types4.SupportBundleSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSupportBundleSchedule", appID, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSupportBundleSchedule indicates an expected call of SetSupportBundleSchedule.
+func (mr *MockStoreMockRecorder) SetSupportBundleSchedule(appID, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSupportBundleSchedule", reflect.TypeOf((*MockStore)(nil).SetSupportBundleSchedule), appID, schedule)
+}
+
 // SetTaskStatus mocks base method.
 func (m *MockStore) SetTaskStatus(taskID, message, status string) error {
 	m.ctrl.T.Helper()
@@ -1826,8 +2088,22 @@ func (mr *MockStoreMockRecorder) SetUpdateCheckerSpec(appID, updateCheckerSpec i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUpdateCheckerSpec", reflect.TypeOf((*MockStore)(nil).SetUpdateCheckerSpec), appID, updateCheckerSpec)
 }
 
+// UpdateAPITokenLastUsedAt mocks base method.
+func (m *MockStore) UpdateAPITokenLastUsedAt(id string, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPITokenLastUsedAt", id, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPITokenLastUsedAt indicates an expected call of UpdateAPITokenLastUsedAt.
+func (mr *MockStoreMockRecorder) UpdateAPITokenLastUsedAt(id, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPITokenLastUsedAt", reflect.TypeOf((*MockStore)(nil).UpdateAPITokenLastUsedAt), id, lastUsedAt)
+}
+
 // UpdateAppLicense mocks base method.
-func (m *MockStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, channelChanged, failOnVersionCreate bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) (int64, error) {
+func (m *MockStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, channelChanged, failOnVersionCreate bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppLicense", appID, sequence, archiveDir, newLicense, originalLicenseData, channelChanged, failOnVersionCreate, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -1856,7 +2132,7 @@ func (mr *MockStoreMockRecorder) UpdateAppLicenseSyncNow(appID interface{}) *gom
 }
 
 // UpdateAppVersion mocks base method.
-func (m *MockStore) UpdateAppVersion(appID string, sequence int64, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) error {
+func (m *MockStore) UpdateAppVersion(appID string, sequence int64, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppVersion", appID, sequence, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 	ret0, _ := ret[0].(error)
@@ -1869,6 +2145,20 @@ func (mr *MockStoreMockRecorder) UpdateAppVersion(appID, sequence, baseSequence,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersion", reflect.TypeOf((*MockStore)(nil).UpdateAppVersion), appID, sequence, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 }
 
+// UpdateAppVersionArchive mocks base method.
+func (m *MockStore) UpdateAppVersionArchive(appID string, sequence int64, archivePath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAppVersionArchive", appID, sequence, archivePath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAppVersionArchive indicates an expected call of UpdateAppVersionArchive.
+func (mr *MockStoreMockRecorder) UpdateAppVersionArchive(appID, sequence, archivePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersionArchive", reflect.TypeOf((*MockStore)(nil).UpdateAppVersionArchive), appID, sequence, archivePath)
+}
+
 // UpdateAppVersionInstallationSpec mocks base method.
 func (m *MockStore) UpdateAppVersionInstallationSpec(appID string, sequence int64, spec v1beta1.Installation) error {
 	m.ctrl.T.Helper()
@@ -1939,6 +2229,20 @@ func (mr *MockStoreMockRecorder) UpdateScheduledInstanceSnapshot(snapshotID, bac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledInstanceSnapshot", reflect.TypeOf((*MockStore)(nil).UpdateScheduledInstanceSnapshot), snapshotID, backupName)
 }
 
+// UpdateScheduledRestoreDrill mocks base method.
+func (m *MockStore) UpdateScheduledRestoreDrill(drillID, backupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledRestoreDrill", drillID, backupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScheduledRestoreDrill indicates an expected call of UpdateScheduledRestoreDrill.
+func (mr *MockStoreMockRecorder) UpdateScheduledRestoreDrill(drillID, backupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledRestoreDrill", reflect.TypeOf((*MockStore)(nil).UpdateScheduledRestoreDrill), drillID, backupName)
+}
+
 // UpdateScheduledSnapshot mocks base method.
 func (m *MockStore) UpdateScheduledSnapshot(snapshotID, backupName string) error {
 	m.ctrl.T.Helper()
@@ -1968,7 +2272,7 @@ func (mr *MockStoreMockRecorder) UpdateSessionExpiresAt(sessionID, expiresAt int
 }
 
 // UpdateSupportBundle mocks base method.
-func (m *MockStore) UpdateSupportBundle(bundle *types13.SupportBundle) error {
+func (m *MockStore) UpdateSupportBundle(bundle *types15.SupportBundle) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateSupportBundle", bundle)
 	ret0, _ := ret[0].(error)
@@ -2097,10 +2401,10 @@ func (mr *MockRegistryStoreMockRecorder) GetAppIDsFromRegistry(hostname interfac
 }
 
 // GetRegistryDetailsForApp mocks base method.
-func (m *MockRegistryStore) GetRegistryDetailsForApp(appID string) (types9.RegistrySettings, error) {
+func (m *MockRegistryStore) GetRegistryDetailsForApp(appID string) (types11.RegistrySettings, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRegistryDetailsForApp", appID)
-	ret0, _ := ret[0].(types9.RegistrySettings)
+	ret0, _ := ret[0].(types11.RegistrySettings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2149,7 +2453,7 @@ func (m *MockSupportBundleStore) EXPECT() *MockSupportBundleStoreMockRecorder {
 }
 
 // CreateInProgressSupportBundle mocks base method.
-func (m *MockSupportBundleStore) CreateInProgressSupportBundle(supportBundle *types13.SupportBundle) error {
+func (m *MockSupportBundleStore) CreateInProgressSupportBundle(supportBundle *types15.SupportBundle) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateInProgressSupportBundle", supportBundle)
 	ret0, _ := ret[0].(error)
@@ -2163,10 +2467,10 @@ func (mr *MockSupportBundleStoreMockRecorder) CreateInProgressSupportBundle(supp
 }
 
 // CreateSupportBundle mocks base method.
-func (m *MockSupportBundleStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types13.SupportBundle, error) {
+func (m *MockSupportBundleStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSupportBundle", bundleID, appID, archivePath, marshalledTree)
-	ret0, _ := ret[0].(*types13.SupportBundle)
+	ret0, _ := ret[0].(*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2207,10 +2511,10 @@ func (mr *MockSupportBundleStoreMockRecorder) GetRedactions(bundleID interface{}
 }
 
 // GetSupportBundle mocks base method.
-func (m *MockSupportBundleStore) GetSupportBundle(bundleID string) (*types13.SupportBundle, error) {
+func (m *MockSupportBundleStore) GetSupportBundle(bundleID string) (*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundle", bundleID)
-	ret0, _ := ret[0].(*types13.SupportBundle)
+	ret0, _ := ret[0].(*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2222,10 +2526,10 @@ func (mr *MockSupportBundleStoreMockRecorder) GetSupportBundle(bundleID interfac
 }
 
 // GetSupportBundleAnalysis mocks base method.
-func (m *MockSupportBundleStore) GetSupportBundleAnalysis(bundleID string) (*types13.SupportBundleAnalysis, error) {
+func (m *MockSupportBundleStore) GetSupportBundleAnalysis(bundleID string) (*types15.SupportBundleAnalysis, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleAnalysis", bundleID)
-	ret0, _ := ret[0].(*types13.SupportBundleAnalysis)
+	ret0, _ := ret[0].(*types15.SupportBundleAnalysis)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2252,10 +2556,10 @@ func (mr *MockSupportBundleStoreMockRecorder) GetSupportBundleArchive(bundleID i
 }
 
 // ListSupportBundles mocks base method.
-func (m *MockSupportBundleStore) ListSupportBundles(appID string) ([]*types13.SupportBundle, error) {
+func (m *MockSupportBundleStore) ListSupportBundles(appID string) ([]*types15.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSupportBundles", appID)
-	ret0, _ := ret[0].([]*types13.SupportBundle)
+	ret0, _ := ret[0].([]*types15.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2295,7 +2599,7 @@ func (mr *MockSupportBundleStoreMockRecorder) SetSupportBundleAnalysis(bundleID,
 }
 
 // UpdateSupportBundle mocks base method.
-func (m *MockSupportBundleStore) UpdateSupportBundle(bundle *types13.SupportBundle) error {
+func (m *MockSupportBundleStore) UpdateSupportBundle(bundle *types15.SupportBundle) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateSupportBundle", bundle)
 	ret0, _ := ret[0].(error)
@@ -2361,10 +2665,10 @@ func (mr *MockPreflightStoreMockRecorder) GetPreflightProgress(appID, sequence i
 }
 
 // GetPreflightResults mocks base method.
-func (m *MockPreflightStore) GetPreflightResults(appID string, sequence int64) (*types8.PreflightResult, error) {
+func (m *MockPreflightStore) GetPreflightResults(appID string, sequence int64) (*types10.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPreflightResults", appID, sequence)
-	ret0, _ := ret[0].(*types8.PreflightResult)
+	ret0, _ := ret[0].(*types10.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2375,6 +2679,21 @@ func (mr *MockPreflightStoreMockRecorder) GetPreflightResults(appID, sequence in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResults", reflect.TypeOf((*MockPreflightStore)(nil).GetPreflightResults), appID, sequence)
 }
 
+// GetPreflightResultsHistory mocks base method.
+func (m *MockPreflightStore) GetPreflightResultsHistory(appID string, sequence *int64) ([]types10.PreflightRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreflightResultsHistory", appID, sequence)
+	ret0, _ := ret[0].([]types10.PreflightRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreflightResultsHistory indicates an expected call of GetPreflightResultsHistory.
+func (mr *MockPreflightStoreMockRecorder) GetPreflightResultsHistory(appID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResultsHistory", reflect.TypeOf((*MockPreflightStore)(nil).GetPreflightResultsHistory), appID, sequence)
+}
+
 // ResetPreflightResults mocks base method.
 func (m *MockPreflightStore) ResetPreflightResults(appID string, sequence int64) error {
 	m.ctrl.T.Helper()
@@ -2418,17 +2737,17 @@ func (mr *MockPreflightStoreMockRecorder) SetPreflightProgress(appID, sequence,
 }
 
 // SetPreflightResults mocks base method.
-func (m *MockPreflightStore) SetPreflightResults(appID string, sequence int64, results []byte) error {
+func (m *MockPreflightStore) SetPreflightResults(appID string, sequence int64, results []byte, trigger types10.PreflightTrigger) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPreflightResults", appID, sequence, results)
+	ret := m.ctrl.Call(m, "SetPreflightResults", appID, sequence, results, trigger)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetPreflightResults indicates an expected call of SetPreflightResults.
-func (mr *MockPreflightStoreMockRecorder) SetPreflightResults(appID, sequence, results interface{}) *gomock.Call {
+func (mr *MockPreflightStoreMockRecorder) SetPreflightResults(appID, sequence, results, trigger interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreflightResults", reflect.TypeOf((*MockPreflightStore)(nil).SetPreflightResults), appID, sequence, results)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreflightResults", reflect.TypeOf((*MockPreflightStore)(nil).SetPreflightResults), appID, sequence, results, trigger)
 }
 
 // MockPrometheusStore is a mock of PrometheusStore interface.
@@ -2669,10 +2988,10 @@ func (m *MockSessionStore) EXPECT() *MockSessionStoreMockRecorder {
 }
 
 // CreateSession mocks base method.
-func (m *MockSessionStore) CreateSession(user *types15.User, issuedAt, expiresAt time.Time, roles []string) (*types11.Session, error) {
+func (m *MockSessionStore) CreateSession(user *types17.User, issuedAt, expiresAt time.Time, roles []string) (*types13.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", user, issuedAt, expiresAt, roles)
-	ret0, _ := ret[0].(*types11.Session)
+	ret0, _ := ret[0].(*types13.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2712,10 +3031,10 @@ func (mr *MockSessionStoreMockRecorder) DeleteSession(sessionID interface{}) *go
 }
 
 // GetSession mocks base method.
-func (m *MockSessionStore) GetSession(sessionID string) (*types11.Session, error) {
+func (m *MockSessionStore) GetSession(sessionID string) (*types13.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", sessionID)
-	ret0, _ := ret[0].(*types11.Session)
+	ret0, _ := ret[0].(*types13.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2764,10 +3083,10 @@ func (m *MockAppStatusStore) EXPECT() *MockAppStatusStoreMockRecorder {
 }
 
 // GetAppStatus mocks base method.
-func (m *MockAppStatusStore) GetAppStatus(appID string) (*types4.AppStatus, error) {
+func (m *MockAppStatusStore) GetAppStatus(appID string) (*types5.AppStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppStatus", appID)
-	ret0, _ := ret[0].(*types4.AppStatus)
+	ret0, _ := ret[0].(*types5.AppStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2779,7 +3098,7 @@ func (mr *MockAppStatusStoreMockRecorder) GetAppStatus(appID interface{}) *gomoc
 }
 
 // SetAppStatus mocks base method.
-func (m *MockAppStatusStore) SetAppStatus(appID string, resourceStates types4.ResourceStates, updatedAt time.Time, sequence int64) error {
+func (m *MockAppStatusStore) SetAppStatus(appID string, resourceStates types5.ResourceStates, updatedAt time.Time, sequence int64) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetAppStatus", appID, resourceStates, updatedAt, sequence)
 	ret0, _ := ret[0].(error)
@@ -2830,10 +3149,10 @@ func (mr *MockAppStoreMockRecorder) AddAppToAllDownstreams(appID interface{}) *g
 }
 
 // CreateApp mocks base method.
-func (m *MockAppStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush, registryIsReadOnly bool) (*types3.App, error) {
+func (m *MockAppStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush, registryIsReadOnly bool) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateApp", name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush, registryIsReadOnly)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2845,10 +3164,10 @@ func (mr *MockAppStoreMockRecorder) CreateApp(name, upstreamURI, licenseData, is
 }
 
 // GetApp mocks base method.
-func (m *MockAppStore) GetApp(appID string) (*types3.App, error) {
+func (m *MockAppStore) GetApp(appID string) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetApp", appID)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2860,10 +3179,10 @@ func (mr *MockAppStoreMockRecorder) GetApp(appID interface{}) *gomock.Call {
 }
 
 // GetAppFromSlug mocks base method.
-func (m *MockAppStore) GetAppFromSlug(slug string) (*types3.App, error) {
+func (m *MockAppStore) GetAppFromSlug(slug string) (*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppFromSlug", slug)
-	ret0, _ := ret[0].(*types3.App)
+	ret0, _ := ret[0].(*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2920,10 +3239,10 @@ func (mr *MockAppStoreMockRecorder) IsGitOpsEnabledForApp(appID interface{}) *go
 }
 
 // ListAppsForDownstream mocks base method.
-func (m *MockAppStore) ListAppsForDownstream(clusterID string) ([]*types3.App, error) {
+func (m *MockAppStore) ListAppsForDownstream(clusterID string) ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListAppsForDownstream", clusterID)
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2950,10 +3269,10 @@ func (mr *MockAppStoreMockRecorder) ListDownstreamsForApp(appID interface{}) *go
 }
 
 // ListFailedApps mocks base method.
-func (m *MockAppStore) ListFailedApps() ([]*types3.App, error) {
+func (m *MockAppStore) ListFailedApps() ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListFailedApps")
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2980,10 +3299,10 @@ func (mr *MockAppStoreMockRecorder) ListInstalledAppSlugs() *gomock.Call {
 }
 
 // ListInstalledApps mocks base method.
-func (m *MockAppStore) ListInstalledApps() ([]*types3.App, error) {
+func (m *MockAppStore) ListInstalledApps() ([]*types4.App, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListInstalledApps")
-	ret0, _ := ret[0].([]*types3.App)
+	ret0, _ := ret[0].([]*types4.App)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -3037,7 +3356,7 @@ func (mr *MockAppStoreMockRecorder) SetAppInstallState(appID, state interface{})
 }
 
 // SetAutoDeploy mocks base method.
-func (m *MockAppStore) SetAutoDeploy(appID string, autoDeploy types3.AutoDeploy) error {
+func (m *MockAppStore) SetAutoDeploy(appID string, autoDeploy types4.AutoDeploy) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetAutoDeploy", appID, autoDeploy)
 	ret0, _ := ret[0].(error)
@@ -3050,6 +3369,48 @@ func (mr *MockAppStoreMockRecorder) SetAutoDeploy(appID, autoDeploy interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeploy", reflect.TypeOf((*MockAppStore)(nil).SetAutoDeploy), appID, autoDeploy)
 }
 
+// SetAutoDeployWindow mocks base method.
+func (m *MockAppStore) SetAutoDeployWindow(appID string, window *types4.MaintenanceWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAutoDeployWindow", appID, window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAutoDeployWindow indicates an expected call of SetAutoDeployWindow.
+func (mr *MockAppStoreMockRecorder) SetAutoDeployWindow(appID, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeployWindow", reflect.TypeOf((*MockAppStore)(nil).SetAutoDeployWindow), appID, window)
+}
+
+// SetAutoRollbackPolicy mocks base method.
+func (m *MockAppStore) SetAutoRollbackPolicy(appID string, policy *types4.AutoRollbackPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAutoRollbackPolicy", appID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAutoRollbackPolicy indicates an expected call of SetAutoRollbackPolicy.
+func (mr *MockAppStoreMockRecorder) SetAutoRollbackPolicy(appID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoRollbackPolicy", reflect.TypeOf((*MockAppStore)(nil).SetAutoRollbackPolicy), appID, policy)
+}
+
+// SetDeployHealthMonitor mocks base method.
+func (m *MockAppStore) SetDeployHealthMonitor(appID string, monitor *types4.DeployHealthMonitor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeployHealthMonitor", appID, monitor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeployHealthMonitor indicates an expected call of SetDeployHealthMonitor.
+func (mr *MockAppStoreMockRecorder) SetDeployHealthMonitor(appID, monitor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeployHealthMonitor", reflect.TypeOf((*MockAppStore)(nil).SetDeployHealthMonitor), appID, monitor)
+}
+
 // SetSnapshotSchedule mocks base method.
 func (m *MockAppStore) SetSnapshotSchedule(appID, snapshotSchedule string) error {
 	m.ctrl.T.Helper()
@@ -3078,6 +3439,20 @@ func (mr *MockAppStoreMockRecorder) SetSnapshotTTL(appID, snapshotTTL interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotTTL", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotTTL), appID, snapshotTTL)
 }
 
+// SetSupportBundleSchedule mocks base method.
+func (m *MockAppStore) SetSupportBundleSchedule(appID string, schedule *types4.SupportBundleSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSupportBundleSchedule", appID, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSupportBundleSchedule indicates an expected call of SetSupportBundleSchedule.
+func (mr *MockAppStoreMockRecorder) SetSupportBundleSchedule(appID, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSupportBundleSchedule", reflect.TypeOf((*MockAppStore)(nil).SetSupportBundleSchedule), appID, schedule)
+}
+
 // SetUpdateCheckerSpec mocks base method.
 func (m *MockAppStore) SetUpdateCheckerSpec(appID, updateCheckerSpec string) error {
 	m.ctrl.T.Helper()
@@ -3263,10 +3638,10 @@ func (mr *MockDownstreamStoreMockRecorder) GetDownstreamVersionSource(appID, seq
 }
 
 // GetDownstreamVersionStatus mocks base method.
-func (m *MockDownstreamStore) GetDownstreamVersionStatus(appID string, sequence int64) (types12.DownstreamVersionStatus, error) {
+func (m *MockDownstreamStore) GetDownstreamVersionStatus(appID string, sequence int64) (types14.DownstreamVersionStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetDownstreamVersionStatus", appID, sequence)
-	ret0, _ := ret[0].(types12.DownstreamVersionStatus)
+	ret0, _ := ret[0].(types14.DownstreamVersionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -3355,10 +3730,10 @@ func (mr *MockDownstreamStoreMockRecorder) GetPreviouslyDeployedSequence(appID,
 }
 
 // GetStatusForVersion mocks base method.
-func (m *MockDownstreamStore) GetStatusForVersion(appID, clusterID string, sequence int64) (types12.DownstreamVersionStatus, error) {
+func (m *MockDownstreamStore) GetStatusForVersion(appID, clusterID string, sequence int64) (types14.DownstreamVersionStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetStatusForVersion", appID, clusterID, sequence)
-	ret0, _ := ret[0].(types12.DownstreamVersionStatus)
+	ret0, _ := ret[0].(types14.DownstreamVersionStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -3415,7 +3790,7 @@ func (mr *MockDownstreamStoreMockRecorder) MarkAsCurrentDownstreamVersion(appID,
 }
 
 // SetDownstreamVersionStatus mocks base method.
-func (m *MockDownstreamStore) SetDownstreamVersionStatus(appID string, sequence int64, status types12.DownstreamVersionStatus, statusInfo string) error {
+func (m *MockDownstreamStore) SetDownstreamVersionStatus(appID string, sequence int64, status types14.DownstreamVersionStatus, statusInfo string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetDownstreamVersionStatus", appID, sequence, status, statusInfo)
 	ret0, _ := ret[0].(error)
@@ -3479,6 +3854,20 @@ func (mr *MockSnapshotStoreMockRecorder) CreateScheduledInstanceSnapshot(snapsho
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledInstanceSnapshot", reflect.TypeOf((*MockSnapshotStore)(nil).CreateScheduledInstanceSnapshot), snapshotID, clusterID, timestamp)
 }
 
+// CreateScheduledRestoreDrill mocks base method.
+func (m *MockSnapshotStore) CreateScheduledRestoreDrill(drillID, clusterID string, timestamp time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledRestoreDrill", drillID, clusterID, timestamp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateScheduledRestoreDrill indicates an expected call of CreateScheduledRestoreDrill.
+func (mr *MockSnapshotStoreMockRecorder) CreateScheduledRestoreDrill(drillID, clusterID, timestamp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledRestoreDrill", reflect.TypeOf((*MockSnapshotStore)(nil).CreateScheduledRestoreDrill), drillID, clusterID, timestamp)
+}
+
 // CreateScheduledSnapshot mocks base method.
 func (m *MockSnapshotStore) CreateScheduledSnapshot(snapshotID, appID string, timestamp time.Time) error {
 	m.ctrl.T.Helper()
@@ -3507,6 +3896,20 @@ func (mr *MockSnapshotStoreMockRecorder) DeletePendingScheduledInstanceSnapshots
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledInstanceSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).DeletePendingScheduledInstanceSnapshots), clusterID)
 }
 
+// DeletePendingScheduledRestoreDrills mocks base method.
+func (m *MockSnapshotStore) DeletePendingScheduledRestoreDrills(clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingScheduledRestoreDrills", clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingScheduledRestoreDrills indicates an expected call of DeletePendingScheduledRestoreDrills.
+func (mr *MockSnapshotStoreMockRecorder) DeletePendingScheduledRestoreDrills(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledRestoreDrills", reflect.TypeOf((*MockSnapshotStore)(nil).DeletePendingScheduledRestoreDrills), clusterID)
+}
+
 // DeletePendingScheduledSnapshots mocks base method.
 func (m *MockSnapshotStore) DeletePendingScheduledSnapshots(appID string) error {
 	m.ctrl.T.Helper()
@@ -3522,10 +3925,10 @@ func (mr *MockSnapshotStoreMockRecorder) DeletePendingScheduledSnapshots(appID i
 }
 
 // ListPendingScheduledInstanceSnapshots mocks base method.
-func (m *MockSnapshotStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types6.ScheduledInstanceSnapshot, error) {
+func (m *MockSnapshotStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types8.ScheduledInstanceSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledInstanceSnapshots", clusterID)
-	ret0, _ := ret[0].([]types6.ScheduledInstanceSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledInstanceSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -3536,11 +3939,26 @@ func (mr *MockSnapshotStoreMockRecorder) ListPendingScheduledInstanceSnapshots(c
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledInstanceSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).ListPendingScheduledInstanceSnapshots), clusterID)
 }
 
+// ListPendingScheduledRestoreDrills mocks base method.
+func (m *MockSnapshotStore) ListPendingScheduledRestoreDrills(clusterID string) ([]types8.ScheduledRestoreDrill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingScheduledRestoreDrills", clusterID)
+	ret0, _ := ret[0].([]types8.ScheduledRestoreDrill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingScheduledRestoreDrills indicates an expected call of ListPendingScheduledRestoreDrills.
+func (mr *MockSnapshotStoreMockRecorder) ListPendingScheduledRestoreDrills(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledRestoreDrills", reflect.TypeOf((*MockSnapshotStore)(nil).ListPendingScheduledRestoreDrills), clusterID)
+}
+
 // ListPendingScheduledSnapshots mocks base method.
-func (m *MockSnapshotStore) ListPendingScheduledSnapshots(appID string) ([]types6.ScheduledSnapshot, error) {
+func (m *MockSnapshotStore) ListPendingScheduledSnapshots(appID string) ([]types8.ScheduledSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledSnapshots", appID)
-	ret0, _ := ret[0].([]types6.ScheduledSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -3565,6 +3983,20 @@ func (mr *MockSnapshotStoreMockRecorder) UpdateScheduledInstanceSnapshot(snapsho
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledInstanceSnapshot", reflect.TypeOf((*MockSnapshotStore)(nil).UpdateScheduledInstanceSnapshot), snapshotID, backupName)
 }
 
+// UpdateScheduledRestoreDrill mocks base method.
+func (m *MockSnapshotStore) UpdateScheduledRestoreDrill(drillID, backupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledRestoreDrill", drillID, backupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScheduledRestoreDrill indicates an expected call of UpdateScheduledRestoreDrill.
+func (mr *MockSnapshotStoreMockRecorder) UpdateScheduledRestoreDrill(drillID, backupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledRestoreDrill", reflect.TypeOf((*MockSnapshotStore)(nil).UpdateScheduledRestoreDrill), drillID, backupName)
+}
+
 // UpdateScheduledSnapshot mocks base method.
 func (m *MockSnapshotStore) UpdateScheduledSnapshot(snapshotID, backupName string) error {
 	m.ctrl.T.Helper()
@@ -3603,7 +4035,7 @@ func (m *MockVersionStore) EXPECT() *MockVersionStoreMockRecorder {
 }
 
 // CreateAppVersion mocks base method.
-func (m *MockVersionStore) CreateAppVersion(appID string, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) (int64, error) {
+func (m *MockVersionStore) CreateAppVersion(appID string, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateAppVersion", appID, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -3632,7 +4064,7 @@ func (mr *MockVersionStoreMockRecorder) CreateAppVersionArchive(appID, sequence,
 }
 
 // CreatePendingDownloadAppVersion mocks base method.
-func (m *MockVersionStore) CreatePendingDownloadAppVersion(appID string, update types14.Update, kotsApplication *v1beta1.Application, license *v1beta1.License) (int64, error) {
+func (m *MockVersionStore) CreatePendingDownloadAppVersion(appID string, update types16.Update, kotsApplication *v1beta1.Application, license *v1beta1.License) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePendingDownloadAppVersion", appID, update, kotsApplication, license)
 	ret0, _ := ret[0].(int64)
@@ -3812,7 +4244,7 @@ func (mr *MockVersionStoreMockRecorder) IsRollbackSupportedForVersion(appID, seq
 }
 
 // IsSnapshotsSupportedForVersion mocks base method.
-func (m *MockVersionStore) IsSnapshotsSupportedForVersion(a *types3.App, sequence int64, renderer types10.Renderer) (bool, error) {
+func (m *MockVersionStore) IsSnapshotsSupportedForVersion(a *types4.App, sequence int64, renderer types12.Renderer) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsSnapshotsSupportedForVersion", a, sequence, renderer)
 	ret0, _ := ret[0].(bool)
@@ -3827,7 +4259,7 @@ func (mr *MockVersionStoreMockRecorder) IsSnapshotsSupportedForVersion(a, sequen
 }
 
 // UpdateAppVersion mocks base method.
-func (m *MockVersionStore) UpdateAppVersion(appID string, sequence int64, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) error {
+func (m *MockVersionStore) UpdateAppVersion(appID string, sequence int64, baseSequence *int64, filesInDir, source string, skipPreflights bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppVersion", appID, sequence, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 	ret0, _ := ret[0].(error)
@@ -3840,6 +4272,20 @@ func (mr *MockVersionStoreMockRecorder) UpdateAppVersion(appID, sequence, baseSe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersion", reflect.TypeOf((*MockVersionStore)(nil).UpdateAppVersion), appID, sequence, baseSequence, filesInDir, source, skipPreflights, gitops, renderer)
 }
 
+// UpdateAppVersionArchive mocks base method.
+func (m *MockVersionStore) UpdateAppVersionArchive(appID string, sequence int64, archivePath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAppVersionArchive", appID, sequence, archivePath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAppVersionArchive indicates an expected call of UpdateAppVersionArchive.
+func (mr *MockVersionStoreMockRecorder) UpdateAppVersionArchive(appID, sequence, archivePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersionArchive", reflect.TypeOf((*MockVersionStore)(nil).UpdateAppVersionArchive), appID, sequence, archivePath)
+}
+
 // UpdateAppVersionInstallationSpec mocks base method.
 func (m *MockVersionStore) UpdateAppVersionInstallationSpec(appID string, sequence int64, spec v1beta1.Installation) error {
 	m.ctrl.T.Helper()
@@ -3937,7 +4383,7 @@ func (mr *MockLicenseStoreMockRecorder) GetLicenseForAppVersion(appID, sequence
 }
 
 // UpdateAppLicense mocks base method.
-func (m *MockLicenseStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, channelChanged, failOnVersionCreate bool, gitops types5.DownstreamGitOps, renderer types10.Renderer) (int64, error) {
+func (m *MockLicenseStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, channelChanged, failOnVersionCreate bool, gitops types7.DownstreamGitOps, renderer types12.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppLicense", appID, sequence, archiveDir, newLicense, originalLicenseData, channelChanged, failOnVersionCreate, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -3989,31 +4435,46 @@ func (m *MockUserStore) EXPECT() *MockUserStoreMockRecorder {
 }
 
 // FlagInvalidPassword mocks base method.
-func (m *MockUserStore) FlagInvalidPassword() error {
+func (m *MockUserStore) FlagInvalidPassword(clientIP string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FlagInvalidPassword")
+	ret := m.ctrl.Call(m, "FlagInvalidPassword", clientIP)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FlagInvalidPassword indicates an expected call of FlagInvalidPassword.
-func (mr *MockUserStoreMockRecorder) FlagInvalidPassword() *gomock.Call {
+func (mr *MockUserStoreMockRecorder) FlagInvalidPassword(clientIP interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagInvalidPassword", reflect.TypeOf((*MockUserStore)(nil).FlagInvalidPassword))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagInvalidPassword", reflect.TypeOf((*MockUserStore)(nil).FlagInvalidPassword), clientIP)
 }
 
 // FlagSuccessfulLogin mocks base method.
-func (m *MockUserStore) FlagSuccessfulLogin() error {
+func (m *MockUserStore) FlagSuccessfulLogin(clientIP string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FlagSuccessfulLogin")
+	ret := m.ctrl.Call(m, "FlagSuccessfulLogin", clientIP)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FlagSuccessfulLogin indicates an expected call of FlagSuccessfulLogin.
-func (mr *MockUserStoreMockRecorder) FlagSuccessfulLogin() *gomock.Call {
+func (mr *MockUserStoreMockRecorder) FlagSuccessfulLogin(clientIP interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSuccessfulLogin", reflect.TypeOf((*MockUserStore)(nil).FlagSuccessfulLogin))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSuccessfulLogin", reflect.TypeOf((*MockUserStore)(nil).FlagSuccessfulLogin), clientIP)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockUserStore) GetLoginAttempts() (*types17.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts")
+	ret0, _ := ret[0].(*types17.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockUserStoreMockRecorder) GetLoginAttempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockUserStore)(nil).GetLoginAttempts))
 }
 
 // GetPasswordUpdatedAt mocks base method.
@@ -4129,6 +4590,34 @@ func (mr *MockClusterStoreMockRecorder) ListClusters() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusters", reflect.TypeOf((*MockClusterStore)(nil).ListClusters))
 }
 
+// SetInstanceRestoreDrillSchedule mocks base method.
+func (m *MockClusterStore) SetInstanceRestoreDrillSchedule(clusterID, restoreDrillSchedule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceRestoreDrillSchedule", clusterID, restoreDrillSchedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceRestoreDrillSchedule indicates an expected call of SetInstanceRestoreDrillSchedule.
+func (mr *MockClusterStoreMockRecorder) SetInstanceRestoreDrillSchedule(clusterID, restoreDrillSchedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceRestoreDrillSchedule", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceRestoreDrillSchedule), clusterID, restoreDrillSchedule)
+}
+
+// SetInstanceSnapshotRetention mocks base method.
+func (m *MockClusterStore) SetInstanceSnapshotRetention(clusterID, retention string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotRetention", clusterID, retention)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotRetention indicates an expected call of SetInstanceSnapshotRetention.
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotRetention(clusterID, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotRetention", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotRetention), clusterID, retention)
+}
+
 // SetInstanceSnapshotSchedule mocks base method.
 func (m *MockClusterStore) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule string) error {
 	m.ctrl.T.Helper()
@@ -4143,6 +4632,20 @@ func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, s
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
 }
 
+// SetInstanceSnapshotStorageLocation mocks base method.
+func (m *MockClusterStore) SetInstanceSnapshotStorageLocation(clusterID, storageLocation string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotStorageLocation", clusterID, storageLocation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotStorageLocation indicates an expected call of SetInstanceSnapshotStorageLocation.
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotStorageLocation(clusterID, storageLocation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotStorageLocation", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotStorageLocation), clusterID, storageLocation)
+}
+
 // SetInstanceSnapshotTTL mocks base method.
 func (m *MockClusterStore) SetInstanceSnapshotTTL(clusterID, snapshotTTL string) error {
 	m.ctrl.T.Helper()
@@ -4181,10 +4684,10 @@ func (m *MockInstallationStore) EXPECT() *MockInstallationStoreMockRecorder {
 }
 
 // GetPendingInstallationStatus mocks base method.
-func (m *MockInstallationStore) GetPendingInstallationStatus() (*types7.InstallStatus, error) {
+func (m *MockInstallationStore) GetPendingInstallationStatus() (*types9.InstallStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPendingInstallationStatus")
-	ret0, _ := ret[0].(*types7.InstallStatus)
+	ret0, _ := ret[0].(*types9.InstallStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -4432,3 +4935,152 @@ func (mr *MockReportingStoreMockRecorder) SaveReportingInfo(licenseID, reporting
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReportingInfo", reflect.TypeOf((*MockReportingStore)(nil).SaveReportingInfo), licenseID, reportingInfo)
 }
+
+// MockAuditStore is a mock of AuditStore interface.
+type MockAuditStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditStoreMockRecorder
+}
+
+// MockAuditStoreMockRecorder is the mock recorder for MockAuditStore.
+type MockAuditStoreMockRecorder struct {
+	mock *MockAuditStore
+}
+
+// NewMockAuditStore creates a new mock instance.
+func NewMockAuditStore(ctrl *gomock.Controller) *MockAuditStore {
+	mock := &MockAuditStore{ctrl: ctrl}
+	mock.recorder = &MockAuditStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditStore) EXPECT() *MockAuditStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockAuditStore) CreateAuditEvent(event *types6.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockAuditStoreMockRecorder) CreateAuditEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockAuditStore)(nil).CreateAuditEvent), event)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockAuditStore) ListAuditEvents(opts types6.ListOptions) ([]types6.Event, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", opts)
+	ret0, _ := ret[0].([]types6.Event)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockAuditStoreMockRecorder) ListAuditEvents(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockAuditStore)(nil).ListAuditEvents), opts)
+}
+
+// MockAPITokenStore is a mock of APITokenStore interface.
+type MockAPITokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPITokenStoreMockRecorder
+}
+
+// MockAPITokenStoreMockRecorder is the mock recorder for MockAPITokenStore.
+type MockAPITokenStoreMockRecorder struct {
+	mock *MockAPITokenStore
+}
+
+// NewMockAPITokenStore creates a new mock instance.
+func NewMockAPITokenStore(ctrl *gomock.Controller) *MockAPITokenStore {
+	mock := &MockAPITokenStore{ctrl: ctrl}
+	mock.recorder = &MockAPITokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPITokenStore) EXPECT() *MockAPITokenStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIToken mocks base method.
+func (m *MockAPITokenStore) CreateAPIToken(token *types3.APIToken, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", token, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockAPITokenStoreMockRecorder) CreateAPIToken(token, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockAPITokenStore)(nil).CreateAPIToken), token, tokenHash)
+}
+
+// GetAPIToken mocks base method.
+func (m *MockAPITokenStore) GetAPIToken(id string) (*types3.APIToken, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", id)
+	ret0, _ := ret[0].(*types3.APIToken)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockAPITokenStoreMockRecorder) GetAPIToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockAPITokenStore)(nil).GetAPIToken), id)
+}
+
+// ListAPITokens mocks base method.
+func (m *MockAPITokenStore) ListAPITokens() ([]types3.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPITokens")
+	ret0, _ := ret[0].([]types3.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPITokens indicates an expected call of ListAPITokens.
+func (mr *MockAPITokenStoreMockRecorder) ListAPITokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokens", reflect.TypeOf((*MockAPITokenStore)(nil).ListAPITokens))
+}
+
+// RevokeAPIToken mocks base method.
+func (m *MockAPITokenStore) RevokeAPIToken(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIToken", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIToken indicates an expected call of RevokeAPIToken.
+func (mr *MockAPITokenStoreMockRecorder) RevokeAPIToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIToken", reflect.TypeOf((*MockAPITokenStore)(nil).RevokeAPIToken), id)
+}
+
+// UpdateAPITokenLastUsedAt mocks base method.
+func (m *MockAPITokenStore) UpdateAPITokenLastUsedAt(id string, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPITokenLastUsedAt", id, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPITokenLastUsedAt indicates an expected call of UpdateAPITokenLastUsedAt.
+func (mr *MockAPITokenStoreMockRecorder) UpdateAPITokenLastUsedAt(id, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPITokenLastUsedAt", reflect.TypeOf((*MockAPITokenStore)(nil).UpdateAPITokenLastUsedAt), id, lastUsedAt)
+}
@@ -9,8 +9,10 @@ import (
 	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
 	reportingtypes "github.com/replicatedhq/kots/pkg/api/reporting/types"
 	versiontypes "github.com/replicatedhq/kots/pkg/api/version/types"
+	apitokentypes "github.com/replicatedhq/kots/pkg/apitoken/types"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
 	gitopstypes "github.com/replicatedhq/kots/pkg/gitops/types"
 	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	installationtypes "github.com/replicatedhq/kots/pkg/online/types"
@@ -47,6 +49,8 @@ type Store interface {
 	EmbeddedStore
 	BrandingStore
 	ReportingStore
+	AuditStore
+	APITokenStore
 
 	Init() error // this may need options
 	WaitForReady(ctx context.Context) error
@@ -81,9 +85,10 @@ type SupportBundleStore interface {
 type PreflightStore interface {
 	SetPreflightProgress(appID string, sequence int64, progress string) error
 	GetPreflightProgress(appID string, sequence int64) (string, error)
-	SetPreflightResults(appID string, sequence int64, results []byte) error
+	SetPreflightResults(appID string, sequence int64, results []byte, trigger preflighttypes.PreflightTrigger) error
 	GetPreflightResults(appID string, sequence int64) (*preflighttypes.PreflightResult, error)
 	ResetPreflightResults(appID string, sequence int64) error
+	GetPreflightResultsHistory(appID string, sequence *int64) ([]preflighttypes.PreflightRun, error)
 	SetIgnorePreflightPermissionErrors(appID string, sequence int64) error
 }
 
@@ -135,6 +140,10 @@ type AppStore interface {
 	IsGitOpsEnabledForApp(appID string) (bool, error)
 	SetUpdateCheckerSpec(appID string, updateCheckerSpec string) error
 	SetAutoDeploy(appID string, autoDeploy apptypes.AutoDeploy) error
+	SetAutoDeployWindow(appID string, window *apptypes.MaintenanceWindow) error
+	SetAutoRollbackPolicy(appID string, policy *apptypes.AutoRollbackPolicy) error
+	SetDeployHealthMonitor(appID string, monitor *apptypes.DeployHealthMonitor) error
+	SetSupportBundleSchedule(appID string, schedule *apptypes.SupportBundleSchedule) error
 	SetSnapshotTTL(appID string, snapshotTTL string) error
 	SetSnapshotSchedule(appID string, snapshotSchedule string) error
 	RemoveApp(appID string) error
@@ -179,6 +188,11 @@ type SnapshotStore interface {
 	UpdateScheduledInstanceSnapshot(snapshotID string, backupName string) error
 	DeletePendingScheduledInstanceSnapshots(clusterID string) error
 	CreateScheduledInstanceSnapshot(snapshotID string, clusterID string, timestamp time.Time) error
+
+	ListPendingScheduledRestoreDrills(clusterID string) ([]snapshottypes.ScheduledRestoreDrill, error)
+	UpdateScheduledRestoreDrill(drillID string, backupName string) error
+	DeletePendingScheduledRestoreDrills(clusterID string) error
+	CreateScheduledRestoreDrill(drillID string, clusterID string, timestamp time.Time) error
 }
 
 type VersionStore interface {
@@ -197,6 +211,7 @@ type VersionStore interface {
 	GetLatestAppSequence(appID string, downloadedOnly bool) (int64, error)
 	UpdateNextAppVersionDiffSummary(appID string, baseSequence int64) error
 	UpdateAppVersionInstallationSpec(appID string, sequence int64, spec kotsv1beta1.Installation) error
+	UpdateAppVersionArchive(appID string, sequence int64, archivePath string) error
 	GetNextAppSequence(appID string) (int64, error)
 	GetCurrentUpdateCursor(appID string, channelID string) (string, error)
 	HasStrictPreflights(appID string, sequence int64) (bool, error)
@@ -215,8 +230,9 @@ type LicenseStore interface {
 type UserStore interface {
 	GetSharedPasswordBcrypt() ([]byte, error)
 	GetPasswordUpdatedAt() (*time.Time, error)
-	FlagInvalidPassword() error
-	FlagSuccessfulLogin() error
+	GetLoginAttempts() (*usertypes.LoginAttempts, error)
+	FlagInvalidPassword(clientIP string) error
+	FlagSuccessfulLogin(clientIP string) error
 }
 
 type ClusterStore interface {
@@ -226,6 +242,9 @@ type ClusterStore interface {
 	CreateNewCluster(userID string, isAllUsers bool, title string, token string) (clusterID string, err error)
 	SetInstanceSnapshotTTL(clusterID string, snapshotTTL string) error
 	SetInstanceSnapshotSchedule(clusterID string, snapshotSchedule string) error
+	SetInstanceRestoreDrillSchedule(clusterID string, restoreDrillSchedule string) error
+	SetInstanceSnapshotStorageLocation(clusterID string, storageLocation string) error
+	SetInstanceSnapshotRetention(clusterID string, retention string) error
 }
 
 type InstallationStore interface {
@@ -253,3 +272,16 @@ type ReportingStore interface {
 	SavePreflightReport(licenseID string, preflightStatus *reportingtypes.PreflightStatus) error
 	SaveReportingInfo(licenseID string, reportingInfo *reportingtypes.ReportingInfo) error
 }
+
+type AuditStore interface {
+	CreateAuditEvent(event *audittypes.Event) error
+	ListAuditEvents(opts audittypes.ListOptions) ([]audittypes.Event, int64, error)
+}
+
+type APITokenStore interface {
+	CreateAPIToken(token *apitokentypes.APIToken, tokenHash string) error
+	GetAPIToken(id string) (*apitokentypes.APIToken, string, error)
+	ListAPITokens() ([]apitokentypes.APIToken, error)
+	RevokeAPIToken(id string) error
+	UpdateAPITokenLastUsedAt(id string, lastUsedAt time.Time) error
+}
@@ -0,0 +1,470 @@
+package supportbundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+)
+
+const clusterResourcesDir = "cluster-resources"
+
+// comparedFields are the top level fields of a cluster resource that are compared.
+// Status and metadata change all the time and are ignored.
+var comparedFields = []string{"spec", "data", "rules"}
+
+// CompareInput is one side of a support bundle comparison
+type CompareInput struct {
+	ID          string
+	ArchivePath string
+	// Insights are read from the analysis in the archive if they are nil
+	Insights []types.SupportBundleInsight
+}
+
+type bundleContents struct {
+	insights []types.SupportBundleInsight
+	// resources are the items in the json files in the cluster-resources directory, keyed by resource type
+	resources map[string][]clusterResource
+}
+
+type clusterResource struct {
+	namespace string
+	name      string
+	uid       string
+	object    map[string]interface{}
+}
+
+func (r clusterResource) key() string {
+	return fmt.Sprintf("%s/%s", r.namespace, r.name)
+}
+
+// Compare returns what changed from the base to the target support bundle
+func Compare(base CompareInput, target CompareInput) (*types.SupportBundleComparison, error) {
+	baseContents, err := loadBundleContents(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load base bundle")
+	}
+
+	targetContents, err := loadBundleContents(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load target bundle")
+	}
+
+	comparison := compareBundleContents(baseContents, targetContents)
+	comparison.BaseID = base.ID
+	comparison.TargetID = target.ID
+
+	return comparison, nil
+}
+
+func loadBundleContents(input CompareInput) (*bundleContents, error) {
+	bundleDir, err := ioutil.TempDir("", "kots")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(bundleDir)
+
+	tarGz := archiver.TarGz{
+		Tar: &archiver.Tar{
+			ImplicitTopLevelFolder: false,
+		},
+	}
+	if err := tarGz.Unarchive(input.ArchivePath, bundleDir); err != nil {
+		return nil, errors.Wrap(err, "failed to unarchive")
+	}
+
+	contents := &bundleContents{
+		insights:  input.Insights,
+		resources: map[string][]clusterResource{},
+	}
+
+	err = filepath.Walk(bundleDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			return errors.Wrap(err, "failed to get relative path")
+		}
+		trimmedRelPath := SupportBundleNameRegex.ReplaceAllString(filepath.ToSlash(relPath), "")
+		trimmedRelPath = strings.TrimPrefix(trimmedRelPath, "/")
+
+		if trimmedRelPath == "analysis.json" && input.Insights == nil {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrap(err, "failed to read analysis file")
+			}
+			contents.insights = insightsFromAnalysis(b)
+			return nil
+		}
+
+		resourceType := clusterResourceType(trimmedRelPath)
+		if resourceType == "" {
+			return nil
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", trimmedRelPath)
+		}
+		contents.resources[resourceType] = append(contents.resources[resourceType], clusterResourcesFromFile(b)...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to walk")
+	}
+
+	return contents, nil
+}
+
+// clusterResourceType returns the resource type for json files in the cluster-resources directory,
+// e.g. "deployments" for "cluster-resources/deployments/default.json" and "nodes" for "cluster-resources/nodes.json".
+// It returns an empty string for other files.
+func clusterResourceType(relPath string) string {
+	if !strings.HasPrefix(relPath, clusterResourcesDir+"/") || !strings.HasSuffix(relPath, ".json") {
+		return ""
+	}
+	// collection errors are written next to the resources
+	if strings.HasSuffix(relPath, "-errors.json") {
+		return ""
+	}
+
+	relPath = strings.TrimPrefix(relPath, clusterResourcesDir+"/")
+	dir, file := filepath.Split(relPath)
+	if dir == "" {
+		return strings.TrimSuffix(file, ".json")
+	}
+	return strings.TrimSuffix(dir, "/")
+}
+
+// clusterResourcesFromFile reads the items from a kubernetes list, or from a json array written by older versions of troubleshoot.
+// Items without a name are skipped.
+func clusterResourcesFromFile(b []byte) []clusterResource {
+	items := []map[string]interface{}{}
+
+	list := struct {
+		Items []map[string]interface{} `json:"items"`
+	}{}
+	if err := json.Unmarshal(b, &list); err == nil {
+		items = list.Items
+	} else if err := json.Unmarshal(b, &items); err != nil {
+		return nil
+	}
+
+	resources := []clusterResource{}
+	for _, item := range items {
+		name := nestedString(item, "metadata", "name")
+		if name == "" {
+			continue
+		}
+		resources = append(resources, clusterResource{
+			namespace: nestedString(item, "metadata", "namespace"),
+			name:      name,
+			uid:       nestedString(item, "metadata", "uid"),
+			object:    item,
+		})
+	}
+
+	return resources
+}
+
+// insightsFromAnalysis reads the insights from the analysis.json file in a support bundle
+func insightsFromAnalysis(b []byte) []types.SupportBundleInsight {
+	results := []struct {
+		Name     string `json:"name"`
+		Severity string `json:"severity"`
+		Insight  struct {
+			Primary string `json:"primary"`
+			Detail  string `json:"detail"`
+		} `json:"insight"`
+	}{}
+	if err := json.Unmarshal(b, &results); err != nil {
+		return nil
+	}
+
+	insights := []types.SupportBundleInsight{}
+	for _, result := range results {
+		insights = append(insights, types.SupportBundleInsight{
+			Key:      result.Name,
+			Severity: result.Severity,
+			Primary:  result.Insight.Primary,
+			Detail:   result.Insight.Detail,
+		})
+	}
+
+	return insights
+}
+
+func compareBundleContents(base *bundleContents, target *bundleContents) *types.SupportBundleComparison {
+	comparison := &types.SupportBundleComparison{
+		AnalyzerChanges:       compareInsights(base.insights, target.insights),
+		PodChanges:            []types.ResourceChange{},
+		NewEvents:             newEvents(base.resources["events"], target.resources["events"]),
+		ResourceChanges:       []types.ResourceChange{},
+		ClusterResourceDeltas: []types.ClusterResourceDelta{},
+	}
+
+	resourceTypes := map[string]bool{}
+	for resourceType := range base.resources {
+		resourceTypes[resourceType] = true
+	}
+	for resourceType := range target.resources {
+		resourceTypes[resourceType] = true
+	}
+
+	sortedResourceTypes := []string{}
+	for resourceType := range resourceTypes {
+		sortedResourceTypes = append(sortedResourceTypes, resourceType)
+	}
+	sort.Strings(sortedResourceTypes)
+
+	for _, resourceType := range sortedResourceTypes {
+		baseResources, targetResources := base.resources[resourceType], target.resources[resourceType]
+
+		if len(baseResources) != len(targetResources) {
+			comparison.ClusterResourceDeltas = append(comparison.ClusterResourceDeltas, types.ClusterResourceDelta{
+				Resource:    resourceType,
+				BaseCount:   len(baseResources),
+				TargetCount: len(targetResources),
+			})
+		}
+
+		switch resourceType {
+		case "events":
+			// new events are reported on their own, events are never modified in a way that matters
+		case "pods":
+			comparison.PodChanges = compareResources(resourceType, baseResources, targetResources, podPhaseDiff)
+		default:
+			comparison.ResourceChanges = append(comparison.ResourceChanges, compareResources(resourceType, baseResources, targetResources, fieldsDiff)...)
+		}
+	}
+
+	return comparison
+}
+
+func compareInsights(base []types.SupportBundleInsight, target []types.SupportBundleInsight) []types.AnalyzerChange {
+	baseByKey := map[string]types.SupportBundleInsight{}
+	for _, insight := range base {
+		baseByKey[insight.Key] = insight
+	}
+	targetByKey := map[string]types.SupportBundleInsight{}
+	for _, insight := range target {
+		targetByKey[insight.Key] = insight
+	}
+
+	changes := []types.AnalyzerChange{}
+	for key, targetInsight := range targetByKey {
+		baseInsight, ok := baseByKey[key]
+		if !ok {
+			changes = append(changes, types.AnalyzerChange{
+				Key:            key,
+				Change:         types.ChangeAdded,
+				TargetSeverity: targetInsight.Severity,
+				Primary:        targetInsight.Primary,
+				Detail:         targetInsight.Detail,
+			})
+			continue
+		}
+		if baseInsight.Severity != targetInsight.Severity {
+			changes = append(changes, types.AnalyzerChange{
+				Key:            key,
+				Change:         types.ChangeModified,
+				BaseSeverity:   baseInsight.Severity,
+				TargetSeverity: targetInsight.Severity,
+				Primary:        targetInsight.Primary,
+				Detail:         targetInsight.Detail,
+			})
+		}
+	}
+	for key, baseInsight := range baseByKey {
+		if _, ok := targetByKey[key]; !ok {
+			changes = append(changes, types.AnalyzerChange{
+				Key:          key,
+				Change:       types.ChangeRemoved,
+				BaseSeverity: baseInsight.Severity,
+				Primary:      baseInsight.Primary,
+				Detail:       baseInsight.Detail,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// compareResources matches resources by namespace and name. diff returns a description of how a resource
+// changed, or an empty string if it did not.
+func compareResources(resourceType string, base []clusterResource, target []clusterResource, diff func(base, target map[string]interface{}) string) []types.ResourceChange {
+	baseByKey := map[string]clusterResource{}
+	for _, r := range base {
+		baseByKey[r.key()] = r
+	}
+	targetByKey := map[string]clusterResource{}
+	for _, r := range target {
+		targetByKey[r.key()] = r
+	}
+
+	changes := []types.ResourceChange{}
+	for key, targetResource := range targetByKey {
+		baseResource, ok := baseByKey[key]
+		if !ok {
+			changes = append(changes, types.ResourceChange{
+				Resource:  resourceType,
+				Namespace: targetResource.namespace,
+				Name:      targetResource.name,
+				Change:    types.ChangeAdded,
+			})
+			continue
+		}
+		if detail := diff(baseResource.object, targetResource.object); detail != "" {
+			changes = append(changes, types.ResourceChange{
+				Resource:  resourceType,
+				Namespace: targetResource.namespace,
+				Name:      targetResource.name,
+				Change:    types.ChangeModified,
+				Detail:    detail,
+			})
+		}
+	}
+	for key, baseResource := range baseByKey {
+		if _, ok := targetByKey[key]; !ok {
+			changes = append(changes, types.ResourceChange{
+				Resource:  resourceType,
+				Namespace: baseResource.namespace,
+				Name:      baseResource.name,
+				Change:    types.ChangeRemoved,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Namespace != changes[j].Namespace {
+			return changes[i].Namespace < changes[j].Namespace
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func podPhaseDiff(base map[string]interface{}, target map[string]interface{}) string {
+	basePhase, targetPhase := nestedString(base, "status", "phase"), nestedString(target, "status", "phase")
+	if basePhase == targetPhase {
+		return ""
+	}
+	return fmt.Sprintf("phase %s -> %s", basePhase, targetPhase)
+}
+
+// fieldsDiff lists the compared fields that differ, down to the second level, e.g. "spec.replicas"
+func fieldsDiff(base map[string]interface{}, target map[string]interface{}) string {
+	diffs := []string{}
+	for _, field := range comparedFields {
+		baseValue, targetValue := base[field], target[field]
+		if reflect.DeepEqual(baseValue, targetValue) {
+			continue
+		}
+
+		baseMap, baseIsMap := baseValue.(map[string]interface{})
+		targetMap, targetIsMap := targetValue.(map[string]interface{})
+		if !baseIsMap || !targetIsMap {
+			diffs = append(diffs, field)
+			continue
+		}
+
+		keys := map[string]bool{}
+		for key := range baseMap {
+			keys[key] = true
+		}
+		for key := range targetMap {
+			keys[key] = true
+		}
+		fieldDiffs := []string{}
+		for key := range keys {
+			if !reflect.DeepEqual(baseMap[key], targetMap[key]) {
+				fieldDiffs = append(fieldDiffs, fmt.Sprintf("%s.%s", field, key))
+			}
+		}
+		sort.Strings(fieldDiffs)
+		diffs = append(diffs, fieldDiffs...)
+	}
+
+	return strings.Join(diffs, ", ")
+}
+
+func newEvents(base []clusterResource, target []clusterResource) []types.EventSummary {
+	seen := map[string]bool{}
+	for _, event := range base {
+		seen[eventKey(event)] = true
+	}
+
+	events := []types.EventSummary{}
+	for _, event := range target {
+		if seen[eventKey(event)] {
+			continue
+		}
+
+		involvedObject := nestedString(event.object, "involvedObject", "name")
+		if kind := nestedString(event.object, "involvedObject", "kind"); kind != "" {
+			involvedObject = fmt.Sprintf("%s/%s", kind, involvedObject)
+		}
+
+		count, _ := event.object["count"].(float64)
+
+		events = append(events, types.EventSummary{
+			Namespace:      event.namespace,
+			Name:           event.name,
+			Type:           nestedString(event.object, "type"),
+			Reason:         nestedString(event.object, "reason"),
+			Message:        nestedString(event.object, "message"),
+			InvolvedObject: involvedObject,
+			Count:          int64(count),
+			LastTimestamp:  nestedString(event.object, "lastTimestamp"),
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].LastTimestamp != events[j].LastTimestamp {
+			return events[i].LastTimestamp < events[j].LastTimestamp
+		}
+		return events[i].Name < events[j].Name
+	})
+
+	return events
+}
+
+// eventKey identifies an event across bundles. The uid is preferred because event names are reused.
+func eventKey(event clusterResource) string {
+	if event.uid != "" {
+		return event.uid
+	}
+	return event.key()
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[field]
+	}
+	s, _ := current.(string)
+	return s
+}
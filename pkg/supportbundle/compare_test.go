@@ -0,0 +1,201 @@
+package supportbundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_clusterResourceType(t *testing.T) {
+	tests := []struct {
+		relPath string
+		want    string
+	}{
+		{relPath: "cluster-resources/deployments/default.json", want: "deployments"},
+		{relPath: "cluster-resources/custom-resources/backups.velero.io/velero.json", want: "custom-resources/backups.velero.io"},
+		{relPath: "cluster-resources/nodes.json", want: "nodes"},
+		{relPath: "cluster-resources/pods/logs/default/kotsadm/kotsadm.log", want: ""},
+		{relPath: "cluster-resources/auth-cani-list-errors.json", want: ""},
+		{relPath: "analysis.json", want: ""},
+		{relPath: "cluster-info/cluster_version.json", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.relPath, func(t *testing.T) {
+			assert.Equal(t, test.want, clusterResourceType(test.relPath))
+		})
+	}
+}
+
+func Test_compareBundleContents(t *testing.T) {
+	base := &bundleContents{
+		insights: []types.SupportBundleInsight{
+			{Key: "kubernetes-version", Severity: "info"},
+			{Key: "node-resources", Severity: "info"},
+			{Key: "removed", Severity: "warn"},
+		},
+		resources: map[string][]clusterResource{
+			"pods": {
+				pod("default", "web", "Running"),
+				pod("default", "worker", "Running"),
+				pod("default", "old", "Running"),
+			},
+			"deployments": {
+				deployment("default", "web", 1),
+				deployment("default", "worker", 1),
+			},
+			"events": {
+				event("default", "web.1", "uid-1"),
+			},
+		},
+	}
+	target := &bundleContents{
+		insights: []types.SupportBundleInsight{
+			{Key: "kubernetes-version", Severity: "info"},
+			{Key: "node-resources", Severity: "error", Primary: "Node resources", Detail: "not enough memory"},
+			{Key: "added", Severity: "warn"},
+		},
+		resources: map[string][]clusterResource{
+			"pods": {
+				pod("default", "web", "Running"),
+				pod("default", "worker", "Pending"),
+				pod("default", "new", "Running"),
+			},
+			"deployments": {
+				deployment("default", "web", 1),
+				deployment("default", "worker", 3),
+				deployment("default", "api", 1),
+			},
+			"events": {
+				event("default", "web.1", "uid-1"),
+				event("default", "worker.1", "uid-2"),
+			},
+		},
+	}
+
+	got := compareBundleContents(base, target)
+
+	assert.Equal(t, []types.AnalyzerChange{
+		{Key: "added", Change: types.ChangeAdded, TargetSeverity: "warn"},
+		{Key: "node-resources", Change: types.ChangeModified, BaseSeverity: "info", TargetSeverity: "error", Primary: "Node resources", Detail: "not enough memory"},
+		{Key: "removed", Change: types.ChangeRemoved, BaseSeverity: "warn"},
+	}, got.AnalyzerChanges)
+
+	assert.Equal(t, []types.ResourceChange{
+		{Resource: "pods", Namespace: "default", Name: "new", Change: types.ChangeAdded},
+		{Resource: "pods", Namespace: "default", Name: "old", Change: types.ChangeRemoved},
+		{Resource: "pods", Namespace: "default", Name: "worker", Change: types.ChangeModified, Detail: "phase Running -> Pending"},
+	}, got.PodChanges)
+
+	assert.Equal(t, []types.ResourceChange{
+		{Resource: "deployments", Namespace: "default", Name: "api", Change: types.ChangeAdded},
+		{Resource: "deployments", Namespace: "default", Name: "worker", Change: types.ChangeModified, Detail: "spec.replicas"},
+	}, got.ResourceChanges)
+
+	require.Len(t, got.NewEvents, 1)
+	assert.Equal(t, "worker.1", got.NewEvents[0].Name)
+	assert.Equal(t, "Pod/worker.1", got.NewEvents[0].InvolvedObject)
+	assert.Equal(t, int64(2), got.NewEvents[0].Count)
+
+	assert.Equal(t, []types.ClusterResourceDelta{
+		{Resource: "deployments", BaseCount: 2, TargetCount: 3},
+		{Resource: "events", BaseCount: 1, TargetCount: 2},
+	}, got.ClusterResourceDeltas)
+}
+
+func Test_Compare(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	baseArchive := writeTestBundle(t, tmpDir, "base", map[string]string{
+		"analysis.json": `[{"name":"node-resources","severity":"info","insight":{"primary":"Node resources"}}]`,
+		"cluster-resources/deployments/default.json": `{"kind":"DeploymentList","items":[{"metadata":{"name":"web","namespace":"default"},"spec":{"replicas":1}}]}`,
+		"cluster-resources/nodes.json":               `[{"metadata":{"name":"node-1"}}]`,
+	})
+	targetArchive := writeTestBundle(t, tmpDir, "target", map[string]string{
+		"analysis.json": `[{"name":"node-resources","severity":"error","insight":{"primary":"Node resources"}}]`,
+		"cluster-resources/deployments/default.json": `{"kind":"DeploymentList","items":[{"metadata":{"name":"web","namespace":"default"},"spec":{"replicas":2}}]}`,
+		"cluster-resources/nodes.json":               `[{"metadata":{"name":"node-1"}},{"metadata":{"name":"node-2"}}]`,
+	})
+
+	got, err := Compare(CompareInput{ID: "base", ArchivePath: baseArchive}, CompareInput{ID: "target", ArchivePath: targetArchive})
+	require.NoError(t, err)
+
+	assert.Equal(t, "base", got.BaseID)
+	assert.Equal(t, "target", got.TargetID)
+	assert.Equal(t, []types.AnalyzerChange{
+		{Key: "node-resources", Change: types.ChangeModified, BaseSeverity: "info", TargetSeverity: "error", Primary: "Node resources"},
+	}, got.AnalyzerChanges)
+	assert.Equal(t, []types.ResourceChange{
+		{Resource: "deployments", Namespace: "default", Name: "web", Change: types.ChangeModified, Detail: "spec.replicas"},
+		{Resource: "nodes", Name: "node-2", Change: types.ChangeAdded},
+	}, got.ResourceChanges)
+	assert.Equal(t, []types.ClusterResourceDelta{
+		{Resource: "nodes", BaseCount: 1, TargetCount: 2},
+	}, got.ClusterResourceDeltas)
+
+	// stored insights take precedence over the analysis in the archive
+	got, err = Compare(
+		CompareInput{ArchivePath: baseArchive, Insights: []types.SupportBundleInsight{}},
+		CompareInput{ArchivePath: targetArchive, Insights: []types.SupportBundleInsight{}},
+	)
+	require.NoError(t, err)
+	assert.Empty(t, got.AnalyzerChanges)
+}
+
+// writeTestBundle writes a support bundle archive with the files in a top level directory, like troubleshoot does
+func writeTestBundle(t *testing.T, tmpDir string, name string, files map[string]string) string {
+	bundleDir := filepath.Join(tmpDir, name, "support-bundle-2023-07-01T12_00_00")
+	for path, content := range files {
+		filePath := filepath.Join(bundleDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	archivePath := filepath.Join(tmpDir, name+".tar.gz")
+	tarGz := archiver.TarGz{
+		Tar: &archiver.Tar{
+			ImplicitTopLevelFolder: false,
+		},
+	}
+	require.NoError(t, tarGz.Archive([]string{bundleDir}, archivePath))
+
+	return archivePath
+}
+
+func pod(namespace string, name string, phase string) clusterResource {
+	return clusterResource{
+		namespace: namespace,
+		name:      name,
+		object: map[string]interface{}{
+			"status": map[string]interface{}{"phase": phase},
+		},
+	}
+}
+
+func deployment(namespace string, name string, replicas float64) clusterResource {
+	return clusterResource{
+		namespace: namespace,
+		name:      name,
+		object: map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": replicas},
+		},
+	}
+}
+
+func event(namespace string, name string, uid string) clusterResource {
+	return clusterResource{
+		namespace: namespace,
+		name:      name,
+		uid:       uid,
+		object: map[string]interface{}{
+			"type":           "Warning",
+			"count":          float64(2),
+			"involvedObject": map[string]interface{}{"kind": "Pod", "name": name},
+		},
+	}
+}
@@ -276,6 +276,12 @@ func executeSupportBundleCollectRoutine(bundle *types.SupportBundle, progressCha
 			logger.Error(errors.Wrap(err, "failed to set redactions"))
 			return
 		}
+
+		if bundle.IsScheduled {
+			if err := shareScheduledBundleOnFailure(bundle); err != nil {
+				logger.Error(errors.Wrapf(err, "failed to share scheduled support bundle %s", bundle.ID))
+			}
+		}
 	}()
 }
 
@@ -0,0 +1,206 @@
+package supportbundle
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+)
+
+// scheduledRun is the next scheduled collection for an app. It is only kept in memory, after a restart
+// the next run is computed from the most recent scheduled bundle so that a missed run is collected right away.
+type scheduledRun struct {
+	schedule string
+	next     time.Time
+}
+
+// nextScheduledRuns is only accessed from the scheduler loop
+var nextScheduledRuns = map[string]scheduledRun{}
+
+// StartScheduler starts collecting support bundles for apps that have a support bundle schedule
+func StartScheduler() error {
+	logger.Debug("starting support bundle scheduler")
+
+	go func() {
+		for {
+			scheduleLoop()
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	return nil
+}
+
+func scheduleLoop() {
+	appsList, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list installed apps for scheduled support bundles"))
+		return
+	}
+
+	for _, a := range appsList {
+		if err := handleScheduledApp(a, time.Now()); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to handle scheduled support bundles for app %s", a.ID))
+		}
+	}
+}
+
+func handleScheduledApp(a *apptypes.App, now time.Time) error {
+	if a.SupportBundleSchedule == nil || a.SupportBundleSchedule.Schedule == "" {
+		delete(nextScheduledRuns, a.ID)
+		return nil
+	}
+
+	bundles, err := store.GetStore().ListSupportBundles(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list support bundles")
+	}
+
+	run, ok := nextScheduledRuns[a.ID]
+	if !ok || run.schedule != a.SupportBundleSchedule.Schedule {
+		next, err := nextScheduledRun(a.SupportBundleSchedule, bundles, now)
+		if err != nil {
+			return errors.Wrap(err, "failed to get next scheduled run")
+		}
+		run = scheduledRun{
+			schedule: a.SupportBundleSchedule.Schedule,
+			next:     next,
+		}
+		nextScheduledRuns[a.ID] = run
+		logger.Debugf("Next scheduled support bundle for app %s at %s", a.ID, next.Format(time.RFC3339))
+	}
+
+	if run.next.After(now) {
+		return nil
+	}
+
+	for _, bundle := range bundles {
+		if bundle.IsScheduled && bundle.Status == types.BUNDLE_RUNNING {
+			logger.Infof("Postponing scheduled support bundle for app %s because one is in progress", a.ID)
+			return nil
+		}
+	}
+
+	downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list downstreams for app")
+	}
+	if len(downstreams) == 0 {
+		return errors.New("no downstreams found for app")
+	}
+
+	bundleID, err := CollectScheduled(a, downstreams[0].ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to collect scheduled support bundle")
+	}
+	logger.Infof("Collecting scheduled support bundle %s for app %s", bundleID, a.ID)
+
+	next, err := a.SupportBundleSchedule.Next(now)
+	if err != nil {
+		return errors.Wrap(err, "failed to get next scheduled run")
+	}
+	nextScheduledRuns[a.ID] = scheduledRun{
+		schedule: a.SupportBundleSchedule.Schedule,
+		next:     next,
+	}
+
+	// the bundle that is being collected counts towards the retention
+	for _, bundle := range expiredScheduledBundles(bundles, a.SupportBundleSchedule.GetRetention()-1) {
+		if err := store.GetStore().DeleteSupportBundle(bundle.ID, a.ID); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to delete expired scheduled support bundle %s", bundle.ID))
+			continue
+		}
+		logger.Infof("Deleted expired scheduled support bundle %s for app %s", bundle.ID, a.ID)
+	}
+
+	return nil
+}
+
+// nextScheduledRun returns the first scheduled time after the most recent scheduled bundle, or after now if there is none
+func nextScheduledRun(schedule *apptypes.SupportBundleSchedule, bundles []*types.SupportBundle, now time.Time) (time.Time, error) {
+	after := now
+	var lastScheduled *time.Time
+	for _, bundle := range bundles {
+		if !bundle.IsScheduled {
+			continue
+		}
+		if lastScheduled == nil || bundle.CreatedAt.After(*lastScheduled) {
+			createdAt := bundle.CreatedAt
+			lastScheduled = &createdAt
+		}
+	}
+	if lastScheduled != nil {
+		after = *lastScheduled
+	}
+
+	return schedule.Next(after)
+}
+
+// expiredScheduledBundles returns the scheduled bundles beyond the newest ones to keep.
+// Bundles collected on demand and bundles that are still running are never expired.
+func expiredScheduledBundles(bundles []*types.SupportBundle, keep int) []*types.SupportBundle {
+	scheduled := []*types.SupportBundle{}
+	for _, bundle := range bundles {
+		if bundle.IsScheduled && bundle.Status != types.BUNDLE_RUNNING {
+			scheduled = append(scheduled, bundle)
+		}
+	}
+
+	sort.Sort(sort.Reverse(types.ByCreated(scheduled)))
+
+	if keep < 0 {
+		keep = 0
+	}
+	if len(scheduled) <= keep {
+		return nil
+	}
+	return scheduled[keep:]
+}
+
+// shareScheduledBundleOnFailure shares a scheduled bundle with the vendor if the app's schedule
+// asks for it and the analysis reports an error
+func shareScheduledBundleOnFailure(bundle *types.SupportBundle) error {
+	app, err := store.GetStore().GetApp(bundle.AppID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app")
+	}
+	if app.SupportBundleSchedule == nil || !app.SupportBundleSchedule.UploadOnFailure {
+		return nil
+	}
+
+	analysis, err := store.GetStore().GetSupportBundleAnalysis(bundle.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get analysis")
+	}
+	if !analysisHasError(analysis) {
+		return nil
+	}
+
+	// share a fresh copy, the collect routine still holds the bundle
+	sharedBundle, err := store.GetStore().GetSupportBundle(bundle.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get support bundle")
+	}
+	if err := Share(app, sharedBundle); err != nil {
+		return errors.Wrap(err, "failed to share support bundle")
+	}
+	logger.Infof("Shared scheduled support bundle %s for app %s because analysis reported an error", bundle.ID, app.ID)
+
+	return nil
+}
+
+func analysisHasError(analysis *types.SupportBundleAnalysis) bool {
+	if analysis == nil {
+		return false
+	}
+	for _, insight := range analysis.Insights {
+		if insight.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
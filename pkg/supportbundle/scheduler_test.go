@@ -0,0 +1,114 @@
+package supportbundle
+
+import (
+	"testing"
+	"time"
+
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextScheduledRun(t *testing.T) {
+	schedule := &apptypes.SupportBundleSchedule{Schedule: "0 * * * *"}
+	now := time.Date(2023, 7, 1, 12, 20, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		bundles []*types.SupportBundle
+		want    time.Time
+	}{
+		{
+			name: "no scheduled bundles",
+			bundles: []*types.SupportBundle{
+				{ID: "manual", CreatedAt: now.Add(-10 * time.Hour)},
+			},
+			want: time.Date(2023, 7, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "after the most recent scheduled bundle",
+			bundles: []*types.SupportBundle{
+				{ID: "newest", IsScheduled: true, CreatedAt: time.Date(2023, 7, 1, 12, 0, 5, 0, time.UTC)},
+				{ID: "older", IsScheduled: true, CreatedAt: time.Date(2023, 7, 1, 11, 0, 5, 0, time.UTC)},
+			},
+			want: time.Date(2023, 7, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "a missed run is due",
+			bundles: []*types.SupportBundle{
+				{ID: "manual", CreatedAt: time.Date(2023, 7, 1, 12, 10, 0, 0, time.UTC)},
+				{ID: "scheduled", IsScheduled: true, CreatedAt: time.Date(2023, 7, 1, 9, 0, 5, 0, time.UTC)},
+			},
+			want: time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextScheduledRun(schedule, tt.bundles, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_expiredScheduledBundles(t *testing.T) {
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	bundle := func(id string, hoursAgo int, isScheduled bool, status types.SupportBundleStatus) *types.SupportBundle {
+		return &types.SupportBundle{
+			ID:          id,
+			IsScheduled: isScheduled,
+			Status:      status,
+			CreatedAt:   now.Add(-time.Duration(hoursAgo) * time.Hour),
+		}
+	}
+
+	bundles := []*types.SupportBundle{
+		bundle("running", 0, true, types.BUNDLE_RUNNING),
+		bundle("manual", 1, false, types.BUNDLE_UPLOADED),
+		bundle("scheduled-2", 2, true, types.BUNDLE_UPLOADED),
+		bundle("scheduled-4", 4, true, types.BUNDLE_FAILED),
+		bundle("scheduled-3", 3, true, types.BUNDLE_UPLOADED),
+	}
+
+	tests := []struct {
+		name string
+		keep int
+		want []string
+	}{
+		{
+			name: "keep all",
+			keep: 3,
+			want: []string{},
+		},
+		{
+			name: "oldest are expired",
+			keep: 1,
+			want: []string{"scheduled-3", "scheduled-4"},
+		},
+		{
+			name: "keep none",
+			keep: 0,
+			want: []string{"scheduled-2", "scheduled-3", "scheduled-4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, b := range expiredScheduledBundles(bundles, tt.keep) {
+				got = append(got, b.ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_analysisHasError(t *testing.T) {
+	assert.False(t, analysisHasError(nil))
+	assert.False(t, analysisHasError(&types.SupportBundleAnalysis{
+		Insights: []types.SupportBundleInsight{{Severity: "warn"}, {Severity: "info"}},
+	}))
+	assert.True(t, analysisHasError(&types.SupportBundleAnalysis{
+		Insights: []types.SupportBundleInsight{{Severity: "info"}, {Severity: "error"}},
+	}))
+}
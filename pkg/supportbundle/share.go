@@ -0,0 +1,100 @@
+package supportbundle
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/reporting"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+var (
+	ErrShareAirgap       = errors.New("Support bundle sharing is not supported for airgapped installations.")
+	ErrShareNotSupported = errors.New("License does not have support bundle sharing enabled")
+)
+
+// ShareError is returned when the vendor portal rejects the upload
+type ShareError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ShareError) Error() string {
+	return fmt.Sprintf("failed to share support bundle: %d: %s", e.StatusCode, e.Body)
+}
+
+// Share uploads the support bundle archive to the vendor and marks the bundle as shared
+func Share(app *apptypes.App, bundle *types.SupportBundle) error {
+	if app.IsAirgap {
+		return ErrShareAirgap
+	}
+
+	license, err := store.GetStore().GetLatestLicenseForApp(app.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest license")
+	}
+
+	if !license.Spec.IsSupportBundleUploadSupported {
+		return ErrShareNotSupported
+	}
+
+	bundleArchive, err := store.GetStore().GetSupportBundleArchive(bundle.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get support bundle archive")
+	}
+	defer os.RemoveAll(bundleArchive)
+
+	f, err := os.Open(bundleArchive)
+	if err != nil {
+		return errors.Wrap(err, "failed to open support bundle archive")
+	}
+	defer f.Close()
+
+	fileStat, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat support bundle archive")
+	}
+
+	endpoint := fmt.Sprintf("%s/supportbundle/upload/%s", license.Spec.Endpoint, license.Spec.AppSlug)
+
+	req, err := util.NewRequest("POST", endpoint, f)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	reportingInfo := reporting.GetReportingInfo(app.ID)
+	reporting.InjectReportingInfoHeaders(req, reportingInfo)
+
+	req.Header.Set("Content-Type", "application/tar+gzip")
+	req.Header.Set("X-Replicated-SupportBundle-CollectedAt", bundle.CreatedAt.Format(time.RFC3339))
+
+	req.ContentLength = fileStat.Size()
+
+	req.SetBasicAuth(license.Spec.LicenseID, license.Spec.LicenseID)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return &ShareError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	now := time.Now()
+	bundle.SharedAt = &now
+	if err := store.GetStore().UpdateSupportBundle(bundle); err != nil {
+		return errors.Wrap(err, "failed to update support bundle")
+	}
+
+	return nil
+}
@@ -39,6 +39,16 @@ const (
 // It returns the ID of the support bundle so that the status can be queried by the
 // front end.
 func Collect(app *apptypes.App, clusterID string) (string, error) {
+	return collect(app, clusterID, false)
+}
+
+// CollectScheduled will queue collection of a new support bundle for the app's support bundle schedule.
+// Scheduled bundles are subject to the schedule's retention.
+func CollectScheduled(app *apptypes.App, clusterID string) (string, error) {
+	return collect(app, clusterID, true)
+}
+
+func collect(app *apptypes.App, clusterID string, isScheduled bool) (string, error) {
 	sequence := int64(0)
 
 	currentVersion, err := store.GetStore().GetCurrentDownstreamVersion(app.ID, clusterID)
@@ -59,6 +69,7 @@ func Collect(app *apptypes.App, clusterID string) (string, error) {
 
 	supportBundle.ID = strings.ToLower(ksuid.New().String())
 	supportBundle.Slug = supportBundle.ID
+	supportBundle.IsScheduled = isScheduled
 
 	err = store.GetStore().CreateInProgressSupportBundle(supportBundle)
 	if err != nil {
@@ -0,0 +1,60 @@
+package types
+
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// SupportBundleComparison is what changed between a base and a target support bundle
+type SupportBundleComparison struct {
+	BaseID                string                 `json:"baseId,omitempty"`
+	TargetID              string                 `json:"targetId,omitempty"`
+	AnalyzerChanges       []AnalyzerChange       `json:"analyzerChanges"`
+	PodChanges            []ResourceChange       `json:"podChanges"`
+	NewEvents             []EventSummary         `json:"newEvents"`
+	ResourceChanges       []ResourceChange       `json:"resourceChanges"`
+	ClusterResourceDeltas []ClusterResourceDelta `json:"clusterResourceDeltas"`
+}
+
+// AnalyzerChange is an analyzer whose outcome differs between the bundles.
+// Primary and Detail are from the target bundle, or from the base bundle if the analyzer was removed.
+type AnalyzerChange struct {
+	Key            string     `json:"key"`
+	Change         ChangeType `json:"change"`
+	BaseSeverity   string     `json:"baseSeverity,omitempty"`
+	TargetSeverity string     `json:"targetSeverity,omitempty"`
+	Primary        string     `json:"primary"`
+	Detail         string     `json:"detail,omitempty"`
+}
+
+// ResourceChange is a cluster resource that was added, removed or modified.
+// Resource is the type of the resource as it is named in the cluster-resources directory, e.g. "deployments".
+type ResourceChange struct {
+	Resource  string     `json:"resource"`
+	Namespace string     `json:"namespace,omitempty"`
+	Name      string     `json:"name"`
+	Change    ChangeType `json:"change"`
+	Detail    string     `json:"detail,omitempty"`
+}
+
+// EventSummary is an event that is in the target bundle but not in the base bundle
+type EventSummary struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	InvolvedObject string `json:"involvedObject"`
+	Count          int64  `json:"count,omitempty"`
+	LastTimestamp  string `json:"lastTimestamp,omitempty"`
+}
+
+// ClusterResourceDelta is a resource type whose count differs between the bundles
+type ClusterResourceDelta struct {
+	Resource    string `json:"resource"`
+	BaseCount   int    `json:"baseCount"`
+	TargetCount int    `json:"targetCount"`
+}
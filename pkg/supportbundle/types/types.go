@@ -29,6 +29,8 @@ type SupportBundle struct {
 	Progress   SupportBundleProgress `json:"progress"`
 	URI        string                `json:"uri"`
 	RedactURIs []string              `json:"redactURIs"`
+	// IsScheduled is true for bundles collected by the app's support bundle schedule
+	IsScheduled bool `json:"isScheduled,omitempty"`
 
 	BundleSpec          *troubleshootv1beta2.SupportBundle `json:"-"`
 	AdditionalRedactors *troubleshootv1beta2.Redactor      `json:"-"`
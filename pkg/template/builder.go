@@ -12,6 +12,7 @@ import (
 	dockerregistrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
+	"github.com/replicatedhq/kots/pkg/secretref"
 )
 
 var (
@@ -65,7 +66,7 @@ func NewBuilder(opts BuilderOptions) (Builder, map[string]ItemValue, error) {
 	}
 
 	b.Ctx = []Ctx{
-		StaticCtx{},
+		newStaticCtx(),
 		licenseCtx{License: opts.License, App: opts.Application, VersionInfo: opts.VersionInfo},
 		newKurlContext("base", "default"), // can be hardcoded because kurl always deploys to the default namespace
 		newVersionCtx(opts.VersionInfo),
@@ -233,5 +234,23 @@ func (b *Builder) RenderTemplate(name string, text string) (string, error) {
 		curText = contents.String()
 	}
 
+	if err := secretref.CheckUsage([]byte(curText), b.SecretRefs()); err != nil {
+		return "", errors.Wrap(err, "failed to check secret references")
+	}
+
 	return curText, nil
 }
+
+// SecretRefs returns the secret references in the config item values.
+func (b *Builder) SecretRefs() []string {
+	refs := []string{}
+	for _, ctx := range b.Ctx {
+		switch configCtx := ctx.(type) {
+		case ConfigCtx:
+			refs = append(refs, configCtx.secretRefs()...)
+		case *ConfigCtx:
+			refs = append(refs, configCtx.secretRefs()...)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,248 @@
+package template
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// clusterLookupCache holds the results of cluster lookups for the lifetime of a builder,
+// so that a template that calls the same function many times only queries the cluster once.
+// Lookups that fail are cached as empty values as well.
+type clusterLookupCache struct {
+	mtx                  sync.Mutex
+	objects              map[string]map[string]interface{}
+	apiResources         map[string]*metav1.APIResourceList
+	nodes                []corev1.Node
+	nodesLoaded          bool
+	storageClasses       []storagev1.StorageClass
+	storageClassesLoaded bool
+}
+
+func newClusterLookupCache() *clusterLookupCache {
+	return &clusterLookupCache{
+		objects:      map[string]map[string]interface{}{},
+		apiResources: map[string]*metav1.APIResourceList{},
+	}
+}
+
+// lookup returns the object with the given name, or a list of the objects in the namespace if the name is empty, like Helm's lookup function.
+// An empty map is returned if the object does not exist or cannot be read.
+func (ctx StaticCtx) lookup(apiVersion string, kind string, namespace string, name string) map[string]interface{} {
+	cacheKey := strings.Join([]string{apiVersion, kind, namespace, name}, "/")
+	if obj, ok := ctx.cachedObject(cacheKey); ok {
+		return obj
+	}
+
+	obj, err := ctx.lookupObject(apiVersion, kind, namespace, name)
+	if err != nil {
+		obj = map[string]interface{}{}
+	}
+
+	ctx.cacheObject(cacheKey, obj)
+	return obj
+}
+
+func (ctx StaticCtx) lookupObject(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+	resource := ctx.findAPIResource(apiVersion, kind)
+	if resource == nil {
+		return nil, errors.Errorf("resource %s %s not found", apiVersion, kind)
+	}
+
+	dynamicClient, err := ctx.getDynamicClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dynamic client")
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse api version")
+	}
+
+	var client dynamic.ResourceInterface = dynamicClient.Resource(gv.WithResource(resource.Name))
+	if resource.Namespaced {
+		client = dynamicClient.Resource(gv.WithResource(resource.Name)).Namespace(namespace)
+	}
+
+	if name == "" {
+		list, err := client.List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list objects")
+		}
+		return list.UnstructuredContent(), nil
+	}
+
+	obj, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+	return obj.UnstructuredContent(), nil
+}
+
+// hasCRD returns true if the cluster serves the kind in the group version, e.g. HasCRD "cert-manager.io/v1" "Certificate"
+func (ctx StaticCtx) hasCRD(apiVersion string, kind string) bool {
+	return ctx.findAPIResource(apiVersion, kind) != nil
+}
+
+// storageClasses returns the names of the storage classes in the cluster, sorted by name
+func (ctx StaticCtx) storageClasses() []string {
+	names := []string{}
+	for _, storageClass := range ctx.listStorageClasses() {
+		names = append(names, storageClass.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultStorageClass returns the name of the default storage class, or an empty string if there is none
+func (ctx StaticCtx) defaultStorageClass() string {
+	for _, storageClass := range ctx.listStorageClasses() {
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" || storageClass.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return storageClass.Name
+		}
+	}
+	return ""
+}
+
+// nodeLabels returns the labels of every node in the cluster, keyed by node name
+func (ctx StaticCtx) nodeLabels() map[string]map[string]string {
+	labels := map[string]map[string]string{}
+	for _, node := range ctx.listNodes() {
+		nodeLabels := map[string]string{}
+		for k, v := range node.Labels {
+			nodeLabels[k] = v
+		}
+		labels[node.Name] = nodeLabels
+	}
+	return labels
+}
+
+func (ctx StaticCtx) findAPIResource(apiVersion string, kind string) *metav1.APIResource {
+	resources := ctx.getAPIResources(apiVersion)
+	for _, resource := range resources.APIResources {
+		// subresources such as deployments/scale share the kind of their parent
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			r := resource
+			return &r
+		}
+	}
+	return nil
+}
+
+func (ctx StaticCtx) getAPIResources(apiVersion string) *metav1.APIResourceList {
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.mtx.Lock()
+		resources, ok := ctx.lookupCache.apiResources[apiVersion]
+		ctx.lookupCache.mtx.Unlock()
+		if ok {
+			return resources
+		}
+	}
+
+	// the group version is not served, or discovery is not allowed
+	resources := &metav1.APIResourceList{GroupVersion: apiVersion}
+
+	if clientset, err := ctx.getClientset(); err == nil {
+		if serverResources, err := clientset.Discovery().ServerResourcesForGroupVersion(apiVersion); err == nil {
+			resources = serverResources
+		}
+	}
+
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.mtx.Lock()
+		ctx.lookupCache.apiResources[apiVersion] = resources
+		ctx.lookupCache.mtx.Unlock()
+	}
+
+	return resources
+}
+
+func (ctx StaticCtx) listNodes() []corev1.Node {
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.mtx.Lock()
+		defer ctx.lookupCache.mtx.Unlock()
+		if ctx.lookupCache.nodesLoaded {
+			return ctx.lookupCache.nodes
+		}
+	}
+
+	nodes := []corev1.Node{}
+	if clientset, err := ctx.getClientset(); err == nil {
+		if nodeList, err := getNodes(clientset); err == nil {
+			nodes = nodeList
+		}
+	}
+
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.nodes = nodes
+		ctx.lookupCache.nodesLoaded = true
+	}
+	return nodes
+}
+
+func (ctx StaticCtx) listStorageClasses() []storagev1.StorageClass {
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.mtx.Lock()
+		defer ctx.lookupCache.mtx.Unlock()
+		if ctx.lookupCache.storageClassesLoaded {
+			return ctx.lookupCache.storageClasses
+		}
+	}
+
+	storageClasses := []storagev1.StorageClass{}
+	if clientset, err := ctx.getClientset(); err == nil {
+		if scList, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{}); err == nil {
+			storageClasses = scList.Items
+		}
+	}
+
+	if ctx.lookupCache != nil {
+		ctx.lookupCache.storageClasses = storageClasses
+		ctx.lookupCache.storageClassesLoaded = true
+	}
+	return storageClasses
+}
+
+func (ctx StaticCtx) cachedObject(key string) (map[string]interface{}, bool) {
+	if ctx.lookupCache == nil {
+		return nil, false
+	}
+	ctx.lookupCache.mtx.Lock()
+	defer ctx.lookupCache.mtx.Unlock()
+	obj, ok := ctx.lookupCache.objects[key]
+	return obj, ok
+}
+
+func (ctx StaticCtx) cacheObject(key string, obj map[string]interface{}) {
+	if ctx.lookupCache == nil {
+		return
+	}
+	ctx.lookupCache.mtx.Lock()
+	defer ctx.lookupCache.mtx.Unlock()
+	ctx.lookupCache.objects[key] = obj
+}
+
+func (ctx StaticCtx) getDynamicClient() (dynamic.Interface, error) {
+	if ctx.dynamicClient != nil {
+		return ctx.dynamicClient, nil
+	}
+	dynamicClient, err := k8sutil.GetDynamicClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dynamic client")
+	}
+	return dynamicClient, nil
+}
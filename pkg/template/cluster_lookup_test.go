@@ -0,0 +1,193 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testLookupCtx(t *testing.T) (StaticCtx, *int) {
+	clientset := fakeclientset.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-1",
+				Labels: map[string]string{"node-role.kubernetes.io/control-plane": "", "topology.kubernetes.io/zone": "us-east-1a"},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-2",
+				Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1b"},
+			},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "standard",
+			},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "local-path",
+				Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+			},
+		},
+	)
+
+	storageClassLists := 0
+	clientset.PrependReactor("list", "storageclasses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		storageClassLists++
+		return false, nil, nil
+	})
+
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "secrets", Kind: "Secret", Namespaced: true},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "cert-manager.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterissuers", Kind: "ClusterIssuer", Namespaced: false},
+				{Name: "certificates", Kind: "Certificate", Namespaced: true},
+				{Name: "certificates/status", Kind: "Certificate", Namespaced: true},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	clusterIssuer := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "ClusterIssuer",
+			"metadata":   map[string]interface{}{"name": "letsencrypt"},
+		},
+	}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}: "ClusterIssuerList",
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("cGFzc3dvcmQ=")},
+		},
+		clusterIssuer,
+	)
+	dynamicClient.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "kube-system" {
+			return true, nil, kuberneteserrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "db", nil)
+		}
+		return false, nil, nil
+	})
+
+	return StaticCtx{
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		lookupCache:   newClusterLookupCache(),
+	}, &storageClassLists
+}
+
+func TestStaticCtx_clusterLookups(t *testing.T) {
+	ctx, storageClassLists := testLookupCtx(t)
+
+	builder := Builder{}
+	builder.AddCtx(ctx)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "lookup a secret",
+			template: `{{repl (Lookup "v1" "Secret" "default" "db").metadata.name }}`,
+			want:     "db",
+		},
+		{
+			name:     "lookup a cluster scoped custom resource",
+			template: `{{repl (Lookup "cert-manager.io/v1" "ClusterIssuer" "" "letsencrypt").metadata.name }}`,
+			want:     "letsencrypt",
+		},
+		{
+			name:     "lookup a list",
+			template: `{{repl len (Lookup "cert-manager.io/v1" "ClusterIssuer" "" "").items }}`,
+			want:     "1",
+		},
+		{
+			name:     "lookup a missing object",
+			template: `{{repl if Lookup "v1" "Secret" "default" "missing" }}found{{repl else }}missing{{repl end }}`,
+			want:     "missing",
+		},
+		{
+			name:     "lookup a forbidden object",
+			template: `{{repl if Lookup "v1" "Secret" "kube-system" "db" }}found{{repl else }}missing{{repl end }}`,
+			want:     "missing",
+		},
+		{
+			name:     "lookup an unknown kind",
+			template: `{{repl len (Lookup "example.com/v1" "Widget" "default" "widget") }}`,
+			want:     "0",
+		},
+		{
+			name:     "has crd",
+			template: `{{repl HasCRD "cert-manager.io/v1" "Certificate" }} {{repl HasCRD "cert-manager.io/v1" "Issuer" }} {{repl HasCRD "example.com/v1" "Widget" }}`,
+			want:     "true false false",
+		},
+		{
+			name:     "storage classes",
+			template: `{{repl StorageClasses | join "," }}`,
+			want:     "local-path,standard",
+		},
+		{
+			name:     "default storage class",
+			template: `{{repl DefaultStorageClass }}`,
+			want:     "local-path",
+		},
+		{
+			name:     "node labels",
+			template: `{{repl range $node, $labels := NodeLabels }}{{repl $node }}={{repl index $labels "topology.kubernetes.io/zone" }};{{repl end }}`,
+			want:     "node-1=us-east-1a;node-2=us-east-1b;",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := builder.String(test.template)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	// storage classes are listed once per render
+	assert.Equal(t, 1, *storageClassLists)
+}
+
+func TestStaticCtx_clusterLookupsWithoutAccess(t *testing.T) {
+	clientset := fakeclientset.NewSimpleClientset()
+	clientset.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kuberneteserrors.NewForbidden(schema.GroupResource{Resource: action.GetResource().Resource}, "", nil)
+	})
+
+	builder := Builder{}
+	builder.AddCtx(StaticCtx{clientset: clientset, dynamicClient: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme()), lookupCache: newClusterLookupCache()})
+
+	got, err := builder.String(`{{repl len StorageClasses }}|{{repl DefaultStorageClass }}|{{repl len NodeLabels }}|{{repl HasCRD "cert-manager.io/v1" "Certificate" }}|{{repl len (Lookup "v1" "Secret" "default" "db") }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "0||0|false|0", got)
+}
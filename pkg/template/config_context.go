@@ -13,6 +13,8 @@ import (
 	dockerregistrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	"github.com/replicatedhq/kots/pkg/image"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
+	"github.com/replicatedhq/kots/pkg/secretref"
+	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -84,7 +86,7 @@ func (b *Builder) newConfigContext(configGroups []kotsv1beta1.ConfigGroup, exist
 	builder := Builder{
 		Ctx: []Ctx{
 			configCtx,
-			StaticCtx{},
+			newStaticCtx(),
 			&licenseCtx{License: license, App: app, VersionInfo: info},
 			newKurlContext("base", "default"),
 			newVersionCtx(info),
@@ -178,6 +180,7 @@ func (ctx ConfigCtx) FuncMap() template.FuncMap {
 		"ConfigOptionFilename":         ctx.configOptionFilename,
 		"ConfigOptionEquals":           ctx.configOptionEquals,
 		"ConfigOptionNotEquals":        ctx.configOptionNotEquals,
+		"ConfigOptionSecret":           ctx.configOptionSecret,
 		"LocalRegistryAddress":         ctx.localRegistryAddress,
 		"LocalRegistryHost":            ctx.localRegistryHost,
 		"LocalRegistryNamespace":       ctx.localRegistryNamespace,
@@ -212,12 +215,32 @@ func isReadOnly(item kotsv1beta1.ConfigItem) bool {
 	return !editable
 }
 
-func (ctx ConfigCtx) configOption(name string) string {
+// SecretReference is the type of the values returned by ConfigOption for items that reference a secret.
+// References are only resolved when the version is deployed, so the value is not a string to make the render fail
+// when it's piped into a function that would encode or transform the reference instead of the secret.
+type SecretReference string
+
+// configOption returns the value of the item. Secret references are returned as is so that the secret value is never
+// written to the app version archive, they are resolved in the rendered manifests when the version is deployed.
+// References to external secrets operator stores can only be used with ConfigOptionSecret.
+func (ctx ConfigCtx) configOption(name string) (interface{}, error) {
 	v, err := ctx.getConfigOptionValue(name)
 	if err != nil {
-		return ""
+		return "", nil
+	}
+
+	ref, err := secretref.Parse(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse secret reference for config item %s", name)
 	}
-	return v
+	if ref == nil {
+		return v, nil
+	}
+	if ref.Provider == secretref.ProviderExternalSecret {
+		return "", errors.Errorf("config item %s references an external secret and can only be used with ConfigOptionSecret", name)
+	}
+
+	return SecretReference(v), nil
 }
 
 func (ctx ConfigCtx) configOptionName(name string) string {
@@ -228,18 +251,37 @@ func (ctx ConfigCtx) configOptionIndex(name string) string {
 	return ""
 }
 
-func (ctx ConfigCtx) configOptionData(name string) string {
+func (ctx ConfigCtx) configOptionData(name string) (string, error) {
 	v, err := ctx.getConfigOptionValue(name)
 	if err != nil {
-		return ""
+		return "", nil
+	}
+
+	if secretref.IsRef(v) {
+		return "", errors.Errorf("config item %s references a secret and cannot be decoded, use ConfigOption or ConfigOptionSecret instead", name)
 	}
 
 	decoded, err := base64.StdEncoding.DecodeString(v)
 	if err != nil {
-		return ""
+		return "", nil
 	}
 
-	return string(decoded)
+	return string(decoded), nil
+}
+
+// secretRefs returns the values of the items that reference a secret.
+func (ctx ConfigCtx) secretRefs() []string {
+	refs := []string{}
+	for name := range ctx.ItemValues {
+		v, err := ctx.getConfigOptionValue(name)
+		if err != nil {
+			continue
+		}
+		if secretref.IsRef(v) {
+			refs = append(refs, v)
+		}
+	}
+	return refs
 }
 
 func (ctx ConfigCtx) configOptionFilename(itemName string) string {
@@ -269,6 +311,52 @@ func (ctx ConfigCtx) configOptionNotEquals(name string, value string) bool {
 	return value != val
 }
 
+// configOptionSecret returns a Secret named secretName with the value of the item stored under the item name.
+// If the item references an external secrets operator store, an ExternalSecret that creates the Secret is returned instead
+// so that the value is never read by kots. Other references are stored unresolved in stringData and resolved at deploy time.
+func (ctx ConfigCtx) configOptionSecret(name string, secretName string) (string, error) {
+	v, err := ctx.getConfigOptionValue(name)
+	if err != nil {
+		return "", nil
+	}
+
+	ref, err := secretref.Parse(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse secret reference for config item %s", name)
+	}
+	if ref != nil && ref.Provider == secretref.ProviderExternalSecret {
+		externalSecret, err := secretref.ExternalSecret(*ref, secretName, name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to create external secret for config item %s", name)
+		}
+		return string(externalSecret), nil
+	}
+
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]string{
+			"name": secretName,
+		},
+		"type": "Opaque",
+	}
+	if ref != nil {
+		secret["stringData"] = map[string]string{
+			name: v,
+		}
+	} else {
+		secret["data"] = map[string]string{
+			name: base64.StdEncoding.EncodeToString([]byte(v)),
+		}
+	}
+
+	b, err := yaml.Marshal(secret)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal secret")
+	}
+	return string(b), nil
+}
+
 func (ctx ConfigCtx) localRegistryAddress() string {
 	if ctx.LocalRegistry.Namespace == "" {
 		return ctx.LocalRegistry.Hostname
@@ -728,3 +728,132 @@ func TestConfigCtx_localRegistryImagePullSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigCtx_secretReferences(t *testing.T) {
+	ctx := ConfigCtx{
+		ItemValues: map[string]ItemValue{
+			"plain":     {Value: "plain-value"},
+			"vault_ref": {Value: "ref+vault://secret/data/my-app#db_password"},
+			"invalid":   {Value: "ref+vault://secret/data/my-app"},
+			"eso_ref":   {Value: "ref+externalsecret://SecretStore/vault/my-app#password"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "plain value",
+			template: `repl{{ ConfigOption "plain" }}`,
+			want:     "plain-value",
+		},
+		{
+			name:     "reference is not resolved",
+			template: `repl{{ ConfigOption "vault_ref" }}`,
+			want:     "ref+vault://secret/data/my-app#db_password",
+		},
+		{
+			name:     "reference used as a whole value",
+			template: "password: 'repl{{ ConfigOption \"vault_ref\" }}'\nurl: postgres://db:5432",
+			want:     "password: 'ref+vault://secret/data/my-app#db_password'\nurl: postgres://db:5432",
+		},
+		{
+			name:     "reference piped into a function fails the render",
+			template: `repl{{ ConfigOption "vault_ref" | Base64Encode }}`,
+			wantErr:  true,
+		},
+		{
+			name:     "reference embedded in another value fails the render",
+			template: `url: postgres://admin:repl{{ ConfigOption "vault_ref" }}@db:5432`,
+			wantErr:  true,
+		},
+		{
+			name:     "reference embedded in a template that is not yaml fails the render",
+			template: "url: {{ .Values.url }}\npassword: repl{{ ConfigOption \"vault_ref\" }}-suffix",
+			wantErr:  true,
+		},
+		{
+			name:     "reference in a template that is not yaml",
+			template: "url: {{ .Values.url }}\npassword: repl{{ ConfigOption \"vault_ref\" }}",
+			want:     "url: {{ .Values.url }}\npassword: ref+vault://secret/data/my-app#db_password",
+		},
+		{
+			name:     "reference cannot be decoded",
+			template: `repl{{ ConfigOptionData "vault_ref" }}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid reference fails the render",
+			template: `repl{{ ConfigOption "invalid" }}`,
+			wantErr:  true,
+		},
+		{
+			name:     "external secret reference cannot be read",
+			template: `repl{{ ConfigOption "eso_ref" }}`,
+			wantErr:  true,
+		},
+		{
+			name:     "secret for a plain value",
+			template: `repl{{ ConfigOptionSecret "plain" "db" }}`,
+			want: `apiVersion: v1
+data:
+  plain: cGxhaW4tdmFsdWU=
+kind: Secret
+metadata:
+  name: db
+type: Opaque
+`,
+		},
+		{
+			name:     "secret for a reference",
+			template: `repl{{ ConfigOptionSecret "vault_ref" "db" }}`,
+			want: `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  vault_ref: ref+vault://secret/data/my-app#db_password
+type: Opaque
+`,
+		},
+		{
+			name:     "external secret for an external secret reference",
+			template: `repl{{ ConfigOptionSecret "eso_ref" "db" }}`,
+			want: `apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: db
+spec:
+  refreshInterval: 1h
+  secretStoreRef:
+    kind: SecretStore
+    name: vault
+  target:
+    name: db
+    creationPolicy: Owner
+  data:
+  - secretKey: eso_ref
+    remoteRef:
+      key: my-app
+      property: password
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			builder := Builder{Ctx: []Ctx{ctx}}
+			got, err := builder.String(tt.template)
+			if tt.wantErr {
+				req.Error(err)
+				return
+			}
+			req.NoError(err)
+			req.Equal(tt.want, got)
+		})
+	}
+}
@@ -36,6 +36,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	certUtil "k8s.io/client-go/util/cert"
 )
@@ -47,6 +48,16 @@ type Ctx interface {
 type StaticCtx struct {
 	// a new clientset will be initialized if nil
 	clientset kubernetes.Interface
+	// a new dynamic client will be initialized if nil
+	dynamicClient dynamic.Interface
+	// cluster lookups are not cached if nil
+	lookupCache *clusterLookupCache
+}
+
+func newStaticCtx() StaticCtx {
+	return StaticCtx{
+		lookupCache: newClusterLookupCache(),
+	}
 }
 
 type TLSPair struct {
@@ -96,6 +107,11 @@ func (ctx StaticCtx) FuncMap() template.FuncMap {
 	funcMap["IsKurl"] = ctx.isKurl
 	funcMap["Distribution"] = ctx.distribution
 	funcMap["NodeCount"] = ctx.nodeCount
+	funcMap["NodeLabels"] = ctx.nodeLabels
+	funcMap["StorageClasses"] = ctx.storageClasses
+	funcMap["DefaultStorageClass"] = ctx.defaultStorageClass
+	funcMap["Lookup"] = ctx.lookup
+	funcMap["HasCRD"] = ctx.hasCRD
 
 	funcMap["HTTPSProxy"] = ctx.httpsProxy
 	funcMap["HTTPProxy"] = ctx.httpProxy
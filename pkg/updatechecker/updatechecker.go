@@ -64,7 +64,9 @@ func Start() error {
 // Configure will check if the app has scheduled update checks enabled and:
 // if enabled, and cron job was NOT found: add a new cron job to check app updates
 // if enabled, and a cron job was found, update the existing cron job with the latest cron spec
-// if disabled: stop the current running cron job (if exists)
+// if disabled: remove the update check from the cron job
+// the start of the app's auto deploy window is scheduled regardless of the update check spec,
+// and the cron job is stopped when it has nothing left to run
 // no-op for airgap applications
 func Configure(a apptypes.AppType, updateCheckerSpec string) error {
 	appId := a.GetID()
@@ -83,11 +85,6 @@ func Configure(a apptypes.AppType, updateCheckerSpec string) error {
 
 	cronSpec := updateCheckerSpec
 
-	if cronSpec == "@never" || cronSpec == "" {
-		Stop(appId)
-		return nil
-	}
-
 	if cronSpec == "@default" {
 		// check for updates every 4 hours
 		t := time.Now()
@@ -113,6 +110,32 @@ func Configure(a apptypes.AppType, updateCheckerSpec string) error {
 	jobAppID := appId
 	jobAppSlug := appSlug
 
+	if cronSpec != "@never" && cronSpec != "" {
+		if err := addUpdateCheckFunc(job, cronSpec, jobAppID, jobAppSlug); err != nil {
+			return errors.Wrap(err, "failed to add update check func")
+		}
+	}
+
+	if !util.IsHelmManaged() {
+		if err := addAutoDeployWindowFunc(job, jobAppID); err != nil {
+			return errors.Wrap(err, "failed to add auto deploy window func")
+		}
+	}
+
+	jobs[appId] = job
+
+	if len(job.Entries()) == 0 {
+		Stop(appId)
+		return nil
+	}
+
+	job.Start()
+
+	return nil
+}
+
+// addUpdateCheckFunc adds a func to the job that checks for updates for the app on the cron spec.
+func addUpdateCheckFunc(job *cron.Cron, cronSpec string, jobAppID string, jobAppSlug string) error {
 	_, err := job.AddFunc(cronSpec, func() {
 		logger.Debug("checking updates for app", zap.String("slug", jobAppSlug))
 
@@ -139,8 +162,59 @@ func Configure(a apptypes.AppType, updateCheckerSpec string) error {
 		return errors.Wrap(err, "failed to add func")
 	}
 
-	job.Start()
-	jobs[appId] = job
+	return nil
+}
+
+// addAutoDeployWindowFunc adds a func to the job that deploys versions that were queued
+// while outside of the app's auto deploy window, at the start of each window.
+func addAutoDeployWindowFunc(job *cron.Cron, appID string) error {
+	a, err := store.GetApp(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app")
+	}
+	if a.AutoDeployWindow == nil || a.AutoDeploy == "" || a.AutoDeploy == apptypes.AutoDeployDisabled {
+		return nil
+	}
+
+	windowSpec, err := a.AutoDeployWindow.StartSpec()
+	if err != nil {
+		return errors.Wrap(err, "failed to get window start spec")
+	}
+
+	jobAppSlug := a.Slug
+
+	_, err = job.AddFunc(windowSpec, func() {
+		logger.Debug("auto deploy window started for app", zap.String("slug", jobAppSlug))
+
+		if err := deployQueuedVersion(appID); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to deploy queued version for app %s", jobAppSlug))
+		}
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to add func")
+	}
+
+	return nil
+}
+
+// deployQueuedVersion deploys the version that matches the app's auto deploy configuration, if any.
+// versions that were downloaded outside of the auto deploy window are not deployed until this runs.
+func deployQueuedVersion(appID string) error {
+	downstreams, err := store.ListDownstreamsForApp(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list downstreams for app")
+	}
+	if len(downstreams) == 0 {
+		return errors.Errorf("no downstreams found for app %q", appID)
+	}
+
+	opts := CheckForUpdatesOpts{
+		AppID:       appID,
+		IsAutomatic: true,
+	}
+	if err := ensureDesiredVersionIsDeployed(opts, downstreams[0].ClusterID); err != nil {
+		return errors.Wrap(err, "failed to ensure desired version is deployed")
+	}
 
 	return nil
 }
@@ -519,6 +593,17 @@ func ensureDesiredVersionIsDeployed(opts CheckForUpdatesOpts, clusterID string)
 	if err != nil {
 		return errors.Wrap(err, "failed to get app")
 	}
+	if a.AutoDeployWindow != nil {
+		isOpen, err := a.AutoDeployWindow.IsOpen(time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to check auto deploy window")
+		}
+		if !isOpen {
+			// the version stays pending and will be deployed when the next window starts
+			logger.Infof("not auto deploying app %s outside of its auto deploy window", a.Slug)
+			return nil
+		}
+	}
 	if err := autoDeploy(opts, clusterID, a.AutoDeploy); err != nil {
 		return errors.Wrap(err, "failed to auto deploy")
 	}
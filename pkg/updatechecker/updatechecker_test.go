@@ -918,3 +918,123 @@ func Test_removeOldUpdates(t *testing.T) {
 		req.Equal(test.want, got)
 	}
 }
+
+func TestEnsureDesiredVersionIsDeployedOutsideAutoDeployWindowDoesNothing(t *testing.T) {
+	var appID = "some-app"
+	var clusterID = "some-cluster-id"
+	var opts = CheckForUpdatesOpts{
+		AppID:       appID,
+		IsAutomatic: true,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_store.NewMockStore(ctrl)
+	mockStore.EXPECT().GetApp(appID).Return(&apptypes.App{
+		ID:         appID,
+		AutoDeploy: apptypes.AutoDeploySequence,
+		AutoDeployWindow: &apptypes.MaintenanceWindow{
+			// february 30th never happens, so the window is never open
+			Cron:     "0 0 30 2 *",
+			Duration: "1h",
+		},
+	}, nil)
+
+	store = mockStore
+
+	// no other store calls are expected since the version is not deployed
+	err := ensureDesiredVersionIsDeployed(opts, clusterID)
+	if err != nil {
+		t.Errorf("ensureDesiredVersionIsDeployed() returned error = %v, wanted nil", err)
+	}
+}
+
+func TestEnsureDesiredVersionIsDeployedInvalidAutoDeployWindowErrors(t *testing.T) {
+	var appID = "some-app"
+	var clusterID = "some-cluster-id"
+	var opts = CheckForUpdatesOpts{
+		AppID:       appID,
+		IsAutomatic: true,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_store.NewMockStore(ctrl)
+	mockStore.EXPECT().GetApp(appID).Return(&apptypes.App{
+		ID:         appID,
+		AutoDeploy: apptypes.AutoDeploySequence,
+		AutoDeployWindow: &apptypes.MaintenanceWindow{
+			Days:      []string{"someday"},
+			StartTime: "01:00",
+			EndTime:   "02:00",
+		},
+	}, nil)
+
+	store = mockStore
+
+	err := ensureDesiredVersionIsDeployed(opts, clusterID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to check auto deploy window")
+}
+
+func TestConfigureSchedulesAutoDeployWindow(t *testing.T) {
+	var appID = "some-app"
+
+	window := &apptypes.MaintenanceWindow{
+		Days:      []string{"saturday"},
+		StartTime: "01:00",
+		EndTime:   "03:00",
+	}
+
+	tests := []struct {
+		name              string
+		updateCheckerSpec string
+		autoDeployWindow  *apptypes.MaintenanceWindow
+		wantEntries       int
+	}{
+		{
+			name:              "update checks and window",
+			updateCheckerSpec: "@default",
+			autoDeployWindow:  window,
+			wantEntries:       2,
+		},
+		{
+			name:              "update checks disabled with window",
+			updateCheckerSpec: "@never",
+			autoDeployWindow:  window,
+			wantEntries:       1,
+		},
+		{
+			name:              "update checks disabled without window",
+			updateCheckerSpec: "@never",
+			wantEntries:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			a := &apptypes.App{
+				ID:               appID,
+				Slug:             appID,
+				AutoDeploy:       apptypes.AutoDeploySequence,
+				AutoDeployWindow: tt.autoDeployWindow,
+			}
+
+			mockStore := mock_store.NewMockStore(ctrl)
+			mockStore.EXPECT().GetApp(appID).Return(a, nil)
+
+			store = mockStore
+
+			err := Configure(a, tt.updateCheckerSpec)
+			require.NoError(t, err)
+			defer func() {
+				Stop(appID)
+				delete(jobs, appID)
+			}()
+
+			require.Len(t, jobs[appID].Entries(), tt.wantEntries)
+		})
+	}
+}
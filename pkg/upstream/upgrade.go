@@ -13,11 +13,15 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/airgap/integrity"
 	"github.com/replicatedhq/kots/pkg/auth"
 	registrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsadm"
 	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	kotslicense "github.com/replicatedhq/kots/pkg/license"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/util"
 	kustomizetypes "sigs.k8s.io/kustomize/api/types"
@@ -39,10 +43,14 @@ type UpgradeRelease struct {
 
 type UpgradeOptions struct {
 	AirgapBundle        string
+	License             *kotsv1beta1.License
 	RegistryConfig      kotsadmtypes.RegistryConfig
 	IsKurl              bool
 	DisableImagePush    bool
+	SkipIntegrityCheck  bool
 	UpdateCheckEndpoint string
+	LicenseEndpoint     string
+	VerifyDeltaEndpoint string
 	Namespace           string
 	Debug               bool
 	Deploy              bool
@@ -57,6 +65,20 @@ func Upgrade(appSlug string, options UpgradeOptions) (*UpgradeResponse, error) {
 		log.Silence()
 	}
 
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, options.Namespace)
+	if err != nil {
+		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", options.Namespace)
+		if options.Debug {
+			return nil, errors.Wrap(err, "failed to get kotsadm auth slug")
+		}
+		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+	}
+
 	airgapPath := ""
 	var images []kustomizetypes.Image
 	if options.AirgapBundle != "" {
@@ -68,6 +90,44 @@ func Upgrade(appSlug string, options UpgradeOptions) (*UpgradeResponse, error) {
 
 		airgapPath = airgapRootDir
 
+		if options.SkipIntegrityCheck {
+			log.ActionWithoutSpinnerWarning("WARNING: skipping the airgap bundle integrity check, the bundle will not be verified and may have been tampered with", nil)
+		} else {
+			if options.License == nil {
+				license, err := getLicense(options.LicenseEndpoint, authSlug)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to get license from the admin console")
+				}
+				options.License = license
+			}
+			// the signature is verified before anything is extracted or pushed
+			log.ActionWithSpinner("Verifying airgap bundle")
+			if _, err := integrity.VerifyBundle(options.AirgapBundle, options.License); err != nil {
+				if errors.Cause(err) != integrity.ErrManifestMissing || integrity.IsManifestRequired(options.License) {
+					log.FinishSpinnerWithError()
+					return nil, errors.Wrap(err, "failed to verify airgap bundle")
+				}
+				log.FinishSpinner()
+				log.ActionWithoutSpinnerWarning("WARNING: airgap bundle does not have a checksum manifest, the bundle has not been verified", nil)
+			} else {
+				log.FinishSpinner()
+			}
+		}
+
+		airgap, err := kotsutil.FindAirgapMetaInBundle(options.AirgapBundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find airgap meta")
+		}
+		if airgap.Spec.Delta != nil {
+			// the images omitted from a delta must already be in the registry, so the base is checked before pushing anything
+			log.ActionWithSpinner("Verifying delta airgap bundle base version")
+			if err := verifyDeltaBase(options.VerifyDeltaEndpoint, authSlug, airgap.Spec.Delta); err != nil {
+				log.FinishSpinnerWithError()
+				return nil, err
+			}
+			log.FinishSpinner()
+		}
+
 		err = kotsadm.ExtractAppAirgapArchive(options.AirgapBundle, airgapRootDir, options.DisableImagePush, os.Stdout)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to extract images")
@@ -118,6 +178,12 @@ func Upgrade(appSlug string, options UpgradeOptions) (*UpgradeResponse, error) {
 			return nil, errors.Wrap(err, "failed to create part from app.tar.gz")
 		}
 
+		if _, err := os.Stat(filepath.Join(airgapPath, integrity.ManifestFileName)); err == nil {
+			if err := createPartFromFile(writer, airgapPath, integrity.ManifestFileName); err != nil {
+				return nil, errors.Wrapf(err, "failed to create part from %s", integrity.ManifestFileName)
+			}
+		}
+
 		b, err := json.Marshal(images)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to marshal images data")
@@ -140,21 +206,6 @@ func Upgrade(appSlug string, options UpgradeOptions) (*UpgradeResponse, error) {
 		requestBody = buffer
 	}
 
-	clientset, err := k8sutil.GetClientset()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get k8s clientset")
-	}
-
-	authSlug, err := auth.GetOrCreateAuthSlug(clientset, options.Namespace)
-	if err != nil {
-		log.FinishSpinnerWithError()
-		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", options.Namespace)
-		if options.Debug {
-			return nil, errors.Wrap(err, "failed to get kotsadm auth slug")
-		}
-		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
-	}
-
 	newReq, err := util.NewRequest("POST", options.UpdateCheckEndpoint, requestBody)
 	if err != nil {
 		log.FinishSpinnerWithError()
@@ -275,3 +326,87 @@ func createPartFromFile(partWriter *multipart.Writer, path string, fileName stri
 
 	return nil
 }
+
+// verifyDeltaBase asks the admin console to check that the delta airgap bundle was built against the deployed version
+// getLicense returns the app's license from the admin console. The license signature is verified
+// so that it can be trusted to verify the airgap bundle signature.
+func getLicense(endpoint string, authSlug string) (*kotsv1beta1.License, error) {
+	req, err := util.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create get license request")
+	}
+	req.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get license")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read server response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Unexpected response from the API: %d", resp.StatusCode)
+	}
+
+	licenseResponse := struct {
+		LicenseData string `json:"licenseData"`
+	}{}
+	if err := json.Unmarshal(body, &licenseResponse); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal license response")
+	}
+	if licenseResponse.LicenseData == "" {
+		return nil, errors.New("admin console did not return the license, use --license-file to provide it")
+	}
+
+	license, err := kotsutil.LoadLicenseFromBytes([]byte(licenseResponse.LicenseData))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load license")
+	}
+
+	license, err = kotslicense.VerifySignature(license)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify license signature")
+	}
+
+	return license, nil
+}
+
+func verifyDeltaBase(endpoint string, authSlug string, delta *kotsv1beta1.AirgapDelta) error {
+	b, err := json.Marshal(delta)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal delta")
+	}
+
+	req, err := util.NewRequest("POST", endpoint, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, "failed to create verify delta request")
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to verify delta base")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read server response")
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	verifyResponse := struct {
+		Error string `json:"error"`
+	}{}
+	if err := json.Unmarshal(body, &verifyResponse); err == nil && verifyResponse.Error != "" {
+		return errors.New(verifyResponse.Error)
+	}
+	return errors.Errorf("Unexpected response from the API: %d", resp.StatusCode)
+}
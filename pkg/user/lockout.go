@@ -0,0 +1,107 @@
+package user
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/logger"
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
+)
+
+const (
+	defaultMaxAttemptsPerIP = 5
+	defaultMaxAttempts      = 10
+	defaultLockoutDuration  = time.Minute
+	defaultMaxLockout       = time.Hour
+)
+
+// LockoutConfig limits failed shared password logins. Once a client ip, or all clients together,
+// reach their limit, logins are locked for LockoutDuration. Every further failure doubles the
+// lockout, up to MaxLockoutDuration. A limit of 0 disables it.
+type LockoutConfig struct {
+	MaxAttemptsPerIP   int
+	MaxAttempts        int
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+}
+
+// LockedOutError is returned when logins are locked because of too many failed attempts
+type LockedOutError struct {
+	Until time.Time
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("too many attempts, locked until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// GetLockoutConfig reads the lockout config from the environment, falling back to the defaults
+func GetLockoutConfig() LockoutConfig {
+	return LockoutConfig{
+		MaxAttemptsPerIP:   intFromEnv("LOGIN_LOCKOUT_MAX_ATTEMPTS_PER_IP", defaultMaxAttemptsPerIP),
+		MaxAttempts:        intFromEnv("LOGIN_LOCKOUT_MAX_ATTEMPTS", defaultMaxAttempts),
+		LockoutDuration:    durationFromEnv("LOGIN_LOCKOUT_DURATION", defaultLockoutDuration),
+		MaxLockoutDuration: durationFromEnv("LOGIN_LOCKOUT_MAX_DURATION", defaultMaxLockout),
+	}
+}
+
+// LockedUntil returns the time until which logins from the client ip are locked, or nil if they are not
+func (c LockoutConfig) LockedUntil(attempts *usertypes.LoginAttempts, clientIP string, now time.Time) *time.Time {
+	var until time.Time
+	if t := c.lockedUntil(attempts.Global, c.MaxAttempts); t.After(until) {
+		until = t
+	}
+	if ipAttempts, ok := attempts.ByIP[clientIP]; ok && clientIP != "" {
+		if t := c.lockedUntil(ipAttempts, c.MaxAttemptsPerIP); t.After(until) {
+			until = t
+		}
+	}
+
+	if !until.After(now) {
+		return nil
+	}
+	return &until
+}
+
+func (c LockoutConfig) lockedUntil(failedLogins usertypes.FailedLogins, maxAttempts int) time.Time {
+	if maxAttempts <= 0 || failedLogins.Count < maxAttempts {
+		return time.Time{}
+	}
+
+	lockout := c.LockoutDuration
+	for i := maxAttempts; i < failedLogins.Count && lockout < c.MaxLockoutDuration; i++ {
+		lockout *= 2
+	}
+	if lockout > c.MaxLockoutDuration {
+		lockout = c.MaxLockoutDuration
+	}
+
+	return failedLogins.LastFailure.Add(lockout)
+}
+
+func intFromEnv(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Errorf("failed to parse %s %q, using %d", name, value, defaultValue)
+		return defaultValue
+	}
+	return i
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Errorf("failed to parse %s %q, using %s", name, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
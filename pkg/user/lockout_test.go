@@ -0,0 +1,128 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	usertypes "github.com/replicatedhq/kots/pkg/user/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutConfig_LockedUntil(t *testing.T) {
+	config := LockoutConfig{
+		MaxAttemptsPerIP:   3,
+		MaxAttempts:        10,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: 10 * time.Minute,
+	}
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	lastFailure := now.Add(-30 * time.Second)
+
+	timePtr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name     string
+		config   LockoutConfig
+		attempts usertypes.LoginAttempts
+		clientIP string
+		want     *time.Time
+	}{
+		{
+			name:     "no failures",
+			config:   config,
+			attempts: usertypes.LoginAttempts{},
+			clientIP: "10.0.0.1",
+		},
+		{
+			name:   "below the per ip limit",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 2, LastFailure: lastFailure},
+				ByIP:   map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 2, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+		},
+		{
+			name:   "at the per ip limit",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 3, LastFailure: lastFailure},
+				ByIP:   map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 3, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+			want:     timePtr(lastFailure.Add(time.Minute)),
+		},
+		{
+			name:   "other ips are not locked by the per ip limit",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 3, LastFailure: lastFailure},
+				ByIP:   map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 3, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.2",
+		},
+		{
+			name:   "lockout doubles with each failure past the limit",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				ByIP: map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 5, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+			want:     timePtr(lastFailure.Add(4 * time.Minute)),
+		},
+		{
+			name:   "lockout is capped",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				ByIP: map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 100, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+			want:     timePtr(lastFailure.Add(10 * time.Minute)),
+		},
+		{
+			name:   "lockout has expired",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				ByIP: map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 3, LastFailure: now.Add(-2 * time.Minute)}},
+			},
+			clientIP: "10.0.0.1",
+		},
+		{
+			name:   "global limit locks all ips",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 11, LastFailure: lastFailure},
+			},
+			clientIP: "10.0.0.2",
+			want:     timePtr(lastFailure.Add(2 * time.Minute)),
+		},
+		{
+			name:   "the later of the global and per ip lockouts wins",
+			config: config,
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 10, LastFailure: lastFailure},
+				ByIP:   map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 6, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+			want:     timePtr(lastFailure.Add(8 * time.Minute)),
+		},
+		{
+			name: "limits of 0 are disabled",
+			config: LockoutConfig{
+				LockoutDuration:    time.Minute,
+				MaxLockoutDuration: time.Hour,
+			},
+			attempts: usertypes.LoginAttempts{
+				Global: usertypes.FailedLogins{Count: 100, LastFailure: lastFailure},
+				ByIP:   map[string]usertypes.FailedLogins{"10.0.0.1": {Count: 100, LastFailure: lastFailure}},
+			},
+			clientIP: "10.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.LockedUntil(&tt.attempts, tt.clientIP, now)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
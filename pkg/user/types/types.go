@@ -1,5 +1,30 @@
 package types
 
+import "time"
+
+const (
+	// SharedPasswordUserID is the user that logs in with the shared admin console password
+	SharedPasswordUserID = "000000"
+	// CLIUserID is the user that the kots CLI acts as
+	CLIUserID = "kots-cli"
+	// APITokenUserIDPrefix is followed by the token name in the user id of sessions created from api tokens
+	APITokenUserIDPrefix = "apitoken:"
+
+	// FailedLoginsByIPKey is the key in the password secret that holds the failed logins per client ip as json
+	FailedLoginsByIPKey = "failedLoginsByIP"
+)
+
 type User struct {
 	ID string
 }
+
+// LoginAttempts are the failed shared password logins since the last successful login
+type LoginAttempts struct {
+	Global FailedLogins            `json:"global"`
+	ByIP   map[string]FailedLogins `json:"byIP"`
+}
+
+type FailedLogins struct {
+	Count       int       `json:"count"`
+	LastFailure time.Time `json:"lastFailure"`
+}
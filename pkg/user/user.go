@@ -2,6 +2,7 @@ package user
 
 import (
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/logger"
@@ -13,26 +14,30 @@ import (
 var (
 	loginMutex         sync.Mutex
 	ErrInvalidPassword = errors.New("invalid password")
-	ErrTooManyAttempts = errors.New("too many attempts")
 )
 
-func LogIn(password string) (*usertypes.User, error) {
+// LogIn checks the shared password. It returns a *LockedOutError without checking the password
+// if there have been too many failed attempts from the client ip or from all clients.
+func LogIn(password string, clientIP string) (*usertypes.User, error) {
 	loginMutex.Lock()
 	defer loginMutex.Unlock()
 
-	shaBytes, err := store.GetStore().GetSharedPasswordBcrypt()
-
-	// this is rough...  the error is defined twice but we can't wrap it if this is the error
-	if err != nil && err.Error() == ErrTooManyAttempts.Error() {
-		return nil, ErrTooManyAttempts
+	lockedUntil, err := GetLockedUntil(clientIP)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get lockout")
 	}
+	if lockedUntil != nil {
+		return nil, &LockedOutError{Until: *lockedUntil}
+	}
+
+	shaBytes, err := store.GetStore().GetSharedPasswordBcrypt()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get shared password bcrypt")
 	}
 
 	if err := bcrypt.CompareHashAndPassword(shaBytes, []byte(password)); err != nil {
 		if err == bcrypt.ErrMismatchedHashAndPassword {
-			if err := store.GetStore().FlagInvalidPassword(); err != nil {
+			if err := store.GetStore().FlagInvalidPassword(clientIP); err != nil {
 				logger.Infof("failed to flag failed login: %v", err)
 			}
 			return nil, ErrInvalidPassword
@@ -41,11 +46,21 @@ func LogIn(password string) (*usertypes.User, error) {
 		return nil, errors.Wrap(err, "failed to compare password")
 	}
 
-	if err := store.GetStore().FlagSuccessfulLogin(); err != nil {
+	if err := store.GetStore().FlagSuccessfulLogin(clientIP); err != nil {
 		logger.Error(errors.Wrap(err, "failed to flag successful login"))
 	}
 
 	return &usertypes.User{
-		ID: "000000",
+		ID: usertypes.SharedPasswordUserID,
 	}, nil
 }
+
+// GetLockedUntil returns the time until which shared password logins from the client ip are locked, or nil if they are not
+func GetLockedUntil(clientIP string) (*time.Time, error) {
+	attempts, err := store.GetStore().GetLoginAttempts()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get login attempts")
+	}
+
+	return GetLockoutConfig().LockedUntil(attempts, clientIP, time.Now()), nil
+}
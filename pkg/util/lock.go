@@ -0,0 +1,28 @@
+package util
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	appVersionMtxs    = map[string]*sync.Mutex{} // key is app id and sequence
+	appVersionMtxsMtx sync.Mutex
+)
+
+// LockAppVersion locks the archive of the app version until the returned function is called, so that changes
+// that read, modify and write back the archive of the same version don't overwrite each other.
+func LockAppVersion(appID string, sequence int64) func() {
+	key := fmt.Sprintf("%s/%d", appID, sequence)
+
+	appVersionMtxsMtx.Lock()
+	mtx, ok := appVersionMtxs[key]
+	if !ok {
+		mtx = &sync.Mutex{}
+		appVersionMtxs[key] = mtx
+	}
+	appVersionMtxsMtx.Unlock()
+
+	mtx.Lock()
+	return mtx.Unlock
+}
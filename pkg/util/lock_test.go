@@ -0,0 +1,35 @@
+package util
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockAppVersion(t *testing.T) {
+	counter := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := LockAppVersion("app-id", 1)
+			defer unlock()
+
+			// read, modify and write back
+			value := counter
+			value++
+			counter = value
+		}()
+	}
+	wg.Wait()
+
+	if counter != 10 {
+		t.Errorf("expected counter to be 10, got %d", counter)
+	}
+
+	// other versions are not locked
+	unlock := LockAppVersion("app-id", 1)
+	defer unlock()
+	LockAppVersion("app-id", 2)()
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/store"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
 	"github.com/replicatedhq/kots/pkg/util"
-	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,7 +41,7 @@ func (d *DownstreamGitOps) CreateGitOpsDownstreamCommit(appID string, clusterID
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get app")
 	}
-	createdCommitURL, err := gitops.CreateGitOpsCommit(downstreamGitOps, a.Slug, a.Name, int(newSequence), filesInDir, downstreamName)
+	createdCommitURL, err := gitops.CreateGitOpsCommit(downstreamGitOps, a.ID, clusterID, a.Slug, a.Name, int(newSequence), filesInDir, downstreamName)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create gitops commit")
 	}
@@ -88,7 +87,7 @@ func DeployVersion(appID string, sequence int64) error {
 func GetRealizedLinksFromAppSpec(appID string, sequence int64) ([]types.RealizedLink, error) {
 	db := persistence.MustGetDBSession()
 	query := `select app_spec, kots_app_spec from app_version where app_id = ? and sequence = ?`
-	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+	rows, err := db.QueryOneParameterized(persistence.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
 	})
@@ -99,8 +98,8 @@ func GetRealizedLinksFromAppSpec(appID string, sequence int64) ([]types.Realized
 		return []types.RealizedLink{}, nil
 	}
 
-	var appSpecStr gorqlite.NullString
-	var kotsAppSpecStr gorqlite.NullString
+	var appSpecStr persistence.NullString
+	var kotsAppSpecStr persistence.NullString
 	if err := rows.Scan(&appSpecStr, &kotsAppSpecStr); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
@@ -24,6 +24,8 @@ type State = {
   authLoading: boolean;
   loginInfo: {
     method: string;
+    lockedUntil?: string;
+    lockoutRemainingSeconds?: number;
   } | null;
 };
 type LoginResponse = {
@@ -223,6 +225,17 @@ class SecureAdminConsole extends React.Component<Props, State> {
       }
 
       const loginInfo = await response.json();
+      if (loginInfo.lockoutRemainingSeconds) {
+        const minutes = Math.ceil(loginInfo.lockoutRemainingSeconds / 60);
+        this.setState({
+          loginInfo,
+          loginErr: true,
+          loginErrMessage: `Too many failed login attempts. Try again in ${minutes} minute${
+            minutes === 1 ? "" : "s"
+          }, or unlock the Admin Console using the "kubectl kots reset-password --unlock" command.`,
+        });
+        return loginInfo;
+      }
       this.setState({ loginInfo, loginErr: false, loginErrMessage: "" });
 
       return loginInfo;